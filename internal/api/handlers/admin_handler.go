@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type AdminHandler struct {
+	adminService *services.AdminService
+	logger       *logger.Logger
+}
+
+func NewAdminHandler(adminService *services.AdminService, log *logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		logger:       log,
+	}
+}
+
+// ListBusinesses handles GET /api/v1/admin/businesses
+func (h *AdminHandler) ListBusinesses(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+	limit, offset := paginationParams(r)
+
+	query := r.URL.Query()
+	response, err := h.adminService.ListBusinesses(r.Context(), actorID, dto.AdminBusinessQuery{
+		Search:   query.Get("q"),
+		Status:   query.Get("status"),
+		ParentID: query.Get("parent_id"),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// GetBusiness handles GET /api/v1/admin/businesses/:id
+func (h *AdminHandler) GetBusiness(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	businessID := vars["id"]
+
+	response, err := h.adminService.GetBusiness(r.Context(), actorID, businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// SuspendBusiness handles POST /api/v1/admin/businesses/:id/suspend
+func (h *AdminHandler) SuspendBusiness(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	businessID := vars["id"]
+
+	var req dto.SuspendBusinessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.adminService.SuspendBusiness(r.Context(), actorID, businessID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ReactivateBusiness handles POST /api/v1/admin/businesses/:id/reactivate
+func (h *AdminHandler) ReactivateBusiness(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	businessID := vars["id"]
+
+	response, err := h.adminService.ReactivateBusiness(r.Context(), actorID, businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// GetCall handles GET /api/v1/admin/calls/:id
+func (h *AdminHandler) GetCall(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	callID := vars["id"]
+
+	response, err := h.adminService.GetCall(r.Context(), actorID, callID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ReconcileCall handles POST /api/v1/admin/calls/:id/reconcile
+func (h *AdminHandler) ReconcileCall(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	callID := vars["id"]
+
+	response, err := h.adminService.ReconcileCall(r.Context(), actorID, callID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ReconcileCalls handles POST /api/v1/admin/calls/reconcile
+func (h *AdminHandler) ReconcileCalls(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+
+	// The body is optional
+	var req dto.ReconcileCallsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.adminService.ReconcileCalls(r.Context(), actorID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ListWebhookEvents handles GET /api/v1/admin/webhooks
+func (h *AdminHandler) ListWebhookEvents(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+	limit, offset := paginationParams(r)
+
+	query := r.URL.Query()
+	response, err := h.adminService.ListWebhookEvents(r.Context(), actorID, dto.WebhookEventQuery{
+		ProviderCallID: query.Get("provider_call_id"),
+		EventType:      query.Get("event_type"),
+		Status:         query.Get("status"),
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// GetWebhookEvent handles GET /api/v1/admin/webhooks/:id
+func (h *AdminHandler) GetWebhookEvent(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	eventID := vars["id"]
+
+	response, err := h.adminService.GetWebhookEvent(r.Context(), actorID, eventID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// GetUsage handles GET /api/v1/admin/usage
+func (h *AdminHandler) GetUsage(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+
+	days := 30
+	if d, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil {
+		days = d
+	}
+
+	response, err := h.adminService.GetUsage(r.Context(), actorID, days)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ListAuditEntries handles GET /api/v1/admin/audit
+func (h *AdminHandler) ListAuditEntries(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+	limit, offset := paginationParams(r)
+
+	query := r.URL.Query()
+	response, err := h.adminService.ListAuditEntries(r.Context(), actorID, query.Get("business_id"), dto.AuditQuery{
+		ActorID:    query.Get("actor_id"),
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		TargetID:   query.Get("target_id"),
+		From:       query.Get("from"),
+		To:         query.Get("to"),
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ListDoNotCall handles GET /api/v1/admin/dnc
+func (h *AdminHandler) ListDoNotCall(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+	limit, offset := paginationParams(r)
+
+	response, err := h.adminService.ListDoNotCall(r.Context(), actorID, limit, offset)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// AddDoNotCall handles POST /api/v1/admin/dnc
+func (h *AdminHandler) AddDoNotCall(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+
+	var req dto.AddDoNotCallRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.adminService.AddDoNotCall(r.Context(), actorID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusCreated, response)
+}
+
+// RemoveDoNotCall handles DELETE /api/v1/admin/dnc/:id
+func (h *AdminHandler) RemoveDoNotCall(w http.ResponseWriter, r *http.Request) {
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	entryID := vars["id"]
+
+	if err := h.adminService.RemoveDoNotCall(r.Context(), actorID, entryID); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "Number removed from global do-not-call list",
+	})
+}
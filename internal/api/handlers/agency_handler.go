@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type AgencyHandler struct {
+	agencyService *services.AgencyService
+	logger        *logger.Logger
+}
+
+func NewAgencyHandler(agencyService *services.AgencyService, log *logger.Logger) *AgencyHandler {
+	return &AgencyHandler{
+		agencyService: agencyService,
+		logger:        log,
+	}
+}
+
+// ListChildren handles GET /api/v1/agency/businesses
+func (h *AgencyHandler) ListChildren(w http.ResponseWriter, r *http.Request) {
+	agencyID := middleware.GetBusinessID(r.Context())
+	actorID := middleware.GetUserID(r.Context())
+	limit, offset := paginationParams(r)
+
+	response, err := h.agencyService.ListChildren(r.Context(), agencyID, actorID, limit, offset)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// CreateChild handles POST /api/v1/agency/businesses
+func (h *AgencyHandler) CreateChild(w http.ResponseWriter, r *http.Request) {
+	agencyID := middleware.GetBusinessID(r.Context())
+	actorID := middleware.GetUserID(r.Context())
+
+	var req dto.CreateChildBusinessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.agencyService.CreateChild(r.Context(), agencyID, actorID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusCreated, response)
+}
+
+// GetChild handles GET /api/v1/agency/businesses/:id
+func (h *AgencyHandler) GetChild(w http.ResponseWriter, r *http.Request) {
+	agencyID := middleware.GetBusinessID(r.Context())
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	businessID := vars["id"]
+
+	response, err := h.agencyService.GetChild(r.Context(), agencyID, actorID, businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// UpdateChild handles PUT /api/v1/agency/businesses/:id
+func (h *AgencyHandler) UpdateChild(w http.ResponseWriter, r *http.Request) {
+	agencyID := middleware.GetBusinessID(r.Context())
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	businessID := vars["id"]
+
+	var req dto.UpdateBusinessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.agencyService.UpdateChild(r.Context(), agencyID, actorID, businessID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// Impersonate handles POST /api/v1/agency/businesses/:id/impersonate
+func (h *AgencyHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	agencyID := middleware.GetBusinessID(r.Context())
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	businessID := vars["id"]
+
+	response, err := h.agencyService.Impersonate(r.Context(), agencyID, actorID, businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// GetAnalytics handles GET /api/v1/agency/analytics
+func (h *AgencyHandler) GetAnalytics(w http.ResponseWriter, r *http.Request) {
+	agencyID := middleware.GetBusinessID(r.Context())
+	actorID := middleware.GetUserID(r.Context())
+
+	days := 30
+	if d, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil {
+		days = d
+	}
+
+	response, err := h.agencyService.GetAnalytics(r.Context(), agencyID, actorID, days)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// PushAssistantTemplate handles POST /api/v1/agency/assistant-template/push
+func (h *AgencyHandler) PushAssistantTemplate(w http.ResponseWriter, r *http.Request) {
+	agencyID := middleware.GetBusinessID(r.Context())
+	actorID := middleware.GetUserID(r.Context())
+
+	var req dto.PushAssistantTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.agencyService.PushAssistantTemplate(r.Context(), agencyID, actorID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+	logger        *logger.Logger
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService, log *logger.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		logger:        log,
+	}
+}
+
+// CreateKey handles POST /api/v1/api-keys
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	userID := middleware.GetUserID(r.Context())
+
+	var req dto.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.apiKeyService.CreateKey(r.Context(), businessID, userID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusCreated, response)
+}
+
+// ListKeys handles GET /api/v1/api-keys
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	response, err := h.apiKeyService.ListKeys(r.Context(), businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// RevokeKey handles DELETE /api/v1/api-keys/:id
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	userID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	keyID := vars["id"]
+
+	if err := h.apiKeyService.RevokeKey(r.Context(), businessID, userID, keyID); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "API key revoked",
+	})
+}
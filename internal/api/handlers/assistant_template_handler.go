@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type AssistantTemplateHandler struct {
+	templateService *services.AssistantTemplateService
+	logger          *logger.Logger
+}
+
+func NewAssistantTemplateHandler(templateService *services.AssistantTemplateService, log *logger.Logger) *AssistantTemplateHandler {
+	return &AssistantTemplateHandler{
+		templateService: templateService,
+		logger:          log,
+	}
+}
+
+// ListTemplates handles GET /api/v1/assistant-templates
+func (h *AssistantTemplateHandler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	middleware.RespondJSON(w, http.StatusOK, h.templateService.ListTemplates())
+}
+
+// GetTemplate handles GET /api/v1/businesses/me/assistant-template
+func (h *AssistantTemplateHandler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	response, err := h.templateService.GetBusinessTemplate(r.Context(), businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// UpdateTemplate handles PUT /api/v1/businesses/me/assistant-template
+func (h *AssistantTemplateHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	var req dto.UpdateAssistantTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.templateService.UpdateBusinessTemplate(r.Context(), businessID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ResetTemplate handles DELETE /api/v1/businesses/me/assistant-template
+func (h *AssistantTemplateHandler) ResetTemplate(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	response, err := h.templateService.ResetBusinessTemplate(r.Context(), businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// GenerateAssistant handles POST /api/v1/businesses/me/assistant-template/generate
+func (h *AssistantTemplateHandler) GenerateAssistant(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	response, err := h.templateService.GenerateAssistant(r.Context(), businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
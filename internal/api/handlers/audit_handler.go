@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type AuditHandler struct {
+	auditService *services.AuditService
+	logger       *logger.Logger
+}
+
+func NewAuditHandler(auditService *services.AuditService, log *logger.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		logger:       log,
+	}
+}
+
+// ListEntries handles GET /api/v1/audit
+func (h *AuditHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	userID := middleware.GetUserID(r.Context())
+	limit, offset := paginationParams(r)
+
+	query := r.URL.Query()
+	response, err := h.auditService.List(r.Context(), businessID, userID, dto.AuditQuery{
+		ActorID:    query.Get("actor_id"),
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		TargetID:   query.Get("target_id"),
+		From:       query.Get("from"),
+		To:         query.Get("to"),
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
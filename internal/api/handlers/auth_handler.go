@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/CallPilotReceptionist/internal/application/dto"
@@ -11,14 +12,16 @@ import (
 )
 
 type AuthHandler struct {
-	authService *services.AuthService
-	logger      *logger.Logger
+	authService    *services.AuthService
+	accountService *services.AccountService
+	logger         *logger.Logger
 }
 
-func NewAuthHandler(authService *services.AuthService, log *logger.Logger) *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, accountService *services.AccountService, log *logger.Logger) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		logger:      log,
+		authService:    authService,
+		accountService: accountService,
+		logger:         log,
 	}
 }
 
@@ -47,6 +50,8 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	req.Client = clientInfo(r)
+
 	response, err := h.authService.Login(r.Context(), req)
 	if err != nil {
 		middleware.RespondError(w, err, h.logger)
@@ -56,6 +61,25 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	middleware.RespondJSON(w, http.StatusOK, response)
 }
 
+// MFALogin handles POST /api/v1/auth/mfa/login
+func (h *AuthHandler) MFALogin(w http.ResponseWriter, r *http.Request) {
+	var req dto.MFALoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	req.Client = clientInfo(r)
+
+	response, err := h.authService.CompleteMFALogin(r.Context(), req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
 // RefreshToken handles POST /api/v1/auth/refresh
 func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	var req dto.RefreshTokenRequest
@@ -73,11 +97,165 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	middleware.RespondJSON(w, http.StatusOK, response)
 }
 
+// AcceptInvitation handles POST /api/v1/auth/invitations/accept
+func (h *AuthHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	var req dto.AcceptInvitationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.authService.AcceptInvitation(r.Context(), req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusCreated, response)
+}
+
 // Logout handles POST /api/v1/auth/logout
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
-	// In a stateless JWT system, logout is typically handled client-side
-	// by deleting the token. We can add token blacklisting in the future.
+	userID := middleware.GetUserID(r.Context())
+	sessionID := middleware.GetSessionID(r.Context())
+
+	if err := h.authService.Logout(r.Context(), userID, sessionID); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
 	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
 		Message: "Logged out successfully",
 	})
 }
+
+// LogoutAll handles POST /api/v1/auth/logout-all
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	revoked, err := h.authService.LogoutAll(r.Context(), userID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: fmt.Sprintf("Logged out of %d sessions", revoked),
+	})
+}
+
+// ListBusinesses handles GET /api/v1/me/businesses
+func (h *AuthHandler) ListBusinesses(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	businessID := middleware.GetBusinessID(r.Context())
+
+	response, err := h.authService.ListBusinesses(r.Context(), userID, businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// SwitchBusiness handles POST /api/v1/me/businesses/switch
+func (h *AuthHandler) SwitchBusiness(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	sessionID := middleware.GetSessionID(r.Context())
+
+	var req dto.SwitchBusinessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.authService.SwitchBusiness(r.Context(), userID, sessionID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ForgotPassword handles POST /api/v1/auth/password/forgot
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(r.Context(), req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "If the address belongs to an account, a reset link has been sent",
+	})
+}
+
+// ResetPassword handles POST /api/v1/auth/password/reset
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req dto.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	if err := h.accountService.ResetPassword(r.Context(), req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "Password reset, please log in again",
+	})
+}
+
+// VerifyEmail handles POST /api/v1/auth/email/verify
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req dto.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(r.Context(), req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "Email address verified",
+	})
+}
+
+// ResendVerification handles POST /api/v1/auth/email/verification
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	if err := h.accountService.ResendVerification(r.Context(), userID); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "Verification email sent",
+	})
+}
+
+// JWKS handles GET /.well-known/jwks.json
+func (h *AuthHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	middleware.RespondJSON(w, http.StatusOK, h.authService.JWKS())
+}
+
+// clientInfo records where a request came from, for login throttling
+func clientInfo(r *http.Request) dto.ClientInfo {
+	return dto.ClientInfo{
+		RemoteAddr:   r.RemoteAddr,
+		ForwardedFor: r.Header.Get("X-Forwarded-For"),
+	}
+}
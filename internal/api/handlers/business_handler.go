@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 
 	"github.com/CallPilotReceptionist/internal/application/dto"
 	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
 	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
 	"github.com/CallPilotReceptionist/pkg/logger"
 )
@@ -53,3 +55,43 @@ func (h *BusinessHandler) UpdateBusiness(w http.ResponseWriter, r *http.Request)
 
 	middleware.RespondJSON(w, http.StatusOK, response)
 }
+
+// GetSettings handles GET /api/v1/businesses/me/settings
+func (h *BusinessHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	settings, err := h.businessService.GetSettings(r.Context(), businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, settings)
+}
+
+// UpdateSettings handles PATCH /api/v1/businesses/me/settings. The body is
+// a JSON merge patch (RFC 7396).
+func (h *BusinessHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	settings, err := h.businessService.UpdateSettings(r.Context(), businessID, patch)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, settings)
+}
+
+// SettingsSchema handles GET /api/v1/schemas/business-settings.json
+func (h *BusinessHandler) SettingsSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(entities.BusinessSettingsSchema)
+}
@@ -127,11 +127,18 @@ func (h *CallHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
 	signature := r.Header.Get("X-Vapi-Signature")
 
 	// Process webhook
-	if err := h.callService.HandleWebhook(r.Context(), body, signature); err != nil {
+	reply, err := h.callService.ProcessWebhook(r.Context(), body, signature)
+	if err != nil {
 		middleware.RespondError(w, err, h.logger)
 		return
 	}
 
+	// Some events, such as assistant requests, expect a provider-specific reply
+	if reply != nil {
+		middleware.RespondJSON(w, http.StatusOK, reply)
+		return
+	}
+
 	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
 		Message: "Webhook processed successfully",
 	})
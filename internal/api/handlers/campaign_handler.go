@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type CampaignHandler struct {
+	campaignService *services.CampaignService
+	logger          *logger.Logger
+}
+
+func NewCampaignHandler(campaignService *services.CampaignService, log *logger.Logger) *CampaignHandler {
+	return &CampaignHandler{
+		campaignService: campaignService,
+		logger:          log,
+	}
+}
+
+// CreateCampaign handles POST /api/v1/campaigns
+func (h *CampaignHandler) CreateCampaign(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	var req dto.CreateCampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.campaignService.CreateCampaign(r.Context(), businessID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusCreated, response)
+}
+
+// ListCampaigns handles GET /api/v1/campaigns
+func (h *CampaignHandler) ListCampaigns(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	limit, offset := paginationParams(r)
+
+	response, err := h.campaignService.ListCampaigns(r.Context(), businessID, limit, offset)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// GetCampaign handles GET /api/v1/campaigns/:id
+func (h *CampaignHandler) GetCampaign(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	campaignID := vars["id"]
+
+	response, err := h.campaignService.GetCampaign(r.Context(), businessID, campaignID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// AddContacts handles POST /api/v1/campaigns/:id/contacts
+func (h *CampaignHandler) AddContacts(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	campaignID := vars["id"]
+
+	var req dto.AddCampaignContactsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.campaignService.AddContacts(r.Context(), businessID, campaignID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ListContacts handles GET /api/v1/campaigns/:id/contacts
+func (h *CampaignHandler) ListContacts(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	campaignID := vars["id"]
+	limit, offset := paginationParams(r)
+	status := r.URL.Query().Get("status")
+
+	response, err := h.campaignService.ListContacts(r.Context(), businessID, campaignID, status, limit, offset)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// StartCampaign handles POST /api/v1/campaigns/:id/start
+func (h *CampaignHandler) StartCampaign(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	campaignID := vars["id"]
+
+	response, err := h.campaignService.StartCampaign(r.Context(), businessID, campaignID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// PauseCampaign handles POST /api/v1/campaigns/:id/pause
+func (h *CampaignHandler) PauseCampaign(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	campaignID := vars["id"]
+
+	response, err := h.campaignService.PauseCampaign(r.Context(), businessID, campaignID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// CancelCampaign handles POST /api/v1/campaigns/:id/cancel
+func (h *CampaignHandler) CancelCampaign(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	campaignID := vars["id"]
+
+	response, err := h.campaignService.CancelCampaign(r.Context(), businessID, campaignID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type ComplianceHandler struct {
+	complianceService *services.ComplianceService
+	logger            *logger.Logger
+}
+
+func NewComplianceHandler(complianceService *services.ComplianceService, log *logger.Logger) *ComplianceHandler {
+	return &ComplianceHandler{
+		complianceService: complianceService,
+		logger:            log,
+	}
+}
+
+// ListDoNotCall handles GET /api/v1/compliance/dnc
+func (h *ComplianceHandler) ListDoNotCall(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	limit, offset := paginationParams(r)
+
+	response, err := h.complianceService.ListDoNotCall(r.Context(), businessID, limit, offset)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// AddDoNotCall handles POST /api/v1/compliance/dnc
+func (h *ComplianceHandler) AddDoNotCall(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	var req dto.AddDoNotCallRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.complianceService.AddToDoNotCall(r.Context(), businessID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusCreated, response)
+}
+
+// RemoveDoNotCall handles DELETE /api/v1/compliance/dnc/:id
+func (h *ComplianceHandler) RemoveDoNotCall(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	entryID := vars["id"]
+
+	if err := h.complianceService.RemoveFromDoNotCall(r.Context(), businessID, entryID); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "Number removed from do-not-call list",
+	})
+}
+
+// ListConsents handles GET /api/v1/compliance/consents
+func (h *ComplianceHandler) ListConsents(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	limit, offset := paginationParams(r)
+
+	response, err := h.complianceService.ListConsents(r.Context(), businessID, limit, offset)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// RecordConsent handles POST /api/v1/compliance/consents
+func (h *ComplianceHandler) RecordConsent(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	var req dto.RecordConsentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.complianceService.RecordConsent(r.Context(), businessID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusCreated, response)
+}
+
+// RevokeConsent handles DELETE /api/v1/compliance/consents/:id
+func (h *ComplianceHandler) RevokeConsent(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	consentID := vars["id"]
+
+	response, err := h.complianceService.RevokeConsent(r.Context(), businessID, consentID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// Check handles GET /api/v1/compliance/check?phone=
+func (h *ComplianceHandler) Check(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	phoneNumber := r.URL.Query().Get("phone")
+	if phoneNumber == "" {
+		middleware.RespondError(w, errors.NewFieldValidationError("phone", "phone is required"), h.logger)
+		return
+	}
+
+	response, err := h.complianceService.Check(r.Context(), businessID, phoneNumber)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// paginationParams reads limit and offset query parameters, falling back to
+// the defaults for missing or malformed values
+func paginationParams(r *http.Request) (int, int) {
+	limit := 20
+	offset := 0
+
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil {
+		offset = o
+	}
+
+	return limit, offset
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type InboxHandler struct {
+	inboxService *services.InboxService
+	logger       *logger.Logger
+}
+
+func NewInboxHandler(inboxService *services.InboxService, log *logger.Logger) *InboxHandler {
+	return &InboxHandler{
+		inboxService: inboxService,
+		logger:       log,
+	}
+}
+
+// ListInbox handles GET /api/v1/inbox
+func (h *InboxHandler) ListInbox(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	limit, offset := paginationParams(r)
+	query := r.URL.Query()
+
+	assigneeID := query.Get("assignee_id")
+	if assigneeID == "me" {
+		assigneeID = middleware.GetUserID(r.Context())
+	}
+
+	response, err := h.inboxService.ListInbox(r.Context(), businessID, dto.ListInboxRequest{
+		View:       query.Get("view"),
+		Type:       query.Get("type"),
+		Priority:   query.Get("priority"),
+		AssigneeID: assigneeID,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// GetCounts handles GET /api/v1/inbox/counts
+func (h *InboxHandler) GetCounts(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	userID := middleware.GetUserID(r.Context())
+
+	response, err := h.inboxService.GetCounts(r.Context(), businessID, userID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// GetItem handles GET /api/v1/inbox/:id
+func (h *InboxHandler) GetItem(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	itemID := vars["id"]
+
+	response, err := h.inboxService.GetItem(r.Context(), businessID, itemID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// AssignItem handles POST /api/v1/inbox/:id/assign
+func (h *InboxHandler) AssignItem(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	itemID := vars["id"]
+
+	var req dto.AssignInboxItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.inboxService.AssignItem(r.Context(), businessID, itemID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// SnoozeItem handles POST /api/v1/inbox/:id/snooze
+func (h *InboxHandler) SnoozeItem(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	itemID := vars["id"]
+
+	var req dto.SnoozeInboxItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.inboxService.SnoozeItem(r.Context(), businessID, itemID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// MarkDone handles POST /api/v1/inbox/:id/done
+func (h *InboxHandler) MarkDone(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	userID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	itemID := vars["id"]
+
+	response, err := h.inboxService.MarkDone(r.Context(), businessID, userID, itemID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ReopenItem handles POST /api/v1/inbox/:id/reopen
+func (h *InboxHandler) ReopenItem(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	itemID := vars["id"]
+
+	response, err := h.inboxService.ReopenItem(r.Context(), businessID, itemID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// AddComment handles POST /api/v1/inbox/:id/comments
+func (h *InboxHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	userID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	itemID := vars["id"]
+
+	var req dto.InboxCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.inboxService.AddComment(r.Context(), businessID, userID, itemID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusCreated, response)
+}
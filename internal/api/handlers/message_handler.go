@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type MessageHandler struct {
+	messageService *services.MessageService
+	logger         *logger.Logger
+}
+
+func NewMessageHandler(messageService *services.MessageService, log *logger.Logger) *MessageHandler {
+	return &MessageHandler{
+		messageService: messageService,
+		logger:         log,
+	}
+}
+
+// ListMessages handles GET /api/v1/messages
+func (h *MessageHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	limit, offset := paginationParams(r)
+	query := r.URL.Query()
+
+	assigneeID := query.Get("assignee_id")
+	if assigneeID == "me" {
+		assigneeID = middleware.GetUserID(r.Context())
+	}
+
+	response, err := h.messageService.ListMessages(r.Context(), businessID, dto.ListMessagesRequest{
+		Status:      query.Get("status"),
+		RecipientID: query.Get("recipient_id"),
+		AssigneeID:  assigneeID,
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// GetMessage handles GET /api/v1/messages/:id
+func (h *MessageHandler) GetMessage(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	messageID := vars["id"]
+
+	response, err := h.messageService.GetMessage(r.Context(), businessID, messageID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// AcknowledgeMessage handles POST /api/v1/messages/:id/acknowledge
+func (h *MessageHandler) AcknowledgeMessage(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	userID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	messageID := vars["id"]
+
+	response, err := h.messageService.AcknowledgeMessage(r.Context(), businessID, userID, messageID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ResolveMessage handles POST /api/v1/messages/:id/resolve
+func (h *MessageHandler) ResolveMessage(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	userID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	messageID := vars["id"]
+
+	response, err := h.messageService.ResolveMessage(r.Context(), businessID, userID, messageID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ReopenMessage handles POST /api/v1/messages/:id/reopen
+func (h *MessageHandler) ReopenMessage(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	messageID := vars["id"]
+
+	response, err := h.messageService.ReopenMessage(r.Context(), businessID, messageID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// AssignMessage handles POST /api/v1/messages/:id/assign
+func (h *MessageHandler) AssignMessage(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	messageID := vars["id"]
+
+	var req dto.AssignMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.messageService.AssignMessage(r.Context(), businessID, messageID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ListRecipients handles GET /api/v1/message-recipients
+func (h *MessageHandler) ListRecipients(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	response, err := h.messageService.ListRecipients(r.Context(), businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// CreateRecipient handles POST /api/v1/message-recipients
+func (h *MessageHandler) CreateRecipient(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	var req dto.MessageRecipientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.messageService.CreateRecipient(r.Context(), businessID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusCreated, response)
+}
+
+// UpdateRecipient handles PUT /api/v1/message-recipients/:id
+func (h *MessageHandler) UpdateRecipient(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	recipientID := vars["id"]
+
+	var req dto.MessageRecipientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.messageService.UpdateRecipient(r.Context(), businessID, recipientID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// DeleteRecipient handles DELETE /api/v1/message-recipients/:id
+func (h *MessageHandler) DeleteRecipient(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	recipientID := vars["id"]
+
+	if err := h.messageService.DeleteRecipient(r.Context(), businessID, recipientID); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "Message recipient deleted",
+	})
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type OnboardingHandler struct {
+	onboardingService *services.OnboardingService
+	logger            *logger.Logger
+}
+
+func NewOnboardingHandler(onboardingService *services.OnboardingService, log *logger.Logger) *OnboardingHandler {
+	return &OnboardingHandler{
+		onboardingService: onboardingService,
+		logger:            log,
+	}
+}
+
+// Start handles POST /api/v1/businesses/me/onboarding
+func (h *OnboardingHandler) Start(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	var req dto.StartOnboardingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.onboardingService.Start(r.Context(), businessID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// GetStatus handles GET /api/v1/businesses/me/onboarding
+func (h *OnboardingHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	response, err := h.onboardingService.GetStatus(r.Context(), businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// Resume handles POST /api/v1/businesses/me/onboarding/resume
+func (h *OnboardingHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	response, err := h.onboardingService.Resume(r.Context(), businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
@@ -5,21 +5,39 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
 	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
 	"github.com/CallPilotReceptionist/pkg/logger"
 )
 
 type Router struct {
-	router              *mux.Router
-	authMiddleware      *middleware.AuthMiddleware
-	loggingMiddleware   *middleware.LoggingMiddleware
-	corsMiddleware      *middleware.CORSMiddleware
-	errorMiddleware     *middleware.ErrorMiddleware
-	authHandler         *AuthHandler
-	businessHandler     *BusinessHandler
-	callHandler         *CallHandler
-	analyticsHandler    *AnalyticsHandler
-	interactionHandler  *InteractionHandler
+	router               *mux.Router
+	authMiddleware       *middleware.AuthMiddleware
+	loggingMiddleware    *middleware.LoggingMiddleware
+	corsMiddleware       *middleware.CORSMiddleware
+	errorMiddleware      *middleware.ErrorMiddleware
+	authHandler          *AuthHandler
+	businessHandler      *BusinessHandler
+	callHandler          *CallHandler
+	analyticsHandler     *AnalyticsHandler
+	interactionHandler   *InteractionHandler
+	complianceHandler    *ComplianceHandler
+	campaignHandler      *CampaignHandler
+	scheduledCallHandler *ScheduledCallHandler
+	transferHandler      *TransferHandler
+	messageHandler       *MessageHandler
+	inboxHandler         *InboxHandler
+	userHandler          *UserHandler
+	apiKeyHandler        *APIKeyHandler
+	mfaHandler           *MFAHandler
+	ssoHandler           *SSOHandler
+	agencyHandler        *AgencyHandler
+	auditHandler         *AuditHandler
+	adminHandler         *AdminHandler
+	onboardingHandler    *OnboardingHandler
+	templateHandler      *AssistantTemplateHandler
+	tenantScope          middleware.TenantScope
+	logger               *logger.Logger
 }
 
 func NewRouter(
@@ -28,21 +46,59 @@ func NewRouter(
 	callService *services.CallService,
 	analyticsService *services.AnalyticsService,
 	interactionService *services.InteractionService,
+	complianceService *services.ComplianceService,
+	campaignService *services.CampaignService,
+	scheduledCallService *services.ScheduledCallService,
+	transferService *services.TransferService,
+	messageService *services.MessageService,
+	inboxService *services.InboxService,
+	userService *services.UserService,
+	apiKeyService *services.APIKeyService,
+	accountService *services.AccountService,
+	mfaService *services.MFAService,
+	ssoService *services.SSOService,
+	agencyService *services.AgencyService,
+	auditService *services.AuditService,
+	adminService *services.AdminService,
+	onboardingService *services.OnboardingService,
+	templateService *services.AssistantTemplateService,
+	tenantScope middleware.TenantScope,
 	log *logger.Logger,
 ) *Router {
 	r := &Router{
-		router:              mux.NewRouter(),
-		authMiddleware:      middleware.NewAuthMiddleware(authService, log),
-		loggingMiddleware:   middleware.NewLoggingMiddleware(log),
-		corsMiddleware:      middleware.NewCORSMiddleware(nil, nil, nil),
-		errorMiddleware:     middleware.NewErrorMiddleware(log),
-		authHandler:         NewAuthHandler(authService, log),
-		businessHandler:     NewBusinessHandler(businessService, log),
-		callHandler:         NewCallHandler(callService, log),
-		analyticsHandler:    NewAnalyticsHandler(analyticsService, log),
-		interactionHandler:  NewInteractionHandler(interactionService, log),
+		router:               mux.NewRouter(),
+		authMiddleware:       middleware.NewAuthMiddleware(authService, apiKeyService, log),
+		loggingMiddleware:    middleware.NewLoggingMiddleware(log),
+		corsMiddleware:       middleware.NewCORSMiddleware(nil, nil, nil),
+		errorMiddleware:      middleware.NewErrorMiddleware(log),
+		authHandler:          NewAuthHandler(authService, accountService, log),
+		businessHandler:      NewBusinessHandler(businessService, log),
+		callHandler:          NewCallHandler(callService, log),
+		analyticsHandler:     NewAnalyticsHandler(analyticsService, log),
+		interactionHandler:   NewInteractionHandler(interactionService, log),
+		complianceHandler:    NewComplianceHandler(complianceService, log),
+		campaignHandler:      NewCampaignHandler(campaignService, log),
+		scheduledCallHandler: NewScheduledCallHandler(scheduledCallService, log),
+		transferHandler:      NewTransferHandler(transferService, log),
+		messageHandler:       NewMessageHandler(messageService, log),
+		inboxHandler:         NewInboxHandler(inboxService, log),
+		userHandler:          NewUserHandler(userService, log),
+		apiKeyHandler:        NewAPIKeyHandler(apiKeyService, log),
+		mfaHandler:           NewMFAHandler(mfaService, log),
+		ssoHandler:           NewSSOHandler(ssoService, log),
+		agencyHandler:        NewAgencyHandler(agencyService, log),
+		auditHandler:         NewAuditHandler(auditService, log),
+		adminHandler:         NewAdminHandler(adminService, log),
+		onboardingHandler:    NewOnboardingHandler(onboardingService, log),
+		templateHandler:      NewAssistantTemplateHandler(templateService, log),
+		tenantScope:          tenantScope,
+		logger:               log,
 	}
 
+	// POST /calls runs outside ScopeTenant, so the service must scope its
+	// own work
+	callService.SetTenantScope(tenantScope)
+
 	r.setupRoutes()
 	return r
 }
@@ -57,46 +113,204 @@ func (r *Router) setupRoutes() {
 	r.router.HandleFunc("/health", r.healthCheck).Methods("GET")
 	r.router.HandleFunc("/ready", r.readyCheck).Methods("GET")
 
+	// Public keys for verifying access tokens (no auth required)
+	r.router.HandleFunc("/.well-known/jwks.json", r.authHandler.JWKS).Methods("GET")
+
 	// API v1 routes
 	api := r.router.PathPrefix("/api/v1").Subrouter()
+	api.Use(r.authMiddleware.RecordClient)
 
 	// Public routes (no auth required)
 	auth := api.PathPrefix("/auth").Subrouter()
+	auth.Use(middleware.Unscoped(r.tenantScope))
 	auth.HandleFunc("/register", r.authHandler.Register).Methods("POST")
 	auth.HandleFunc("/login", r.authHandler.Login).Methods("POST")
+	auth.HandleFunc("/mfa/login", r.authHandler.MFALogin).Methods("POST")
+	auth.HandleFunc("/sso/start", r.ssoHandler.StartLogin).Methods("POST")
+	auth.HandleFunc("/sso/callback", r.ssoHandler.CompleteLogin).Methods("POST")
 	auth.HandleFunc("/refresh", r.authHandler.RefreshToken).Methods("POST")
+	auth.HandleFunc("/invitations/accept", r.authHandler.AcceptInvitation).Methods("POST")
+	auth.HandleFunc("/password/forgot", r.authHandler.ForgotPassword).Methods("POST")
+	auth.HandleFunc("/password/reset", r.authHandler.ResetPassword).Methods("POST")
+	auth.HandleFunc("/email/verify", r.authHandler.VerifyEmail).Methods("POST")
 
 	// Webhook route (no auth - validated by signature)
-	api.HandleFunc("/webhooks/vapi", r.callHandler.HandleWebhook).Methods("POST")
+	api.Handle("/webhooks/vapi", middleware.Unscoped(r.tenantScope)(http.HandlerFunc(r.callHandler.HandleWebhook))).Methods("POST")
+
+	// Settings schema for building settings forms (no auth required)
+	api.HandleFunc("/schemas/business-settings.json", r.businessHandler.SettingsSchema).Methods("GET")
 
 	// Protected routes (require authentication)
+	// Authentication and the /me and /admin routes are not scoped to one
+	// business; the business data routes below are scoped again
 	protected := api.PathPrefix("").Subrouter()
+	protected.Use(middleware.Unscoped(r.tenantScope))
 	protected.Use(r.authMiddleware.Authenticate)
 
 	// Auth routes
 	protected.HandleFunc("/auth/logout", r.authHandler.Logout).Methods("POST")
+	protected.HandleFunc("/auth/logout-all", r.authHandler.LogoutAll).Methods("POST")
+	protected.HandleFunc("/auth/email/verification", r.authHandler.ResendVerification).Methods("POST")
+	protected.HandleFunc("/auth/mfa", r.mfaHandler.GetStatus).Methods("GET")
+	protected.HandleFunc("/auth/mfa/setup", r.mfaHandler.Setup).Methods("POST")
+	protected.HandleFunc("/auth/mfa/enable", r.mfaHandler.Enable).Methods("POST")
+	protected.HandleFunc("/auth/mfa/disable", r.mfaHandler.Disable).Methods("POST")
+	protected.HandleFunc("/auth/mfa/recovery-codes", r.mfaHandler.RegenerateRecoveryCodes).Methods("POST")
+	protected.HandleFunc("/me/businesses", r.authHandler.ListBusinesses).Methods("GET")
+	protected.HandleFunc("/me/businesses/switch", r.authHandler.SwitchBusiness).Methods("POST")
+
+	// Platform admin routes, for operators across every business
+	admin := protected.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.RequirePlatformAdmin(r.logger))
+	admin.HandleFunc("/businesses", r.adminHandler.ListBusinesses).Methods("GET")
+	admin.HandleFunc("/businesses/{id}", r.adminHandler.GetBusiness).Methods("GET")
+	admin.HandleFunc("/businesses/{id}/suspend", r.adminHandler.SuspendBusiness).Methods("POST")
+	admin.HandleFunc("/businesses/{id}/reactivate", r.adminHandler.ReactivateBusiness).Methods("POST")
+	admin.HandleFunc("/calls/reconcile", r.adminHandler.ReconcileCalls).Methods("POST")
+	admin.HandleFunc("/calls/{id}", r.adminHandler.GetCall).Methods("GET")
+	admin.HandleFunc("/calls/{id}/reconcile", r.adminHandler.ReconcileCall).Methods("POST")
+	admin.HandleFunc("/webhooks", r.adminHandler.ListWebhookEvents).Methods("GET")
+	admin.HandleFunc("/webhooks/{id}", r.adminHandler.GetWebhookEvent).Methods("GET")
+	admin.HandleFunc("/usage", r.adminHandler.GetUsage).Methods("GET")
+	admin.HandleFunc("/audit", r.adminHandler.ListAuditEntries).Methods("GET")
+	admin.HandleFunc("/dnc", r.adminHandler.ListDoNotCall).Methods("GET")
+	admin.HandleFunc("/dnc", r.adminHandler.AddDoNotCall).Methods("POST")
+	admin.HandleFunc("/dnc/{id}", r.adminHandler.RemoveDoNotCall).Methods("DELETE")
+
+	// Placing a call scopes its own database work, so the provider is not
+	// called inside a transaction (see CallService.SetTenantScope)
+	protected.Handle("/calls", middleware.RequireTenant(r.logger)(r.allow(entities.PermissionCallsCreate, r.callHandler.InitiateCall))).Methods("POST")
+
+	// Business data routes, each request in a transaction that row-level
+	// security limits to the authenticated business
+	tenant := protected.PathPrefix("").Subrouter()
+	tenant.Use(middleware.ScopeTenant(r.tenantScope, r.logger))
 
 	// Business routes
-	protected.HandleFunc("/businesses/me", r.businessHandler.GetBusiness).Methods("GET")
-	protected.HandleFunc("/businesses/me", r.businessHandler.UpdateBusiness).Methods("PUT")
+	tenant.Handle("/businesses/me", r.allow(entities.PermissionBusinessRead, r.businessHandler.GetBusiness)).Methods("GET")
+	tenant.Handle("/businesses/me", r.allow(entities.PermissionBusinessWrite, r.businessHandler.UpdateBusiness)).Methods("PUT")
+	tenant.Handle("/businesses/me/settings", r.allow(entities.PermissionBusinessRead, r.businessHandler.GetSettings)).Methods("GET")
+	tenant.Handle("/businesses/me/settings", r.allow(entities.PermissionBusinessWrite, r.businessHandler.UpdateSettings)).Methods("PATCH")
+	tenant.Handle("/businesses/me/onboarding", r.allow(entities.PermissionBusinessRead, r.onboardingHandler.GetStatus)).Methods("GET")
+	tenant.Handle("/businesses/me/onboarding", r.allow(entities.PermissionBusinessWrite, r.onboardingHandler.Start)).Methods("POST")
+	tenant.Handle("/businesses/me/onboarding/resume", r.allow(entities.PermissionBusinessWrite, r.onboardingHandler.Resume)).Methods("POST")
+	tenant.Handle("/businesses/me/assistant-template", r.allow(entities.PermissionBusinessRead, r.templateHandler.GetTemplate)).Methods("GET")
+	tenant.Handle("/businesses/me/assistant-template", r.allow(entities.PermissionBusinessWrite, r.templateHandler.UpdateTemplate)).Methods("PUT")
+	tenant.Handle("/businesses/me/assistant-template", r.allow(entities.PermissionBusinessWrite, r.templateHandler.ResetTemplate)).Methods("DELETE")
+	tenant.Handle("/businesses/me/assistant-template/generate", r.allow(entities.PermissionBusinessWrite, r.templateHandler.GenerateAssistant)).Methods("POST")
+	tenant.Handle("/assistant-templates", r.allow(entities.PermissionBusinessRead, r.templateHandler.ListTemplates)).Methods("GET")
+	tenant.Handle("/businesses/me/sso", r.allow(entities.PermissionSSOManage, r.ssoHandler.GetConnection)).Methods("GET")
+	tenant.Handle("/businesses/me/sso", r.allow(entities.PermissionSSOManage, r.ssoHandler.SaveConnection)).Methods("PUT")
+	tenant.Handle("/businesses/me/sso", r.allow(entities.PermissionSSOManage, r.ssoHandler.DeleteConnection)).Methods("DELETE")
+	tenant.Handle("/businesses/me/sso/link", r.allow(entities.PermissionBusinessRead, r.ssoHandler.StartLink)).Methods("POST")
+
+	// User routes
+	tenant.Handle("/users", r.allow(entities.PermissionUsersRead, r.userHandler.ListUsers)).Methods("GET")
+	tenant.Handle("/users/invitations", r.allow(entities.PermissionUsersRead, r.userHandler.ListInvitations)).Methods("GET")
+	tenant.Handle("/users/invitations", r.allow(entities.PermissionUsersManage, r.userHandler.InviteUser)).Methods("POST")
+	tenant.Handle("/users/invitations/{id}", r.allow(entities.PermissionUsersManage, r.userHandler.RevokeInvitation)).Methods("DELETE")
+	tenant.Handle("/users/{id}/role", r.allow(entities.PermissionUsersManage, r.userHandler.ChangeRole)).Methods("PUT")
+	tenant.Handle("/users/{id}/deactivate", r.allow(entities.PermissionUsersManage, r.userHandler.DeactivateUser)).Methods("POST")
+	tenant.Handle("/users/{id}/reactivate", r.allow(entities.PermissionUsersManage, r.userHandler.ReactivateUser)).Methods("POST")
+	tenant.Handle("/users/{id}/unlock", r.allow(entities.PermissionUsersManage, r.userHandler.UnlockUser)).Methods("POST")
+	tenant.Handle("/users/{id}/membership", r.allow(entities.PermissionUsersManage, r.userHandler.RemoveMember)).Methods("DELETE")
+
+	// Agency routes
+	tenant.Handle("/agency/businesses", r.allow(entities.PermissionAgencyManage, r.agencyHandler.ListChildren)).Methods("GET")
+	tenant.Handle("/agency/businesses", r.allow(entities.PermissionAgencyManage, r.agencyHandler.CreateChild)).Methods("POST")
+	tenant.Handle("/agency/businesses/{id}", r.allow(entities.PermissionAgencyManage, r.agencyHandler.GetChild)).Methods("GET")
+	tenant.Handle("/agency/businesses/{id}", r.allow(entities.PermissionAgencyManage, r.agencyHandler.UpdateChild)).Methods("PUT")
+	tenant.Handle("/agency/businesses/{id}/impersonate", r.allow(entities.PermissionAgencyManage, r.agencyHandler.Impersonate)).Methods("POST")
+	tenant.Handle("/agency/analytics", r.allow(entities.PermissionAgencyManage, r.agencyHandler.GetAnalytics)).Methods("GET")
+	tenant.Handle("/agency/assistant-template/push", r.allow(entities.PermissionAgencyManage, r.agencyHandler.PushAssistantTemplate)).Methods("POST")
+
+	// Audit log routes
+	tenant.Handle("/audit", r.allow(entities.PermissionAuditRead, r.auditHandler.ListEntries)).Methods("GET")
+
+	// API key routes
+	tenant.Handle("/api-keys", r.allow(entities.PermissionAPIKeysManage, r.apiKeyHandler.ListKeys)).Methods("GET")
+	tenant.Handle("/api-keys", r.allow(entities.PermissionAPIKeysManage, r.apiKeyHandler.CreateKey)).Methods("POST")
+	tenant.Handle("/api-keys/{id}", r.allow(entities.PermissionAPIKeysManage, r.apiKeyHandler.RevokeKey)).Methods("DELETE")
 
 	// Call routes
-	protected.HandleFunc("/calls", r.callHandler.InitiateCall).Methods("POST")
-	protected.HandleFunc("/calls", r.callHandler.ListCalls).Methods("GET")
-	protected.HandleFunc("/calls/{id}", r.callHandler.GetCall).Methods("GET")
-	protected.HandleFunc("/calls/{id}/transcript", r.callHandler.GetTranscript).Methods("GET")
-	protected.HandleFunc("/calls/{id}/interactions", r.interactionHandler.GetCallInteractions).Methods("GET")
+	tenant.Handle("/calls", r.allow(entities.PermissionCallsRead, r.callHandler.ListCalls)).Methods("GET")
+	tenant.Handle("/calls/{id}", r.allow(entities.PermissionCallsRead, r.callHandler.GetCall)).Methods("GET")
+	tenant.Handle("/calls/{id}/transcript", r.allow(entities.PermissionCallsRead, r.callHandler.GetTranscript)).Methods("GET")
+	tenant.Handle("/calls/{id}/interactions", r.allow(entities.PermissionCallsRead, r.interactionHandler.GetCallInteractions)).Methods("GET")
 
 	// Interaction routes
-	protected.HandleFunc("/interactions", r.interactionHandler.ListInteractions).Methods("GET")
+	tenant.Handle("/interactions", r.allow(entities.PermissionCallsRead, r.interactionHandler.ListInteractions)).Methods("GET")
 
 	// Appointment routes
-	protected.HandleFunc("/appointments", r.interactionHandler.ListAppointments).Methods("GET")
-	protected.HandleFunc("/appointments/{id}", r.interactionHandler.UpdateAppointmentStatus).Methods("PATCH")
+	tenant.Handle("/appointments", r.allow(entities.PermissionAppointmentsRead, r.interactionHandler.ListAppointments)).Methods("GET")
+	tenant.Handle("/appointments/{id}", r.allow(entities.PermissionAppointmentsUpdate, r.interactionHandler.UpdateAppointmentStatus)).Methods("PATCH")
+
+	// Campaign routes
+	tenant.Handle("/campaigns", r.allow(entities.PermissionCampaignsWrite, r.campaignHandler.CreateCampaign)).Methods("POST")
+	tenant.Handle("/campaigns", r.allow(entities.PermissionCampaignsRead, r.campaignHandler.ListCampaigns)).Methods("GET")
+	tenant.Handle("/campaigns/{id}", r.allow(entities.PermissionCampaignsRead, r.campaignHandler.GetCampaign)).Methods("GET")
+	tenant.Handle("/campaigns/{id}/contacts", r.allow(entities.PermissionCampaignsWrite, r.campaignHandler.AddContacts)).Methods("POST")
+	tenant.Handle("/campaigns/{id}/contacts", r.allow(entities.PermissionCampaignsRead, r.campaignHandler.ListContacts)).Methods("GET")
+	tenant.Handle("/campaigns/{id}/start", r.allow(entities.PermissionCampaignsWrite, r.campaignHandler.StartCampaign)).Methods("POST")
+	tenant.Handle("/campaigns/{id}/pause", r.allow(entities.PermissionCampaignsWrite, r.campaignHandler.PauseCampaign)).Methods("POST")
+	tenant.Handle("/campaigns/{id}/cancel", r.allow(entities.PermissionCampaignsWrite, r.campaignHandler.CancelCampaign)).Methods("POST")
+
+	// Scheduled call routes
+	tenant.Handle("/scheduled-calls", r.allow(entities.PermissionScheduledCallsWrite, r.scheduledCallHandler.CreateScheduledCall)).Methods("POST")
+	tenant.Handle("/scheduled-calls", r.allow(entities.PermissionScheduledCallsRead, r.scheduledCallHandler.ListScheduledCalls)).Methods("GET")
+	tenant.Handle("/scheduled-calls/{id}", r.allow(entities.PermissionScheduledCallsRead, r.scheduledCallHandler.GetScheduledCall)).Methods("GET")
+	tenant.Handle("/scheduled-calls/{id}/cancel", r.allow(entities.PermissionScheduledCallsWrite, r.scheduledCallHandler.CancelScheduledCall)).Methods("POST")
+	tenant.Handle("/scheduled-calls/{id}/reschedule", r.allow(entities.PermissionScheduledCallsWrite, r.scheduledCallHandler.RescheduleCall)).Methods("POST")
+
+	// Transfer routes
+	tenant.Handle("/transfers/destinations", r.allow(entities.PermissionTransfersRead, r.transferHandler.ListDestinations)).Methods("GET")
+	tenant.Handle("/transfers/destinations", r.allow(entities.PermissionTransfersWrite, r.transferHandler.CreateDestination)).Methods("POST")
+	tenant.Handle("/transfers/destinations/{id}", r.allow(entities.PermissionTransfersWrite, r.transferHandler.UpdateDestination)).Methods("PUT")
+	tenant.Handle("/transfers/destinations/{id}", r.allow(entities.PermissionTransfersWrite, r.transferHandler.DeleteDestination)).Methods("DELETE")
+	tenant.Handle("/transfers/destinations/{id}/on-call", r.allow(entities.PermissionTransfersRead, r.transferHandler.ListOnCallShifts)).Methods("GET")
+	tenant.Handle("/transfers/destinations/{id}/on-call", r.allow(entities.PermissionTransfersWrite, r.transferHandler.CreateOnCallShift)).Methods("POST")
+	tenant.Handle("/transfers/on-call/{id}", r.allow(entities.PermissionTransfersWrite, r.transferHandler.DeleteOnCallShift)).Methods("DELETE")
+	tenant.Handle("/transfers/stats", r.allow(entities.PermissionTransfersRead, r.transferHandler.GetTransferStats)).Methods("GET")
+
+	// Message routes
+	tenant.Handle("/messages", r.allow(entities.PermissionMessagesRead, r.messageHandler.ListMessages)).Methods("GET")
+	tenant.Handle("/messages/{id}", r.allow(entities.PermissionMessagesRead, r.messageHandler.GetMessage)).Methods("GET")
+	tenant.Handle("/messages/{id}/acknowledge", r.allow(entities.PermissionMessagesUpdate, r.messageHandler.AcknowledgeMessage)).Methods("POST")
+	tenant.Handle("/messages/{id}/resolve", r.allow(entities.PermissionMessagesUpdate, r.messageHandler.ResolveMessage)).Methods("POST")
+	tenant.Handle("/messages/{id}/reopen", r.allow(entities.PermissionMessagesUpdate, r.messageHandler.ReopenMessage)).Methods("POST")
+	tenant.Handle("/messages/{id}/assign", r.allow(entities.PermissionMessagesUpdate, r.messageHandler.AssignMessage)).Methods("POST")
+	tenant.Handle("/message-recipients", r.allow(entities.PermissionMessagesRead, r.messageHandler.ListRecipients)).Methods("GET")
+	tenant.Handle("/message-recipients", r.allow(entities.PermissionRecipientsWrite, r.messageHandler.CreateRecipient)).Methods("POST")
+	tenant.Handle("/message-recipients/{id}", r.allow(entities.PermissionRecipientsWrite, r.messageHandler.UpdateRecipient)).Methods("PUT")
+	tenant.Handle("/message-recipients/{id}", r.allow(entities.PermissionRecipientsWrite, r.messageHandler.DeleteRecipient)).Methods("DELETE")
+
+	// Inbox routes
+	tenant.Handle("/inbox", r.allow(entities.PermissionInboxRead, r.inboxHandler.ListInbox)).Methods("GET")
+	tenant.Handle("/inbox/counts", r.allow(entities.PermissionInboxRead, r.inboxHandler.GetCounts)).Methods("GET")
+	tenant.Handle("/inbox/{id}", r.allow(entities.PermissionInboxRead, r.inboxHandler.GetItem)).Methods("GET")
+	tenant.Handle("/inbox/{id}/assign", r.allow(entities.PermissionInboxUpdate, r.inboxHandler.AssignItem)).Methods("POST")
+	tenant.Handle("/inbox/{id}/snooze", r.allow(entities.PermissionInboxUpdate, r.inboxHandler.SnoozeItem)).Methods("POST")
+	tenant.Handle("/inbox/{id}/done", r.allow(entities.PermissionInboxUpdate, r.inboxHandler.MarkDone)).Methods("POST")
+	tenant.Handle("/inbox/{id}/reopen", r.allow(entities.PermissionInboxUpdate, r.inboxHandler.ReopenItem)).Methods("POST")
+	tenant.Handle("/inbox/{id}/comments", r.allow(entities.PermissionInboxUpdate, r.inboxHandler.AddComment)).Methods("POST")
+
+	// Compliance routes
+	tenant.Handle("/compliance/check", r.allow(entities.PermissionComplianceRead, r.complianceHandler.Check)).Methods("GET")
+	tenant.Handle("/compliance/dnc", r.allow(entities.PermissionComplianceRead, r.complianceHandler.ListDoNotCall)).Methods("GET")
+	tenant.Handle("/compliance/dnc", r.allow(entities.PermissionComplianceWrite, r.complianceHandler.AddDoNotCall)).Methods("POST")
+	tenant.Handle("/compliance/dnc/{id}", r.allow(entities.PermissionComplianceManage, r.complianceHandler.RemoveDoNotCall)).Methods("DELETE")
+	tenant.Handle("/compliance/consents", r.allow(entities.PermissionComplianceRead, r.complianceHandler.ListConsents)).Methods("GET")
+	tenant.Handle("/compliance/consents", r.allow(entities.PermissionComplianceWrite, r.complianceHandler.RecordConsent)).Methods("POST")
+	tenant.Handle("/compliance/consents/{id}", r.allow(entities.PermissionComplianceWrite, r.complianceHandler.RevokeConsent)).Methods("DELETE")
 
 	// Analytics routes
-	protected.HandleFunc("/analytics/overview", r.analyticsHandler.GetOverview).Methods("GET")
-	protected.HandleFunc("/analytics/calls", r.analyticsHandler.GetCallVolume).Methods("GET")
+	tenant.Handle("/analytics/overview", r.allow(entities.PermissionAnalyticsRead, r.analyticsHandler.GetOverview)).Methods("GET")
+	tenant.Handle("/analytics/calls", r.allow(entities.PermissionAnalyticsRead, r.analyticsHandler.GetCallVolume)).Methods("GET")
+}
+
+// allow guards a protected route with the role permission it requires
+func (r *Router) allow(permission entities.Permission, handler http.HandlerFunc) http.Handler {
+	return middleware.RequirePermission(permission, r.logger)(handler)
 }
 
 func (r *Router) healthCheck(w http.ResponseWriter, req *http.Request) {
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type ScheduledCallHandler struct {
+	scheduledCallService *services.ScheduledCallService
+	logger               *logger.Logger
+}
+
+func NewScheduledCallHandler(scheduledCallService *services.ScheduledCallService, log *logger.Logger) *ScheduledCallHandler {
+	return &ScheduledCallHandler{
+		scheduledCallService: scheduledCallService,
+		logger:               log,
+	}
+}
+
+// CreateScheduledCall handles POST /api/v1/scheduled-calls
+func (h *ScheduledCallHandler) CreateScheduledCall(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	userID := middleware.GetUserID(r.Context())
+
+	var req dto.CreateScheduledCallRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.scheduledCallService.CreateScheduledCall(r.Context(), businessID, userID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusCreated, response)
+}
+
+// ListScheduledCalls handles GET /api/v1/scheduled-calls
+func (h *ScheduledCallHandler) ListScheduledCalls(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	limit, offset := paginationParams(r)
+	status := r.URL.Query().Get("status")
+
+	response, err := h.scheduledCallService.ListScheduledCalls(r.Context(), businessID, status, limit, offset)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// GetScheduledCall handles GET /api/v1/scheduled-calls/:id
+func (h *ScheduledCallHandler) GetScheduledCall(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	scheduledCallID := vars["id"]
+
+	response, err := h.scheduledCallService.GetScheduledCall(r.Context(), businessID, scheduledCallID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// CancelScheduledCall handles POST /api/v1/scheduled-calls/:id/cancel
+func (h *ScheduledCallHandler) CancelScheduledCall(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	scheduledCallID := vars["id"]
+
+	response, err := h.scheduledCallService.CancelScheduledCall(r.Context(), businessID, scheduledCallID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// RescheduleCall handles POST /api/v1/scheduled-calls/:id/reschedule
+func (h *ScheduledCallHandler) RescheduleCall(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	scheduledCallID := vars["id"]
+
+	var req dto.RescheduleCallRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.scheduledCallService.RescheduleCall(r.Context(), businessID, scheduledCallID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type SSOHandler struct {
+	ssoService *services.SSOService
+	logger     *logger.Logger
+}
+
+func NewSSOHandler(ssoService *services.SSOService, log *logger.Logger) *SSOHandler {
+	return &SSOHandler{
+		ssoService: ssoService,
+		logger:     log,
+	}
+}
+
+// StartLogin handles POST /api/v1/auth/sso/start
+func (h *SSOHandler) StartLogin(w http.ResponseWriter, r *http.Request) {
+	var req dto.SSOStartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.ssoService.StartLogin(r.Context(), req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// CompleteLogin handles POST /api/v1/auth/sso/callback
+func (h *SSOHandler) CompleteLogin(w http.ResponseWriter, r *http.Request) {
+	var req dto.SSOCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.ssoService.CompleteLogin(r.Context(), req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// StartLink handles POST /api/v1/businesses/me/sso/link
+func (h *SSOHandler) StartLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	businessID := middleware.GetBusinessID(r.Context())
+
+	response, err := h.ssoService.StartLink(r.Context(), userID, businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// GetConnection handles GET /api/v1/businesses/me/sso
+func (h *SSOHandler) GetConnection(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	response, err := h.ssoService.GetConnection(r.Context(), businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// SaveConnection handles PUT /api/v1/businesses/me/sso
+func (h *SSOHandler) SaveConnection(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	var req dto.SSOConnectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.ssoService.SaveConnection(r.Context(), businessID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// DeleteConnection handles DELETE /api/v1/businesses/me/sso
+func (h *SSOHandler) DeleteConnection(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	if err := h.ssoService.DeleteConnection(r.Context(), businessID); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "Single sign-on removed",
+	})
+}
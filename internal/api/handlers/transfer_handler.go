@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type TransferHandler struct {
+	transferService *services.TransferService
+	logger          *logger.Logger
+}
+
+func NewTransferHandler(transferService *services.TransferService, log *logger.Logger) *TransferHandler {
+	return &TransferHandler{
+		transferService: transferService,
+		logger:          log,
+	}
+}
+
+// ListDestinations handles GET /api/v1/transfers/destinations
+func (h *TransferHandler) ListDestinations(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	response, err := h.transferService.ListDestinations(r.Context(), businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// CreateDestination handles POST /api/v1/transfers/destinations
+func (h *TransferHandler) CreateDestination(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	var req dto.TransferDestinationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.transferService.CreateDestination(r.Context(), businessID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusCreated, response)
+}
+
+// UpdateDestination handles PUT /api/v1/transfers/destinations/:id
+func (h *TransferHandler) UpdateDestination(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	destinationID := vars["id"]
+
+	var req dto.TransferDestinationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.transferService.UpdateDestination(r.Context(), businessID, destinationID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// DeleteDestination handles DELETE /api/v1/transfers/destinations/:id
+func (h *TransferHandler) DeleteDestination(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	destinationID := vars["id"]
+
+	if err := h.transferService.DeleteDestination(r.Context(), businessID, destinationID); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "Transfer destination deleted",
+	})
+}
+
+// ListOnCallShifts handles GET /api/v1/transfers/destinations/:id/on-call
+func (h *TransferHandler) ListOnCallShifts(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	destinationID := vars["id"]
+
+	response, err := h.transferService.ListOnCallShifts(r.Context(), businessID, destinationID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// CreateOnCallShift handles POST /api/v1/transfers/destinations/:id/on-call
+func (h *TransferHandler) CreateOnCallShift(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	destinationID := vars["id"]
+
+	var req dto.CreateOnCallShiftRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.transferService.CreateOnCallShift(r.Context(), businessID, destinationID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusCreated, response)
+}
+
+// DeleteOnCallShift handles DELETE /api/v1/transfers/on-call/:id
+func (h *TransferHandler) DeleteOnCallShift(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	vars := mux.Vars(r)
+	shiftID := vars["id"]
+
+	if err := h.transferService.DeleteOnCallShift(r.Context(), businessID, shiftID); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "On-call shift deleted",
+	})
+}
+
+// GetTransferStats handles GET /api/v1/transfers/stats
+func (h *TransferHandler) GetTransferStats(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	daysStr := r.URL.Query().Get("days")
+	days := 30
+	if daysStr != "" {
+		if d, err := strconv.Atoi(daysStr); err == nil {
+			days = d
+		}
+	}
+
+	response, err := h.transferService.GetTransferStats(r.Context(), businessID, days)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/infrastructure/http/middleware"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type UserHandler struct {
+	userService *services.UserService
+	logger      *logger.Logger
+}
+
+func NewUserHandler(userService *services.UserService, log *logger.Logger) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+		logger:      log,
+	}
+}
+
+// ListUsers handles GET /api/v1/users
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	response, err := h.userService.ListUsers(r.Context(), businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// InviteUser handles POST /api/v1/users/invitations
+func (h *UserHandler) InviteUser(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	userID := middleware.GetUserID(r.Context())
+
+	var req dto.InviteUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.userService.InviteUser(r.Context(), businessID, userID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusCreated, response)
+}
+
+// ListInvitations handles GET /api/v1/users/invitations
+func (h *UserHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+
+	response, err := h.userService.ListInvitations(r.Context(), businessID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// RevokeInvitation handles DELETE /api/v1/users/invitations/:id
+func (h *UserHandler) RevokeInvitation(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	userID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	invitationID := vars["id"]
+
+	if err := h.userService.RevokeInvitation(r.Context(), businessID, userID, invitationID); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "Invitation revoked",
+	})
+}
+
+// ChangeRole handles PUT /api/v1/users/:id/role
+func (h *UserHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	var req dto.UpdateUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	response, err := h.userService.ChangeRole(r.Context(), businessID, actorID, userID, req)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// DeactivateUser handles POST /api/v1/users/:id/deactivate
+func (h *UserHandler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	response, err := h.userService.DeactivateUser(r.Context(), businessID, actorID, userID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// ReactivateUser handles POST /api/v1/users/:id/reactivate
+func (h *UserHandler) ReactivateUser(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	response, err := h.userService.ReactivateUser(r.Context(), businessID, actorID, userID)
+	if err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, response)
+}
+
+// UnlockUser handles POST /api/v1/users/:id/unlock
+func (h *UserHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if err := h.userService.UnlockUser(r.Context(), businessID, actorID, userID); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "User unlocked",
+	})
+}
+
+// RemoveMember handles DELETE /api/v1/users/:id/membership
+func (h *UserHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	businessID := middleware.GetBusinessID(r.Context())
+	actorID := middleware.GetUserID(r.Context())
+	vars := mux.Vars(r)
+	userID := vars["id"]
+
+	if err := h.userService.RemoveMember(r.Context(), businessID, actorID, userID); err != nil {
+		middleware.RespondError(w, err, h.logger)
+		return
+	}
+
+	middleware.RespondJSON(w, http.StatusOK, dto.SuccessResponse{
+		Message: "Member removed",
+	})
+}
@@ -1,24 +1,44 @@
 package dto
 
+import "encoding/json"
+
 // Authentication DTOs
 
 type RegisterRequest struct {
-	BusinessName string `json:"business_name"`
-	BusinessType string `json:"business_type"`
-	Phone        string `json:"phone"`
-	Email        string `json:"email"`
-	Password     string `json:"password"`
+	BusinessName  string `json:"business_name"`
+	BusinessType  string `json:"business_type"`
+	Phone         string `json:"phone"`
+	DefaultRegion string `json:"default_region,omitempty"` // ISO 3166 code used to read national phone numbers
+	Email         string `json:"email"`
+	Password      string `json:"password"`
 }
 
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+
+	Client ClientInfo `json:"-"`
+}
+
+// ClientInfo is where a request came from. Handlers fill it in from the
+// connection; it is never read from the request body.
+type ClientInfo struct {
+	RemoteAddr   string
+	ForwardedFor string
 }
 
 type LoginResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
 	User         UserResponse `json:"user"`
+
+	// MFARequired means the password was right but a second factor is
+	// needed: exchange MFAToken and a code at /auth/mfa/login
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	// MFASetupRequired means the business requires two-factor authentication
+	// and access is limited until it is set up
+	MFASetupRequired bool `json:"mfa_setup_required,omitempty"`
 }
 
 type RefreshTokenRequest struct {
@@ -26,36 +46,338 @@ type RefreshTokenRequest struct {
 }
 
 type RefreshTokenResponse struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// Two-factor authentication DTOs
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"` // authenticator or recovery code
+
+	Client ClientInfo `json:"-"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+type MFAStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+type MFASetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// Single sign-on DTOs
+
+type SSOStartRequest struct {
+	BusinessID string `json:"business_id"`
+}
+
+type SSOStartResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// SSOCallbackRequest carries the parameters the identity provider redirected
+// back to the web app with
+type SSOCallbackRequest struct {
+	State string `json:"state"`
+	Code  string `json:"code"`
+}
+
+type SSOConnectionRequest struct {
+	Issuer         string   `json:"issuer"`
+	ClientID       string   `json:"client_id"`
+	ClientSecret   string   `json:"client_secret,omitempty"` // required when creating; omit to keep the current one
+	AllowedDomains []string `json:"allowed_domains"`
+	DefaultRole    string   `json:"default_role"` // admin or employee, defaults to employee
+	Enabled        *bool    `json:"enabled,omitempty"`
+}
+
+type SSOConnectionResponse struct {
+	Issuer         string   `json:"issuer"`
+	ClientID       string   `json:"client_id"`
+	AllowedDomains []string `json:"allowed_domains"`
+	DefaultRole    string   `json:"default_role"`
+	Enabled        bool     `json:"enabled"`
+	RedirectURI    string   `json:"redirect_uri"`
+	CreatedAt      string   `json:"created_at"`
+	UpdatedAt      string   `json:"updated_at"`
 }
 
 // User DTOs
 
 type UserResponse struct {
-	ID         string `json:"id"`
+	ID            string  `json:"id"`
+	BusinessID    string  `json:"business_id"`
+	Email         string  `json:"email"`
+	Role          string  `json:"role"`
+	EmailVerified bool    `json:"email_verified"`
+	DeactivatedAt *string `json:"deactivated_at,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+type InviteUserRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"` // defaults to employee
+}
+
+type InvitationResponse struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	InvitedBy string `json:"invited_by,omitempty"`
+	Token     string `json:"token,omitempty"` // only returned when the invitation is created
+	ExpiresAt string `json:"expires_at"`
+	CreatedAt string `json:"created_at"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// BusinessMembershipResponse is a business the signed-in user belongs to
+type BusinessMembershipResponse struct {
 	BusinessID string `json:"business_id"`
-	Email      string `json:"email"`
+	Name       string `json:"name"`
 	Role       string `json:"role"`
-	CreatedAt  string `json:"created_at"`
+	// Home is the business the account was created in
+	Home bool `json:"home"`
+	// Active is the business the current token is scoped to
+	Active bool `json:"active"`
+}
+
+type SwitchBusinessRequest struct {
+	BusinessID string `json:"business_id"`
+}
+
+// API key DTOs
+
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expires_at,omitempty"`
+}
+
+type APIKeyResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Key        string   `json:"key,omitempty"`
+	Scopes     []string `json:"scopes"`
+	CreatedBy  string   `json:"created_by"`
+	ExpiresAt  *string  `json:"expires_at,omitempty"`
+	LastUsedAt *string  `json:"last_used_at,omitempty"`
+	RevokedAt  *string  `json:"revoked_at,omitempty"`
+	CreatedAt  string   `json:"created_at"`
 }
 
 // Business DTOs
 
 type BusinessResponse struct {
-	ID        string                 `json:"id"`
-	Name      string                 `json:"name"`
-	Type      string                 `json:"type"`
-	Phone     string                 `json:"phone"`
-	Settings  map[string]interface{} `json:"settings"`
-	CreatedAt string                 `json:"created_at"`
-	UpdatedAt string                 `json:"updated_at"`
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	ParentID *string `json:"parent_id,omitempty"`
+	Phone    string  `json:"phone"`
+	// Settings follow the published business settings schema
+	Settings json.RawMessage `json:"settings"`
+	// SuspendedAt is set while a platform operator has suspended the business
+	SuspendedAt      *string `json:"suspended_at,omitempty"`
+	SuspensionReason string  `json:"suspension_reason,omitempty"`
+	CreatedAt        string  `json:"created_at"`
+	UpdatedAt        string  `json:"updated_at"`
 }
 
 type UpdateBusinessRequest struct {
-	Name     string                 `json:"name,omitempty"`
-	Type     string                 `json:"type,omitempty"`
-	Phone    string                 `json:"phone,omitempty"`
-	Settings map[string]interface{} `json:"settings,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Phone string `json:"phone,omitempty"`
+	// Settings is a JSON merge patch (RFC 7396) of the settings
+	Settings json.RawMessage `json:"settings,omitempty"`
+}
+
+// Agency DTOs
+
+type CreateChildBusinessRequest struct {
+	Name          string          `json:"name"`
+	Type          string          `json:"type"`
+	Phone         string          `json:"phone"`
+	DefaultRegion string          `json:"default_region,omitempty"` // ISO 3166 code used to read national phone numbers
+	Settings      json.RawMessage `json:"settings,omitempty"`       // JSON merge patch of the default settings
+}
+
+// AgencyAnalyticsResponse sums the analytics of an agency's child businesses
+type AgencyAnalyticsResponse struct {
+	Days       int                       `json:"days"`
+	Totals     AnalyticsOverviewResponse `json:"totals"`
+	Businesses []ChildAnalyticsResponse  `json:"businesses"`
+}
+
+type ChildAnalyticsResponse struct {
+	BusinessID string                    `json:"business_id"`
+	Name       string                    `json:"name"`
+	Overview   AnalyticsOverviewResponse `json:"overview"`
+}
+
+// ImpersonationResponse is a short-lived token for acting in a child
+// business. It cannot be refreshed.
+type ImpersonationResponse struct {
+	AccessToken string `json:"access_token"`
+	BusinessID  string `json:"business_id"`
+	AgencyID    string `json:"agency_id"`
+	ExpiresAt   string `json:"expires_at"`
+}
+
+// AssistantTemplate is the assistant an agency pushes to its children
+type AssistantTemplate struct {
+	Name         string `json:"name,omitempty"` // defaults to the child's name
+	Voice        string `json:"voice,omitempty"`
+	Language     string `json:"language,omitempty"`
+	Model        string `json:"model,omitempty"`
+	Prompt       string `json:"prompt"`
+	FirstMessage string `json:"first_message,omitempty"`
+}
+
+type PushAssistantTemplateRequest struct {
+	Template AssistantTemplate `json:"template"`
+	// BusinessIDs are the children to push to; empty means all of them
+	BusinessIDs []string `json:"business_ids,omitempty"`
+}
+
+type PushAssistantTemplateResponse struct {
+	Results []AssistantPushResult `json:"results"`
+}
+
+// AssistantPushResult is the outcome for one child. Error is set when the
+// push to that child failed; the others are unaffected.
+type AssistantPushResult struct {
+	BusinessID  string `json:"business_id"`
+	AssistantID string `json:"assistant_id,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Onboarding DTOs
+
+// StartOnboardingRequest describes the business so its assistant can be
+// generated
+type StartOnboardingRequest struct {
+	BusinessType string `json:"business_type"`
+	Timezone     string `json:"timezone,omitempty"` // IANA name; defaults to the business's
+	// Hours has the shape of settings.hours; it defaults to the business's
+	Hours    json.RawMessage         `json:"hours,omitempty"`
+	Services []OfferedServiceRequest `json:"services,omitempty"`
+	FAQs     []FAQRequest            `json:"faqs,omitempty"`
+}
+
+type OfferedServiceRequest struct {
+	Name            string `json:"name"`
+	Description     string `json:"description,omitempty"`
+	DurationMinutes int    `json:"duration_minutes,omitempty"`
+}
+
+type FAQRequest struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// OnboardingResponse reports each step of the onboarding. A failed step
+// carries its error; resuming the onboarding runs it again.
+type OnboardingResponse struct {
+	BusinessID    string                   `json:"business_id"`
+	Status        string                   `json:"status"` // in_progress, failed or completed
+	Steps         []OnboardingStepResponse `json:"steps"`
+	AssistantID   string                   `json:"assistant_id,omitempty"`
+	PhoneNumberID string                   `json:"phone_number_id,omitempty"`
+	CreatedAt     string                   `json:"created_at"`
+	UpdatedAt     string                   `json:"updated_at"`
+	CompletedAt   *string                  `json:"completed_at,omitempty"`
+}
+
+type OnboardingStepResponse struct {
+	Name        string  `json:"name"`
+	Status      string  `json:"status"` // pending, completed or failed
+	Error       string  `json:"error,omitempty"`
+	Attempts    int     `json:"attempts"`
+	CompletedAt *string `json:"completed_at,omitempty"`
+}
+
+// Assistant template DTOs
+
+// AssistantTemplateResponse is a template from the library, or a business's
+// template with its changes applied
+type AssistantTemplateResponse struct {
+	Key               string                  `json:"key"`
+	Name              string                  `json:"name"`
+	BusinessTypes     []string                `json:"business_types,omitempty"`
+	Prompt            string                  `json:"prompt"`
+	FirstMessage      string                  `json:"first_message"`
+	Functions         []string                `json:"functions"`
+	InteractionFields []InteractionField      `json:"interaction_fields"`
+	Services          []OfferedServiceRequest `json:"services"`
+}
+
+// InteractionField is something the assistant collects from callers
+type InteractionField struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// BusinessAssistantTemplateResponse is the template the business's
+// assistant is generated from and the assistant it renders to
+type BusinessAssistantTemplateResponse struct {
+	Template    AssistantTemplateResponse `json:"template"`
+	Overridden  bool                      `json:"overridden"` // the business has changed the template
+	Preview     AssistantPreview          `json:"preview"`
+	AssistantID string                    `json:"assistant_id,omitempty"`
+}
+
+type AssistantPreview struct {
+	Prompt         string                 `json:"prompt"`
+	FirstMessage   string                 `json:"first_message"`
+	Functions      []string               `json:"functions"`
+	AnalysisSchema map[string]interface{} `json:"analysis_schema,omitempty"`
+}
+
+// UpdateAssistantTemplateRequest replaces the business's changes to its
+// template. Omitted fields keep the template's; an empty list replaces the
+// template's with nothing.
+type UpdateAssistantTemplateRequest struct {
+	Template          string                  `json:"template,omitempty"` // a library key; defaults to the business type's
+	Prompt            string                  `json:"prompt,omitempty"`
+	FirstMessage      string                  `json:"first_message,omitempty"`
+	Functions         []string                `json:"functions"`
+	InteractionFields []InteractionField      `json:"interaction_fields"`
+	Services          []OfferedServiceRequest `json:"services"`
 }
 
 // Call DTOs
@@ -64,6 +386,7 @@ type InitiateCallRequest struct {
 	PhoneNumber string                 `json:"phone_number"`
 	AssistantID string                 `json:"assistant_id,omitempty"`
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Variables   map[string]interface{} `json:"variables,omitempty"` // assistant prompt variables
 }
 
 type CallResponse struct {
@@ -71,6 +394,7 @@ type CallResponse struct {
 	BusinessID     string                 `json:"business_id"`
 	ProviderCallID string                 `json:"provider_call_id,omitempty"`
 	CallerPhone    string                 `json:"caller_phone"`
+	Direction      string                 `json:"direction"`
 	Duration       int                    `json:"duration"`
 	Status         string                 `json:"status"`
 	Cost           float64                `json:"cost"`
@@ -146,6 +470,333 @@ type UpdateAppointmentRequest struct {
 	Status string `json:"status"`
 }
 
+// Compliance DTOs
+
+type AddDoNotCallRequest struct {
+	Phone  string `json:"phone"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type DoNotCallResponse struct {
+	ID        string `json:"id"`
+	Phone     string `json:"phone"`
+	Source    string `json:"source"`
+	Reason    string `json:"reason,omitempty"`
+	Global    bool   `json:"global"`
+	CreatedAt string `json:"created_at"`
+}
+
+type RecordConsentRequest struct {
+	Phone     string `json:"phone"`
+	Source    string `json:"source"`
+	Notes     string `json:"notes,omitempty"`
+	GrantedAt string `json:"granted_at,omitempty"` // RFC3339, defaults to now
+}
+
+type ConsentResponse struct {
+	ID        string  `json:"id"`
+	Phone     string  `json:"phone"`
+	Source    string  `json:"source"`
+	Notes     string  `json:"notes,omitempty"`
+	GrantedAt string  `json:"granted_at"`
+	RevokedAt *string `json:"revoked_at,omitempty"`
+	CreatedAt string  `json:"created_at"`
+}
+
+type ComplianceCheckResponse struct {
+	Phone     string   `json:"phone"`
+	Allowed   bool     `json:"allowed"`
+	Reason    string   `json:"reason,omitempty"`
+	TimeZones []string `json:"time_zones"`
+}
+
+// Campaign DTOs
+
+type RetryPolicyRequest struct {
+	MaxAttempts       int      `json:"max_attempts"`
+	RetryDelayMinutes int      `json:"retry_delay_minutes"`
+	RetryOn           []string `json:"retry_on"`
+}
+
+type CampaignContactRequest struct {
+	Phone     string                 `json:"phone"`
+	Name      string                 `json:"name,omitempty"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+type CreateCampaignRequest struct {
+	Name           string                   `json:"name"`
+	AssistantID    string                   `json:"assistant_id,omitempty"`
+	MaxConcurrent  int                      `json:"max_concurrent,omitempty"`
+	CallsPerMinute int                      `json:"calls_per_minute,omitempty"`
+	StartsAt       string                   `json:"starts_at,omitempty"` // RFC3339, defaults to now
+	EndsAt         string                   `json:"ends_at,omitempty"`   // RFC3339
+	RetryPolicy    *RetryPolicyRequest      `json:"retry_policy,omitempty"`
+	Contacts       []CampaignContactRequest `json:"contacts,omitempty"`
+}
+
+type AddCampaignContactsRequest struct {
+	Contacts []CampaignContactRequest `json:"contacts"`
+}
+
+type RejectedContactResponse struct {
+	Index int    `json:"index"`
+	Phone string `json:"phone"`
+	Error string `json:"error"`
+}
+
+type AddCampaignContactsResponse struct {
+	Added      int                       `json:"added"`
+	Duplicates int                       `json:"duplicates"`
+	Rejected   []RejectedContactResponse `json:"rejected"`
+}
+
+type CampaignProgressResponse struct {
+	Total           int     `json:"total"`
+	Pending         int     `json:"pending"`
+	Calling         int     `json:"calling"`
+	Completed       int     `json:"completed"`
+	Failed          int     `json:"failed"`
+	Skipped         int     `json:"skipped"`
+	PercentFinished float64 `json:"percent_finished"`
+}
+
+type CampaignResponse struct {
+	ID             string                    `json:"id"`
+	BusinessID     string                    `json:"business_id"`
+	Name           string                    `json:"name"`
+	AssistantID    string                    `json:"assistant_id,omitempty"`
+	Status         string                    `json:"status"`
+	MaxConcurrent  int                       `json:"max_concurrent"`
+	CallsPerMinute int                       `json:"calls_per_minute"`
+	RetryPolicy    RetryPolicyRequest        `json:"retry_policy"`
+	StartsAt       string                    `json:"starts_at"`
+	EndsAt         *string                   `json:"ends_at,omitempty"`
+	StartedAt      *string                   `json:"started_at,omitempty"`
+	CompletedAt    *string                   `json:"completed_at,omitempty"`
+	CreatedAt      string                    `json:"created_at"`
+	Progress       *CampaignProgressResponse `json:"progress,omitempty"`
+}
+
+type CampaignContactResponse struct {
+	ID            string   `json:"id"`
+	Phone         string   `json:"phone"`
+	Name          string   `json:"name,omitempty"`
+	Status        string   `json:"status"`
+	Attempts      int      `json:"attempts"`
+	CallIDs       []string `json:"call_ids"`
+	LastOutcome   string   `json:"last_outcome,omitempty"`
+	LastError     string   `json:"last_error,omitempty"`
+	NextAttemptAt *string  `json:"next_attempt_at,omitempty"`
+	UpdatedAt     string   `json:"updated_at"`
+}
+
+// Scheduled call DTOs
+
+type CreateScheduledCallRequest struct {
+	PhoneNumber  string `json:"phone_number"`
+	ScheduledFor string `json:"scheduled_for"` // RFC3339
+	AssistantID  string `json:"assistant_id,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+type RescheduleCallRequest struct {
+	ScheduledFor string `json:"scheduled_for"` // RFC3339
+}
+
+type ScheduledCallResponse struct {
+	ID           string  `json:"id"`
+	BusinessID   string  `json:"business_id"`
+	PhoneNumber  string  `json:"phone_number"`
+	AssistantID  string  `json:"assistant_id,omitempty"`
+	Reason       string  `json:"reason,omitempty"`
+	Source       string  `json:"source"`
+	Status       string  `json:"status"`
+	ScheduledFor string  `json:"scheduled_for"`
+	Attempts     int     `json:"attempts"`
+	LastError    string  `json:"last_error,omitempty"`
+	CallID       *string `json:"call_id,omitempty"`
+	OriginCallID *string `json:"origin_call_id,omitempty"`
+	CreatedBy    *string `json:"created_by,omitempty"`
+	CreatedAt    string  `json:"created_at"`
+	UpdatedAt    string  `json:"updated_at"`
+}
+
+// Transfer DTOs
+
+type TransferDestinationRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	PhoneNumber string `json:"phone_number"`
+	Mode        string `json:"mode,omitempty"`   // warm (default) or cold
+	Active      *bool  `json:"active,omitempty"` // defaults to true
+}
+
+type TransferDestinationResponse struct {
+	ID          string               `json:"id"`
+	BusinessID  string               `json:"business_id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	PhoneNumber string               `json:"phone_number"`
+	Mode        string               `json:"mode"`
+	Active      bool                 `json:"active"`
+	OnCall      *OnCallShiftResponse `json:"on_call,omitempty"` // shift covering the current time
+	CreatedAt   string               `json:"created_at"`
+	UpdatedAt   string               `json:"updated_at"`
+}
+
+type CreateOnCallShiftRequest struct {
+	Name        string `json:"name"`
+	PhoneNumber string `json:"phone_number"`
+	StartsAt    string `json:"starts_at"` // RFC3339
+	EndsAt      string `json:"ends_at"`   // RFC3339
+}
+
+type OnCallShiftResponse struct {
+	ID            string `json:"id"`
+	DestinationID string `json:"destination_id"`
+	Name          string `json:"name"`
+	PhoneNumber   string `json:"phone_number"`
+	StartsAt      string `json:"starts_at"`
+	EndsAt        string `json:"ends_at"`
+}
+
+type TransferStatsResponse struct {
+	Days             int            `json:"days"`
+	TotalCalls       int            `json:"total_calls"`
+	TransferredCalls int            `json:"transferred_calls"` // calls with at least one transfer attempt
+	TransferRate     float64        `json:"transfer_rate"`     // transferred_calls / total_calls
+	Transfers        int            `json:"transfers"`
+	ByDestination    map[string]int `json:"by_destination"`
+	ByOutcome        map[string]int `json:"by_outcome"`
+}
+
+// Message DTOs
+
+type MessageResponse struct {
+	ID             string  `json:"id"`
+	BusinessID     string  `json:"business_id"`
+	CallID         *string `json:"call_id,omitempty"`
+	CallerName     string  `json:"caller_name,omitempty"`
+	CallerPhone    string  `json:"caller_phone"`
+	CallbackPhone  string  `json:"callback_phone"`
+	RecipientName  string  `json:"recipient_name,omitempty"`
+	RecipientID    *string `json:"recipient_id,omitempty"`
+	AssigneeID     *string `json:"assignee_id,omitempty"`
+	Urgency        string  `json:"urgency"`
+	Body           string  `json:"body"`
+	Source         string  `json:"source"`
+	Status         string  `json:"status"`
+	AcknowledgedBy *string `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *string `json:"acknowledged_at,omitempty"`
+	ResolvedBy     *string `json:"resolved_by,omitempty"`
+	ResolvedAt     *string `json:"resolved_at,omitempty"`
+	CreatedAt      string  `json:"created_at"`
+	UpdatedAt      string  `json:"updated_at"`
+}
+
+type ListMessagesRequest struct {
+	Status      string `json:"status"`
+	RecipientID string `json:"recipient_id"`
+	AssigneeID  string `json:"assignee_id"`
+	Limit       int    `json:"limit"`
+	Offset      int    `json:"offset"`
+}
+
+type AssignMessageRequest struct {
+	UserID *string `json:"user_id"` // null unassigns the message
+}
+
+type NotificationChannelRequest struct {
+	Type   string `json:"type"` // email, sms or webhook
+	Target string `json:"target"`
+}
+
+type MessageRecipientRequest struct {
+	Name     string                       `json:"name"`
+	Aliases  []string                     `json:"aliases,omitempty"`
+	UserID   *string                      `json:"user_id,omitempty"`
+	Channels []NotificationChannelRequest `json:"channels"`
+	Active   *bool                        `json:"active,omitempty"` // defaults to true
+}
+
+type MessageRecipientResponse struct {
+	ID         string                       `json:"id"`
+	BusinessID string                       `json:"business_id"`
+	UserID     *string                      `json:"user_id,omitempty"`
+	Name       string                       `json:"name"`
+	Aliases    []string                     `json:"aliases"`
+	Channels   []NotificationChannelRequest `json:"channels"`
+	Active     bool                         `json:"active"`
+	CreatedAt  string                       `json:"created_at"`
+	UpdatedAt  string                       `json:"updated_at"`
+}
+
+// Inbox DTOs
+
+type ListInboxRequest struct {
+	View       string `json:"view"` // active (default), snoozed, done or all
+	Type       string `json:"type"`
+	Priority   string `json:"priority"`
+	AssigneeID string `json:"assignee_id"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+}
+
+type InboxItemResponse struct {
+	ID           string                 `json:"id"`
+	Type         string                 `json:"type"`
+	SourceID     string                 `json:"source_id"`
+	CallID       *string                `json:"call_id,omitempty"`
+	Title        string                 `json:"title"`
+	Summary      string                 `json:"summary,omitempty"`
+	Phone        string                 `json:"phone,omitempty"`
+	Priority     string                 `json:"priority"`
+	Status       string                 `json:"status"` // active, snoozed or done
+	OccurredAt   string                 `json:"occurred_at"`
+	AssigneeID   *string                `json:"assignee_id,omitempty"`
+	SnoozedUntil *string                `json:"snoozed_until,omitempty"`
+	DoneBy       *string                `json:"done_by,omitempty"`
+	DoneAt       *string                `json:"done_at,omitempty"`
+	CommentCount int                    `json:"comment_count"`
+	Comments     []InboxCommentResponse `json:"comments,omitempty"`
+}
+
+type ListInboxResponse struct {
+	Items  []InboxItemResponse `json:"items"`
+	Total  int                 `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+type InboxCountsResponse struct {
+	Active       int            `json:"active"`
+	Mine         int            `json:"mine"`
+	Unassigned   int            `json:"unassigned"`
+	HighPriority int            `json:"high_priority"`
+	Snoozed      int            `json:"snoozed"`
+	ByType       map[string]int `json:"by_type"`
+}
+
+type AssignInboxItemRequest struct {
+	UserID *string `json:"user_id"` // null unassigns the item
+}
+
+type SnoozeInboxItemRequest struct {
+	Until string `json:"until"` // RFC3339
+}
+
+type InboxCommentRequest struct {
+	Body string `json:"body"`
+}
+
+type InboxCommentResponse struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id,omitempty"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
+}
+
 // Analytics DTOs
 
 type AnalyticsOverviewResponse struct {
@@ -167,11 +818,124 @@ type CallVolumeResponse struct {
 	Data []CallVolumeData `json:"data"`
 }
 
+// Audit DTOs
+
+// AuditQuery filters the audit log. From and To are RFC 3339 timestamps.
+type AuditQuery struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	From       string
+	To         string
+	Limit      int
+	Offset     int
+}
+
+type AuditEntryResponse struct {
+	ID         string                 `json:"id"`
+	ActorType  string                 `json:"actor_type"`
+	ActorID    string                 `json:"actor_id,omitempty"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"target_type,omitempty"`
+	TargetID   string                 `json:"target_id,omitempty"`
+	Changes    map[string]AuditChange `json:"changes,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	IP         string                 `json:"ip,omitempty"`
+	UserAgent  string                 `json:"user_agent,omitempty"`
+	CreatedAt  string                 `json:"created_at"`
+}
+
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// Platform admin DTOs
+
+// AdminBusinessQuery filters the businesses an operator lists. Search
+// matches part of the name or phone number, or the exact ID; Status is
+// "active" or "suspended" and empty lists both.
+type AdminBusinessQuery struct {
+	Search   string
+	Status   string
+	ParentID string
+	Limit    int
+	Offset   int
+}
+
+type SuspendBusinessRequest struct {
+	Reason string `json:"reason"`
+}
+
+// AdminCallResponse is a call together with the webhooks received for it
+type AdminCallResponse struct {
+	Call          CallResponse           `json:"call"`
+	WebhookEvents []WebhookEventResponse `json:"webhook_events"`
+}
+
+// ReconcileCallsRequest picks the calls to reconcile: those still not
+// finished OlderThanMinutes after they were created, oldest first
+type ReconcileCallsRequest struct {
+	OlderThanMinutes int `json:"older_than_minutes,omitempty"`
+	Limit            int `json:"limit,omitempty"`
+}
+
+type ReconcileCallsResponse struct {
+	Results []ReconcileResult `json:"results"`
+}
+
+// ReconcileResult is the outcome for one call. Error is set when the call
+// could not be reconciled; the others are unaffected.
+type ReconcileResult struct {
+	CallID         string `json:"call_id"`
+	PreviousStatus string `json:"previous_status"`
+	Status         string `json:"status"`
+	Changed        bool   `json:"changed"`
+	Error          string `json:"error,omitempty"`
+}
+
+// WebhookEventQuery filters the webhook log. Empty fields match every event.
+type WebhookEventQuery struct {
+	ProviderCallID string
+	EventType      string
+	Status         string
+	Limit          int
+	Offset         int
+}
+
+// WebhookEventResponse is a received webhook. Payload is only included when
+// a single event is fetched.
+type WebhookEventResponse struct {
+	ID             string          `json:"id"`
+	EventType      string          `json:"event_type"`
+	ProviderCallID string          `json:"provider_call_id,omitempty"`
+	Status         string          `json:"status"`
+	Error          string          `json:"error,omitempty"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	ReceivedAt     string          `json:"received_at"`
+}
+
+// PlatformUsageResponse is the call usage of every business over the last
+// Days days, busiest first
+type PlatformUsageResponse struct {
+	Days       int                       `json:"days"`
+	Totals     AnalyticsOverviewResponse `json:"totals"`
+	Businesses []BusinessUsageResponse   `json:"businesses"`
+}
+
+type BusinessUsageResponse struct {
+	BusinessID string                    `json:"business_id"`
+	Name       string                    `json:"name"`
+	Usage      AnalyticsOverviewResponse `json:"usage"`
+}
+
 // Error response
 
 type ErrorResponse struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
 	Details string `json:"details,omitempty"`
 }
 
@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/config"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+const (
+	// passwordResetTTL is how long a password reset link works for
+	passwordResetTTL = time.Hour
+	// emailVerificationTTL is how long an email verification link works for
+	emailVerificationTTL = 48 * time.Hour
+)
+
+// AccountService lets users recover their account and prove they own their
+// email address, using single-use tokens sent by email
+type AccountService struct {
+	userRepo    database.UserRepository
+	tokenRepo   database.UserTokenRepository
+	sessionRepo database.SessionRepository
+	mailer      providers.Mailer
+	appURL      string
+	logger      *logger.Logger
+
+	audit *AuditService
+
+	now func() time.Time
+}
+
+func NewAccountService(
+	userRepo database.UserRepository,
+	tokenRepo database.UserTokenRepository,
+	sessionRepo database.SessionRepository,
+	mailer providers.Mailer,
+	cfg *config.Config,
+	log *logger.Logger,
+) *AccountService {
+	return &AccountService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		sessionRepo: sessionRepo,
+		mailer:      mailer,
+		appURL:      strings.TrimRight(cfg.Mail.AppURL, "/"),
+		logger:      log,
+		now:         time.Now,
+	}
+}
+
+// SetAudit records password resets in the audit log
+func (s *AccountService) SetAudit(audit *AuditService) {
+	s.audit = audit
+}
+
+// RequestPasswordReset emails a password reset link. It succeeds whether or
+// not the address belongs to a user, so it cannot be used to find accounts.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, req dto.ForgotPasswordRequest) error {
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		return errors.NewFieldValidationError("email", "email is required")
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil || user == nil || !user.IsActive() {
+		s.logger.Warn("Password reset requested for unknown or deactivated user", map[string]interface{}{
+			"email": email,
+		})
+		return nil
+	}
+
+	token, err := s.issueToken(ctx, user, entities.UserTokenPasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	err = s.mailer.Send(ctx, providers.Email{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password for your account.\n\n"+
+			"Choose a new password here within the next hour:\n%s\n\n"+
+			"If it wasn't you, you can ignore this email.", s.link("/reset-password", token)),
+	})
+	if err != nil {
+		s.logger.Error("Failed to send password reset email", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password with a reset token and signs the user
+// out of every session
+func (s *AccountService) ResetPassword(ctx context.Context, req dto.ResetPasswordRequest) error {
+	if req.Token == "" || req.Password == "" {
+		return errors.NewValidationError("token and password are required")
+	}
+
+	user, err := s.redeemToken(ctx, req.Token, entities.UserTokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := database.HashPassword(req.Password)
+	if err != nil {
+		return errors.NewInternalError(err)
+	}
+	if err := user.ChangePassword(hashedPassword); err != nil {
+		return err
+	}
+	// Receiving the reset email proves the address belongs to the user
+	user.VerifyEmail(s.now())
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		s.logger.Error("Failed to update password", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+		return err
+	}
+
+	revoked, err := s.sessionRepo.RevokeAllForUser(ctx, user.ID, s.now())
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Password reset", map[string]interface{}{
+		"user_id":          user.ID,
+		"revoked_sessions": revoked,
+	})
+
+	s.audit.Record(withAuditUser(ctx, user.ID), AuditEvent{
+		BusinessID: user.BusinessID,
+		Action:     entities.AuditActionPasswordReset,
+		TargetType: "user",
+		TargetID:   user.ID,
+		Metadata:   map[string]interface{}{"revoked_sessions": revoked},
+	})
+
+	return nil
+}
+
+// SendVerification emails a link to verify the user's email address, unless
+// it is already verified
+func (s *AccountService) SendVerification(ctx context.Context, user *entities.User) error {
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	token, err := s.issueToken(ctx, user, entities.UserTokenEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	err = s.mailer.Send(ctx, providers.Email{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Confirm this is your email address to finish setting up your account:\n%s\n\n"+
+			"The link works for 48 hours.", s.link("/verify-email", token)),
+	})
+	if err != nil {
+		s.logger.Error("Failed to send verification email", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+		return errors.NewInternalError(err)
+	}
+
+	return nil
+}
+
+// ResendVerification sends the signed-in user a new verification link
+func (s *AccountService) ResendVerification(ctx context.Context, userID string) error {
+	if userID == "" {
+		return errors.NewForbiddenError("only users can verify an email address")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.NewNotFoundError("user", userID)
+	}
+	if user.IsEmailVerified() {
+		return errors.NewValidationError("email address is already verified")
+	}
+
+	return s.SendVerification(ctx, user)
+}
+
+// VerifyEmail marks the user's email address verified with a verification
+// token. The user gets full access from their next access token.
+func (s *AccountService) VerifyEmail(ctx context.Context, req dto.VerifyEmailRequest) error {
+	if req.Token == "" {
+		return errors.NewFieldValidationError("token", "token is required")
+	}
+
+	user, err := s.redeemToken(ctx, req.Token, entities.UserTokenEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	user.VerifyEmail(s.now())
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	s.logger.Info("Email verified", map[string]interface{}{
+		"user_id": user.ID,
+	})
+
+	return nil
+}
+
+// issueToken replaces the user's outstanding tokens for purpose with a new
+// one and returns it
+func (s *AccountService) issueToken(ctx context.Context, user *entities.User, purpose entities.UserTokenPurpose, ttl time.Duration) (string, error) {
+	now := s.now()
+	if err := s.tokenRepo.InvalidateForUser(ctx, user.ID, purpose, now); err != nil {
+		return "", err
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return "", errors.NewInternalError(err)
+	}
+
+	userToken, err := entities.NewUserToken(user.ID, purpose, tokenHash, now.Add(ttl))
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.tokenRepo.Create(ctx, userToken); err != nil {
+		s.logger.Error("Failed to create user token", err, map[string]interface{}{
+			"user_id": user.ID,
+			"purpose": string(purpose),
+		})
+		return "", err
+	}
+
+	return token, nil
+}
+
+// redeemToken uses up a token issued for purpose and returns its user
+func (s *AccountService) redeemToken(ctx context.Context, token string, purpose entities.UserTokenPurpose) (*entities.User, error) {
+	userToken, err := s.tokenRepo.GetByTokenHash(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if userToken == nil || userToken.Purpose != purpose {
+		return nil, errors.NewUnauthorizedError("invalid or expired token")
+	}
+
+	if err := userToken.Use(s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.tokenRepo.Update(ctx, userToken); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userToken.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || !user.IsActive() {
+		return nil, errors.NewUnauthorizedError("invalid or expired token")
+	}
+
+	return user, nil
+}
+
+func (s *AccountService) link(path, token string) string {
+	return s.appURL + path + "?token=" + url.QueryEscape(token)
+}
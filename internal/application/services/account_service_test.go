@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/pkg/config"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// newMockAccountService returns an account service for the team users, with
+// the auth service that sends its verification emails
+func newMockAccountService(users *mockUserRepository, sessions *mockSessionRepository, mailer *mockMailer) (*AccountService, *AuthService) {
+	log := logger.New("info", "console")
+	cfg := &config.Config{Mail: config.MailConfig{AppURL: "https://app.example.com/"}}
+
+	accounts := NewAccountService(users, &mockUserTokenRepository{}, sessions, mailer, cfg, log)
+	auth := NewAuthService(users, newMockTeamBusinessRepository(), newMockInvitationRepository(), sessions, testAuthConfig(), log)
+	auth.SetAccounts(accounts)
+	return accounts, auth
+}
+
+func TestAccountService_RequestPasswordReset(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		wantCode string
+		wantSent bool
+	}{
+		{
+			name:     "known address",
+			email:    "admin@example.com",
+			wantSent: true,
+		},
+		{
+			name:  "unknown address accepted silently",
+			email: "nobody@example.com",
+		},
+		{
+			name:     "missing address",
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mailer := &mockMailer{}
+			accounts, _ := newMockAccountService(newMockTeamUserRepository(), newMockSessionRepository(), mailer)
+
+			err := accounts.RequestPasswordReset(context.Background(), dto.ForgotPasswordRequest{Email: tt.email})
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("RequestPasswordReset() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RequestPasswordReset() error = %v", err)
+			}
+
+			if !tt.wantSent {
+				if len(mailer.sent) != 0 {
+					t.Errorf("expected no email, got %d", len(mailer.sent))
+				}
+				return
+			}
+			if len(mailer.sent) != 1 || mailer.sent[0].To != tt.email {
+				t.Fatalf("expected a reset email to %s, got %+v", tt.email, mailer.sent)
+			}
+			if !strings.Contains(mailer.sent[0].Body, "https://app.example.com/reset-password?token=") {
+				t.Errorf("unexpected reset link in %q", mailer.sent[0].Body)
+			}
+		})
+	}
+}
+
+func TestAccountService_ResetPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests int
+		link     int
+		used     bool
+		after    time.Duration
+		wantCode string
+	}{
+		{
+			name:     "latest link",
+			requests: 2,
+			link:     1,
+		},
+		{
+			name:     "earlier link",
+			requests: 2,
+			link:     0,
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name:     "link already used",
+			requests: 1,
+			used:     true,
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name:     "expired link",
+			requests: 1,
+			after:    2 * time.Hour,
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := newMockTeamUserRepository()
+			mailer := &mockMailer{}
+			accounts, auth := newMockAccountService(users, newMockSessionRepository(), mailer)
+
+			login, err := auth.Login(ctx, dto.LoginRequest{Email: "admin@example.com", Password: "password123"})
+			if err != nil {
+				t.Fatalf("Login() error = %v", err)
+			}
+			for i := 0; i < tt.requests; i++ {
+				if err := accounts.RequestPasswordReset(ctx, dto.ForgotPasswordRequest{Email: "admin@example.com"}); err != nil {
+					t.Fatalf("RequestPasswordReset() error = %v", err)
+				}
+			}
+			token := mailer.token(t, tt.link)
+			if tt.used {
+				if err := accounts.ResetPassword(ctx, dto.ResetPasswordRequest{Token: token, Password: "other-password"}); err != nil {
+					t.Fatalf("ResetPassword() error = %v", err)
+				}
+			}
+			if tt.after != 0 {
+				accounts.now = func() time.Time { return time.Now().Add(tt.after) }
+			}
+
+			err = accounts.ResetPassword(ctx, dto.ResetPasswordRequest{Token: token, Password: "new-password"})
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("ResetPassword() error = %v, want %s", err, tt.wantCode)
+				}
+				if _, err := auth.Login(ctx, dto.LoginRequest{Email: "admin@example.com", Password: "new-password"}); err == nil {
+					t.Error("expected the password to be unchanged")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResetPassword() error = %v", err)
+			}
+
+			if _, err := auth.RefreshToken(ctx, login.RefreshToken); err == nil {
+				t.Error("expected existing sessions to be revoked")
+			}
+			if _, err := auth.Login(ctx, dto.LoginRequest{Email: "admin@example.com", Password: "password123"}); err == nil {
+				t.Error("expected the old password to stop working")
+			}
+			if _, err := auth.Login(ctx, dto.LoginRequest{Email: "admin@example.com", Password: "new-password"}); err != nil {
+				t.Errorf("expected the new password to work, got %v", err)
+			}
+			if !users.users["user-admin"].IsEmailVerified() {
+				t.Error("expected a password reset to verify the email address")
+			}
+		})
+	}
+}
+
+func TestAccountService_VerifyEmail(t *testing.T) {
+	tests := []struct {
+		name      string
+		resetLink bool
+		wantCode  string
+	}{
+		{
+			name: "verification link",
+		},
+		{
+			name:      "password reset link",
+			resetLink: true,
+			wantCode:  domainerrors.ErrCodeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mailer := &mockMailer{}
+			accounts, auth := newMockAccountService(newMockTeamUserRepository(), newMockSessionRepository(), mailer)
+
+			registered, err := auth.Register(ctx, dto.RegisterRequest{
+				BusinessName: "Test Business",
+				BusinessType: "dentist",
+				Phone:        "+15551234567",
+				Email:        "new@example.com",
+				Password:     "password123",
+			})
+			if err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			if registered.User.EmailVerified {
+				t.Error("expected a new user to be unverified")
+			}
+			claims, err := auth.ValidateToken(registered.AccessToken)
+			if err != nil {
+				t.Fatalf("ValidateToken() error = %v", err)
+			}
+			if !claims.Unverified {
+				t.Error("expected the access token to restrict an unverified user")
+			}
+			if len(mailer.sent) != 1 || mailer.sent[0].To != "new@example.com" {
+				t.Fatalf("expected a verification email, got %+v", mailer.sent)
+			}
+			if tt.resetLink {
+				if err := accounts.RequestPasswordReset(ctx, dto.ForgotPasswordRequest{Email: "new@example.com"}); err != nil {
+					t.Fatalf("RequestPasswordReset() error = %v", err)
+				}
+			}
+
+			err = accounts.VerifyEmail(ctx, dto.VerifyEmailRequest{Token: mailer.lastToken(t)})
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("VerifyEmail() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyEmail() error = %v", err)
+			}
+
+			refreshed, err := auth.RefreshToken(ctx, registered.RefreshToken)
+			if err != nil {
+				t.Fatalf("RefreshToken() error = %v", err)
+			}
+			claims, err = auth.ValidateToken(refreshed.AccessToken)
+			if err != nil {
+				t.Fatalf("ValidateToken() error = %v", err)
+			}
+			if claims.Unverified {
+				t.Error("expected full access after verifying")
+			}
+		})
+	}
+}
+
+func TestAccountService_ResendVerification(t *testing.T) {
+	tests := []struct {
+		name     string
+		verified bool
+		userID   string
+		wantCode string
+	}{
+		{
+			name:   "unverified user",
+			userID: "user-admin",
+		},
+		{
+			name:     "verified user",
+			verified: true,
+			userID:   "user-admin",
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:     "API key",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockTeamUserRepository()
+			if tt.verified {
+				users.users["user-admin"].VerifyEmail(time.Now())
+			}
+			mailer := &mockMailer{}
+			accounts, _ := newMockAccountService(users, newMockSessionRepository(), mailer)
+
+			err := accounts.ResendVerification(context.Background(), tt.userID)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("ResendVerification() error = %v, want %s", err, tt.wantCode)
+				}
+				if len(mailer.sent) != 0 {
+					t.Errorf("expected no email, got %d", len(mailer.sent))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResendVerification() error = %v", err)
+			}
+			if len(mailer.sent) != 1 || mailer.sent[0].To != "admin@example.com" {
+				t.Errorf("expected a verification email, got %+v", mailer.sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,670 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/config"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+const (
+	// defaultReconcileAge is how long a call must have been going before a
+	// batch reconciliation picks it up
+	defaultReconcileAge = 60 * time.Minute
+	// maxReconcileBatch bounds the provider lookups of a batch reconciliation
+	maxReconcileBatch = 500
+	// maxPageSize bounds the listings an operator can page through
+	maxPageSize = 100
+)
+
+// AdminService lets platform operators work across every business: search
+// and suspend businesses, inspect calls and the webhooks received for them,
+// view usage, reconcile calls whose webhooks were missed and maintain the
+// global do-not-call list. Every operator action is recorded in the audit
+// log.
+type AdminService struct {
+	businessRepo database.BusinessRepository
+	userRepo     database.UserRepository
+	callRepo     database.CallRepository
+	sessionRepo  database.SessionRepository
+	webhookLog   database.WebhookEventRepository
+	calls        *CallService
+	compliance   *ComplianceService
+	audit        *AuditService
+	config       config.WebhookLogConfig
+	logger       *logger.Logger
+
+	now func() time.Time
+}
+
+func NewAdminService(
+	businessRepo database.BusinessRepository,
+	userRepo database.UserRepository,
+	callRepo database.CallRepository,
+	sessionRepo database.SessionRepository,
+	webhookLog database.WebhookEventRepository,
+	calls *CallService,
+	compliance *ComplianceService,
+	audit *AuditService,
+	cfg *config.Config,
+	log *logger.Logger,
+) *AdminService {
+	return &AdminService{
+		businessRepo: businessRepo,
+		userRepo:     userRepo,
+		callRepo:     callRepo,
+		sessionRepo:  sessionRepo,
+		webhookLog:   webhookLog,
+		calls:        calls,
+		compliance:   compliance,
+		audit:        audit,
+		config:       cfg.Webhooks,
+		logger:       log,
+		now:          time.Now,
+	}
+}
+
+// ListBusinesses returns a page of every business on the platform, newest
+// first
+func (s *AdminService) ListBusinesses(ctx context.Context, actorID string, query dto.AdminBusinessQuery) ([]*dto.BusinessResponse, error) {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	filter := database.BusinessFilter{ParentID: query.ParentID, Search: query.Search}
+	switch query.Status {
+	case "":
+	case "active", "suspended":
+		suspended := query.Status == "suspended"
+		filter.Suspended = &suspended
+	default:
+		return nil, errors.NewFieldValidationError("status", "status must be active or suspended")
+	}
+
+	limit, offset := pageBounds(query.Limit, query.Offset)
+	businesses, err := s.businessRepo.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, AuditEvent{
+		Action: entities.AuditActionBusinessesSearched,
+		Metadata: map[string]interface{}{
+			"search":    query.Search,
+			"status":    query.Status,
+			"parent_id": query.ParentID,
+		},
+	})
+
+	responses := make([]*dto.BusinessResponse, 0, len(businesses))
+	for _, business := range businesses {
+		responses = append(responses, mapBusinessToResponse(business))
+	}
+	return responses, nil
+}
+
+// GetBusiness returns any business
+func (s *AdminService) GetBusiness(ctx context.Context, actorID, businessID string) (*dto.BusinessResponse, error) {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	business, err := s.getBusiness(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: business.ID,
+		Action:     entities.AuditActionBusinessViewed,
+		TargetType: "business",
+		TargetID:   business.ID,
+	})
+
+	return mapBusinessToResponse(business), nil
+}
+
+// SuspendBusiness stops a business from making and taking calls and signs
+// its users out. They cannot sign in again until it is reactivated.
+func (s *AdminService) SuspendBusiness(ctx context.Context, actorID, businessID string, req dto.SuspendBusinessRequest) (*dto.BusinessResponse, error) {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	business, err := s.getBusiness(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if err := business.Suspend(req.Reason, now); err != nil {
+		return nil, err
+	}
+	if err := s.businessRepo.Update(ctx, business); err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.sessionRepo.RevokeAllForBusiness(ctx, business.ID, now)
+	if err != nil {
+		// The suspension already blocks refreshing the sessions
+		s.logger.Error("Failed to revoke sessions of suspended business", err, map[string]interface{}{
+			"business_id": business.ID,
+		})
+	}
+
+	s.logger.Info("Business suspended", map[string]interface{}{
+		"business_id":      business.ID,
+		"actor_id":         actorID,
+		"sessions_revoked": revoked,
+	})
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: business.ID,
+		Action:     entities.AuditActionBusinessSuspended,
+		TargetType: "business",
+		TargetID:   business.ID,
+		Changes: map[string]entities.AuditChange{
+			"suspended": {Before: false, After: true},
+		},
+		Metadata: map[string]interface{}{
+			"reason":           req.Reason,
+			"sessions_revoked": revoked,
+		},
+	})
+
+	return mapBusinessToResponse(business), nil
+}
+
+// ReactivateBusiness lifts a suspension
+func (s *AdminService) ReactivateBusiness(ctx context.Context, actorID, businessID string) (*dto.BusinessResponse, error) {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	business, err := s.getBusiness(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := business.SuspensionReason
+	if err := business.Reactivate(s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.businessRepo.Update(ctx, business); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Business reactivated", map[string]interface{}{
+		"business_id": business.ID,
+		"actor_id":    actorID,
+	})
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: business.ID,
+		Action:     entities.AuditActionBusinessReactivated,
+		TargetType: "business",
+		TargetID:   business.ID,
+		Changes: map[string]entities.AuditChange{
+			"suspended": {Before: true, After: false},
+		},
+		Metadata: map[string]interface{}{
+			"suspension_reason": reason,
+		},
+	})
+
+	return mapBusinessToResponse(business), nil
+}
+
+// GetCall returns any call together with the webhooks received for it
+func (s *AdminService) GetCall(ctx context.Context, actorID, callID string) (*dto.AdminCallResponse, error) {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	call, err := s.getCall(ctx, callID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.AdminCallResponse{
+		Call:          *s.calls.mapCallToResponse(call),
+		WebhookEvents: []dto.WebhookEventResponse{},
+	}
+	if call.ProviderCallID != "" {
+		events, err := s.webhookLog.List(ctx, database.WebhookEventFilter{ProviderCallID: call.ProviderCallID}, maxPageSize, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			response.WebhookEvents = append(response.WebhookEvents, mapWebhookEventToResponse(event, false))
+		}
+	}
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: call.BusinessID,
+		Action:     entities.AuditActionCallViewed,
+		TargetType: "call",
+		TargetID:   call.ID,
+	})
+
+	return response, nil
+}
+
+// ReconcileCall brings a call up to date with the provider
+func (s *AdminService) ReconcileCall(ctx context.Context, actorID, callID string) (*dto.ReconcileResult, error) {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	call, err := s.getCall(ctx, callID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.reconcile(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ReconcileCalls reconciles the oldest calls still not finished some time
+// after they were created. A call that fails to reconcile does not stop the
+// others.
+func (s *AdminService) ReconcileCalls(ctx context.Context, actorID string, req dto.ReconcileCallsRequest) (*dto.ReconcileCallsResponse, error) {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	olderThan := defaultReconcileAge
+	if req.OlderThanMinutes > 0 {
+		olderThan = time.Duration(req.OlderThanMinutes) * time.Minute
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > maxReconcileBatch {
+		limit = maxReconcileBatch
+	}
+
+	calls, err := s.callRepo.GetUnfinished(ctx, s.now().Add(-olderThan), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.ReconcileCallsResponse{Results: make([]dto.ReconcileResult, 0, len(calls))}
+	for _, call := range calls {
+		result, err := s.reconcile(ctx, call)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
+
+// reconcile reconciles one call and audits the outcome
+func (s *AdminService) reconcile(ctx context.Context, call *entities.Call) (dto.ReconcileResult, error) {
+	result := dto.ReconcileResult{
+		CallID:         call.ID,
+		PreviousStatus: string(call.Status),
+	}
+
+	changed, err := s.calls.ReconcileCall(ctx, call)
+	result.Status = string(call.Status)
+	result.Changed = changed
+	if err != nil {
+		s.logger.Warn("Failed to reconcile call", map[string]interface{}{
+			"call_id": call.ID,
+			"error":   err.Error(),
+		})
+	}
+
+	event := AuditEvent{
+		BusinessID: call.BusinessID,
+		Action:     entities.AuditActionCallReconciled,
+		TargetType: "call",
+		TargetID:   call.ID,
+	}
+	if changed {
+		event.Changes = map[string]entities.AuditChange{
+			"status": {Before: result.PreviousStatus, After: result.Status},
+		}
+	}
+	if err != nil {
+		event.Metadata = map[string]interface{}{"error": err.Error()}
+	}
+	s.audit.Record(ctx, event)
+
+	return result, err
+}
+
+// ListWebhookEvents returns a page of the webhook log, newest first
+func (s *AdminService) ListWebhookEvents(ctx context.Context, actorID string, query dto.WebhookEventQuery) ([]dto.WebhookEventResponse, error) {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	switch entities.WebhookEventStatus(query.Status) {
+	case "", entities.WebhookEventProcessed, entities.WebhookEventFailed:
+	default:
+		return nil, errors.NewFieldValidationError("status", "status must be processed or failed")
+	}
+
+	limit, offset := pageBounds(query.Limit, query.Offset)
+	events, err := s.webhookLog.List(ctx, database.WebhookEventFilter{
+		ProviderCallID: query.ProviderCallID,
+		EventType:      query.EventType,
+		Status:         query.Status,
+	}, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, AuditEvent{
+		Action: entities.AuditActionWebhooksSearched,
+		Metadata: map[string]interface{}{
+			"provider_call_id": query.ProviderCallID,
+			"event_type":       query.EventType,
+			"status":           query.Status,
+		},
+	})
+
+	responses := make([]dto.WebhookEventResponse, 0, len(events))
+	for _, event := range events {
+		responses = append(responses, mapWebhookEventToResponse(event, false))
+	}
+	return responses, nil
+}
+
+// GetWebhookEvent returns a received webhook with its payload
+func (s *AdminService) GetWebhookEvent(ctx context.Context, actorID, eventID string) (*dto.WebhookEventResponse, error) {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	event, err := s.webhookLog.GetByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, errors.NewNotFoundError("webhook event", eventID)
+	}
+
+	s.audit.Record(ctx, AuditEvent{
+		Action:     entities.AuditActionWebhookViewed,
+		TargetType: "webhook_event",
+		TargetID:   event.ID,
+	})
+
+	response := mapWebhookEventToResponse(event, true)
+	return &response, nil
+}
+
+// GetUsage returns the call usage of every business over the last days
+// days, busiest first
+func (s *AdminService) GetUsage(ctx context.Context, actorID string, days int) (*dto.PlatformUsageResponse, error) {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return nil, err
+	}
+	if days <= 0 {
+		days = 30
+	}
+
+	endDate := s.now()
+	usage, err := s.callRepo.GetUsageByBusiness(ctx, endDate.AddDate(0, 0, -days), endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.PlatformUsageResponse{
+		Days:       days,
+		Businesses: make([]dto.BusinessUsageResponse, 0, len(usage)),
+	}
+	totals := &response.Totals
+	for _, business := range usage {
+		totals.TotalCalls += business.TotalCalls
+		totals.CompletedCalls += business.CompletedCalls
+		totals.FailedCalls += business.FailedCalls
+		totals.TotalDuration += business.TotalDuration
+		totals.TotalCost += business.TotalCost
+
+		response.Businesses = append(response.Businesses, dto.BusinessUsageResponse{
+			BusinessID: business.BusinessID,
+			Name:       business.BusinessName,
+			Usage: dto.AnalyticsOverviewResponse{
+				TotalCalls:      business.TotalCalls,
+				CompletedCalls:  business.CompletedCalls,
+				FailedCalls:     business.FailedCalls,
+				TotalDuration:   business.TotalDuration,
+				AverageDuration: business.AverageDuration,
+				TotalCost:       business.TotalCost,
+			},
+		})
+	}
+	if totals.TotalCalls > 0 {
+		totals.AverageDuration = float64(totals.TotalDuration) / float64(totals.TotalCalls)
+	}
+
+	s.audit.Record(ctx, AuditEvent{
+		Action:   entities.AuditActionUsageViewed,
+		Metadata: map[string]interface{}{"days": days},
+	})
+
+	return response, nil
+}
+
+// ListAuditEntries returns a page of the audit log of one business, or of
+// the whole platform when businessID is empty
+func (s *AdminService) ListAuditEntries(ctx context.Context, actorID, businessID string, query dto.AuditQuery) ([]dto.AuditEntryResponse, error) {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.audit.list(ctx, businessID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionAuditSearched,
+		Metadata: map[string]interface{}{
+			"actor_id": query.ActorID,
+			"action":   query.Action,
+		},
+	})
+
+	return entries, nil
+}
+
+// ListDoNotCall returns a page of the global do-not-call list, whose
+// numbers no business may call
+func (s *AdminService) ListDoNotCall(ctx context.Context, actorID string, limit, offset int) ([]*dto.DoNotCallResponse, error) {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.compliance.ListGlobalDoNotCall(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, AuditEvent{Action: entities.AuditActionDoNotCallViewed})
+
+	return entries, nil
+}
+
+// AddDoNotCall lists a number on the global do-not-call list
+func (s *AdminService) AddDoNotCall(ctx context.Context, actorID string, req dto.AddDoNotCallRequest) (*dto.DoNotCallResponse, error) {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.compliance.AddToGlobalDoNotCall(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Number added to global do-not-call list", map[string]interface{}{
+		"entry_id": entry.ID,
+		"actor_id": actorID,
+	})
+
+	s.audit.Record(ctx, AuditEvent{
+		Action:     entities.AuditActionDoNotCallAdded,
+		TargetType: "do_not_call",
+		TargetID:   entry.ID,
+		Metadata: map[string]interface{}{
+			"phone":  entry.Phone,
+			"reason": entry.Reason,
+		},
+	})
+
+	return entry, nil
+}
+
+// RemoveDoNotCall deletes an entry from the global do-not-call list
+func (s *AdminService) RemoveDoNotCall(ctx context.Context, actorID, entryID string) error {
+	if err := s.requireOperator(ctx, actorID); err != nil {
+		return err
+	}
+
+	entry, err := s.compliance.RemoveFromGlobalDoNotCall(ctx, entryID)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Number removed from global do-not-call list", map[string]interface{}{
+		"entry_id": entry.ID,
+		"actor_id": actorID,
+	})
+
+	s.audit.Record(ctx, AuditEvent{
+		Action:     entities.AuditActionDoNotCallRemoved,
+		TargetType: "do_not_call",
+		TargetID:   entry.ID,
+		Metadata: map[string]interface{}{
+			"phone": entry.Phone,
+		},
+	})
+
+	return nil
+}
+
+// PurgeWebhookLog deletes the webhook events older than the retention
+// period and returns how many were deleted
+func (s *AdminService) PurgeWebhookLog(ctx context.Context) (int, error) {
+	if s.config.Retention <= 0 {
+		return 0, nil
+	}
+
+	// The webhook log is not any one business's
+	deleted, err := s.webhookLog.DeleteBefore(database.Unscoped(ctx), s.now().Add(-s.config.Retention))
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		s.logger.Info("Purged webhook log", map[string]interface{}{
+			"deleted":   deleted,
+			"retention": s.config.Retention.String(),
+		})
+	}
+	return deleted, nil
+}
+
+// Run purges expired webhook events every purge interval until the context
+// is cancelled
+func (s *AdminService) Run(ctx context.Context) {
+	interval := s.config.PurgeInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.PurgeWebhookLog(ctx); err != nil {
+				s.logger.Error("Failed to purge webhook log", err, nil)
+			}
+		}
+	}
+}
+
+// requireOperator checks that the actor is still a platform operator. The
+// access token says so too, but the flag may have been revoked since it was
+// issued.
+func (s *AdminService) requireOperator(ctx context.Context, actorID string) error {
+	if actorID == "" {
+		return errors.NewForbiddenError("platform admin access required")
+	}
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if actor == nil || !actor.IsActive() || !actor.PlatformAdmin {
+		return errors.NewForbiddenError("platform admin access required")
+	}
+	return nil
+}
+
+func (s *AdminService) getBusiness(ctx context.Context, businessID string) (*entities.Business, error) {
+	business, err := s.businessRepo.GetByID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, errors.NewNotFoundError("business", businessID)
+	}
+	return business, nil
+}
+
+func (s *AdminService) getCall(ctx context.Context, callID string) (*entities.Call, error) {
+	call, err := s.callRepo.GetByID(ctx, callID)
+	if err != nil {
+		return nil, err
+	}
+	if call == nil {
+		return nil, errors.NewNotFoundError("call", callID)
+	}
+	return call, nil
+}
+
+// pageBounds applies the default and maximum page size
+func pageBounds(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+func mapWebhookEventToResponse(event *entities.WebhookEvent, withPayload bool) dto.WebhookEventResponse {
+	response := dto.WebhookEventResponse{
+		ID:             event.ID,
+		EventType:      event.EventType,
+		ProviderCallID: event.ProviderCallID,
+		Status:         string(event.Status),
+		Error:          event.Error,
+		ReceivedAt:     event.ReceivedAt.Format(time.RFC3339),
+	}
+	if withPayload {
+		response.Payload = event.Payload
+	}
+	return response
+}
@@ -0,0 +1,752 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/config"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// newMockOperatorUserRepository adds the platform operator user-operator to
+// the team
+func newMockOperatorUserRepository() *mockUserRepository {
+	users := newMockTeamUserRepository()
+	operator := *users.users["user-employee"]
+	operator.ID = "user-operator"
+	operator.Email = "operator@example.com"
+	operator.PlatformAdmin = true
+	users.users[operator.ID] = &operator
+	return users
+}
+
+// newMockAdminCallService logs the webhooks it receives to webhooks
+func newMockAdminCallService(callRepo *mockUsageCallRepository, businesses *mockBusinessRepository, provider *mockVoiceProvider, webhooks *mockWebhookEventRepository) *CallService {
+	calls := NewCallService(callRepo, newTestTranscriptRepository(), newTestInteractionRepository(), businesses, provider, logger.New("info", "console"))
+	calls.SetWebhookLog(webhooks)
+	return calls
+}
+
+func newMockAdminService(users *mockUserRepository, businesses *mockBusinessRepository, sessions *mockSessionRepository, callRepo *mockUsageCallRepository, calls *CallService, webhooks *mockWebhookEventRepository, dncRepo *testDoNotCallRepository, auditRepo *mockAuditRepository) *AdminService {
+	log := logger.New("info", "console")
+	cfg := &config.Config{Webhooks: config.WebhookLogConfig{Retention: 30 * 24 * time.Hour}}
+	compliance := NewComplianceService(dncRepo, &testConsentRepository{}, businesses, log)
+	return NewAdminService(businesses, users, callRepo, sessions, webhooks, calls, compliance, newMockAuditService(auditRepo, users, 0), cfg, log)
+}
+
+// newMockWebhookProvider reports a call started for the provider call ID
+// in each webhook's payload
+func newMockWebhookProvider() *mockVoiceProvider {
+	return &mockVoiceProvider{
+		handleWebhookFunc: func(ctx context.Context, payload []byte, signature string) (*providers.CallEvent, error) {
+			var body struct {
+				Call string `json:"call"`
+			}
+			if err := json.Unmarshal(payload, &body); err != nil {
+				return nil, err
+			}
+			return &providers.CallEvent{Type: "call.started", CallID: body.Call}, nil
+		},
+	}
+}
+
+// operatorContext is a request made by the platform operator
+func operatorContext() context.Context {
+	return WithAuditActor(context.Background(), entities.AuditActor{
+		Type: entities.AuditActorUser,
+		ID:   "user-operator",
+	})
+}
+
+func auditActions(entries []*entities.AuditEntry) []string {
+	var actions []string
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
+
+func TestAdminService_RequiresOperator(t *testing.T) {
+	tests := []struct {
+		name       string
+		setupMocks func(users *mockUserRepository)
+		call       func(admin *AdminService) error
+		wantCode   string
+	}{
+		{
+			name: "operator",
+			call: func(admin *AdminService) error {
+				_, err := admin.GetBusiness(operatorContext(), "user-operator", "business-456")
+				return err
+			},
+		},
+		{
+			name: "owner",
+			call: func(admin *AdminService) error {
+				_, err := admin.ListBusinesses(ownerContext(), "user-owner", dto.AdminBusinessQuery{})
+				return err
+			},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name: "API key",
+			call: func(admin *AdminService) error {
+				_, err := admin.GetUsage(ownerContext(), "", 30)
+				return err
+			},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			// Revoking the flag takes effect before the operator's token
+			// expires
+			name: "former operator",
+			setupMocks: func(users *mockUserRepository) {
+				users.users["user-operator"].PlatformAdmin = false
+			},
+			call: func(admin *AdminService) error {
+				_, err := admin.GetBusiness(operatorContext(), "user-operator", "business-456")
+				return err
+			},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockOperatorUserRepository()
+			if tt.setupMocks != nil {
+				tt.setupMocks(users)
+			}
+			businesses := newMockTeamBusinessRepository()
+			callRepo := &mockUsageCallRepository{testCallRepository: newTestCallRepository()}
+			webhooks := &mockWebhookEventRepository{}
+			calls := newMockAdminCallService(callRepo, businesses, &mockVoiceProvider{}, webhooks)
+			admin := newMockAdminService(users, businesses, newMockSessionRepository(), callRepo, calls, webhooks, &testDoNotCallRepository{}, &mockAuditRepository{})
+
+			if err := tt.call(admin); errorCode(err) != tt.wantCode {
+				t.Errorf("error = %v, want %q", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAdminService_ListBusinesses(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    dto.AdminBusinessQuery
+		wantCode string
+		wantIDs  []string
+	}{
+		{
+			name:    "search by name",
+			query:   dto.AdminBusinessQuery{Search: "other"},
+			wantIDs: []string{"business-456"},
+		},
+		{
+			name:    "suspended businesses",
+			query:   dto.AdminBusinessQuery{Status: "suspended"},
+			wantIDs: []string{"business-789"},
+		},
+		{
+			name:     "unknown status",
+			query:    dto.AdminBusinessQuery{Status: "closed"},
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockOperatorUserRepository()
+			businesses := newMockTeamBusinessRepository()
+			suspendedAt := time.Now()
+			businesses.businesses["business-789"] = &entities.Business{ID: "business-789", Name: "Closed Clinic", SuspendedAt: &suspendedAt}
+			callRepo := &mockUsageCallRepository{testCallRepository: newTestCallRepository()}
+			webhooks := &mockWebhookEventRepository{}
+			auditRepo := &mockAuditRepository{}
+			calls := newMockAdminCallService(callRepo, businesses, &mockVoiceProvider{}, webhooks)
+			admin := newMockAdminService(users, businesses, newMockSessionRepository(), callRepo, calls, webhooks, &testDoNotCallRepository{}, auditRepo)
+
+			result, err := admin.ListBusinesses(operatorContext(), "user-operator", tt.query)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("ListBusinesses() error = %v, want %s", err, tt.wantCode)
+				}
+				if len(auditRepo.entries) != 0 {
+					t.Errorf("expected a refused search not to be audited, got %d entries", len(auditRepo.entries))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ListBusinesses() error = %v", err)
+			}
+
+			var ids []string
+			for _, business := range result {
+				ids = append(ids, business.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("ListBusinesses() = %v, want %v", ids, tt.wantIDs)
+			}
+
+			if len(auditRepo.entries) != 1 {
+				t.Fatalf("expected the search to be audited, got %d entries", len(auditRepo.entries))
+			}
+			search := auditRepo.entries[0]
+			if search.Action != entities.AuditActionBusinessesSearched || search.ActorID != "user-operator" || search.BusinessID != "" {
+				t.Errorf("unexpected entry %+v", search)
+			}
+			if tt.query.Search != "" && search.Metadata["search"] != tt.query.Search {
+				t.Errorf("expected the search to be recorded, got %+v", search.Metadata)
+			}
+		})
+	}
+}
+
+func TestAdminService_SuspendBusiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		businessID string
+		req        dto.SuspendBusinessRequest
+		wantCode   string
+	}{
+		{
+			name:       "suspended",
+			businessID: "business-456",
+			req:        dto.SuspendBusinessRequest{Reason: "unpaid invoices"},
+		},
+		{
+			name:       "no reason",
+			businessID: "business-456",
+			wantCode:   domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:       "unknown business",
+			businessID: "business-999",
+			req:        dto.SuspendBusinessRequest{Reason: "fraud"},
+			wantCode:   domainerrors.ErrCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := newMockOperatorUserRepository()
+			businesses := newMockTeamBusinessRepository()
+			sessions := newMockSessionRepository()
+			callRepo := &mockUsageCallRepository{testCallRepository: newTestCallRepository()}
+			webhooks := &mockWebhookEventRepository{}
+			auditRepo := &mockAuditRepository{}
+			calls := newMockAdminCallService(callRepo, businesses, &mockVoiceProvider{}, webhooks)
+			admin := newMockAdminService(users, businesses, sessions, callRepo, calls, webhooks, &testDoNotCallRepository{}, auditRepo)
+			auth := NewAuthService(users, businesses, newMockInvitationRepository(), sessions, testAuthConfig(), logger.New("info", "console"))
+
+			login := dto.LoginRequest{Email: "other@example.com", Password: "password123"}
+			session, err := auth.Login(ctx, login)
+			if err != nil {
+				t.Fatalf("Login() error = %v", err)
+			}
+
+			suspended, err := admin.SuspendBusiness(operatorContext(), "user-operator", tt.businessID, tt.req)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("SuspendBusiness() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SuspendBusiness() error = %v", err)
+			}
+			if suspended.SuspendedAt == nil || suspended.SuspensionReason != "unpaid invoices" {
+				t.Errorf("unexpected business %+v", suspended)
+			}
+
+			// Its users are signed out and cannot sign back in
+			if _, err := auth.RefreshToken(ctx, session.RefreshToken); err == nil {
+				t.Error("expected the business's sessions to be revoked")
+			}
+			if _, err := auth.Login(ctx, login); errorCode(err) != domainerrors.ErrCodeForbidden {
+				t.Errorf("expected the login to be blocked, got %v", err)
+			}
+			// Other businesses are unaffected
+			if _, err := auth.Login(ctx, dto.LoginRequest{Email: "owner@example.com", Password: "password123"}); err != nil {
+				t.Errorf("expected another business's login to work, got %v", err)
+			}
+			// It cannot make calls
+			if _, err := calls.InitiateCall(ctx, "business-456", dto.InitiateCallRequest{PhoneNumber: "+15551234567"}); errorCode(err) != domainerrors.ErrCodeForbidden {
+				t.Errorf("expected the call to be blocked, got %v", err)
+			}
+
+			entry := auditRepo.entries[0]
+			if entry.Action != entities.AuditActionBusinessSuspended || entry.BusinessID != "business-456" || entry.ActorID != "user-operator" || entry.Metadata["reason"] != "unpaid invoices" {
+				t.Errorf("unexpected entry %+v", entry)
+			}
+		})
+	}
+}
+
+func TestAdminService_ReactivateBusiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		businessID string
+		wantCode   string
+	}{
+		{
+			name:       "suspended business",
+			businessID: "business-456",
+		},
+		{
+			name:       "active business",
+			businessID: "business-123",
+			wantCode:   domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:       "unknown business",
+			businessID: "business-999",
+			wantCode:   domainerrors.ErrCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockOperatorUserRepository()
+			businesses := newMockTeamBusinessRepository()
+			suspendedAt := time.Now()
+			businesses.businesses["business-456"].SuspendedAt = &suspendedAt
+			businesses.businesses["business-456"].SuspensionReason = "unpaid invoices"
+			sessions := newMockSessionRepository()
+			callRepo := &mockUsageCallRepository{testCallRepository: newTestCallRepository()}
+			webhooks := &mockWebhookEventRepository{}
+			auditRepo := &mockAuditRepository{}
+			calls := newMockAdminCallService(callRepo, businesses, &mockVoiceProvider{}, webhooks)
+			admin := newMockAdminService(users, businesses, sessions, callRepo, calls, webhooks, &testDoNotCallRepository{}, auditRepo)
+			auth := NewAuthService(users, businesses, newMockInvitationRepository(), sessions, testAuthConfig(), logger.New("info", "console"))
+
+			_, err := admin.ReactivateBusiness(operatorContext(), "user-operator", tt.businessID)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("ReactivateBusiness() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReactivateBusiness() error = %v", err)
+			}
+
+			if _, err := auth.Login(context.Background(), dto.LoginRequest{Email: "other@example.com", Password: "password123"}); err != nil {
+				t.Errorf("expected the login to work again, got %v", err)
+			}
+			if entry := auditRepo.entries[0]; entry.Action != entities.AuditActionBusinessReactivated || entry.Metadata["suspension_reason"] != "unpaid invoices" {
+				t.Errorf("expected the reactivation to be audited, got %+v", entry)
+			}
+		})
+	}
+}
+
+func TestAdminService_ReconcileCalls(t *testing.T) {
+	now := time.Now()
+	endedAt := now.Add(-90 * time.Minute)
+
+	stuck := func() *entities.Call {
+		call, _ := entities.NewCall("business-123", "+15551234567")
+		call.ID, call.ProviderCallID, call.CreatedAt = "call-stuck", "vapi-stuck", now.Add(-2*time.Hour)
+		return call
+	}
+	recent := func() *entities.Call {
+		call, _ := entities.NewCall("business-456", "+15551234567")
+		call.ID, call.ProviderCallID, call.CreatedAt = "call-recent", "vapi-recent", now.Add(-time.Minute)
+		return call
+	}
+	unplaced := func() *entities.Call {
+		call, _ := entities.NewCall("business-456", "+15551234567")
+		call.ID, call.CreatedAt = "call-unplaced", now.Add(-3*time.Hour)
+		return call
+	}
+
+	tests := []struct {
+		name     string
+		calls    []*entities.Call
+		wantIDs  []string
+		validate func(t *testing.T, results []dto.ReconcileResult, callRepo *mockUsageCallRepository, auditRepo *mockAuditRepository)
+	}{
+		{
+			name:    "stuck call",
+			calls:   []*entities.Call{stuck()},
+			wantIDs: []string{"call-stuck"},
+			validate: func(t *testing.T, results []dto.ReconcileResult, callRepo *mockUsageCallRepository, auditRepo *mockAuditRepository) {
+				if result := results[0]; !result.Changed || result.PreviousStatus != "initiated" || result.Status != "no_answer" {
+					t.Errorf("unexpected result %+v", result)
+				}
+				call := callRepo.calls["call-stuck"]
+				if call.Cost != 0.05 || call.EndedAt == nil || !call.EndedAt.Equal(endedAt) {
+					t.Errorf("expected the provider's cost and end time, got %+v", call)
+				}
+				entry := auditRepo.entries[len(auditRepo.entries)-1]
+				if entry.Action != entities.AuditActionCallReconciled || entry.TargetID != "call-stuck" || entry.BusinessID != "business-123" || entry.Changes["status"].After != "no_answer" {
+					t.Errorf("unexpected entry %+v", entry)
+				}
+			},
+		},
+		{
+			name:    "call never placed",
+			calls:   []*entities.Call{unplaced()},
+			wantIDs: []string{"call-unplaced"},
+			validate: func(t *testing.T, results []dto.ReconcileResult, callRepo *mockUsageCallRepository, auditRepo *mockAuditRepository) {
+				if result := results[0]; result.Error == "" || result.Changed {
+					t.Errorf("expected the unplaced call to fail, got %+v", result)
+				}
+			},
+		},
+		{
+			name:  "recent call",
+			calls: []*entities.Call{recent()},
+			validate: func(t *testing.T, results []dto.ReconcileResult, callRepo *mockUsageCallRepository, auditRepo *mockAuditRepository) {
+				if callRepo.calls["call-recent"].Status != entities.CallStatusInitiated {
+					t.Error("expected the recent call to be left alone")
+				}
+			},
+		},
+		{
+			name:    "oldest first, failures alone",
+			calls:   []*entities.Call{stuck(), recent(), unplaced()},
+			wantIDs: []string{"call-unplaced", "call-stuck"},
+			validate: func(t *testing.T, results []dto.ReconcileResult, callRepo *mockUsageCallRepository, auditRepo *mockAuditRepository) {
+				if !results[1].Changed {
+					t.Errorf("expected the failure not to stop the other call, got %+v", results[1])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockOperatorUserRepository()
+			businesses := newMockTeamBusinessRepository()
+			callRepo := &mockUsageCallRepository{testCallRepository: newTestCallRepository()}
+			for _, call := range tt.calls {
+				callRepo.calls[call.ID] = call
+			}
+			provider := &mockVoiceProvider{
+				getCallDetailsFunc: func(ctx context.Context, callID string) (*providers.CallDetails, error) {
+					return &providers.CallDetails{ID: callID, Status: "ended", Outcome: providers.CallOutcomeNoAnswer, EndedAt: &endedAt, Cost: 0.05}, nil
+				},
+			}
+			webhooks := &mockWebhookEventRepository{}
+			auditRepo := &mockAuditRepository{}
+			calls := newMockAdminCallService(callRepo, businesses, provider, webhooks)
+			admin := newMockAdminService(users, businesses, newMockSessionRepository(), callRepo, calls, webhooks, &testDoNotCallRepository{}, auditRepo)
+
+			response, err := admin.ReconcileCalls(operatorContext(), "user-operator", dto.ReconcileCallsRequest{OlderThanMinutes: 30})
+			if err != nil {
+				t.Fatalf("ReconcileCalls() error = %v", err)
+			}
+
+			var ids []string
+			for _, result := range response.Results {
+				ids = append(ids, result.CallID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Fatalf("ReconcileCalls() = %v, want %v", ids, tt.wantIDs)
+			}
+			if tt.validate != nil {
+				tt.validate(t, response.Results, callRepo, auditRepo)
+			}
+		})
+	}
+}
+
+func TestAdminService_ReconcileCall(t *testing.T) {
+	tests := []struct {
+		name        string
+		reconciled  bool
+		wantChanged bool
+	}{
+		{
+			name:        "stuck call",
+			wantChanged: true,
+		},
+		{
+			name:       "finished call",
+			reconciled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := operatorContext()
+			users := newMockOperatorUserRepository()
+			businesses := newMockTeamBusinessRepository()
+			callRepo := &mockUsageCallRepository{testCallRepository: newTestCallRepository()}
+			call, _ := entities.NewCall("business-123", "+15551234567")
+			call.ID, call.ProviderCallID = "call-stuck", "vapi-stuck"
+			callRepo.calls[call.ID] = call
+			endedAt := time.Now()
+			provider := &mockVoiceProvider{
+				getCallDetailsFunc: func(ctx context.Context, callID string) (*providers.CallDetails, error) {
+					return &providers.CallDetails{ID: callID, Status: "ended", Outcome: providers.CallOutcomeNoAnswer, EndedAt: &endedAt, Cost: 0.05}, nil
+				},
+			}
+			webhooks := &mockWebhookEventRepository{}
+			calls := newMockAdminCallService(callRepo, businesses, provider, webhooks)
+			admin := newMockAdminService(users, businesses, newMockSessionRepository(), callRepo, calls, webhooks, &testDoNotCallRepository{}, &mockAuditRepository{})
+
+			if tt.reconciled {
+				if _, err := admin.ReconcileCall(ctx, "user-operator", "call-stuck"); err != nil {
+					t.Fatalf("ReconcileCall() error = %v", err)
+				}
+			}
+
+			result, err := admin.ReconcileCall(ctx, "user-operator", "call-stuck")
+			if err != nil {
+				t.Fatalf("ReconcileCall() error = %v", err)
+			}
+			if result.Changed != tt.wantChanged {
+				t.Errorf("ReconcileCall() = %+v, want changed %v", result, tt.wantChanged)
+			}
+		})
+	}
+}
+
+func TestAdminService_WebhookLog(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(t *testing.T, admin *AdminService, webhooks *mockWebhookEventRepository) error
+		wantCode string
+	}{
+		{
+			name: "failed webhooks are listed without their payload",
+			call: func(t *testing.T, admin *AdminService, webhooks *mockWebhookEventRepository) error {
+				failed, err := admin.ListWebhookEvents(operatorContext(), "user-operator", dto.WebhookEventQuery{Status: "failed"})
+				if err == nil && (len(failed) != 1 || failed[0].ProviderCallID != "vapi-9" || failed[0].Error == "" || failed[0].Payload != nil) {
+					t.Errorf("expected the failed webhook without its payload, got %+v", failed)
+				}
+				return err
+			},
+		},
+		{
+			name: "call with its webhooks",
+			call: func(t *testing.T, admin *AdminService, webhooks *mockWebhookEventRepository) error {
+				detail, err := admin.GetCall(operatorContext(), "user-operator", "call-1")
+				if err == nil && (detail.Call.Status != "in_progress" || len(detail.WebhookEvents) != 1 || detail.WebhookEvents[0].Status != "processed") {
+					t.Errorf("unexpected call %+v", detail)
+				}
+				return err
+			},
+		},
+		{
+			name: "webhook with its payload",
+			call: func(t *testing.T, admin *AdminService, webhooks *mockWebhookEventRepository) error {
+				event, err := admin.GetWebhookEvent(operatorContext(), "user-operator", webhooks.events[0].ID)
+				if err == nil && string(event.Payload) != `{"call":"vapi-1"}` {
+					t.Errorf("expected the payload, got %s", event.Payload)
+				}
+				return err
+			},
+		},
+		{
+			name: "unknown webhook",
+			call: func(t *testing.T, admin *AdminService, webhooks *mockWebhookEventRepository) error {
+				_, err := admin.GetWebhookEvent(operatorContext(), "user-operator", "webhook-404")
+				return err
+			},
+			wantCode: domainerrors.ErrCodeNotFound,
+		},
+		{
+			name: "expired webhooks are purged",
+			call: func(t *testing.T, admin *AdminService, webhooks *mockWebhookEventRepository) error {
+				webhooks.events[0].ReceivedAt = time.Now().Add(-40 * 24 * time.Hour)
+				deleted, err := admin.PurgeWebhookLog(context.Background())
+				if err == nil && (deleted != 1 || len(webhooks.events) != 1) {
+					t.Errorf("expected the expired event to be purged, deleted %d", deleted)
+				}
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockOperatorUserRepository()
+			businesses := newMockTeamBusinessRepository()
+			callRepo := &mockUsageCallRepository{testCallRepository: newTestCallRepository()}
+			call, _ := entities.NewCall("business-123", "+15551234567")
+			call.ID, call.ProviderCallID = "call-1", "vapi-1"
+			callRepo.calls[call.ID] = call
+			webhooks := &mockWebhookEventRepository{}
+			calls := newMockAdminCallService(callRepo, businesses, newMockWebhookProvider(), webhooks)
+			admin := newMockAdminService(users, businesses, newMockSessionRepository(), callRepo, calls, webhooks, &testDoNotCallRepository{}, &mockAuditRepository{})
+
+			if _, err := calls.ProcessWebhook(context.Background(), []byte(`{"call":"vapi-1"}`), ""); err != nil {
+				t.Fatalf("ProcessWebhook() error = %v", err)
+			}
+			if _, err := calls.ProcessWebhook(context.Background(), []byte(`{"call":"vapi-9"}`), ""); err == nil {
+				t.Fatal("expected a webhook for an unknown call to fail")
+			}
+
+			if err := tt.call(t, admin, webhooks); errorCode(err) != tt.wantCode {
+				t.Errorf("error = %v, want %q", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestAdminService_GetUsage(t *testing.T) {
+	tests := []struct {
+		name     string
+		days     int
+		wantDays int
+	}{
+		{
+			name:     "default period",
+			wantDays: 30,
+		},
+		{
+			name:     "last week",
+			days:     7,
+			wantDays: 7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockOperatorUserRepository()
+			businesses := newMockTeamBusinessRepository()
+			callRepo := &mockUsageCallRepository{
+				testCallRepository: newTestCallRepository(),
+				usage: []*database.BusinessUsage{
+					{BusinessID: "business-456", BusinessName: "Other Business", CallStats: database.CallStats{TotalCalls: 3, CompletedCalls: 3, TotalDuration: 600, AverageDuration: 200, TotalCost: 1.5}},
+					{BusinessID: "business-123", BusinessName: "Test Business", CallStats: database.CallStats{TotalCalls: 1, FailedCalls: 1, TotalCost: 0.5}},
+				},
+			}
+			webhooks := &mockWebhookEventRepository{}
+			auditRepo := &mockAuditRepository{}
+			calls := newMockAdminCallService(callRepo, businesses, &mockVoiceProvider{}, webhooks)
+			admin := newMockAdminService(users, businesses, newMockSessionRepository(), callRepo, calls, webhooks, &testDoNotCallRepository{}, auditRepo)
+
+			usage, err := admin.GetUsage(operatorContext(), "user-operator", tt.days)
+			if err != nil {
+				t.Fatalf("GetUsage() error = %v", err)
+			}
+			if usage.Days != tt.wantDays || len(usage.Businesses) != 2 || usage.Businesses[0].Usage.AverageDuration != 200 {
+				t.Errorf("unexpected usage %+v", usage)
+			}
+			totals := usage.Totals
+			if totals.TotalCalls != 4 || totals.CompletedCalls != 3 || totals.FailedCalls != 1 || totals.TotalCost != 2 || totals.AverageDuration != 150 {
+				t.Errorf("unexpected totals %+v", totals)
+			}
+			if fmt.Sprint(auditActions(auditRepo.entries)) != fmt.Sprint([]string{entities.AuditActionUsageViewed}) {
+				t.Errorf("expected the usage view to be audited, got %v", auditActions(auditRepo.entries))
+			}
+		})
+	}
+}
+
+func TestAdminService_GlobalDoNotCall(t *testing.T) {
+	tests := []struct {
+		name        string
+		call        func(t *testing.T, admin *AdminService, dncRepo *testDoNotCallRepository, auditRepo *mockAuditRepository) error
+		wantCode    string
+		wantActions []string
+	}{
+		{
+			name: "number added for every business",
+			call: func(t *testing.T, admin *AdminService, dncRepo *testDoNotCallRepository, auditRepo *mockAuditRepository) error {
+				entry, err := admin.AddDoNotCall(operatorContext(), "user-operator", dto.AddDoNotCallRequest{Phone: "(212) 555-1234", Reason: "litigator"})
+				if err != nil {
+					return err
+				}
+				if !entry.Global || entry.Phone != "+12125551234" {
+					t.Errorf("expected a global entry for +12125551234, got %+v", entry)
+				}
+				listed, err := dncRepo.IsListed(context.Background(), "business-123", "+12125551234")
+				if err != nil || !listed {
+					t.Errorf("expected the number to be listed for every business, got %v, %v", listed, err)
+				}
+				if added := auditRepo.entries[0]; added.TargetID != entry.ID || added.Metadata["phone"] != "+12125551234" {
+					t.Errorf("unexpected entry %+v", added)
+				}
+				return nil
+			},
+			wantActions: []string{entities.AuditActionDoNotCallAdded},
+		},
+		{
+			name: "owner",
+			call: func(t *testing.T, admin *AdminService, dncRepo *testDoNotCallRepository, auditRepo *mockAuditRepository) error {
+				_, err := admin.AddDoNotCall(ownerContext(), "user-owner", dto.AddDoNotCallRequest{Phone: "+12125551234"})
+				return err
+			},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name: "no phone",
+			call: func(t *testing.T, admin *AdminService, dncRepo *testDoNotCallRepository, auditRepo *mockAuditRepository) error {
+				_, err := admin.AddDoNotCall(operatorContext(), "user-operator", dto.AddDoNotCallRequest{})
+				return err
+			},
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name: "only global entries are listed",
+			call: func(t *testing.T, admin *AdminService, dncRepo *testDoNotCallRepository, auditRepo *mockAuditRepository) error {
+				entries, err := admin.ListDoNotCall(operatorContext(), "user-operator", 0, 0)
+				if err == nil && (len(entries) != 1 || entries[0].ID != "dnc-global") {
+					t.Errorf("expected only the global entry, got %+v", entries)
+				}
+				return err
+			},
+			wantActions: []string{entities.AuditActionDoNotCallViewed},
+		},
+		{
+			name: "global entry removed",
+			call: func(t *testing.T, admin *AdminService, dncRepo *testDoNotCallRepository, auditRepo *mockAuditRepository) error {
+				err := admin.RemoveDoNotCall(operatorContext(), "user-operator", "dnc-global")
+				if err == nil && (len(dncRepo.entries) != 1 || dncRepo.entries[0].ID != "dnc-own") {
+					t.Errorf("expected only the business's entry to remain, got %d entries", len(dncRepo.entries))
+				}
+				return err
+			},
+			wantActions: []string{entities.AuditActionDoNotCallRemoved},
+		},
+		{
+			name: "business's own entry",
+			call: func(t *testing.T, admin *AdminService, dncRepo *testDoNotCallRepository, auditRepo *mockAuditRepository) error {
+				return admin.RemoveDoNotCall(operatorContext(), "user-operator", "dnc-own")
+			},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockOperatorUserRepository()
+			businesses := newMockTeamBusinessRepository()
+			businessID := "business-456"
+			dncRepo := &testDoNotCallRepository{entries: []*entities.DoNotCallEntry{
+				{ID: "dnc-own", BusinessID: &businessID, Phone: "+12125550000"},
+				{ID: "dnc-global", Phone: "+12125559999"},
+			}}
+			callRepo := &mockUsageCallRepository{testCallRepository: newTestCallRepository()}
+			webhooks := &mockWebhookEventRepository{}
+			auditRepo := &mockAuditRepository{}
+			calls := newMockAdminCallService(callRepo, businesses, &mockVoiceProvider{}, webhooks)
+			admin := newMockAdminService(users, businesses, newMockSessionRepository(), callRepo, calls, webhooks, dncRepo, auditRepo)
+
+			if err := tt.call(t, admin, dncRepo, auditRepo); errorCode(err) != tt.wantCode {
+				t.Fatalf("error = %v, want %q", err, tt.wantCode)
+			}
+			if fmt.Sprint(auditActions(auditRepo.entries)) != fmt.Sprint(tt.wantActions) {
+				t.Errorf("expected audit actions %v, got %v", tt.wantActions, auditActions(auditRepo.entries))
+			}
+			if tt.wantActions != nil && auditRepo.entries[0].ActorID != "user-operator" {
+				t.Errorf("unexpected entry %+v", auditRepo.entries[0])
+			}
+		})
+	}
+}
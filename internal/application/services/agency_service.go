@@ -0,0 +1,449 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+	"github.com/CallPilotReceptionist/pkg/phone"
+)
+
+// agencyPageSize is how many children are loaded at a time when an action
+// covers all of an agency's businesses
+const agencyPageSize = 100
+
+// AgencyService lets an agency create and manage the child businesses it
+// resells the receptionist to: it reports their analytics together, pushes
+// assistants to them and lets agency users act in them.
+type AgencyService struct {
+	businessRepo    database.BusinessRepository
+	userRepo        database.UserRepository
+	businessService *BusinessService
+	analytics       *AnalyticsService
+	voiceProvider   providers.VoiceProvider
+	auth            *AuthService
+	logger          *logger.Logger
+
+	audit *AuditService
+}
+
+func NewAgencyService(
+	businessRepo database.BusinessRepository,
+	userRepo database.UserRepository,
+	businessService *BusinessService,
+	analytics *AnalyticsService,
+	voiceProvider providers.VoiceProvider,
+	auth *AuthService,
+	log *logger.Logger,
+) *AgencyService {
+	return &AgencyService{
+		businessRepo:    businessRepo,
+		userRepo:        userRepo,
+		businessService: businessService,
+		analytics:       analytics,
+		voiceProvider:   voiceProvider,
+		auth:            auth,
+		logger:          log,
+	}
+}
+
+// SetAudit records the children an agency creates, updates, impersonates and
+// pushes assistants to in the audit log
+func (s *AgencyService) SetAudit(audit *AuditService) {
+	s.audit = audit
+}
+
+// ListChildren returns a page of the agency's child businesses, newest first
+func (s *AgencyService) ListChildren(ctx context.Context, agencyID, actorID string, limit, offset int) ([]*dto.BusinessResponse, error) {
+	if _, _, err := s.getAgency(ctx, agencyID, actorID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	children, err := s.businessRepo.List(ctx, database.BusinessFilter{ParentID: agencyID}, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.BusinessResponse, 0, len(children))
+	for _, child := range children {
+		responses = append(responses, mapBusinessToResponse(child))
+	}
+	return responses, nil
+}
+
+// CreateChild creates a business managed by the agency. The acting user
+// becomes an owner of it, so they can switch to it and invite its team.
+func (s *AgencyService) CreateChild(ctx context.Context, agencyID, actorID string, req dto.CreateChildBusinessRequest) (*dto.BusinessResponse, error) {
+	actor, agency, err := s.getAgency(ctx, agencyID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name == "" || req.Phone == "" {
+		return nil, errors.NewValidationError("name and phone are required")
+	}
+
+	region := req.DefaultRegion
+	if region == "" {
+		region = agency.DefaultRegion()
+	}
+	if !phone.IsSupportedRegion(region) {
+		return nil, errors.NewFieldValidationError("default_region", "unsupported phone region "+region)
+	}
+
+	businessPhone, err := normalizePhone("phone", req.Phone, region)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := entities.DefaultBusinessSettings()
+	if len(req.Settings) > 0 {
+		if settings, err = settings.Merge(req.Settings); err != nil {
+			return nil, err
+		}
+	}
+	settings.DefaultRegion = region
+	if err := normalizeSettings(&settings); err != nil {
+		return nil, err
+	}
+
+	child, err := entities.NewChildBusiness(agency, req.Name, req.Type, businessPhone, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.businessRepo.Create(ctx, child); err != nil {
+		s.logger.Error("Failed to create child business", err, map[string]interface{}{
+			"agency_id": agencyID,
+		})
+		return nil, err
+	}
+
+	membership, err := entities.NewMembership(actor.ID, child.ID, entities.UserRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SaveMembership(ctx, membership); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Child business created", map[string]interface{}{
+		"agency_id":   agencyID,
+		"business_id": child.ID,
+		"created_by":  actor.ID,
+	})
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: agencyID,
+		Action:     entities.AuditActionChildCreated,
+		TargetType: "business",
+		TargetID:   child.ID,
+		Metadata:   map[string]interface{}{"name": child.Name},
+	})
+
+	return mapBusinessToResponse(child), nil
+}
+
+// GetChild returns one of the agency's child businesses
+func (s *AgencyService) GetChild(ctx context.Context, agencyID, actorID, businessID string) (*dto.BusinessResponse, error) {
+	if _, _, err := s.getAgency(ctx, agencyID, actorID); err != nil {
+		return nil, err
+	}
+
+	child, err := s.getChild(ctx, agencyID, businessID)
+	if err != nil {
+		return nil, err
+	}
+	return mapBusinessToResponse(child), nil
+}
+
+// UpdateChild updates one of the agency's child businesses
+func (s *AgencyService) UpdateChild(ctx context.Context, agencyID, actorID, businessID string, req dto.UpdateBusinessRequest) (*dto.BusinessResponse, error) {
+	if _, _, err := s.getAgency(ctx, agencyID, actorID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getChild(ctx, agencyID, businessID); err != nil {
+		return nil, err
+	}
+
+	// The child's own log records what changed; the agency's records that
+	// it was changed from the agency
+	response, err := s.businessService.UpdateBusiness(ctx, businessID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: agencyID,
+		Action:     entities.AuditActionChildUpdated,
+		TargetType: "business",
+		TargetID:   businessID,
+	})
+	return response, nil
+}
+
+// GetAnalytics returns the overview of each child business over the last
+// days, and their totals
+func (s *AgencyService) GetAnalytics(ctx context.Context, agencyID, actorID string, days int) (*dto.AgencyAnalyticsResponse, error) {
+	if _, _, err := s.getAgency(ctx, agencyID, actorID); err != nil {
+		return nil, err
+	}
+	if days <= 0 {
+		days = 30
+	}
+
+	children, err := s.allChildren(ctx, agencyID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.AgencyAnalyticsResponse{
+		Days:       days,
+		Businesses: make([]dto.ChildAnalyticsResponse, 0, len(children)),
+	}
+	totals := &response.Totals
+	for _, child := range children {
+		overview, err := s.analytics.GetOverview(ctx, child.ID, days)
+		if err != nil {
+			return nil, err
+		}
+
+		totals.TotalCalls += overview.TotalCalls
+		totals.CompletedCalls += overview.CompletedCalls
+		totals.FailedCalls += overview.FailedCalls
+		totals.TotalDuration += overview.TotalDuration
+		totals.TotalCost += overview.TotalCost
+		totals.PendingAppointments += overview.PendingAppointments
+
+		response.Businesses = append(response.Businesses, dto.ChildAnalyticsResponse{
+			BusinessID: child.ID,
+			Name:       child.Name,
+			Overview:   *overview,
+		})
+	}
+	if totals.TotalCalls > 0 {
+		totals.AverageDuration = float64(totals.TotalDuration) / float64(totals.TotalCalls)
+	}
+
+	return response, nil
+}
+
+// Impersonate issues a short-lived token for the acting agency user to work
+// in a child business as an admin. Every impersonation is logged, and so is
+// every request made with the token.
+func (s *AgencyService) Impersonate(ctx context.Context, agencyID, actorID, businessID string) (*dto.ImpersonationResponse, error) {
+	actor, _, err := s.getAgency(ctx, agencyID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	child, err := s.getChild(ctx, agencyID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, expiresAt, err := s.auth.IssueImpersonationToken(actor, agencyID, child.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Agency user impersonating child business", map[string]interface{}{
+		"agency_id":   agencyID,
+		"business_id": child.ID,
+		"user_id":     actor.ID,
+		"expires_at":  expiresAt.Format(time.RFC3339),
+	})
+
+	// Recorded in the child so its own admins can see who acted in it
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: child.ID,
+		Action:     entities.AuditActionImpersonated,
+		TargetType: "user",
+		TargetID:   actor.ID,
+		Metadata: map[string]interface{}{
+			"agency_id":  agencyID,
+			"expires_at": expiresAt.Format(time.RFC3339),
+		},
+	})
+
+	return &dto.ImpersonationResponse{
+		AccessToken: token,
+		BusinessID:  child.ID,
+		AgencyID:    agencyID,
+		ExpiresAt:   expiresAt.Format(time.RFC3339),
+	}, nil
+}
+
+// PushAssistantTemplate creates an assistant from the template for each of
+// the chosen children, or all of them, and makes it answer their calls. A
+// failure for one child is reported in its result and does not stop the
+// others.
+func (s *AgencyService) PushAssistantTemplate(ctx context.Context, agencyID, actorID string, req dto.PushAssistantTemplateRequest) (*dto.PushAssistantTemplateResponse, error) {
+	actor, _, err := s.getAgency(ctx, agencyID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Template.Prompt == "" {
+		return nil, errors.NewFieldValidationError("template.prompt", "template prompt is required")
+	}
+
+	var children []*entities.Business
+	if len(req.BusinessIDs) == 0 {
+		children, err = s.allChildren(ctx, agencyID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Check every target before pushing to any of them
+		for _, businessID := range req.BusinessIDs {
+			child, err := s.getChild(ctx, agencyID, businessID)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+		}
+	}
+
+	response := &dto.PushAssistantTemplateResponse{
+		Results: make([]dto.AssistantPushResult, 0, len(children)),
+	}
+	for _, child := range children {
+		result := dto.AssistantPushResult{BusinessID: child.ID}
+
+		assistantID, err := s.pushAssistant(ctx, agencyID, child, req.Template)
+		if err != nil {
+			s.logger.Error("Failed to push assistant to child business", err, map[string]interface{}{
+				"agency_id":   agencyID,
+				"business_id": child.ID,
+			})
+			result.Error = err.Error()
+		} else {
+			result.AssistantID = assistantID
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	s.logger.Info("Assistant template pushed", map[string]interface{}{
+		"agency_id":  agencyID,
+		"user_id":    actor.ID,
+		"businesses": len(children),
+	})
+
+	return response, nil
+}
+
+// pushAssistant creates the child's assistant from the template and stores
+// its ID in the child's settings
+func (s *AgencyService) pushAssistant(ctx context.Context, agencyID string, child *entities.Business, template dto.AssistantTemplate) (string, error) {
+	name := template.Name
+	if name == "" {
+		name = child.Name
+	}
+
+	assistantID, err := s.voiceProvider.UpdateAssistantConfig(ctx, providers.AssistantConfig{
+		Name:         name,
+		Voice:        template.Voice,
+		Language:     template.Language,
+		Model:        template.Model,
+		Prompt:       template.Prompt,
+		FirstMessage: template.FirstMessage,
+		Metadata: map[string]interface{}{
+			"business_id": child.ID,
+			"agency_id":   agencyID,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	previousAssistantID := child.AssistantID()
+	settings := child.EffectiveSettings()
+	settings.AssistantID = assistantID
+	if err := child.UpdateSettings(settings); err != nil {
+		return "", err
+	}
+	if err := s.businessRepo.Update(ctx, child); err != nil {
+		return "", err
+	}
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: child.ID,
+		Action:     entities.AuditActionAssistantPushed,
+		TargetType: "business",
+		TargetID:   child.ID,
+		Changes: map[string]entities.AuditChange{
+			"settings.assistant_id": {Before: previousAssistantID, After: assistantID},
+		},
+		Metadata: map[string]interface{}{"agency_id": agencyID},
+	})
+
+	return assistantID, nil
+}
+
+// getAgency loads the acting user and the agency, checking they may manage
+// its children
+func (s *AgencyService) getAgency(ctx context.Context, agencyID, actorID string) (*entities.User, *entities.Business, error) {
+	actor, err := s.userRepo.GetMember(ctx, agencyID, actorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if actor == nil || !actor.IsActive() || !actor.Role.Can(entities.PermissionAgencyManage) {
+		return nil, nil, errors.NewForbiddenError("your role does not allow managing the agency's businesses")
+	}
+
+	agency, err := s.businessRepo.GetByID(ctx, agencyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if agency == nil {
+		return nil, nil, errors.NewNotFoundError("business", agencyID)
+	}
+	if !agency.CanHaveChildren() {
+		return nil, nil, errors.NewForbiddenError("a child business cannot manage other businesses")
+	}
+
+	return actor, agency, nil
+}
+
+// getChild loads a business and checks the agency manages it
+func (s *AgencyService) getChild(ctx context.Context, agencyID, businessID string) (*entities.Business, error) {
+	child, err := s.businessRepo.GetByID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if child == nil {
+		return nil, errors.NewNotFoundError("business", businessID)
+	}
+	if !child.IsChildOf(agencyID) {
+		return nil, errors.NewForbiddenError("access denied to this business")
+	}
+	return child, nil
+}
+
+// allChildren loads every child business of the agency
+func (s *AgencyService) allChildren(ctx context.Context, agencyID string) ([]*entities.Business, error) {
+	var children []*entities.Business
+	for offset := 0; ; offset += agencyPageSize {
+		page, err := s.businessRepo.List(ctx, database.BusinessFilter{ParentID: agencyID}, agencyPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, page...)
+		if len(page) < agencyPageSize {
+			return children, nil
+		}
+	}
+}
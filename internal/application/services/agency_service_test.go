@@ -0,0 +1,441 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// newMockAgencyBusinessRepository makes business-123 an agency with the
+// children child-1 and child-2. business-456 is not related.
+func newMockAgencyBusinessRepository() *mockBusinessRepository {
+	businesses := newMockTeamBusinessRepository()
+	for _, id := range []string{"child-1", "child-2"} {
+		parentID := "business-123"
+		businesses.businesses[id] = &entities.Business{
+			ID:       id,
+			Name:     "Clinic " + id,
+			Type:     "dentist",
+			ParentID: &parentID,
+			Phone:    "+15551230000",
+			Settings: testSettings(func(s *entities.BusinessSettings) { s.Timezone = "America/New_York" }),
+		}
+	}
+	return businesses
+}
+
+// newMockAgencyService returns the agency service with the auth service that
+// issues its impersonation tokens
+func newMockAgencyService(users *mockUserRepository, businesses *mockBusinessRepository, callRepo *mockStatsCallRepository, provider providers.VoiceProvider) (*AgencyService, *AuthService) {
+	log := logger.New("info", "console")
+	auth := NewAuthService(users, businesses, newMockInvitationRepository(), newMockSessionRepository(), testAuthConfig(), log)
+	analytics := NewAnalyticsService(callRepo, &testAppointmentRepository{}, log)
+	agency := NewAgencyService(businesses, users, NewBusinessService(businesses, log), analytics, provider, auth, log)
+	return agency, auth
+}
+
+func TestAgencyService_CreateChild(t *testing.T) {
+	tests := []struct {
+		name     string
+		agencyID string
+		actorID  string
+		req      dto.CreateChildBusinessRequest
+		setup    func(users *mockUserRepository)
+		wantCode string
+		validate func(t *testing.T, agency *AgencyService, users *mockUserRepository, child *dto.BusinessResponse)
+	}{
+		{
+			name:     "admin creates a child",
+			agencyID: "business-123",
+			actorID:  "user-admin",
+			req:      dto.CreateChildBusinessRequest{Name: "Uptown Dental", Type: "dentist", Phone: "(555) 987-6543"},
+			validate: func(t *testing.T, agency *AgencyService, users *mockUserRepository, child *dto.BusinessResponse) {
+				if child.ParentID == nil || *child.ParentID != "business-123" || child.Phone != "+15559876543" {
+					t.Errorf("unexpected child %+v", child)
+				}
+				// The creator can switch to the child as its owner
+				member, _ := users.GetMember(context.Background(), child.ID, "user-admin")
+				if member == nil || member.Role != entities.UserRoleOwner {
+					t.Errorf("expected the creator to own the child, got %+v", member)
+				}
+				children, err := agency.ListChildren(context.Background(), "business-123", "user-owner", 0, 0)
+				if err != nil {
+					t.Fatalf("ListChildren() error = %v", err)
+				}
+				if len(children) != 3 {
+					t.Errorf("expected 3 children, got %d", len(children))
+				}
+			},
+		},
+		{
+			name:     "employee",
+			agencyID: "business-123",
+			actorID:  "user-employee",
+			req:      dto.CreateChildBusinessRequest{Name: "Clinic", Phone: "+15559876543"},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:     "invalid phone number",
+			agencyID: "business-123",
+			actorID:  "user-admin",
+			req:      dto.CreateChildBusinessRequest{Name: "Clinic", Phone: "not a number"},
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:     "child cannot be an agency",
+			agencyID: "child-1",
+			actorID:  "user-admin",
+			req:      dto.CreateChildBusinessRequest{Name: "Grandchild", Phone: "+15559876543"},
+			setup: func(users *mockUserRepository) {
+				users.SaveMembership(context.Background(), &entities.Membership{UserID: "user-admin", BusinessID: "child-1", Role: entities.UserRoleOwner})
+			},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockTeamUserRepository()
+			if tt.setup != nil {
+				tt.setup(users)
+			}
+			agency, _ := newMockAgencyService(users, newMockAgencyBusinessRepository(), newMockStatsCallRepository(), &mockVoiceProvider{})
+
+			child, err := agency.CreateChild(context.Background(), tt.agencyID, tt.actorID, tt.req)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("CreateChild() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateChild() error = %v", err)
+			}
+			tt.validate(t, agency, users, child)
+		})
+	}
+}
+
+func TestAgencyService_GetChild(t *testing.T) {
+	tests := []struct {
+		name       string
+		actorID    string
+		businessID string
+		wantCode   string
+	}{
+		{
+			name:       "child",
+			actorID:    "user-owner",
+			businessID: "child-1",
+		},
+		{
+			name:       "unrelated business",
+			actorID:    "user-owner",
+			businessID: "business-456",
+			wantCode:   domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:       "employee",
+			actorID:    "user-employee",
+			businessID: "child-1",
+			wantCode:   domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agency, _ := newMockAgencyService(newMockTeamUserRepository(), newMockAgencyBusinessRepository(), newMockStatsCallRepository(), &mockVoiceProvider{})
+
+			child, err := agency.GetChild(context.Background(), "business-123", tt.actorID, tt.businessID)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("GetChild() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetChild() error = %v", err)
+			}
+			if child.ID != tt.businessID {
+				t.Errorf("GetChild() = %s, want %s", child.ID, tt.businessID)
+			}
+		})
+	}
+}
+
+func TestAgencyService_UpdateChild(t *testing.T) {
+	tests := []struct {
+		name       string
+		businessID string
+		wantCode   string
+	}{
+		{
+			name:       "child",
+			businessID: "child-1",
+		},
+		{
+			name:       "unrelated business",
+			businessID: "business-456",
+			wantCode:   domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			businesses := newMockAgencyBusinessRepository()
+			agency, _ := newMockAgencyService(newMockTeamUserRepository(), businesses, newMockStatsCallRepository(), &mockVoiceProvider{})
+
+			updated, err := agency.UpdateChild(context.Background(), "business-123", "user-owner", tt.businessID, dto.UpdateBusinessRequest{Name: "Renamed Clinic"})
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("UpdateChild() error = %v, want %s", err, tt.wantCode)
+				}
+				if businesses.businesses[tt.businessID].Name == "Renamed Clinic" {
+					t.Error("expected the business to be unchanged")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateChild() error = %v", err)
+			}
+			if updated.Name != "Renamed Clinic" || updated.ParentID == nil {
+				t.Errorf("unexpected child %+v", updated)
+			}
+		})
+	}
+}
+
+func TestAgencyService_GetAnalytics(t *testing.T) {
+	tests := []struct {
+		name     string
+		actorID  string
+		wantCode string
+	}{
+		{
+			name:    "owner",
+			actorID: "user-owner",
+		},
+		{
+			name:     "employee",
+			actorID:  "user-employee",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callRepo := newMockStatsCallRepository()
+			callRepo.stats["child-1"] = &database.CallStats{TotalCalls: 3, CompletedCalls: 2, FailedCalls: 1, TotalDuration: 300, AverageDuration: 100, TotalCost: 1.5}
+			callRepo.stats["child-2"] = &database.CallStats{TotalCalls: 1, CompletedCalls: 1, TotalDuration: 100, AverageDuration: 100, TotalCost: 0.5}
+			callRepo.stats["business-456"] = &database.CallStats{TotalCalls: 50}
+			agency, _ := newMockAgencyService(newMockTeamUserRepository(), newMockAgencyBusinessRepository(), callRepo, &mockVoiceProvider{})
+
+			analytics, err := agency.GetAnalytics(context.Background(), "business-123", tt.actorID, 7)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("GetAnalytics() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetAnalytics() error = %v", err)
+			}
+
+			if len(analytics.Businesses) != 2 {
+				t.Fatalf("expected 2 children, got %d", len(analytics.Businesses))
+			}
+			totals := analytics.Totals
+			if totals.TotalCalls != 4 || totals.CompletedCalls != 3 || totals.FailedCalls != 1 || totals.TotalDuration != 400 || totals.TotalCost != 2 {
+				t.Errorf("unexpected totals %+v", totals)
+			}
+			if totals.AverageDuration != 100 {
+				t.Errorf("average duration = %v, want 100", totals.AverageDuration)
+			}
+		})
+	}
+}
+
+func TestAgencyService_Impersonate(t *testing.T) {
+	tests := []struct {
+		name       string
+		actorID    string
+		businessID string
+		suspend    string
+		wantCode   string
+	}{
+		{
+			name:       "owner",
+			actorID:    "user-owner",
+			businessID: "child-1",
+		},
+		// The token has no session to revoke, so suspending either business
+		// refuses it
+		{
+			name:       "child suspended",
+			actorID:    "user-owner",
+			businessID: "child-1",
+			suspend:    "child-1",
+		},
+		{
+			name:       "agency suspended",
+			actorID:    "user-owner",
+			businessID: "child-1",
+			suspend:    "business-123",
+		},
+		{
+			name:       "employee",
+			actorID:    "user-employee",
+			businessID: "child-1",
+			wantCode:   domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:       "unrelated business",
+			actorID:    "user-owner",
+			businessID: "business-456",
+			wantCode:   domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			businesses := newMockAgencyBusinessRepository()
+			agency, auth := newMockAgencyService(newMockTeamUserRepository(), businesses, newMockStatsCallRepository(), &mockVoiceProvider{})
+
+			response, err := agency.Impersonate(ctx, "business-123", tt.actorID, tt.businessID)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("Impersonate() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Impersonate() error = %v", err)
+			}
+
+			claims, err := auth.ValidateToken(response.AccessToken)
+			if err != nil {
+				t.Fatalf("ValidateToken() error = %v", err)
+			}
+			if claims.UserID != "user-owner" || claims.BusinessID != "child-1" || claims.AgencyID != "business-123" {
+				t.Errorf("unexpected claims %+v", claims)
+			}
+			if claims.Role != string(entities.UserRoleAdmin) {
+				t.Errorf("role = %s, want admin", claims.Role)
+			}
+			if claims.SessionID != "" {
+				t.Error("expected an impersonation token to have no session to refresh")
+			}
+			if ttl := time.Until(claims.ExpiresAt.Time); ttl > impersonationTTL {
+				t.Errorf("expected a short-lived token, expires in %s", ttl)
+			}
+
+			if tt.suspend == "" {
+				if err := auth.CheckSession(ctx, claims); err != nil {
+					t.Errorf("CheckSession() error = %v", err)
+				}
+				return
+			}
+			if err := businesses.businesses[tt.suspend].Suspend("unpaid invoices", time.Now()); err != nil {
+				t.Fatalf("Suspend() error = %v", err)
+			}
+			if err := auth.CheckSession(ctx, claims); errorCode(err) != domainerrors.ErrCodeForbidden {
+				t.Errorf("expected the token to be refused while %s is suspended, got %v", tt.suspend, err)
+			}
+		})
+	}
+}
+
+func TestAgencyService_PushAssistantTemplate(t *testing.T) {
+	template := dto.AssistantTemplate{Prompt: "You are the receptionist.", FirstMessage: "Hello!"}
+
+	tests := []struct {
+		name     string
+		req      dto.PushAssistantTemplateRequest
+		wantCode string
+	}{
+		{
+			name: "every child",
+			req:  dto.PushAssistantTemplateRequest{Template: template},
+		},
+		{
+			name:     "missing prompt",
+			req:      dto.PushAssistantTemplateRequest{},
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:     "unrelated business",
+			req:      dto.PushAssistantTemplateRequest{Template: template, BusinessIDs: []string{"child-1", "business-456"}},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pushed []providers.AssistantConfig
+			provider := &mockVoiceProvider{
+				updateAssistantConfigFunc: func(ctx context.Context, config providers.AssistantConfig) (string, error) {
+					if config.Metadata["business_id"] == "child-2" {
+						return "", errors.New("provider unavailable")
+					}
+					pushed = append(pushed, config)
+					return "assistant-" + config.Metadata["business_id"].(string), nil
+				},
+			}
+			businesses := newMockAgencyBusinessRepository()
+			agency, _ := newMockAgencyService(newMockTeamUserRepository(), businesses, newMockStatsCallRepository(), provider)
+
+			response, err := agency.PushAssistantTemplate(context.Background(), "business-123", "user-owner", tt.req)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("PushAssistantTemplate() error = %v, want %s", err, tt.wantCode)
+				}
+				if len(pushed) != 0 {
+					t.Error("expected nothing to be pushed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PushAssistantTemplate() error = %v", err)
+			}
+
+			if len(response.Results) != 2 {
+				t.Fatalf("expected a result per child, got %d", len(response.Results))
+			}
+			for _, result := range response.Results {
+				switch result.BusinessID {
+				case "child-1":
+					if result.AssistantID != "assistant-child-1" || result.Error != "" {
+						t.Errorf("unexpected result %+v", result)
+					}
+				case "child-2":
+					if result.Error == "" {
+						t.Error("expected the failed push to be reported")
+					}
+				}
+			}
+			if len(pushed) != 1 || pushed[0].Name != "Clinic child-1" || pushed[0].Prompt != template.Prompt {
+				t.Errorf("unexpected pushed assistants %+v", pushed)
+			}
+
+			child := businesses.businesses["child-1"]
+			if child.AssistantID() != "assistant-child-1" {
+				t.Errorf("expected the child to answer with the new assistant, got %q", child.AssistantID())
+			}
+			if child.Timezone() != "America/New_York" {
+				t.Error("expected the child's other settings to be kept")
+			}
+			if businesses.businesses["child-2"].AssistantID() != "" {
+				t.Error("expected the failed child to be unchanged")
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// apiKeyUsageInterval limits how often a key's last-used time is written
+const apiKeyUsageInterval = time.Minute
+
+// apiKeyPrefixLength is how much of a key is kept to recognise it by
+const apiKeyPrefixLength = len(entities.APIKeyPrefix) + 8
+
+// APIKeyService manages the API keys back-office systems use instead of a
+// user's credentials, and authenticates requests made with them.
+type APIKeyService struct {
+	apiKeyRepo   database.APIKeyRepository
+	userRepo     database.UserRepository
+	businessRepo database.BusinessRepository
+	logger       *logger.Logger
+
+	audit *AuditService
+
+	now func() time.Time
+}
+
+func NewAPIKeyService(
+	apiKeyRepo database.APIKeyRepository,
+	userRepo database.UserRepository,
+	businessRepo database.BusinessRepository,
+	log *logger.Logger,
+) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo:   apiKeyRepo,
+		userRepo:     userRepo,
+		businessRepo: businessRepo,
+		logger:       log,
+		now:          time.Now,
+	}
+}
+
+// SetAudit records the keys created and revoked in the audit log
+func (s *APIKeyService) SetAudit(audit *AuditService) {
+	s.audit = audit
+}
+
+// CreateKey issues a new API key. The key itself is only returned here.
+func (s *APIKeyService) CreateKey(ctx context.Context, businessID, actorID string, req dto.CreateAPIKeyRequest) (*dto.APIKeyResponse, error) {
+	actor, err := s.getManager(ctx, businessID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := make([]entities.Permission, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		permission := entities.Permission(scope)
+		if !actor.Role.Can(permission) {
+			return nil, errors.NewForbiddenError("you cannot grant " + scope)
+		}
+		scopes = append(scopes, permission)
+	}
+
+	expiresAt, err := parseOptionalTime("expires_at", req.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	token, _, err := newOpaqueToken()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	rawKey := entities.APIKeyPrefix + token
+
+	key, err := entities.NewAPIKey(businessID, req.Name, scopes, actor.ID, rawKey[:apiKeyPrefixLength], hashToken(rawKey), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		s.logger.Error("Failed to create API key", err, map[string]interface{}{
+			"business_id": businessID,
+		})
+		return nil, err
+	}
+
+	s.logger.Info("API key created", map[string]interface{}{
+		"business_id": businessID,
+		"api_key_id":  key.ID,
+		"created_by":  actor.ID,
+		"scopes":      req.Scopes,
+	})
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionAPIKeyCreated,
+		TargetType: "api_key",
+		TargetID:   key.ID,
+		Metadata:   map[string]interface{}{"name": key.Name, "scopes": req.Scopes},
+	})
+
+	response := mapAPIKeyToResponse(key)
+	response.Key = rawKey
+	return &response, nil
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context, businessID string) ([]dto.APIKeyResponse, error) {
+	keys, err := s.apiKeyRepo.GetByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, mapAPIKeyToResponse(key))
+	}
+
+	return response, nil
+}
+
+func (s *APIKeyService) RevokeKey(ctx context.Context, businessID, actorID, id string) error {
+	actor, err := s.getManager(ctx, businessID, actorID)
+	if err != nil {
+		return err
+	}
+
+	key, err := s.apiKeyRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if key.BusinessID != businessID {
+		return errors.NewForbiddenError("access denied to this API key")
+	}
+
+	if err := key.Revoke(s.now()); err != nil {
+		return err
+	}
+
+	if err := s.apiKeyRepo.Update(ctx, key); err != nil {
+		return err
+	}
+
+	s.logger.Info("API key revoked", map[string]interface{}{
+		"business_id": businessID,
+		"api_key_id":  key.ID,
+		"revoked_by":  actor.ID,
+	})
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionAPIKeyRevoked,
+		TargetType: "api_key",
+		TargetID:   key.ID,
+	})
+
+	return nil
+}
+
+// Authenticate returns the active API key matching rawKey and records that
+// it was used. Keys of a suspended business are refused until it is
+// reactivated.
+func (s *APIKeyService) Authenticate(ctx context.Context, rawKey string) (*entities.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByHash(ctx, hashToken(rawKey))
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if key == nil || !key.IsActive(now) {
+		return nil, errors.NewUnauthorizedError("invalid or expired API key")
+	}
+
+	business, err := s.businessRepo.GetByID(ctx, key.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, errors.NewUnauthorizedError("invalid or expired API key")
+	}
+	if business.IsSuspended() {
+		return nil, errors.NewForbiddenError("business is suspended")
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyUsageInterval {
+		if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			s.logger.Error("Failed to record API key use", err, map[string]interface{}{
+				"api_key_id": key.ID,
+			})
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
+
+// getManager loads the acting user and checks they may manage API keys.
+// Requests made with an API key have no user, so keys cannot mint keys.
+func (s *APIKeyService) getManager(ctx context.Context, businessID, actorID string) (*entities.User, error) {
+	if actorID == "" {
+		return nil, errors.NewForbiddenError("API keys can only be managed by users")
+	}
+
+	actor, err := s.userRepo.GetMember(ctx, businessID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor == nil || !actor.IsActive() || !actor.Role.Can(entities.PermissionAPIKeysManage) {
+		return nil, errors.NewForbiddenError("only owners and admins can manage API keys")
+	}
+	return actor, nil
+}
+
+func mapAPIKeyToResponse(key *entities.APIKey) dto.APIKeyResponse {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	return dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		CreatedBy:  key.CreatedBy,
+		ExpiresAt:  formatOptionalTime(key.ExpiresAt),
+		LastUsedAt: formatOptionalTime(key.LastUsedAt),
+		RevokedAt:  formatOptionalTime(key.RevokedAt),
+		CreatedAt:  key.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type testAPIKeyRepository struct {
+	keys    map[string]*entities.APIKey
+	touches int
+}
+
+func newTestAPIKeyRepository() *testAPIKeyRepository {
+	return &testAPIKeyRepository{keys: make(map[string]*entities.APIKey)}
+}
+
+func (m *testAPIKeyRepository) Create(ctx context.Context, key *entities.APIKey) error {
+	key.ID = fmt.Sprintf("key-%d", len(m.keys)+1)
+	m.keys[key.ID] = key
+	return nil
+}
+
+func (m *testAPIKeyRepository) GetByID(ctx context.Context, id string) (*entities.APIKey, error) {
+	if key, ok := m.keys[id]; ok {
+		return key, nil
+	}
+	return nil, domainerrors.NewNotFoundError("API key", id)
+}
+
+func (m *testAPIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*entities.APIKey, error) {
+	for _, key := range m.keys {
+		if key.KeyHash == keyHash {
+			return key, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *testAPIKeyRepository) GetByBusinessID(ctx context.Context, businessID string) ([]*entities.APIKey, error) {
+	var result []*entities.APIKey
+	for _, key := range m.keys {
+		if key.BusinessID == businessID {
+			result = append(result, key)
+		}
+	}
+	return result, nil
+}
+
+func (m *testAPIKeyRepository) Update(ctx context.Context, key *entities.APIKey) error {
+	return nil
+}
+
+func (m *testAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	m.touches++
+	return nil
+}
+
+func newTestAPIKeyService() (*APIKeyService, *testAPIKeyRepository, *mockBusinessRepository) {
+	repo := newTestAPIKeyRepository()
+	businesses := newMockTeamBusinessRepository()
+	return NewAPIKeyService(repo, newMockTeamUserRepository(), businesses, logger.New("info", "console")), repo, businesses
+}
+
+func TestAPIKeyService_CreateKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		actorID  string
+		req      dto.CreateAPIKeyRequest
+		wantCode string
+	}{
+		{
+			name:    "admin creates a key",
+			actorID: "user-admin",
+			req:     dto.CreateAPIKeyRequest{Name: "Practice management", Scopes: []string{"calls:create", "appointments:read"}},
+		},
+		{
+			name:    "key with an expiry",
+			actorID: "user-owner",
+			req:     dto.CreateAPIKeyRequest{Name: "CRM", Scopes: []string{"calls:read"}, ExpiresAt: time.Now().Add(24 * time.Hour).Format(time.RFC3339)},
+		},
+		{
+			name:     "employee cannot create keys",
+			actorID:  "user-employee",
+			req:      dto.CreateAPIKeyRequest{Name: "CRM", Scopes: []string{"calls:read"}},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:     "requests made with an API key cannot create keys",
+			actorID:  "",
+			req:      dto.CreateAPIKeyRequest{Name: "CRM", Scopes: []string{"calls:read"}},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:     "user management cannot be granted",
+			actorID:  "user-owner",
+			req:      dto.CreateAPIKeyRequest{Name: "CRM", Scopes: []string{"users:manage"}},
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:     "at least one scope",
+			actorID:  "user-owner",
+			req:      dto.CreateAPIKeyRequest{Name: "CRM"},
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:     "expiry in the past",
+			actorID:  "user-owner",
+			req:      dto.CreateAPIKeyRequest{Name: "CRM", Scopes: []string{"calls:read"}, ExpiresAt: time.Now().Add(-time.Hour).Format(time.RFC3339)},
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, _ := newTestAPIKeyService()
+
+			response, err := service.CreateKey(context.Background(), "business-123", tt.actorID, tt.req)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Fatalf("expected %s error, got %v", tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateKey() error = %v", err)
+			}
+
+			if !strings.HasPrefix(response.Key, entities.APIKeyPrefix) || !strings.HasPrefix(response.Key, response.Prefix) {
+				t.Errorf("unexpected key %q with prefix %q", response.Key, response.Prefix)
+			}
+			if stored := repo.keys[response.ID]; stored.KeyHash != hashToken(response.Key) {
+				t.Error("expected only the key hash to be stored")
+			}
+
+			listed, err := service.ListKeys(context.Background(), "business-123")
+			if err != nil {
+				t.Fatalf("ListKeys() error = %v", err)
+			}
+			if len(listed) != 1 || listed[0].Key != "" {
+				t.Error("expected listed keys not to include the key")
+			}
+		})
+	}
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	service, repo, businesses := newTestAPIKeyService()
+	ctx := context.Background()
+
+	created, err := service.CreateKey(ctx, "business-123", "user-admin", dto.CreateAPIKeyRequest{Name: "CRM", Scopes: []string{"calls:read"}})
+	if err != nil {
+		t.Fatalf("CreateKey() error = %v", err)
+	}
+
+	key, err := service.Authenticate(ctx, created.Key)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if key.BusinessID != "business-123" || !key.HasScope(entities.PermissionCallsRead) || key.HasScope(entities.PermissionCallsCreate) {
+		t.Errorf("unexpected key %+v", key)
+	}
+	if _, err := service.Authenticate(ctx, created.Key); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if repo.touches != 1 || key.LastUsedAt == nil {
+		t.Errorf("expected last use to be recorded once within a minute, got %d writes", repo.touches)
+	}
+
+	if _, err := service.Authenticate(ctx, entities.APIKeyPrefix+"unknown"); errorCode(err) != domainerrors.ErrCodeUnauthorized {
+		t.Errorf("expected an unknown key to be rejected, got %v", err)
+	}
+
+	// Keys stop working while their business is suspended
+	business := businesses.businesses["business-123"]
+	if err := business.Suspend("unpaid invoices", time.Now()); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	if _, err := service.Authenticate(ctx, created.Key); errorCode(err) != domainerrors.ErrCodeForbidden {
+		t.Errorf("expected the key of a suspended business to be refused, got %v", err)
+	}
+	if err := business.Reactivate(time.Now()); err != nil {
+		t.Fatalf("Reactivate() error = %v", err)
+	}
+	if _, err := service.Authenticate(ctx, created.Key); err != nil {
+		t.Errorf("expected the key to work again after reactivation, got %v", err)
+	}
+
+	if err := service.RevokeKey(ctx, "business-456", "user-other", created.ID); errorCode(err) != domainerrors.ErrCodeForbidden {
+		t.Errorf("expected another business to be unable to revoke the key, got %v", err)
+	}
+	if err := service.RevokeKey(ctx, "business-123", "user-owner", created.ID); err != nil {
+		t.Fatalf("RevokeKey() error = %v", err)
+	}
+	if _, err := service.Authenticate(ctx, created.Key); errorCode(err) != domainerrors.ErrCodeUnauthorized {
+		t.Errorf("expected a revoked key to be rejected, got %v", err)
+	}
+}
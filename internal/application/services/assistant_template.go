@@ -0,0 +1,432 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+)
+
+// defaultAssistantTemplate is used for business types without a template of
+// their own
+var defaultAssistantTemplate = entities.AssistantTemplate{
+	Key:  "general",
+	Name: "General receptionist",
+	Prompt: `You are the receptionist for {{business.name}}, a {{business.type}}. Answer calls warmly and briefly, help callers with what they need and never make up information you were not given.
+
+Opening hours ({{timezone}}):
+{{hours}}
+
+Services:
+{{services}}
+
+Frequently asked questions:
+{{faqs}}
+
+Before the call ends, make sure you have:
+{{interaction_fields}}
+
+If the caller wants to book, ask which service they want and when suits them, and confirm their name and phone number. If you cannot help, offer to take a message or transfer them to a member of staff.`,
+	FirstMessage: "Thank you for calling {{business.name}}. How can I help you today?",
+	Functions:    []string{MessageToolName, TransferToolName},
+	InteractionFields: []entities.InteractionField{
+		{Name: "caller_name", Description: "The caller's full name", Required: true},
+		{Name: "callback_number", Description: "The best number to call the caller back on"},
+		{Name: "reason", Description: "Why the caller called, in a sentence", Required: true},
+	},
+}
+
+// assistantTemplates is the library of templates for common business types.
+// Their prompts and first messages use {{variables}} filled from the
+// business and its onboarding profile; variables a template does not know,
+// such as those the provider fills on each call, are left in place.
+var assistantTemplates = []entities.AssistantTemplate{
+	{
+		Key:           "dental_clinic",
+		Name:          "Dental clinic",
+		BusinessTypes: []string{"dental", "dentist", "dental clinic", "dental office", "dental practice", "orthodontist", "orthodontics"},
+		Prompt: `You are the receptionist for {{business.name}}, a dental clinic. Be calm and reassuring; many callers are anxious or in pain. Never diagnose, give medical advice or quote prices you were not given.
+
+Opening hours ({{timezone}}):
+{{hours}}
+
+Treatments we book:
+{{services}}
+
+Frequently asked questions:
+{{faqs}}
+
+Before the call ends, make sure you have:
+{{interaction_fields}}
+
+If the caller is in severe pain, has swelling or bleeding that will not stop, or has had a tooth knocked out, treat it as urgent: offer the earliest emergency appointment or transfer them to a member of staff. If they describe a medical emergency, tell them to call emergency services. Otherwise ask whether they are a new or existing patient and which treatment and time suit them, and offer to take a message for anything you cannot help with.`,
+		FirstMessage: "Thank you for calling {{business.name}}. Are you calling to book an appointment, or is there something else I can help with?",
+		Functions:    []string{MessageToolName, TransferToolName},
+		InteractionFields: []entities.InteractionField{
+			{Name: "patient_name", Description: "The patient's full name", Required: true},
+			{Name: "callback_number", Description: "The best number to reach the patient on", Required: true},
+			{Name: "new_patient", Description: "Whether the patient is new to the clinic"},
+			{Name: "reason_for_visit", Description: "The treatment wanted or the problem described", Required: true},
+			{Name: "preferred_time", Description: "When the patient would like to come in"},
+			{Name: "insurance_provider", Description: "The patient's dental insurer, if they have one"},
+		},
+		Services: []entities.OfferedService{
+			{Name: "Check-up and cleaning", DurationMinutes: 60},
+			{Name: "Emergency appointment", Description: "For pain, swelling or a broken tooth", DurationMinutes: 30},
+			{Name: "Consultation", Description: "For new patients and treatment planning", DurationMinutes: 30},
+			{Name: "Teeth whitening", DurationMinutes: 60},
+		},
+	},
+	{
+		Key:           "salon",
+		Name:          "Salon",
+		BusinessTypes: []string{"salon", "hair salon", "beauty salon", "nail salon", "barber", "barbershop", "spa"},
+		Prompt: `You are the receptionist for {{business.name}}, a salon. Be friendly and upbeat, keep calls short and never quote prices or availability you were not given.
+
+Opening hours ({{timezone}}):
+{{hours}}
+
+Services:
+{{services}}
+
+Frequently asked questions:
+{{faqs}}
+
+Before the call ends, make sure you have:
+{{interaction_fields}}
+
+When a caller wants to book, ask which service they want, whether they have a preferred stylist and when suits them. For colour treatments, mention that a patch test may be needed beforehand. If you cannot help, offer to take a message.`,
+		FirstMessage: "Hi, thanks for calling {{business.name}}! Would you like to book an appointment?",
+		Functions:    []string{MessageToolName, TransferToolName},
+		InteractionFields: []entities.InteractionField{
+			{Name: "client_name", Description: "The client's full name", Required: true},
+			{Name: "callback_number", Description: "The best number to reach the client on", Required: true},
+			{Name: "service", Description: "The service the client wants", Required: true},
+			{Name: "preferred_stylist", Description: "The stylist the client asked for, if any"},
+			{Name: "preferred_time", Description: "When the client would like to come in"},
+		},
+		Services: []entities.OfferedService{
+			{Name: "Haircut", DurationMinutes: 45},
+			{Name: "Blow-dry", DurationMinutes: 30},
+			{Name: "Colour", Description: "A patch test may be needed 48 hours before", DurationMinutes: 120},
+			{Name: "Manicure", DurationMinutes: 45},
+		},
+	},
+	{
+		Key:           "restaurant",
+		Name:          "Restaurant",
+		BusinessTypes: []string{"restaurant", "cafe", "bistro", "diner", "bar", "pub"},
+		Prompt: `You are the host answering the phone for {{business.name}}, a restaurant. Be warm and efficient and never promise a table, dish or price you were not given.
+
+Opening hours ({{timezone}}):
+{{hours}}
+
+What callers can book:
+{{services}}
+
+Frequently asked questions:
+{{faqs}}
+
+Before the call ends, make sure you have:
+{{interaction_fields}}
+
+For reservations, ask for the date, time and number of guests, and whether anyone has allergies or dietary requirements. Take large party, event and complaint calls as a message for the manager or transfer them to a member of staff.`,
+		FirstMessage: "Thank you for calling {{business.name}}. Would you like to make a reservation?",
+		Functions:    []string{MessageToolName, TransferToolName},
+		InteractionFields: []entities.InteractionField{
+			{Name: "guest_name", Description: "The name the reservation is under", Required: true},
+			{Name: "callback_number", Description: "The best number to reach the guest on", Required: true},
+			{Name: "party_size", Description: "How many guests the reservation is for"},
+			{Name: "reservation_date", Description: "The date of the reservation"},
+			{Name: "reservation_time", Description: "The time of the reservation"},
+			{Name: "special_requests", Description: "Allergies, dietary requirements or occasions mentioned"},
+		},
+		Services: []entities.OfferedService{
+			{Name: "Table reservation", DurationMinutes: 90},
+			{Name: "Private dining", Description: "For groups and events; the manager confirms", DurationMinutes: 180},
+		},
+	},
+	{
+		Key:           "auto_repair",
+		Name:          "Auto repair shop",
+		BusinessTypes: []string{"auto repair", "auto shop", "auto service", "car repair", "garage", "mechanic"},
+		Prompt: `You are the receptionist for {{business.name}}, an auto repair shop. Be practical and clear. Never diagnose a fault or quote repair costs you were not given; the technicians do that after inspecting the vehicle.
+
+Opening hours ({{timezone}}):
+{{hours}}
+
+Services:
+{{services}}
+
+Frequently asked questions:
+{{faqs}}
+
+Before the call ends, make sure you have:
+{{interaction_fields}}
+
+Ask for the vehicle's make, model and year and for a description of the problem, including any warning lights or noises. If the caller says the vehicle is unsafe to drive, advise them not to drive it and offer to transfer them to a member of staff. Otherwise offer a drop-off time or take a message.`,
+		FirstMessage: "Thanks for calling {{business.name}}. What can we help you with today?",
+		Functions:    []string{MessageToolName, TransferToolName},
+		InteractionFields: []entities.InteractionField{
+			{Name: "customer_name", Description: "The customer's full name", Required: true},
+			{Name: "callback_number", Description: "The best number to reach the customer on", Required: true},
+			{Name: "vehicle", Description: "The vehicle's make, model and year", Required: true},
+			{Name: "issue", Description: "The problem or service the customer described", Required: true},
+			{Name: "preferred_drop_off", Description: "When the customer would like to bring the vehicle in"},
+		},
+		Services: []entities.OfferedService{
+			{Name: "Oil change", DurationMinutes: 30},
+			{Name: "Diagnostic check", Description: "For warning lights and unexplained faults", DurationMinutes: 60},
+			{Name: "Brake inspection", DurationMinutes: 60},
+			{Name: "Tyre replacement", DurationMinutes: 60},
+		},
+	},
+	{
+		Key:           "law_office",
+		Name:          "Law office",
+		BusinessTypes: []string{"law office", "law firm", "lawyer", "attorney", "legal", "solicitor"},
+		Prompt: `You are the receptionist for {{business.name}}, a law office. Be professional and discreet. You must never give legal advice or say whether a caller has a case; only the attorneys can do that.
+
+Opening hours ({{timezone}}):
+{{hours}}
+
+Services:
+{{services}}
+
+Frequently asked questions:
+{{faqs}}
+
+Before the call ends, make sure you have:
+{{interaction_fields}}
+
+For new enquiries, take a brief summary of the matter and the names of the other parties involved so the office can check for conflicts of interest, and ask about any court dates or deadlines. Existing clients can leave a message for their attorney or be transferred. Remind callers not to share confidential details until an attorney has taken them on.`,
+		FirstMessage: "Thank you for calling {{business.name}}. How may I help you?",
+		Functions:    []string{MessageToolName, TransferToolName},
+		InteractionFields: []entities.InteractionField{
+			{Name: "client_name", Description: "The caller's full name", Required: true},
+			{Name: "callback_number", Description: "The best number to reach the caller on", Required: true},
+			{Name: "matter_type", Description: "The area of law, such as family, criminal or property", Required: true},
+			{Name: "summary", Description: "A brief summary of the matter"},
+			{Name: "other_parties", Description: "The names of the other parties involved, for the conflict check"},
+			{Name: "deadline", Description: "Any court date or deadline mentioned"},
+		},
+		Services: []entities.OfferedService{
+			{Name: "Initial consultation", DurationMinutes: 30},
+			{Name: "Document review", DurationMinutes: 60},
+		},
+	},
+}
+
+// assistantTemplateFor returns the template for the business type, or the
+// default template if there is none
+func assistantTemplateFor(businessType string) entities.AssistantTemplate {
+	for _, template := range assistantTemplates {
+		if template.Matches(businessType) {
+			return template
+		}
+	}
+	return defaultAssistantTemplate
+}
+
+// findAssistantTemplate returns the template with the key
+func findAssistantTemplate(key string) (entities.AssistantTemplate, bool) {
+	if key == defaultAssistantTemplate.Key {
+		return defaultAssistantTemplate, true
+	}
+	for _, template := range assistantTemplates {
+		if template.Key == key {
+			return template, true
+		}
+	}
+	return entities.AssistantTemplate{}, false
+}
+
+var templateVariable = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.]+)\s*\}\}`)
+
+// renderTemplate replaces the {{variables}} it has values for
+func renderTemplate(text string, variables map[string]string) string {
+	return templateVariable.ReplaceAllStringFunc(text, func(match string) string {
+		name := templateVariable.FindStringSubmatch(match)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// templateVariables are the values a template can use for the business. The
+// template's services stand in for the business's until it lists its own.
+func templateVariables(template entities.AssistantTemplate, business *entities.Business, profile entities.OnboardingProfile) map[string]string {
+	settings := business.EffectiveSettings()
+	services := profile.Services
+	if len(services) == 0 {
+		services = template.Services
+	}
+	return map[string]string{
+		"business.name":      business.Name,
+		"business.type":      business.Type,
+		"business.phone":     business.Phone,
+		"timezone":           settings.Timezone,
+		"hours":              formatHours(settings.Hours),
+		"services":           formatServices(services),
+		"faqs":               formatFAQs(profile.FAQs),
+		"interaction_fields": formatInteractionFields(template.InteractionFields),
+	}
+}
+
+// renderAssistantTemplate builds the business's assistant from the
+// template. Functions come from the named tools; a tool with nothing to
+// offer the business yet, such as transfers with no destinations, is left
+// out.
+func renderAssistantTemplate(ctx context.Context, template entities.AssistantTemplate, business *entities.Business, profile entities.OnboardingProfile, tools []CallTool) (providers.AssistantConfig, error) {
+	variables := templateVariables(template, business, profile)
+	config := providers.AssistantConfig{
+		Name:           business.Name,
+		Language:       business.EffectiveSettings().Locale,
+		Prompt:         renderTemplate(template.Prompt, variables),
+		FirstMessage:   renderTemplate(template.FirstMessage, variables),
+		Metadata:       map[string]interface{}{"business_id": business.ID, "template": template.Key},
+		AnalysisSchema: interactionSchema(template.InteractionFields),
+	}
+
+	for _, name := range template.Functions {
+		for _, tool := range tools {
+			if tool.Name() != name {
+				continue
+			}
+			function, err := tool.Definition(ctx, business.ID)
+			if err != nil {
+				return providers.AssistantConfig{}, fmt.Errorf("failed to describe %s: %w", name, err)
+			}
+			if function != nil {
+				config.Functions = append(config.Functions, *function)
+			}
+		}
+	}
+	return config, nil
+}
+
+// interactionAnalysisKey is where the interaction fields are reported in a
+// finished call's analysis
+const interactionAnalysisKey = "interaction"
+
+// analysedInteractionTypes are the kinds of call the analysis can report
+var analysedInteractionTypes = []entities.InteractionType{
+	entities.InteractionTypeAppointmentRequest,
+	entities.InteractionTypeQuestion,
+	entities.InteractionTypeComplaint,
+	entities.InteractionTypeInformation,
+	entities.InteractionTypeOther,
+}
+
+// interactionSchema asks the provider to extract the fields from each
+// finished call, with the kind of call, under interactionAnalysisKey
+func interactionSchema(fields []entities.InteractionField) map[string]interface{} {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	types := make([]string, 0, len(analysedInteractionTypes))
+	for _, interactionType := range analysedInteractionTypes {
+		types = append(types, string(interactionType))
+	}
+	properties := map[string]interface{}{
+		"type": map[string]interface{}{
+			"type":        "string",
+			"enum":        types,
+			"description": "What the caller mainly wanted",
+		},
+	}
+	required := []string{"type"}
+	for _, field := range fields {
+		properties[field.Name] = map[string]interface{}{
+			"type":        "string",
+			"description": field.Description,
+		}
+		if field.Required {
+			required = append(required, field.Name)
+		}
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			interactionAnalysisKey: map[string]interface{}{
+				"type":        "object",
+				"description": "The details the caller gave",
+				"properties":  properties,
+				"required":    required,
+			},
+		},
+	}
+}
+
+// weekdays in the order opening hours are read out
+var weekdays = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+func formatHours(hours entities.WeeklyHours) string {
+	lines := make([]string, 0, len(weekdays))
+	for _, day := range weekdays {
+		ranges := hours.On(day)
+		if len(ranges) == 0 {
+			lines = append(lines, day.String()+": closed")
+			continue
+		}
+		parts := make([]string, 0, len(ranges))
+		for _, r := range ranges {
+			parts = append(parts, r.Open+"-"+r.Close)
+		}
+		lines = append(lines, day.String()+": "+strings.Join(parts, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatServices(services []entities.OfferedService) string {
+	if len(services) == 0 {
+		return "None listed; take a message for questions about services."
+	}
+	lines := make([]string, 0, len(services))
+	for _, service := range services {
+		line := "- " + service.Name
+		if service.DurationMinutes > 0 {
+			line += fmt.Sprintf(" (%d minutes)", service.DurationMinutes)
+		}
+		if service.Description != "" {
+			line += ": " + service.Description
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatFAQs(faqs []entities.FAQ) string {
+	if len(faqs) == 0 {
+		return "None listed."
+	}
+	lines := make([]string, 0, len(faqs))
+	for _, faq := range faqs {
+		lines = append(lines, "Q: "+faq.Question+"\nA: "+faq.Answer)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatInteractionFields(fields []entities.InteractionField) string {
+	if len(fields) == 0 {
+		return "Nothing in particular."
+	}
+	lines := make([]string, 0, len(fields))
+	for _, field := range fields {
+		line := "- " + field.Description
+		if !field.Required {
+			line += " (if the caller offers it)"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
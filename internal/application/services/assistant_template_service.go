@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// AssistantTemplateService serves the library of assistant templates and
+// each business's changes to the template for its type. A business's
+// assistant is generated from its template at onboarding, and again when
+// the business asks after changing it.
+type AssistantTemplateService struct {
+	templateRepo   database.AssistantTemplateRepository
+	businessRepo   database.BusinessRepository
+	onboardingRepo database.OnboardingRepository
+	voiceProvider  providers.VoiceProvider
+	logger         *logger.Logger
+
+	tools []CallTool
+	audit *AuditService
+}
+
+func NewAssistantTemplateService(
+	templateRepo database.AssistantTemplateRepository,
+	businessRepo database.BusinessRepository,
+	onboardingRepo database.OnboardingRepository,
+	voiceProvider providers.VoiceProvider,
+	log *logger.Logger,
+) *AssistantTemplateService {
+	return &AssistantTemplateService{
+		templateRepo:   templateRepo,
+		businessRepo:   businessRepo,
+		onboardingRepo: onboardingRepo,
+		voiceProvider:  voiceProvider,
+		logger:         log,
+	}
+}
+
+// AddTool makes a call tool available to the templates' functions, as
+// OnboardingService.AddTool does
+func (s *AssistantTemplateService) AddTool(tool CallTool) {
+	s.tools = append(s.tools, tool)
+}
+
+// SetAudit records template changes and generated assistants in the audit
+// log
+func (s *AssistantTemplateService) SetAudit(audit *AuditService) {
+	s.audit = audit
+}
+
+// ListTemplates returns the library, the default template first
+func (s *AssistantTemplateService) ListTemplates() []dto.AssistantTemplateResponse {
+	response := []dto.AssistantTemplateResponse{mapAssistantTemplateToResponse(defaultAssistantTemplate)}
+	for _, template := range assistantTemplates {
+		response = append(response, mapAssistantTemplateToResponse(template))
+	}
+	return response
+}
+
+// Template returns the business's template: the one it picked or the one for
+// its type, with its changes applied
+func (s *AssistantTemplateService) Template(ctx context.Context, business *entities.Business) (entities.AssistantTemplate, error) {
+	override, err := s.templateRepo.GetOverride(ctx, business.ID)
+	if err != nil {
+		return entities.AssistantTemplate{}, err
+	}
+	return s.effectiveTemplate(business, override), nil
+}
+
+// GetBusinessTemplate returns the business's template and a preview of the
+// assistant it renders to
+func (s *AssistantTemplateService) GetBusinessTemplate(ctx context.Context, businessID string) (*dto.BusinessAssistantTemplateResponse, error) {
+	business, err := s.getBusiness(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	override, err := s.templateRepo.GetOverride(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	return s.businessTemplateResponse(ctx, business, override)
+}
+
+// UpdateBusinessTemplate replaces the business's changes to its template.
+// The business's assistant is not changed until it is generated again.
+func (s *AssistantTemplateService) UpdateBusinessTemplate(ctx context.Context, businessID string, req dto.UpdateAssistantTemplateRequest) (*dto.BusinessAssistantTemplateResponse, error) {
+	business, err := s.getBusiness(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.templateRepo.GetOverride(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	override := &entities.AssistantTemplateOverride{
+		BusinessID:   businessID,
+		Template:     req.Template,
+		Prompt:       req.Prompt,
+		FirstMessage: req.FirstMessage,
+		Functions:    req.Functions,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if existing != nil {
+		override.CreatedAt = existing.CreatedAt
+	}
+	if req.InteractionFields != nil {
+		override.InteractionFields = make([]entities.InteractionField, 0, len(req.InteractionFields))
+		for _, field := range req.InteractionFields {
+			override.InteractionFields = append(override.InteractionFields, entities.InteractionField{
+				Name:        field.Name,
+				Description: field.Description,
+				Required:    field.Required,
+			})
+		}
+	}
+	if req.Services != nil {
+		override.Services = make([]entities.OfferedService, 0, len(req.Services))
+		for _, service := range req.Services {
+			override.Services = append(override.Services, entities.OfferedService{
+				Name:            service.Name,
+				Description:     service.Description,
+				DurationMinutes: service.DurationMinutes,
+			})
+		}
+	}
+
+	if err := s.validateOverride(override); err != nil {
+		return nil, err
+	}
+	if err := s.templateRepo.SaveOverride(ctx, override); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionTemplateSaved,
+		TargetType: "business",
+		TargetID:   businessID,
+		Metadata:   map[string]interface{}{"template": s.effectiveTemplate(business, override).Key},
+	})
+
+	return s.businessTemplateResponse(ctx, business, override)
+}
+
+// ResetBusinessTemplate removes the business's changes, returning it to
+// the template for its type
+func (s *AssistantTemplateService) ResetBusinessTemplate(ctx context.Context, businessID string) (*dto.BusinessAssistantTemplateResponse, error) {
+	business, err := s.getBusiness(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.templateRepo.DeleteOverride(ctx, businessID); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionTemplateReset,
+		TargetType: "business",
+		TargetID:   businessID,
+	})
+
+	return s.businessTemplateResponse(ctx, business, nil)
+}
+
+// GenerateAssistant creates an assistant from the business's template and
+// makes it answer the business's calls
+func (s *AssistantTemplateService) GenerateAssistant(ctx context.Context, businessID string) (*dto.BusinessAssistantTemplateResponse, error) {
+	business, err := s.getBusiness(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	override, err := s.templateRepo.GetOverride(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := s.profile(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	template := s.effectiveTemplate(business, override)
+	config, err := renderAssistantTemplate(ctx, template, business, profile, s.tools)
+	if err != nil {
+		return nil, err
+	}
+	assistantID, err := s.voiceProvider.UpdateAssistantConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if assistantID == "" {
+		return nil, domainerrors.NewProviderError(fmt.Errorf("no assistant ID returned"), "failed to create assistant")
+	}
+
+	previousAssistantID := business.AssistantID()
+	settings := business.EffectiveSettings()
+	settings.AssistantID = assistantID
+	if err := business.UpdateSettings(settings); err != nil {
+		return nil, err
+	}
+	if err := s.businessRepo.Update(ctx, business); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Assistant generated from template", map[string]interface{}{
+		"business_id":  businessID,
+		"template":     template.Key,
+		"assistant_id": assistantID,
+	})
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionAssistantGenerated,
+		TargetType: "business",
+		TargetID:   businessID,
+		Changes: map[string]entities.AuditChange{
+			"settings.assistant_id": {Before: previousAssistantID, After: assistantID},
+		},
+		Metadata: map[string]interface{}{"template": template.Key},
+	})
+
+	return s.businessTemplateResponse(ctx, business, override)
+}
+
+// effectiveTemplate applies the override to the template it picked, or to
+// the one for the business's type
+func (s *AssistantTemplateService) effectiveTemplate(business *entities.Business, override *entities.AssistantTemplateOverride) entities.AssistantTemplate {
+	if override == nil {
+		return assistantTemplateFor(business.Type)
+	}
+	template, ok := findAssistantTemplate(override.Template)
+	if !ok {
+		template = assistantTemplateFor(business.Type)
+	}
+	return override.Apply(template)
+}
+
+// validateOverride checks the override and that the template and functions
+// it names exist
+func (s *AssistantTemplateService) validateOverride(override *entities.AssistantTemplateOverride) error {
+	if err := override.Validate(); err != nil {
+		return err
+	}
+	if override.Template != "" {
+		if _, ok := findAssistantTemplate(override.Template); !ok {
+			return domainerrors.NewFieldValidationError("template", fmt.Sprintf("unknown template %q", override.Template))
+		}
+	}
+	for i, name := range override.Functions {
+		if !s.hasTool(name) {
+			return domainerrors.NewFieldValidationError(fmt.Sprintf("functions[%d]", i), fmt.Sprintf("unknown function %q", name))
+		}
+	}
+	return nil
+}
+
+func (s *AssistantTemplateService) hasTool(name string) bool {
+	for _, tool := range s.tools {
+		if tool.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// profile returns what the business described at onboarding, so previews
+// and generated assistants list its services and FAQs
+func (s *AssistantTemplateService) profile(ctx context.Context, businessID string) (entities.OnboardingProfile, error) {
+	onboarding, err := s.onboardingRepo.Get(ctx, businessID)
+	if err != nil {
+		return entities.OnboardingProfile{}, err
+	}
+	if onboarding == nil {
+		return entities.OnboardingProfile{}, nil
+	}
+	return onboarding.Profile, nil
+}
+
+func (s *AssistantTemplateService) businessTemplateResponse(ctx context.Context, business *entities.Business, override *entities.AssistantTemplateOverride) (*dto.BusinessAssistantTemplateResponse, error) {
+	profile, err := s.profile(ctx, business.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	template := s.effectiveTemplate(business, override)
+	config, err := renderAssistantTemplate(ctx, template, business, profile, s.tools)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := dto.AssistantPreview{
+		Prompt:         config.Prompt,
+		FirstMessage:   config.FirstMessage,
+		Functions:      make([]string, 0, len(config.Functions)),
+		AnalysisSchema: config.AnalysisSchema,
+	}
+	for _, function := range config.Functions {
+		preview.Functions = append(preview.Functions, function.Name)
+	}
+
+	return &dto.BusinessAssistantTemplateResponse{
+		Template:    mapAssistantTemplateToResponse(template),
+		Overridden:  override != nil,
+		Preview:     preview,
+		AssistantID: business.AssistantID(),
+	}, nil
+}
+
+func (s *AssistantTemplateService) getBusiness(ctx context.Context, businessID string) (*entities.Business, error) {
+	business, err := s.businessRepo.GetByID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, ErrBusinessNotFound
+	}
+	return business, nil
+}
+
+func mapAssistantTemplateToResponse(template entities.AssistantTemplate) dto.AssistantTemplateResponse {
+	response := dto.AssistantTemplateResponse{
+		Key:               template.Key,
+		Name:              template.Name,
+		BusinessTypes:     template.BusinessTypes,
+		Prompt:            template.Prompt,
+		FirstMessage:      template.FirstMessage,
+		Functions:         template.Functions,
+		InteractionFields: make([]dto.InteractionField, 0, len(template.InteractionFields)),
+		Services:          make([]dto.OfferedServiceRequest, 0, len(template.Services)),
+	}
+	if response.Functions == nil {
+		response.Functions = []string{}
+	}
+	for _, field := range template.InteractionFields {
+		response.InteractionFields = append(response.InteractionFields, dto.InteractionField{
+			Name:        field.Name,
+			Description: field.Description,
+			Required:    field.Required,
+		})
+	}
+	for _, service := range template.Services {
+		response.Services = append(response.Services, dto.OfferedServiceRequest{
+			Name:            service.Name,
+			Description:     service.Description,
+			DurationMinutes: service.DurationMinutes,
+		})
+	}
+	return response
+}
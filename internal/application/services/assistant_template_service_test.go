@@ -0,0 +1,518 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// newMockSalonBusinessRepository returns business-123, the hair salon Shear
+// Joy
+func newMockSalonBusinessRepository() *mockBusinessRepository {
+	return &mockBusinessRepository{businesses: map[string]*entities.Business{
+		"business-123": {
+			ID:       "business-123",
+			Name:     "Shear Joy",
+			Type:     "Hair Salon",
+			Phone:    "+15551230000",
+			Settings: entities.DefaultBusinessSettings(),
+		},
+	}}
+}
+
+// newMockAssistantTemplateService generates assistant-generated, recording
+// the assistants it asks the provider for
+func newMockAssistantTemplateService(templates *mockAssistantTemplateRepository, businesses *mockBusinessRepository, onboardings *mockOnboardingRepository, assistants *[]providers.AssistantConfig) *AssistantTemplateService {
+	provider := &mockVoiceProvider{
+		updateAssistantConfigFunc: func(ctx context.Context, config providers.AssistantConfig) (string, error) {
+			*assistants = append(*assistants, config)
+			return "assistant-generated", nil
+		},
+	}
+
+	service := NewAssistantTemplateService(templates, businesses, onboardings, provider, logger.New("info", "console"))
+	service.AddTool(&mockCallTool{name: MessageToolName, function: &providers.Function{Name: MessageToolName}})
+	service.AddTool(&mockCallTool{name: TransferToolName, function: &providers.Function{Name: TransferToolName}})
+	return service
+}
+
+func TestAssistantTemplateFor(t *testing.T) {
+	tests := []struct {
+		businessType string
+		want         string
+	}{
+		{"dental", "dental_clinic"},
+		{"Dental Clinic", "dental_clinic"},
+		{"hair-salon", "salon"},
+		{"Barbershop", "salon"},
+		{"bistro", "restaurant"},
+		{"Auto Repair", "auto_repair"},
+		{"garage", "auto_repair"},
+		{"Law Firm", "law_office"},
+		{"florist", "general"},
+		{"", "general"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.businessType, func(t *testing.T) {
+			if got := assistantTemplateFor(tt.businessType).Key; got != tt.want {
+				t.Errorf("assistantTemplateFor(%q) = %s, want %s", tt.businessType, got, tt.want)
+			}
+		})
+	}
+}
+
+// Every template in the library renders completely and is one a business
+// could have saved as its own
+func TestAssistantTemplates_Render(t *testing.T) {
+	business := &entities.Business{ID: "business-123", Name: "Acme", Type: "test", Settings: entities.DefaultBusinessSettings()}
+	tools := []CallTool{
+		&mockCallTool{name: MessageToolName, function: &providers.Function{Name: MessageToolName}},
+		&mockCallTool{name: TransferToolName, function: &providers.Function{Name: TransferToolName}},
+	}
+
+	seen := make(map[string]bool)
+	for _, template := range append([]entities.AssistantTemplate{defaultAssistantTemplate}, assistantTemplates...) {
+		t.Run(template.Key, func(t *testing.T) {
+			if seen[template.Key] {
+				t.Errorf("template key %s is used more than once", template.Key)
+			}
+			seen[template.Key] = true
+
+			override := &entities.AssistantTemplateOverride{
+				BusinessID:        business.ID,
+				Prompt:            template.Prompt,
+				FirstMessage:      template.FirstMessage,
+				InteractionFields: template.InteractionFields,
+				Services:          template.Services,
+			}
+			if err := override.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+
+			config, err := renderAssistantTemplate(context.Background(), template, business, entities.OnboardingProfile{}, tools)
+			if err != nil {
+				t.Fatalf("renderAssistantTemplate() error = %v", err)
+			}
+			if strings.Contains(config.Prompt+config.FirstMessage, "{{") {
+				t.Errorf("expected every variable to be filled:\n%s\n%s", config.FirstMessage, config.Prompt)
+			}
+			if !strings.Contains(config.Prompt, "Acme") || !strings.Contains(config.Prompt, "Monday: ") {
+				t.Errorf("expected the business and its hours in the prompt:\n%s", config.Prompt)
+			}
+			for _, service := range template.Services {
+				if !strings.Contains(config.Prompt, "- "+service.Name) {
+					t.Errorf("expected the template's service %q in the prompt", service.Name)
+				}
+			}
+			if len(config.Functions) != len(template.Functions) {
+				t.Errorf("expected %d functions, got %d", len(template.Functions), len(config.Functions))
+			}
+
+			interaction := config.AnalysisSchema["properties"].(map[string]interface{})[interactionAnalysisKey].(map[string]interface{})
+			properties := interaction["properties"].(map[string]interface{})
+			for _, field := range template.InteractionFields {
+				if _, ok := properties[field.Name]; !ok {
+					t.Errorf("expected the analysis to extract %s", field.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestAssistantTemplateService_GetBusinessTemplate(t *testing.T) {
+	tests := []struct {
+		name           string
+		override       *entities.AssistantTemplateOverride
+		wantTemplate   string
+		wantOverridden bool
+		validate       func(t *testing.T, response *dto.BusinessAssistantTemplateResponse)
+	}{
+		{
+			name:         "business type's template",
+			wantTemplate: "salon",
+			validate: func(t *testing.T, response *dto.BusinessAssistantTemplateResponse) {
+				if !strings.HasPrefix(response.Preview.FirstMessage, "Hi, thanks for calling Shear Joy!") || !strings.Contains(response.Preview.Prompt, "- Haircut (45 minutes)") {
+					t.Errorf("expected a rendered preview, got %+v", response.Preview)
+				}
+			},
+		},
+		{
+			name:           "another template chosen",
+			override:       &entities.AssistantTemplateOverride{BusinessID: "business-123", Template: "restaurant"},
+			wantTemplate:   "restaurant",
+			wantOverridden: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assistants []providers.AssistantConfig
+			templates := newMockAssistantTemplateRepository()
+			if tt.override != nil {
+				templates.overrides["business-123"] = tt.override
+			}
+			service := newMockAssistantTemplateService(templates, newMockSalonBusinessRepository(), newMockOnboardingRepository(), &assistants)
+
+			response, err := service.GetBusinessTemplate(context.Background(), "business-123")
+			if err != nil {
+				t.Fatalf("GetBusinessTemplate() error = %v", err)
+			}
+			if response.Template.Key != tt.wantTemplate || response.Overridden != tt.wantOverridden {
+				t.Errorf("GetBusinessTemplate() = %s overridden %v, want %s overridden %v", response.Template.Key, response.Overridden, tt.wantTemplate, tt.wantOverridden)
+			}
+			if tt.validate != nil {
+				tt.validate(t, response)
+			}
+		})
+	}
+}
+
+func TestAssistantTemplateService_UpdateBusinessTemplate(t *testing.T) {
+	tests := []struct {
+		name         string
+		override     *entities.AssistantTemplateOverride
+		req          dto.UpdateAssistantTemplateRequest
+		wantTemplate string
+		validate     func(t *testing.T, response *dto.BusinessAssistantTemplateResponse)
+	}{
+		{
+			name: "changes to the business type's template",
+			req: dto.UpdateAssistantTemplateRequest{
+				FirstMessage: "Welcome to {{business.name}}, it's {{caller_name}}'s lucky day.",
+				Functions:    []string{},
+				InteractionFields: []dto.InteractionField{
+					{Name: "pet_name", Description: "The name of the dog being groomed", Required: true},
+				},
+				Services: []dto.OfferedServiceRequest{{Name: "Dog grooming", DurationMinutes: 90}},
+			},
+			wantTemplate: "salon",
+			validate: func(t *testing.T, response *dto.BusinessAssistantTemplateResponse) {
+				if response.Preview.FirstMessage != "Welcome to Shear Joy, it's {{caller_name}}'s lucky day." {
+					t.Errorf("expected the first message to be overridden, got %q", response.Preview.FirstMessage)
+				}
+				if !strings.Contains(response.Preview.Prompt, "You are the receptionist for Shear Joy, a salon") {
+					t.Errorf("expected the template's prompt to be kept:\n%s", response.Preview.Prompt)
+				}
+				if !strings.Contains(response.Preview.Prompt, "- Dog grooming (90 minutes)") || strings.Contains(response.Preview.Prompt, "Haircut") {
+					t.Errorf("expected the services to be replaced:\n%s", response.Preview.Prompt)
+				}
+				if !strings.Contains(response.Preview.Prompt, "- The name of the dog being groomed\n") {
+					t.Errorf("expected the interaction fields to be replaced:\n%s", response.Preview.Prompt)
+				}
+				if len(response.Preview.Functions) != 0 {
+					t.Errorf("expected an empty list to remove the functions, got %v", response.Preview.Functions)
+				}
+			},
+		},
+		{
+			name: "new override replaces the earlier one",
+			override: &entities.AssistantTemplateOverride{
+				BusinessID:   "business-123",
+				FirstMessage: "Welcome to {{business.name}}.",
+				Functions:    []string{},
+			},
+			req:          dto.UpdateAssistantTemplateRequest{Template: "restaurant"},
+			wantTemplate: "restaurant",
+			validate: func(t *testing.T, response *dto.BusinessAssistantTemplateResponse) {
+				if len(response.Preview.Functions) != 2 || response.Preview.FirstMessage == "Welcome to Shear Joy." {
+					t.Errorf("expected the restaurant template alone, got %+v", response.Preview)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assistants []providers.AssistantConfig
+			templates := newMockAssistantTemplateRepository()
+			if tt.override != nil {
+				templates.overrides["business-123"] = tt.override
+			}
+			service := newMockAssistantTemplateService(templates, newMockSalonBusinessRepository(), newMockOnboardingRepository(), &assistants)
+
+			response, err := service.UpdateBusinessTemplate(context.Background(), "business-123", tt.req)
+			if err != nil {
+				t.Fatalf("UpdateBusinessTemplate() error = %v", err)
+			}
+			if !response.Overridden || response.Template.Key != tt.wantTemplate {
+				t.Errorf("expected the %s template with changes, got %+v", tt.wantTemplate, response.Template)
+			}
+			if tt.validate != nil {
+				tt.validate(t, response)
+			}
+		})
+	}
+}
+
+func TestAssistantTemplateService_UpdateValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   dto.UpdateAssistantTemplateRequest
+		field string
+	}{
+		{
+			name:  "unknown template",
+			req:   dto.UpdateAssistantTemplateRequest{Template: "bakery"},
+			field: "template",
+		},
+		{
+			name:  "unknown function",
+			req:   dto.UpdateAssistantTemplateRequest{Functions: []string{MessageToolName, "book_table"}},
+			field: "functions[1]",
+		},
+		{
+			name: "interaction field name",
+			req: dto.UpdateAssistantTemplateRequest{InteractionFields: []dto.InteractionField{
+				{Name: "Party Size", Description: "How many guests"},
+			}},
+			field: "interaction_fields[0].name",
+		},
+		{
+			name: "reserved interaction field",
+			req: dto.UpdateAssistantTemplateRequest{InteractionFields: []dto.InteractionField{
+				{Name: "type", Description: "The kind of call"},
+			}},
+			field: "interaction_fields[0].name",
+		},
+		{
+			name:  "service without a name",
+			req:   dto.UpdateAssistantTemplateRequest{Services: []dto.OfferedServiceRequest{{DurationMinutes: 30}}},
+			field: "services[0].name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assistants []providers.AssistantConfig
+			templates := newMockAssistantTemplateRepository()
+			service := newMockAssistantTemplateService(templates, newMockSalonBusinessRepository(), newMockOnboardingRepository(), &assistants)
+
+			_, err := service.UpdateBusinessTemplate(context.Background(), "business-123", tt.req)
+			var domainErr *domainerrors.DomainError
+			if !errors.As(err, &domainErr) || domainErr.Field != tt.field {
+				t.Fatalf("expected a %s error, got %v", tt.field, err)
+			}
+			if len(templates.overrides) != 0 {
+				t.Error("expected nothing to be saved")
+			}
+		})
+	}
+}
+
+func TestAssistantTemplateService_ResetBusinessTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		override *entities.AssistantTemplateOverride
+	}{
+		{
+			name:     "overridden template",
+			override: &entities.AssistantTemplateOverride{BusinessID: "business-123", Template: "restaurant"},
+		},
+		{
+			name: "business type's template",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assistants []providers.AssistantConfig
+			templates := newMockAssistantTemplateRepository()
+			if tt.override != nil {
+				templates.overrides["business-123"] = tt.override
+			}
+			service := newMockAssistantTemplateService(templates, newMockSalonBusinessRepository(), newMockOnboardingRepository(), &assistants)
+
+			response, err := service.ResetBusinessTemplate(context.Background(), "business-123")
+			if err != nil {
+				t.Fatalf("ResetBusinessTemplate() error = %v", err)
+			}
+			if response.Overridden || response.Template.Key != "salon" || len(templates.overrides) != 0 {
+				t.Errorf("expected the business to return to its type's template, got %+v", response.Template)
+			}
+		})
+	}
+}
+
+func TestAssistantTemplateService_GenerateAssistant(t *testing.T) {
+	tests := []struct {
+		name       string
+		override   *entities.AssistantTemplateOverride
+		onboarding *entities.Onboarding
+		wantPrompt func(t *testing.T, prompt string)
+	}{
+		{
+			name: "overridden prompt with the onboarding profile",
+			override: &entities.AssistantTemplateOverride{
+				BusinessID: "business-123",
+				Prompt:     "You answer for {{business.name}}.\n{{services}}\n{{faqs}}",
+			},
+			onboarding: &entities.Onboarding{
+				BusinessID: "business-123",
+				Profile: entities.OnboardingProfile{
+					BusinessType: "Hair Salon",
+					Services:     []entities.OfferedService{{Name: "Balayage", DurationMinutes: 180}},
+					FAQs:         []entities.FAQ{{Question: "Do you cut children's hair?", Answer: "Yes, under 12s are half price."}},
+				},
+			},
+			wantPrompt: func(t *testing.T, prompt string) {
+				want := "You answer for Shear Joy.\n- Balayage (180 minutes)\nQ: Do you cut children's hair?\nA: Yes, under 12s are half price."
+				if prompt != want {
+					t.Errorf("expected the overridden prompt with the onboarding profile, got:\n%s", prompt)
+				}
+			},
+		},
+		{
+			name: "business type's template without onboarding",
+			wantPrompt: func(t *testing.T, prompt string) {
+				if !strings.Contains(prompt, "Shear Joy") || !strings.Contains(prompt, "- Haircut (45 minutes)") {
+					t.Errorf("expected the salon template with its own services, got:\n%s", prompt)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assistants []providers.AssistantConfig
+			templates := newMockAssistantTemplateRepository()
+			if tt.override != nil {
+				templates.overrides["business-123"] = tt.override
+			}
+			onboardings := newMockOnboardingRepository()
+			if tt.onboarding != nil {
+				onboardings.onboardings["business-123"] = tt.onboarding
+			}
+			businesses := newMockSalonBusinessRepository()
+			service := newMockAssistantTemplateService(templates, businesses, onboardings, &assistants)
+
+			response, err := service.GenerateAssistant(context.Background(), "business-123")
+			if err != nil {
+				t.Fatalf("GenerateAssistant() error = %v", err)
+			}
+			if response.AssistantID != "assistant-generated" || businesses.businesses["business-123"].AssistantID() != "assistant-generated" {
+				t.Errorf("expected the business to answer with the new assistant, got %q", response.AssistantID)
+			}
+			if len(assistants) != 1 {
+				t.Fatalf("expected one assistant, got %d", len(assistants))
+			}
+			tt.wantPrompt(t, assistants[0].Prompt)
+		})
+	}
+}
+
+// Onboarding generates the assistant from the business's changed template
+func TestOnboardingService_UsesTemplateOverride(t *testing.T) {
+	tests := []struct {
+		name             string
+		override         *entities.AssistantTemplateOverride
+		wantFirstMessage string
+		wantPrompt       string
+	}{
+		{
+			name: "overridden template",
+			override: &entities.AssistantTemplateOverride{
+				BusinessID:   "business-123",
+				Template:     "restaurant",
+				FirstMessage: "{{business.name}}, bookings desk.",
+			},
+			wantFirstMessage: "Smith Dental, bookings desk.",
+			wantPrompt:       "a restaurant",
+		},
+		{
+			name:             "business type's template",
+			wantFirstMessage: "Thank you for calling Smith Dental.",
+			wantPrompt:       "dental clinic",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assistants []providers.AssistantConfig
+			var attached []providers.PhoneNumberRequest
+			businesses := newMockOnboardingBusinessRepository()
+			onboardings := newMockOnboardingRepository()
+			service := newMockOnboardingService(onboardings, businesses, newMockOnboardingProvider(&assistants, &attached))
+
+			templateRepo := newMockAssistantTemplateRepository()
+			if tt.override != nil {
+				templateRepo.overrides["business-123"] = tt.override
+			}
+			var unused []providers.AssistantConfig
+			service.SetTemplates(newMockAssistantTemplateService(templateRepo, businesses, onboardings, &unused))
+
+			if _, err := service.Start(context.Background(), "business-123", onboardingRequest()); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			if len(assistants) != 1 {
+				t.Fatalf("expected one assistant, got %d", len(assistants))
+			}
+			assistant := assistants[0]
+			if !strings.HasPrefix(assistant.FirstMessage, tt.wantFirstMessage) || !strings.Contains(assistant.Prompt, tt.wantPrompt) {
+				t.Errorf("expected the %s template, got %q", tt.wantPrompt, assistant.FirstMessage)
+			}
+		})
+	}
+}
+
+func TestCallService_RecordsAnalysedInteraction(t *testing.T) {
+	call, _ := entities.NewInboundCall("business-123", "+15551234567", "provider-123")
+	call.ID = "call-123"
+	call.StartedAt = timePtr(time.Now().Add(-time.Minute))
+	callRepo := newTestCallRepository()
+	callRepo.calls[call.ID] = call
+	interactions := newTestInteractionRepository()
+
+	provider := &testVoiceProvider{
+		handleWebhookFunc: func(ctx context.Context, payload []byte, signature string) (*providers.CallEvent, error) {
+			return &providers.CallEvent{
+				Type:    providers.CallEventTypeEndOfCallReport,
+				CallID:  "provider-123",
+				Outcome: providers.CallOutcomeCompleted,
+				Analysis: map[string]interface{}{
+					interactionAnalysisKey: map[string]interface{}{
+						"type":             "appointment_request",
+						"guest_name":       "Ana",
+						"party_size":       "4",
+						"special_requests": "",
+					},
+				},
+			}, nil
+		},
+		getTranscriptFunc: func(ctx context.Context, callID string) (*providers.Transcript, error) {
+			return &providers.Transcript{}, nil
+		},
+	}
+
+	service := NewCallService(
+		callRepo,
+		newTestTranscriptRepository(),
+		interactions,
+		&mockBusinessRepository{businesses: make(map[string]*entities.Business)},
+		provider,
+		logger.New("info", "console"),
+	)
+
+	if err := service.HandleWebhook(context.Background(), []byte(`{}`), "valid-signature"); err != nil {
+		t.Fatalf("HandleWebhook() error = %v", err)
+	}
+
+	recorded := interactions.interactions["call-123"]
+	if len(recorded) != 1 {
+		t.Fatalf("expected one interaction, got %d", len(recorded))
+	}
+	if recorded[0].Type != entities.InteractionTypeAppointmentRequest {
+		t.Errorf("expected an appointment request, got %s", recorded[0].Type)
+	}
+	if len(recorded[0].Content) != 2 || recorded[0].Content["guest_name"] != "Ana" {
+		t.Errorf("expected only the fields the caller gave, got %+v", recorded[0].Content)
+	}
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/config"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a context whose audited actions are attributed to
+// actor
+func WithAuditActor(ctx context.Context, actor entities.AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFrom returns the actor set on ctx by WithAuditActor, if any
+func AuditActorFrom(ctx context.Context) (entities.AuditActor, bool) {
+	actor, ok := ctx.Value(auditActorKey{}).(entities.AuditActor)
+	return actor, ok
+}
+
+// auditActor returns who is acting in ctx. Actions outside a request, or
+// before anyone is identified, are the system's.
+func auditActor(ctx context.Context) entities.AuditActor {
+	actor, ok := AuditActorFrom(ctx)
+	if !ok {
+		return entities.SystemActor
+	}
+	if actor.Type == "" {
+		actor.Type = entities.AuditActorSystem
+	}
+	return actor
+}
+
+// withAuditUser attributes the actions in ctx to a user identified by the
+// request itself, such as one logging in, keeping where the request came
+// from
+func withAuditUser(ctx context.Context, userID string) context.Context {
+	actor, _ := AuditActorFrom(ctx)
+	actor.Type = entities.AuditActorUser
+	actor.ID = userID
+	return WithAuditActor(ctx, actor)
+}
+
+// AuditEvent is an action to record in the audit log
+type AuditEvent struct {
+	BusinessID string
+	Action     string
+	TargetType string
+	TargetID   string
+	Changes    map[string]entities.AuditChange
+	Metadata   map[string]interface{}
+}
+
+// AuditService records user and system actions in the append-only audit log
+// and lets admins read it. A nil AuditService records nothing, so services
+// work without one.
+type AuditService struct {
+	auditRepo database.AuditRepository
+	userRepo  database.UserRepository
+	config    config.AuditConfig
+	logger    *logger.Logger
+
+	now func() time.Time
+}
+
+func NewAuditService(
+	auditRepo database.AuditRepository,
+	userRepo database.UserRepository,
+	cfg *config.Config,
+	log *logger.Logger,
+) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+		userRepo:  userRepo,
+		config:    cfg.Audit,
+		logger:    log,
+		now:       time.Now,
+	}
+}
+
+// Record appends the event to the audit log, attributed to the actor in ctx.
+// A failure to record is logged rather than returned: the action has
+// already happened.
+func (s *AuditService) Record(ctx context.Context, event AuditEvent) {
+	if s == nil {
+		return
+	}
+
+	entry, err := entities.NewAuditEntry(event.BusinessID, auditActor(ctx), event.Action, event.TargetType, event.TargetID, event.Changes)
+	if err != nil {
+		s.logger.Error("Invalid audit entry", err, map[string]interface{}{
+			"action": event.Action,
+		})
+		return
+	}
+	entry.CreatedAt = s.now()
+	for key, value := range event.Metadata {
+		if entry.Metadata == nil {
+			entry.Metadata = make(map[string]interface{}, len(event.Metadata))
+		}
+		entry.Metadata[key] = value
+	}
+
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		s.logger.Error("Failed to record audit entry", err, map[string]interface{}{
+			"business_id": event.BusinessID,
+			"action":      event.Action,
+			"actor_type":  entry.ActorType,
+			"actor_id":    entry.ActorID,
+		})
+	}
+}
+
+// List returns a page of the business's audit log, newest first. Only owners
+// and admins can read it.
+func (s *AuditService) List(ctx context.Context, businessID, actorID string, query dto.AuditQuery) ([]dto.AuditEntryResponse, error) {
+	if actorID == "" {
+		return nil, errors.NewForbiddenError("only users can read the audit log")
+	}
+	actor, err := s.userRepo.GetMember(ctx, businessID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor == nil || !actor.IsActive() || !actor.Role.Can(entities.PermissionAuditRead) {
+		return nil, errors.NewForbiddenError("your role does not allow reading the audit log")
+	}
+
+	return s.list(ctx, businessID, query)
+}
+
+// list returns a page of the business's audit log, or of the whole
+// platform's when businessID is empty, without checking who is reading it
+func (s *AuditService) list(ctx context.Context, businessID string, query dto.AuditQuery) ([]dto.AuditEntryResponse, error) {
+	from, err := parseOptionalTime("from", query.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseOptionalTime("to", query.To)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	offset := query.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := s.auditRepo.List(ctx, businessID, database.AuditFilter{
+		ActorID:    query.ActorID,
+		Action:     query.Action,
+		TargetType: query.TargetType,
+		TargetID:   query.TargetID,
+		From:       from,
+		To:         to,
+	}, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.AuditEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, mapAuditEntryToResponse(entry))
+	}
+	return responses, nil
+}
+
+// Purge deletes the entries older than the retention period and returns how
+// many were deleted
+func (s *AuditService) Purge(ctx context.Context) (int, error) {
+	if s.config.Retention <= 0 {
+		return 0, nil
+	}
+
+	// Retention applies to every business's entries
+	deleted, err := s.auditRepo.DeleteBefore(database.Unscoped(ctx), s.now().Add(-s.config.Retention))
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		s.logger.Info("Purged audit log", map[string]interface{}{
+			"deleted":   deleted,
+			"retention": s.config.Retention.String(),
+		})
+	}
+	return deleted, nil
+}
+
+// Run purges expired entries every purge interval until the context is
+// cancelled
+func (s *AuditService) Run(ctx context.Context) {
+	interval := s.config.PurgeInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Purge(ctx); err != nil {
+				s.logger.Error("Failed to purge audit log", err, nil)
+			}
+		}
+	}
+}
+
+func mapAuditEntryToResponse(entry *entities.AuditEntry) dto.AuditEntryResponse {
+	response := dto.AuditEntryResponse{
+		ID:         entry.ID,
+		ActorType:  string(entry.ActorType),
+		ActorID:    entry.ActorID,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Metadata:   entry.Metadata,
+		IP:         entry.IP,
+		UserAgent:  entry.UserAgent,
+		CreatedAt:  entry.CreatedAt.Format(time.RFC3339),
+	}
+	if len(entry.Changes) > 0 {
+		response.Changes = make(map[string]dto.AuditChange, len(entry.Changes))
+		for field, change := range entry.Changes {
+			response.Changes[field] = dto.AuditChange{Before: change.Before, After: change.After}
+		}
+	}
+	return response
+}
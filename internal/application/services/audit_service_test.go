@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/pkg/config"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+func newMockAuditService(auditRepo *mockAuditRepository, users *mockUserRepository, retention time.Duration) *AuditService {
+	cfg := &config.Config{Audit: config.AuditConfig{Retention: retention}}
+	return NewAuditService(auditRepo, users, cfg, logger.New("info", "console"))
+}
+
+// ownerContext is a request made by the owner of business-123
+func ownerContext() context.Context {
+	return WithAuditActor(context.Background(), entities.AuditActor{
+		Type:      entities.AuditActorUser,
+		ID:        "user-owner",
+		IP:        "203.0.113.7",
+		UserAgent: "test-agent",
+	})
+}
+
+func TestAuditService_RecordsActions(t *testing.T) {
+	log := logger.New("info", "console")
+
+	tests := []struct {
+		name     string
+		act      func(t *testing.T, audit *AuditService, users *mockUserRepository, businesses *mockBusinessRepository)
+		validate func(t *testing.T, entries []*entities.AuditEntry)
+	}{
+		{
+			name: "business settings updated",
+			act: func(t *testing.T, audit *AuditService, users *mockUserRepository, businesses *mockBusinessRepository) {
+				service := NewBusinessService(businesses, log)
+				service.SetAudit(audit)
+				if _, err := service.UpdateBusiness(ownerContext(), "business-123", dto.UpdateBusinessRequest{
+					Settings: json.RawMessage(`{"timezone": "America/Chicago"}`),
+				}); err != nil {
+					t.Fatalf("UpdateBusiness() error = %v", err)
+				}
+			},
+			validate: func(t *testing.T, entries []*entities.AuditEntry) {
+				entry := entries[0]
+				if entry.Action != entities.AuditActionBusinessUpdated || entry.ActorID != "user-owner" || entry.IP != "203.0.113.7" || entry.UserAgent != "test-agent" {
+					t.Errorf("unexpected entry %+v", entry)
+				}
+				change := entry.Changes["settings.timezone"]
+				if len(entry.Changes) != 1 || change.Before != "UTC" || change.After != "America/Chicago" {
+					t.Errorf("unexpected changes %+v", entry.Changes)
+				}
+			},
+		},
+		{
+			name: "role changed",
+			act: func(t *testing.T, audit *AuditService, users *mockUserRepository, businesses *mockBusinessRepository) {
+				service := NewUserService(users, newMockInvitationRepository(), log)
+				service.SetAudit(audit)
+				if _, err := service.ChangeRole(ownerContext(), "business-123", "user-owner", "user-employee", dto.UpdateUserRoleRequest{Role: "admin"}); err != nil {
+					t.Fatalf("ChangeRole() error = %v", err)
+				}
+			},
+			validate: func(t *testing.T, entries []*entities.AuditEntry) {
+				if entry := entries[0]; entry.Action != entities.AuditActionUserRoleChanged || entry.TargetID != "user-employee" || entry.Changes["role"].After != "admin" {
+					t.Errorf("unexpected entry %+v", entry)
+				}
+			},
+		},
+		{
+			name: "appointment updated",
+			act: func(t *testing.T, audit *AuditService, users *mockUserRepository, businesses *mockBusinessRepository) {
+				appointments := &testAppointmentRepository{appointments: []*entities.AppointmentRequest{
+					{ID: "appointment-1", BusinessID: "business-123", Status: entities.AppointmentStatusPending},
+				}}
+				service := NewInteractionService(nil, appointments, nil, log)
+				service.SetAudit(audit)
+				if _, err := service.UpdateAppointmentStatus(ownerContext(), "business-123", "appointment-1", dto.UpdateAppointmentRequest{Status: "confirmed"}); err != nil {
+					t.Fatalf("UpdateAppointmentStatus() error = %v", err)
+				}
+			},
+			validate: func(t *testing.T, entries []*entities.AuditEntry) {
+				if entry := entries[0]; entry.Action != entities.AuditActionAppointmentUpdated || entry.Changes["status"].Before != "pending" || entry.Changes["status"].After != "confirmed" {
+					t.Errorf("unexpected entry %+v", entry)
+				}
+			},
+		},
+		{
+			name: "without an actor the system acted",
+			act: func(t *testing.T, audit *AuditService, users *mockUserRepository, businesses *mockBusinessRepository) {
+				audit.Record(context.Background(), AuditEvent{BusinessID: "business-123", Action: entities.AuditActionCallInitiated})
+			},
+			validate: func(t *testing.T, entries []*entities.AuditEntry) {
+				if entry := entries[0]; entry.ActorType != entities.AuditActorSystem || entry.ActorID != "" {
+					t.Errorf("expected the system to be the actor, got %+v", entry)
+				}
+			},
+		},
+		{
+			name: "auditing not configured",
+			act: func(t *testing.T, audit *AuditService, users *mockUserRepository, businesses *mockBusinessRepository) {
+				var nilAudit *AuditService
+				nilAudit.Record(ownerContext(), AuditEvent{Action: entities.AuditActionLogin})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockTeamUserRepository()
+			auditRepo := &mockAuditRepository{}
+			audit := newMockAuditService(auditRepo, users, 0)
+
+			tt.act(t, audit, users, newMockTeamBusinessRepository())
+
+			if tt.validate == nil {
+				if len(auditRepo.entries) != 0 {
+					t.Errorf("expected no entries, got %d", len(auditRepo.entries))
+				}
+				return
+			}
+			if len(auditRepo.entries) != 1 {
+				t.Fatalf("expected 1 entry, got %d", len(auditRepo.entries))
+			}
+			tt.validate(t, auditRepo.entries)
+		})
+	}
+}
+
+func TestAuditService_RecordsLogins(t *testing.T) {
+	tests := []struct {
+		name          string
+		password      string
+		wantErr       bool
+		wantAction    string
+		wantActorType entities.AuditActorType
+		wantActorID   string
+	}{
+		{
+			name:          "successful login",
+			password:      "password123",
+			wantAction:    entities.AuditActionLogin,
+			wantActorType: entities.AuditActorUser,
+			wantActorID:   "user-admin",
+		},
+		{
+			name:          "wrong password",
+			password:      "wrong",
+			wantErr:       true,
+			wantAction:    entities.AuditActionLoginFailed,
+			wantActorType: entities.AuditActorSystem,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithAuditActor(context.Background(), entities.AuditActor{IP: "198.51.100.1"})
+			users := newMockTeamUserRepository()
+			auditRepo := &mockAuditRepository{}
+			auth := NewAuthService(users, newMockTeamBusinessRepository(), newMockInvitationRepository(), newMockSessionRepository(), testAuthConfig(), logger.New("info", "console"))
+			auth.SetAudit(newMockAuditService(auditRepo, users, 0))
+
+			_, err := auth.Login(ctx, dto.LoginRequest{Email: "admin@example.com", Password: tt.password})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Login() error = %v", err)
+			}
+
+			if len(auditRepo.entries) != 1 {
+				t.Fatalf("expected 1 entry, got %d", len(auditRepo.entries))
+			}
+			entry := auditRepo.entries[0]
+			if entry.Action != tt.wantAction || entry.ActorType != tt.wantActorType || entry.ActorID != tt.wantActorID || entry.IP != "198.51.100.1" {
+				t.Errorf("unexpected entry %+v", entry)
+			}
+			if tt.wantErr && (entry.TargetID != "user-admin" || entry.Metadata["email"] != "admin@example.com") {
+				t.Errorf("expected the user and email to be recorded, got %+v", entry)
+			}
+		})
+	}
+}
+
+func TestAuditService_List(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		userID   string
+		query    dto.AuditQuery
+		wantCode string
+		wantIDs  []string
+	}{
+		{
+			name:    "business's entries newest first",
+			userID:  "user-admin",
+			wantIDs: []string{"entry-c", "entry-b", "entry-a"},
+		},
+		{
+			name:   "filtered",
+			userID: "user-admin",
+			query: dto.AuditQuery{
+				Action: entities.AuditActionLogin,
+				From:   now.Add(-150 * time.Minute).Format(time.RFC3339),
+			},
+			wantIDs: []string{"entry-c"},
+		},
+		{
+			name:    "second page",
+			userID:  "user-owner",
+			query:   dto.AuditQuery{Limit: 1, Offset: 1},
+			wantIDs: []string{"entry-b"},
+		},
+		{
+			name:     "invalid date",
+			userID:   "user-admin",
+			query:    dto.AuditQuery{From: "yesterday"},
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:     "employee",
+			userID:   "user-employee",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:     "API key",
+			userID:   "",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockTeamUserRepository()
+			auditRepo := &mockAuditRepository{}
+			for i, action := range []string{entities.AuditActionLogin, entities.AuditActionBusinessUpdated, entities.AuditActionLogin} {
+				auditRepo.entries = append(auditRepo.entries, &entities.AuditEntry{
+					ID:         "entry-" + string(rune('a'+i)),
+					BusinessID: "business-123",
+					ActorType:  entities.AuditActorUser,
+					ActorID:    "user-owner",
+					Action:     action,
+					CreatedAt:  now.Add(time.Duration(i-3) * time.Hour),
+				})
+			}
+			auditRepo.entries = append(auditRepo.entries, &entities.AuditEntry{
+				ID: "entry-other", BusinessID: "business-456", ActorType: entities.AuditActorSystem, Action: entities.AuditActionLogin, CreatedAt: now,
+			})
+			service := newMockAuditService(auditRepo, users, 0)
+
+			entries, err := service.List(ownerContext(), "business-123", tt.userID, tt.query)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("List() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+
+			var ids []string
+			for _, entry := range entries {
+				ids = append(ids, entry.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("List() = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestAuditService_Purge(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		retention   time.Duration
+		wantDeleted int
+		wantIDs     []string
+	}{
+		{
+			name:        "expired entries",
+			retention:   30 * 24 * time.Hour,
+			wantDeleted: 1,
+			wantIDs:     []string{"recent"},
+		},
+		{
+			name:    "retention of zero keeps everything",
+			wantIDs: []string{"old", "recent"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditRepo := &mockAuditRepository{entries: []*entities.AuditEntry{
+				{ID: "old", BusinessID: "business-123", CreatedAt: now.Add(-40 * 24 * time.Hour)},
+				{ID: "recent", BusinessID: "business-123", CreatedAt: now.Add(-time.Hour)},
+			}}
+			service := newMockAuditService(auditRepo, newMockTeamUserRepository(), tt.retention)
+			service.now = func() time.Time { return now }
+
+			deleted, err := service.Purge(context.Background())
+			if err != nil {
+				t.Fatalf("Purge() error = %v", err)
+			}
+
+			var ids []string
+			for _, entry := range auditRepo.entries {
+				ids = append(ids, entry.ID)
+			}
+			if deleted != tt.wantDeleted || fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("Purge() deleted %d leaving %v, want %d leaving %v", deleted, ids, tt.wantDeleted, tt.wantIDs)
+			}
+		})
+	}
+}
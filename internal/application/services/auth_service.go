@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"net"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -10,35 +11,125 @@ import (
 	"github.com/CallPilotReceptionist/internal/domain/errors"
 	"github.com/CallPilotReceptionist/internal/infrastructure/database"
 	"github.com/CallPilotReceptionist/pkg/config"
+	"github.com/CallPilotReceptionist/pkg/jwtkeys"
 	"github.com/CallPilotReceptionist/pkg/logger"
+	"github.com/CallPilotReceptionist/pkg/phone"
 )
 
 type AuthService struct {
-	userRepo     database.UserRepository
-	businessRepo database.BusinessRepository
-	config       *config.Config
-	logger       *logger.Logger
+	userRepo       database.UserRepository
+	businessRepo   database.BusinessRepository
+	invitationRepo database.UserInvitationRepository
+	sessionRepo    database.SessionRepository
+	config         *config.Config
+	logger         *logger.Logger
+
+	// accounts sends verification emails to new users when set
+	accounts *AccountService
+	// mfa adds a second step to sign-in for users with two-factor
+	// authentication when set
+	mfa *MFAService
+	// throttle slows down and locks out repeated failed logins when set
+	throttle *LoginThrottleService
+	// keys signs tokens with an asymmetric key when set; otherwise they are
+	// signed HS256 with the shared secret
+	keys *jwtkeys.KeySet
+	// audit records sign-ins and failed logins when set
+	audit *AuditService
+
+	now func() time.Time
 }
 
 func NewAuthService(
 	userRepo database.UserRepository,
 	businessRepo database.BusinessRepository,
+	invitationRepo database.UserInvitationRepository,
+	sessionRepo database.SessionRepository,
 	cfg *config.Config,
 	log *logger.Logger,
 ) *AuthService {
 	return &AuthService{
-		userRepo:     userRepo,
-		businessRepo: businessRepo,
-		config:       cfg,
-		logger:       log,
+		userRepo:       userRepo,
+		businessRepo:   businessRepo,
+		invitationRepo: invitationRepo,
+		sessionRepo:    sessionRepo,
+		config:         cfg,
+		logger:         log,
+		now:            time.Now,
 	}
 }
 
+// SetAccounts makes new users receive an email verification link
+func (s *AuthService) SetAccounts(accounts *AccountService) {
+	s.accounts = accounts
+}
+
+// SetMFA makes users with two-factor authentication enter a code to sign in
+func (s *AuthService) SetMFA(mfa *MFAService) {
+	s.mfa = mfa
+}
+
+// SetLoginThrottle protects logins against brute-force attempts
+func (s *AuthService) SetLoginThrottle(throttle *LoginThrottleService) {
+	s.throttle = throttle
+}
+
+// SetSigningKeys signs tokens with the key set's signing key and accepts
+// tokens signed by any of its keys, instead of the shared secret. Tokens the
+// shared secret signed are still accepted until JWT.SharedSecretUntil, so
+// switching to keys does not sign everyone out; their next refresh returns
+// tokens signed with the key.
+func (s *AuthService) SetSigningKeys(keys *jwtkeys.KeySet) {
+	s.keys = keys
+}
+
+// SetAudit records sign-ins, failed logins, logouts and business switches in
+// the audit log
+func (s *AuthService) SetAudit(audit *AuditService) {
+	s.audit = audit
+}
+
+// JWKS returns the public keys tokens can be verified with. It is empty
+// while tokens are signed with the shared secret.
+func (s *AuthService) JWKS() jwtkeys.JWKS {
+	if s.keys == nil {
+		return jwtkeys.JWKS{Keys: []jwtkeys.JWK{}}
+	}
+	return s.keys.JWKS()
+}
+
+const (
+	// tokenUseMFAChallenge marks a token that only proves the password was
+	// right and can only be exchanged at /auth/mfa/login
+	tokenUseMFAChallenge = "mfa_challenge"
+	// mfaChallengeTTL is how long a user has to enter their code
+	mfaChallengeTTL = 5 * time.Minute
+	// impersonationTTL is how long an agency user can act in a child business
+	// before asking again. Impersonation tokens cannot be refreshed.
+	impersonationTTL = 15 * time.Minute
+)
+
 type Claims struct {
 	UserID     string `json:"user_id"`
 	BusinessID string `json:"business_id"`
 	Email      string `json:"email"`
 	Role       string `json:"role"`
+	SessionID  string `json:"sid,omitempty"`
+	Unverified bool   `json:"unverified,omitempty"`
+	// MFASetupRequired restricts the token to setting up two-factor
+	// authentication, which the user's business requires
+	MFASetupRequired bool `json:"mfa_setup_required,omitempty"`
+	// AgencyID is set when a user of the agency is acting in one of its
+	// child businesses
+	AgencyID string `json:"agency_id,omitempty"`
+	// PlatformAdmin lets the user operate the platform across every
+	// business through the /admin routes
+	PlatformAdmin bool `json:"platform_admin,omitempty"`
+	// SSO is set on the MFA challenge of a sign-in through the business's
+	// identity provider, so the session it opens is also restricted to it
+	SSO bool `json:"sso,omitempty"`
+	// TokenUse is empty for access tokens
+	TokenUse string `json:"token_use,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -54,8 +145,23 @@ func (s *AuthService) Register(ctx context.Context, req dto.RegisterRequest) (*d
 		return nil, errors.NewAlreadyExistsError("user", "email", req.Email)
 	}
 
+	region := req.DefaultRegion
+	if region == "" {
+		region = phone.DefaultRegion
+	}
+	if !phone.IsSupportedRegion(region) {
+		return nil, errors.NewFieldValidationError("default_region", "unsupported phone region "+region)
+	}
+
+	businessPhone, err := normalizePhone("phone", req.Phone, region)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create business
-	business, err := entities.NewBusiness(req.BusinessName, req.BusinessType, req.Phone, nil)
+	settings := entities.DefaultBusinessSettings()
+	settings.DefaultRegion = region
+	business, err := entities.NewBusiness(req.BusinessName, req.BusinessType, businessPhone, settings)
 	if err != nil {
 		return nil, err
 	}
@@ -92,28 +198,10 @@ func (s *AuthService) Register(ctx context.Context, req dto.RegisterRequest) (*d
 		"email":       user.Email,
 	})
 
-	// Generate tokens
-	accessToken, err := s.generateAccessToken(user)
-	if err != nil {
-		return nil, err
-	}
-
-	refreshToken, err := s.generateRefreshToken(user)
-	if err != nil {
-		return nil, err
-	}
+	s.sendVerification(ctx, user)
 
-	return &dto.LoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User: dto.UserResponse{
-			ID:         user.ID,
-			BusinessID: user.BusinessID,
-			Email:      user.Email,
-			Role:       string(user.Role),
-			CreatedAt:  user.CreatedAt.Format(time.RFC3339),
-		},
-	}, nil
+	// Generate tokens
+	return s.signIn(ctx, user, false)
 }
 
 func (s *AuthService) Login(ctx context.Context, req dto.LoginRequest) (*dto.LoginResponse, error) {
@@ -122,17 +210,25 @@ func (s *AuthService) Login(ctx context.Context, req dto.LoginRequest) (*dto.Log
 		return nil, errors.NewValidationError("email and password are required")
 	}
 
+	ip := s.ClientIP(req.Client)
+	if err := s.checkThrottle(ctx, req.Email, ip); err != nil {
+		return nil, err
+	}
+
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
 		return nil, errors.NewUnauthorizedError("invalid credentials")
 	}
-	
+
 	// Check if user exists
 	if user == nil {
 		s.logger.Warn("Login attempt for non-existent user", map[string]interface{}{
 			"email": req.Email,
+			"ip":    ip,
 		})
+		s.recordLoginFailure(ctx, req.Email, ip)
+		s.auditLoginFailure(ctx, "", "", req.Email, "unknown email")
 		return nil, errors.NewUnauthorizedError("invalid credentials")
 	}
 
@@ -140,7 +236,19 @@ func (s *AuthService) Login(ctx context.Context, req dto.LoginRequest) (*dto.Log
 	if err := database.ComparePassword(user.PasswordHash, req.Password); err != nil {
 		s.logger.Warn("Failed login attempt", map[string]interface{}{
 			"email": req.Email,
+			"ip":    ip,
 		})
+		s.recordLoginFailure(ctx, req.Email, ip)
+		s.auditLoginFailure(ctx, user.BusinessID, user.ID, req.Email, "wrong password")
+		return nil, errors.NewUnauthorizedError("invalid credentials")
+	}
+
+	if !user.IsActive() {
+		s.logger.Warn("Login attempt for deactivated user", map[string]interface{}{
+			"user_id": user.ID,
+		})
+		s.recordLoginFailure(ctx, req.Email, ip)
+		s.auditLoginFailure(ctx, user.BusinessID, user.ID, req.Email, "user deactivated")
 		return nil, errors.NewUnauthorizedError("invalid credentials")
 	}
 
@@ -149,96 +257,827 @@ func (s *AuthService) Login(ctx context.Context, req dto.LoginRequest) (*dto.Log
 		"email":   user.Email,
 	})
 
-	// Generate tokens
-	accessToken, err := s.generateAccessToken(user)
+	response, err := s.SignInUser(ctx, user)
 	if err != nil {
 		return nil, err
 	}
+	if !response.MFARequired {
+		s.resetThrottle(ctx, user.Email)
+	}
 
-	refreshToken, err := s.generateRefreshToken(user)
-	if err != nil {
+	return response, nil
+}
+
+// SignInUser signs in a user whose password has been checked. Users with
+// two-factor authentication get an MFA challenge to exchange at
+// /auth/mfa/login instead of tokens.
+func (s *AuthService) SignInUser(ctx context.Context, user *entities.User) (*dto.LoginResponse, error) {
+	return s.signInUser(ctx, user, false)
+}
+
+// SignInWithSSO signs in a member whose identity the business's identity
+// provider has vouched for. The session is restricted to that business, as
+// the provider speaks for no other.
+func (s *AuthService) SignInWithSSO(ctx context.Context, user *entities.User) (*dto.LoginResponse, error) {
+	return s.signInUser(ctx, user, true)
+}
+
+func (s *AuthService) signInUser(ctx context.Context, user *entities.User, sso bool) (*dto.LoginResponse, error) {
+	if s.mfa != nil {
+		enabled, err := s.mfa.IsEnabled(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if enabled {
+			mfaToken, err := s.generateMFAChallenge(user, sso)
+			if err != nil {
+				return nil, err
+			}
+			return &dto.LoginResponse{
+				MFARequired: true,
+				MFAToken:    mfaToken,
+				User:        mapUserToResponse(user),
+			}, nil
+		}
+	}
+
+	// Generate tokens
+	return s.signIn(ctx, user, sso)
+}
+
+// CompleteMFALogin finishes a sign-in started by Login by exchanging the MFA
+// challenge token and a code from the authenticator app, or a recovery code,
+// for tokens
+func (s *AuthService) CompleteMFALogin(ctx context.Context, req dto.MFALoginRequest) (*dto.LoginResponse, error) {
+	if req.MFAToken == "" || req.Code == "" {
+		return nil, errors.NewValidationError("mfa token and code are required")
+	}
+	if s.mfa == nil {
+		return nil, errors.NewUnauthorizedError("invalid or expired mfa token")
+	}
+
+	claims, err := s.validateToken(req.MFAToken)
+	if err != nil || claims.TokenUse != tokenUseMFAChallenge {
+		return nil, errors.NewUnauthorizedError("invalid or expired mfa token")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil || user == nil || !user.IsActive() {
+		return nil, errors.NewUnauthorizedError("invalid or expired mfa token")
+	}
+	// The challenge is scoped to the business the sign-in started for
+	user, err = s.getMember(ctx, user, claims.BusinessID)
+	if err != nil || user == nil {
+		return nil, errors.NewUnauthorizedError("invalid or expired mfa token")
+	}
+
+	ip := s.ClientIP(req.Client)
+	if err := s.checkThrottle(ctx, user.Email, ip); err != nil {
 		return nil, err
 	}
 
-	return &dto.LoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		User: dto.UserResponse{
-			ID:         user.ID,
-			BusinessID: user.BusinessID,
-			Email:      user.Email,
-			Role:       string(user.Role),
-			CreatedAt:  user.CreatedAt.Format(time.RFC3339),
-		},
-	}, nil
+	if err := s.mfa.VerifyCode(ctx, user.ID, req.Code); err != nil {
+		s.logger.Warn("Failed two-factor login attempt", map[string]interface{}{
+			"user_id": user.ID,
+			"ip":      ip,
+		})
+		s.recordLoginFailure(ctx, user.Email, ip)
+		s.auditLoginFailure(ctx, user.BusinessID, user.ID, user.Email, "wrong two-factor code")
+		return nil, err
+	}
+	s.resetThrottle(ctx, user.Email)
+
+	s.logger.Info("User completed two-factor login", map[string]interface{}{
+		"user_id": user.ID,
+	})
+
+	return s.signIn(ctx, user, claims.SSO)
 }
 
+// RefreshToken exchanges a refresh token for a new access token and a new
+// refresh token. The presented token is revoked; presenting it again revokes
+// every session descended from the same sign-in.
 func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*dto.RefreshTokenResponse, error) {
-	// Parse and validate refresh token
-	claims, err := s.validateToken(refreshToken)
+	if refreshToken == "" {
+		return nil, errors.NewUnauthorizedError("invalid refresh token")
+	}
+
+	session, err := s.sessionRepo.GetByTokenHash(ctx, hashToken(refreshToken))
 	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, errors.NewUnauthorizedError("invalid refresh token")
+	}
+
+	now := s.now()
+	if session.IsRevoked() {
+		s.revokeFamily(ctx, session, "refresh token reused")
 		return nil, errors.NewUnauthorizedError("invalid refresh token")
 	}
+	if !session.IsActive(now) {
+		return nil, errors.NewUnauthorizedError("refresh token expired")
+	}
 
 	// Get user
-	user, err := s.userRepo.GetByID(ctx, claims.UserID)
-	if err != nil {
+	user, err := s.userRepo.GetByID(ctx, session.UserID)
+	if err != nil || user == nil {
 		return nil, errors.NewUnauthorizedError("user not found")
 	}
+	if !user.IsActive() {
+		return nil, errors.NewUnauthorizedError("user is deactivated")
+	}
 
-	// Generate new access token
-	accessToken, err := s.generateAccessToken(user)
+	// The new tokens stay scoped to the business the session was for
+	user, err = s.getMember(ctx, user, session.BusinessID)
 	if err != nil {
 		return nil, err
 	}
+	if user == nil {
+		return nil, errors.NewUnauthorizedError("no longer a member of this business")
+	}
+
+	next, err := s.startSession(ctx, user, session.FamilyID, session.SSO)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.sessionRepo.Revoke(ctx, session.ID, &next.session.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		// Another refresh with the same token won the race
+		s.revokeFamily(ctx, session, "refresh token reused concurrently")
+		return nil, errors.NewUnauthorizedError("invalid refresh token")
+	}
 
 	return &dto.RefreshTokenResponse{
-		AccessToken: accessToken,
+		AccessToken:  next.accessToken,
+		RefreshToken: next.refreshToken,
+	}, nil
+}
+
+// Logout revokes the session the access token was issued for
+func (s *AuthService) Logout(ctx context.Context, userID, sessionID string) error {
+	if sessionID == "" {
+		// Tokens issued before sessions were tracked have nothing to revoke
+		return nil
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return errors.NewForbiddenError("access denied to this session")
+	}
+
+	if _, err := s.sessionRepo.Revoke(ctx, session.ID, nil, s.now()); err != nil {
+		return err
+	}
+
+	s.logger.Info("User logged out", map[string]interface{}{
+		"user_id":    userID,
+		"session_id": session.ID,
+	})
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: session.BusinessID,
+		Action:     entities.AuditActionLogout,
+		TargetType: "session",
+		TargetID:   session.ID,
+	})
+
+	return nil
+}
+
+// LogoutAll revokes every session of the user and returns how many were
+// revoked
+func (s *AuthService) LogoutAll(ctx context.Context, userID string) (int, error) {
+	if userID == "" {
+		return 0, errors.NewForbiddenError("only users can log out")
+	}
+
+	revoked, err := s.sessionRepo.RevokeAllForUser(ctx, userID, s.now())
+	if err != nil {
+		s.logger.Error("Failed to revoke sessions", err, map[string]interface{}{
+			"user_id": userID,
+		})
+		return 0, err
+	}
+
+	s.logger.Info("User logged out everywhere", map[string]interface{}{
+		"user_id":  userID,
+		"sessions": revoked,
+	})
+
+	// Sessions in every business were revoked; the user's home business
+	// keeps the record
+	businessID := ""
+	if user, err := s.userRepo.GetByID(ctx, userID); err == nil && user != nil {
+		businessID = user.BusinessID
+	}
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionLogoutAll,
+		TargetType: "user",
+		TargetID:   userID,
+		Metadata:   map[string]interface{}{"sessions": revoked},
+	})
+
+	return revoked, nil
+}
+
+// ListBusinesses returns the businesses the user belongs to, marking the one
+// activeBusinessID names
+func (s *AuthService) ListBusinesses(ctx context.Context, userID, activeBusinessID string) ([]dto.BusinessMembershipResponse, error) {
+	if userID == "" {
+		return nil, errors.NewForbiddenError("only users belong to businesses")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.NewNotFoundError("user", userID)
+	}
+
+	memberships, err := s.userRepo.GetMemberships(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.BusinessMembershipResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		business, err := s.businessRepo.GetByID(ctx, membership.BusinessID)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, dto.BusinessMembershipResponse{
+			BusinessID: membership.BusinessID,
+			Name:       business.Name,
+			Role:       string(membership.Role),
+			Home:       membership.BusinessID == user.BusinessID,
+			Active:     membership.BusinessID == activeBusinessID,
+		})
+	}
+
+	return responses, nil
+}
+
+// SwitchBusiness issues tokens scoped to another business the user belongs
+// to. The current session is replaced, so its refresh token stops working.
+func (s *AuthService) SwitchBusiness(ctx context.Context, userID, sessionID string, req dto.SwitchBusinessRequest) (*dto.LoginResponse, error) {
+	if userID == "" {
+		return nil, errors.NewForbiddenError("only users can switch business")
+	}
+	if req.BusinessID == "" {
+		return nil, errors.NewFieldValidationError("business_id", "business_id is required")
+	}
+
+	member, err := s.userRepo.GetMember(ctx, req.BusinessID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errors.NewForbiddenError("you are not a member of this business")
+	}
+	if !member.IsActive() {
+		return nil, errors.NewUnauthorizedError("user is deactivated")
+	}
+
+	var current *entities.Session
+	familyID := ""
+	if sessionID != "" {
+		current, err = s.sessionRepo.GetByID(ctx, sessionID)
+		if err != nil {
+			return nil, err
+		}
+		if current.UserID != userID {
+			return nil, errors.NewForbiddenError("access denied to this session")
+		}
+		if current.SSO {
+			return nil, errors.NewForbiddenError("sessions opened with single sign-on cannot switch business")
+		}
+		familyID = current.FamilyID
+	}
+
+	issued, err := s.startSession(ctx, member, familyID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if current != nil {
+		if _, err := s.sessionRepo.Revoke(ctx, current.ID, &issued.session.ID, s.now()); err != nil {
+			return nil, err
+		}
+	}
+
+	s.logger.Info("User switched business", map[string]interface{}{
+		"user_id":     userID,
+		"business_id": member.BusinessID,
+		"role":        member.Role,
+	})
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: member.BusinessID,
+		Action:     entities.AuditActionBusinessSwitched,
+		TargetType: "session",
+		TargetID:   issued.session.ID,
+	})
+
+	return &dto.LoginResponse{
+		AccessToken:      issued.accessToken,
+		RefreshToken:     issued.refreshToken,
+		MFASetupRequired: issued.mfaSetupRequired,
+		User:             mapUserToResponse(member),
 	}, nil
 }
 
+// AcceptInvitation creates the invited user with the chosen password and
+// signs them in. Someone who already has an account joins the business with
+// their existing password and is signed in to it.
+func (s *AuthService) AcceptInvitation(ctx context.Context, req dto.AcceptInvitationRequest) (*dto.LoginResponse, error) {
+	if req.Token == "" || req.Password == "" {
+		return nil, errors.NewValidationError("token and password are required")
+	}
+
+	invitation, err := s.invitationRepo.GetByTokenHash(ctx, hashToken(req.Token))
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if invitation == nil || !invitation.IsPending(now) {
+		return nil, errors.NewUnauthorizedError("invalid or expired invitation")
+	}
+
+	existingUser, err := s.userRepo.GetByEmail(ctx, invitation.Email)
+	if err == nil && existingUser != nil {
+		return s.joinBusiness(ctx, existingUser, invitation, req.Password)
+	}
+
+	hashedPassword, err := database.HashPassword(req.Password)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	user, err := entities.NewUser(invitation.BusinessID, invitation.Email, hashedPassword, invitation.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := invitation.Accept(now); err != nil {
+		return nil, err
+	}
+	if err := s.invitationRepo.Update(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		s.logger.Error("Failed to create invited user", err, map[string]interface{}{
+			"invitation_id": invitation.ID,
+		})
+		return nil, err
+	}
+
+	s.logger.Info("Invitation accepted", map[string]interface{}{
+		"user_id":       user.ID,
+		"business_id":   user.BusinessID,
+		"invitation_id": invitation.ID,
+	})
+
+	s.auditInvitationAccepted(ctx, user.ID, invitation)
+
+	s.sendVerification(ctx, user)
+
+	return s.signIn(ctx, user, false)
+}
+
+// joinBusiness adds an existing account to the invitation's business once
+// the account's password has been checked
+func (s *AuthService) joinBusiness(ctx context.Context, user *entities.User, invitation *entities.UserInvitation, password string) (*dto.LoginResponse, error) {
+	if !user.IsActive() {
+		return nil, errors.NewUnauthorizedError("user is deactivated")
+	}
+	if err := database.ComparePassword(user.PasswordHash, password); err != nil {
+		return nil, errors.NewUnauthorizedError("invalid credentials")
+	}
+
+	member, err := s.userRepo.GetMember(ctx, invitation.BusinessID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if member != nil {
+		return nil, errors.NewAlreadyExistsError("user", "email", invitation.Email)
+	}
+
+	membership, err := entities.NewMembership(user.ID, invitation.BusinessID, invitation.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := invitation.Accept(s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.invitationRepo.Update(ctx, invitation); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SaveMembership(ctx, membership); err != nil {
+		s.logger.Error("Failed to add member", err, map[string]interface{}{
+			"invitation_id": invitation.ID,
+		})
+		return nil, err
+	}
+
+	s.logger.Info("Invitation accepted by existing user", map[string]interface{}{
+		"user_id":       user.ID,
+		"business_id":   invitation.BusinessID,
+		"invitation_id": invitation.ID,
+	})
+
+	s.auditInvitationAccepted(ctx, user.ID, invitation)
+
+	return s.signIn(ctx, user.AsMember(membership), false)
+}
+
+// getMember returns user with their role in businessID. It returns nil if
+// they are no longer a member.
+func (s *AuthService) getMember(ctx context.Context, user *entities.User, businessID string) (*entities.User, error) {
+	if businessID == "" || businessID == user.BusinessID {
+		return user, nil
+	}
+	return s.userRepo.GetMember(ctx, businessID, user.ID)
+}
+
+// ValidateToken validates an access token. Other tokens, such as MFA
+// challenge tokens, are rejected.
 func (s *AuthService) ValidateToken(tokenString string) (*Claims, error) {
-	return s.validateToken(tokenString)
+	claims, err := s.validateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenUse != "" {
+		return nil, errors.NewUnauthorizedError("invalid token")
+	}
+	return claims, nil
 }
 
-func (s *AuthService) generateAccessToken(user *entities.User) (string, error) {
-	claims := &Claims{
-		UserID:     user.ID,
+// CheckSession checks that the session an access token was issued for is
+// still signed in and that its business is not suspended. Logging out,
+// refreshing, switching business and suspension revoke the session, and its
+// access tokens with it. Tokens without a session, such as impersonation
+// tokens, cannot be revoked, so for them only the suspension of the business
+// or of the impersonating agency is checked.
+func (s *AuthService) CheckSession(ctx context.Context, claims *Claims) error {
+	if err := s.checkNotSuspended(ctx, claims.BusinessID); err != nil {
+		return err
+	}
+	if claims.AgencyID != "" {
+		if err := s.checkNotSuspended(ctx, claims.AgencyID); err != nil {
+			return err
+		}
+	}
+
+	if claims.SessionID == "" {
+		return nil
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, claims.SessionID)
+	if err != nil {
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeNotFound {
+			return errors.NewUnauthorizedError("session not found")
+		}
+		return err
+	}
+	if session.UserID != claims.UserID || session.IsRevoked() {
+		return errors.NewUnauthorizedError("session has been revoked")
+	}
+
+	return nil
+}
+
+// sendVerification emails a new user a verification link. Failing to send it
+// does not fail sign-up; the user can ask for another.
+func (s *AuthService) sendVerification(ctx context.Context, user *entities.User) {
+	if s.accounts == nil {
+		return
+	}
+	if err := s.accounts.SendVerification(ctx, user); err != nil {
+		s.logger.Warn("Failed to send verification email", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// ClientIP returns the address the request came from, trusting the
+// forwarded address only from the configured proxies
+func (s *AuthService) ClientIP(client dto.ClientInfo) string {
+	if s.throttle == nil {
+		if host, _, err := net.SplitHostPort(client.RemoteAddr); err == nil {
+			return host
+		}
+		return client.RemoteAddr
+	}
+	return s.throttle.ClientIP(client.RemoteAddr, client.ForwardedFor)
+}
+
+func (s *AuthService) checkThrottle(ctx context.Context, email, ip string) error {
+	if s.throttle == nil {
+		return nil
+	}
+	return s.throttle.Check(ctx, email, ip)
+}
+
+func (s *AuthService) recordLoginFailure(ctx context.Context, email, ip string) {
+	if s.throttle != nil {
+		s.throttle.RecordFailure(ctx, email, ip)
+	}
+}
+
+// resetThrottle clears the account's failed logins once it has fully signed
+// in. With two-factor authentication that is only after the code, so a
+// known password cannot be used to keep guessing codes.
+func (s *AuthService) resetThrottle(ctx context.Context, email string) {
+	if s.throttle == nil {
+		return
+	}
+	if err := s.throttle.Reset(ctx, email); err != nil {
+		s.logger.Error("Failed to reset login throttle", err, map[string]interface{}{
+			"email": email,
+		})
+	}
+}
+
+// auditLoginFailure records a failed login. The user is only known when the
+// email matched an account; the actor is whoever sent the request.
+func (s *AuthService) auditLoginFailure(ctx context.Context, businessID, userID, email, reason string) {
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionLoginFailed,
+		TargetType: "user",
+		TargetID:   userID,
+		Metadata:   map[string]interface{}{"email": email, "reason": reason},
+	})
+}
+
+func (s *AuthService) auditInvitationAccepted(ctx context.Context, userID string, invitation *entities.UserInvitation) {
+	s.audit.Record(withAuditUser(ctx, userID), AuditEvent{
+		BusinessID: invitation.BusinessID,
+		Action:     entities.AuditActionInvitationAccepted,
+		TargetType: "invitation",
+		TargetID:   invitation.ID,
+	})
+}
+
+// issuedSession is a session started by startSession with its tokens
+type issuedSession struct {
+	session          *entities.Session
+	accessToken      string
+	refreshToken     string
+	mfaSetupRequired bool
+}
+
+// signIn starts a new session for the user and returns its tokens. sso
+// restricts the session to the user's current business.
+func (s *AuthService) signIn(ctx context.Context, user *entities.User, sso bool) (*dto.LoginResponse, error) {
+	issued, err := s.startSession(ctx, user, "", sso)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(withAuditUser(ctx, user.ID), AuditEvent{
 		BusinessID: user.BusinessID,
-		Email:      user.Email,
-		Role:       string(user.Role),
+		Action:     entities.AuditActionLogin,
+		TargetType: "session",
+		TargetID:   issued.session.ID,
+	})
+
+	return &dto.LoginResponse{
+		AccessToken:      issued.accessToken,
+		RefreshToken:     issued.refreshToken,
+		MFASetupRequired: issued.mfaSetupRequired,
+		User:             mapUserToResponse(user),
+	}, nil
+}
+
+// checkNotSuspended refuses access to a suspended business
+func (s *AuthService) checkNotSuspended(ctx context.Context, businessID string) error {
+	business, err := s.businessRepo.GetByID(ctx, businessID)
+	if err != nil {
+		return err
+	}
+	if business != nil && business.IsSuspended() {
+		return errors.NewForbiddenError("business is suspended")
+	}
+	return nil
+}
+
+// startSession stores a new session for the user, in familyID when the
+// session replaces a rotated one, and issues its tokens
+func (s *AuthService) startSession(ctx context.Context, user *entities.User, familyID string, sso bool) (*issuedSession, error) {
+	// Members of a suspended business can neither sign in nor refresh
+	if err := s.checkNotSuspended(ctx, user.BusinessID); err != nil {
+		return nil, err
+	}
+
+	mfaSetupRequired := false
+	if s.mfa != nil {
+		required, err := s.mfa.SetupRequired(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		mfaSetupRequired = required
+	}
+
+	refreshToken, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	session, err := entities.NewSession(user.ID, user.BusinessID, familyID, tokenHash, s.now().Add(s.config.JWT.RefreshTokenDuration))
+	if err != nil {
+		return nil, err
+	}
+	session.SSO = sso
+
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		s.logger.Error("Failed to create session", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+		return nil, err
+	}
+
+	accessToken, err := s.generateAccessToken(user, session.ID, mfaSetupRequired)
+	if err != nil {
+		return nil, err
+	}
+
+	return &issuedSession{
+		session:          session,
+		accessToken:      accessToken,
+		refreshToken:     refreshToken,
+		mfaSetupRequired: mfaSetupRequired,
+	}, nil
+}
+
+// revokeFamily revokes every session descended from the same sign-in as
+// session after one of its tokens was misused
+func (s *AuthService) revokeFamily(ctx context.Context, session *entities.Session, reason string) {
+	s.logger.Warn("Revoking session family", map[string]interface{}{
+		"user_id":    session.UserID,
+		"session_id": session.ID,
+		"family_id":  session.FamilyID,
+		"reason":     reason,
+	})
+
+	if err := s.sessionRepo.RevokeFamily(ctx, session.FamilyID, s.now()); err != nil {
+		s.logger.Error("Failed to revoke session family", err, map[string]interface{}{
+			"family_id": session.FamilyID,
+		})
+	}
+}
+
+func (s *AuthService) generateAccessToken(user *entities.User, sessionID string, mfaSetupRequired bool) (string, error) {
+	claims := &Claims{
+		UserID:           user.ID,
+		BusinessID:       user.BusinessID,
+		Email:            user.Email,
+		Role:             string(user.Role),
+		SessionID:        sessionID,
+		Unverified:       !user.IsEmailVerified(),
+		MFASetupRequired: mfaSetupRequired,
+		PlatformAdmin:    user.PlatformAdmin,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.JWT.AccessTokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "vapi-integration",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.config.JWT.SecretKey))
+	return s.signToken(claims)
 }
 
-func (s *AuthService) generateRefreshToken(user *entities.User) (string, error) {
+// IssueImpersonationToken issues a short-lived access token for an agency
+// user to act in a child business as an admin. It has no session, so it
+// cannot be refreshed.
+func (s *AuthService) IssueImpersonationToken(actor *entities.User, agencyID, businessID string) (string, time.Time, error) {
+	ttl := impersonationTTL
+	if s.config.JWT.AccessTokenDuration > 0 && s.config.JWT.AccessTokenDuration < ttl {
+		ttl = s.config.JWT.AccessTokenDuration
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	claims := &Claims{
+		UserID:     actor.ID,
+		BusinessID: businessID,
+		Email:      actor.Email,
+		Role:       string(entities.UserRoleAdmin),
+		Unverified: !actor.IsEmailVerified(),
+		AgencyID:   agencyID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token, err := s.signToken(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// generateMFAChallenge issues the short-lived token a user exchanges with a
+// code to finish signing in
+func (s *AuthService) generateMFAChallenge(user *entities.User, sso bool) (string, error) {
 	claims := &Claims{
 		UserID:     user.ID,
 		BusinessID: user.BusinessID,
-		Email:      user.Email,
-		Role:       string(user.Role),
+		SSO:        sso,
+		TokenUse:   tokenUseMFAChallenge,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.JWT.RefreshTokenDuration)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaChallengeTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "vapi-integration",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.config.JWT.SecretKey))
+	return s.signToken(claims)
 }
 
-func (s *AuthService) validateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+// signToken stamps the issuer and audience on claims and signs them, with
+// the signing key's ID in the "kid" header
+func (s *AuthService) signToken(claims *Claims) (string, error) {
+	claims.Issuer = s.config.JWT.Issuer
+	if s.config.JWT.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{s.config.JWT.Audience}
+	}
+
+	if s.keys == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(s.config.JWT.SecretKey))
+	}
+
+	key := s.keys.SigningKey()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.Private)
+}
+
+// verificationKey picks the key a token is checked with from its "kid"
+// header. The algorithm must be the one that key is used with.
+func (s *AuthService) verificationKey(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+		if !s.acceptsSharedSecret() {
+			return nil, errors.NewUnauthorizedError("unexpected signing algorithm")
+		}
 		return []byte(s.config.JWT.SecretKey), nil
-	})
+	}
+	if s.keys == nil {
+		return nil, errors.NewUnauthorizedError("unexpected signing algorithm")
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys.Lookup(kid)
+	if !ok {
+		return nil, errors.NewUnauthorizedError("unknown signing key")
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, errors.NewUnauthorizedError("unexpected signing algorithm")
+	}
+	return key.Public, nil
+}
+
+// acceptsSharedSecret reports whether tokens signed HS256 with the shared
+// secret are valid: always without keys, and only until the configured
+// cut-off after switching to them
+func (s *AuthService) acceptsSharedSecret() bool {
+	if s.keys == nil {
+		return true
+	}
+	return s.config.JWT.SecretKey != "" && s.now().Before(s.config.JWT.SharedSecretUntil)
+}
+
+func (s *AuthService) validateToken(tokenString string) (*Claims, error) {
+	var algorithms []string
+	if s.keys != nil {
+		algorithms = s.keys.Algorithms()
+	}
+	if s.acceptsSharedSecret() {
+		algorithms = append(algorithms, jwt.SigningMethodHS256.Alg())
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(algorithms), jwt.WithExpirationRequired()}
+	if s.config.JWT.Issuer != "" {
+		options = append(options, jwt.WithIssuer(s.config.JWT.Issuer))
+	}
+	if s.config.JWT.Audience != "" {
+		options = append(options, jwt.WithAudience(s.config.JWT.Audience))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, s.verificationKey, options...)
 
 	if err != nil {
 		return nil, err
@@ -2,18 +2,31 @@ package services
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/CallPilotReceptionist/internal/application/dto"
 	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
 	"github.com/CallPilotReceptionist/pkg/config"
+	"github.com/CallPilotReceptionist/pkg/jwtkeys"
 	"github.com/CallPilotReceptionist/pkg/logger"
 )
 
 // Mock repositories
 type mockUserRepository struct {
 	users map[string]*entities.User
+	// memberships of businesses other than the users' home business, keyed
+	// by user and business ID
+	memberships map[string]*entities.Membership
 }
 
 func (m *mockUserRepository) Create(ctx context.Context, user *entities.User) error {
@@ -42,14 +55,90 @@ func (m *mockUserRepository) GetByEmail(ctx context.Context, email string) (*ent
 }
 
 func (m *mockUserRepository) GetByBusinessID(ctx context.Context, businessID string) ([]*entities.User, error) {
-	return nil, nil
+	var users []*entities.User
+	for _, user := range m.users {
+		if user.BusinessID == businessID {
+			users = append(users, user)
+		}
+	}
+	return users, nil
 }
 
 func (m *mockUserRepository) Update(ctx context.Context, user *entities.User) error {
+	home, ok := m.users[user.ID]
+	if ok && home.BusinessID != user.BusinessID {
+		// A user acting in another business: only that membership's role
+		// changes
+		if membership := m.memberships[user.ID+"/"+user.BusinessID]; membership != nil {
+			membership.Role = user.Role
+		}
+		account := *user
+		account.BusinessID, account.Role = home.BusinessID, home.Role
+		user = &account
+	}
 	m.users[user.ID] = user
 	return nil
 }
 
+func (m *mockUserRepository) GetMember(ctx context.Context, businessID, userID string) (*entities.User, error) {
+	user, ok := m.users[userID]
+	if !ok {
+		return nil, nil
+	}
+	if user.BusinessID == businessID {
+		return user, nil
+	}
+	if membership := m.memberships[userID+"/"+businessID]; membership != nil {
+		return user.AsMember(membership), nil
+	}
+	return nil, nil
+}
+
+func (m *mockUserRepository) GetMembers(ctx context.Context, businessID string) ([]*entities.User, error) {
+	var members []*entities.User
+	for _, user := range m.users {
+		if member, _ := m.GetMember(ctx, businessID, user.ID); member != nil {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+func (m *mockUserRepository) GetMemberships(ctx context.Context, userID string) ([]*entities.Membership, error) {
+	user, ok := m.users[userID]
+	if !ok {
+		return nil, nil
+	}
+	memberships := []*entities.Membership{{UserID: userID, BusinessID: user.BusinessID, Role: user.Role}}
+	for _, membership := range m.memberships {
+		if membership.UserID == userID {
+			memberships = append(memberships, membership)
+		}
+	}
+	return memberships, nil
+}
+
+func (m *mockUserRepository) SaveMembership(ctx context.Context, membership *entities.Membership) error {
+	if user, ok := m.users[membership.UserID]; ok && user.BusinessID == membership.BusinessID {
+		user.Role = membership.Role
+		return nil
+	}
+	if m.memberships == nil {
+		m.memberships = make(map[string]*entities.Membership)
+	}
+	m.memberships[membership.UserID+"/"+membership.BusinessID] = membership
+	return nil
+}
+
+func (m *mockUserRepository) DeleteMembership(ctx context.Context, userID, businessID string) error {
+	key := userID + "/" + businessID
+	if _, ok := m.memberships[key]; !ok {
+		return domainerrors.NewNotFoundError("membership", userID)
+	}
+	delete(m.memberships, key)
+	return nil
+}
+
 func (m *mockUserRepository) Delete(ctx context.Context, id string) error {
 	delete(m.users, id)
 	return nil
@@ -76,6 +165,11 @@ func (m *mockBusinessRepository) GetByID(ctx context.Context, id string) (*entit
 }
 
 func (m *mockBusinessRepository) GetByPhone(ctx context.Context, phone string) (*entities.Business, error) {
+	for _, business := range m.businesses {
+		if business.Phone == phone {
+			return business, nil
+		}
+	}
 	return nil, nil
 }
 
@@ -89,25 +183,128 @@ func (m *mockBusinessRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (m *mockBusinessRepository) List(ctx context.Context, limit, offset int) ([]*entities.Business, error) {
+func (m *mockBusinessRepository) List(ctx context.Context, filter database.BusinessFilter, limit, offset int) ([]*entities.Business, error) {
+	var result []*entities.Business
+	for _, business := range m.businesses {
+		if filter.ParentID != "" && !business.IsChildOf(filter.ParentID) {
+			continue
+		}
+		if filter.Search != "" && !strings.Contains(strings.ToLower(business.Name), strings.ToLower(filter.Search)) &&
+			!strings.Contains(business.Phone, filter.Search) && business.ID != filter.Search {
+			continue
+		}
+		if filter.Suspended != nil && business.IsSuspended() != *filter.Suspended {
+			continue
+		}
+		result = append(result, business)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	if offset >= len(result) {
+		return nil, nil
+	}
+	result = result[offset:]
+	if limit > 0 && limit < len(result) {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+type mockSessionRepository struct {
+	sessions map[string]*entities.Session
+}
+
+func newMockSessionRepository() *mockSessionRepository {
+	return &mockSessionRepository{sessions: make(map[string]*entities.Session)}
+}
+
+func (m *mockSessionRepository) Create(ctx context.Context, session *entities.Session) error {
+	session.ID = fmt.Sprintf("session-%d", len(m.sessions)+1)
+	if session.FamilyID == "" {
+		session.FamilyID = session.ID
+	}
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *mockSessionRepository) GetByID(ctx context.Context, id string) (*entities.Session, error) {
+	if session, ok := m.sessions[id]; ok {
+		return session, nil
+	}
+	return nil, domainerrors.NewNotFoundError("session", id)
+}
+
+func (m *mockSessionRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.Session, error) {
+	for _, session := range m.sessions {
+		if session.TokenHash == tokenHash {
+			return session, nil
+		}
+	}
 	return nil, nil
 }
 
-func newMockAuthService() *AuthService {
-	cfg := &config.Config{
+func (m *mockSessionRepository) Revoke(ctx context.Context, id string, replacedByID *string, now time.Time) (bool, error) {
+	session, ok := m.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return false, nil
+	}
+	session.RevokedAt = &now
+	session.ReplacedByID = replacedByID
+	return true, nil
+}
+
+func (m *mockSessionRepository) RevokeFamily(ctx context.Context, familyID string, now time.Time) error {
+	for _, session := range m.sessions {
+		if session.FamilyID == familyID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *mockSessionRepository) RevokeAllForUser(ctx context.Context, userID string, now time.Time) (int, error) {
+	revoked := 0
+	for _, session := range m.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (m *mockSessionRepository) RevokeAllForBusiness(ctx context.Context, businessID string, now time.Time) (int, error) {
+	revoked := 0
+	for _, session := range m.sessions {
+		if session.BusinessID == businessID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func testAuthConfig() *config.Config {
+	return &config.Config{
 		JWT: config.JWTConfig{
 			SecretKey:            "test-secret-key",
 			AccessTokenDuration:  15 * time.Minute,
 			RefreshTokenDuration: 7 * 24 * time.Hour,
 		},
+		MFA: config.MFAConfig{Issuer: "CallPilot", EncryptionKey: "test-encryption-key"},
+		SSO: config.SSOConfig{RedirectURL: "http://localhost:3000/sso/callback"},
 	}
+}
 
+func newMockAuthService() *AuthService {
 	log := logger.New("info", "console")
 
 	return NewAuthService(
 		&mockUserRepository{users: make(map[string]*entities.User)},
 		&mockBusinessRepository{businesses: make(map[string]*entities.Business)},
-		cfg,
+		newMockInvitationRepository(),
+		newMockSessionRepository(),
+		testAuthConfig(),
 		log,
 	)
 }
@@ -123,7 +320,7 @@ func TestAuthService_Register(t *testing.T) {
 			req: dto.RegisterRequest{
 				BusinessName: "Test Business",
 				BusinessType: "dentist",
-				Phone:        "+1234567890",
+				Phone:        "+15551234567",
 				Email:        "test@example.com",
 				Password:     "password123",
 			},
@@ -134,7 +331,7 @@ func TestAuthService_Register(t *testing.T) {
 			req: dto.RegisterRequest{
 				BusinessName: "Test Business",
 				BusinessType: "dentist",
-				Phone:        "+1234567890",
+				Phone:        "+15551234567",
 				Email:        "",
 				Password:     "password123",
 			},
@@ -145,18 +342,40 @@ func TestAuthService_Register(t *testing.T) {
 			req: dto.RegisterRequest{
 				BusinessName: "Test Business",
 				BusinessType: "dentist",
-				Phone:        "+1234567890",
+				Phone:        "+15551234567",
 				Email:        "test@example.com",
 				Password:     "",
 			},
 			wantErr: true,
 		},
+		{
+			name: "national phone format",
+			req: dto.RegisterRequest{
+				BusinessName: "Test Business",
+				BusinessType: "dentist",
+				Phone:        "(555) 123-4567",
+				Email:        "national@example.com",
+				Password:     "password123",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unparseable phone",
+			req: dto.RegisterRequest{
+				BusinessName: "Test Business",
+				BusinessType: "dentist",
+				Phone:        "call me maybe",
+				Email:        "test@example.com",
+				Password:     "password123",
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing business name",
 			req: dto.RegisterRequest{
 				BusinessName: "",
 				BusinessType: "dentist",
-				Phone:        "+1234567890",
+				Phone:        "+15551234567",
 				Email:        "test@example.com",
 				Password:     "password123",
 			},
@@ -203,7 +422,7 @@ func TestAuthService_Login(t *testing.T) {
 	registerReq := dto.RegisterRequest{
 		BusinessName: "Test Business",
 		BusinessType: "dentist",
-		Phone:        "+1234567890",
+		Phone:        "+15551234567",
 		Email:        "test@example.com",
 		Password:     "password123",
 	}
@@ -284,7 +503,7 @@ func TestAuthService_ValidateToken(t *testing.T) {
 	registerReq := dto.RegisterRequest{
 		BusinessName: "Test Business",
 		BusinessType: "dentist",
-		Phone:        "+1234567890",
+		Phone:        "+15551234567",
 		Email:        "test@example.com",
 		Password:     "password123",
 	}
@@ -345,7 +564,7 @@ func TestAuthService_RefreshToken(t *testing.T) {
 	registerReq := dto.RegisterRequest{
 		BusinessName: "Test Business",
 		BusinessType: "dentist",
-		Phone:        "+1234567890",
+		Phone:        "+15551234567",
 		Email:        "test@example.com",
 		Password:     "password123",
 	}
@@ -392,3 +611,462 @@ func TestAuthService_RefreshToken(t *testing.T) {
 		})
 	}
 }
+
+// newMockTeamAuthService signs in the users of newMockTeamUserRepository
+func newMockTeamAuthService(businesses *mockBusinessRepository, sessions *mockSessionRepository) *AuthService {
+	return NewAuthService(newMockTeamUserRepository(), businesses, newMockInvitationRepository(), sessions, testAuthConfig(), logger.New("info", "console"))
+}
+
+func teamLogin(t *testing.T, auth *AuthService, email string) *dto.LoginResponse {
+	t.Helper()
+	login, err := auth.Login(context.Background(), dto.LoginRequest{Email: email, Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	return login
+}
+
+func validClaims(t *testing.T, auth *AuthService, accessToken string) *Claims {
+	t.Helper()
+	claims, err := auth.ValidateToken(accessToken)
+	if err != nil {
+		t.Fatalf("ValidateToken() error = %v", err)
+	}
+	return claims
+}
+
+func TestAuthService_RefreshTokenRotation(t *testing.T) {
+	tests := []struct {
+		name        string
+		rotateFirst bool
+		advance     time.Duration
+		wantCode    string
+	}{
+		{
+			name: "rotates the session",
+		},
+		{
+			name:        "reuse of a rotated token",
+			rotateFirst: true,
+			wantCode:    domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name:     "expired refresh token",
+			advance:  8 * 24 * time.Hour,
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			sessions := newMockSessionRepository()
+			auth := newMockTeamAuthService(newMockTeamBusinessRepository(), sessions)
+			login := teamLogin(t, auth, "employee@example.com")
+
+			var rotated *dto.RefreshTokenResponse
+			if tt.rotateFirst {
+				var err error
+				if rotated, err = auth.RefreshToken(ctx, login.RefreshToken); err != nil {
+					t.Fatalf("RefreshToken() error = %v", err)
+				}
+			}
+			auth.now = func() time.Time { return time.Now().Add(tt.advance) }
+
+			response, err := auth.RefreshToken(ctx, login.RefreshToken)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Fatalf("expected %s error, got %v", tt.wantCode, err)
+				}
+				// Reusing the rotated token revokes the whole family
+				if rotated != nil {
+					if _, err := auth.RefreshToken(ctx, rotated.RefreshToken); err == nil {
+						t.Error("expected the latest refresh token to be revoked after reuse")
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RefreshToken() error = %v", err)
+			}
+			if response.RefreshToken == "" || response.RefreshToken == login.RefreshToken {
+				t.Fatal("expected a new refresh token")
+			}
+
+			current := sessions.sessions[validClaims(t, auth, response.AccessToken).SessionID]
+			if current == nil || !current.IsActive(time.Now()) {
+				t.Fatal("expected the access token to carry the new session")
+			}
+			if original := sessions.sessions[current.FamilyID]; original.RevokedAt == nil || original.ReplacedByID == nil || *original.ReplacedByID != current.ID {
+				t.Error("expected the original session to be revoked and replaced")
+			}
+		})
+	}
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	tests := []struct {
+		name     string
+		actorID  string
+		wantCode string
+	}{
+		{
+			name:    "own session",
+			actorID: "user-admin",
+		},
+		{
+			name:     "another user's session",
+			actorID:  "user-employee",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			auth := newMockTeamAuthService(newMockTeamBusinessRepository(), newMockSessionRepository())
+			first := teamLogin(t, auth, "admin@example.com")
+			second := teamLogin(t, auth, "admin@example.com")
+
+			err := auth.Logout(ctx, tt.actorID, validClaims(t, auth, first.AccessToken).SessionID)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Fatalf("expected %s error, got %v", tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Logout() error = %v", err)
+			}
+			if _, err := auth.RefreshToken(ctx, first.RefreshToken); err == nil {
+				t.Error("expected the logged out session to be unable to refresh")
+			}
+			if _, err := auth.RefreshToken(ctx, second.RefreshToken); err != nil {
+				t.Errorf("expected the other session to stay signed in, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthService_LogoutAll(t *testing.T) {
+	tests := []struct {
+		name        string
+		logins      int
+		wantRevoked int
+	}{
+		{
+			name:        "every session",
+			logins:      2,
+			wantRevoked: 2,
+		},
+		{
+			name:        "no sessions",
+			wantRevoked: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := newMockSessionRepository()
+			auth := newMockTeamAuthService(newMockTeamBusinessRepository(), sessions)
+			for i := 0; i < tt.logins; i++ {
+				teamLogin(t, auth, "admin@example.com")
+			}
+			teamLogin(t, auth, "employee@example.com")
+
+			revoked, err := auth.LogoutAll(context.Background(), "user-admin")
+			if err != nil {
+				t.Fatalf("LogoutAll() error = %v", err)
+			}
+			if revoked != tt.wantRevoked {
+				t.Errorf("LogoutAll() revoked %d sessions, want %d", revoked, tt.wantRevoked)
+			}
+			for _, session := range sessions.sessions {
+				if (session.UserID == "user-admin") != (session.RevokedAt != nil) {
+					t.Errorf("session %s of %s: revoked = %v", session.ID, session.UserID, session.RevokedAt != nil)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthService_CheckSession(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   func(t *testing.T, auth *AuthService, businesses *mockBusinessRepository, login *dto.LoginResponse) *Claims
+		wantCode string
+	}{
+		{
+			name: "active session",
+			claims: func(t *testing.T, auth *AuthService, businesses *mockBusinessRepository, login *dto.LoginResponse) *Claims {
+				return validClaims(t, auth, login.AccessToken)
+			},
+		},
+		{
+			// Refreshing replaces the session, so the old access token stops working
+			name: "session replaced by a refresh",
+			claims: func(t *testing.T, auth *AuthService, businesses *mockBusinessRepository, login *dto.LoginResponse) *Claims {
+				claims := validClaims(t, auth, login.AccessToken)
+				if _, err := auth.RefreshToken(context.Background(), login.RefreshToken); err != nil {
+					t.Fatalf("RefreshToken() error = %v", err)
+				}
+				return claims
+			},
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name: "logged out session",
+			claims: func(t *testing.T, auth *AuthService, businesses *mockBusinessRepository, login *dto.LoginResponse) *Claims {
+				claims := validClaims(t, auth, login.AccessToken)
+				if err := auth.Logout(context.Background(), "user-admin", claims.SessionID); err != nil {
+					t.Fatalf("Logout() error = %v", err)
+				}
+				return claims
+			},
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name: "unknown session",
+			claims: func(t *testing.T, auth *AuthService, businesses *mockBusinessRepository, login *dto.LoginResponse) *Claims {
+				claims := validClaims(t, auth, login.AccessToken)
+				claims.SessionID = "session-unknown"
+				return claims
+			},
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name: "token without a session",
+			claims: func(t *testing.T, auth *AuthService, businesses *mockBusinessRepository, login *dto.LoginResponse) *Claims {
+				claims := validClaims(t, auth, login.AccessToken)
+				claims.SessionID = ""
+				return claims
+			},
+		},
+		{
+			name: "suspended business",
+			claims: func(t *testing.T, auth *AuthService, businesses *mockBusinessRepository, login *dto.LoginResponse) *Claims {
+				claims := validClaims(t, auth, login.AccessToken)
+				claims.SessionID = ""
+				if err := businesses.businesses["business-123"].Suspend("unpaid invoices", time.Now()); err != nil {
+					t.Fatalf("Suspend() error = %v", err)
+				}
+				return claims
+			},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			businesses := newMockTeamBusinessRepository()
+			auth := newMockTeamAuthService(businesses, newMockSessionRepository())
+			claims := tt.claims(t, auth, businesses, teamLogin(t, auth, "admin@example.com"))
+
+			err := auth.CheckSession(context.Background(), claims)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("expected %s error, got %v", tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("CheckSession() error = %v", err)
+			}
+		})
+	}
+}
+
+func newSigningKey(t *testing.T, rsaKey bool) *jwtkeys.Key {
+	t.Helper()
+	var private interface{}
+	if rsaKey {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		private = key
+	} else {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		private = key
+	}
+
+	key, err := jwtkeys.NewKey(private)
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+	return key
+}
+
+func newKeySet(t *testing.T, signing *jwtkeys.Key, verification ...*jwtkeys.Key) *jwtkeys.KeySet {
+	t.Helper()
+	keys, err := jwtkeys.NewKeySet(signing, verification...)
+	if err != nil {
+		t.Fatalf("NewKeySet() error = %v", err)
+	}
+	return keys
+}
+
+func TestAuthService_SigningKeyRotation(t *testing.T) {
+	service := newMockAuthService()
+	service.config.JWT.Issuer = "callpilot"
+	service.config.JWT.Audience = "callpilot-api"
+	user := &entities.User{ID: "user-1", BusinessID: "business-1", Email: "test@example.com", Role: entities.UserRoleOwner}
+
+	oldKey := newSigningKey(t, true)
+	newKey := newSigningKey(t, false)
+
+	service.SetSigningKeys(newKeySet(t, oldKey))
+	oldToken, err := service.generateAccessToken(user, "session-1", false)
+	if err != nil {
+		t.Fatalf("generateAccessToken() error = %v", err)
+	}
+
+	parsed, _, _ := jwt.NewParser().ParseUnverified(oldToken, &Claims{})
+	if parsed.Header["kid"] != oldKey.ID || parsed.Header["alg"] != "RS256" {
+		t.Errorf("unexpected header %v", parsed.Header)
+	}
+
+	// Rotate: sign with the new key, keep the old one for verification
+	service.SetSigningKeys(newKeySet(t, newKey, oldKey))
+	newToken, _ := service.generateAccessToken(user, "session-2", false)
+
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err := service.ValidateToken(token); err != nil {
+			t.Errorf("expected the %s token to be valid after rotation, got %v", name, err)
+		}
+	}
+	if jwks := service.JWKS(); len(jwks.Keys) != 2 || jwks.Keys[0].KeyID != newKey.ID {
+		t.Errorf("expected both public keys to be published, got %+v", jwks)
+	}
+
+	// Retire the old key once its tokens have expired
+	service.SetSigningKeys(newKeySet(t, newKey))
+	if _, err := service.ValidateToken(oldToken); err == nil {
+		t.Error("expected tokens from a retired key to be rejected")
+	}
+}
+
+func TestAuthService_SharedSecretCutover(t *testing.T) {
+	service := newMockAuthService()
+	now := time.Now()
+	service.now = func() time.Time { return now }
+	service.config.JWT.SharedSecretUntil = now.Add(service.config.JWT.AccessTokenDuration)
+	user := &entities.User{ID: "user-1", BusinessID: "business-1", Email: "test@example.com", Role: entities.UserRoleOwner}
+
+	secretToken, err := service.generateAccessToken(user, "session-1", false)
+	if err != nil {
+		t.Fatalf("generateAccessToken() error = %v", err)
+	}
+
+	// Deploying with a signing key keeps the tokens already handed out valid
+	key := newSigningKey(t, false)
+	service.SetSigningKeys(newKeySet(t, key))
+	keyToken, _ := service.generateAccessToken(user, "session-2", false)
+
+	parsed, _, _ := jwt.NewParser().ParseUnverified(keyToken, &Claims{})
+	if parsed.Header["alg"] != "EdDSA" {
+		t.Errorf("expected new tokens to be signed with the key, got %v", parsed.Header)
+	}
+	for name, token := range map[string]string{"secret": secretToken, "key": keyToken} {
+		if _, err := service.ValidateToken(token); err != nil {
+			t.Errorf("expected the %s token to be valid during the cut-over, got %v", name, err)
+		}
+	}
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		UserID:           user.ID,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute))},
+	})
+	forgedToken, _ := forged.SignedString([]byte("another-secret"))
+	if _, err := service.ValidateToken(forgedToken); err == nil {
+		t.Error("expected a token signed with another secret to be rejected")
+	}
+
+	// Once every secret-signed token has expired, only the key is trusted,
+	// also after a restart
+	now = now.Add(service.config.JWT.AccessTokenDuration + time.Second)
+	service.SetSigningKeys(newKeySet(t, key))
+	lateSecret := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		UserID:           user.ID,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	lateToken, _ := lateSecret.SignedString([]byte("test-secret-key"))
+	if _, err := service.ValidateToken(lateToken); err == nil {
+		t.Error("expected tokens signed with the secret to be rejected after the cut-over")
+	}
+	if _, err := service.ValidateToken(keyToken); err != nil {
+		t.Errorf("expected the key token to stay valid, got %v", err)
+	}
+}
+
+func TestAuthService_SharedSecretWithoutCutover(t *testing.T) {
+	service := newMockAuthService()
+	user := &entities.User{ID: "user-1", BusinessID: "business-1", Email: "test@example.com", Role: entities.UserRoleOwner}
+
+	secretToken, err := service.generateAccessToken(user, "session-1", false)
+	if err != nil {
+		t.Fatalf("generateAccessToken() error = %v", err)
+	}
+
+	// Without a cut-off the secret stops being trusted as soon as a key is
+	service.SetSigningKeys(newKeySet(t, newSigningKey(t, false)))
+	if _, err := service.ValidateToken(secretToken); err == nil {
+		t.Error("expected tokens signed with the secret to be rejected without a cut-off")
+	}
+}
+
+func TestAuthService_ValidateTokenClaims(t *testing.T) {
+	service := newMockAuthService()
+	service.config.JWT.Issuer = "callpilot"
+	service.config.JWT.Audience = "callpilot-api"
+	// Without a shared secret only the keys are trusted
+	service.config.JWT.SecretKey = ""
+	key := newSigningKey(t, false)
+	service.SetSigningKeys(newKeySet(t, key))
+	user := &entities.User{ID: "user-1", BusinessID: "business-1", Email: "test@example.com", Role: entities.UserRoleOwner}
+
+	sign := func(method jwt.SigningMethod, signingKey interface{}, mutate func(*Claims)) string {
+		claims := &Claims{
+			UserID:     user.ID,
+			BusinessID: user.BusinessID,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    "callpilot",
+				Audience:  jwt.ClaimStrings{"callpilot-api"},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+			},
+		}
+		mutate(claims)
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = key.ID
+		signed, err := token.SignedString(signingKey)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"valid", sign(jwt.SigningMethodEdDSA, key.Private, func(c *Claims) {}), false},
+		{"wrong issuer", sign(jwt.SigningMethodEdDSA, key.Private, func(c *Claims) { c.Issuer = "someone-else" }), true},
+		{"wrong audience", sign(jwt.SigningMethodEdDSA, key.Private, func(c *Claims) { c.Audience = jwt.ClaimStrings{"other-api"} }), true},
+		{"no expiry", sign(jwt.SigningMethodEdDSA, key.Private, func(c *Claims) { c.ExpiresAt = nil }), true},
+		{"shared secret", sign(jwt.SigningMethodHS256, []byte("test-secret-key"), func(c *Claims) {}), true},
+		{"unsigned", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, func(c *Claims) {}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ValidateToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,12 +2,17 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
 	"github.com/CallPilotReceptionist/internal/infrastructure/database"
 	"github.com/CallPilotReceptionist/pkg/logger"
+	"github.com/CallPilotReceptionist/pkg/phone"
 )
 
 var ErrBusinessNotFound = errors.New("business not found")
@@ -15,6 +20,8 @@ var ErrBusinessNotFound = errors.New("business not found")
 type BusinessService struct {
 	businessRepo database.BusinessRepository
 	logger       *logger.Logger
+
+	audit *AuditService
 }
 
 func NewBusinessService(
@@ -27,6 +34,12 @@ func NewBusinessService(
 	}
 }
 
+// SetAudit records changes to the business and its settings in the audit
+// log
+func (s *BusinessService) SetAudit(audit *AuditService) {
+	s.audit = audit
+}
+
 func (s *BusinessService) GetBusiness(ctx context.Context, businessID string) (*dto.BusinessResponse, error) {
 	business, err := s.businessRepo.GetByID(ctx, businessID)
 	if err != nil {
@@ -37,15 +50,7 @@ func (s *BusinessService) GetBusiness(ctx context.Context, businessID string) (*
 		return nil, ErrBusinessNotFound
 	}
 
-	return &dto.BusinessResponse{
-		ID:        business.ID,
-		Name:      business.Name,
-		Type:      business.Type,
-		Phone:     business.Phone,
-		Settings:  business.Settings,
-		CreatedAt: business.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: business.UpdatedAt.Format(time.RFC3339),
-	}, nil
+	return mapBusinessToResponse(business), nil
 }
 
 func (s *BusinessService) UpdateBusiness(ctx context.Context, businessID string, req dto.UpdateBusinessRequest) (*dto.BusinessResponse, error) {
@@ -59,10 +64,33 @@ func (s *BusinessService) UpdateBusiness(ctx context.Context, businessID string,
 		return nil, ErrBusinessNotFound
 	}
 
+	settings := business.EffectiveSettings()
+	if len(req.Settings) > 0 {
+		settings, err = mergeSettings(settings, req.Settings)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	businessPhone := req.Phone
+	if businessPhone != "" {
+		businessPhone, err = normalizePhone("phone", businessPhone, settings.DefaultRegion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	before := businessAuditFields(business)
+
 	// Update fields
-	if err := business.Update(req.Name, req.Type, req.Phone, req.Settings); err != nil {
+	if err := business.Update(req.Name, req.Type, businessPhone); err != nil {
 		return nil, err
 	}
+	if len(req.Settings) > 0 {
+		if err := business.UpdateSettings(settings); err != nil {
+			return nil, err
+		}
+	}
 
 	// Save to database
 	if err := s.businessRepo.Update(ctx, business); err != nil {
@@ -76,13 +104,115 @@ func (s *BusinessService) UpdateBusiness(ctx context.Context, businessID string,
 		"business_id": businessID,
 	})
 
-	return &dto.BusinessResponse{
-		ID:        business.ID,
-		Name:      business.Name,
-		Type:      business.Type,
-		Phone:     business.Phone,
-		Settings:  business.Settings,
-		CreatedAt: business.CreatedAt.Format(time.RFC3339),
-		UpdatedAt: business.UpdatedAt.Format(time.RFC3339),
-	}, nil
+	if changes := entities.DiffFields(before, businessAuditFields(business)); len(changes) > 0 {
+		s.audit.Record(ctx, AuditEvent{
+			BusinessID: businessID,
+			Action:     entities.AuditActionBusinessUpdated,
+			TargetType: "business",
+			TargetID:   businessID,
+			Changes:    changes,
+		})
+	}
+
+	return mapBusinessToResponse(business), nil
+}
+
+// GetSettings returns the business's settings, with defaults for those it
+// has not set
+func (s *BusinessService) GetSettings(ctx context.Context, businessID string) (json.RawMessage, error) {
+	business, err := s.businessRepo.GetByID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if business == nil {
+		return nil, ErrBusinessNotFound
+	}
+
+	return json.Marshal(business.EffectiveSettings())
+}
+
+// UpdateSettings applies a JSON merge patch (RFC 7396) to the business's
+// settings, so a client only sends the settings it changes. Members set to
+// null go back to their defaults.
+func (s *BusinessService) UpdateSettings(ctx context.Context, businessID string, patch json.RawMessage) (json.RawMessage, error) {
+	if len(patch) == 0 {
+		return nil, domainerrors.NewValidationError("a merge patch is required")
+	}
+
+	response, err := s.UpdateBusiness(ctx, businessID, dto.UpdateBusinessRequest{Settings: patch})
+	if err != nil {
+		return nil, err
+	}
+	return response.Settings, nil
+}
+
+// mergeSettings applies a merge patch to the settings and normalises the
+// transfer numbers it sets, which users may write in national format
+func mergeSettings(settings entities.BusinessSettings, patch json.RawMessage) (entities.BusinessSettings, error) {
+	merged, err := settings.Merge(patch)
+	if err != nil {
+		return entities.BusinessSettings{}, err
+	}
+	if err := normalizeSettings(&merged); err != nil {
+		return entities.BusinessSettings{}, err
+	}
+	return merged, nil
+}
+
+// normalizeSettings normalises the transfer numbers to E.164 with the
+// settings' default region
+func normalizeSettings(settings *entities.BusinessSettings) error {
+	if !phone.IsSupportedRegion(settings.DefaultRegion) {
+		return domainerrors.NewFieldValidationError("default_region", "unsupported phone region "+settings.DefaultRegion)
+	}
+
+	numbers := make([]entities.TransferNumber, len(settings.TransferNumbers))
+	for i, number := range settings.TransferNumbers {
+		normalized, err := normalizePhone(fmt.Sprintf("transfer_numbers[%d].phone", i), number.Phone, settings.DefaultRegion)
+		if err != nil {
+			return err
+		}
+		numbers[i] = entities.TransferNumber{Label: number.Label, Phone: normalized}
+	}
+	settings.TransferNumbers = numbers
+	return nil
+}
+
+// businessAuditFields flattens the audited fields of a business, with each
+// setting under its own settings.<name> field so a diff shows which
+// settings changed
+func businessAuditFields(business *entities.Business) map[string]interface{} {
+	fields := map[string]interface{}{
+		"name":  business.Name,
+		"type":  business.Type,
+		"phone": business.Phone,
+	}
+	var settings map[string]interface{}
+	data, _ := json.Marshal(business.EffectiveSettings())
+	json.Unmarshal(data, &settings)
+	for key, value := range settings {
+		fields["settings."+key] = value
+	}
+	return fields
+}
+
+func mapBusinessToResponse(business *entities.Business) *dto.BusinessResponse {
+	settings, _ := json.Marshal(business.EffectiveSettings())
+	response := &dto.BusinessResponse{
+		ID:               business.ID,
+		Name:             business.Name,
+		Type:             business.Type,
+		ParentID:         business.ParentID,
+		Phone:            business.Phone,
+		Settings:         settings,
+		SuspensionReason: business.SuspensionReason,
+		CreatedAt:        business.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        business.UpdatedAt.Format(time.RFC3339),
+	}
+	if business.SuspendedAt != nil {
+		suspendedAt := business.SuspendedAt.Format(time.RFC3339)
+		response.SuspendedAt = &suspendedAt
+	}
+	return response
 }
@@ -2,13 +2,22 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/CallPilotReceptionist/internal/application/dto"
 	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
 	"github.com/CallPilotReceptionist/pkg/logger"
 )
 
+// testSettings returns the default settings with change applied
+func testSettings(change func(s *entities.BusinessSettings)) entities.BusinessSettings {
+	settings := entities.DefaultBusinessSettings()
+	change(&settings)
+	return settings
+}
+
 func TestBusinessService_GetBusiness(t *testing.T) {
 	log := logger.New("info", "console")
 
@@ -29,10 +38,10 @@ func TestBusinessService_GetBusiness(t *testing.T) {
 					ID:    businessID,
 					Name:  "Smith Dental",
 					Type:  "dentist",
-					Phone: "+1234567890",
-					Settings: map[string]interface{}{
-						"timezone": "America/New_York",
-					},
+					Phone: "+15551234567",
+					Settings: testSettings(func(s *entities.BusinessSettings) {
+						s.Timezone = "America/New_York"
+					}),
 				}
 				repo.businesses[businessID] = business
 			},
@@ -44,8 +53,8 @@ func TestBusinessService_GetBusiness(t *testing.T) {
 				if resp.Type != "dentist" {
 					t.Errorf("expected type 'dentist', got %s", resp.Type)
 				}
-				if resp.Phone != "+1234567890" {
-					t.Errorf("expected phone '+1234567890', got %s", resp.Phone)
+				if resp.Phone != "+15551234567" {
+					t.Errorf("expected phone '+15551234567', got %s", resp.Phone)
 				}
 			},
 		},
@@ -115,7 +124,7 @@ func TestBusinessService_UpdateBusiness(t *testing.T) {
 					ID:    businessID,
 					Name:  "Smith Dental",
 					Type:  "dentist",
-					Phone: "+1234567890",
+					Phone: "+15551234567",
 				}
 				repo.businesses[businessID] = business
 			},
@@ -132,19 +141,18 @@ func TestBusinessService_UpdateBusiness(t *testing.T) {
 			request: dto.UpdateBusinessRequest{
 				Name:  "New Name",
 				Type:  "clinic",
-				Phone: "+9876543210",
-				Settings: map[string]interface{}{
-					"timezone":       "America/Los_Angeles",
-					"workingHours":   "9-5",
-					"appointmentGap": 30,
-				},
+				Phone: "+15559876543",
+				Settings: json.RawMessage(`{
+					"timezone": "America/Los_Angeles",
+					"hours": {"saturday": [{"open": "10:00", "close": "14:00"}]}
+				}`),
 			},
 			setupMocks: func(repo *mockBusinessRepository) {
 				business := &entities.Business{
 					ID:    businessID,
 					Name:  "Smith Dental",
 					Type:  "dentist",
-					Phone: "+1234567890",
+					Phone: "+15551234567",
 				}
 				repo.businesses[businessID] = business
 			},
@@ -156,11 +164,12 @@ func TestBusinessService_UpdateBusiness(t *testing.T) {
 				if resp.Type != "clinic" {
 					t.Errorf("expected type 'clinic', got %s", resp.Type)
 				}
-				if resp.Phone != "+9876543210" {
-					t.Errorf("expected phone '+9876543210', got %s", resp.Phone)
+				if resp.Phone != "+15559876543" {
+					t.Errorf("expected phone '+15559876543', got %s", resp.Phone)
 				}
-				if resp.Settings == nil {
-					t.Error("expected settings to be set")
+				settings, err := entities.ParseBusinessSettings(resp.Settings)
+				if err != nil || settings.Timezone != "America/Los_Angeles" || len(settings.Hours.Saturday) != 1 {
+					t.Errorf("expected settings to be set, got %s", resp.Settings)
 				}
 			},
 		},
@@ -179,14 +188,14 @@ func TestBusinessService_UpdateBusiness(t *testing.T) {
 			name:       "update with empty name (no change)",
 			businessID: businessID,
 			request: dto.UpdateBusinessRequest{
-				Phone: "+9876543210",
+				Phone: "+15559876543",
 			},
 			setupMocks: func(repo *mockBusinessRepository) {
 				business := &entities.Business{
 					ID:    businessID,
 					Name:  "Smith Dental",
 					Type:  "dentist",
-					Phone: "+1234567890",
+					Phone: "+15551234567",
 				}
 				repo.businesses[businessID] = business
 			},
@@ -195,8 +204,8 @@ func TestBusinessService_UpdateBusiness(t *testing.T) {
 				if resp.Name != "Smith Dental" {
 					t.Errorf("expected name unchanged 'Smith Dental', got %s", resp.Name)
 				}
-				if resp.Phone != "+9876543210" {
-					t.Errorf("expected phone '+9876543210', got %s", resp.Phone)
+				if resp.Phone != "+15559876543" {
+					t.Errorf("expected phone '+15559876543', got %s", resp.Phone)
 				}
 			},
 		},
@@ -233,3 +242,85 @@ func TestBusinessService_UpdateBusiness(t *testing.T) {
 		})
 	}
 }
+
+func TestBusinessService_UpdateSettings(t *testing.T) {
+	log := logger.New("info", "console")
+
+	tests := []struct {
+		name     string
+		patch    string
+		wantCode string
+		validate func(t *testing.T, settings entities.BusinessSettings)
+	}{
+		{
+			name:  "keeps the settings the patch leaves out",
+			patch: `{"locale": "es-US"}`,
+			validate: func(t *testing.T, settings entities.BusinessSettings) {
+				if settings.Locale != "es-US" || settings.Timezone != "America/New_York" || !settings.CallerRecognition {
+					t.Errorf("expected only the locale to change, got %+v", settings)
+				}
+			},
+		},
+		{
+			name:  "normalises transfer numbers",
+			patch: `{"transfer_numbers": [{"label": "Front desk", "phone": "(555) 123-4567"}]}`,
+			validate: func(t *testing.T, settings entities.BusinessSettings) {
+				if len(settings.TransferNumbers) != 1 || settings.TransferNumbers[0].Phone != "+15551234567" {
+					t.Errorf("expected an E.164 transfer number, got %+v", settings.TransferNumbers)
+				}
+			},
+		},
+		{
+			name:  "null restores the default",
+			patch: `{"timezone": null}`,
+			validate: func(t *testing.T, settings entities.BusinessSettings) {
+				if settings.Timezone != "UTC" {
+					t.Errorf("expected the default time zone, got %s", settings.Timezone)
+				}
+			},
+		},
+		{name: "invalid value", patch: `{"timezone": "Mars/Olympus"}`, wantCode: domainerrors.ErrCodeValidationError},
+		{name: "unknown setting", patch: `{"workingHours": "9-5"}`, wantCode: domainerrors.ErrCodeValidationError},
+		{name: "empty patch", patch: ``, wantCode: domainerrors.ErrCodeValidationError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockBusinessRepository{businesses: map[string]*entities.Business{
+				"business-123": {
+					ID:    "business-123",
+					Name:  "Smith Dental",
+					Phone: "+15551230000",
+					Settings: testSettings(func(s *entities.BusinessSettings) {
+						s.Timezone = "America/New_York"
+						s.CallerRecognition = true
+					}),
+				},
+			}}
+			service := NewBusinessService(repo, log)
+
+			response, err := service.UpdateSettings(context.Background(), "business-123", json.RawMessage(tt.patch))
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Fatalf("expected %s, got %v", tt.wantCode, err)
+				}
+				if repo.businesses["business-123"].Settings.Timezone != "America/New_York" {
+					t.Error("expected the settings to be unchanged")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UpdateSettings() error = %v", err)
+			}
+
+			var settings entities.BusinessSettings
+			if err := json.Unmarshal(response, &settings); err != nil {
+				t.Fatalf("invalid response %s: %v", response, err)
+			}
+			tt.validate(t, settings)
+			if stored := repo.businesses["business-123"].Settings; stored.Locale != settings.Locale || stored.Timezone != settings.Timezone {
+				t.Errorf("expected the settings to be saved, got %+v", stored)
+			}
+		})
+	}
+}
@@ -10,20 +10,39 @@ import (
 	"github.com/CallPilotReceptionist/internal/domain/providers"
 	"github.com/CallPilotReceptionist/internal/infrastructure/database"
 	"github.com/CallPilotReceptionist/pkg/logger"
+	"github.com/CallPilotReceptionist/pkg/phone"
 )
 
 type CallService struct {
 	callRepo        database.CallRepository
 	transcriptRepo  database.TranscriptRepository
 	interactionRepo database.InteractionRepository
+	businessRepo    database.BusinessRepository
 	voiceProvider   providers.VoiceProvider
 	logger          *logger.Logger
+
+	callerRecognition *CallerRecognitionService
+	compliance        *ComplianceService
+	callbacks         *ScheduledCallService
+	messages          *MessageService
+	audit             *AuditService
+	webhookLog        database.WebhookEventRepository
+	tools             []CallTool
+	tenantScope       TenantScope
+}
+
+// TenantScope runs database work in transactions scoped to one business.
+// database.DB implements it.
+type TenantScope interface {
+	InTenant(ctx context.Context, businessID string, fn func(ctx context.Context) error) error
+	Detached(ctx context.Context, businessID string, fn func(ctx context.Context) error) error
 }
 
 func NewCallService(
 	callRepo database.CallRepository,
 	transcriptRepo database.TranscriptRepository,
 	interactionRepo database.InteractionRepository,
+	businessRepo database.BusinessRepository,
 	voiceProvider providers.VoiceProvider,
 	log *logger.Logger,
 ) *CallService {
@@ -31,56 +50,111 @@ func NewCallService(
 		callRepo:        callRepo,
 		transcriptRepo:  transcriptRepo,
 		interactionRepo: interactionRepo,
+		businessRepo:    businessRepo,
 		voiceProvider:   voiceProvider,
 		logger:          log,
 	}
 }
 
-func (s *CallService) InitiateCall(ctx context.Context, businessID string, req dto.InitiateCallRequest) (*dto.CallResponse, error) {
-	// Validate input
-	if req.PhoneNumber == "" {
-		return nil, errors.NewValidationError("phone_number is required")
-	}
+// SetCallerRecognition enables personalised assistant context for inbound
+// assistant requests and outbound calls
+func (s *CallService) SetCallerRecognition(callerRecognition *CallerRecognitionService) {
+	s.callerRecognition = callerRecognition
+}
 
-	// Create call entity
-	call, err := entities.NewCall(businessID, req.PhoneNumber)
-	if err != nil {
-		return nil, err
+// SetCompliance enables do-not-call, consent and calling-window checks on
+// outbound calls and opt-out detection in transcripts
+func (s *CallService) SetCompliance(compliance *ComplianceService) {
+	s.compliance = compliance
+}
+
+// SetCallbacks enables automatic callbacks for calls that end unanswered or
+// drop early
+func (s *CallService) SetCallbacks(callbacks *ScheduledCallService) {
+	s.callbacks = callbacks
+}
+
+// SetMessages enables creating messages from the provider's analysis of a
+// finished call. Register the service with AddTool as well to let the
+// assistant take messages during the call.
+func (s *CallService) SetMessages(messages *MessageService) {
+	s.messages = messages
+}
+
+// SetAudit records who started each outbound call in the audit log
+func (s *CallService) SetAudit(audit *AuditService) {
+	s.audit = audit
+}
+
+// SetWebhookLog keeps every provider webhook and the outcome of processing
+// it, for platform operators to inspect
+func (s *CallService) SetWebhookLog(webhookLog database.WebhookEventRepository) {
+	s.webhookLog = webhookLog
+}
+
+// SetTenantScope makes InitiateCall scope its own database work, so that
+// requests placing calls need no transaction of their own and the provider
+// is called outside one. Without it the work runs in the caller's scope.
+func (s *CallService) SetTenantScope(scope TenantScope) {
+	s.tenantScope = scope
+}
+
+// inTenant runs fn in a transaction scoped to the business, or in ctx when
+// no tenant scope is set
+func (s *CallService) inTenant(ctx context.Context, businessID string, fn func(ctx context.Context) error) error {
+	if s.tenantScope == nil {
+		return fn(ctx)
 	}
+	return s.tenantScope.InTenant(ctx, businessID, fn)
+}
 
-	// Save call to database
-	if err := s.callRepo.Create(ctx, call); err != nil {
-		s.logger.Error("Failed to create call record", err, map[string]interface{}{
-			"business_id": businessID,
-			"phone":       req.PhoneNumber,
-		})
-		return nil, err
+// detached runs fn in a transaction of its own that commits whatever then
+// happens to the caller's, or in ctx when no tenant scope is set
+func (s *CallService) detached(ctx context.Context, businessID string, fn func(ctx context.Context) error) error {
+	if s.tenantScope == nil {
+		return fn(ctx)
 	}
+	return s.tenantScope.Detached(ctx, businessID, fn)
+}
 
-	// Initiate call with provider
-	providerReq := providers.CallRequest{
-		PhoneNumber: req.PhoneNumber,
-		AssistantID: req.AssistantID,
-		Metadata: map[string]interface{}{
-			"call_id":     call.ID,
-			"business_id": businessID,
-		},
+// InitiateCall records the call and asks the provider to place it. The
+// record is saved before the provider is called, outside any transaction,
+// and its outcome is saved on its own, so a call the provider refuses is
+// still kept as failed.
+func (s *CallService) InitiateCall(ctx context.Context, businessID string, req dto.InitiateCallRequest) (*dto.CallResponse, error) {
+	var call *entities.Call
+	var providerReq *providers.CallRequest
+	err := s.inTenant(ctx, businessID, func(ctx context.Context) error {
+		var err error
+		call, providerReq, err = s.prepareCall(ctx, businessID, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	session, err := s.voiceProvider.InitiateCall(ctx, providerReq)
+	session, err := s.voiceProvider.InitiateCall(ctx, *providerReq)
 	if err != nil {
 		s.logger.Error("Failed to initiate call with provider", err, map[string]interface{}{
 			"call_id": call.ID,
 		})
 		// Update call status to failed
 		call.UpdateStatus(entities.CallStatusFailed)
-		s.callRepo.Update(ctx, call)
+		if err := s.detached(ctx, businessID, func(ctx context.Context) error {
+			return s.callRepo.Update(ctx, call)
+		}); err != nil {
+			s.logger.Error("Failed to mark call as failed", err, map[string]interface{}{
+				"call_id": call.ID,
+			})
+		}
 		return nil, err
 	}
 
 	// Update call with provider ID
 	call.SetProviderCallID(session.ID)
-	if err := s.callRepo.Update(ctx, call); err != nil {
+	if err := s.detached(ctx, businessID, func(ctx context.Context) error {
+		return s.callRepo.Update(ctx, call)
+	}); err != nil {
 		s.logger.Error("Failed to update call with provider ID", err, map[string]interface{}{
 			"call_id":          call.ID,
 			"provider_call_id": session.ID,
@@ -93,15 +167,140 @@ func (s *CallService) InitiateCall(ctx context.Context, businessID string, req d
 		"business_id":      businessID,
 	})
 
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionCallInitiated,
+		TargetType: "call",
+		TargetID:   call.ID,
+		Metadata: map[string]interface{}{
+			"phone_number":     providerReq.PhoneNumber,
+			"provider_call_id": session.ID,
+		},
+	})
+
 	return s.mapCallToResponse(call), nil
 }
 
+// prepareCall saves the call record and builds the request for the provider
+func (s *CallService) prepareCall(ctx context.Context, businessID string, req dto.InitiateCallRequest) (*entities.Call, *providers.CallRequest, error) {
+	// Validate input
+	if req.PhoneNumber == "" {
+		return nil, nil, errors.NewValidationError("phone_number is required")
+	}
+
+	business, err := s.businessRepo.GetByID(ctx, businessID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if business != nil && business.IsSuspended() {
+		return nil, nil, errors.NewForbiddenError("business is suspended")
+	}
+
+	// Caller context and functions are sent as overrides of a saved
+	// assistant. Without one the provider picks the assistant, as it did
+	// before businesses had one.
+	assistantID := req.AssistantID
+	if assistantID == "" && business != nil {
+		assistantID = business.AssistantID()
+	}
+
+	phoneNumber, err := s.normalizeBusinessPhone(ctx, businessID, "phone_number", req.PhoneNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if s.compliance != nil {
+		if err := s.compliance.CheckOutboundCall(ctx, businessID, phoneNumber); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Create call entity
+	call, err := entities.NewCall(businessID, phoneNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Save call to database
+	if err := s.callRepo.Create(ctx, call); err != nil {
+		s.logger.Error("Failed to create call record", err, map[string]interface{}{
+			"business_id": businessID,
+			"phone":       phoneNumber,
+		})
+		return nil, nil, err
+	}
+
+	// Build the provider request
+	providerReq := providers.CallRequest{
+		PhoneNumber: phoneNumber,
+		AssistantID: assistantID,
+		Metadata:    make(map[string]interface{}, len(req.Metadata)+2),
+	}
+	for k, v := range req.Metadata {
+		providerReq.Metadata[k] = v
+	}
+	providerReq.Metadata["call_id"] = call.ID
+	providerReq.Metadata["business_id"] = businessID
+
+	if s.callerRecognition != nil {
+		callerContext, err := s.callerRecognition.OutboundContext(ctx, businessID, phoneNumber)
+		if err != nil {
+			s.logger.Warn("Failed to look up caller", map[string]interface{}{
+				"call_id": call.ID,
+				"error":   err.Error(),
+			})
+		} else if callerContext != nil {
+			providerReq.AssistantConfig = callerContext
+			for k, v := range callerContext.Metadata {
+				providerReq.Metadata[k] = v
+			}
+		}
+	}
+
+	if len(req.Variables) > 0 {
+		if providerReq.AssistantConfig == nil {
+			providerReq.AssistantConfig = &providers.AssistantConfig{}
+		}
+		if providerReq.AssistantConfig.VariableValues == nil {
+			providerReq.AssistantConfig.VariableValues = make(map[string]interface{}, len(req.Variables))
+		}
+		for k, v := range req.Variables {
+			providerReq.AssistantConfig.VariableValues[k] = v
+		}
+	}
+
+	if functions := s.assistantFunctions(ctx, businessID); len(functions) > 0 {
+		if providerReq.AssistantConfig == nil {
+			providerReq.AssistantConfig = &providers.AssistantConfig{}
+		}
+		providerReq.AssistantConfig.Functions = append(providerReq.AssistantConfig.Functions, functions...)
+	}
+
+	return call, &providerReq, nil
+}
+
+// normalizeBusinessPhone reads a number in the business's default region
+func (s *CallService) normalizeBusinessPhone(ctx context.Context, businessID, field, raw string) (string, error) {
+	region := phone.DefaultRegion
+	if business, err := s.businessRepo.GetByID(ctx, businessID); err == nil && business != nil {
+		region = business.DefaultRegion()
+	}
+	return normalizePhone(field, raw, region)
+}
+
 func (s *CallService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	_, err := s.ProcessWebhook(ctx, payload, signature)
+	return err
+}
+
+// ProcessWebhook handles a provider webhook and returns the body the provider
+// expects in reply, or nil when a plain acknowledgement is enough
+func (s *CallService) ProcessWebhook(ctx context.Context, payload []byte, signature string) (interface{}, error) {
 	// Process webhook from provider
 	event, err := s.voiceProvider.HandleWebhook(ctx, payload, signature)
 	if err != nil {
 		s.logger.Error("Failed to parse webhook", err, nil)
-		return err
+		return nil, err
 	}
 
 	s.logger.Info("Received webhook event", map[string]interface{}{
@@ -110,6 +309,106 @@ func (s *CallService) HandleWebhook(ctx context.Context, payload []byte, signatu
 		"status":     event.Status,
 	})
 
+	var response interface{}
+	switch event.Type {
+	case providers.CallEventTypeAssistantRequest:
+		response, err = s.handleAssistantRequest(ctx, event)
+	case providers.CallEventTypeToolCalls:
+		response, err = s.handleToolCalls(ctx, event)
+	default:
+		err = s.handleCallEvent(ctx, event)
+	}
+
+	s.logWebhook(ctx, event, payload, err)
+	return response, err
+}
+
+// logWebhook keeps the webhook in the webhook log when one is set. Failures
+// are only logged because the webhook has already been processed.
+func (s *CallService) logWebhook(ctx context.Context, event *providers.CallEvent, payload []byte, processErr error) {
+	if s.webhookLog == nil {
+		return
+	}
+
+	entry, err := entities.NewWebhookEvent(event.Type, event.CallID, payload, processErr, time.Now())
+	if err != nil {
+		return
+	}
+	if err := s.webhookLog.Create(ctx, entry); err != nil {
+		s.logger.Error("Failed to log webhook", err, map[string]interface{}{
+			"event_type":       event.Type,
+			"provider_call_id": event.CallID,
+		})
+	}
+}
+
+func (s *CallService) handleAssistantRequest(ctx context.Context, event *providers.CallEvent) (interface{}, error) {
+	responder, ok := s.voiceProvider.(providers.AssistantRequestResponder)
+	if !ok || s.callerRecognition == nil {
+		return nil, errors.NewInvalidInputError("assistant requests are not supported")
+	}
+
+	selection, err := s.callerRecognition.SelectAssistant(ctx, event)
+	if err != nil {
+		// An unanswered call is worse than one without the caller's context
+		s.logger.Error("Failed to select assistant for inbound call, falling back to the default", err, map[string]interface{}{
+			"provider_call_id": event.CallID,
+		})
+		selection, err = s.callerRecognition.DefaultAssistant(ctx, event)
+		if err != nil {
+			s.logger.Error("Failed to find an assistant for inbound call", err, map[string]interface{}{
+				"provider_call_id": event.CallID,
+			})
+			return nil, err
+		}
+	}
+
+	if selection.AssistantConfig != nil {
+		if businessID, ok := selection.AssistantConfig.Metadata["business_id"].(string); ok {
+			s.recordInboundCall(ctx, businessID, event)
+			selection.AssistantConfig.Functions = append(selection.AssistantConfig.Functions,
+				s.assistantFunctions(ctx, businessID)...)
+		}
+	}
+
+	return responder.BuildAssistantResponse(*selection), nil
+}
+
+// recordInboundCall stores an inbound call so its outcome and transcript are
+// tracked like those of outbound calls. Failures are only logged because the
+// call must still be answered.
+func (s *CallService) recordInboundCall(ctx context.Context, businessID string, event *providers.CallEvent) {
+	if event.CallID == "" || event.CustomerPhone == "" {
+		return
+	}
+
+	callerPhone, err := s.normalizeBusinessPhone(ctx, businessID, "customer_phone", event.CustomerPhone)
+	if err != nil {
+		s.logger.Warn("Inbound call has an unusable caller number", map[string]interface{}{
+			"provider_call_id": event.CallID,
+			"error":            err.Error(),
+		})
+		return
+	}
+
+	call, err := entities.NewInboundCall(businessID, callerPhone, event.CallID)
+	if err != nil {
+		s.logger.Warn("Failed to build inbound call record", map[string]interface{}{
+			"provider_call_id": event.CallID,
+			"error":            err.Error(),
+		})
+		return
+	}
+
+	if err := s.callRepo.Create(ctx, call); err != nil {
+		s.logger.Error("Failed to record inbound call", err, map[string]interface{}{
+			"provider_call_id": event.CallID,
+			"business_id":      businessID,
+		})
+	}
+}
+
+func (s *CallService) handleCallEvent(ctx context.Context, event *providers.CallEvent) error {
 	// Get call from database using provider call ID
 	call, err := s.callRepo.GetByProviderCallID(ctx, event.CallID)
 	if err != nil {
@@ -119,14 +418,26 @@ func (s *CallService) HandleWebhook(ctx context.Context, payload []byte, signatu
 		return err
 	}
 
+	return s.applyCallEvent(ctx, call, event)
+}
+
+// applyCallEvent moves the call on to the state the provider reported and
+// starts the follow-ups of a call that has just ended
+func (s *CallService) applyCallEvent(ctx context.Context, call *entities.Call, event *providers.CallEvent) error {
+	alreadyEnded := call.IsCompleted()
+
 	// Update call status based on event
 	switch event.Type {
 	case "call.started":
 		call.UpdateStatus(entities.CallStatusInProgress)
-	case "call.ended", "call.completed":
-		call.UpdateStatus(entities.CallStatusCompleted)
-		// Fetch and store transcript
-		go s.fetchAndStoreTranscript(context.Background(), call.ID, event.CallID)
+	case "call.ended", "call.completed", providers.CallEventTypeEndOfCallReport:
+		status := endedCallStatus(event.Outcome)
+		call.UpdateStatus(status)
+		// Fetch and store transcript. The fetch outlives the request, so it
+		// runs as system work rather than in the request's scope.
+		if status == entities.CallStatusCompleted && !alreadyEnded {
+			go s.fetchAndStoreTranscript(database.Unscoped(context.Background()), call, event.CallID)
+		}
 	case "call.failed":
 		call.UpdateStatus(entities.CallStatusFailed)
 	}
@@ -139,9 +450,130 @@ func (s *CallService) HandleWebhook(ctx context.Context, payload []byte, signatu
 		return err
 	}
 
+	if s.messages != nil && !alreadyEnded && call.IsCompleted() && event.Analysis != nil {
+		if err := s.messages.HandleCallAnalysis(ctx, call, event.Analysis); err != nil {
+			s.logger.Error("Failed to extract message from call", err, map[string]interface{}{
+				"call_id": call.ID,
+			})
+		}
+	}
+
+	if !alreadyEnded && call.IsCompleted() && event.Analysis != nil {
+		if err := s.recordAnalysedInteraction(ctx, call, event.Analysis); err != nil {
+			s.logger.Error("Failed to record interaction from call", err, map[string]interface{}{
+				"call_id": call.ID,
+			})
+		}
+	}
+
+	if s.callbacks != nil && !alreadyEnded && call.IsCompleted() {
+		if err := s.callbacks.HandleCallEnded(ctx, call); err != nil {
+			s.logger.Error("Failed to schedule callback", err, map[string]interface{}{
+				"call_id": call.ID,
+			})
+		}
+	}
+
 	return nil
 }
 
+// recordAnalysedInteraction stores the interaction fields the provider
+// extracted from a finished call, as asked by the assistant's template. The
+// fields the caller did not give are left out.
+func (s *CallService) recordAnalysedInteraction(ctx context.Context, call *entities.Call, analysis map[string]interface{}) error {
+	data, ok := analysis[interactionAnalysisKey].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	content := make(map[string]interface{}, len(data))
+	for name, value := range data {
+		if name == "type" || value == nil || value == "" {
+			continue
+		}
+		content[name] = value
+	}
+	if len(content) == 0 {
+		return nil
+	}
+
+	interactionType := entities.InteractionTypeOther
+	for _, analysed := range analysedInteractionTypes {
+		if string(analysed) == stringArg(data, "type") {
+			interactionType = analysed
+		}
+	}
+
+	interaction, err := entities.NewInteraction(call.ID, interactionType, content)
+	if err != nil {
+		return err
+	}
+	return s.interactionRepo.Create(ctx, interaction)
+}
+
+// ReconcileCall brings a call up to date with the provider, for calls whose
+// webhooks were missed. It reports whether the call's status changed.
+func (s *CallService) ReconcileCall(ctx context.Context, call *entities.Call) (bool, error) {
+	if call.ProviderCallID == "" {
+		return false, errors.NewValidationError("call was never placed with the provider")
+	}
+
+	details, err := s.voiceProvider.GetCallDetails(ctx, call.ProviderCallID)
+	if err != nil {
+		return false, err
+	}
+
+	event := &providers.CallEvent{CallID: call.ProviderCallID, Outcome: details.Outcome}
+	switch {
+	case details.Outcome != "":
+		event.Type = providers.CallEventTypeEndOfCallReport
+	case details.StartedAt != nil && call.Status != entities.CallStatusInProgress && !call.IsCompleted():
+		event.Type = "call.started"
+	default:
+		return false, nil
+	}
+
+	// The provider's times are kept over those of the reconciliation
+	if call.StartedAt == nil {
+		call.StartedAt = details.StartedAt
+	}
+	if details.Outcome != "" && call.EndedAt == nil && details.EndedAt != nil {
+		call.EndedAt = details.EndedAt
+		call.Duration = details.Duration
+	}
+	if details.Cost > 0 {
+		call.SetCost(details.Cost)
+	}
+
+	before := call.Status
+	if err := s.applyCallEvent(ctx, call, event); err != nil {
+		return false, err
+	}
+
+	s.logger.Info("Reconciled call with provider", map[string]interface{}{
+		"call_id":     call.ID,
+		"status_from": string(before),
+		"status_to":   string(call.Status),
+	})
+
+	return call.Status != before, nil
+}
+
+// endedCallStatus maps the outcome reported by the provider to a call status.
+// Providers that do not report an outcome are treated as completed.
+func endedCallStatus(outcome string) entities.CallStatus {
+	switch outcome {
+	case providers.CallOutcomeNoAnswer:
+		return entities.CallStatusNoAnswer
+	case providers.CallOutcomeBusy:
+		return entities.CallStatusBusy
+	case providers.CallOutcomeFailed:
+		return entities.CallStatusFailed
+	default:
+		return entities.CallStatusCompleted
+	}
+}
+
 func (s *CallService) GetCall(ctx context.Context, businessID, callID string) (*dto.CallResponse, error) {
 	call, err := s.callRepo.GetByID(ctx, callID)
 	if err != nil {
@@ -215,7 +647,8 @@ func (s *CallService) GetTranscript(ctx context.Context, businessID, callID stri
 	return response, nil
 }
 
-func (s *CallService) fetchAndStoreTranscript(ctx context.Context, callID, providerCallID string) {
+func (s *CallService) fetchAndStoreTranscript(ctx context.Context, call *entities.Call, providerCallID string) {
+	callID := call.ID
 	transcript, err := s.voiceProvider.GetTranscript(ctx, providerCallID)
 	if err != nil {
 		s.logger.Error("Failed to fetch transcript from provider", err, map[string]interface{}{
@@ -248,6 +681,10 @@ func (s *CallService) fetchAndStoreTranscript(ctx context.Context, callID, provi
 			"message_count": len(transcriptEntities),
 		})
 	}
+
+	if s.compliance != nil {
+		s.compliance.HandleTranscript(ctx, call, transcriptEntities)
+	}
 }
 
 func (s *CallService) mapCallToResponse(call *entities.Call) *dto.CallResponse {
@@ -256,6 +693,7 @@ func (s *CallService) mapCallToResponse(call *entities.Call) *dto.CallResponse {
 		BusinessID:     call.BusinessID,
 		ProviderCallID: call.ProviderCallID,
 		CallerPhone:    call.CallerPhone,
+		Direction:      string(call.Direction),
 		Duration:       call.Duration,
 		Status:         string(call.Status),
 		Cost:           call.Cost,
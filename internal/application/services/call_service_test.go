@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"sort"
 	"testing"
 	"time"
 
@@ -14,6 +15,81 @@ import (
 )
 
 // Extended mock for CallRepository with function fields
+func TestCallService_InitiateCallScopesItsOwnWork(t *testing.T) {
+	log := logger.New("error", "console")
+
+	tests := []struct {
+		name          string
+		providerErr   error
+		expectedError bool
+		wantStatus    entities.CallStatus
+	}{
+		{
+			name:       "provider places the call",
+			wantStatus: entities.CallStatusInitiated,
+		},
+		{
+			name:          "provider refuses the call",
+			providerErr:   errors.New("provider API unavailable"),
+			expectedError: true,
+			wantStatus:    entities.CallStatusFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope := &mockTenantScope{}
+			callRepo := newTestCallRepository()
+			callRepo.createFunc = func(ctx context.Context, call *entities.Call) error {
+				if scope.current != "tenant" {
+					t.Errorf("call created in %q, want in the tenant transaction", scope.current)
+				}
+				call.ID = "call-1"
+				callRepo.calls[call.ID] = call
+				return nil
+			}
+			callRepo.updateFunc = func(ctx context.Context, call *entities.Call) error {
+				if scope.current != "detached" {
+					t.Errorf("call updated in %q, want in a detached transaction", scope.current)
+				}
+				stored := *call
+				callRepo.calls[call.ID] = &stored
+				return nil
+			}
+			provider := &testVoiceProvider{
+				initiateCallFunc: func(ctx context.Context, req providers.CallRequest) (*providers.CallSession, error) {
+					if scope.current != "" {
+						t.Errorf("provider called in %q, want outside any transaction", scope.current)
+					}
+					if tt.providerErr != nil {
+						return nil, tt.providerErr
+					}
+					return &providers.CallSession{ID: "provider-call-123", Status: "initiated"}, nil
+				},
+			}
+			businessRepo := &mockBusinessRepository{businesses: map[string]*entities.Business{
+				"business-123": {ID: "business-123", Name: "Smith Dental"},
+			}}
+
+			service := NewCallService(callRepo, newTestTranscriptRepository(), newTestInteractionRepository(), businessRepo, provider, log)
+			service.SetTenantScope(scope)
+
+			_, err := service.InitiateCall(context.Background(), "business-123", dto.InitiateCallRequest{PhoneNumber: "+15551234567"})
+			if (err != nil) != tt.expectedError {
+				t.Fatalf("InitiateCall() error = %v, expectedError %v", err, tt.expectedError)
+			}
+
+			stored := callRepo.calls["call-1"]
+			if stored == nil {
+				t.Fatal("expected the call to be kept")
+			}
+			if stored.Status != tt.wantStatus {
+				t.Errorf("stored status = %s, want %s", stored.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
 type testCallRepository struct {
 	calls                   map[string]*entities.Call
 	createFunc              func(ctx context.Context, call *entities.Call) error
@@ -93,13 +169,37 @@ func (m *testCallRepository) Delete(ctx context.Context, id string) error {
 }
 
 func (m *testCallRepository) GetByDateRange(ctx context.Context, businessID string, startDate, endDate time.Time) ([]*entities.Call, error) {
-	return nil, errors.New("not implemented")
+	var result []*entities.Call
+	for _, call := range m.calls {
+		if call.BusinessID == businessID && !call.CreatedAt.Before(startDate) && !call.CreatedAt.After(endDate) {
+			result = append(result, call)
+		}
+	}
+	return result, nil
 }
 
 func (m *testCallRepository) GetStats(ctx context.Context, businessID string, startDate, endDate time.Time) (*database.CallStats, error) {
 	return nil, errors.New("not implemented")
 }
 
+func (m *testCallRepository) GetUsageByBusiness(ctx context.Context, startDate, endDate time.Time) ([]*database.BusinessUsage, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *testCallRepository) GetUnfinished(ctx context.Context, createdBefore time.Time, limit int) ([]*entities.Call, error) {
+	var result []*entities.Call
+	for _, call := range m.calls {
+		if !call.IsCompleted() && call.CreatedAt.Before(createdBefore) {
+			result = append(result, call)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
 // Extended mock for TranscriptRepository
 type testTranscriptRepository struct {
 	transcripts     map[string][]*entities.Transcript
@@ -157,12 +257,14 @@ func (m *testTranscriptRepository) GetByID(ctx context.Context, id string) (*ent
 
 // Extended mock for InteractionRepository
 type testInteractionRepository struct {
-	interactions map[string][]*entities.Interaction
+	interactions  map[string][]*entities.Interaction
+	byCallerPhone map[string][]*entities.Interaction
 }
 
 func newTestInteractionRepository() *testInteractionRepository {
 	return &testInteractionRepository{
-		interactions: make(map[string][]*entities.Interaction),
+		interactions:  make(map[string][]*entities.Interaction),
+		byCallerPhone: make(map[string][]*entities.Interaction),
 	}
 }
 
@@ -182,7 +284,14 @@ func (m *testInteractionRepository) GetByCallID(ctx context.Context, callID stri
 }
 
 func (m *testInteractionRepository) GetByID(ctx context.Context, id string) (*entities.Interaction, error) {
-	return nil, errors.New("not implemented")
+	for _, interactions := range m.interactions {
+		for _, interaction := range interactions {
+			if interaction.ID == id {
+				return interaction, nil
+			}
+		}
+	}
+	return nil, errors.New("interaction not found")
 }
 
 func (m *testInteractionRepository) Delete(ctx context.Context, id string) error {
@@ -193,6 +302,22 @@ func (m *testInteractionRepository) List(ctx context.Context, businessID string,
 	return nil, errors.New("not implemented")
 }
 
+func (m *testInteractionRepository) GetByCallerPhone(ctx context.Context, businessID, callerPhone string, types []entities.InteractionType, limit int) ([]*entities.Interaction, error) {
+	return m.byCallerPhone[callerPhone], nil
+}
+
+func (m *testInteractionRepository) GetByType(ctx context.Context, businessID string, interactionType entities.InteractionType, startDate, endDate time.Time) ([]*entities.Interaction, error) {
+	var matched []*entities.Interaction
+	for _, interactions := range m.interactions {
+		for _, interaction := range interactions {
+			if interaction.Type == interactionType && !interaction.Timestamp.Before(startDate) && !interaction.Timestamp.After(endDate) {
+				matched = append(matched, interaction)
+			}
+		}
+	}
+	return matched, nil
+}
+
 // Extended mock for VoiceProvider
 type testVoiceProvider struct {
 	initiateCallFunc func(ctx context.Context, req providers.CallRequest) (*providers.CallSession, error)
@@ -245,18 +370,19 @@ func TestCallService_InitiateCall(t *testing.T) {
 	log := logger.New("info", "console")
 
 	tests := []struct {
-		name          string
-		businessID    string
-		request       dto.InitiateCallRequest
-		setupMocks    func(*testCallRepository, *testVoiceProvider)
-		expectedError bool
-		validateResp  func(*testing.T, *dto.CallResponse)
+		name                string
+		businessID          string
+		request             dto.InitiateCallRequest
+		setupMocks          func(*testCallRepository, *testVoiceProvider)
+		businessUnavailable bool
+		expectedError       bool
+		validateResp        func(*testing.T, *dto.CallResponse)
 	}{
 		{
 			name:       "successful call initiation",
 			businessID: "business-123",
 			request: dto.InitiateCallRequest{
-				PhoneNumber: "+1234567890",
+				PhoneNumber: "+15551234567",
 				AssistantID: "assistant-1",
 				Metadata: map[string]interface{}{
 					"customer": "John Doe",
@@ -267,14 +393,14 @@ func TestCallService_InitiateCall(t *testing.T) {
 					return &providers.CallSession{
 						ID:          "provider-call-123",
 						Status:      "initiated",
-						PhoneNumber: "+1234567890",
+						PhoneNumber: "+15551234567",
 					}, nil
 				}
 			},
 			expectedError: false,
 			validateResp: func(t *testing.T, resp *dto.CallResponse) {
-				if resp.CallerPhone != "+1234567890" {
-					t.Errorf("expected phone +1234567890, got %s", resp.CallerPhone)
+				if resp.CallerPhone != "+15551234567" {
+					t.Errorf("expected phone +15551234567, got %s", resp.CallerPhone)
 				}
 				if resp.Status == "" {
 					t.Error("expected status to be set")
@@ -284,6 +410,75 @@ func TestCallService_InitiateCall(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:       "national phone number is normalised",
+			businessID: "business-123",
+			request: dto.InitiateCallRequest{
+				PhoneNumber: "(555) 123-4567",
+			},
+			setupMocks: func(callRepo *testCallRepository, provider *testVoiceProvider) {
+				provider.initiateCallFunc = func(ctx context.Context, req providers.CallRequest) (*providers.CallSession, error) {
+					if req.PhoneNumber != "+15551234567" {
+						return nil, errors.New("provider received unnormalised number " + req.PhoneNumber)
+					}
+					if req.AssistantID != "assistant-default" {
+						return nil, errors.New("provider received assistant " + req.AssistantID + " instead of the business's")
+					}
+					return &providers.CallSession{ID: "provider-call-456", Status: "initiated"}, nil
+				}
+			},
+			expectedError: false,
+			validateResp: func(t *testing.T, resp *dto.CallResponse) {
+				if resp.CallerPhone != "+15551234567" {
+					t.Errorf("expected phone +15551234567, got %s", resp.CallerPhone)
+				}
+			},
+		},
+		{
+			name:       "business lookup fails",
+			businessID: "business-123",
+			request: dto.InitiateCallRequest{
+				PhoneNumber: "+15551234567",
+			},
+			setupMocks: func(callRepo *testCallRepository, provider *testVoiceProvider) {
+				provider.initiateCallFunc = func(ctx context.Context, req providers.CallRequest) (*providers.CallSession, error) {
+					t.Error("expected no call to be placed without checking the business")
+					return &providers.CallSession{ID: "provider-call-123", Status: "initiated"}, nil
+				}
+			},
+			businessUnavailable: true,
+			expectedError:       true,
+		},
+		{
+			name:       "no assistant configured",
+			businessID: "business-without-assistant",
+			request: dto.InitiateCallRequest{
+				PhoneNumber: "+15551234567",
+			},
+			setupMocks: func(callRepo *testCallRepository, provider *testVoiceProvider) {
+				provider.initiateCallFunc = func(ctx context.Context, req providers.CallRequest) (*providers.CallSession, error) {
+					if req.AssistantID != "" {
+						return nil, errors.New("provider received assistant " + req.AssistantID)
+					}
+					return &providers.CallSession{ID: "provider-call-789", Status: "initiated"}, nil
+				}
+			},
+			expectedError: false,
+			validateResp: func(t *testing.T, resp *dto.CallResponse) {
+				if resp.ProviderCallID != "provider-call-789" {
+					t.Errorf("expected the call to be placed without an assistant, got %+v", resp)
+				}
+			},
+		},
+		{
+			name:       "unparseable phone number",
+			businessID: "business-123",
+			request: dto.InitiateCallRequest{
+				PhoneNumber: "12345",
+			},
+			setupMocks:    func(callRepo *testCallRepository, provider *testVoiceProvider) {},
+			expectedError: true,
+		},
 		{
 			name:       "missing phone number",
 			businessID: "business-123",
@@ -297,7 +492,7 @@ func TestCallService_InitiateCall(t *testing.T) {
 			name:       "provider fails to initiate call",
 			businessID: "business-123",
 			request: dto.InitiateCallRequest{
-				PhoneNumber: "+1234567890",
+				PhoneNumber: "+15551234567",
 			},
 			setupMocks: func(callRepo *testCallRepository, provider *testVoiceProvider) {
 				provider.initiateCallFunc = func(ctx context.Context, req providers.CallRequest) (*providers.CallSession, error) {
@@ -310,7 +505,7 @@ func TestCallService_InitiateCall(t *testing.T) {
 			name:       "database error on create",
 			businessID: "business-123",
 			request: dto.InitiateCallRequest{
-				PhoneNumber: "+1234567890",
+				PhoneNumber: "+15551234567",
 			},
 			setupMocks: func(callRepo *testCallRepository, provider *testVoiceProvider) {
 				callRepo.createFunc = func(ctx context.Context, call *entities.Call) error {
@@ -320,7 +515,7 @@ func TestCallService_InitiateCall(t *testing.T) {
 					return &providers.CallSession{
 						ID:          "provider-call-123",
 						Status:      "initiated",
-						PhoneNumber: "+1234567890",
+						PhoneNumber: "+15551234567",
 					}, nil
 				}
 			},
@@ -337,10 +532,25 @@ func TestCallService_InitiateCall(t *testing.T) {
 
 			tt.setupMocks(callRepo, provider)
 
+			var businessRepo database.BusinessRepository = &mockBusinessRepository{businesses: map[string]*entities.Business{
+				"business-123": {
+					ID:   "business-123",
+					Name: "Smith Dental",
+					Settings: testSettings(func(s *entities.BusinessSettings) {
+						s.AssistantID = "assistant-default"
+					}),
+				},
+				"business-without-assistant": {ID: "business-without-assistant", Name: "New Business"},
+			}}
+			if tt.businessUnavailable {
+				businessRepo = &unavailableBusinessRepository{businessRepo.(*mockBusinessRepository)}
+			}
+
 			service := NewCallService(
 				callRepo,
 				transcriptRepo,
 				interactionRepo,
+				businessRepo,
 				provider,
 				log,
 			)
@@ -384,7 +594,7 @@ func TestCallService_HandleWebhook(t *testing.T) {
 			signature: "valid-signature",
 			setupMocks: func(callRepo *testCallRepository, provider *testVoiceProvider) {
 				// Create a call first
-				call, _ := entities.NewCall("business-123", "+1234567890")
+				call, _ := entities.NewCall("business-123", "+15551234567")
 				call.ID = "call-123"
 				call.ProviderCallID = "provider-123"
 				call.Status = entities.CallStatusInitiated
@@ -407,7 +617,7 @@ func TestCallService_HandleWebhook(t *testing.T) {
 			signature: "valid-signature",
 			setupMocks: func(callRepo *testCallRepository, provider *testVoiceProvider) {
 				startTime := time.Now().Add(-2 * time.Minute)
-				call, _ := entities.NewCall("business-123", "+1234567890")
+				call, _ := entities.NewCall("business-123", "+15551234567")
 				call.ID = "call-123"
 				call.ProviderCallID = "provider-123"
 				call.Status = entities.CallStatusInProgress
@@ -470,6 +680,7 @@ func TestCallService_HandleWebhook(t *testing.T) {
 				callRepo,
 				transcriptRepo,
 				interactionRepo,
+				&mockBusinessRepository{businesses: make(map[string]*entities.Business)},
 				provider,
 				log,
 			)
@@ -508,7 +719,7 @@ func TestCallService_GetCall(t *testing.T) {
 			businessID: businessID,
 			callID:     callID,
 			setupMocks: func(callRepo *testCallRepository) {
-				call, _ := entities.NewCall(businessID, "+1234567890")
+				call, _ := entities.NewCall(businessID, "+15551234567")
 				call.ID = callID
 				call.ProviderCallID = "provider-123"
 				call.Status = entities.CallStatusCompleted
@@ -539,7 +750,7 @@ func TestCallService_GetCall(t *testing.T) {
 			businessID: "different-business",
 			callID:     callID,
 			setupMocks: func(callRepo *testCallRepository) {
-				call, _ := entities.NewCall(businessID, "+1234567890")
+				call, _ := entities.NewCall(businessID, "+15551234567")
 				call.ID = callID
 				callRepo.calls[callID] = call
 			},
@@ -556,6 +767,7 @@ func TestCallService_GetCall(t *testing.T) {
 				callRepo,
 				newTestTranscriptRepository(),
 				newTestInteractionRepository(),
+				&mockBusinessRepository{businesses: make(map[string]*entities.Business)},
 				&testVoiceProvider{},
 				log,
 			)
@@ -600,7 +812,7 @@ name:   "successful get transcript with messages",
 callID: callID,
 setupMocks: func(callRepo *testCallRepository, transcriptRepo *testTranscriptRepository) {
 // Setup call
-call, _ := entities.NewCall(businessID, "+1234567890")
+call, _ := entities.NewCall(businessID, "+15551234567")
 call.ID = callID
 call.ProviderCallID = "provider-123"
 call.Status = "completed"
@@ -639,7 +851,7 @@ name:   "no transcript found",
 callID: callID,
 setupMocks: func(callRepo *testCallRepository, transcriptRepo *testTranscriptRepository) {
 // Setup call
-call, _ := entities.NewCall(businessID, "+1234567890")
+call, _ := entities.NewCall(businessID, "+15551234567")
 call.ID = callID
 call.ProviderCallID = "provider-123"
 call.Status = "completed"
@@ -654,7 +866,7 @@ name:   "database error",
 callID: callID,
 setupMocks: func(callRepo *testCallRepository, transcriptRepo *testTranscriptRepository) {
 // Setup call
-call, _ := entities.NewCall(businessID, "+1234567890")
+call, _ := entities.NewCall(businessID, "+15551234567")
 call.ID = callID
 call.ProviderCallID = "provider-123"
 call.Status = "completed"
@@ -678,6 +890,7 @@ service := NewCallService(
 callRepo,
 transcriptRepo,
 newTestInteractionRepository(),
+&mockBusinessRepository{businesses: make(map[string]*entities.Business)},
 &testVoiceProvider{},
 log,
 )
@@ -705,3 +918,159 @@ t.Errorf("expected %d messages, got %d", tt.expectedCount, len(response.Messages
 }
 }
 
+
+func TestCallService_HandleWebhookOutcome(t *testing.T) {
+	tests := []struct {
+		name    string
+		outcome string
+		want    entities.CallStatus
+	}{
+		{name: "answered", outcome: providers.CallOutcomeCompleted, want: entities.CallStatusCompleted},
+		{name: "no outcome reported", outcome: "", want: entities.CallStatusCompleted},
+		{name: "no answer", outcome: providers.CallOutcomeNoAnswer, want: entities.CallStatusNoAnswer},
+		{name: "busy", outcome: providers.CallOutcomeBusy, want: entities.CallStatusBusy},
+		{name: "failed", outcome: providers.CallOutcomeFailed, want: entities.CallStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			callRepo := newTestCallRepository()
+			call, _ := entities.NewCall("business-123", "+15551234567")
+			call.ID = "call-123"
+			call.ProviderCallID = "provider-123"
+			callRepo.calls[call.ID] = call
+
+			provider := &testVoiceProvider{
+				handleWebhookFunc: func(ctx context.Context, payload []byte, signature string) (*providers.CallEvent, error) {
+					return &providers.CallEvent{
+						Type:    providers.CallEventTypeEndOfCallReport,
+						CallID:  "provider-123",
+						Outcome: tt.outcome,
+					}, nil
+				},
+				getTranscriptFunc: func(ctx context.Context, callID string) (*providers.Transcript, error) {
+					return &providers.Transcript{CallID: callID}, nil
+				},
+			}
+
+			service := NewCallService(
+				callRepo,
+				newTestTranscriptRepository(),
+				newTestInteractionRepository(),
+				&mockBusinessRepository{businesses: make(map[string]*entities.Business)},
+				provider,
+				logger.New("info", "console"),
+			)
+
+			if err := service.HandleWebhook(context.Background(), []byte(`{}`), "valid-signature"); err != nil {
+				t.Fatalf("HandleWebhook() error = %v", err)
+			}
+
+			if call.Status != tt.want {
+				t.Errorf("expected status %s, got %s", tt.want, call.Status)
+			}
+		})
+	}
+}
+
+// Voice provider that answers assistant requests with the selection itself
+type testAssistantProvider struct {
+	testVoiceProvider
+}
+
+func (m *testAssistantProvider) BuildAssistantResponse(selection providers.AssistantSelection) interface{} {
+	return selection
+}
+
+// Business repository whose lookups by ID always fail
+type unavailableBusinessRepository struct {
+	*mockBusinessRepository
+}
+
+func (m *unavailableBusinessRepository) GetByID(ctx context.Context, id string) (*entities.Business, error) {
+	return nil, errors.New("connection reset")
+}
+
+// Business repository whose phone lookups fail a number of times first
+type flakyBusinessRepository struct {
+	*mockBusinessRepository
+	failures int
+}
+
+func (m *flakyBusinessRepository) GetByPhone(ctx context.Context, phone string) (*entities.Business, error) {
+	if m.failures > 0 {
+		m.failures--
+		return nil, errors.New("connection reset")
+	}
+	return m.mockBusinessRepository.GetByPhone(ctx, phone)
+}
+
+func TestCallService_AssistantRequestFallsBackToDefaultAssistant(t *testing.T) {
+	tests := []struct {
+		name          string
+		failures      int
+		expectedError bool
+	}{
+		{name: "selection succeeds", failures: 0},
+		{name: "selection fails and the default is used", failures: 1},
+		{name: "both fail", failures: 2, expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := logger.New("info", "console")
+			businessRepo := &flakyBusinessRepository{
+				mockBusinessRepository: &mockBusinessRepository{businesses: map[string]*entities.Business{
+					"business-123": {
+						ID:    "business-123",
+						Name:  "Smith Dental",
+						Phone: "+15550000000",
+						Settings: testSettings(func(s *entities.BusinessSettings) {
+							s.AssistantID = "assistant-1"
+						}),
+					},
+				}},
+				failures: tt.failures,
+			}
+
+			provider := &testAssistantProvider{}
+			provider.handleWebhookFunc = func(ctx context.Context, payload []byte, signature string) (*providers.CallEvent, error) {
+				return &providers.CallEvent{
+					Type:          providers.CallEventTypeAssistantRequest,
+					CallID:        "provider-call-123",
+					BusinessPhone: "+15550000000",
+					CustomerPhone: "+15551234567",
+				}, nil
+			}
+
+			callRepo := newTestCallRepository()
+			service := NewCallService(callRepo, newTestTranscriptRepository(), newTestInteractionRepository(), businessRepo, provider, log)
+			service.SetCallerRecognition(NewCallerRecognitionService(businessRepo, &testAppointmentRepository{}, newTestInteractionRepository(), log))
+
+			response, err := service.ProcessWebhook(context.Background(), []byte(`{}`), "valid-signature")
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessWebhook() error = %v", err)
+			}
+
+			selection, ok := response.(providers.AssistantSelection)
+			if !ok {
+				t.Fatalf("expected an assistant selection, got %#v", response)
+			}
+			if selection.AssistantID != "assistant-1" {
+				t.Errorf("expected assistant-1, got %s", selection.AssistantID)
+			}
+			if selection.AssistantConfig == nil || selection.AssistantConfig.Metadata["business_id"] != "business-123" {
+				t.Errorf("expected business_id in metadata, got %+v", selection.AssistantConfig)
+			}
+			if len(callRepo.calls) != 1 {
+				t.Errorf("expected the inbound call to be recorded, got %d calls", len(callRepo.calls))
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+)
+
+// CallTool is a function the assistant can invoke during a call
+type CallTool interface {
+	// Name is the function name the assistant calls the tool by
+	Name() string
+
+	// Definition describes the function to the business's assistant. It
+	// returns nil when the tool is not available to the business.
+	Definition(ctx context.Context, businessID string) (*providers.Function, error)
+
+	// Invoke runs the function for the call and returns the text read back
+	// to the assistant
+	Invoke(ctx context.Context, call *entities.Call, args map[string]interface{}) (string, error)
+}
+
+// AddTool exposes a function to the assistant on every call
+func (s *CallService) AddTool(tool CallTool) {
+	s.tools = append(s.tools, tool)
+}
+
+// assistantFunctions lists the functions available to the business's
+// assistant. A tool that fails to describe itself is left out so the call
+// can still go ahead.
+func (s *CallService) assistantFunctions(ctx context.Context, businessID string) []providers.Function {
+	var functions []providers.Function
+	for _, tool := range s.tools {
+		function, err := tool.Definition(ctx, businessID)
+		if err != nil {
+			s.logger.Error("Failed to describe assistant function", err, map[string]interface{}{
+				"business_id": businessID,
+				"function":    tool.Name(),
+			})
+			continue
+		}
+		if function != nil {
+			functions = append(functions, *function)
+		}
+	}
+	return functions
+}
+
+func (s *CallService) tool(name string) CallTool {
+	for _, tool := range s.tools {
+		if tool.Name() == name {
+			return tool
+		}
+	}
+	return nil
+}
+
+// handleToolCalls runs the functions the assistant invoked and replies with
+// their results
+func (s *CallService) handleToolCalls(ctx context.Context, event *providers.CallEvent) (interface{}, error) {
+	responder, ok := s.voiceProvider.(providers.ToolCallResponder)
+	if !ok {
+		return nil, errors.NewInvalidInputError("tool calls are not supported")
+	}
+
+	call, err := s.callRepo.GetByProviderCallID(ctx, event.CallID)
+	if err != nil {
+		s.logger.Error("Call not found for tool call", err, map[string]interface{}{
+			"provider_call_id": event.CallID,
+		})
+		return nil, err
+	}
+
+	results := make([]providers.ToolCallResult, 0, len(event.ToolCalls))
+	for _, toolCall := range event.ToolCalls {
+		result := providers.ToolCallResult{ToolCallID: toolCall.ID, Name: toolCall.Name}
+
+		tool := s.tool(toolCall.Name)
+		if tool == nil {
+			result.Error = "unknown function " + toolCall.Name
+			results = append(results, result)
+			continue
+		}
+
+		output, err := tool.Invoke(ctx, call, toolCall.Arguments)
+		if err != nil {
+			s.logger.Warn("Assistant function failed", map[string]interface{}{
+				"call_id":  call.ID,
+				"function": toolCall.Name,
+				"error":    err.Error(),
+			})
+			result.Error = toolErrorMessage(err)
+		} else {
+			result.Result = output
+		}
+		results = append(results, result)
+	}
+
+	return responder.BuildToolCallResponse(results), nil
+}
+
+// toolErrorMessage keeps internal details out of what the assistant is told
+func toolErrorMessage(err error) string {
+	var domainErr *errors.DomainError
+	if stderrors.As(err, &domainErr) && domainErr.Code != errors.ErrCodeInternal && domainErr.Code != errors.ErrCodeDatabaseError {
+		return domainErr.Message
+	}
+	return "the request could not be completed"
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+	"github.com/CallPilotReceptionist/pkg/phone"
+)
+
+const (
+	callerAppointmentLookupLimit = 20
+	callerIssueLookupLimit       = 3
+)
+
+// CallerRecognitionService looks up what a business already knows about a
+// caller so the assistant can greet returning customers personally
+type CallerRecognitionService struct {
+	businessRepo    database.BusinessRepository
+	appointmentRepo database.AppointmentRepository
+	interactionRepo database.InteractionRepository
+	logger          *logger.Logger
+}
+
+func NewCallerRecognitionService(
+	businessRepo database.BusinessRepository,
+	appointmentRepo database.AppointmentRepository,
+	interactionRepo database.InteractionRepository,
+	log *logger.Logger,
+) *CallerRecognitionService {
+	return &CallerRecognitionService{
+		businessRepo:    businessRepo,
+		appointmentRepo: appointmentRepo,
+		interactionRepo: interactionRepo,
+		logger:          log,
+	}
+}
+
+// LookupCaller builds a profile of the caller from their appointment and
+// complaint history with the business
+func (s *CallerRecognitionService) LookupCaller(ctx context.Context, businessID, phone string) (*entities.CallerProfile, error) {
+	profile := entities.NewCallerProfile(phone)
+
+	appointments, err := s.appointmentRepo.GetByCustomerPhone(ctx, businessID, phone, callerAppointmentLookupLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, apt := range appointments {
+		if profile.Name == "" && apt.CustomerName != "" {
+			profile.Name = apt.CustomerName
+		}
+		if apt.IsUpcoming(now) {
+			profile.UpcomingAppointments = append(profile.UpcomingAppointments, apt)
+		}
+	}
+
+	issues, err := s.interactionRepo.GetByCallerPhone(ctx, businessID, phone,
+		[]entities.InteractionType{entities.InteractionTypeComplaint}, callerIssueLookupLimit)
+	if err != nil {
+		return nil, err
+	}
+	profile.RecentIssues = issues
+
+	return profile, nil
+}
+
+// CallerContext returns assistant overrides carrying the caller's profile, or
+// nil when the business has not opted in or the caller is unknown
+func (s *CallerRecognitionService) CallerContext(ctx context.Context, business *entities.Business, phone string) (*providers.AssistantConfig, error) {
+	if !business.CallerRecognitionEnabled() {
+		return nil, nil
+	}
+
+	profile, err := s.LookupCaller(ctx, business.ID, phone)
+	if err != nil {
+		return nil, err
+	}
+
+	if !profile.IsKnown() {
+		return nil, nil
+	}
+
+	return &providers.AssistantConfig{
+		VariableValues: profile.Variables(),
+		Metadata: map[string]interface{}{
+			"caller_profile": profile.Reference(),
+		},
+	}, nil
+}
+
+// OutboundContext is CallerContext for a business referenced by ID
+func (s *CallerRecognitionService) OutboundContext(ctx context.Context, businessID, phone string) (*providers.AssistantConfig, error) {
+	business, err := s.businessRepo.GetByID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, errors.NewNotFoundError("business", businessID)
+	}
+
+	return s.CallerContext(ctx, business, phone)
+}
+
+// SelectAssistant answers a provider's assistant request for an inbound call
+func (s *CallerRecognitionService) SelectAssistant(ctx context.Context, event *providers.CallEvent) (*providers.AssistantSelection, error) {
+	business, err := s.inboundBusiness(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	assistantID := business.AssistantID()
+
+	overrides := &providers.AssistantConfig{}
+	if event.CustomerPhone != "" {
+		callerContext, err := s.inboundCallerContext(ctx, business, event.CustomerPhone)
+		if err != nil {
+			// The call must still be answered without the caller's history
+			s.logger.Warn("Failed to look up caller", map[string]interface{}{
+				"business_id": business.ID,
+				"error":       err.Error(),
+			})
+		} else if callerContext != nil {
+			overrides = callerContext
+		}
+	}
+
+	if overrides.Metadata == nil {
+		overrides.Metadata = map[string]interface{}{}
+	}
+	overrides.Metadata["business_id"] = business.ID
+
+	return &providers.AssistantSelection{
+		AssistantID:     assistantID,
+		AssistantConfig: overrides,
+	}, nil
+}
+
+// DefaultAssistant answers an assistant request with the business's saved
+// assistant alone, for when SelectAssistant fails
+func (s *CallerRecognitionService) DefaultAssistant(ctx context.Context, event *providers.CallEvent) (*providers.AssistantSelection, error) {
+	business, err := s.inboundBusiness(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &providers.AssistantSelection{
+		AssistantID: business.AssistantID(),
+		AssistantConfig: &providers.AssistantConfig{
+			Metadata: map[string]interface{}{"business_id": business.ID},
+		},
+	}, nil
+}
+
+// inboundBusiness finds the business that owns the dialled number and checks
+// it can answer calls
+func (s *CallerRecognitionService) inboundBusiness(ctx context.Context, event *providers.CallEvent) (*entities.Business, error) {
+	if event.BusinessPhone == "" {
+		return nil, errors.NewValidationError("assistant request is missing the dialled number")
+	}
+
+	businessPhone, err := normalizePhone("business_phone", event.BusinessPhone, phone.DefaultRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	business, err := s.businessRepo.GetByPhone(ctx, businessPhone)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, errors.NewNotFoundError("business", businessPhone)
+	}
+	if business.IsSuspended() {
+		return nil, errors.NewForbiddenError("business is suspended")
+	}
+	if business.AssistantID() == "" {
+		return nil, errors.NewValidationError("business has no assistant configured")
+	}
+	return business, nil
+}
+
+func (s *CallerRecognitionService) inboundCallerContext(ctx context.Context, business *entities.Business, rawPhone string) (*providers.AssistantConfig, error) {
+	customerPhone, err := normalizePhone("customer_phone", rawPhone, business.DefaultRegion())
+	if err != nil {
+		return nil, err
+	}
+	return s.CallerContext(ctx, business, customerPhone)
+}
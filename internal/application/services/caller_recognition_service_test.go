@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// Appointment repository backed by a slice
+type testAppointmentRepository struct {
+	appointments []*entities.AppointmentRequest
+}
+
+func (m *testAppointmentRepository) Create(ctx context.Context, appointment *entities.AppointmentRequest) error {
+	if appointment.ID == "" {
+		appointment.ID = "appointment-" + time.Now().Format("20060102150405.000000")
+	}
+	m.appointments = append(m.appointments, appointment)
+	return nil
+}
+
+func (m *testAppointmentRepository) GetByID(ctx context.Context, id string) (*entities.AppointmentRequest, error) {
+	for _, apt := range m.appointments {
+		if apt.ID == id {
+			return apt, nil
+		}
+	}
+	return nil, errors.New("appointment not found")
+}
+
+func (m *testAppointmentRepository) GetByCallID(ctx context.Context, callID string) (*entities.AppointmentRequest, error) {
+	for _, apt := range m.appointments {
+		if apt.CallID == callID {
+			return apt, nil
+		}
+	}
+	return nil, errors.New("appointment not found")
+}
+
+func (m *testAppointmentRepository) GetByBusinessID(ctx context.Context, businessID string, limit, offset int) ([]*entities.AppointmentRequest, error) {
+	var result []*entities.AppointmentRequest
+	for _, apt := range m.appointments {
+		if apt.BusinessID == businessID {
+			result = append(result, apt)
+		}
+	}
+	return result, nil
+}
+
+func (m *testAppointmentRepository) GetPendingAppointments(ctx context.Context, businessID string) ([]*entities.AppointmentRequest, error) {
+	var result []*entities.AppointmentRequest
+	for _, apt := range m.appointments {
+		if apt.BusinessID == businessID && apt.IsPending() {
+			result = append(result, apt)
+		}
+	}
+	return result, nil
+}
+
+func (m *testAppointmentRepository) GetByCustomerPhone(ctx context.Context, businessID, customerPhone string, limit int) ([]*entities.AppointmentRequest, error) {
+	var result []*entities.AppointmentRequest
+	for _, apt := range m.appointments {
+		if apt.BusinessID == businessID && apt.CustomerPhone == customerPhone {
+			result = append(result, apt)
+		}
+	}
+	return result, nil
+}
+
+func (m *testAppointmentRepository) Update(ctx context.Context, appointment *entities.AppointmentRequest) error {
+	return nil
+}
+
+func (m *testAppointmentRepository) Delete(ctx context.Context, id string) error {
+	return errors.New("not implemented")
+}
+
+func newTestCallerRecognitionService(settings entities.BusinessSettings) (*CallerRecognitionService, *testAppointmentRepository, *testInteractionRepository) {
+	businessRepo := &mockBusinessRepository{businesses: make(map[string]*entities.Business)}
+	businessRepo.businesses["business-123"] = &entities.Business{
+		ID:       "business-123",
+		Name:     "Smith Dental",
+		Phone:    "+15550000000",
+		Settings: settings,
+	}
+
+	appointmentRepo := &testAppointmentRepository{}
+	interactionRepo := newTestInteractionRepository()
+
+	service := NewCallerRecognitionService(businessRepo, appointmentRepo, interactionRepo, logger.New("info", "console"))
+	return service, appointmentRepo, interactionRepo
+}
+
+func TestCallerRecognitionService_LookupCaller(t *testing.T) {
+	service, appointmentRepo, interactionRepo := newTestCallerRecognitionService(entities.DefaultBusinessSettings())
+
+	tomorrow := time.Now().Add(24 * time.Hour)
+	lastMonth := time.Now().AddDate(0, -1, 0)
+
+	upcoming, _ := entities.NewAppointmentRequest("call-1", "business-123", "Jane Doe", "+15551234567", &tomorrow, "10:00 AM", "cleaning", "")
+	past, _ := entities.NewAppointmentRequest("call-2", "business-123", "Jane Doe", "+15551234567", &lastMonth, "9:00 AM", "checkup", "")
+	appointmentRepo.appointments = []*entities.AppointmentRequest{upcoming, past}
+
+	complaint, _ := entities.NewInteraction("call-2", entities.InteractionTypeComplaint, map[string]interface{}{
+		"summary": "billed twice for checkup",
+	})
+	interactionRepo.byCallerPhone["+15551234567"] = []*entities.Interaction{complaint}
+
+	profile, err := service.LookupCaller(context.Background(), "business-123", "+15551234567")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if profile.Name != "Jane Doe" {
+		t.Errorf("expected name Jane Doe, got %s", profile.Name)
+	}
+	if len(profile.UpcomingAppointments) != 1 {
+		t.Errorf("expected 1 upcoming appointment, got %d", len(profile.UpcomingAppointments))
+	}
+	if len(profile.RecentIssues) != 1 {
+		t.Errorf("expected 1 recent issue, got %d", len(profile.RecentIssues))
+	}
+
+	vars := profile.Variables()
+	if vars["recent_issues"] != "billed twice for checkup" {
+		t.Errorf("unexpected recent_issues variable: %v", vars["recent_issues"])
+	}
+}
+
+func TestCallerRecognitionService_SelectAssistant(t *testing.T) {
+	tests := []struct {
+		name          string
+		settings      entities.BusinessSettings
+		event         *providers.CallEvent
+		expectedError bool
+		expectCaller  bool
+	}{
+		{
+			name: "opted in with known caller",
+			settings: testSettings(func(s *entities.BusinessSettings) {
+				s.AssistantID = "assistant-1"
+				s.CallerRecognition = true
+			}),
+			event:        &providers.CallEvent{BusinessPhone: "+15550000000", CustomerPhone: "+15551234567"},
+			expectCaller: true,
+		},
+		{
+			name: "not opted in",
+			settings: testSettings(func(s *entities.BusinessSettings) {
+				s.AssistantID = "assistant-1"
+			}),
+			event:        &providers.CallEvent{BusinessPhone: "+15550000000", CustomerPhone: "+15551234567"},
+			expectCaller: false,
+		},
+		{
+			name: "opted in with unknown caller",
+			settings: testSettings(func(s *entities.BusinessSettings) {
+				s.AssistantID = "assistant-1"
+				s.CallerRecognition = true
+			}),
+			event:        &providers.CallEvent{BusinessPhone: "+15550000000", CustomerPhone: "+15559999999"},
+			expectCaller: false,
+		},
+		{
+			name:          "no assistant configured",
+			settings:      entities.DefaultBusinessSettings(),
+			event:         &providers.CallEvent{BusinessPhone: "+15550000000", CustomerPhone: "+15551234567"},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, appointmentRepo, _ := newTestCallerRecognitionService(tt.settings)
+
+			apt, _ := entities.NewAppointmentRequest("call-1", "business-123", "Jane Doe", "+15551234567", nil, "", "", "")
+			appointmentRepo.appointments = []*entities.AppointmentRequest{apt}
+
+			selection, err := service.SelectAssistant(context.Background(), tt.event)
+
+			if tt.expectedError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if selection.AssistantID != "assistant-1" {
+				t.Errorf("expected assistant-1, got %s", selection.AssistantID)
+			}
+			if selection.AssistantConfig.Metadata["business_id"] != "business-123" {
+				t.Error("expected business_id in metadata")
+			}
+
+			hasCaller := selection.AssistantConfig.VariableValues["customer_name"] == "Jane Doe"
+			if hasCaller != tt.expectCaller {
+				t.Errorf("caller context present = %v, want %v", hasCaller, tt.expectCaller)
+			}
+			if tt.expectCaller {
+				// Only the caller's reference is sent, not their history
+				reference, ok := selection.AssistantConfig.Metadata["caller_profile"].(map[string]interface{})
+				if !ok || len(reference) != 2 || reference["name"] != "Jane Doe" || reference["phone"] != "+15551234567" {
+					t.Errorf("unexpected caller_profile metadata: %#v", selection.AssistantConfig.Metadata["caller_profile"])
+				}
+			}
+		})
+	}
+}
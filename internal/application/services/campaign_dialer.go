@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"math"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+const (
+	defaultDialerInterval = 5 * time.Second
+	// campaignCallTimeout is how long a call may go without a final status
+	// before the dialer gives up on it and counts it as failed
+	campaignCallTimeout = 2 * time.Hour
+	// outsideWindowDelay is how long a contact waits after being blocked by
+	// the calling window before it is tried again
+	outsideWindowDelay = 15 * time.Minute
+	// maxTrackedCalls bounds how many in-flight calls are checked per campaign
+	maxTrackedCalls = 100
+)
+
+// CampaignDialer places the calls for running campaigns. Each tick it
+// collects the outcome of finished calls, then starts new ones within the
+// campaign's concurrency limit and call rate.
+type CampaignDialer struct {
+	campaignRepo database.CampaignRepository
+	contactRepo  database.CampaignContactRepository
+	callRepo     database.CallRepository
+	callService  *CallService
+	logger       *logger.Logger
+
+	interval time.Duration
+	now      func() time.Time
+	pacers   map[string]*dialPacer
+}
+
+func NewCampaignDialer(
+	campaignRepo database.CampaignRepository,
+	contactRepo database.CampaignContactRepository,
+	callRepo database.CallRepository,
+	callService *CallService,
+	log *logger.Logger,
+) *CampaignDialer {
+	return &CampaignDialer{
+		campaignRepo: campaignRepo,
+		contactRepo:  contactRepo,
+		callRepo:     callRepo,
+		callService:  callService,
+		logger:       log,
+		interval:     defaultDialerInterval,
+		now:          time.Now,
+		pacers:       make(map[string]*dialPacer),
+	}
+}
+
+// SetInterval changes how often the dialer runs
+func (d *CampaignDialer) SetInterval(interval time.Duration) {
+	if interval > 0 {
+		d.interval = interval
+	}
+}
+
+// Run dials until the context is cancelled
+func (d *CampaignDialer) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	d.logger.Info("Campaign dialer started", map[string]interface{}{
+		"interval": d.interval.String(),
+	})
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.Info("Campaign dialer stopped", nil)
+			return
+		case <-ticker.C:
+			if err := d.Tick(ctx); err != nil {
+				d.logger.Error("Campaign dialer tick failed", err, nil)
+			}
+		}
+	}
+}
+
+// Tick runs one dialling pass over every running campaign. It is not safe to
+// call concurrently.
+func (d *CampaignDialer) Tick(ctx context.Context) error {
+	// The dialer works across every business
+	ctx = database.Unscoped(ctx)
+
+	campaigns, err := d.campaignRepo.GetByStatus(ctx, entities.CampaignStatusRunning)
+	if err != nil {
+		return err
+	}
+
+	running := make(map[string]bool, len(campaigns))
+	for _, campaign := range campaigns {
+		running[campaign.ID] = true
+		if err := d.processCampaign(ctx, campaign); err != nil {
+			d.logger.Error("Failed to process campaign", err, map[string]interface{}{
+				"campaign_id": campaign.ID,
+			})
+		}
+	}
+
+	for id := range d.pacers {
+		if !running[id] {
+			delete(d.pacers, id)
+		}
+	}
+
+	return nil
+}
+
+func (d *CampaignDialer) processCampaign(ctx context.Context, campaign *entities.Campaign) error {
+	now := d.now()
+
+	calling, err := d.contactRepo.GetByCampaignID(ctx, campaign.ID, entities.CampaignContactStatusCalling, maxTrackedCalls, 0)
+	if err != nil {
+		return err
+	}
+
+	active := 0
+	for _, contact := range calling {
+		if d.collectOutcome(ctx, campaign, contact, now) {
+			active++
+		}
+	}
+
+	if campaign.HasEnded(now) {
+		if active == 0 {
+			return d.complete(ctx, campaign)
+		}
+		return nil
+	}
+	if !campaign.InSchedule(now) {
+		return nil
+	}
+
+	slots := campaign.MaxConcurrent - active
+	if slots <= 0 {
+		return nil
+	}
+
+	pacer := d.pacer(campaign)
+	allowed := pacer.available(now, campaign.CallsPerMinute, d.burst(campaign))
+	if allowed < slots {
+		slots = allowed
+	}
+	if slots <= 0 {
+		return nil
+	}
+
+	due, err := d.contactRepo.GetDue(ctx, campaign.ID, now, slots)
+	if err != nil {
+		return err
+	}
+
+	for _, contact := range due {
+		if d.dial(ctx, campaign, contact, now) {
+			pacer.take()
+		}
+	}
+
+	if len(due) == 0 && active == 0 {
+		counts, err := d.contactRepo.CountByStatus(ctx, campaign.ID)
+		if err != nil {
+			return err
+		}
+		if counts[entities.CampaignContactStatusPending] == 0 && counts[entities.CampaignContactStatusCalling] == 0 {
+			return d.complete(ctx, campaign)
+		}
+	}
+
+	return nil
+}
+
+// collectOutcome records the result of the contact's current call once it
+// has ended. It reports whether the call is still in progress.
+func (d *CampaignDialer) collectOutcome(ctx context.Context, campaign *entities.Campaign, contact *entities.CampaignContact, now time.Time) bool {
+	call, err := d.callRepo.GetByID(ctx, contact.CurrentCallID())
+	if err != nil {
+		d.logger.Warn("Failed to load campaign call", map[string]interface{}{
+			"campaign_contact_id": contact.ID,
+			"call_id":             contact.CurrentCallID(),
+			"error":               err.Error(),
+		})
+		return true
+	}
+
+	switch {
+	case call.IsCompleted():
+		contact.RecordOutcome(call.Status, campaign.RetryPolicy, now)
+	case now.Sub(call.CreatedAt) > campaignCallTimeout:
+		contact.LastError = "no final call status received"
+		contact.RecordOutcome(entities.CallStatusFailed, campaign.RetryPolicy, now)
+	default:
+		return true
+	}
+
+	if err := d.contactRepo.Update(ctx, contact); err != nil {
+		d.logger.Error("Failed to record campaign call outcome", err, map[string]interface{}{
+			"campaign_contact_id": contact.ID,
+		})
+	}
+	return false
+}
+
+// dial places a call to the contact. It reports whether a call was attempted,
+// which is what counts against the campaign's call rate.
+func (d *CampaignDialer) dial(ctx context.Context, campaign *entities.Campaign, contact *entities.CampaignContact, now time.Time) bool {
+	if compliance := d.callService.compliance; compliance != nil {
+		verdict, err := compliance.evaluate(ctx, campaign.BusinessID, contact.Phone)
+		if err != nil {
+			d.logger.Error("Failed to check campaign contact compliance", err, map[string]interface{}{
+				"campaign_contact_id": contact.ID,
+			})
+			return false
+		}
+		if !verdict.allowed() {
+			if verdict.outsideWindow {
+				contact.Defer(verdict.reason, now.Add(outsideWindowDelay))
+			} else {
+				contact.Skip(verdict.reason)
+			}
+			d.updateContact(ctx, contact)
+			return false
+		}
+	}
+
+	variables := make(map[string]interface{}, len(contact.Variables)+1)
+	for k, v := range contact.Variables {
+		variables[k] = v
+	}
+	if contact.Name != "" {
+		variables["customer_name"] = contact.Name
+	}
+
+	call, err := d.callService.InitiateCall(ctx, campaign.BusinessID, dto.InitiateCallRequest{
+		PhoneNumber: contact.Phone,
+		AssistantID: campaign.AssistantID,
+		Metadata: map[string]interface{}{
+			"campaign_id":         campaign.ID,
+			"campaign_contact_id": contact.ID,
+		},
+		Variables: variables,
+	})
+
+	var domainErr *errors.DomainError
+	switch {
+	case err != nil && stderrors.As(err, &domainErr) && domainErr.Code == errors.ErrCodeComplianceBlock:
+		contact.Skip(domainErr.Message)
+	case err != nil:
+		d.logger.Warn("Campaign call failed to start", map[string]interface{}{
+			"campaign_contact_id": contact.ID,
+			"error":               err.Error(),
+		})
+		contact.RecordDialError(err.Error(), campaign.RetryPolicy, now)
+	default:
+		contact.RecordAttempt(call.ID)
+	}
+
+	d.updateContact(ctx, contact)
+	return true
+}
+
+func (d *CampaignDialer) updateContact(ctx context.Context, contact *entities.CampaignContact) {
+	if err := d.contactRepo.Update(ctx, contact); err != nil {
+		d.logger.Error("Failed to update campaign contact", err, map[string]interface{}{
+			"campaign_contact_id": contact.ID,
+		})
+	}
+}
+
+func (d *CampaignDialer) complete(ctx context.Context, campaign *entities.Campaign) error {
+	campaign.Complete()
+	if err := d.campaignRepo.Update(ctx, campaign); err != nil {
+		return err
+	}
+
+	d.logger.Info("Campaign completed", map[string]interface{}{
+		"campaign_id": campaign.ID,
+	})
+	return nil
+}
+
+func (d *CampaignDialer) pacer(campaign *entities.Campaign) *dialPacer {
+	pacer, ok := d.pacers[campaign.ID]
+	if !ok {
+		pacer = &dialPacer{}
+		d.pacers[campaign.ID] = pacer
+	}
+	return pacer
+}
+
+// burst is the most calls a campaign may start in a single tick
+func (d *CampaignDialer) burst(campaign *entities.Campaign) int {
+	burst := int(math.Ceil(float64(campaign.CallsPerMinute) * d.interval.Minutes()))
+	if burst < 1 {
+		return 1
+	}
+	return burst
+}
+
+// dialPacer is a token bucket refilled at the campaign's calls-per-minute rate
+type dialPacer struct {
+	tokens  float64
+	updated time.Time
+}
+
+// available refills the bucket up to burst and returns the whole number of
+// calls that may start now
+func (p *dialPacer) available(now time.Time, perMinute, burst int) int {
+	if p.updated.IsZero() {
+		p.tokens = float64(burst)
+	} else if elapsed := now.Sub(p.updated); elapsed > 0 {
+		p.tokens += elapsed.Minutes() * float64(perMinute)
+	}
+	if p.tokens > float64(burst) {
+		p.tokens = float64(burst)
+	}
+	p.updated = now
+	return int(p.tokens)
+}
+
+func (p *dialPacer) take() {
+	p.tokens--
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// newMockCampaignDialer returns a dialer whose calls are created at *now,
+// recording the numbers it dials
+func newMockCampaignDialer(campaigns *mockCampaignRepository, contacts *mockCampaignContactRepository, callRepo *testCallRepository, now *time.Time, dialled *[]string) *CampaignDialer {
+	callRepo.createFunc = func(ctx context.Context, call *entities.Call) error {
+		call.ID = "call-" + strconv.Itoa(len(callRepo.calls)+1)
+		call.CreatedAt = *now
+		callRepo.calls[call.ID] = call
+		return nil
+	}
+
+	provider := &testVoiceProvider{
+		initiateCallFunc: func(ctx context.Context, req providers.CallRequest) (*providers.CallSession, error) {
+			*dialled = append(*dialled, req.PhoneNumber)
+			return &providers.CallSession{ID: "provider-" + strconv.Itoa(len(*dialled))}, nil
+		},
+	}
+
+	log := logger.New("info", "console")
+	callService := NewCallService(
+		callRepo,
+		newTestTranscriptRepository(),
+		newTestInteractionRepository(),
+		&mockBusinessRepository{businesses: make(map[string]*entities.Business)},
+		provider,
+		log,
+	)
+
+	dialer := NewCampaignDialer(campaigns, contacts, callRepo, callService, log)
+	dialer.now = func() time.Time { return *now }
+	return dialer
+}
+
+// newMockRunningCampaign starts a campaign for business-123 with a contact
+// per phone
+func newMockRunningCampaign(t *testing.T, campaigns *mockCampaignRepository, contacts *mockCampaignContactRepository, now time.Time, maxConcurrent, callsPerMinute int, phones ...string) *entities.Campaign {
+	t.Helper()
+
+	campaign, err := entities.NewCampaign("business-123", "Reminders", "assistant-1", maxConcurrent, callsPerMinute,
+		entities.DefaultRetryPolicy, now.Add(-time.Minute), nil)
+	if err != nil {
+		t.Fatalf("NewCampaign() error = %v", err)
+	}
+	campaigns.Create(context.Background(), campaign)
+	campaign.Start()
+
+	var added []*entities.CampaignContact
+	for _, phone := range phones {
+		contact, err := entities.NewCampaignContact(campaign.ID, phone, "", nil)
+		if err != nil {
+			t.Fatalf("NewCampaignContact() error = %v", err)
+		}
+		added = append(added, contact)
+	}
+	contacts.CreateBatch(context.Background(), added)
+
+	return campaign
+}
+
+// endCalls gives every call still in progress the same final status
+func endCalls(callRepo *testCallRepository, status entities.CallStatus) {
+	ids := make([]string, 0, len(callRepo.calls))
+	for id := range callRepo.calls {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if call := callRepo.calls[id]; !call.IsCompleted() {
+			call.UpdateStatus(status)
+		}
+	}
+}
+
+func dialerTick(t *testing.T, dialer *CampaignDialer) {
+	t.Helper()
+	if err := dialer.Tick(context.Background()); err != nil {
+		t.Fatalf("Tick() error = %v", err)
+	}
+}
+
+// dialerStep ends the calls in progress with end, moves the clock on by
+// advance and ticks
+type dialerStep struct {
+	end         entities.CallStatus
+	advance     time.Duration
+	wantDialled int
+}
+
+func TestCampaignDialer_Tick(t *testing.T) {
+	phones := []string{"+12125550001", "+12125550002", "+12125550003", "+12125550004"}
+
+	tests := []struct {
+		name           string
+		maxConcurrent  int
+		callsPerMinute int
+		phones         []string
+		doNotCall      []string
+		steps          []dialerStep
+		wantStatus     entities.CampaignStatus
+		wantContacts   entities.CampaignContactStatus
+		wantCalls      int
+	}{
+		{
+			name:           "respects concurrency",
+			maxConcurrent:  2,
+			callsPerMinute: 120,
+			phones:         phones[:3],
+			steps: []dialerStep{
+				{wantDialled: 2},
+				{advance: time.Minute, wantDialled: 2},
+				{end: entities.CallStatusCompleted, wantDialled: 3},
+				{end: entities.CallStatusCompleted, wantDialled: 3},
+			},
+			wantStatus:   entities.CampaignStatusCompleted,
+			wantContacts: entities.CampaignContactStatusCompleted,
+			wantCalls:    1,
+		},
+		{
+			name:           "paces calls",
+			maxConcurrent:  5,
+			callsPerMinute: 2,
+			phones:         phones,
+			steps: []dialerStep{
+				{wantDialled: 1},
+				{advance: 5 * time.Second, wantDialled: 1},
+				{advance: 30 * time.Second, wantDialled: 2},
+			},
+			wantStatus: entities.CampaignStatusRunning,
+		},
+		{
+			name:           "skips do not call numbers",
+			maxConcurrent:  1,
+			callsPerMinute: 60,
+			phones:         phones[:1],
+			doNotCall:      phones[:1],
+			steps:          []dialerStep{{wantDialled: 0}},
+			wantContacts:   entities.CampaignContactStatusSkipped,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 3, 12, 15, 0, 0, 0, time.UTC)
+			var dialled []string
+			campaigns := newMockCampaignRepository()
+			contacts := &mockCampaignContactRepository{}
+			callRepo := newTestCallRepository()
+			campaign := newMockRunningCampaign(t, campaigns, contacts, now, tt.maxConcurrent, tt.callsPerMinute, tt.phones...)
+			dialer := newMockCampaignDialer(campaigns, contacts, callRepo, &now, &dialled)
+
+			if len(tt.doNotCall) > 0 {
+				businessID := "business-123"
+				dncRepo := &testDoNotCallRepository{}
+				for _, phone := range tt.doNotCall {
+					dncRepo.entries = append(dncRepo.entries, &entities.DoNotCallEntry{BusinessID: &businessID, Phone: phone})
+				}
+				businessRepo := &mockBusinessRepository{businesses: map[string]*entities.Business{
+					"business-123": {ID: "business-123", Name: "Test Clinic", Phone: "+15551230000"},
+				}}
+				compliance := NewComplianceService(dncRepo, &testConsentRepository{}, businessRepo, logger.New("info", "console"))
+				compliance.now = func() time.Time { return now }
+				dialer.callService.SetCompliance(compliance)
+			}
+
+			for i, step := range tt.steps {
+				if step.end != "" {
+					endCalls(callRepo, step.end)
+				}
+				now = now.Add(step.advance)
+				dialerTick(t, dialer)
+				if len(dialled) != step.wantDialled {
+					t.Fatalf("step %d: expected %d calls, got %d", i+1, step.wantDialled, len(dialled))
+				}
+			}
+
+			if tt.wantStatus != "" && campaign.Status != tt.wantStatus {
+				t.Errorf("expected campaign %s, got %s", tt.wantStatus, campaign.Status)
+			}
+			if tt.wantContacts == "" {
+				return
+			}
+			for _, contact := range contacts.contacts {
+				if contact.Status != tt.wantContacts || len(contact.CallIDs) != tt.wantCalls {
+					t.Errorf("contact %s: status %s with %d calls", contact.Phone, contact.Status, len(contact.CallIDs))
+				}
+			}
+		})
+	}
+}
+
+func TestCampaignDialer_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		outcome      entities.CallStatus
+		wantAttempts int
+	}{
+		{
+			name:         "no answer",
+			outcome:      entities.CallStatusNoAnswer,
+			wantAttempts: entities.DefaultRetryPolicy.MaxAttempts,
+		},
+		{
+			name:         "busy",
+			outcome:      entities.CallStatusBusy,
+			wantAttempts: entities.DefaultRetryPolicy.MaxAttempts,
+		},
+		{
+			name:         "failed call not retried",
+			outcome:      entities.CallStatusFailed,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 3, 12, 15, 0, 0, 0, time.UTC)
+			var dialled []string
+			campaigns := newMockCampaignRepository()
+			contacts := &mockCampaignContactRepository{}
+			callRepo := newTestCallRepository()
+			newMockRunningCampaign(t, campaigns, contacts, now, 1, 60, "+12125550001")
+			dialer := newMockCampaignDialer(campaigns, contacts, callRepo, &now, &dialled)
+			contact := contacts.contacts[0]
+
+			for attempt := 1; attempt <= tt.wantAttempts; attempt++ {
+				dialerTick(t, dialer)
+				if contact.Attempts != attempt {
+					t.Fatalf("expected attempt %d, got %d", attempt, contact.Attempts)
+				}
+
+				endCalls(callRepo, tt.outcome)
+				now = now.Add(time.Minute)
+				dialerTick(t, dialer)
+
+				if attempt < tt.wantAttempts {
+					if contact.Status != entities.CampaignContactStatusPending || contact.NextAttemptAt == nil {
+						t.Fatalf("expected retry to be scheduled after attempt %d, got %s", attempt, contact.Status)
+					}
+					if len(dialled) != attempt {
+						t.Fatalf("expected retry to wait for the delay, got %d calls", len(dialled))
+					}
+					now = *contact.NextAttemptAt
+				}
+			}
+
+			if contact.Status != entities.CampaignContactStatusFailed {
+				t.Errorf("expected contact failed after %d attempts, got %s", contact.Attempts, contact.Status)
+			}
+			if contact.LastOutcome != tt.outcome {
+				t.Errorf("expected last outcome %s, got %s", tt.outcome, contact.LastOutcome)
+			}
+			if len(contact.CallIDs) != tt.wantAttempts {
+				t.Errorf("expected %d linked calls, got %d", tt.wantAttempts, len(contact.CallIDs))
+			}
+		})
+	}
+}
+
+func TestCampaignService_AddContacts(t *testing.T) {
+	tests := []struct {
+		name           string
+		businessID     string
+		contacts       []dto.CampaignContactRequest
+		wantErr        bool
+		wantAdded      int
+		wantDuplicates int
+		wantRejected   int
+	}{
+		{
+			name:       "normalises, deduplicates and rejects",
+			businessID: "business-123",
+			contacts: []dto.CampaignContactRequest{
+				{Phone: "(212) 555-0001"},
+				{Phone: "(212) 555-0002"},
+				{Phone: "212-555-0002"},
+				{Phone: "not a number"},
+			},
+			wantAdded:      1,
+			wantDuplicates: 2,
+			wantRejected:   1,
+		},
+		{
+			name:       "another business's campaign",
+			businessID: "business-456",
+			contacts:   []dto.CampaignContactRequest{{Phone: "+12125550003"}},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			contacts := &mockCampaignContactRepository{}
+			businessRepo := &mockBusinessRepository{businesses: map[string]*entities.Business{
+				"business-123": {ID: "business-123", Name: "Test Clinic", Phone: "+15551230000"},
+			}}
+			service := NewCampaignService(newMockCampaignRepository(), contacts, businessRepo, logger.New("info", "console"))
+
+			campaign, err := service.CreateCampaign(ctx, "business-123", dto.CreateCampaignRequest{
+				Name:     "Reactivation",
+				Contacts: []dto.CampaignContactRequest{{Phone: "+12125550001"}},
+			})
+			if err != nil {
+				t.Fatalf("CreateCampaign() error = %v", err)
+			}
+
+			response, err := service.AddContacts(ctx, tt.businessID, campaign.ID, dto.AddCampaignContactsRequest{Contacts: tt.contacts})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				if len(contacts.contacts) != 1 {
+					t.Errorf("expected no contacts to be added, got %d", len(contacts.contacts)-1)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AddContacts() error = %v", err)
+			}
+			if response.Added != tt.wantAdded || response.Duplicates != tt.wantDuplicates || len(response.Rejected) != tt.wantRejected {
+				t.Errorf("expected %d added, %d duplicates, %d rejected; got %+v", tt.wantAdded, tt.wantDuplicates, tt.wantRejected, response)
+			}
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+	"github.com/CallPilotReceptionist/pkg/phone"
+)
+
+const (
+	defaultCampaignConcurrency = 1
+	defaultCampaignCallRate    = 10 // calls per minute
+	maxCampaignContactsPerAdd  = 5000
+)
+
+// CampaignService manages outbound calling campaigns. Calls are placed by
+// the CampaignDialer, not by this service.
+type CampaignService struct {
+	campaignRepo database.CampaignRepository
+	contactRepo  database.CampaignContactRepository
+	businessRepo database.BusinessRepository
+	logger       *logger.Logger
+}
+
+func NewCampaignService(
+	campaignRepo database.CampaignRepository,
+	contactRepo database.CampaignContactRepository,
+	businessRepo database.BusinessRepository,
+	log *logger.Logger,
+) *CampaignService {
+	return &CampaignService{
+		campaignRepo: campaignRepo,
+		contactRepo:  contactRepo,
+		businessRepo: businessRepo,
+		logger:       log,
+	}
+}
+
+func (s *CampaignService) CreateCampaign(ctx context.Context, businessID string, req dto.CreateCampaignRequest) (*dto.CampaignResponse, error) {
+	business, err := s.businessRepo.GetByID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, errors.NewNotFoundError("business", businessID)
+	}
+
+	startsAt, err := parseOptionalTime("starts_at", req.StartsAt)
+	if err != nil {
+		return nil, err
+	}
+	endsAt, err := parseOptionalTime("ends_at", req.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var startTime time.Time
+	if startsAt != nil {
+		startTime = *startsAt
+	}
+
+	maxConcurrent := req.MaxConcurrent
+	if maxConcurrent == 0 {
+		maxConcurrent = defaultCampaignConcurrency
+	}
+	callsPerMinute := req.CallsPerMinute
+	if callsPerMinute == 0 {
+		callsPerMinute = defaultCampaignCallRate
+	}
+
+	assistantID := req.AssistantID
+	if assistantID == "" {
+		assistantID = business.AssistantID()
+	}
+
+	campaign, err := entities.NewCampaign(businessID, req.Name, assistantID, maxConcurrent, callsPerMinute,
+		retryPolicyFromRequest(req.RetryPolicy), startTime, endsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reject a bad contact list before anything is stored
+	contactReqs, rejected := normalizeCampaignContacts(business, req.Contacts)
+	if len(rejected) > 0 {
+		return nil, errors.NewFieldValidationError("contacts", "contact "+rejected[0].Phone+" is "+rejected[0].Error)
+	}
+
+	if err := s.campaignRepo.Create(ctx, campaign); err != nil {
+		s.logger.Error("Failed to create campaign", err, map[string]interface{}{
+			"business_id": businessID,
+		})
+		return nil, err
+	}
+
+	contacts, err := newCampaignContacts(campaign.ID, contactReqs)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.contactRepo.CreateBatch(ctx, contacts); err != nil {
+		s.logger.Error("Failed to add campaign contacts", err, map[string]interface{}{
+			"campaign_id": campaign.ID,
+		})
+		return nil, err
+	}
+
+	return s.buildCampaignResponse(ctx, campaign)
+}
+
+func (s *CampaignService) GetCampaign(ctx context.Context, businessID, campaignID string) (*dto.CampaignResponse, error) {
+	campaign, err := s.getOwnedCampaign(ctx, businessID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildCampaignResponse(ctx, campaign)
+}
+
+func (s *CampaignService) ListCampaigns(ctx context.Context, businessID string, limit, offset int) ([]*dto.CampaignResponse, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	campaigns, err := s.campaignRepo.GetByBusinessID(ctx, businessID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.CampaignResponse, 0, len(campaigns))
+	for _, campaign := range campaigns {
+		response, err := s.buildCampaignResponse(ctx, campaign)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+// AddContacts appends numbers to a campaign that has not finished. Invalid
+// numbers are reported back rather than failing the whole request.
+func (s *CampaignService) AddContacts(ctx context.Context, businessID, campaignID string, req dto.AddCampaignContactsRequest) (*dto.AddCampaignContactsResponse, error) {
+	if len(req.Contacts) == 0 {
+		return nil, errors.NewFieldValidationError("contacts", "at least one contact is required")
+	}
+	if len(req.Contacts) > maxCampaignContactsPerAdd {
+		return nil, errors.NewFieldValidationError("contacts", "at most 5000 contacts can be added at once")
+	}
+
+	campaign, err := s.getOwnedCampaign(ctx, businessID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.IsFinished() {
+		return nil, errors.NewValidationError("cannot add contacts to a finished campaign")
+	}
+
+	business, err := s.businessRepo.GetByID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, errors.NewNotFoundError("business", businessID)
+	}
+
+	contactReqs, rejected := normalizeCampaignContacts(business, req.Contacts)
+	contacts, err := newCampaignContacts(campaign.ID, contactReqs)
+	if err != nil {
+		return nil, err
+	}
+
+	added, err := s.contactRepo.CreateBatch(ctx, contacts)
+	if err != nil {
+		s.logger.Error("Failed to add campaign contacts", err, map[string]interface{}{
+			"campaign_id": campaign.ID,
+		})
+		return nil, err
+	}
+
+	return &dto.AddCampaignContactsResponse{
+		Added:      added,
+		Duplicates: len(req.Contacts) - len(rejected) - added,
+		Rejected:   rejected,
+	}, nil
+}
+
+// ListContacts lists a campaign's contacts, optionally filtered by status
+func (s *CampaignService) ListContacts(ctx context.Context, businessID, campaignID, status string, limit, offset int) ([]*dto.CampaignContactResponse, error) {
+	if _, err := s.getOwnedCampaign(ctx, businessID, campaignID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	contacts, err := s.contactRepo.GetByCampaignID(ctx, campaignID, entities.CampaignContactStatus(status), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.CampaignContactResponse, 0, len(contacts))
+	for _, contact := range contacts {
+		responses = append(responses, s.mapContactToResponse(contact))
+	}
+
+	return responses, nil
+}
+
+func (s *CampaignService) StartCampaign(ctx context.Context, businessID, campaignID string) (*dto.CampaignResponse, error) {
+	return s.transition(ctx, businessID, campaignID, (*entities.Campaign).Start)
+}
+
+func (s *CampaignService) PauseCampaign(ctx context.Context, businessID, campaignID string) (*dto.CampaignResponse, error) {
+	return s.transition(ctx, businessID, campaignID, (*entities.Campaign).Pause)
+}
+
+// CancelCampaign stops dialling for good. Calls already in progress finish
+// normally.
+func (s *CampaignService) CancelCampaign(ctx context.Context, businessID, campaignID string) (*dto.CampaignResponse, error) {
+	return s.transition(ctx, businessID, campaignID, (*entities.Campaign).Cancel)
+}
+
+func (s *CampaignService) transition(ctx context.Context, businessID, campaignID string, apply func(*entities.Campaign) error) (*dto.CampaignResponse, error) {
+	campaign, err := s.getOwnedCampaign(ctx, businessID, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := campaign.Status
+	if err := apply(campaign); err != nil {
+		return nil, err
+	}
+
+	if err := s.campaignRepo.Update(ctx, campaign); err != nil {
+		s.logger.Error("Failed to update campaign status", err, map[string]interface{}{
+			"campaign_id": campaign.ID,
+		})
+		return nil, err
+	}
+
+	s.logger.Info("Campaign status changed", map[string]interface{}{
+		"campaign_id": campaign.ID,
+		"from":        previous,
+		"to":          campaign.Status,
+	})
+
+	return s.buildCampaignResponse(ctx, campaign)
+}
+
+func (s *CampaignService) getOwnedCampaign(ctx context.Context, businessID, campaignID string) (*entities.Campaign, error) {
+	campaign, err := s.campaignRepo.GetByID(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	if campaign.BusinessID != businessID {
+		return nil, errors.NewForbiddenError("access denied to this campaign")
+	}
+
+	return campaign, nil
+}
+
+// normalizeCampaignContacts converts the requested numbers to E.164,
+// dropping repeats within the request and reporting numbers that cannot be used
+func normalizeCampaignContacts(business *entities.Business, requests []dto.CampaignContactRequest) ([]dto.CampaignContactRequest, []dto.RejectedContactResponse) {
+	normalized := make([]dto.CampaignContactRequest, 0, len(requests))
+	rejected := []dto.RejectedContactResponse{}
+	seen := make(map[string]bool, len(requests))
+
+	for i, req := range requests {
+		number, err := phone.Normalize(req.Phone, business.DefaultRegion())
+		if err != nil {
+			rejected = append(rejected, dto.RejectedContactResponse{
+				Index: i,
+				Phone: req.Phone,
+				Error: "not a valid phone number",
+			})
+			continue
+		}
+		if seen[number] {
+			continue
+		}
+		seen[number] = true
+
+		req.Phone = number
+		normalized = append(normalized, req)
+	}
+
+	return normalized, rejected
+}
+
+func newCampaignContacts(campaignID string, requests []dto.CampaignContactRequest) ([]*entities.CampaignContact, error) {
+	contacts := make([]*entities.CampaignContact, 0, len(requests))
+	for _, req := range requests {
+		contact, err := entities.NewCampaignContact(campaignID, req.Phone, req.Name, req.Variables)
+		if err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, contact)
+	}
+	return contacts, nil
+}
+
+func (s *CampaignService) buildCampaignResponse(ctx context.Context, campaign *entities.Campaign) (*dto.CampaignResponse, error) {
+	counts, err := s.contactRepo.CountByStatus(ctx, campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapCampaignToResponse(campaign)
+	response.Progress = campaignProgress(counts)
+	return response, nil
+}
+
+func campaignProgress(counts map[entities.CampaignContactStatus]int) *dto.CampaignProgressResponse {
+	progress := &dto.CampaignProgressResponse{
+		Pending:   counts[entities.CampaignContactStatusPending],
+		Calling:   counts[entities.CampaignContactStatusCalling],
+		Completed: counts[entities.CampaignContactStatusCompleted],
+		Failed:    counts[entities.CampaignContactStatusFailed],
+		Skipped:   counts[entities.CampaignContactStatusSkipped],
+	}
+	progress.Total = progress.Pending + progress.Calling + progress.Completed + progress.Failed + progress.Skipped
+
+	if progress.Total > 0 {
+		finished := progress.Completed + progress.Failed + progress.Skipped
+		progress.PercentFinished = float64(finished) * 100 / float64(progress.Total)
+	}
+
+	return progress
+}
+
+func mapCampaignToResponse(campaign *entities.Campaign) *dto.CampaignResponse {
+	retryOn := make([]string, 0, len(campaign.RetryPolicy.RetryOn))
+	for _, status := range campaign.RetryPolicy.RetryOn {
+		retryOn = append(retryOn, string(status))
+	}
+
+	response := &dto.CampaignResponse{
+		ID:             campaign.ID,
+		BusinessID:     campaign.BusinessID,
+		Name:           campaign.Name,
+		AssistantID:    campaign.AssistantID,
+		Status:         string(campaign.Status),
+		MaxConcurrent:  campaign.MaxConcurrent,
+		CallsPerMinute: campaign.CallsPerMinute,
+		RetryPolicy: dto.RetryPolicyRequest{
+			MaxAttempts:       campaign.RetryPolicy.MaxAttempts,
+			RetryDelayMinutes: campaign.RetryPolicy.RetryDelayMinutes,
+			RetryOn:           retryOn,
+		},
+		StartsAt:    campaign.StartsAt.Format(time.RFC3339),
+		EndsAt:      formatOptionalTime(campaign.EndsAt),
+		StartedAt:   formatOptionalTime(campaign.StartedAt),
+		CompletedAt: formatOptionalTime(campaign.CompletedAt),
+		CreatedAt:   campaign.CreatedAt.Format(time.RFC3339),
+	}
+
+	return response
+}
+
+func (s *CampaignService) mapContactToResponse(contact *entities.CampaignContact) *dto.CampaignContactResponse {
+	return &dto.CampaignContactResponse{
+		ID:            contact.ID,
+		Phone:         contact.Phone,
+		Name:          contact.Name,
+		Status:        string(contact.Status),
+		Attempts:      contact.Attempts,
+		CallIDs:       contact.CallIDs,
+		LastOutcome:   string(contact.LastOutcome),
+		LastError:     contact.LastError,
+		NextAttemptAt: formatOptionalTime(contact.NextAttemptAt),
+		UpdatedAt:     contact.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func retryPolicyFromRequest(req *dto.RetryPolicyRequest) entities.RetryPolicy {
+	if req == nil {
+		return entities.DefaultRetryPolicy
+	}
+
+	policy := entities.RetryPolicy{
+		MaxAttempts:       req.MaxAttempts,
+		RetryDelayMinutes: req.RetryDelayMinutes,
+		RetryOn:           make([]entities.CallStatus, 0, len(req.RetryOn)),
+	}
+	for _, status := range req.RetryOn {
+		policy.RetryOn = append(policy.RetryOn, entities.CallStatus(status))
+	}
+	return policy
+}
+
+// parseOptionalTime reads an RFC3339 timestamp, returning nil for an empty string
+func parseOptionalTime(field, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errors.NewFieldValidationError(field, field+" must be an RFC3339 timestamp")
+	}
+	return &t, nil
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
@@ -0,0 +1,496 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // calling windows need zone data even on minimal images
+	"unicode"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+	"github.com/CallPilotReceptionist/pkg/phone"
+)
+
+// optOutPhrases opt the caller out wherever they say them, as whole words
+var optOutPhrases = []string{
+	"remove me from your list",
+	"remove me from your call list",
+	"take me off your list",
+	"take me off your call list",
+	"do not call list",
+	"unsubscribe",
+	"opt out",
+}
+
+// optOutRequests opt the caller out only when nothing but optOutEndings
+// follows them in the sentence, so "do not call me again" is an opt-out and
+// "please do not call before 9" is not
+var optOutRequests = []string{
+	"do not call",
+	"do not call me",
+	"don't call",
+	"don't call me",
+	"dont call me",
+	"stop calling",
+	"stop calling me",
+}
+
+var optOutEndings = map[string]bool{
+	"":               true,
+	"again":          true,
+	"anymore":        true,
+	"any more":       true,
+	"ever again":     true,
+	"at this number": true,
+	"please":         true,
+	"thanks":         true,
+	"thank you":      true,
+}
+
+// ComplianceService decides whether an outbound call may be placed and
+// maintains the do-not-call list and consent records behind that decision
+type ComplianceService struct {
+	dncRepo      database.DoNotCallRepository
+	consentRepo  database.ConsentRepository
+	businessRepo database.BusinessRepository
+	logger       *logger.Logger
+
+	now func() time.Time
+}
+
+func NewComplianceService(
+	dncRepo database.DoNotCallRepository,
+	consentRepo database.ConsentRepository,
+	businessRepo database.BusinessRepository,
+	log *logger.Logger,
+) *ComplianceService {
+	return &ComplianceService{
+		dncRepo:      dncRepo,
+		consentRepo:  consentRepo,
+		businessRepo: businessRepo,
+		logger:       log,
+		now:          time.Now,
+	}
+}
+
+// CheckOutboundCall returns a compliance error when the business may not call
+// the number right now. The number must already be in E.164 format.
+func (s *ComplianceService) CheckOutboundCall(ctx context.Context, businessID, number string) error {
+	verdict, err := s.evaluate(ctx, businessID, number)
+	if err != nil {
+		return err
+	}
+	if !verdict.allowed() {
+		s.logger.Info("Outbound call blocked", map[string]interface{}{
+			"business_id": businessID,
+			"phone":       number,
+			"reason":      verdict.reason,
+		})
+		return errors.NewComplianceError(verdict.reason)
+	}
+	return nil
+}
+
+// Check reports whether a call to the number would be allowed right now
+func (s *ComplianceService) Check(ctx context.Context, businessID, rawPhone string) (*dto.ComplianceCheckResponse, error) {
+	number, err := s.normalize(ctx, businessID, rawPhone)
+	if err != nil {
+		return nil, err
+	}
+
+	verdict, err := s.evaluate(ctx, businessID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ComplianceCheckResponse{
+		Phone:     number,
+		Allowed:   verdict.allowed(),
+		Reason:    verdict.reason,
+		TimeZones: verdict.zones,
+	}, nil
+}
+
+// complianceVerdict is the outcome of checking a single outbound call
+type complianceVerdict struct {
+	reason string   // why the call is blocked, empty when allowed
+	zones  []string // time zones the callee may be in
+	// outsideWindow is set when the only problem is the time of day, so the
+	// same call may be allowed later
+	outsideWindow bool
+}
+
+func (v complianceVerdict) allowed() bool {
+	return v.reason == ""
+}
+
+func (s *ComplianceService) evaluate(ctx context.Context, businessID, number string) (complianceVerdict, error) {
+	business, err := s.businessRepo.GetByID(ctx, businessID)
+	if err != nil {
+		return complianceVerdict{}, err
+	}
+	if business == nil {
+		return complianceVerdict{}, errors.NewNotFoundError("business", businessID)
+	}
+
+	listed, err := s.dncRepo.IsListed(ctx, businessID, number)
+	if err != nil {
+		return complianceVerdict{}, err
+	}
+	if listed {
+		return complianceVerdict{reason: "number is on the do-not-call list"}, nil
+	}
+
+	if business.RequiresConsent() {
+		consent, err := s.consentRepo.GetActive(ctx, businessID, number)
+		if err != nil {
+			return complianceVerdict{}, err
+		}
+		if consent == nil {
+			return complianceVerdict{reason: "no consent on record for this number"}, nil
+		}
+	}
+
+	window, err := business.CallingWindow()
+	if err != nil {
+		return complianceVerdict{}, err
+	}
+
+	// A number whose zone cannot be pinned down must be inside the window in
+	// every zone it could belong to
+	zones := phone.TimeZones(number)
+	if len(zones) == 0 {
+		zones = []string{business.Timezone()}
+	}
+
+	now := s.now()
+	for _, zone := range zones {
+		location, err := time.LoadLocation(zone)
+		if err != nil {
+			return complianceVerdict{}, errors.NewInternalError(fmt.Errorf("unknown time zone %s: %w", zone, err))
+		}
+		if !window.Allows(now.In(location)) {
+			return complianceVerdict{
+				reason:        fmt.Sprintf("outside calling window %s in %s", window, zone),
+				zones:         zones,
+				outsideWindow: true,
+			}, nil
+		}
+	}
+
+	return complianceVerdict{zones: zones}, nil
+}
+
+// AddToDoNotCall lists a number for the business
+func (s *ComplianceService) AddToDoNotCall(ctx context.Context, businessID string, req dto.AddDoNotCallRequest) (*dto.DoNotCallResponse, error) {
+	if req.Phone == "" {
+		return nil, errors.NewFieldValidationError("phone", "phone is required")
+	}
+
+	number, err := s.normalize(ctx, businessID, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := entities.NewDoNotCallEntry(&businessID, number, entities.DoNotCallSourceManual, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.dncRepo.Create(ctx, entry); err != nil {
+		s.logger.Error("Failed to add number to do-not-call list", err, map[string]interface{}{
+			"business_id": businessID,
+		})
+		return nil, err
+	}
+
+	return s.mapDoNotCallToResponse(entry), nil
+}
+
+func (s *ComplianceService) ListDoNotCall(ctx context.Context, businessID string, limit, offset int) ([]*dto.DoNotCallResponse, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	entries, err := s.dncRepo.GetByBusinessID(ctx, businessID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.DoNotCallResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, s.mapDoNotCallToResponse(entry))
+	}
+
+	return responses, nil
+}
+
+// RemoveFromDoNotCall deletes one of the business's own entries. Global
+// entries can only be removed by the platform.
+func (s *ComplianceService) RemoveFromDoNotCall(ctx context.Context, businessID, entryID string) error {
+	entry, err := s.dncRepo.GetByID(ctx, entryID)
+	if err != nil {
+		return err
+	}
+
+	if entry.IsGlobal() || *entry.BusinessID != businessID {
+		return errors.NewForbiddenError("access denied to this do-not-call entry")
+	}
+
+	return s.dncRepo.Delete(ctx, entryID)
+}
+
+// AddToGlobalDoNotCall lists a number for every business on the platform
+func (s *ComplianceService) AddToGlobalDoNotCall(ctx context.Context, req dto.AddDoNotCallRequest) (*dto.DoNotCallResponse, error) {
+	if req.Phone == "" {
+		return nil, errors.NewFieldValidationError("phone", "phone is required")
+	}
+
+	number, err := normalizePhone("phone", req.Phone, phone.DefaultRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := entities.NewDoNotCallEntry(nil, number, entities.DoNotCallSourceManual, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.dncRepo.Create(ctx, entry); err != nil {
+		s.logger.Error("Failed to add number to global do-not-call list", err, nil)
+		return nil, err
+	}
+
+	return s.mapDoNotCallToResponse(entry), nil
+}
+
+func (s *ComplianceService) ListGlobalDoNotCall(ctx context.Context, limit, offset int) ([]*dto.DoNotCallResponse, error) {
+	limit, offset = pageBounds(limit, offset)
+
+	entries, err := s.dncRepo.GetGlobal(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.DoNotCallResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, s.mapDoNotCallToResponse(entry))
+	}
+
+	return responses, nil
+}
+
+// RemoveFromGlobalDoNotCall deletes a global entry. Businesses' own entries
+// are left to them.
+func (s *ComplianceService) RemoveFromGlobalDoNotCall(ctx context.Context, entryID string) (*dto.DoNotCallResponse, error) {
+	entry, err := s.dncRepo.GetByID(ctx, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !entry.IsGlobal() {
+		return nil, errors.NewForbiddenError("only global do-not-call entries can be removed by the platform")
+	}
+
+	if err := s.dncRepo.Delete(ctx, entryID); err != nil {
+		return nil, err
+	}
+
+	return s.mapDoNotCallToResponse(entry), nil
+}
+
+func (s *ComplianceService) RecordConsent(ctx context.Context, businessID string, req dto.RecordConsentRequest) (*dto.ConsentResponse, error) {
+	if req.Phone == "" {
+		return nil, errors.NewFieldValidationError("phone", "phone is required")
+	}
+
+	number, err := s.normalize(ctx, businessID, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	var grantedAt time.Time
+	if req.GrantedAt != "" {
+		grantedAt, err = time.Parse(time.RFC3339, req.GrantedAt)
+		if err != nil {
+			return nil, errors.NewFieldValidationError("granted_at", "granted_at must be an RFC3339 timestamp")
+		}
+	}
+
+	consent, err := entities.NewConsentRecord(businessID, number, req.Source, req.Notes, grantedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.consentRepo.Create(ctx, consent); err != nil {
+		s.logger.Error("Failed to record consent", err, map[string]interface{}{
+			"business_id": businessID,
+		})
+		return nil, err
+	}
+
+	return s.mapConsentToResponse(consent), nil
+}
+
+func (s *ComplianceService) ListConsents(ctx context.Context, businessID string, limit, offset int) ([]*dto.ConsentResponse, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	consents, err := s.consentRepo.GetByBusinessID(ctx, businessID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.ConsentResponse, 0, len(consents))
+	for _, consent := range consents {
+		responses = append(responses, s.mapConsentToResponse(consent))
+	}
+
+	return responses, nil
+}
+
+func (s *ComplianceService) RevokeConsent(ctx context.Context, businessID, consentID string) (*dto.ConsentResponse, error) {
+	consent, err := s.consentRepo.GetByID(ctx, consentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if consent.BusinessID != businessID {
+		return nil, errors.NewForbiddenError("access denied to this consent record")
+	}
+
+	if err := consent.Revoke(); err != nil {
+		return nil, err
+	}
+
+	if err := s.consentRepo.Update(ctx, consent); err != nil {
+		return nil, err
+	}
+
+	return s.mapConsentToResponse(consent), nil
+}
+
+// HandleTranscript adds the caller to the business's do-not-call list when
+// they asked not to be called again. It reports whether an opt-out was found.
+func (s *ComplianceService) HandleTranscript(ctx context.Context, call *entities.Call, transcripts []*entities.Transcript) bool {
+	var phrase string
+	for _, t := range transcripts {
+		if !t.IsFromUser() {
+			continue
+		}
+		if phrase = matchOptOut(t.Message); phrase != "" {
+			break
+		}
+	}
+	if phrase == "" {
+		return false
+	}
+
+	entry, err := entities.NewDoNotCallEntry(&call.BusinessID, call.CallerPhone, entities.DoNotCallSourceOptOut,
+		fmt.Sprintf("caller said %q on call %s", phrase, call.ID))
+	if err != nil {
+		s.logger.Warn("Cannot list opted-out caller", map[string]interface{}{
+			"call_id": call.ID,
+			"error":   err.Error(),
+		})
+		return false
+	}
+
+	if err := s.dncRepo.Create(ctx, entry); err != nil {
+		s.logger.Error("Failed to add opted-out caller to do-not-call list", err, map[string]interface{}{
+			"call_id": call.ID,
+		})
+		return false
+	}
+
+	s.logger.Info("Caller opted out of further calls", map[string]interface{}{
+		"call_id":     call.ID,
+		"business_id": call.BusinessID,
+	})
+
+	return true
+}
+
+// matchOptOut returns the opt-out phrase the message contains, if any.
+// Each sentence is lowercased and reduced to its words, and phrases only
+// match whole words.
+func matchOptOut(message string) string {
+	message = strings.ToLower(strings.NewReplacer("’", "'", "‘", "'").Replace(message))
+	sentences := strings.FieldsFunc(message, func(r rune) bool {
+		return strings.ContainsRune(".!?;,", r)
+	})
+
+	for _, sentence := range sentences {
+		words := strings.FieldsFunc(sentence, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+		})
+		padded := " " + strings.Join(words, " ") + " "
+
+		for _, phrase := range optOutPhrases {
+			if strings.Contains(padded, " "+phrase+" ") {
+				return phrase
+			}
+		}
+		for _, phrase := range optOutRequests {
+			i := strings.Index(padded, " "+phrase+" ")
+			if i < 0 {
+				continue
+			}
+			rest := strings.TrimSpace(padded[i+len(phrase)+1:])
+			if optOutEndings[rest] {
+				return phrase
+			}
+		}
+	}
+	return ""
+}
+
+// normalize reads a number in the business's default region
+func (s *ComplianceService) normalize(ctx context.Context, businessID, raw string) (string, error) {
+	region := phone.DefaultRegion
+	if business, err := s.businessRepo.GetByID(ctx, businessID); err == nil && business != nil {
+		region = business.DefaultRegion()
+	}
+	return normalizePhone("phone", raw, region)
+}
+
+func (s *ComplianceService) mapDoNotCallToResponse(entry *entities.DoNotCallEntry) *dto.DoNotCallResponse {
+	return &dto.DoNotCallResponse{
+		ID:        entry.ID,
+		Phone:     entry.Phone,
+		Source:    string(entry.Source),
+		Reason:    entry.Reason,
+		Global:    entry.IsGlobal(),
+		CreatedAt: entry.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (s *ComplianceService) mapConsentToResponse(consent *entities.ConsentRecord) *dto.ConsentResponse {
+	response := &dto.ConsentResponse{
+		ID:        consent.ID,
+		Phone:     consent.Phone,
+		Source:    consent.Source,
+		Notes:     consent.Notes,
+		GrantedAt: consent.GrantedAt.Format(time.RFC3339),
+		CreatedAt: consent.CreatedAt.Format(time.RFC3339),
+	}
+
+	if consent.RevokedAt != nil {
+		revokedAt := consent.RevokedAt.Format(time.RFC3339)
+		response.RevokedAt = &revokedAt
+	}
+
+	return response
+}
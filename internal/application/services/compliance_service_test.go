@@ -0,0 +1,399 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// Do-not-call repository backed by a slice
+type testDoNotCallRepository struct {
+	entries []*entities.DoNotCallEntry
+}
+
+func (m *testDoNotCallRepository) Create(ctx context.Context, entry *entities.DoNotCallEntry) error {
+	entry.ID = "dnc-" + strconv.Itoa(len(m.entries)+1)
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *testDoNotCallRepository) GetByID(ctx context.Context, id string) (*entities.DoNotCallEntry, error) {
+	for _, entry := range m.entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return nil, domainerrors.NewNotFoundError("do-not-call entry", id)
+}
+
+func (m *testDoNotCallRepository) IsListed(ctx context.Context, businessID, phone string) (bool, error) {
+	for _, entry := range m.entries {
+		if entry.Phone == phone && (entry.IsGlobal() || *entry.BusinessID == businessID) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *testDoNotCallRepository) GetByBusinessID(ctx context.Context, businessID string, limit, offset int) ([]*entities.DoNotCallEntry, error) {
+	var result []*entities.DoNotCallEntry
+	for _, entry := range m.entries {
+		if !entry.IsGlobal() && *entry.BusinessID == businessID {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func (m *testDoNotCallRepository) GetGlobal(ctx context.Context, limit, offset int) ([]*entities.DoNotCallEntry, error) {
+	var result []*entities.DoNotCallEntry
+	for _, entry := range m.entries {
+		if entry.IsGlobal() {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func (m *testDoNotCallRepository) Delete(ctx context.Context, id string) error {
+	for i, entry := range m.entries {
+		if entry.ID == id {
+			m.entries = append(m.entries[:i], m.entries[i+1:]...)
+			return nil
+		}
+	}
+	return domainerrors.NewNotFoundError("do-not-call entry", id)
+}
+
+// Consent repository backed by a slice
+type testConsentRepository struct {
+	consents []*entities.ConsentRecord
+}
+
+func (m *testConsentRepository) Create(ctx context.Context, consent *entities.ConsentRecord) error {
+	consent.ID = "consent-" + strconv.Itoa(len(m.consents)+1)
+	m.consents = append(m.consents, consent)
+	return nil
+}
+
+func (m *testConsentRepository) GetByID(ctx context.Context, id string) (*entities.ConsentRecord, error) {
+	for _, consent := range m.consents {
+		if consent.ID == id {
+			return consent, nil
+		}
+	}
+	return nil, domainerrors.NewNotFoundError("consent record", id)
+}
+
+func (m *testConsentRepository) GetActive(ctx context.Context, businessID, phone string) (*entities.ConsentRecord, error) {
+	for _, consent := range m.consents {
+		if consent.BusinessID == businessID && consent.Phone == phone && consent.IsActive() {
+			return consent, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *testConsentRepository) GetByBusinessID(ctx context.Context, businessID string, limit, offset int) ([]*entities.ConsentRecord, error) {
+	var result []*entities.ConsentRecord
+	for _, consent := range m.consents {
+		if consent.BusinessID == businessID {
+			result = append(result, consent)
+		}
+	}
+	return result, nil
+}
+
+func (m *testConsentRepository) Update(ctx context.Context, consent *entities.ConsentRecord) error {
+	return nil
+}
+
+func newTestComplianceService(settings entities.BusinessSettings, now time.Time) (*ComplianceService, *testDoNotCallRepository, *testConsentRepository) {
+	dncRepo := &testDoNotCallRepository{}
+	consentRepo := &testConsentRepository{}
+	businessRepo := &mockBusinessRepository{businesses: map[string]*entities.Business{
+		"business-123": {
+			ID:       "business-123",
+			Name:     "Test Clinic",
+			Type:     "dentist",
+			Phone:    "+15551230000",
+			Settings: settings,
+		},
+	}}
+
+	service := NewComplianceService(dncRepo, consentRepo, businessRepo, logger.New("info", "console"))
+	service.now = func() time.Time { return now }
+
+	return service, dncRepo, consentRepo
+}
+
+func isComplianceError(err error) bool {
+	var domainErr *domainerrors.DomainError
+	return errors.As(err, &domainErr) && domainErr.Code == domainerrors.ErrCodeComplianceBlock
+}
+
+func TestComplianceService_CheckOutboundCall(t *testing.T) {
+	// 18:00 UTC is 14:00 in New York and 11:00 in Los Angeles
+	afternoon := time.Date(2024, 3, 12, 18, 0, 0, 0, time.UTC)
+	// 03:00 UTC is 23:00 in New York and 20:00 in Los Angeles
+	lateEvening := time.Date(2024, 3, 13, 3, 0, 0, 0, time.UTC)
+	requireConsent := testSettings(func(s *entities.BusinessSettings) { s.RequireConsent = true })
+
+	tests := []struct {
+		name      string
+		settings  entities.BusinessSettings
+		now       time.Time
+		phone     string
+		setup     func(*testDoNotCallRepository, *testConsentRepository)
+		wantBlock bool
+	}{
+		{
+			name:      "inside calling window",
+			now:       afternoon,
+			phone:     "+12125551234",
+			wantBlock: false,
+		},
+		{
+			name:      "after 9pm in callee's zone",
+			now:       lateEvening,
+			phone:     "+12125551234",
+			wantBlock: true,
+		},
+		{
+			name:      "still evening on the west coast",
+			now:       lateEvening,
+			phone:     "+14155551234",
+			wantBlock: false,
+		},
+		{
+			name:      "unknown area code must fit every continental zone",
+			now:       lateEvening,
+			phone:     "+15551234567",
+			wantBlock: true,
+		},
+		{
+			name: "custom calling window",
+			settings: testSettings(func(s *entities.BusinessSettings) {
+				s.CallingWindow = entities.CallingHours{Start: "09:00", End: "12:00"}
+			}),
+			now:       afternoon,
+			phone:     "+12125551234",
+			wantBlock: true,
+		},
+		{
+			name:  "business do-not-call entry",
+			now:   afternoon,
+			phone: "+12125551234",
+			setup: func(dnc *testDoNotCallRepository, consents *testConsentRepository) {
+				businessID := "business-123"
+				dnc.entries = append(dnc.entries, &entities.DoNotCallEntry{BusinessID: &businessID, Phone: "+12125551234"})
+			},
+			wantBlock: true,
+		},
+		{
+			name:  "global do-not-call entry",
+			now:   afternoon,
+			phone: "+12125551234",
+			setup: func(dnc *testDoNotCallRepository, consents *testConsentRepository) {
+				dnc.entries = append(dnc.entries, &entities.DoNotCallEntry{Phone: "+12125551234"})
+			},
+			wantBlock: true,
+		},
+		{
+			name:  "another business's do-not-call entry",
+			now:   afternoon,
+			phone: "+12125551234",
+			setup: func(dnc *testDoNotCallRepository, consents *testConsentRepository) {
+				other := "business-456"
+				dnc.entries = append(dnc.entries, &entities.DoNotCallEntry{BusinessID: &other, Phone: "+12125551234"})
+			},
+			wantBlock: false,
+		},
+		{
+			name:      "consent required but missing",
+			settings:  requireConsent,
+			now:       afternoon,
+			phone:     "+12125551234",
+			wantBlock: true,
+		},
+		{
+			name:     "consent required and recorded",
+			settings: requireConsent,
+			now:      afternoon,
+			phone:    "+12125551234",
+			setup: func(dnc *testDoNotCallRepository, consents *testConsentRepository) {
+				consents.consents = append(consents.consents, &entities.ConsentRecord{
+					BusinessID: "business-123",
+					Phone:      "+12125551234",
+					Source:     "web_form",
+				})
+			},
+			wantBlock: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, dncRepo, consentRepo := newTestComplianceService(tt.settings, tt.now)
+			if tt.setup != nil {
+				tt.setup(dncRepo, consentRepo)
+			}
+
+			err := service.CheckOutboundCall(context.Background(), "business-123", tt.phone)
+
+			if tt.wantBlock {
+				if !isComplianceError(err) {
+					t.Errorf("expected compliance error, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestComplianceService_HandleTranscript(t *testing.T) {
+	tests := []struct {
+		name       string
+		messages   []*entities.Transcript
+		wantListed bool
+	}{
+		{
+			name: "caller asks not to be called",
+			messages: []*entities.Transcript{
+				entities.NewTranscript("call-1", entities.TranscriptRoleAssistant, "Hi, this is Test Clinic.", time.Now()),
+				entities.NewTranscript("call-1", entities.TranscriptRoleUser, "Please take me off your list.", time.Now()),
+			},
+			wantListed: true,
+		},
+		{
+			name: "curly apostrophe",
+			messages: []*entities.Transcript{
+				entities.NewTranscript("call-1", entities.TranscriptRoleUser, "Don’t call me again", time.Now()),
+			},
+			wantListed: true,
+		},
+		{
+			name: "assistant mentions opting out",
+			messages: []*entities.Transcript{
+				entities.NewTranscript("call-1", entities.TranscriptRoleAssistant, "You can opt out at any time.", time.Now()),
+				entities.NewTranscript("call-1", entities.TranscriptRoleUser, "Thanks, see you Tuesday.", time.Now()),
+			},
+			wantListed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, dncRepo, _ := newTestComplianceService(entities.DefaultBusinessSettings(), time.Now())
+			call := &entities.Call{ID: "call-1", BusinessID: "business-123", CallerPhone: "+12125551234"}
+
+			got := service.HandleTranscript(context.Background(), call, tt.messages)
+
+			if got != tt.wantListed {
+				t.Errorf("HandleTranscript() = %v, want %v", got, tt.wantListed)
+			}
+
+			listed, _ := dncRepo.IsListed(context.Background(), "business-123", call.CallerPhone)
+			if listed != tt.wantListed {
+				t.Errorf("number listed = %v, want %v", listed, tt.wantListed)
+			}
+			if listed && dncRepo.entries[0].Source != entities.DoNotCallSourceOptOut {
+				t.Errorf("expected source opt_out, got %s", dncRepo.entries[0].Source)
+			}
+		})
+	}
+}
+
+func TestComplianceService_RemoveFromDoNotCall(t *testing.T) {
+	service, dncRepo, _ := newTestComplianceService(entities.DefaultBusinessSettings(), time.Now())
+	ctx := context.Background()
+
+	own, err := service.AddToDoNotCall(ctx, "business-123", dto.AddDoNotCallRequest{Phone: "(212) 555-1234"})
+	if err != nil {
+		t.Fatalf("AddToDoNotCall() error = %v", err)
+	}
+	if own.Phone != "+12125551234" {
+		t.Errorf("expected normalised phone +12125551234, got %s", own.Phone)
+	}
+
+	global := &entities.DoNotCallEntry{Phone: "+12125559999"}
+	dncRepo.Create(ctx, global)
+
+	if err := service.RemoveFromDoNotCall(ctx, "business-123", global.ID); err == nil {
+		t.Error("expected error removing a global entry")
+	}
+	if err := service.RemoveFromDoNotCall(ctx, "business-123", own.ID); err != nil {
+		t.Errorf("RemoveFromDoNotCall() error = %v", err)
+	}
+}
+
+func TestMatchOptOut(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"Please take me off your list.", "take me off your list"},
+		{"Don't call me.", "don't call me"},
+		{"Please do not call me again!", "do not call me"},
+		{"Stop calling, thanks", "stop calling"},
+		{"Put me on your do-not-call list", "do not call list"},
+		{"I'd like to OPT OUT of these calls", "opt out"},
+		{"Okay. Unsubscribe.", "unsubscribe"},
+		{"Please do not call before 9", ""},
+		{"Don't call me at work, call my cell", ""},
+		{"Do not call after 5pm on weekdays", ""},
+		{"Stop calling my husband's phone and call me instead", ""},
+		{"What are the options? I'll take the output.", ""},
+		{"The doctor said not to call it a cold", ""},
+		{"See you Tuesday", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			if got := matchOptOut(tt.message); got != tt.want {
+				t.Errorf("matchOptOut(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallService_InitiateCallBlockedByCompliance(t *testing.T) {
+	compliance, dncRepo, _ := newTestComplianceService(entities.DefaultBusinessSettings(), time.Now())
+	businessID := "business-123"
+	dncRepo.entries = append(dncRepo.entries, &entities.DoNotCallEntry{BusinessID: &businessID, Phone: "+12125551234"})
+
+	callRepo := newTestCallRepository()
+	provider := &testVoiceProvider{
+		initiateCallFunc: func(ctx context.Context, req providers.CallRequest) (*providers.CallSession, error) {
+			t.Error("provider should not be called for a blocked number")
+			return &providers.CallSession{ID: "provider-call-123"}, nil
+		},
+	}
+
+	service := NewCallService(
+		callRepo,
+		newTestTranscriptRepository(),
+		newTestInteractionRepository(),
+		compliance.businessRepo,
+		provider,
+		logger.New("info", "console"),
+	)
+	service.SetCompliance(compliance)
+
+	_, err := service.InitiateCall(context.Background(), businessID, dto.InitiateCallRequest{PhoneNumber: "+12125551234", AssistantID: "assistant-1"})
+	if !isComplianceError(err) {
+		t.Errorf("expected compliance error, got %v", err)
+	}
+	if len(callRepo.calls) != 0 {
+		t.Errorf("expected no call record, got %d", len(callRepo.calls))
+	}
+}
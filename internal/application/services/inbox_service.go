@@ -0,0 +1,559 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+const (
+	// inboxLookback is how far back complaints and failed calls are picked up
+	inboxLookback = 30 * 24 * time.Hour
+	// inboxMessageLimit caps the unresolved messages read per status
+	inboxMessageLimit = 200
+)
+
+const (
+	inboxViewActive  = "active"
+	inboxViewSnoozed = "snoozed"
+	inboxViewDone    = "done"
+	inboxViewAll     = "all"
+)
+
+// InboxService merges pending appointment requests, complaints, unresolved
+// messages and failed calls into one prioritised feed, and tracks who is
+// following each item up
+type InboxService struct {
+	inboxRepo       database.InboxRepository
+	appointmentRepo database.AppointmentRepository
+	interactionRepo database.InteractionRepository
+	messageRepo     database.MessageRepository
+	callRepo        database.CallRepository
+	userRepo        database.UserRepository
+	logger          *logger.Logger
+
+	now func() time.Time
+}
+
+func NewInboxService(
+	inboxRepo database.InboxRepository,
+	appointmentRepo database.AppointmentRepository,
+	interactionRepo database.InteractionRepository,
+	messageRepo database.MessageRepository,
+	callRepo database.CallRepository,
+	userRepo database.UserRepository,
+	log *logger.Logger,
+) *InboxService {
+	return &InboxService{
+		inboxRepo:       inboxRepo,
+		appointmentRepo: appointmentRepo,
+		interactionRepo: interactionRepo,
+		messageRepo:     messageRepo,
+		callRepo:        callRepo,
+		userRepo:        userRepo,
+		logger:          log,
+		now:             time.Now,
+	}
+}
+
+// ListInbox returns the business's inbox, highest priority and newest first.
+// By default only active items are listed: snoozed and done items are
+// hidden.
+func (s *InboxService) ListInbox(ctx context.Context, businessID string, req dto.ListInboxRequest) (*dto.ListInboxResponse, error) {
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+	if req.View == "" {
+		req.View = inboxViewActive
+	}
+
+	switch req.View {
+	case inboxViewActive, inboxViewSnoozed, inboxViewDone, inboxViewAll:
+	default:
+		return nil, errors.NewFieldValidationError("view", "view must be active, snoozed, done or all")
+	}
+	if req.Type != "" && !entities.InboxItemType(req.Type).IsValid() {
+		return nil, errors.NewFieldValidationError("type", "type must be appointment_request, complaint, message or failed_call")
+	}
+	if req.Priority != "" && !entities.InboxPriority(req.Priority).IsValid() {
+		return nil, errors.NewFieldValidationError("priority", "priority must be high, normal or low")
+	}
+
+	items, err := s.collect(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	var matched []*entities.InboxItem
+	for _, item := range items {
+		if req.View != inboxViewAll && inboxItemStatus(item.State, now) != req.View {
+			continue
+		}
+		if req.Type != "" && string(item.Type) != req.Type {
+			continue
+		}
+		if req.Priority != "" && string(item.Priority) != req.Priority {
+			continue
+		}
+		if req.AssigneeID != "" && !inboxAssigneeMatches(item.State, req.AssigneeID) {
+			continue
+		}
+		matched = append(matched, item)
+	}
+
+	response := &dto.ListInboxResponse{
+		Items:  make([]dto.InboxItemResponse, 0, req.Limit),
+		Total:  len(matched),
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+	for i := req.Offset; i < len(matched) && i < req.Offset+req.Limit; i++ {
+		response.Items = append(response.Items, mapInboxItemToResponse(matched[i], now))
+	}
+
+	return response, nil
+}
+
+// GetCounts returns the badge counters for the inbox as seen by a user
+func (s *InboxService) GetCounts(ctx context.Context, businessID, userID string) (*dto.InboxCountsResponse, error) {
+	items, err := s.collect(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	counts := &dto.InboxCountsResponse{
+		ByType: map[string]int{
+			string(entities.InboxItemTypeAppointmentRequest): 0,
+			string(entities.InboxItemTypeComplaint):          0,
+			string(entities.InboxItemTypeMessage):            0,
+			string(entities.InboxItemTypeFailedCall):         0,
+		},
+	}
+	for _, item := range items {
+		if item.State.IsSnoozed(now) {
+			counts.Snoozed++
+		}
+		if !item.State.IsActive(now) {
+			continue
+		}
+
+		counts.Active++
+		counts.ByType[string(item.Type)]++
+		if item.Priority == entities.InboxPriorityHigh {
+			counts.HighPriority++
+		}
+		if item.State.AssigneeID == nil {
+			counts.Unassigned++
+		} else if *item.State.AssigneeID == userID {
+			counts.Mine++
+		}
+	}
+
+	return counts, nil
+}
+
+// GetItem returns an inbox item with its comments
+func (s *InboxService) GetItem(ctx context.Context, businessID, id string) (*dto.InboxItemResponse, error) {
+	item, err := s.loadItem(ctx, businessID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.inboxRepo.GetComments(ctx, businessID, item.Type, item.SourceID)
+	if err != nil {
+		return nil, err
+	}
+	item.CommentCount = len(comments)
+
+	response := mapInboxItemToResponse(item, s.now())
+	response.Comments = make([]dto.InboxCommentResponse, 0, len(comments))
+	for _, comment := range comments {
+		response.Comments = append(response.Comments, mapInboxCommentToResponse(comment))
+	}
+
+	return &response, nil
+}
+
+// AssignItem hands an item to a user of the business, or unassigns it
+func (s *InboxService) AssignItem(ctx context.Context, businessID, id string, req dto.AssignInboxItemRequest) (*dto.InboxItemResponse, error) {
+	if req.UserID != nil {
+		if err := checkBusinessUser(ctx, s.userRepo, businessID, *req.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.updateItem(ctx, businessID, id, func(state *entities.InboxItemState) error {
+		state.Assign(req.UserID)
+		return nil
+	})
+}
+
+// SnoozeItem hides an item from the active feed until the given time
+func (s *InboxService) SnoozeItem(ctx context.Context, businessID, id string, req dto.SnoozeInboxItemRequest) (*dto.InboxItemResponse, error) {
+	until, err := parseOptionalTime("until", req.Until)
+	if err != nil {
+		return nil, err
+	}
+	if until == nil {
+		return nil, errors.NewFieldValidationError("until", "until is required")
+	}
+
+	return s.updateItem(ctx, businessID, id, func(state *entities.InboxItemState) error {
+		return state.Snooze(*until, s.now())
+	})
+}
+
+func (s *InboxService) MarkDone(ctx context.Context, businessID, userID, id string) (*dto.InboxItemResponse, error) {
+	return s.updateItem(ctx, businessID, id, func(state *entities.InboxItemState) error {
+		return state.MarkDone(userID)
+	})
+}
+
+// ReopenItem returns a done or snoozed item to the active feed
+func (s *InboxService) ReopenItem(ctx context.Context, businessID, id string) (*dto.InboxItemResponse, error) {
+	return s.updateItem(ctx, businessID, id, func(state *entities.InboxItemState) error {
+		state.Reopen()
+		return nil
+	})
+}
+
+func (s *InboxService) AddComment(ctx context.Context, businessID, userID, id string, req dto.InboxCommentRequest) (*dto.InboxCommentResponse, error) {
+	item, err := s.loadItem(ctx, businessID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := entities.NewInboxComment(businessID, item.Type, item.SourceID, userID, req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.inboxRepo.CreateComment(ctx, comment); err != nil {
+		s.logger.Error("Failed to create inbox comment", err, map[string]interface{}{
+			"item_id": item.ID,
+		})
+		return nil, err
+	}
+
+	response := mapInboxCommentToResponse(comment)
+	return &response, nil
+}
+
+func (s *InboxService) updateItem(ctx context.Context, businessID, id string, change func(*entities.InboxItemState) error) (*dto.InboxItemResponse, error) {
+	item, err := s.loadItem(ctx, businessID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := change(item.State); err != nil {
+		return nil, err
+	}
+
+	if err := s.inboxRepo.SaveState(ctx, item.State); err != nil {
+		s.logger.Error("Failed to save inbox item state", err, map[string]interface{}{
+			"item_id": item.ID,
+		})
+		return nil, err
+	}
+
+	counts, err := s.inboxRepo.CountComments(ctx, businessID, []string{item.SourceID})
+	if err != nil {
+		return nil, err
+	}
+	item.CommentCount = counts[item.SourceID]
+
+	response := mapInboxItemToResponse(item, s.now())
+	return &response, nil
+}
+
+// collect builds every inbox item of the business, with its follow-up state
+// and comment count, sorted highest priority and newest first
+func (s *InboxService) collect(ctx context.Context, businessID string) ([]*entities.InboxItem, error) {
+	now := s.now()
+	since := now.Add(-inboxLookback)
+	var items []*entities.InboxItem
+
+	appointments, err := s.appointmentRepo.GetPendingAppointments(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	for _, apt := range appointments {
+		items = append(items, appointmentInboxItem(apt))
+	}
+
+	calls, err := s.callRepo.GetByDateRange(ctx, businessID, since, now)
+	if err != nil {
+		return nil, err
+	}
+	callsByID := make(map[string]*entities.Call, len(calls))
+	for _, call := range calls {
+		callsByID[call.ID] = call
+		if call.Status == entities.CallStatusFailed {
+			items = append(items, failedCallInboxItem(call))
+		}
+	}
+
+	complaints, err := s.interactionRepo.GetByType(ctx, businessID, entities.InteractionTypeComplaint, since, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, complaint := range complaints {
+		call, ok := callsByID[complaint.CallID]
+		if !ok {
+			if call, err = s.callRepo.GetByID(ctx, complaint.CallID); err != nil {
+				return nil, err
+			}
+		}
+		items = append(items, complaintInboxItem(complaint, call))
+	}
+
+	for _, status := range []entities.MessageStatus{entities.MessageStatusOpen, entities.MessageStatusAcknowledged} {
+		messages, err := s.messageRepo.List(ctx, businessID, database.MessageFilter{Status: status}, inboxMessageLimit, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, message := range messages {
+			items = append(items, messageInboxItem(message))
+		}
+	}
+
+	sourceIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		sourceIDs = append(sourceIDs, item.SourceID)
+	}
+
+	states, err := s.inboxRepo.GetStates(ctx, businessID, sourceIDs)
+	if err != nil {
+		return nil, err
+	}
+	statesByKey := make(map[string]*entities.InboxItemState, len(states))
+	for _, state := range states {
+		statesByKey[entities.InboxItemKey(state.ItemType, state.SourceID)] = state
+	}
+
+	commentCounts, err := s.inboxRepo.CountComments(ctx, businessID, sourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		if state, ok := statesByKey[item.ID]; ok {
+			item.State = state
+		}
+		item.CommentCount = commentCounts[item.SourceID]
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Priority.Rank() != items[j].Priority.Rank() {
+			return items[i].Priority.Rank() > items[j].Priority.Rank()
+		}
+		return items[i].OccurredAt.After(items[j].OccurredAt)
+	})
+
+	return items, nil
+}
+
+// loadItem builds a single inbox item from the record its ID points at
+func (s *InboxService) loadItem(ctx context.Context, businessID, id string) (*entities.InboxItem, error) {
+	itemType, sourceID, err := entities.ParseInboxItemKey(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var item *entities.InboxItem
+	switch itemType {
+	case entities.InboxItemTypeAppointmentRequest:
+		apt, err := s.appointmentRepo.GetByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		if apt.BusinessID != businessID {
+			return nil, errors.NewForbiddenError("access denied to this inbox item")
+		}
+		item = appointmentInboxItem(apt)
+
+	case entities.InboxItemTypeComplaint:
+		complaint, err := s.interactionRepo.GetByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		if complaint.Type != entities.InteractionTypeComplaint {
+			return nil, errors.NewNotFoundError("inbox item", id)
+		}
+		call, err := s.callRepo.GetByID(ctx, complaint.CallID)
+		if err != nil {
+			return nil, err
+		}
+		if call.BusinessID != businessID {
+			return nil, errors.NewForbiddenError("access denied to this inbox item")
+		}
+		item = complaintInboxItem(complaint, call)
+
+	case entities.InboxItemTypeMessage:
+		message, err := s.messageRepo.GetByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		if message.BusinessID != businessID {
+			return nil, errors.NewForbiddenError("access denied to this inbox item")
+		}
+		item = messageInboxItem(message)
+
+	case entities.InboxItemTypeFailedCall:
+		call, err := s.callRepo.GetByID(ctx, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		if call.BusinessID != businessID {
+			return nil, errors.NewForbiddenError("access denied to this inbox item")
+		}
+		if call.Status != entities.CallStatusFailed {
+			return nil, errors.NewNotFoundError("inbox item", id)
+		}
+		item = failedCallInboxItem(call)
+	}
+
+	state, err := s.inboxRepo.GetState(ctx, businessID, itemType, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if state != nil {
+		item.State = state
+	}
+
+	return item, nil
+}
+
+func appointmentInboxItem(apt *entities.AppointmentRequest) *entities.InboxItem {
+	item := entities.NewInboxItem(apt.BusinessID, entities.InboxItemTypeAppointmentRequest, apt.ID, entities.InboxPriorityNormal, apt.CreatedAt)
+	item.CallID = &apt.CallID
+	item.Phone = apt.CustomerPhone
+	item.Title = "Appointment request from " + firstNonEmpty(apt.CustomerName, apt.CustomerPhone)
+
+	var parts []string
+	if apt.ServiceType != "" {
+		parts = append(parts, apt.ServiceType)
+	}
+	if apt.RequestedDate != nil {
+		parts = append(parts, "on "+apt.RequestedDate.Format("Monday, January 2"))
+	}
+	if apt.RequestedTime != "" {
+		parts = append(parts, "at "+apt.RequestedTime)
+	}
+	item.Summary = strings.Join(parts, " ")
+	return item
+}
+
+func complaintInboxItem(complaint *entities.Interaction, call *entities.Call) *entities.InboxItem {
+	item := entities.NewInboxItem(call.BusinessID, entities.InboxItemTypeComplaint, complaint.ID, entities.InboxPriorityHigh, complaint.Timestamp)
+	item.CallID = &complaint.CallID
+	item.Phone = call.CallerPhone
+	item.Title = "Complaint from " + call.CallerPhone
+	item.Summary = complaint.Summary()
+	return item
+}
+
+func messageInboxItem(message *entities.Message) *entities.InboxItem {
+	priority := entities.InboxPriorityNormal
+	switch message.Urgency {
+	case entities.MessageUrgencyUrgent:
+		priority = entities.InboxPriorityHigh
+	case entities.MessageUrgencyLow:
+		priority = entities.InboxPriorityLow
+	}
+
+	item := entities.NewInboxItem(message.BusinessID, entities.InboxItemTypeMessage, message.ID, priority, message.CreatedAt)
+	item.CallID = message.CallID
+	item.Phone = message.CallbackPhone
+	item.Title = "Message from " + firstNonEmpty(message.CallerName, message.CallerPhone)
+	if message.RecipientName != "" {
+		item.Title += " for " + message.RecipientName
+	}
+	item.Summary = message.Body
+	// Until someone assigns the item in the inbox, it follows the message's assignee
+	item.State.AssigneeID = message.AssigneeID
+	return item
+}
+
+func failedCallInboxItem(call *entities.Call) *entities.InboxItem {
+	item := entities.NewInboxItem(call.BusinessID, entities.InboxItemTypeFailedCall, call.ID, entities.InboxPriorityNormal, call.CreatedAt)
+	item.CallID = &call.ID
+	item.Phone = call.CallerPhone
+	if call.Direction == entities.CallDirectionInbound {
+		item.Title = "Failed call from " + call.CallerPhone
+	} else {
+		item.Title = "Failed call to " + call.CallerPhone
+	}
+	return item
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func inboxItemStatus(state *entities.InboxItemState, now time.Time) string {
+	switch {
+	case state.IsDone():
+		return inboxViewDone
+	case state.IsSnoozed(now):
+		return inboxViewSnoozed
+	}
+	return inboxViewActive
+}
+
+// inboxAssigneeMatches filters on a user ID, or "unassigned"
+func inboxAssigneeMatches(state *entities.InboxItemState, assigneeID string) bool {
+	if assigneeID == "unassigned" {
+		return state.AssigneeID == nil
+	}
+	return state.AssigneeID != nil && *state.AssigneeID == assigneeID
+}
+
+func mapInboxItemToResponse(item *entities.InboxItem, now time.Time) dto.InboxItemResponse {
+	return dto.InboxItemResponse{
+		ID:           item.ID,
+		Type:         string(item.Type),
+		SourceID:     item.SourceID,
+		CallID:       item.CallID,
+		Title:        item.Title,
+		Summary:      item.Summary,
+		Phone:        item.Phone,
+		Priority:     string(item.Priority),
+		Status:       inboxItemStatus(item.State, now),
+		OccurredAt:   item.OccurredAt.Format(time.RFC3339),
+		AssigneeID:   item.State.AssigneeID,
+		SnoozedUntil: formatOptionalTime(item.State.SnoozedUntil),
+		DoneBy:       item.State.DoneBy,
+		DoneAt:       formatOptionalTime(item.State.DoneAt),
+		CommentCount: item.CommentCount,
+	}
+}
+
+func mapInboxCommentToResponse(comment *entities.InboxComment) dto.InboxCommentResponse {
+	return dto.InboxCommentResponse{
+		ID:        comment.ID,
+		UserID:    comment.UserID,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,428 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// newMockInboxService returns an inbox holding, in feed order: a complaint,
+// an urgent message, a failed call, a pending appointment request and a
+// low-urgency message
+func newMockInboxService(t *testing.T, now time.Time) *InboxService {
+	t.Helper()
+	ctx := context.Background()
+
+	callRepo := newTestCallRepository()
+	addCall := func(id, businessID string, status entities.CallStatus, createdAt time.Time) {
+		call, err := entities.NewInboundCall(businessID, "+15551234567", "provider-"+id)
+		if err != nil {
+			t.Fatalf("NewInboundCall() error = %v", err)
+		}
+		call.ID = id
+		call.Status = status
+		call.CreatedAt = createdAt
+		callRepo.calls[id] = call
+	}
+	addCall("call-failed", "business-123", entities.CallStatusFailed, now.Add(-time.Hour))
+	addCall("call-complaint", "business-123", entities.CallStatusCompleted, now.Add(-40*time.Minute))
+	addCall("call-other", "business-456", entities.CallStatusFailed, now.Add(-time.Hour))
+	addCall("call-old", "business-123", entities.CallStatusFailed, now.Add(-45*24*time.Hour))
+
+	interactionRepo := newTestInteractionRepository()
+	complaint, _ := entities.NewInteraction("call-complaint", entities.InteractionTypeComplaint, map[string]interface{}{
+		"summary": "Billed twice for the same visit",
+	})
+	complaint.ID = "complaint-1"
+	complaint.Timestamp = now.Add(-30 * time.Minute)
+	interactionRepo.Create(ctx, complaint)
+
+	appointmentRepo := &testAppointmentRepository{}
+	pending, _ := entities.NewAppointmentRequest("call-complaint", "business-123", "Jane Doe", "+15551234567", nil, "3pm", "cleaning", "")
+	pending.ID = "appointment-1"
+	pending.CreatedAt = now.Add(-2 * time.Hour)
+	appointmentRepo.Create(ctx, pending)
+	confirmed, _ := entities.NewAppointmentRequest("call-complaint", "business-123", "Jane Doe", "+15551234567", nil, "", "", "")
+	confirmed.ID = "appointment-2"
+	confirmed.Confirm()
+	appointmentRepo.Create(ctx, confirmed)
+
+	messageRepo := &mockMessageRepository{}
+	addMessage := func(urgency entities.MessageUrgency, createdAt time.Time) *entities.Message {
+		message, err := entities.NewMessage("business-123", "+12125551234", "", "Please call back", urgency, entities.MessageSourceTool)
+		if err != nil {
+			t.Fatalf("NewMessage() error = %v", err)
+		}
+		message.CreatedAt = createdAt
+		messageRepo.Create(ctx, message)
+		return message
+	}
+	urgent := addMessage(entities.MessageUrgencyUrgent, now.Add(-3*time.Hour))
+	urgent.AssigneeID = strPtr("user-lee")
+	low := addMessage(entities.MessageUrgencyLow, now.Add(-4*time.Hour))
+	low.Acknowledge("user-lee")
+	resolved := addMessage(entities.MessageUrgencyNormal, now.Add(-time.Hour))
+	resolved.Resolve("user-lee")
+
+	userRepo := &mockUserRepository{users: map[string]*entities.User{
+		"user-front": {ID: "user-front", BusinessID: "business-123", Role: entities.UserRoleEmployee},
+		"user-other": {ID: "user-other", BusinessID: "business-456", Role: entities.UserRoleOwner},
+	}}
+
+	service := NewInboxService(newMockInboxRepository(), appointmentRepo, interactionRepo, messageRepo, callRepo, userRepo, logger.New("info", "console"))
+	service.now = func() time.Time { return now }
+	return service
+}
+
+func inboxIDs(items []dto.InboxItemResponse) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestInboxService_ListInbox(t *testing.T) {
+	now := time.Date(2024, 10, 1, 14, 0, 0, 0, time.UTC)
+	markDone := func(ctx context.Context, service *InboxService) error {
+		_, err := service.MarkDone(ctx, "business-123", "user-front", "failed_call:call-failed")
+		return err
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(ctx context.Context, service *InboxService) error
+		req     dto.ListInboxRequest
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "prioritised feed",
+			req:  dto.ListInboxRequest{},
+			want: []string{"complaint:complaint-1", "message:message-1", "failed_call:call-failed", "appointment_request:appointment-1", "message:message-2"},
+		},
+		{
+			name: "filtered by type",
+			req:  dto.ListInboxRequest{Type: "message"},
+			want: []string{"message:message-1", "message:message-2"},
+		},
+		{
+			name: "filtered by priority",
+			req:  dto.ListInboxRequest{Priority: "high"},
+			want: []string{"complaint:complaint-1", "message:message-1"},
+		},
+		{
+			name: "message assignee carried into the inbox",
+			req:  dto.ListInboxRequest{AssigneeID: "user-lee"},
+			want: []string{"message:message-1"},
+		},
+		{
+			name: "paginated",
+			req:  dto.ListInboxRequest{Limit: 2, Offset: 1},
+			want: []string{"message:message-1", "failed_call:call-failed"},
+		},
+		{
+			name:  "done item left out",
+			setup: markDone,
+			req:   dto.ListInboxRequest{},
+			want:  []string{"complaint:complaint-1", "message:message-1", "appointment_request:appointment-1", "message:message-2"},
+		},
+		{
+			name: "snoozed item left out",
+			setup: func(ctx context.Context, service *InboxService) error {
+				_, err := service.SnoozeItem(ctx, "business-123", "complaint:complaint-1", dto.SnoozeInboxItemRequest{Until: now.Add(time.Hour).Format(time.RFC3339)})
+				return err
+			},
+			req:  dto.ListInboxRequest{},
+			want: []string{"message:message-1", "failed_call:call-failed", "appointment_request:appointment-1", "message:message-2"},
+		},
+		{
+			name:  "done view",
+			setup: markDone,
+			req:   dto.ListInboxRequest{View: "done"},
+			want:  []string{"failed_call:call-failed"},
+		},
+		{
+			name:    "invalid view",
+			req:     dto.ListInboxRequest{View: "archived"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid type",
+			req:     dto.ListInboxRequest{Type: "voicemail"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service := newMockInboxService(t, now)
+			if tt.setup != nil {
+				if err := tt.setup(ctx, service); err != nil {
+					t.Fatalf("setup error = %v", err)
+				}
+			}
+
+			response, err := service.ListInbox(ctx, "business-123", tt.req)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ListInbox() error = %v", err)
+			}
+
+			if got := inboxIDs(response.Items); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInboxService_AssignItem(t *testing.T) {
+	now := time.Date(2024, 10, 1, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		userID  string
+		wantErr bool
+	}{
+		{
+			name:   "team member",
+			userID: "user-front",
+		},
+		{
+			name:    "user of another business",
+			userID:  "user-other",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newMockInboxService(t, now)
+
+			assigned, err := service.AssignItem(context.Background(), "business-123", "appointment_request:appointment-1", dto.AssignInboxItemRequest{UserID: strPtr(tt.userID)})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AssignItem() error = %v", err)
+			}
+			if assigned.AssigneeID == nil || *assigned.AssigneeID != tt.userID {
+				t.Errorf("expected assignee %s, got %v", tt.userID, assigned.AssigneeID)
+			}
+		})
+	}
+}
+
+func TestInboxService_SnoozeItem(t *testing.T) {
+	now := time.Date(2024, 10, 1, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		until      time.Time
+		later      time.Duration
+		wantErr    bool
+		wantStatus string
+	}{
+		{
+			name:       "until later today",
+			until:      now.Add(time.Hour),
+			wantStatus: "snoozed",
+		},
+		{
+			name:       "snooze expired",
+			until:      now.Add(time.Hour),
+			later:      2 * time.Hour,
+			wantStatus: "active",
+		},
+		{
+			name:    "into the past",
+			until:   now.Add(-time.Minute),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service := newMockInboxService(t, now)
+
+			_, err := service.SnoozeItem(ctx, "business-123", "complaint:complaint-1", dto.SnoozeInboxItemRequest{Until: tt.until.Format(time.RFC3339)})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SnoozeItem() error = %v", err)
+			}
+
+			service.now = func() time.Time { return now.Add(tt.later) }
+			item, err := service.GetItem(ctx, "business-123", "complaint:complaint-1")
+			if err != nil {
+				t.Fatalf("GetItem() error = %v", err)
+			}
+			if item.Status != tt.wantStatus || item.Summary != "Billed twice for the same visit" {
+				t.Errorf("unexpected complaint item %+v", item)
+			}
+		})
+	}
+}
+
+func TestInboxService_ItemWorkflow(t *testing.T) {
+	now := time.Date(2024, 10, 1, 14, 0, 0, 0, time.UTC)
+	markDone := func(ctx context.Context, service *InboxService) (*dto.InboxItemResponse, error) {
+		return service.MarkDone(ctx, "business-123", "user-front", "failed_call:call-failed")
+	}
+
+	tests := []struct {
+		name     string
+		setup    func(ctx context.Context, service *InboxService) (*dto.InboxItemResponse, error)
+		act      func(ctx context.Context, service *InboxService) (*dto.InboxItemResponse, error)
+		validate func(t *testing.T, item *dto.InboxItemResponse)
+	}{
+		{
+			name: "mark done",
+			act:  markDone,
+			validate: func(t *testing.T, item *dto.InboxItemResponse) {
+				if item.Status != "done" || item.DoneBy == nil {
+					t.Errorf("unexpected done item %+v", item)
+				}
+			},
+		},
+		{
+			name:  "reopen",
+			setup: markDone,
+			act: func(ctx context.Context, service *InboxService) (*dto.InboxItemResponse, error) {
+				return service.ReopenItem(ctx, "business-123", "failed_call:call-failed")
+			},
+			validate: func(t *testing.T, item *dto.InboxItemResponse) {
+				if item.Status != "active" {
+					t.Errorf("expected reopened item to be active, got %s", item.Status)
+				}
+			},
+		},
+		{
+			name: "comment",
+			setup: func(ctx context.Context, service *InboxService) (*dto.InboxItemResponse, error) {
+				_, err := service.AddComment(ctx, "business-123", "user-front", "failed_call:call-failed", dto.InboxCommentRequest{Body: "Called back, all sorted"})
+				return nil, err
+			},
+			act: func(ctx context.Context, service *InboxService) (*dto.InboxItemResponse, error) {
+				return service.GetItem(ctx, "business-123", "failed_call:call-failed")
+			},
+			validate: func(t *testing.T, item *dto.InboxItemResponse) {
+				if len(item.Comments) != 1 || item.Comments[0].UserID != "user-front" || item.CommentCount != 1 {
+					t.Errorf("expected the comment on the item, got %+v", item.Comments)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service := newMockInboxService(t, now)
+			if tt.setup != nil {
+				if _, err := tt.setup(ctx, service); err != nil {
+					t.Fatalf("setup error = %v", err)
+				}
+			}
+
+			item, err := tt.act(ctx, service)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			tt.validate(t, item)
+		})
+	}
+}
+
+func TestInboxService_GetCounts(t *testing.T) {
+	now := time.Date(2024, 10, 1, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		userID   string
+		wantMine int
+	}{
+		{
+			name:     "assigned an appointment request",
+			userID:   "user-front",
+			wantMine: 1,
+		},
+		{
+			name:     "assigned a message",
+			userID:   "user-lee",
+			wantMine: 1,
+		},
+		{
+			name:   "nothing assigned",
+			userID: "user-other",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			service := newMockInboxService(t, now)
+			if _, err := service.AssignItem(ctx, "business-123", "appointment_request:appointment-1", dto.AssignInboxItemRequest{UserID: strPtr("user-front")}); err != nil {
+				t.Fatalf("AssignItem() error = %v", err)
+			}
+			if _, err := service.SnoozeItem(ctx, "business-123", "complaint:complaint-1", dto.SnoozeInboxItemRequest{Until: now.Add(time.Hour).Format(time.RFC3339)}); err != nil {
+				t.Fatalf("SnoozeItem() error = %v", err)
+			}
+			if _, err := service.MarkDone(ctx, "business-123", "user-front", "failed_call:call-failed"); err != nil {
+				t.Fatalf("MarkDone() error = %v", err)
+			}
+
+			counts, err := service.GetCounts(ctx, "business-123", tt.userID)
+			if err != nil {
+				t.Fatalf("GetCounts() error = %v", err)
+			}
+			if counts.Active != 3 || counts.Mine != tt.wantMine || counts.Unassigned != 1 || counts.HighPriority != 1 || counts.Snoozed != 1 {
+				t.Errorf("unexpected counts %+v", counts)
+			}
+			if counts.ByType["message"] != 2 || counts.ByType["complaint"] != 0 {
+				t.Errorf("unexpected counts by type %+v", counts.ByType)
+			}
+		})
+	}
+}
+
+func TestInboxService_GetItemAccess(t *testing.T) {
+	now := time.Date(2024, 10, 1, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		id   string
+	}{
+		{"another business's call", "failed_call:call-other"},
+		{"call that did not fail", "failed_call:call-complaint"},
+		{"malformed id", "call-failed"},
+		{"unknown type", "voicemail:call-failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newMockInboxService(t, now)
+			if _, err := service.GetItem(context.Background(), "business-123", tt.id); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -16,6 +16,8 @@ type InteractionService struct {
 	appointmentRepo database.AppointmentRepository
 	callRepo        database.CallRepository
 	logger          *logger.Logger
+
+	audit *AuditService
 }
 
 func NewInteractionService(
@@ -32,6 +34,12 @@ func NewInteractionService(
 	}
 }
 
+// SetAudit records who confirmed, cancelled or completed each appointment in
+// the audit log
+func (s *InteractionService) SetAudit(audit *AuditService) {
+	s.audit = audit
+}
+
 func (s *InteractionService) GetCallInteractions(ctx context.Context, businessID, callID string) ([]dto.InteractionResponse, error) {
 	// Verify call belongs to business
 	call, err := s.callRepo.GetByID(ctx, callID)
@@ -154,6 +162,8 @@ func (s *InteractionService) UpdateAppointmentStatus(ctx context.Context, busine
 		return nil, errors.NewForbiddenError("access denied to this appointment")
 	}
 
+	previousStatus := apt.Status
+
 	// Update status
 	switch entities.AppointmentStatus(req.Status) {
 	case entities.AppointmentStatusConfirmed:
@@ -185,6 +195,16 @@ func (s *InteractionService) UpdateAppointmentStatus(ctx context.Context, busine
 		"new_status":     req.Status,
 	})
 
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionAppointmentUpdated,
+		TargetType: "appointment",
+		TargetID:   apt.ID,
+		Changes: map[string]entities.AuditChange{
+			"status": {Before: string(previousStatus), After: string(apt.Status)},
+		},
+	})
+
 	// Build response
 	aptResponse := dto.AppointmentResponse{
 		ID:            apt.ID,
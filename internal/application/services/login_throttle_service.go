@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/config"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// LoginThrottleService slows down and locks out repeated failed logins, per
+// account and per client IP. Throttled attempts get the same error as a
+// wrong password, and unknown emails are counted like real accounts, so
+// neither gives away whether an account exists.
+type LoginThrottleService struct {
+	repo           database.LoginThrottleRepository
+	cfg            config.LoginThrottleConfig
+	trustedProxies []*net.IPNet
+	logger         *logger.Logger
+
+	now func() time.Time
+}
+
+func NewLoginThrottleService(
+	repo database.LoginThrottleRepository,
+	cfg *config.Config,
+	log *logger.Logger,
+) *LoginThrottleService {
+	return &LoginThrottleService{
+		repo:           repo,
+		cfg:            cfg.Login,
+		trustedProxies: parseTrustedProxies(cfg.Login.TrustedProxies, log),
+		logger:         log,
+		now:            time.Now,
+	}
+}
+
+// ClientIP returns the address a login came from. X-Forwarded-For is only
+// believed when the connection comes from a trusted proxy, and then the
+// closest address that is not itself a trusted proxy is used.
+func (s *LoginThrottleService) ClientIP(remoteAddr, forwardedFor string) string {
+	ip := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		ip = host
+	}
+	if !s.isTrustedProxy(ip) || forwardedFor == "" {
+		return ip
+	}
+
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !s.isTrustedProxy(hop) {
+			break
+		}
+	}
+	return ip
+}
+
+// Check refuses a login while the account or IP is locked out or still
+// waiting out the delay after its last failure
+func (s *LoginThrottleService) Check(ctx context.Context, email, ip string) error {
+	now := s.now()
+	for _, key := range s.keys(email, ip) {
+		throttle, err := s.repo.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if throttle == nil {
+			continue
+		}
+
+		locked := throttle.IsLocked(now)
+		waiting := !throttle.LastFailureAt.Before(now.Add(-s.cfg.Window)) &&
+			now.Before(throttle.RetryAt(s.cfg.BaseDelay, s.cfg.MaxDelay))
+		if locked || waiting {
+			s.logger.Warn("Login throttled", map[string]interface{}{
+				"key":      key,
+				"failures": throttle.Failures,
+				"locked":   locked,
+			})
+			return errors.NewUnauthorizedError("invalid credentials")
+		}
+	}
+
+	return nil
+}
+
+// RecordFailure counts a failed login against the account and IP, locking
+// either out once it reaches its limit
+func (s *LoginThrottleService) RecordFailure(ctx context.Context, email, ip string) {
+	now := s.now()
+	for _, key := range s.keys(email, ip) {
+		throttle, err := s.repo.RecordFailure(ctx, key, now, now.Add(-s.cfg.Window))
+		if err != nil {
+			s.logger.Error("Failed to record login failure", err, map[string]interface{}{
+				"key": key,
+			})
+			continue
+		}
+
+		if throttle.Failures < s.maxFailures(key) || throttle.IsLocked(now) {
+			continue
+		}
+
+		until := now.Add(s.cfg.LockoutDuration)
+		if err := s.repo.Lock(ctx, key, until); err != nil {
+			s.logger.Error("Failed to lock out login", err, map[string]interface{}{
+				"key": key,
+			})
+			continue
+		}
+
+		s.logger.Warn("Login locked out", map[string]interface{}{
+			"key":          key,
+			"failures":     throttle.Failures,
+			"locked_until": until.Format(time.RFC3339),
+		})
+	}
+}
+
+// Reset clears the failed logins of an account after a successful sign-in or
+// when an admin unlocks it. The IP's count is kept, so one valid account
+// cannot be used to reset it.
+func (s *LoginThrottleService) Reset(ctx context.Context, email string) error {
+	return s.repo.Delete(ctx, accountThrottleKey(email))
+}
+
+func (s *LoginThrottleService) keys(email, ip string) []string {
+	keys := []string{accountThrottleKey(email)}
+	if ip != "" {
+		keys = append(keys, ipThrottleKey+ip)
+	}
+	return keys
+}
+
+func (s *LoginThrottleService) maxFailures(key string) int {
+	if strings.HasPrefix(key, ipThrottleKey) {
+		return s.cfg.MaxIPFailures
+	}
+	return s.cfg.MaxAccountFailures
+}
+
+func (s *LoginThrottleService) isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range s.trustedProxies {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+const ipThrottleKey = "ip:"
+
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// parseTrustedProxies reads proxy addresses and CIDR ranges, skipping any
+// that do not parse
+func parseTrustedProxies(proxies []string, log *logger.Logger) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			log.Warn("Ignoring invalid trusted proxy", map[string]interface{}{
+				"proxy": proxy,
+			})
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/config"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// newMockLoginThrottleService locks an account out after 3 failures and an
+// IP after 5, reading the time from clock
+func newMockLoginThrottleService(clock *time.Time) *LoginThrottleService {
+	cfg := &config.Config{Login: config.LoginThrottleConfig{
+		Store:              "memory",
+		MaxAccountFailures: 3,
+		MaxIPFailures:      5,
+		Window:             15 * time.Minute,
+		LockoutDuration:    15 * time.Minute,
+		BaseDelay:          time.Second,
+		MaxDelay:           4 * time.Second,
+		TrustedProxies:     []string{"10.0.0.0/8"},
+	}}
+
+	throttle := NewLoginThrottleService(database.NewMemoryLoginThrottleRepository(), cfg, logger.New("info", "console"))
+	throttle.now = func() time.Time { return *clock }
+	return throttle
+}
+
+func newMockThrottledAuthService(users *mockUserRepository, throttle *LoginThrottleService) *AuthService {
+	auth := NewAuthService(users, newMockTeamBusinessRepository(), newMockInvitationRepository(), newMockSessionRepository(), testAuthConfig(), logger.New("info", "console"))
+	auth.SetLoginThrottle(throttle)
+	return auth
+}
+
+// throttledLogin attempts a login from ip after waiting out any delay
+func throttledLogin(auth *AuthService, clock *time.Time, email, password, ip string) error {
+	*clock = clock.Add(10 * time.Second)
+	_, err := auth.Login(context.Background(), dto.LoginRequest{
+		Email:    email,
+		Password: password,
+		Client:   dto.ClientInfo{RemoteAddr: ip + ":51234"},
+	})
+	return err
+}
+
+func TestLoginThrottle_Lockout(t *testing.T) {
+	employeeFailures := []string{"employee@example.com", "employee@example.com", "employee@example.com"}
+	guesses := []string{"guess0@example.com", "guess1@example.com", "guess2@example.com", "guess3@example.com", "guess4@example.com"}
+
+	tests := []struct {
+		name       string
+		failures   []string
+		failureIP  string
+		wait       time.Duration
+		email      string
+		password   string
+		ip         string
+		wantLocked bool
+		lockedKey  string
+	}{
+		{
+			name:       "account locked from any IP",
+			failures:   employeeFailures,
+			failureIP:  "192.0.2.1",
+			email:      "employee@example.com",
+			password:   "password123",
+			ip:         "192.0.2.2",
+			wantLocked: true,
+		},
+		{
+			name:      "other accounts unaffected",
+			failures:  employeeFailures,
+			failureIP: "192.0.2.1",
+			email:     "admin@example.com",
+			password:  "password123",
+			ip:        "192.0.2.1",
+		},
+		{
+			name:      "lockout expires",
+			failures:  employeeFailures,
+			failureIP: "192.0.2.1",
+			wait:      15 * time.Minute,
+			email:     "employee@example.com",
+			password:  "password123",
+			ip:        "192.0.2.1",
+		},
+		{
+			name:       "unknown emails count the same",
+			failures:   []string{"nobody@example.com", "nobody@example.com", "nobody@example.com"},
+			failureIP:  "192.0.2.1",
+			email:      "nobody@example.com",
+			password:   "wrong",
+			ip:         "192.0.2.1",
+			wantLocked: true,
+			lockedKey:  accountThrottleKey("nobody@example.com"),
+		},
+		{
+			name:       "IP locked for every account",
+			failures:   guesses,
+			failureIP:  "192.0.2.9",
+			email:      "owner@example.com",
+			password:   "password123",
+			ip:         "192.0.2.9",
+			wantLocked: true,
+		},
+		{
+			name:      "other IPs unaffected",
+			failures:  guesses,
+			failureIP: "192.0.2.9",
+			email:     "owner@example.com",
+			password:  "password123",
+			ip:        "192.0.2.10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+			throttle := newMockLoginThrottleService(&clock)
+			auth := newMockThrottledAuthService(newMockTeamUserRepository(), throttle)
+
+			var failure error
+			for i, email := range tt.failures {
+				if failure = throttledLogin(auth, &clock, email, "wrong", tt.failureIP); errorCode(failure) != domainerrors.ErrCodeUnauthorized {
+					t.Fatalf("attempt %d: expected invalid credentials, got %v", i+1, failure)
+				}
+			}
+			clock = clock.Add(tt.wait)
+
+			err := throttledLogin(auth, &clock, tt.email, tt.password, tt.ip)
+			if !tt.wantLocked {
+				if err != nil {
+					t.Errorf("Login() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != failure.Error() {
+				t.Errorf("expected the locked login to get the generic error, got %v", err)
+			}
+			if tt.lockedKey != "" {
+				locked, _ := throttle.repo.Get(context.Background(), tt.lockedKey)
+				if locked == nil || !locked.IsLocked(clock) {
+					t.Errorf("expected %s to be locked out", tt.lockedKey)
+				}
+			}
+		})
+	}
+}
+
+func TestLoginThrottle_ProgressiveDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		wait    time.Duration
+		wantErr bool
+	}{
+		{
+			name:    "within the delay",
+			wait:    time.Second,
+			wantErr: true,
+		},
+		{
+			name: "after the delay",
+			wait: 2 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			clock := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+			throttle := newMockLoginThrottleService(&clock)
+			auth := newMockThrottledAuthService(newMockTeamUserRepository(), throttle)
+
+			throttle.RecordFailure(ctx, "employee@example.com", "")
+			throttle.RecordFailure(ctx, "employee@example.com", "")
+			clock = clock.Add(tt.wait)
+
+			_, err := auth.Login(ctx, dto.LoginRequest{Email: "employee@example.com", Password: "password123"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Login() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if failures, _ := throttle.repo.Get(ctx, accountThrottleKey("employee@example.com")); failures != nil {
+				t.Error("expected a successful login to reset the account's failures")
+			}
+		})
+	}
+}
+
+func TestUserService_UnlockUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		businessID string
+		actorID    string
+		wantCode   string
+	}{
+		{
+			name:       "admin",
+			businessID: "business-123",
+			actorID:    "user-admin",
+		},
+		{
+			name:       "employee",
+			businessID: "business-123",
+			actorID:    "user-employee",
+			wantCode:   domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:       "other business",
+			businessID: "business-456",
+			actorID:    "user-other",
+			wantCode:   domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+			users := newMockTeamUserRepository()
+			throttle := newMockLoginThrottleService(&clock)
+			auth := newMockThrottledAuthService(users, throttle)
+			service := NewUserService(users, newMockInvitationRepository(), logger.New("info", "console"))
+			service.SetLoginThrottle(throttle)
+
+			for i := 0; i < 3; i++ {
+				throttledLogin(auth, &clock, "employee@example.com", "wrong", "192.0.2.1")
+			}
+
+			err := service.UnlockUser(context.Background(), tt.businessID, tt.actorID, "user-employee")
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("UnlockUser() error = %v, want %s", err, tt.wantCode)
+				}
+				if err := throttledLogin(auth, &clock, "employee@example.com", "password123", "192.0.2.1"); err == nil {
+					t.Error("expected the user to stay locked out")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnlockUser() error = %v", err)
+			}
+			if err := throttledLogin(auth, &clock, "employee@example.com", "password123", "192.0.2.1"); err != nil {
+				t.Errorf("expected the user to log in after being unlocked, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLoginThrottleService_ClientIP(t *testing.T) {
+	clock := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	throttle := newMockLoginThrottleService(&clock)
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		want         string
+	}{
+		{"direct connection", "192.0.2.1:5000", "", "192.0.2.1"},
+		{"untrusted forwarded header", "192.0.2.1:5000", "198.51.100.7", "192.0.2.1"},
+		{"trusted proxy", "10.0.0.5:5000", "198.51.100.7", "198.51.100.7"},
+		{"spoofed hop before the proxy", "10.0.0.5:5000", "203.0.113.1, 198.51.100.7", "198.51.100.7"},
+		{"chain of trusted proxies", "10.0.0.5:5000", "198.51.100.7, 10.0.0.9", "198.51.100.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := throttle.ClientIP(tt.remoteAddr, tt.forwardedFor); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
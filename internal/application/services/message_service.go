@@ -0,0 +1,547 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+	"github.com/CallPilotReceptionist/pkg/phone"
+)
+
+// MessageToolName is the function the assistant calls to leave a message for
+// someone at the business
+const MessageToolName = "take_message"
+
+// MessageService records messages callers leave, routes them to the right
+// recipient's notification channels and tracks their follow-up
+type MessageService struct {
+	messageRepo   database.MessageRepository
+	recipientRepo database.MessageRecipientRepository
+	userRepo      database.UserRepository
+	businessRepo  database.BusinessRepository
+	notifier      providers.Notifier
+	logger        *logger.Logger
+
+	now func() time.Time
+}
+
+func NewMessageService(
+	messageRepo database.MessageRepository,
+	recipientRepo database.MessageRecipientRepository,
+	userRepo database.UserRepository,
+	businessRepo database.BusinessRepository,
+	notifier providers.Notifier,
+	log *logger.Logger,
+) *MessageService {
+	return &MessageService{
+		messageRepo:   messageRepo,
+		recipientRepo: recipientRepo,
+		userRepo:      userRepo,
+		businessRepo:  businessRepo,
+		notifier:      notifier,
+		logger:        log,
+		now:           time.Now,
+	}
+}
+
+func (s *MessageService) Name() string {
+	return MessageToolName
+}
+
+// Definition describes take_message, naming the business's recipients so the
+// assistant can confirm who the message is for
+func (s *MessageService) Definition(ctx context.Context, businessID string) (*providers.Function, error) {
+	recipients, err := s.recipientRepo.GetByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipientHelp := "Who the message is for, as the caller said it"
+	var names []string
+	for _, recipient := range recipients {
+		if recipient.Active {
+			names = append(names, recipient.Name)
+		}
+	}
+	if len(names) > 0 {
+		recipientHelp += ". Staff who take messages: " + strings.Join(names, ", ")
+	}
+
+	return &providers.Function{
+		Name:        MessageToolName,
+		Description: "Take a message for someone at the business when the caller asks to leave one or the person they want is unavailable",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"caller_name": map[string]interface{}{
+					"type":        "string",
+					"description": "The caller's name",
+				},
+				"recipient": map[string]interface{}{
+					"type":        "string",
+					"description": recipientHelp,
+				},
+				"message": map[string]interface{}{
+					"type":        "string",
+					"description": "The message, in the caller's words where possible",
+				},
+				"urgency": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"low", "normal", "urgent"},
+					"description": "How soon the caller needs a reply",
+				},
+				"callback_number": map[string]interface{}{
+					"type":        "string",
+					"description": "Number to call back on, if different from the number they are calling from",
+				},
+			},
+			"required": []string{"message"},
+		},
+	}, nil
+}
+
+// Invoke records the message the assistant took during the call
+func (s *MessageService) Invoke(ctx context.Context, call *entities.Call, args map[string]interface{}) (string, error) {
+	message, recipient, err := s.takeMessage(ctx, call, args, entities.MessageSourceTool)
+	if err != nil {
+		return "", err
+	}
+
+	if recipient != nil {
+		return "The message has been passed on to " + recipient.Name + ".", nil
+	}
+	if message.RecipientName != "" {
+		return "The message for " + message.RecipientName + " has been recorded and will be passed on.", nil
+	}
+	return "The message has been recorded and will be passed on.", nil
+}
+
+// HandleCallAnalysis creates a message from the "message" object in the
+// provider's structured analysis of a finished call. It is skipped when the
+// assistant already took a message during the call.
+func (s *MessageService) HandleCallAnalysis(ctx context.Context, call *entities.Call, analysis map[string]interface{}) error {
+	data, ok := analysis["message"].(map[string]interface{})
+	if !ok || stringArg(data, "message") == "" {
+		return nil
+	}
+
+	taken, err := s.messageRepo.HasCallMessage(ctx, call.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return nil
+	}
+
+	_, _, err = s.takeMessage(ctx, call, data, entities.MessageSourceExtraction)
+	return err
+}
+
+func (s *MessageService) takeMessage(ctx context.Context, call *entities.Call, args map[string]interface{}, source entities.MessageSource) (*entities.Message, *entities.MessageRecipient, error) {
+	callbackPhone := call.CallerPhone
+	if raw := stringArg(args, "callback_number"); raw != "" {
+		normalized, err := normalizePhone("callback_number", raw, s.region(ctx, call.BusinessID))
+		if err != nil {
+			return nil, nil, err
+		}
+		callbackPhone = normalized
+	}
+
+	message, err := entities.NewMessage(call.BusinessID, call.CallerPhone, callbackPhone, stringArg(args, "message"),
+		entities.MessageUrgency(strings.ToLower(stringArg(args, "urgency"))), source)
+	if err != nil {
+		return nil, nil, err
+	}
+	message.CallID = &call.ID
+	message.CallerName = stringArg(args, "caller_name")
+	message.RecipientName = stringArg(args, "recipient")
+
+	recipient, err := s.findRecipient(ctx, call.BusinessID, message.RecipientName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if recipient != nil {
+		message.RouteTo(recipient)
+	}
+
+	if err := s.messageRepo.Create(ctx, message); err != nil {
+		s.logger.Error("Failed to create message", err, map[string]interface{}{
+			"call_id": call.ID,
+		})
+		return nil, nil, err
+	}
+
+	if recipient != nil && s.notifies(ctx, message) {
+		s.notify(ctx, message, recipient)
+	}
+
+	return message, recipient, nil
+}
+
+// findRecipient returns the active recipient the caller asked for, or nil
+// when nobody in the directory matches
+func (s *MessageService) findRecipient(ctx context.Context, businessID, requested string) (*entities.MessageRecipient, error) {
+	if requested == "" {
+		return nil, nil
+	}
+
+	recipients, err := s.recipientRepo.GetByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, recipient := range recipients {
+		if recipient.Active && recipient.Matches(requested) {
+			return recipient, nil
+		}
+	}
+	return nil, nil
+}
+
+// notifies reports whether the business's notification settings call for
+// the message's recipient to be notified
+func (s *MessageService) notifies(ctx context.Context, message *entities.Message) bool {
+	business, err := s.businessRepo.GetByID(ctx, message.BusinessID)
+	if err != nil || business == nil {
+		return true
+	}
+	return business.NotifiesMessage(message.Urgency)
+}
+
+// notify sends the message to each of the recipient's channels. Delivery
+// failures are logged; the message is still available in the API.
+func (s *MessageService) notify(ctx context.Context, message *entities.Message, recipient *entities.MessageRecipient) {
+	from := message.CallerName
+	if from == "" {
+		from = message.CallerPhone
+	}
+	subject := "New message from " + from
+	if message.Urgency == entities.MessageUrgencyUrgent {
+		subject = "Urgent message from " + from
+	}
+	body := fmt.Sprintf("%s\n\nCall back on %s", message.Body, message.CallbackPhone)
+
+	for _, channel := range recipient.Channels {
+		err := s.notifier.Notify(ctx, providers.Notification{
+			Channel: string(channel.Type),
+			Target:  channel.Target,
+			Subject: subject,
+			Body:    body,
+			Metadata: map[string]interface{}{
+				"message_id":   message.ID,
+				"business_id":  message.BusinessID,
+				"recipient_id": recipient.ID,
+				"urgency":      string(message.Urgency),
+			},
+		})
+		if err != nil {
+			s.logger.Error("Failed to notify message recipient", err, map[string]interface{}{
+				"message_id": message.ID,
+				"channel":    string(channel.Type),
+			})
+		}
+	}
+}
+
+func (s *MessageService) ListMessages(ctx context.Context, businessID string, req dto.ListMessagesRequest) ([]*dto.MessageResponse, error) {
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	status := entities.MessageStatus(req.Status)
+	if status != "" && !status.IsValid() {
+		return nil, errors.NewFieldValidationError("status", "status must be open, acknowledged or resolved")
+	}
+
+	messages, err := s.messageRepo.List(ctx, businessID, database.MessageFilter{
+		Status:      status,
+		RecipientID: req.RecipientID,
+		AssigneeID:  req.AssigneeID,
+	}, req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.MessageResponse, 0, len(messages))
+	for _, message := range messages {
+		responses = append(responses, mapMessageToResponse(message))
+	}
+
+	return responses, nil
+}
+
+func (s *MessageService) GetMessage(ctx context.Context, businessID, id string) (*dto.MessageResponse, error) {
+	message, err := s.getOwnedMessage(ctx, businessID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapMessageToResponse(message), nil
+}
+
+func (s *MessageService) AcknowledgeMessage(ctx context.Context, businessID, userID, id string) (*dto.MessageResponse, error) {
+	return s.updateMessage(ctx, businessID, id, func(message *entities.Message) error {
+		return message.Acknowledge(userID)
+	})
+}
+
+func (s *MessageService) ResolveMessage(ctx context.Context, businessID, userID, id string) (*dto.MessageResponse, error) {
+	return s.updateMessage(ctx, businessID, id, func(message *entities.Message) error {
+		return message.Resolve(userID)
+	})
+}
+
+func (s *MessageService) ReopenMessage(ctx context.Context, businessID, id string) (*dto.MessageResponse, error) {
+	return s.updateMessage(ctx, businessID, id, func(message *entities.Message) error {
+		return message.Reopen()
+	})
+}
+
+// AssignMessage hands a message to a user of the business, or unassigns it
+func (s *MessageService) AssignMessage(ctx context.Context, businessID, id string, req dto.AssignMessageRequest) (*dto.MessageResponse, error) {
+	if req.UserID != nil {
+		if err := checkBusinessUser(ctx, s.userRepo, businessID, *req.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.updateMessage(ctx, businessID, id, func(message *entities.Message) error {
+		message.Assign(req.UserID)
+		return nil
+	})
+}
+
+func (s *MessageService) updateMessage(ctx context.Context, businessID, id string, change func(*entities.Message) error) (*dto.MessageResponse, error) {
+	message, err := s.getOwnedMessage(ctx, businessID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := change(message); err != nil {
+		return nil, err
+	}
+
+	if err := s.messageRepo.Update(ctx, message); err != nil {
+		s.logger.Error("Failed to update message", err, map[string]interface{}{
+			"message_id": message.ID,
+		})
+		return nil, err
+	}
+
+	return mapMessageToResponse(message), nil
+}
+
+func (s *MessageService) ListRecipients(ctx context.Context, businessID string) ([]*dto.MessageRecipientResponse, error) {
+	recipients, err := s.recipientRepo.GetByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.MessageRecipientResponse, 0, len(recipients))
+	for _, recipient := range recipients {
+		responses = append(responses, mapMessageRecipientToResponse(recipient))
+	}
+
+	return responses, nil
+}
+
+func (s *MessageService) CreateRecipient(ctx context.Context, businessID string, req dto.MessageRecipientRequest) (*dto.MessageRecipientResponse, error) {
+	channels, err := s.parseChannels(ctx, businessID, req.Channels)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID != nil {
+		if err := checkBusinessUser(ctx, s.userRepo, businessID, *req.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	recipient, err := entities.NewMessageRecipient(businessID, req.Name, req.Aliases, channels, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Active != nil {
+		recipient.Active = *req.Active
+	}
+
+	if err := s.recipientRepo.Create(ctx, recipient); err != nil {
+		s.logger.Error("Failed to create message recipient", err, map[string]interface{}{
+			"business_id": businessID,
+		})
+		return nil, err
+	}
+
+	return mapMessageRecipientToResponse(recipient), nil
+}
+
+// UpdateRecipient replaces a recipient's details
+func (s *MessageService) UpdateRecipient(ctx context.Context, businessID, id string, req dto.MessageRecipientRequest) (*dto.MessageRecipientResponse, error) {
+	recipient, err := s.getOwnedRecipient(ctx, businessID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, err := s.parseChannels(ctx, businessID, req.Channels)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID != nil {
+		if err := checkBusinessUser(ctx, s.userRepo, businessID, *req.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+	if err := recipient.Update(req.Name, req.Aliases, channels, req.UserID, active); err != nil {
+		return nil, err
+	}
+
+	if err := s.recipientRepo.Update(ctx, recipient); err != nil {
+		s.logger.Error("Failed to update message recipient", err, map[string]interface{}{
+			"recipient_id": recipient.ID,
+		})
+		return nil, err
+	}
+
+	return mapMessageRecipientToResponse(recipient), nil
+}
+
+// DeleteRecipient removes a recipient. Messages already routed to it keep
+// their recipient name but lose the link.
+func (s *MessageService) DeleteRecipient(ctx context.Context, businessID, id string) error {
+	if _, err := s.getOwnedRecipient(ctx, businessID, id); err != nil {
+		return err
+	}
+
+	return s.recipientRepo.Delete(ctx, id)
+}
+
+// parseChannels validates channels, normalising SMS numbers to E.164
+func (s *MessageService) parseChannels(ctx context.Context, businessID string, requests []dto.NotificationChannelRequest) ([]entities.NotificationChannel, error) {
+	channels := make([]entities.NotificationChannel, 0, len(requests))
+	for _, req := range requests {
+		channel := entities.NotificationChannel{
+			Type:   entities.NotificationChannelType(req.Type),
+			Target: strings.TrimSpace(req.Target),
+		}
+		if channel.Type == entities.NotificationChannelSMS {
+			normalized, err := normalizePhone("channels", channel.Target, s.region(ctx, businessID))
+			if err != nil {
+				return nil, err
+			}
+			channel.Target = normalized
+		}
+		channels = append(channels, channel)
+	}
+	return channels, nil
+}
+
+// checkBusinessUser rejects a user_id that is not an active member of the
+// business
+func checkBusinessUser(ctx context.Context, userRepo database.UserRepository, businessID, userID string) error {
+	user, err := userRepo.GetMember(ctx, businessID, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive() {
+		return errors.NewFieldValidationError("user_id", "user_id must be an active user of this business")
+	}
+	return nil
+}
+
+func (s *MessageService) region(ctx context.Context, businessID string) string {
+	if business, err := s.businessRepo.GetByID(ctx, businessID); err == nil && business != nil {
+		return business.DefaultRegion()
+	}
+	return phone.DefaultRegion
+}
+
+func (s *MessageService) getOwnedMessage(ctx context.Context, businessID, id string) (*entities.Message, error) {
+	message, err := s.messageRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.BusinessID != businessID {
+		return nil, errors.NewForbiddenError("access denied to this message")
+	}
+
+	return message, nil
+}
+
+func (s *MessageService) getOwnedRecipient(ctx context.Context, businessID, id string) (*entities.MessageRecipient, error) {
+	recipient, err := s.recipientRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if recipient.BusinessID != businessID {
+		return nil, errors.NewForbiddenError("access denied to this message recipient")
+	}
+
+	return recipient, nil
+}
+
+func mapMessageToResponse(message *entities.Message) *dto.MessageResponse {
+	return &dto.MessageResponse{
+		ID:             message.ID,
+		BusinessID:     message.BusinessID,
+		CallID:         message.CallID,
+		CallerName:     message.CallerName,
+		CallerPhone:    message.CallerPhone,
+		CallbackPhone:  message.CallbackPhone,
+		RecipientName:  message.RecipientName,
+		RecipientID:    message.RecipientID,
+		AssigneeID:     message.AssigneeID,
+		Urgency:        string(message.Urgency),
+		Body:           message.Body,
+		Source:         string(message.Source),
+		Status:         string(message.Status),
+		AcknowledgedBy: message.AcknowledgedBy,
+		AcknowledgedAt: formatOptionalTime(message.AcknowledgedAt),
+		ResolvedBy:     message.ResolvedBy,
+		ResolvedAt:     formatOptionalTime(message.ResolvedAt),
+		CreatedAt:      message.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      message.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func mapMessageRecipientToResponse(recipient *entities.MessageRecipient) *dto.MessageRecipientResponse {
+	channels := make([]dto.NotificationChannelRequest, 0, len(recipient.Channels))
+	for _, channel := range recipient.Channels {
+		channels = append(channels, dto.NotificationChannelRequest{
+			Type:   string(channel.Type),
+			Target: channel.Target,
+		})
+	}
+
+	aliases := recipient.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+
+	return &dto.MessageRecipientResponse{
+		ID:         recipient.ID,
+		BusinessID: recipient.BusinessID,
+		UserID:     recipient.UserID,
+		Name:       recipient.Name,
+		Aliases:    aliases,
+		Channels:   channels,
+		Active:     recipient.Active,
+		CreatedAt:  recipient.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  recipient.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,544 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// newMockMessageRecipientRepository gives business-123 Dr. Lee, reached by
+// email and SMS, and an inactive Billing webhook
+func newMockMessageRecipientRepository() *mockMessageRecipientRepository {
+	ctx := context.Background()
+	recipients := &mockMessageRecipientRepository{}
+
+	leeID := "user-lee"
+	lee, _ := entities.NewMessageRecipient("business-123", "Dr. Lee", []string{"Susan Lee"}, []entities.NotificationChannel{
+		{Type: entities.NotificationChannelEmail, Target: "lee@example.com"},
+		{Type: entities.NotificationChannelSMS, Target: "+12125551234"},
+	}, &leeID)
+	recipients.Create(ctx, lee)
+
+	billing, _ := entities.NewMessageRecipient("business-123", "Billing", nil, []entities.NotificationChannel{
+		{Type: entities.NotificationChannelWebhook, Target: "https://hooks.example.com/billing"},
+	}, nil)
+	billing.Active = false
+	recipients.Create(ctx, billing)
+
+	return recipients
+}
+
+// newMockMessageUserRepository returns user-lee and user-front of
+// business-123, and user-other of business-456
+func newMockMessageUserRepository() *mockUserRepository {
+	return &mockUserRepository{users: map[string]*entities.User{
+		"user-lee":   {ID: "user-lee", BusinessID: "business-123", Email: "lee@example.com", Role: entities.UserRoleEmployee},
+		"user-front": {ID: "user-front", BusinessID: "business-123", Email: "front@example.com", Role: entities.UserRoleEmployee},
+		"user-other": {ID: "user-other", BusinessID: "business-456", Email: "other@example.com", Role: entities.UserRoleOwner},
+	}}
+}
+
+func newMockMessageService(messages *mockMessageRepository, recipients *mockMessageRecipientRepository, businesses *mockBusinessRepository, notifier *mockNotifier) *MessageService {
+	return NewMessageService(messages, recipients, newMockMessageUserRepository(), businesses, notifier, logger.New("info", "console"))
+}
+
+// newMockMessageCall returns call-123, an inbound call to business-123
+func newMockMessageCall() *entities.Call {
+	call, _ := entities.NewInboundCall("business-123", "+15551234567", "provider-123")
+	call.ID = "call-123"
+	return call
+}
+
+func TestMessageService_TakeMessage(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          map[string]interface{}
+		wantErr       bool
+		wantRecipient string
+		wantAssignee  string
+		wantNotified  int
+		wantCallback  string
+		wantUrgency   entities.MessageUrgency
+	}{
+		{
+			name: "routed to recipient by name",
+			args: map[string]interface{}{
+				"caller_name": "Jane Doe",
+				"recipient":   "doctor lee",
+				"message":     "Please call me about my test results",
+				"urgency":     "urgent",
+			},
+			wantRecipient: "recipient-1",
+			wantAssignee:  "user-lee",
+			wantNotified:  2,
+			wantCallback:  "+15551234567",
+			wantUrgency:   entities.MessageUrgencyUrgent,
+		},
+		{
+			name: "routed by alias with a different callback number",
+			args: map[string]interface{}{
+				"recipient":       "Susan Lee",
+				"message":         "Running late for my appointment",
+				"callback_number": "(212) 555-0000",
+			},
+			wantRecipient: "recipient-1",
+			wantAssignee:  "user-lee",
+			wantNotified:  2,
+			wantCallback:  "+12125550000",
+			wantUrgency:   entities.MessageUrgencyNormal,
+		},
+		{
+			name:         "inactive recipient is not routed",
+			args:         map[string]interface{}{"recipient": "billing", "message": "Question about my invoice"},
+			wantCallback: "+15551234567",
+			wantUrgency:  entities.MessageUrgencyNormal,
+		},
+		{
+			name:    "empty message",
+			args:    map[string]interface{}{"recipient": "Dr. Lee"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid urgency",
+			args:    map[string]interface{}{"message": "Call me", "urgency": "asap"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := &mockMessageRepository{}
+			notifier := &mockNotifier{}
+			service := newMockMessageService(messages, newMockMessageRecipientRepository(), &mockBusinessRepository{businesses: make(map[string]*entities.Business)}, notifier)
+
+			result, err := service.Invoke(context.Background(), newMockMessageCall(), tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if len(messages.messages) != 0 {
+					t.Error("expected no message to be saved")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Invoke() error = %v", err)
+			}
+			if result == "" {
+				t.Error("expected a reply for the assistant")
+			}
+
+			if len(messages.messages) != 1 {
+				t.Fatalf("expected one message, got %d", len(messages.messages))
+			}
+			message := messages.messages[0]
+			if message.Status != entities.MessageStatusOpen || message.Source != entities.MessageSourceTool {
+				t.Errorf("unexpected status %s source %s", message.Status, message.Source)
+			}
+			if message.CallID == nil || *message.CallID != "call-123" {
+				t.Errorf("expected message linked to the call, got %v", message.CallID)
+			}
+			if message.CallbackPhone != tt.wantCallback || message.Urgency != tt.wantUrgency {
+				t.Errorf("callback %s urgency %s, want %s %s", message.CallbackPhone, message.Urgency, tt.wantCallback, tt.wantUrgency)
+			}
+
+			gotRecipient, gotAssignee := "", ""
+			if message.RecipientID != nil {
+				gotRecipient = *message.RecipientID
+			}
+			if message.AssigneeID != nil {
+				gotAssignee = *message.AssigneeID
+			}
+			if gotRecipient != tt.wantRecipient || gotAssignee != tt.wantAssignee {
+				t.Errorf("recipient %q assignee %q, want %q %q", gotRecipient, gotAssignee, tt.wantRecipient, tt.wantAssignee)
+			}
+			if len(notifier.sent) != tt.wantNotified {
+				t.Errorf("expected %d notifications, got %d", tt.wantNotified, len(notifier.sent))
+			}
+		})
+	}
+}
+
+func TestMessageService_NotificationSettings(t *testing.T) {
+	tests := []struct {
+		name          string
+		notifications entities.NotificationSettings
+		urgency       string
+		wantNotified  int
+	}{
+		{"notifications off", entities.NotificationSettings{Messages: false}, "urgent", 0},
+		{"urgent only, normal message", entities.NotificationSettings{Messages: true, UrgentOnly: true}, "normal", 0},
+		{"urgent only, urgent message", entities.NotificationSettings{Messages: true, UrgentOnly: true}, "urgent", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := &mockMessageRepository{}
+			notifier := &mockNotifier{}
+			businesses := &mockBusinessRepository{businesses: map[string]*entities.Business{
+				"business-123": {
+					ID: "business-123",
+					Settings: testSettings(func(s *entities.BusinessSettings) {
+						s.Notifications = tt.notifications
+					}),
+				},
+			}}
+			service := newMockMessageService(messages, newMockMessageRecipientRepository(), businesses, notifier)
+
+			if _, err := service.Invoke(context.Background(), newMockMessageCall(), map[string]interface{}{
+				"recipient": "Dr. Lee",
+				"message":   "Please call me back",
+				"urgency":   tt.urgency,
+			}); err != nil {
+				t.Fatalf("Invoke() error = %v", err)
+			}
+			if len(messages.messages) != 1 {
+				t.Fatalf("expected the message to be saved, got %d", len(messages.messages))
+			}
+			if len(notifier.sent) != tt.wantNotified {
+				t.Errorf("expected %d notifications, got %d", tt.wantNotified, len(notifier.sent))
+			}
+		})
+	}
+}
+
+func TestMessageService_HandleCallAnalysis(t *testing.T) {
+	analysis := map[string]interface{}{
+		"message": map[string]interface{}{
+			"recipient": "Dr. Lee",
+			"message":   "Wants to move Thursday's appointment",
+		},
+	}
+
+	tests := []struct {
+		name         string
+		analysis     map[string]interface{}
+		taken        bool
+		wantMessages int
+		wantSource   entities.MessageSource
+	}{
+		{
+			name:         "message extracted",
+			analysis:     analysis,
+			wantMessages: 1,
+			wantSource:   entities.MessageSourceExtraction,
+		},
+		{
+			name:     "analysis without a message",
+			analysis: map[string]interface{}{"summary": "no message"},
+		},
+		{
+			name:         "message already taken during the call",
+			analysis:     analysis,
+			taken:        true,
+			wantMessages: 1,
+			wantSource:   entities.MessageSourceTool,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			messages := &mockMessageRepository{}
+			service := newMockMessageService(messages, newMockMessageRecipientRepository(), &mockBusinessRepository{businesses: make(map[string]*entities.Business)}, &mockNotifier{})
+			call := newMockMessageCall()
+			if tt.taken {
+				if _, err := service.Invoke(ctx, call, map[string]interface{}{"recipient": "Dr. Lee", "message": "Call me back"}); err != nil {
+					t.Fatalf("Invoke() error = %v", err)
+				}
+			}
+
+			if err := service.HandleCallAnalysis(ctx, call, tt.analysis); err != nil {
+				t.Fatalf("HandleCallAnalysis() error = %v", err)
+			}
+			if len(messages.messages) != tt.wantMessages {
+				t.Fatalf("expected %d messages, got %d", tt.wantMessages, len(messages.messages))
+			}
+			if tt.wantMessages > 0 && messages.messages[0].Source != tt.wantSource {
+				t.Errorf("source = %s, want %s", messages.messages[0].Source, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestMessageService_Workflow(t *testing.T) {
+	acknowledge := func(ctx context.Context, service *MessageService, id string) (*dto.MessageResponse, error) {
+		return service.AcknowledgeMessage(ctx, "business-123", "user-front", id)
+	}
+	resolve := func(ctx context.Context, service *MessageService, id string) (*dto.MessageResponse, error) {
+		return service.ResolveMessage(ctx, "business-123", "user-front", id)
+	}
+
+	tests := []struct {
+		name     string
+		setup    func(ctx context.Context, service *MessageService, id string) (*dto.MessageResponse, error)
+		act      func(ctx context.Context, service *MessageService, id string) (*dto.MessageResponse, error)
+		wantErr  bool
+		validate func(t *testing.T, message *dto.MessageResponse)
+	}{
+		{
+			name: "acknowledge",
+			act:  acknowledge,
+			validate: func(t *testing.T, message *dto.MessageResponse) {
+				if message.Status != "acknowledged" || message.AcknowledgedAt == nil {
+					t.Errorf("unexpected acknowledged message %+v", message)
+				}
+			},
+		},
+		{
+			name:    "acknowledge a resolved message",
+			setup:   resolve,
+			act:     acknowledge,
+			wantErr: true,
+		},
+		{
+			name: "assign to a team member",
+			act: func(ctx context.Context, service *MessageService, id string) (*dto.MessageResponse, error) {
+				return service.AssignMessage(ctx, "business-123", id, dto.AssignMessageRequest{UserID: strPtr("user-front")})
+			},
+			validate: func(t *testing.T, message *dto.MessageResponse) {
+				if message.AssigneeID == nil || *message.AssigneeID != "user-front" {
+					t.Errorf("expected assignee user-front, got %v", message.AssigneeID)
+				}
+			},
+		},
+		{
+			name: "assign to a user of another business",
+			act: func(ctx context.Context, service *MessageService, id string) (*dto.MessageResponse, error) {
+				return service.AssignMessage(ctx, "business-123", id, dto.AssignMessageRequest{UserID: strPtr("user-other")})
+			},
+			wantErr: true,
+		},
+		{
+			name:  "resolve an acknowledged message",
+			setup: acknowledge,
+			act:   resolve,
+			validate: func(t *testing.T, message *dto.MessageResponse) {
+				if message.Status != "resolved" || message.ResolvedBy == nil {
+					t.Errorf("unexpected resolved message %+v", message)
+				}
+			},
+		},
+		{
+			name: "read by another business",
+			act: func(ctx context.Context, service *MessageService, id string) (*dto.MessageResponse, error) {
+				return service.GetMessage(ctx, "business-456", id)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			messages := &mockMessageRepository{}
+			service := newMockMessageService(messages, newMockMessageRecipientRepository(), &mockBusinessRepository{businesses: make(map[string]*entities.Business)}, &mockNotifier{})
+			if _, err := service.Invoke(ctx, newMockMessageCall(), map[string]interface{}{"recipient": "Dr. Lee", "message": "Call me back"}); err != nil {
+				t.Fatalf("Invoke() error = %v", err)
+			}
+			id := messages.messages[0].ID
+			if tt.setup != nil {
+				if _, err := tt.setup(ctx, service, id); err != nil {
+					t.Fatalf("setup error = %v", err)
+				}
+			}
+
+			message, err := tt.act(ctx, service, id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.validate != nil {
+				tt.validate(t, message)
+			}
+		})
+	}
+}
+
+func TestMessageService_ListMessages(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       dto.ListMessagesRequest
+		wantErr   bool
+		wantCount int
+	}{
+		{
+			name:      "assignee's acknowledged messages",
+			req:       dto.ListMessagesRequest{AssigneeID: "user-front", Status: "acknowledged"},
+			wantCount: 1,
+		},
+		{
+			name: "other assignee",
+			req:  dto.ListMessagesRequest{AssigneeID: "user-lee"},
+		},
+		{
+			name:      "open messages",
+			req:       dto.ListMessagesRequest{Status: "open"},
+			wantCount: 1,
+		},
+		{
+			name:    "unknown status",
+			req:     dto.ListMessagesRequest{Status: "pending"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			messages := &mockMessageRepository{}
+			service := newMockMessageService(messages, newMockMessageRecipientRepository(), &mockBusinessRepository{businesses: make(map[string]*entities.Business)}, &mockNotifier{})
+			for _, text := range []string{"Call me back", "Running late"} {
+				if _, err := service.Invoke(ctx, newMockMessageCall(), map[string]interface{}{"message": text}); err != nil {
+					t.Fatalf("Invoke() error = %v", err)
+				}
+			}
+			first := messages.messages[0].ID
+			service.AcknowledgeMessage(ctx, "business-123", "user-front", first)
+			service.AssignMessage(ctx, "business-123", first, dto.AssignMessageRequest{UserID: strPtr("user-front")})
+
+			listed, err := service.ListMessages(ctx, "business-123", tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListMessages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(listed) != tt.wantCount {
+				t.Errorf("expected %d messages, got %d", tt.wantCount, len(listed))
+			}
+		})
+	}
+}
+
+func TestMessageService_CreateRecipient(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      dto.MessageRecipientRequest
+		wantErr  bool
+		validate func(t *testing.T, created *dto.MessageRecipientResponse)
+	}{
+		{
+			name: "recipient reached by SMS",
+			req: dto.MessageRecipientRequest{
+				Name:    "Front desk",
+				Aliases: []string{"reception", " "},
+				UserID:  strPtr("user-front"),
+				Channels: []dto.NotificationChannelRequest{
+					{Type: "sms", Target: "(212) 555-0000"},
+				},
+			},
+			validate: func(t *testing.T, created *dto.MessageRecipientResponse) {
+				if len(created.Aliases) != 1 || created.Channels[0].Target != "+12125550000" || !created.Active {
+					t.Errorf("unexpected recipient %+v", created)
+				}
+			},
+		},
+		{
+			name: "unknown channel type",
+			req: dto.MessageRecipientRequest{
+				Name:     "Manager",
+				Channels: []dto.NotificationChannelRequest{{Type: "pager", Target: "1234"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recipients := newMockMessageRecipientRepository()
+			service := newMockMessageService(&mockMessageRepository{}, recipients, &mockBusinessRepository{businesses: make(map[string]*entities.Business)}, &mockNotifier{})
+
+			created, err := service.CreateRecipient(context.Background(), "business-123", tt.req)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				if len(recipients.recipients) != 2 {
+					t.Error("expected no recipient to be created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateRecipient() error = %v", err)
+			}
+			tt.validate(t, created)
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// Messages found in the end-of-call analysis are recorded by the call service
+func TestCallService_ExtractsMessageAtEndOfCall(t *testing.T) {
+	tests := []struct {
+		name         string
+		analysis     map[string]interface{}
+		wantMessages int
+	}{
+		{
+			name: "message in the analysis",
+			analysis: map[string]interface{}{
+				"message": map[string]interface{}{"recipient": "Dr. Lee", "message": "Please call back"},
+			},
+			wantMessages: 1,
+		},
+		{
+			name:     "no message in the analysis",
+			analysis: map[string]interface{}{"summary": "Asked about opening hours"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := &mockMessageRepository{}
+			callRepo := newTestCallRepository()
+			call := newMockMessageCall()
+			call.StartedAt = timePtr(time.Now().Add(-time.Minute))
+			callRepo.calls[call.ID] = call
+
+			provider := &testVoiceProvider{
+				handleWebhookFunc: func(ctx context.Context, payload []byte, signature string) (*providers.CallEvent, error) {
+					return &providers.CallEvent{
+						Type:     providers.CallEventTypeEndOfCallReport,
+						CallID:   "provider-123",
+						Outcome:  providers.CallOutcomeCompleted,
+						Analysis: tt.analysis,
+					}, nil
+				},
+				getTranscriptFunc: func(ctx context.Context, callID string) (*providers.Transcript, error) {
+					return &providers.Transcript{}, nil
+				},
+			}
+
+			businesses := &mockBusinessRepository{businesses: make(map[string]*entities.Business)}
+			service := NewCallService(
+				callRepo,
+				newTestTranscriptRepository(),
+				newTestInteractionRepository(),
+				businesses,
+				provider,
+				logger.New("info", "console"),
+			)
+			service.SetMessages(newMockMessageService(messages, newMockMessageRecipientRepository(), businesses, &mockNotifier{}))
+
+			if err := service.HandleWebhook(context.Background(), []byte(`{}`), "valid-signature"); err != nil {
+				t.Fatalf("HandleWebhook() error = %v", err)
+			}
+
+			if len(messages.messages) != tt.wantMessages {
+				t.Fatalf("expected %d messages, got %d", tt.wantMessages, len(messages.messages))
+			}
+			if tt.wantMessages > 0 && messages.messages[0].RecipientID == nil {
+				t.Error("expected the message to be routed")
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,365 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/config"
+	"github.com/CallPilotReceptionist/pkg/logger"
+	"github.com/CallPilotReceptionist/pkg/totp"
+)
+
+const (
+	// recoveryCodeCount is how many recovery codes a user gets at a time
+	recoveryCodeCount = 10
+	// totpSkew is how many periods either side of now a code is accepted for
+	totpSkew = 1
+)
+
+// MFAService enrols users in TOTP two-factor authentication and checks their
+// codes at sign-in
+type MFAService struct {
+	userRepo     database.UserRepository
+	businessRepo database.BusinessRepository
+	mfaRepo      database.MFARepository
+	issuer       string
+	secrets      *secretBox
+	logger       *logger.Logger
+
+	audit *AuditService
+
+	now func() time.Time
+}
+
+func NewMFAService(
+	userRepo database.UserRepository,
+	businessRepo database.BusinessRepository,
+	mfaRepo database.MFARepository,
+	cfg *config.Config,
+	log *logger.Logger,
+) *MFAService {
+	return &MFAService{
+		userRepo:     userRepo,
+		businessRepo: businessRepo,
+		mfaRepo:      mfaRepo,
+		issuer:       cfg.MFA.Issuer,
+		secrets:      newSecretBox(cfg),
+		logger:       log,
+		now:          time.Now,
+	}
+}
+
+// SetAudit records users turning two-factor authentication on and off in the
+// audit log
+func (s *MFAService) SetAudit(audit *AuditService) {
+	s.audit = audit
+}
+
+func (s *MFAService) GetStatus(ctx context.Context, userID string) (*dto.MFAStatusResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mfa, err := s.mfaRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.MFAStatusResponse{Enabled: mfa.IsEnabled()}
+	if response.Required, err = s.isRequired(ctx, user); err != nil {
+		return nil, err
+	}
+	if response.Enabled {
+		if response.RecoveryCodesRemaining, err = s.mfaRepo.CountRecoveryCodes(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
+}
+
+// Setup starts enrolment with a new secret. It only takes effect once
+// confirmed with Enable.
+func (s *MFAService) Setup(ctx context.Context, userID string) (*dto.MFASetupResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.mfaRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing.IsEnabled() {
+		return nil, errors.NewValidationError("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	encrypted, err := s.secrets.encrypt(secret)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	mfa, err := entities.NewUserMFA(user.ID, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Save(ctx, mfa); err != nil {
+		return nil, err
+	}
+
+	return &dto.MFASetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable confirms enrolment with a code from the authenticator app and
+// returns the recovery codes, which are only shown here
+func (s *MFAService) Enable(ctx context.Context, userID string, req dto.MFACodeRequest) (*dto.RecoveryCodesResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mfa, err := s.mfaRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, errors.NewValidationError("start two-factor setup first")
+	}
+	if mfa.IsEnabled() {
+		return nil, errors.NewValidationError("two-factor authentication is already enabled")
+	}
+
+	if err := s.checkTOTP(mfa, req.Code); err != nil {
+		return nil, err
+	}
+	if err := mfa.Enable(s.now()); err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Save(ctx, mfa); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Two-factor authentication enabled", map[string]interface{}{
+		"user_id": user.ID,
+	})
+	s.recordChange(ctx, user, entities.AuditActionMFAEnabled)
+
+	return s.replaceRecoveryCodes(ctx, user.ID)
+}
+
+// Disable turns two-factor authentication off after checking a code. Owners
+// and admins cannot turn it off while their business requires it.
+func (s *MFAService) Disable(ctx context.Context, userID string, req dto.MFACodeRequest) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	required, err := s.isRequired(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return errors.NewForbiddenError("your business requires two-factor authentication")
+	}
+
+	if err := s.VerifyCode(ctx, user.ID, req.Code); err != nil {
+		return err
+	}
+	if err := s.mfaRepo.Delete(ctx, user.ID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Two-factor authentication disabled", map[string]interface{}{
+		"user_id": user.ID,
+	})
+	s.recordChange(ctx, user, entities.AuditActionMFADisabled)
+
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a code
+// from the authenticator app
+func (s *MFAService) RegenerateRecoveryCodes(ctx context.Context, userID string, req dto.MFACodeRequest) (*dto.RecoveryCodesResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	mfa, err := s.mfaRepo.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !mfa.IsEnabled() {
+		return nil, errors.NewValidationError("two-factor authentication is not enabled")
+	}
+
+	if err := s.checkTOTP(mfa, req.Code); err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.Save(ctx, mfa); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(ctx, user.ID)
+}
+
+// IsEnabled reports whether sign-in for the user needs a second factor
+func (s *MFAService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	mfa, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return mfa.IsEnabled(), nil
+}
+
+// SetupRequired reports whether the user must set up two-factor
+// authentication before they get access
+func (s *MFAService) SetupRequired(ctx context.Context, user *entities.User) (bool, error) {
+	required, err := s.isRequired(ctx, user)
+	if err != nil || !required {
+		return false, err
+	}
+
+	enabled, err := s.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	return !enabled, nil
+}
+
+// VerifyCode accepts a code from the authenticator app or an unused recovery
+// code. Each code works once.
+func (s *MFAService) VerifyCode(ctx context.Context, userID, code string) error {
+	mfa, err := s.mfaRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !mfa.IsEnabled() {
+		return errors.NewValidationError("two-factor authentication is not enabled")
+	}
+
+	if err := s.checkTOTP(mfa, code); err == nil {
+		return s.mfaRepo.Save(ctx, mfa)
+	}
+
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, userID, hashToken(normalizeRecoveryCode(code)), s.now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errors.NewUnauthorizedError("invalid code")
+	}
+
+	s.logger.Warn("Recovery code used", map[string]interface{}{
+		"user_id": userID,
+	})
+
+	return nil
+}
+
+// checkTOTP checks an authenticator code and records its step so it cannot
+// be replayed
+func (s *MFAService) checkTOTP(mfa *entities.UserMFA, code string) error {
+	secret, err := s.secrets.decrypt(mfa.EncryptedSecret)
+	if err != nil {
+		return errors.NewInternalError(err)
+	}
+
+	step, ok := totp.Validate(secret, code, s.now(), totpSkew)
+	if !ok {
+		return errors.NewUnauthorizedError("invalid code")
+	}
+	return mfa.UseStep(step)
+}
+
+// isRequired reports whether the user's business requires them to use
+// two-factor authentication
+func (s *MFAService) isRequired(ctx context.Context, user *entities.User) (bool, error) {
+	if !user.IsAdmin() {
+		return false, nil
+	}
+
+	business, err := s.businessRepo.GetByID(ctx, user.BusinessID)
+	if err != nil {
+		return false, err
+	}
+	return business != nil && business.RequiresAdminMFA(), nil
+}
+
+func (s *MFAService) replaceRecoveryCodes(ctx context.Context, userID string) (*dto.RecoveryCodesResponse, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]*entities.RecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, errors.NewInternalError(err)
+		}
+		codes = append(codes, code)
+		records = append(records, entities.NewRecoveryCode(userID, hashToken(normalizeRecoveryCode(code))))
+	}
+
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, records); err != nil {
+		return nil, err
+	}
+
+	return &dto.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// getUser loads the signed-in user. Requests made with an API key have no
+// user to enrol.
+func (s *MFAService) getUser(ctx context.Context, userID string) (*entities.User, error) {
+	if userID == "" {
+		return nil, errors.NewForbiddenError("two-factor authentication is only for users")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.NewNotFoundError("user", userID)
+	}
+	return user, nil
+}
+
+// newRecoveryCode returns a code like "k3j9x-2mf7q"
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode lets recovery codes be typed without the dash or in
+// upper case
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func (s *MFAService) recordChange(ctx context.Context, user *entities.User, action string) {
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: user.BusinessID,
+		Action:     action,
+		TargetType: "user",
+		TargetID:   user.ID,
+	})
+}
@@ -0,0 +1,384 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/pkg/logger"
+	"github.com/CallPilotReceptionist/pkg/totp"
+)
+
+// newMockMFAService returns an MFA service for the team whose clock moves on
+// a period each time mfaCode is called
+func newMockMFAService(users *mockUserRepository, businesses *mockBusinessRepository, mfaRepo *mockMFARepository) *MFAService {
+	service := NewMFAService(users, businesses, mfaRepo, testAuthConfig(), logger.New("info", "console"))
+	clock := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return clock }
+	return service
+}
+
+// newMockMFAAuthService signs the team in, challenging users enrolled with
+// mfa for a code
+func newMockMFAAuthService(users *mockUserRepository, businesses *mockBusinessRepository, mfa *MFAService) *AuthService {
+	auth := NewAuthService(users, businesses, newMockInvitationRepository(), newMockSessionRepository(), testAuthConfig(), logger.New("info", "console"))
+	auth.SetMFA(mfa)
+	return auth
+}
+
+// mfaCode returns the current authenticator code for secret and moves the
+// service's clock on a period so the next call gets a fresh one
+func mfaCode(t *testing.T, service *MFAService, secret string) string {
+	t.Helper()
+	now := service.now()
+	code, err := totp.Code(secret, now)
+	if err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+	next := now.Add(totp.Period)
+	service.now = func() time.Time { return next }
+	return code
+}
+
+// mfaEnrol sets up and enables two-factor authentication for userID and
+// returns the secret and recovery codes
+func mfaEnrol(t *testing.T, service *MFAService, userID string) (string, []string) {
+	t.Helper()
+	setup, err := service.Setup(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	codes, err := service.Enable(context.Background(), userID, dto.MFACodeRequest{Code: mfaCode(t, service, setup.Secret)})
+	if err != nil {
+		t.Fatalf("Enable() error = %v", err)
+	}
+	return setup.Secret, codes.RecoveryCodes
+}
+
+// mfaChallenge signs the employee in with their password and returns the
+// challenge token for the second step
+func mfaChallenge(t *testing.T, auth *AuthService) string {
+	t.Helper()
+	response, err := auth.Login(context.Background(), dto.LoginRequest{Email: "employee@example.com", Password: "password123"})
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if !response.MFARequired || response.MFAToken == "" || response.AccessToken != "" {
+		t.Fatalf("expected an MFA challenge instead of tokens, got %+v", response)
+	}
+	if _, err := auth.ValidateToken(response.MFAToken); err == nil {
+		t.Fatal("expected the challenge token not to work as an access token")
+	}
+	return response.MFAToken
+}
+
+func TestMFAService_Setup(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		setupMocks func(t *testing.T, service *MFAService)
+		wantCode   string
+		validate   func(t *testing.T, service *MFAService, mfaRepo *mockMFARepository, setup *dto.MFASetupResponse)
+	}{
+		{
+			name:   "new enrolment",
+			userID: "user-employee",
+			validate: func(t *testing.T, service *MFAService, mfaRepo *mockMFARepository, setup *dto.MFASetupResponse) {
+				if setup.ProvisioningURI == "" || setup.Secret == "" {
+					t.Fatalf("expected a secret and provisioning URI, got %+v", setup)
+				}
+				if mfaRepo.enrolments["user-employee"].EncryptedSecret == setup.Secret {
+					t.Error("expected the secret to be stored encrypted")
+				}
+				if status, _ := service.GetStatus(context.Background(), "user-employee"); status.Enabled {
+					t.Error("expected enrolment to stay pending until confirmed")
+				}
+			},
+		},
+		{
+			name:   "already enabled",
+			userID: "user-employee",
+			setupMocks: func(t *testing.T, service *MFAService) {
+				mfaEnrol(t, service, "user-employee")
+			},
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:     "API key",
+			userID:   "",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockTeamUserRepository()
+			mfaRepo := newMockMFARepository()
+			service := newMockMFAService(users, newMockTeamBusinessRepository(), mfaRepo)
+			if tt.setupMocks != nil {
+				tt.setupMocks(t, service)
+			}
+
+			setup, err := service.Setup(context.Background(), tt.userID)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("Setup() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Setup() error = %v", err)
+			}
+			if tt.validate != nil {
+				tt.validate(t, service, mfaRepo, setup)
+			}
+		})
+	}
+}
+
+func TestMFAService_Enable(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     func(t *testing.T, service *MFAService, secret string) string
+		wantCode string
+	}{
+		{
+			name: "code from the authenticator",
+			code: mfaCode,
+		},
+		{
+			name:     "wrong code",
+			code:     func(t *testing.T, service *MFAService, secret string) string { return "000000" },
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			mfaRepo := newMockMFARepository()
+			service := newMockMFAService(newMockTeamUserRepository(), newMockTeamBusinessRepository(), mfaRepo)
+			setup, err := service.Setup(ctx, "user-employee")
+			if err != nil {
+				t.Fatalf("Setup() error = %v", err)
+			}
+
+			codes, err := service.Enable(ctx, "user-employee", dto.MFACodeRequest{Code: tt.code(t, service, setup.Secret)})
+			status, _ := service.GetStatus(ctx, "user-employee")
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("Enable() error = %v, want %s", err, tt.wantCode)
+				}
+				if status.Enabled {
+					t.Error("expected enrolment to stay pending")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Enable() error = %v", err)
+			}
+
+			if len(codes.RecoveryCodes) != recoveryCodeCount {
+				t.Errorf("expected %d recovery codes, got %d", recoveryCodeCount, len(codes.RecoveryCodes))
+			}
+			for _, code := range mfaRepo.codes["user-employee"] {
+				if code.CodeHash == codes.RecoveryCodes[0] {
+					t.Error("expected recovery codes to be stored hashed")
+				}
+			}
+			if !status.Enabled || status.RecoveryCodesRemaining != recoveryCodeCount {
+				t.Errorf("unexpected status %+v", status)
+			}
+		})
+	}
+}
+
+func TestAuthService_MFALogin(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// request completes any earlier logins and returns the one to test
+		request  func(t *testing.T, auth *AuthService, mfa *MFAService, mfaRepo *mockMFARepository, secret string, recoveryCodes []string) dto.MFALoginRequest
+		wantCode string
+	}{
+		{
+			name: "code from the authenticator",
+			request: func(t *testing.T, auth *AuthService, mfa *MFAService, mfaRepo *mockMFARepository, secret string, recoveryCodes []string) dto.MFALoginRequest {
+				return dto.MFALoginRequest{MFAToken: mfaChallenge(t, auth), Code: mfaCode(t, mfa, secret)}
+			},
+		},
+		{
+			name: "replayed code",
+			request: func(t *testing.T, auth *AuthService, mfa *MFAService, mfaRepo *mockMFARepository, secret string, recoveryCodes []string) dto.MFALoginRequest {
+				code := mfaCode(t, mfa, secret)
+				if _, err := auth.CompleteMFALogin(ctx, dto.MFALoginRequest{MFAToken: mfaChallenge(t, auth), Code: code}); err != nil {
+					t.Fatalf("CompleteMFALogin() error = %v", err)
+				}
+				return dto.MFALoginRequest{MFAToken: mfaChallenge(t, auth), Code: code}
+			},
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			// The other login checks the same code after this one has read
+			// the enrolment and saves first
+			name: "code used by a concurrent login",
+			request: func(t *testing.T, auth *AuthService, mfa *MFAService, mfaRepo *mockMFARepository, secret string, recoveryCodes []string) dto.MFALoginRequest {
+				first, second := mfaChallenge(t, auth), mfaChallenge(t, auth)
+				code := mfaCode(t, mfa, secret)
+				mfaRepo.beforeSave = func() {
+					if _, err := auth.CompleteMFALogin(ctx, dto.MFALoginRequest{MFAToken: second, Code: code}); err != nil {
+						t.Errorf("expected the other login to succeed, got %v", err)
+					}
+				}
+				return dto.MFALoginRequest{MFAToken: first, Code: code}
+			},
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name: "recovery code",
+			request: func(t *testing.T, auth *AuthService, mfa *MFAService, mfaRepo *mockMFARepository, secret string, recoveryCodes []string) dto.MFALoginRequest {
+				return dto.MFALoginRequest{MFAToken: mfaChallenge(t, auth), Code: recoveryCodes[0]}
+			},
+		},
+		{
+			name: "used recovery code",
+			request: func(t *testing.T, auth *AuthService, mfa *MFAService, mfaRepo *mockMFARepository, secret string, recoveryCodes []string) dto.MFALoginRequest {
+				if _, err := auth.CompleteMFALogin(ctx, dto.MFALoginRequest{MFAToken: mfaChallenge(t, auth), Code: recoveryCodes[0]}); err != nil {
+					t.Fatalf("CompleteMFALogin() error = %v", err)
+				}
+				return dto.MFALoginRequest{MFAToken: mfaChallenge(t, auth), Code: recoveryCodes[0]}
+			},
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name: "access token as the challenge token",
+			request: func(t *testing.T, auth *AuthService, mfa *MFAService, mfaRepo *mockMFARepository, secret string, recoveryCodes []string) dto.MFALoginRequest {
+				response, err := auth.CompleteMFALogin(ctx, dto.MFALoginRequest{MFAToken: mfaChallenge(t, auth), Code: mfaCode(t, mfa, secret)})
+				if err != nil {
+					t.Fatalf("CompleteMFALogin() error = %v", err)
+				}
+				return dto.MFALoginRequest{MFAToken: response.AccessToken, Code: mfaCode(t, mfa, secret)}
+			},
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockTeamUserRepository()
+			businesses := newMockTeamBusinessRepository()
+			mfaRepo := newMockMFARepository()
+			mfa := newMockMFAService(users, businesses, mfaRepo)
+			auth := newMockMFAAuthService(users, businesses, mfa)
+			secret, recoveryCodes := mfaEnrol(t, mfa, "user-employee")
+
+			response, err := auth.CompleteMFALogin(ctx, tt.request(t, auth, mfa, mfaRepo, secret, recoveryCodes))
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("CompleteMFALogin() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CompleteMFALogin() error = %v", err)
+			}
+			if response.AccessToken == "" || response.RefreshToken == "" {
+				t.Errorf("expected tokens, got %+v", response)
+			}
+		})
+	}
+}
+
+func TestMFAService_RequireAdminMFA(t *testing.T) {
+	tests := []struct {
+		name              string
+		email             string
+		wantSetupRequired bool
+	}{
+		{
+			name:              "admin without two-factor authentication",
+			email:             "admin@example.com",
+			wantSetupRequired: true,
+		},
+		{
+			name:  "employee",
+			email: "employee@example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockTeamUserRepository()
+			businesses := newMockTeamBusinessRepository()
+			businesses.businesses["business-123"].Settings.RequireAdminMFA = true
+			auth := newMockMFAAuthService(users, businesses, newMockMFAService(users, businesses, newMockMFARepository()))
+
+			response, err := auth.Login(context.Background(), dto.LoginRequest{Email: tt.email, Password: "password123"})
+			if err != nil {
+				t.Fatalf("Login() error = %v", err)
+			}
+			if response.MFASetupRequired != tt.wantSetupRequired {
+				t.Errorf("MFASetupRequired = %v, want %v", response.MFASetupRequired, tt.wantSetupRequired)
+			}
+			claims, err := auth.ValidateToken(response.AccessToken)
+			if err != nil || claims.MFASetupRequired != tt.wantSetupRequired {
+				t.Errorf("expected the access token to be restricted to setup only when required, got %+v, %v", claims, err)
+			}
+		})
+	}
+}
+
+func TestMFAService_Disable(t *testing.T) {
+	tests := []struct {
+		name            string
+		userID          string
+		requireAdminMFA bool
+		wantCode        string
+	}{
+		{
+			name:            "employee",
+			userID:          "user-employee",
+			requireAdminMFA: true,
+		},
+		{
+			name:   "admin",
+			userID: "user-admin",
+		},
+		{
+			name:            "admin when the business requires it",
+			userID:          "user-admin",
+			requireAdminMFA: true,
+			wantCode:        domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			businesses := newMockTeamBusinessRepository()
+			businesses.businesses["business-123"].Settings.RequireAdminMFA = tt.requireAdminMFA
+			mfaRepo := newMockMFARepository()
+			service := newMockMFAService(newMockTeamUserRepository(), businesses, mfaRepo)
+			secret, _ := mfaEnrol(t, service, tt.userID)
+
+			err := service.Disable(context.Background(), tt.userID, dto.MFACodeRequest{Code: mfaCode(t, service, secret)})
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("Disable() error = %v, want %s", err, tt.wantCode)
+				}
+				if mfaRepo.enrolments[tt.userID] == nil {
+					t.Error("expected the enrolment to be kept")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Disable() error = %v", err)
+			}
+			if mfaRepo.enrolments[tt.userID] != nil || len(mfaRepo.codes[tt.userID]) != 0 {
+				t.Error("expected the enrolment and recovery codes to be removed")
+			}
+		})
+	}
+}
@@ -3,10 +3,19 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
 	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
 )
 
 // ========== Mock VoiceProvider ==========
@@ -217,3 +226,1027 @@ func (m *mockAppointmentRepository) ListByBusinessID(ctx context.Context, busine
 	}
 	return []*entities.AppointmentRequest{}, nil
 }
+
+// ========== Mock TenantScope ==========
+
+// mockTenantScope runs work directly, remembering which kind of
+// transaction is open while it runs
+type mockTenantScope struct {
+	current string
+}
+
+func (m *mockTenantScope) InTenant(ctx context.Context, businessID string, fn func(ctx context.Context) error) error {
+	return m.run(ctx, "tenant", fn)
+}
+
+func (m *mockTenantScope) Detached(ctx context.Context, businessID string, fn func(ctx context.Context) error) error {
+	return m.run(ctx, "detached", fn)
+}
+
+func (m *mockTenantScope) run(ctx context.Context, kind string, fn func(ctx context.Context) error) error {
+	previous := m.current
+	m.current = kind
+	defer func() { m.current = previous }()
+	return fn(ctx)
+}
+
+// ========== Team ==========
+
+// newMockTeamUserRepository returns business-123 with an owner, an admin and
+// an employee, and an owner of business-456, all with the password
+// password123
+func newMockTeamUserRepository() *mockUserRepository {
+	hash, _ := database.HashPassword("password123")
+	return &mockUserRepository{users: map[string]*entities.User{
+		"user-owner":    {ID: "user-owner", BusinessID: "business-123", Email: "owner@example.com", PasswordHash: hash, Role: entities.UserRoleOwner},
+		"user-admin":    {ID: "user-admin", BusinessID: "business-123", Email: "admin@example.com", PasswordHash: hash, Role: entities.UserRoleAdmin},
+		"user-employee": {ID: "user-employee", BusinessID: "business-123", Email: "employee@example.com", PasswordHash: hash, Role: entities.UserRoleEmployee},
+		"user-other":    {ID: "user-other", BusinessID: "business-456", Email: "other@example.com", PasswordHash: hash, Role: entities.UserRoleOwner},
+	}}
+}
+
+// newMockTeamBusinessRepository returns the businesses of the team's users
+func newMockTeamBusinessRepository() *mockBusinessRepository {
+	return &mockBusinessRepository{businesses: map[string]*entities.Business{
+		"business-123": {ID: "business-123", Name: "Test Business", Settings: entities.DefaultBusinessSettings()},
+		"business-456": {ID: "business-456", Name: "Other Business"},
+	}}
+}
+
+// ========== Mock InvitationRepository ==========
+
+type mockInvitationRepository struct {
+	invitations  map[string]*entities.UserInvitation
+	beforeUpdate func()
+}
+
+func newMockInvitationRepository() *mockInvitationRepository {
+	return &mockInvitationRepository{invitations: make(map[string]*entities.UserInvitation)}
+}
+
+func (m *mockInvitationRepository) Create(ctx context.Context, invitation *entities.UserInvitation) error {
+	invitation.ID = "invitation-" + invitation.Email
+	copied := *invitation
+	m.invitations[invitation.ID] = &copied
+	return nil
+}
+
+func (m *mockInvitationRepository) GetByID(ctx context.Context, id string) (*entities.UserInvitation, error) {
+	if invitation, ok := m.invitations[id]; ok {
+		copied := *invitation
+		return &copied, nil
+	}
+	return nil, domainerrors.NewNotFoundError("invitation", id)
+}
+
+func (m *mockInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserInvitation, error) {
+	for _, invitation := range m.invitations {
+		if invitation.TokenHash == tokenHash {
+			copied := *invitation
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockInvitationRepository) GetPending(ctx context.Context, businessID string, now time.Time) ([]*entities.UserInvitation, error) {
+	var result []*entities.UserInvitation
+	for _, invitation := range m.invitations {
+		if invitation.BusinessID == businessID && invitation.IsPending(now) {
+			result = append(result, invitation)
+		}
+	}
+	return result, nil
+}
+
+// Update applies only while the stored invitation is pending, like the
+// database's. beforeUpdate runs first, to change it concurrently.
+func (m *mockInvitationRepository) Update(ctx context.Context, invitation *entities.UserInvitation) error {
+	if m.beforeUpdate != nil {
+		m.beforeUpdate()
+	}
+	stored, ok := m.invitations[invitation.ID]
+	if !ok || stored.AcceptedAt != nil || stored.RevokedAt != nil {
+		return domainerrors.NewValidationError("invitation is no longer valid")
+	}
+	copied := *invitation
+	m.invitations[invitation.ID] = &copied
+	return nil
+}
+
+// ========== Mock SSORepository ==========
+
+type mockSSORepository struct {
+	connections map[string]*entities.SSOConnection
+	states      map[string]*entities.SSOLoginState
+	identities  map[string]*entities.SSOIdentity
+}
+
+func newMockSSORepository() *mockSSORepository {
+	return &mockSSORepository{
+		connections: make(map[string]*entities.SSOConnection),
+		states:      make(map[string]*entities.SSOLoginState),
+		identities:  make(map[string]*entities.SSOIdentity),
+	}
+}
+
+func (m *mockSSORepository) GetConnection(ctx context.Context, businessID string) (*entities.SSOConnection, error) {
+	return m.connections[businessID], nil
+}
+
+func (m *mockSSORepository) SaveConnection(ctx context.Context, connection *entities.SSOConnection) error {
+	m.connections[connection.BusinessID] = connection
+	return nil
+}
+
+func (m *mockSSORepository) DeleteConnection(ctx context.Context, businessID string) error {
+	if _, ok := m.connections[businessID]; !ok {
+		return domainerrors.NewNotFoundError("SSO connection", businessID)
+	}
+	delete(m.connections, businessID)
+	return nil
+}
+
+func (m *mockSSORepository) CreateLoginState(ctx context.Context, state *entities.SSOLoginState) error {
+	m.states[state.StateHash] = state
+	return nil
+}
+
+func (m *mockSSORepository) ConsumeLoginState(ctx context.Context, stateHash string) (*entities.SSOLoginState, error) {
+	state := m.states[stateHash]
+	delete(m.states, stateHash)
+	return state, nil
+}
+
+func (m *mockSSORepository) GetIdentity(ctx context.Context, businessID, issuer, subject string) (*entities.SSOIdentity, error) {
+	return m.identities[businessID+" "+issuer+" "+subject], nil
+}
+
+func (m *mockSSORepository) CreateIdentity(ctx context.Context, identity *entities.SSOIdentity) error {
+	key := identity.BusinessID + " " + identity.Issuer + " " + identity.Subject
+	if _, ok := m.identities[key]; ok {
+		return domainerrors.NewAlreadyExistsError("SSO identity", "subject", identity.Subject)
+	}
+	m.identities[key] = identity
+	return nil
+}
+
+// ========== Mock MFARepository ==========
+
+type mockMFARepository struct {
+	enrolments map[string]*entities.UserMFA
+	codes      map[string][]*entities.RecoveryCode
+	// beforeSave runs before each save, to use a code concurrently
+	beforeSave func()
+}
+
+func newMockMFARepository() *mockMFARepository {
+	return &mockMFARepository{
+		enrolments: make(map[string]*entities.UserMFA),
+		codes:      make(map[string][]*entities.RecoveryCode),
+	}
+}
+
+func (m *mockMFARepository) GetByUserID(ctx context.Context, userID string) (*entities.UserMFA, error) {
+	if mfa, ok := m.enrolments[userID]; ok {
+		stored := *mfa
+		return &stored, nil
+	}
+	return nil, nil
+}
+
+// Save only replaces an enrolment with a later step, or a new setup of one
+// that is not enabled, like the database's
+func (m *mockMFARepository) Save(ctx context.Context, mfa *entities.UserMFA) error {
+	if beforeSave := m.beforeSave; beforeSave != nil {
+		m.beforeSave = nil
+		beforeSave()
+	}
+	if existing, ok := m.enrolments[mfa.UserID]; ok && existing.LastUsedStep >= mfa.LastUsedStep &&
+		(mfa.LastUsedStep != 0 || existing.IsEnabled()) {
+		if mfa.LastUsedStep == 0 {
+			return domainerrors.NewValidationError("two-factor authentication is already enabled")
+		}
+		return domainerrors.NewUnauthorizedError("code has already been used")
+	}
+	stored := *mfa
+	m.enrolments[mfa.UserID] = &stored
+	return nil
+}
+
+func (m *mockMFARepository) Delete(ctx context.Context, userID string) error {
+	delete(m.enrolments, userID)
+	delete(m.codes, userID)
+	return nil
+}
+
+func (m *mockMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []*entities.RecoveryCode) error {
+	m.codes[userID] = codes
+	return nil
+}
+
+func (m *mockMFARepository) UseRecoveryCode(ctx context.Context, userID, codeHash string, now time.Time) (bool, error) {
+	for _, code := range m.codes[userID] {
+		if code.CodeHash == codeHash && code.UsedAt == nil {
+			code.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockMFARepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	count := 0
+	for _, code := range m.codes[userID] {
+		if code.UsedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ========== Mock AuditRepository ==========
+
+type mockAuditRepository struct {
+	entries []*entities.AuditEntry
+}
+
+func (m *mockAuditRepository) Create(ctx context.Context, entry *entities.AuditEntry) error {
+	entry.ID = "audit-" + time.Now().Format("20060102150405.000000000")
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *mockAuditRepository) List(ctx context.Context, businessID string, filter database.AuditFilter, limit, offset int) ([]*entities.AuditEntry, error) {
+	var matched []*entities.AuditEntry
+	for _, entry := range m.entries {
+		if (businessID != "" && entry.BusinessID != businessID) ||
+			(filter.ActorID != "" && entry.ActorID != filter.ActorID) ||
+			(filter.Action != "" && entry.Action != filter.Action) ||
+			(filter.TargetType != "" && entry.TargetType != filter.TargetType) ||
+			(filter.TargetID != "" && entry.TargetID != filter.TargetID) ||
+			(filter.From != nil && entry.CreatedAt.Before(*filter.From)) ||
+			(filter.To != nil && !entry.CreatedAt.Before(*filter.To)) {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	if offset >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[offset:]
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+func (m *mockAuditRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	kept := m.entries[:0]
+	for _, entry := range m.entries {
+		if !entry.CreatedAt.Before(before) {
+			kept = append(kept, entry)
+		}
+	}
+	deleted := len(m.entries) - len(kept)
+	m.entries = kept
+	return deleted, nil
+}
+
+// ========== Mock WebhookEventRepository ==========
+
+type mockWebhookEventRepository struct {
+	events []*entities.WebhookEvent
+}
+
+func (m *mockWebhookEventRepository) Create(ctx context.Context, event *entities.WebhookEvent) error {
+	event.ID = fmt.Sprintf("webhook-%d", len(m.events)+1)
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockWebhookEventRepository) GetByID(ctx context.Context, id string) (*entities.WebhookEvent, error) {
+	for _, event := range m.events {
+		if event.ID == id {
+			return event, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockWebhookEventRepository) List(ctx context.Context, filter database.WebhookEventFilter, limit, offset int) ([]*entities.WebhookEvent, error) {
+	var matched []*entities.WebhookEvent
+	for _, event := range m.events {
+		if (filter.ProviderCallID != "" && event.ProviderCallID != filter.ProviderCallID) ||
+			(filter.EventType != "" && event.EventType != filter.EventType) ||
+			(filter.Status != "" && string(event.Status) != filter.Status) {
+			continue
+		}
+		matched = append(matched, event)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].ReceivedAt.After(matched[j].ReceivedAt)
+	})
+
+	if offset >= len(matched) {
+		return nil, nil
+	}
+	matched = matched[offset:]
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+func (m *mockWebhookEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	kept := m.events[:0]
+	for _, event := range m.events {
+		if !event.ReceivedAt.Before(before) {
+			kept = append(kept, event)
+		}
+	}
+	deleted := len(m.events) - len(kept)
+	m.events = kept
+	return deleted, nil
+}
+
+// ========== Mock UsageCallRepository ==========
+
+// mockUsageCallRepository reports fixed usage per business
+type mockUsageCallRepository struct {
+	*testCallRepository
+	usage []*database.BusinessUsage
+}
+
+func (m *mockUsageCallRepository) GetUsageByBusiness(ctx context.Context, startDate, endDate time.Time) ([]*database.BusinessUsage, error) {
+	return m.usage, nil
+}
+
+// ========== Mock StatsCallRepository ==========
+
+// mockStatsCallRepository reports fixed stats for a business, or counts its
+// calls
+type mockStatsCallRepository struct {
+	*testCallRepository
+	stats map[string]*database.CallStats
+}
+
+func newMockStatsCallRepository() *mockStatsCallRepository {
+	return &mockStatsCallRepository{testCallRepository: newTestCallRepository(), stats: make(map[string]*database.CallStats)}
+}
+
+func (m *mockStatsCallRepository) GetStats(ctx context.Context, businessID string, startDate, endDate time.Time) (*database.CallStats, error) {
+	if stats, ok := m.stats[businessID]; ok {
+		return stats, nil
+	}
+	calls, _ := m.GetByBusinessID(ctx, businessID, 0, 0)
+	return &database.CallStats{TotalCalls: len(calls)}, nil
+}
+
+// ========== Mock OnboardingRepository ==========
+
+// mockOnboardingRepository keeps provider ids apart from the onboardings,
+// as they are committed on their own. Replacing onboardings with an earlier
+// snapshot rolls a request back.
+type mockOnboardingRepository struct {
+	onboardings map[string]*entities.Onboarding
+	providerIDs map[string]map[entities.OnboardingStepName]string
+	saves       int
+	locks       int
+	// fail fails saves when set and it returns an error
+	fail func(onboarding *entities.Onboarding) error
+}
+
+func newMockOnboardingRepository() *mockOnboardingRepository {
+	return &mockOnboardingRepository{
+		onboardings: make(map[string]*entities.Onboarding),
+		providerIDs: make(map[string]map[entities.OnboardingStepName]string),
+	}
+}
+
+func (m *mockOnboardingRepository) Get(ctx context.Context, businessID string) (*entities.Onboarding, error) {
+	onboarding, ok := m.onboardings[businessID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *onboarding
+	copied.Steps = append([]entities.OnboardingStep(nil), onboarding.Steps...)
+	if id, ok := m.providerIDs[businessID][entities.OnboardingStepAssistant]; ok {
+		copied.AssistantID = id
+	}
+	if id, ok := m.providerIDs[businessID][entities.OnboardingStepPhoneNumber]; ok {
+		copied.PhoneNumberID = id
+	}
+	return &copied, nil
+}
+
+func (m *mockOnboardingRepository) GetForUpdate(ctx context.Context, businessID string) (*entities.Onboarding, error) {
+	m.locks++
+	return m.Get(ctx, businessID)
+}
+
+func (m *mockOnboardingRepository) Create(ctx context.Context, onboarding *entities.Onboarding) error {
+	if _, ok := m.onboardings[onboarding.BusinessID]; ok {
+		return domainerrors.NewAlreadyExistsError("onboarding", "business_id", onboarding.BusinessID)
+	}
+	return m.Save(ctx, onboarding)
+}
+
+func (m *mockOnboardingRepository) Save(ctx context.Context, onboarding *entities.Onboarding) error {
+	if m.fail != nil {
+		if err := m.fail(onboarding); err != nil {
+			return err
+		}
+	}
+	m.saves++
+	copied := *onboarding
+	copied.Steps = append([]entities.OnboardingStep(nil), onboarding.Steps...)
+	m.onboardings[onboarding.BusinessID] = &copied
+	return nil
+}
+
+func (m *mockOnboardingRepository) RecordProviderID(ctx context.Context, businessID string, step entities.OnboardingStepName, providerID string) error {
+	if m.providerIDs[businessID] == nil {
+		m.providerIDs[businessID] = make(map[entities.OnboardingStepName]string)
+	}
+	m.providerIDs[businessID][step] = providerID
+	return nil
+}
+
+func (m *mockOnboardingRepository) ClearProviderID(ctx context.Context, businessID string, step entities.OnboardingStepName) error {
+	delete(m.providerIDs[businessID], step)
+	return nil
+}
+
+func (m *mockOnboardingRepository) snapshot() map[string]*entities.Onboarding {
+	onboardings := make(map[string]*entities.Onboarding, len(m.onboardings))
+	for businessID, onboarding := range m.onboardings {
+		onboardings[businessID] = onboarding
+	}
+	return onboardings
+}
+
+// ========== Mock PhoneProvider ==========
+
+// mockPhoneProvider is a voice provider that can attach phone numbers
+type mockPhoneProvider struct {
+	*mockVoiceProvider
+	attachPhoneNumberFunc func(ctx context.Context, req providers.PhoneNumberRequest) (string, error)
+}
+
+func (m *mockPhoneProvider) AttachPhoneNumber(ctx context.Context, req providers.PhoneNumberRequest) (string, error) {
+	return m.attachPhoneNumberFunc(ctx, req)
+}
+
+// ========== Mock FailingBusinessRepository ==========
+
+// mockFailingBusinessRepository fails updates when fail returns an error
+type mockFailingBusinessRepository struct {
+	*mockBusinessRepository
+	fail func(business *entities.Business) error
+}
+
+func (m *mockFailingBusinessRepository) Update(ctx context.Context, business *entities.Business) error {
+	if err := m.fail(business); err != nil {
+		return err
+	}
+	return m.mockBusinessRepository.Update(ctx, business)
+}
+
+// ========== Mock CallTool ==========
+
+// mockCallTool is a call tool with a fixed definition
+type mockCallTool struct {
+	name     string
+	function *providers.Function
+}
+
+func (m *mockCallTool) Name() string {
+	return m.name
+}
+
+func (m *mockCallTool) Definition(ctx context.Context, businessID string) (*providers.Function, error) {
+	return m.function, nil
+}
+
+func (m *mockCallTool) Invoke(ctx context.Context, call *entities.Call, args map[string]interface{}) (string, error) {
+	return "", nil
+}
+
+// ========== Mock AssistantTemplateRepository ==========
+
+type mockAssistantTemplateRepository struct {
+	overrides map[string]*entities.AssistantTemplateOverride
+}
+
+func newMockAssistantTemplateRepository() *mockAssistantTemplateRepository {
+	return &mockAssistantTemplateRepository{overrides: make(map[string]*entities.AssistantTemplateOverride)}
+}
+
+func (m *mockAssistantTemplateRepository) GetOverride(ctx context.Context, businessID string) (*entities.AssistantTemplateOverride, error) {
+	return m.overrides[businessID], nil
+}
+
+func (m *mockAssistantTemplateRepository) SaveOverride(ctx context.Context, override *entities.AssistantTemplateOverride) error {
+	m.overrides[override.BusinessID] = override
+	return nil
+}
+
+func (m *mockAssistantTemplateRepository) DeleteOverride(ctx context.Context, businessID string) error {
+	delete(m.overrides, businessID)
+	return nil
+}
+
+// ========== Mock UserTokenRepository ==========
+
+type mockUserTokenRepository struct {
+	tokens []*entities.UserToken
+}
+
+func (m *mockUserTokenRepository) Create(ctx context.Context, token *entities.UserToken) error {
+	token.ID = "token-" + token.TokenHash[:8]
+	m.tokens = append(m.tokens, token)
+	return nil
+}
+
+func (m *mockUserTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserToken, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockUserTokenRepository) Update(ctx context.Context, token *entities.UserToken) error {
+	return nil
+}
+
+func (m *mockUserTokenRepository) InvalidateForUser(ctx context.Context, userID string, purpose entities.UserTokenPurpose, now time.Time) error {
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+// ========== Mock Mailer ==========
+
+type mockMailer struct {
+	sent []providers.Email
+}
+
+func (m *mockMailer) Send(ctx context.Context, email providers.Email) error {
+	m.sent = append(m.sent, email)
+	return nil
+}
+
+// token returns the token in the link of the i-th email sent
+func (m *mockMailer) token(t *testing.T, i int) string {
+	t.Helper()
+	if i < 0 || i >= len(m.sent) {
+		t.Fatalf("expected at least %d emails to be sent, got %d", i+1, len(m.sent))
+	}
+	body := m.sent[i].Body
+	start := strings.Index(body, "http")
+	link, err := url.Parse(strings.Fields(body[start:])[0])
+	if err != nil {
+		t.Fatalf("failed to parse link: %v", err)
+	}
+	return link.Query().Get("token")
+}
+
+// lastToken returns the token in the link of the last email sent
+func (m *mockMailer) lastToken(t *testing.T) string {
+	t.Helper()
+	return m.token(t, len(m.sent)-1)
+}
+
+// ========== Mock CampaignRepository ==========
+
+// mockCampaignRepository is backed by a map
+type mockCampaignRepository struct {
+	campaigns map[string]*entities.Campaign
+}
+
+func newMockCampaignRepository() *mockCampaignRepository {
+	return &mockCampaignRepository{campaigns: make(map[string]*entities.Campaign)}
+}
+
+func (m *mockCampaignRepository) Create(ctx context.Context, campaign *entities.Campaign) error {
+	campaign.ID = "campaign-" + strconv.Itoa(len(m.campaigns)+1)
+	m.campaigns[campaign.ID] = campaign
+	return nil
+}
+
+func (m *mockCampaignRepository) GetByID(ctx context.Context, id string) (*entities.Campaign, error) {
+	if campaign, ok := m.campaigns[id]; ok {
+		return campaign, nil
+	}
+	return nil, domainerrors.NewNotFoundError("campaign", id)
+}
+
+func (m *mockCampaignRepository) GetByBusinessID(ctx context.Context, businessID string, limit, offset int) ([]*entities.Campaign, error) {
+	var result []*entities.Campaign
+	for _, campaign := range m.campaigns {
+		if campaign.BusinessID == businessID {
+			result = append(result, campaign)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockCampaignRepository) GetByStatus(ctx context.Context, status entities.CampaignStatus) ([]*entities.Campaign, error) {
+	var result []*entities.Campaign
+	for _, campaign := range m.campaigns {
+		if campaign.Status == status {
+			result = append(result, campaign)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockCampaignRepository) Update(ctx context.Context, campaign *entities.Campaign) error {
+	m.campaigns[campaign.ID] = campaign
+	return nil
+}
+
+func (m *mockCampaignRepository) Delete(ctx context.Context, id string) error {
+	delete(m.campaigns, id)
+	return nil
+}
+
+// ========== Mock CampaignContactRepository ==========
+
+// mockCampaignContactRepository is backed by a slice, kept in insertion order
+type mockCampaignContactRepository struct {
+	contacts []*entities.CampaignContact
+}
+
+func (m *mockCampaignContactRepository) CreateBatch(ctx context.Context, contacts []*entities.CampaignContact) (int, error) {
+	added := 0
+	for _, contact := range contacts {
+		duplicate := false
+		for _, existing := range m.contacts {
+			if existing.CampaignID == contact.CampaignID && existing.Phone == contact.Phone {
+				duplicate = true
+			}
+		}
+		if duplicate {
+			continue
+		}
+		contact.ID = "contact-" + strconv.Itoa(len(m.contacts)+1)
+		m.contacts = append(m.contacts, contact)
+		added++
+	}
+	return added, nil
+}
+
+func (m *mockCampaignContactRepository) GetByID(ctx context.Context, id string) (*entities.CampaignContact, error) {
+	for _, contact := range m.contacts {
+		if contact.ID == id {
+			return contact, nil
+		}
+	}
+	return nil, domainerrors.NewNotFoundError("campaign contact", id)
+}
+
+func (m *mockCampaignContactRepository) GetByCampaignID(ctx context.Context, campaignID string, status entities.CampaignContactStatus, limit, offset int) ([]*entities.CampaignContact, error) {
+	var result []*entities.CampaignContact
+	for _, contact := range m.contacts {
+		if contact.CampaignID == campaignID && (status == "" || contact.Status == status) {
+			result = append(result, contact)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockCampaignContactRepository) GetDue(ctx context.Context, campaignID string, now time.Time, limit int) ([]*entities.CampaignContact, error) {
+	var result []*entities.CampaignContact
+	for _, contact := range m.contacts {
+		if contact.CampaignID == campaignID && contact.IsDue(now) && len(result) < limit {
+			result = append(result, contact)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockCampaignContactRepository) CountByStatus(ctx context.Context, campaignID string) (map[entities.CampaignContactStatus]int, error) {
+	counts := make(map[entities.CampaignContactStatus]int)
+	for _, contact := range m.contacts {
+		if contact.CampaignID == campaignID {
+			counts[contact.Status]++
+		}
+	}
+	return counts, nil
+}
+
+func (m *mockCampaignContactRepository) Update(ctx context.Context, contact *entities.CampaignContact) error {
+	return nil
+}
+
+func (m *mockCampaignContactRepository) HasCall(ctx context.Context, callID string) (bool, error) {
+	for _, contact := range m.contacts {
+		for _, id := range contact.CallIDs {
+			if id == callID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// ========== Mock TransferDestinationRepository ==========
+
+type mockTransferDestinationRepository struct {
+	destinations []*entities.TransferDestination
+}
+
+func (m *mockTransferDestinationRepository) Create(ctx context.Context, destination *entities.TransferDestination) error {
+	destination.ID = "destination-" + strconv.Itoa(len(m.destinations)+1)
+	m.destinations = append(m.destinations, destination)
+	return nil
+}
+
+func (m *mockTransferDestinationRepository) GetByID(ctx context.Context, id string) (*entities.TransferDestination, error) {
+	for _, destination := range m.destinations {
+		if destination.ID == id {
+			return destination, nil
+		}
+	}
+	return nil, domainerrors.NewNotFoundError("transfer destination", id)
+}
+
+func (m *mockTransferDestinationRepository) GetByBusinessID(ctx context.Context, businessID string) ([]*entities.TransferDestination, error) {
+	var result []*entities.TransferDestination
+	for _, destination := range m.destinations {
+		if destination.BusinessID == businessID {
+			result = append(result, destination)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockTransferDestinationRepository) GetByName(ctx context.Context, businessID, name string) (*entities.TransferDestination, error) {
+	for _, destination := range m.destinations {
+		if destination.BusinessID == businessID && destination.Name == name {
+			return destination, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockTransferDestinationRepository) Update(ctx context.Context, destination *entities.TransferDestination) error {
+	return nil
+}
+
+func (m *mockTransferDestinationRepository) Delete(ctx context.Context, id string) error {
+	return errors.New("not implemented")
+}
+
+// ========== Mock OnCallShiftRepository ==========
+
+type mockOnCallShiftRepository struct {
+	shifts []*entities.OnCallShift
+}
+
+func (m *mockOnCallShiftRepository) Create(ctx context.Context, shift *entities.OnCallShift) error {
+	shift.ID = "shift-" + strconv.Itoa(len(m.shifts)+1)
+	m.shifts = append(m.shifts, shift)
+	return nil
+}
+
+func (m *mockOnCallShiftRepository) GetByID(ctx context.Context, id string) (*entities.OnCallShift, error) {
+	for _, shift := range m.shifts {
+		if shift.ID == id {
+			return shift, nil
+		}
+	}
+	return nil, domainerrors.NewNotFoundError("on-call shift", id)
+}
+
+func (m *mockOnCallShiftRepository) GetByDestinationID(ctx context.Context, destinationID string, from time.Time) ([]*entities.OnCallShift, error) {
+	var result []*entities.OnCallShift
+	for _, shift := range m.shifts {
+		if shift.DestinationID == destinationID && shift.EndsAt.After(from) {
+			result = append(result, shift)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockOnCallShiftRepository) GetCurrent(ctx context.Context, destinationID string, at time.Time) (*entities.OnCallShift, error) {
+	for _, shift := range m.shifts {
+		if shift.DestinationID == destinationID && shift.Covers(at) {
+			return shift, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockOnCallShiftRepository) Delete(ctx context.Context, id string) error {
+	return errors.New("not implemented")
+}
+
+// ========== Mock TransferProvider ==========
+
+// mockTransferProvider can transfer calls and answer tool calls
+type mockTransferProvider struct {
+	testVoiceProvider
+	transfers   []providers.TransferRequest
+	transferErr error
+}
+
+func (m *mockTransferProvider) TransferCall(ctx context.Context, req providers.TransferRequest) error {
+	if m.transferErr != nil {
+		return m.transferErr
+	}
+	m.transfers = append(m.transfers, req)
+	return nil
+}
+
+func (m *mockTransferProvider) BuildToolCallResponse(results []providers.ToolCallResult) interface{} {
+	return results
+}
+
+// ========== Mock MessageRepository ==========
+
+type mockMessageRepository struct {
+	messages []*entities.Message
+}
+
+func (m *mockMessageRepository) Create(ctx context.Context, message *entities.Message) error {
+	message.ID = "message-" + strconv.Itoa(len(m.messages)+1)
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func (m *mockMessageRepository) GetByID(ctx context.Context, id string) (*entities.Message, error) {
+	for _, message := range m.messages {
+		if message.ID == id {
+			return message, nil
+		}
+	}
+	return nil, domainerrors.NewNotFoundError("message", id)
+}
+
+func (m *mockMessageRepository) List(ctx context.Context, businessID string, filter database.MessageFilter, limit, offset int) ([]*entities.Message, error) {
+	var result []*entities.Message
+	for _, message := range m.messages {
+		if message.BusinessID != businessID {
+			continue
+		}
+		if filter.Status != "" && message.Status != filter.Status {
+			continue
+		}
+		if filter.RecipientID != "" && (message.RecipientID == nil || *message.RecipientID != filter.RecipientID) {
+			continue
+		}
+		if filter.AssigneeID != "" && (message.AssigneeID == nil || *message.AssigneeID != filter.AssigneeID) {
+			continue
+		}
+		result = append(result, message)
+	}
+	return result, nil
+}
+
+func (m *mockMessageRepository) HasCallMessage(ctx context.Context, callID string) (bool, error) {
+	for _, message := range m.messages {
+		if message.CallID != nil && *message.CallID == callID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockMessageRepository) Update(ctx context.Context, message *entities.Message) error {
+	return nil
+}
+
+// ========== Mock MessageRecipientRepository ==========
+
+type mockMessageRecipientRepository struct {
+	recipients []*entities.MessageRecipient
+}
+
+func (m *mockMessageRecipientRepository) Create(ctx context.Context, recipient *entities.MessageRecipient) error {
+	recipient.ID = "recipient-" + strconv.Itoa(len(m.recipients)+1)
+	m.recipients = append(m.recipients, recipient)
+	return nil
+}
+
+func (m *mockMessageRecipientRepository) GetByID(ctx context.Context, id string) (*entities.MessageRecipient, error) {
+	for _, recipient := range m.recipients {
+		if recipient.ID == id {
+			return recipient, nil
+		}
+	}
+	return nil, domainerrors.NewNotFoundError("message recipient", id)
+}
+
+func (m *mockMessageRecipientRepository) GetByBusinessID(ctx context.Context, businessID string) ([]*entities.MessageRecipient, error) {
+	var result []*entities.MessageRecipient
+	for _, recipient := range m.recipients {
+		if recipient.BusinessID == businessID {
+			result = append(result, recipient)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockMessageRecipientRepository) Update(ctx context.Context, recipient *entities.MessageRecipient) error {
+	return nil
+}
+
+func (m *mockMessageRecipientRepository) Delete(ctx context.Context, id string) error {
+	for i, recipient := range m.recipients {
+		if recipient.ID == id {
+			m.recipients = append(m.recipients[:i], m.recipients[i+1:]...)
+			return nil
+		}
+	}
+	return domainerrors.NewNotFoundError("message recipient", id)
+}
+
+// ========== Mock Notifier ==========
+
+type mockNotifier struct {
+	sent []providers.Notification
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, notification providers.Notification) error {
+	m.sent = append(m.sent, notification)
+	return nil
+}
+
+// ========== Mock InboxRepository ==========
+
+type mockInboxRepository struct {
+	states   map[string]*entities.InboxItemState
+	comments []*entities.InboxComment
+}
+
+func newMockInboxRepository() *mockInboxRepository {
+	return &mockInboxRepository{states: make(map[string]*entities.InboxItemState)}
+}
+
+func (m *mockInboxRepository) GetState(ctx context.Context, businessID string, itemType entities.InboxItemType, sourceID string) (*entities.InboxItemState, error) {
+	if state, ok := m.states[entities.InboxItemKey(itemType, sourceID)]; ok && state.BusinessID == businessID {
+		copied := *state
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockInboxRepository) GetStates(ctx context.Context, businessID string, sourceIDs []string) ([]*entities.InboxItemState, error) {
+	wanted := make(map[string]bool, len(sourceIDs))
+	for _, id := range sourceIDs {
+		wanted[id] = true
+	}
+
+	var result []*entities.InboxItemState
+	for _, state := range m.states {
+		if state.BusinessID == businessID && wanted[state.SourceID] {
+			copied := *state
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockInboxRepository) SaveState(ctx context.Context, state *entities.InboxItemState) error {
+	copied := *state
+	m.states[entities.InboxItemKey(state.ItemType, state.SourceID)] = &copied
+	return nil
+}
+
+func (m *mockInboxRepository) CreateComment(ctx context.Context, comment *entities.InboxComment) error {
+	comment.ID = "comment-" + time.Now().Format("150405.000000")
+	m.comments = append(m.comments, comment)
+	return nil
+}
+
+func (m *mockInboxRepository) GetComments(ctx context.Context, businessID string, itemType entities.InboxItemType, sourceID string) ([]*entities.InboxComment, error) {
+	var result []*entities.InboxComment
+	for _, comment := range m.comments {
+		if comment.BusinessID == businessID && comment.ItemType == itemType && comment.SourceID == sourceID {
+			result = append(result, comment)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockInboxRepository) CountComments(ctx context.Context, businessID string, sourceIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, comment := range m.comments {
+		if comment.BusinessID == businessID {
+			counts[comment.SourceID]++
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,401 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// OnboardingService sets a newly registered business up to take calls. From
+// the business's type, hours, services and FAQs it saves the business's
+// profile, generates and creates its assistant and attaches its number to
+// the voice provider. Progress is saved after each step, so an onboarding
+// that fails part way can be resumed from the failed step. The onboarding is
+// locked while its steps run, so a business's onboardings run one at a time.
+type OnboardingService struct {
+	onboardingRepo database.OnboardingRepository
+	businessRepo   database.BusinessRepository
+	voiceProvider  providers.VoiceProvider
+	logger         *logger.Logger
+
+	tools     []CallTool
+	templates *AssistantTemplateService
+	audit     *AuditService
+}
+
+func NewOnboardingService(
+	onboardingRepo database.OnboardingRepository,
+	businessRepo database.BusinessRepository,
+	voiceProvider providers.VoiceProvider,
+	log *logger.Logger,
+) *OnboardingService {
+	return &OnboardingService{
+		onboardingRepo: onboardingRepo,
+		businessRepo:   businessRepo,
+		voiceProvider:  voiceProvider,
+		logger:         log,
+	}
+}
+
+// AddTool makes a call tool available to the generated assistants, as
+// CallService.AddTool does for each call
+func (s *OnboardingService) AddTool(tool CallTool) {
+	s.tools = append(s.tools, tool)
+}
+
+// SetTemplates generates assistants from the business's template with its
+// changes applied. Without it the library template for the business's type
+// is used as it is.
+func (s *OnboardingService) SetTemplates(templates *AssistantTemplateService) {
+	s.templates = templates
+}
+
+// SetAudit records onboardings starting and completing in the audit log
+func (s *OnboardingService) SetAudit(audit *AuditService) {
+	s.audit = audit
+}
+
+// Start onboards the business from its description and runs every step. A
+// failed step is reported in the response rather than as an error, so its
+// progress is kept for Resume. Starting again before an onboarding has
+// completed replaces it.
+func (s *OnboardingService) Start(ctx context.Context, businessID string, req dto.StartOnboardingRequest) (*dto.OnboardingResponse, error) {
+	business, err := s.getBusiness(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.onboardingRepo.GetForUpdate(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.IsCompleted() {
+		return nil, domainerrors.NewAlreadyExistsError("onboarding", "business_id", businessID)
+	}
+
+	profile, err := onboardingProfile(business, req)
+	if err != nil {
+		return nil, err
+	}
+
+	onboarding, err := entities.NewOnboarding(businessID, profile)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		// Another start of the same business waits here for the first to
+		// finish and is then refused
+		err = s.onboardingRepo.Create(ctx, onboarding)
+	} else {
+		onboarding.CreatedAt = existing.CreatedAt
+		onboarding.PhoneNumberID = existing.PhoneNumberID
+		err = s.onboardingRepo.Save(ctx, onboarding)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The profile may have changed, so the assistant is generated again,
+	// but a number already attached, even by a start that failed, is kept
+	if err := s.onboardingRepo.ClearProviderID(ctx, businessID, entities.OnboardingStepAssistant); err != nil {
+		return nil, err
+	}
+	onboarding, err = s.onboardingRepo.GetForUpdate(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionOnboardingStarted,
+		TargetType: "business",
+		TargetID:   businessID,
+		Metadata:   map[string]interface{}{"business_type": profile.BusinessType},
+	})
+
+	return s.run(ctx, business, onboarding)
+}
+
+// GetStatus returns the business's onboarding progress
+func (s *OnboardingService) GetStatus(ctx context.Context, businessID string) (*dto.OnboardingResponse, error) {
+	onboarding, err := s.getOnboarding(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	return mapOnboardingToResponse(onboarding), nil
+}
+
+// Resume runs the steps that have not completed, starting with the one that
+// failed. A Resume that waited for another to finish sees its progress.
+func (s *OnboardingService) Resume(ctx context.Context, businessID string) (*dto.OnboardingResponse, error) {
+	onboarding, err := s.onboardingRepo.GetForUpdate(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if onboarding == nil {
+		return nil, domainerrors.NewNotFoundError("onboarding", businessID)
+	}
+	if onboarding.IsCompleted() {
+		return mapOnboardingToResponse(onboarding), nil
+	}
+
+	business, err := s.getBusiness(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.run(ctx, business, onboarding)
+}
+
+// run works through the remaining steps, saving after each, and stops at
+// the first that fails
+func (s *OnboardingService) run(ctx context.Context, business *entities.Business, onboarding *entities.Onboarding) (*dto.OnboardingResponse, error) {
+	for step := onboarding.NextStep(); step != nil; step = onboarding.NextStep() {
+		name := step.Name
+
+		if err := s.runStep(ctx, business, onboarding, name); err != nil {
+			s.logger.Error("Onboarding step failed", err, map[string]interface{}{
+				"business_id": business.ID,
+				"step":        string(name),
+			})
+			onboarding.FailStep(name, toolErrorMessage(err))
+			if err := s.onboardingRepo.Save(ctx, onboarding); err != nil {
+				return nil, err
+			}
+			return mapOnboardingToResponse(onboarding), nil
+		}
+
+		onboarding.CompleteStep(name)
+		if err := s.onboardingRepo.Save(ctx, onboarding); err != nil {
+			return nil, err
+		}
+	}
+
+	s.logger.Info("Business onboarded", map[string]interface{}{
+		"business_id":     business.ID,
+		"assistant_id":    onboarding.AssistantID,
+		"phone_number_id": onboarding.PhoneNumberID,
+	})
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: business.ID,
+		Action:     entities.AuditActionOnboardingCompleted,
+		TargetType: "business",
+		TargetID:   business.ID,
+		Metadata: map[string]interface{}{
+			"assistant_id":    onboarding.AssistantID,
+			"phone_number_id": onboarding.PhoneNumberID,
+		},
+	})
+
+	return mapOnboardingToResponse(onboarding), nil
+}
+
+func (s *OnboardingService) runStep(ctx context.Context, business *entities.Business, onboarding *entities.Onboarding, name entities.OnboardingStepName) error {
+	switch name {
+	case entities.OnboardingStepProfile:
+		return s.saveProfile(ctx, business, onboarding.Profile)
+	case entities.OnboardingStepAssistant:
+		return s.createAssistant(ctx, business, onboarding)
+	case entities.OnboardingStepPhoneNumber:
+		return s.attachPhoneNumber(ctx, business, onboarding)
+	default:
+		return fmt.Errorf("unknown onboarding step %s", name)
+	}
+}
+
+// saveProfile stores the business type, time zone and hours on the business
+func (s *OnboardingService) saveProfile(ctx context.Context, business *entities.Business, profile entities.OnboardingProfile) error {
+	if err := business.Update("", profile.BusinessType, ""); err != nil {
+		return err
+	}
+	if err := business.UpdateSettings(profileSettings(business, profile)); err != nil {
+		return err
+	}
+	return s.businessRepo.Update(ctx, business)
+}
+
+// createAssistant generates the business's assistant from the template for
+// its type and makes it answer the business's calls. The assistant is
+// recorded as soon as the provider creates it, outside the request's
+// transaction, so a retry after a later failure does not create another
+// even if the request rolled back.
+func (s *OnboardingService) createAssistant(ctx context.Context, business *entities.Business, onboarding *entities.Onboarding) error {
+	if onboarding.AssistantID == "" {
+		template, err := s.template(ctx, business)
+		if err != nil {
+			return err
+		}
+
+		config, err := renderAssistantTemplate(ctx, template, business, onboarding.Profile, s.tools)
+		if err != nil {
+			return err
+		}
+
+		assistantID, err := s.voiceProvider.UpdateAssistantConfig(ctx, config)
+		if err != nil {
+			return err
+		}
+		if assistantID == "" {
+			return domainerrors.NewProviderError(fmt.Errorf("no assistant ID returned"), "failed to create assistant")
+		}
+
+		onboarding.AssistantID = assistantID
+		if err := s.onboardingRepo.RecordProviderID(ctx, business.ID, entities.OnboardingStepAssistant, assistantID); err != nil {
+			return err
+		}
+	}
+
+	settings := business.EffectiveSettings()
+	settings.AssistantID = onboarding.AssistantID
+	if err := business.UpdateSettings(settings); err != nil {
+		return err
+	}
+	return s.businessRepo.Update(ctx, business)
+}
+
+// template returns the template the business's assistant is generated from
+func (s *OnboardingService) template(ctx context.Context, business *entities.Business) (entities.AssistantTemplate, error) {
+	if s.templates == nil {
+		return assistantTemplateFor(business.Type), nil
+	}
+	return s.templates.Template(ctx, business)
+}
+
+// attachPhoneNumber puts the business's number on the voice provider and,
+// like createAssistant, records it as soon as the provider has attached it
+func (s *OnboardingService) attachPhoneNumber(ctx context.Context, business *entities.Business, onboarding *entities.Onboarding) error {
+	if onboarding.PhoneNumberID != "" {
+		return nil
+	}
+
+	attacher, ok := s.voiceProvider.(providers.PhoneNumberAttacher)
+	if !ok {
+		return domainerrors.NewInvalidInputError("the voice provider cannot attach phone numbers")
+	}
+
+	phoneNumberID, err := attacher.AttachPhoneNumber(ctx, providers.PhoneNumberRequest{
+		PhoneNumber: business.Phone,
+		Name:        business.Name,
+		AssistantID: onboarding.AssistantID,
+	})
+	if err != nil {
+		return err
+	}
+
+	onboarding.PhoneNumberID = phoneNumberID
+	return s.onboardingRepo.RecordProviderID(ctx, business.ID, entities.OnboardingStepPhoneNumber, phoneNumberID)
+}
+
+func (s *OnboardingService) getBusiness(ctx context.Context, businessID string) (*entities.Business, error) {
+	business, err := s.businessRepo.GetByID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, ErrBusinessNotFound
+	}
+	return business, nil
+}
+
+func (s *OnboardingService) getOnboarding(ctx context.Context, businessID string) (*entities.Onboarding, error) {
+	onboarding, err := s.onboardingRepo.Get(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if onboarding == nil {
+		return nil, domainerrors.NewNotFoundError("onboarding", businessID)
+	}
+	return onboarding, nil
+}
+
+// onboardingProfile reads the request, defaulting the hours to the
+// business's. The settings the profile would save are checked now, so a
+// bad time zone or hours are rejected before any step runs.
+func onboardingProfile(business *entities.Business, req dto.StartOnboardingRequest) (entities.OnboardingProfile, error) {
+	profile := entities.OnboardingProfile{
+		BusinessType: req.BusinessType,
+		Timezone:     req.Timezone,
+		Hours:        business.Hours(),
+	}
+
+	if len(req.Hours) > 0 {
+		var hours entities.WeeklyHours
+		decoder := json.NewDecoder(bytes.NewReader(req.Hours))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&hours); err != nil {
+			return entities.OnboardingProfile{}, domainerrors.NewFieldValidationError("hours", "hours must map days of the week to lists of opening and closing times")
+		}
+		profile.Hours = hours
+	}
+
+	for _, service := range req.Services {
+		profile.Services = append(profile.Services, entities.OfferedService{
+			Name:            service.Name,
+			Description:     service.Description,
+			DurationMinutes: service.DurationMinutes,
+		})
+	}
+	for _, faq := range req.FAQs {
+		profile.FAQs = append(profile.FAQs, entities.FAQ{Question: faq.Question, Answer: faq.Answer})
+	}
+
+	if err := profile.Validate(); err != nil {
+		return entities.OnboardingProfile{}, err
+	}
+	if err := profileSettings(business, profile).Validate(); err != nil {
+		return entities.OnboardingProfile{}, err
+	}
+	return profile, nil
+}
+
+// profileSettings returns the business's settings with the profile's time
+// zone and hours
+func profileSettings(business *entities.Business, profile entities.OnboardingProfile) entities.BusinessSettings {
+	settings := business.EffectiveSettings()
+	settings.Hours = profile.Hours
+	if profile.Timezone != "" {
+		settings.Timezone = profile.Timezone
+	}
+	return settings
+}
+
+func mapOnboardingToResponse(onboarding *entities.Onboarding) *dto.OnboardingResponse {
+	response := &dto.OnboardingResponse{
+		BusinessID:    onboarding.BusinessID,
+		Status:        string(onboarding.Status),
+		Steps:         make([]dto.OnboardingStepResponse, 0, len(onboarding.Steps)),
+		AssistantID:   onboarding.AssistantID,
+		PhoneNumberID: onboarding.PhoneNumberID,
+		CreatedAt:     onboarding.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     onboarding.UpdatedAt.Format(time.RFC3339),
+	}
+	if onboarding.CompletedAt != nil {
+		completedAt := onboarding.CompletedAt.Format(time.RFC3339)
+		response.CompletedAt = &completedAt
+	}
+
+	for _, step := range onboarding.Steps {
+		stepResponse := dto.OnboardingStepResponse{
+			Name:     string(step.Name),
+			Status:   string(step.Status),
+			Error:    step.Error,
+			Attempts: step.Attempts,
+		}
+		if step.CompletedAt != nil {
+			completedAt := step.CompletedAt.Format(time.RFC3339)
+			stepResponse.CompletedAt = &completedAt
+		}
+		response.Steps = append(response.Steps, stepResponse)
+	}
+	return response
+}
@@ -0,0 +1,463 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// newMockOnboardingBusinessRepository returns business-123, Smith Dental,
+// before onboarding
+func newMockOnboardingBusinessRepository() *mockBusinessRepository {
+	return &mockBusinessRepository{businesses: map[string]*entities.Business{
+		"business-123": {
+			ID:       "business-123",
+			Name:     "Smith Dental",
+			Phone:    "+15551230000",
+			Settings: entities.DefaultBusinessSettings(),
+		},
+	}}
+}
+
+// newMockOnboardingProvider creates assistant-new and attaches
+// phone-number-1, recording what it is asked for
+func newMockOnboardingProvider(assistants *[]providers.AssistantConfig, attached *[]providers.PhoneNumberRequest) *mockPhoneProvider {
+	return &mockPhoneProvider{
+		mockVoiceProvider: &mockVoiceProvider{
+			updateAssistantConfigFunc: func(ctx context.Context, config providers.AssistantConfig) (string, error) {
+				*assistants = append(*assistants, config)
+				return "assistant-new", nil
+			},
+		},
+		attachPhoneNumberFunc: func(ctx context.Context, req providers.PhoneNumberRequest) (string, error) {
+			*attached = append(*attached, req)
+			return "phone-number-1", nil
+		},
+	}
+}
+
+func newMockOnboardingService(onboardings *mockOnboardingRepository, businesses database.BusinessRepository, provider providers.VoiceProvider) *OnboardingService {
+	service := NewOnboardingService(onboardings, businesses, provider, logger.New("info", "console"))
+	service.AddTool(&mockCallTool{name: MessageToolName, function: &providers.Function{Name: MessageToolName}})
+	// Transfers have no destinations yet, so they are left out
+	service.AddTool(&mockCallTool{name: TransferToolName})
+	return service
+}
+
+func onboardingRequest() dto.StartOnboardingRequest {
+	return dto.StartOnboardingRequest{
+		BusinessType: "dental clinic",
+		Timezone:     "America/Chicago",
+		Hours:        json.RawMessage(`{"monday": [{"open": "08:00", "close": "16:00"}], "saturday": [{"open": "09:00", "close": "12:00"}]}`),
+		Services: []dto.OfferedServiceRequest{
+			{Name: "Cleaning", DurationMinutes: 45},
+			{Name: "Whitening", Description: "In-office whitening"},
+		},
+		FAQs: []dto.FAQRequest{{Question: "Do you take walk-ins?", Answer: "Only for emergencies."}},
+	}
+}
+
+func TestOnboardingService_Start(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// noPhoneNumbers uses a provider that cannot attach numbers
+		noPhoneNumbers bool
+		setupMocks     func(t *testing.T, service *OnboardingService, onboardings *mockOnboardingRepository, provider *mockPhoneProvider)
+		wantCode       string
+		validate       func(t *testing.T, service *OnboardingService, response *dto.OnboardingResponse, business *entities.Business, assistants []providers.AssistantConfig, attached []providers.PhoneNumberRequest)
+	}{
+		{
+			name: "new onboarding",
+			validate: func(t *testing.T, service *OnboardingService, response *dto.OnboardingResponse, business *entities.Business, assistants []providers.AssistantConfig, attached []providers.PhoneNumberRequest) {
+				if response.Status != string(entities.OnboardingStatusCompleted) || response.CompletedAt == nil {
+					t.Fatalf("expected the onboarding to complete, got %+v", response)
+				}
+				for _, step := range response.Steps {
+					if step.Status != string(entities.OnboardingStepCompleted) || step.Attempts != 1 {
+						t.Errorf("expected step %s to complete once, got %+v", step.Name, step)
+					}
+				}
+
+				if business.Type != "dental clinic" || business.Timezone() != "America/Chicago" {
+					t.Errorf("expected the profile to be saved, got type %q time zone %q", business.Type, business.Timezone())
+				}
+				if len(business.Hours().Saturday) != 1 || len(business.Hours().Tuesday) != 0 {
+					t.Errorf("expected the hours to be saved, got %+v", business.Hours())
+				}
+				if business.AssistantID() != "assistant-new" || response.AssistantID != "assistant-new" {
+					t.Errorf("expected the business to answer with the new assistant, got %q", business.AssistantID())
+				}
+
+				if len(assistants) != 1 {
+					t.Fatalf("expected one assistant, got %d", len(assistants))
+				}
+				assistant := assistants[0]
+				for _, want := range []string{"Smith Dental", "dental clinic", "America/Chicago", "Monday: 08:00-16:00", "Tuesday: closed",
+					"- Cleaning (45 minutes)", "- Whitening: In-office whitening", "Q: Do you take walk-ins?"} {
+					if !strings.Contains(assistant.Prompt, want) {
+						t.Errorf("expected the prompt to contain %q:\n%s", want, assistant.Prompt)
+					}
+				}
+				if strings.Contains(assistant.Prompt, "{{") || !strings.HasPrefix(assistant.FirstMessage, "Thank you for calling Smith Dental. Are you calling to book") {
+					t.Errorf("expected the dental clinic template to be rendered, got %q", assistant.FirstMessage)
+				}
+				if assistant.Metadata["template"] != "dental_clinic" || assistant.AnalysisSchema == nil {
+					t.Errorf("expected the template's interaction fields to be extracted, got %+v %+v", assistant.Metadata, assistant.AnalysisSchema)
+				}
+				if len(assistant.Functions) != 1 || assistant.Functions[0].Name != MessageToolName {
+					t.Errorf("expected only the message function, got %+v", assistant.Functions)
+				}
+
+				if len(attached) != 1 || attached[0].PhoneNumber != "+15551230000" || attached[0].AssistantID != "assistant-new" {
+					t.Errorf("unexpected attached numbers %+v", attached)
+				}
+			},
+		},
+		{
+			name: "completed onboarding",
+			setupMocks: func(t *testing.T, service *OnboardingService, onboardings *mockOnboardingRepository, provider *mockPhoneProvider) {
+				if _, err := service.Start(ctx, "business-123", onboardingRequest()); err != nil {
+					t.Fatalf("Start() error = %v", err)
+				}
+			},
+			wantCode: domainerrors.ErrCodeAlreadyExists,
+		},
+		{
+			// An earlier start created the assistant and attached the
+			// number, and then rolled back
+			name: "earlier start rolled back",
+			setupMocks: func(t *testing.T, service *OnboardingService, onboardings *mockOnboardingRepository, provider *mockPhoneProvider) {
+				onboardings.RecordProviderID(ctx, "business-123", entities.OnboardingStepAssistant, "assistant-old")
+				onboardings.RecordProviderID(ctx, "business-123", entities.OnboardingStepPhoneNumber, "phone-number-1")
+			},
+			validate: func(t *testing.T, service *OnboardingService, response *dto.OnboardingResponse, business *entities.Business, assistants []providers.AssistantConfig, attached []providers.PhoneNumberRequest) {
+				if response.Status != string(entities.OnboardingStatusCompleted) {
+					t.Fatalf("expected the onboarding to complete, got %+v", response)
+				}
+				if response.AssistantID != "assistant-new" || len(assistants) != 1 {
+					t.Errorf("expected the assistant to be generated again, got %+v", response)
+				}
+				if response.PhoneNumberID != "phone-number-1" || len(attached) != 0 {
+					t.Errorf("expected the attached number to be kept, got %+v", response)
+				}
+			},
+		},
+		{
+			name: "number cannot be attached",
+			setupMocks: func(t *testing.T, service *OnboardingService, onboardings *mockOnboardingRepository, provider *mockPhoneProvider) {
+				provider.attachPhoneNumberFunc = func(ctx context.Context, req providers.PhoneNumberRequest) (string, error) {
+					return "", domainerrors.NewProviderError(errors.New("number in use"), "failed to attach phone number")
+				}
+			},
+			validate: func(t *testing.T, service *OnboardingService, response *dto.OnboardingResponse, business *entities.Business, assistants []providers.AssistantConfig, attached []providers.PhoneNumberRequest) {
+				if response.Status != string(entities.OnboardingStatusFailed) {
+					t.Fatalf("expected the onboarding to fail, got %s", response.Status)
+				}
+				phoneStep := response.Steps[2]
+				if phoneStep.Name != string(entities.OnboardingStepPhoneNumber) || phoneStep.Status != string(entities.OnboardingStepFailed) ||
+					phoneStep.Error != "failed to attach phone number" {
+					t.Errorf("expected the phone number step to report its failure, got %+v", phoneStep)
+				}
+				if response.Steps[1].Status != string(entities.OnboardingStepCompleted) {
+					t.Errorf("expected the assistant step to be kept, got %+v", response.Steps[1])
+				}
+				status, err := service.GetStatus(ctx, "business-123")
+				if err != nil || status.Status != string(entities.OnboardingStatusFailed) {
+					t.Errorf("expected the failure to be saved, got %+v, %v", status, err)
+				}
+			},
+		},
+		{
+			name:           "provider without phone numbers",
+			noPhoneNumbers: true,
+			validate: func(t *testing.T, service *OnboardingService, response *dto.OnboardingResponse, business *entities.Business, assistants []providers.AssistantConfig, attached []providers.PhoneNumberRequest) {
+				if response.Status != string(entities.OnboardingStatusFailed) || response.Steps[2].Error == "" {
+					t.Errorf("expected the phone number step to fail, got %+v", response)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assistants []providers.AssistantConfig
+			var attached []providers.PhoneNumberRequest
+			onboardings := newMockOnboardingRepository()
+			businesses := newMockOnboardingBusinessRepository()
+			provider := newMockOnboardingProvider(&assistants, &attached)
+			service := newMockOnboardingService(onboardings, businesses, provider)
+			if tt.noPhoneNumbers {
+				service = newMockOnboardingService(onboardings, businesses, provider.mockVoiceProvider)
+			}
+			if tt.setupMocks != nil {
+				tt.setupMocks(t, service, onboardings, provider)
+			}
+
+			response, err := service.Start(ctx, "business-123", onboardingRequest())
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("Start() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			if tt.validate != nil {
+				tt.validate(t, service, response, businesses.businesses["business-123"], assistants, attached)
+			}
+		})
+	}
+}
+
+func TestOnboardingService_Resume(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		setupMocks func(t *testing.T, service *OnboardingService, onboardings *mockOnboardingRepository, businesses *mockBusinessRepository, provider *mockPhoneProvider)
+		wantCode   string
+		validate   func(t *testing.T, response *dto.OnboardingResponse, onboardings *mockOnboardingRepository, assistants []providers.AssistantConfig, attached []providers.PhoneNumberRequest)
+	}{
+		{
+			name: "failed step",
+			setupMocks: func(t *testing.T, service *OnboardingService, onboardings *mockOnboardingRepository, businesses *mockBusinessRepository, provider *mockPhoneProvider) {
+				attach := provider.attachPhoneNumberFunc
+				provider.attachPhoneNumberFunc = func(ctx context.Context, req providers.PhoneNumberRequest) (string, error) {
+					return "", domainerrors.NewProviderError(errors.New("number in use"), "failed to attach phone number")
+				}
+				if _, err := service.Start(ctx, "business-123", onboardingRequest()); err != nil {
+					t.Fatalf("Start() error = %v", err)
+				}
+				provider.attachPhoneNumberFunc = attach
+			},
+			validate: func(t *testing.T, response *dto.OnboardingResponse, onboardings *mockOnboardingRepository, assistants []providers.AssistantConfig, attached []providers.PhoneNumberRequest) {
+				if response.PhoneNumberID != "phone-number-1" {
+					t.Errorf("expected the number to be attached, got %+v", response)
+				}
+				if response.Steps[2].Attempts != 2 || response.Steps[2].Error != "" {
+					t.Errorf("expected the phone number step to succeed on its second attempt, got %+v", response.Steps[2])
+				}
+				if len(assistants) != 1 {
+					t.Errorf("expected resuming not to create another assistant, got %d", len(assistants))
+				}
+			},
+		},
+		{
+			// The provider created the assistant but the business could
+			// not be updated with it
+			name: "assistant created but not saved",
+			setupMocks: func(t *testing.T, service *OnboardingService, onboardings *mockOnboardingRepository, businesses *mockBusinessRepository, provider *mockPhoneProvider) {
+				failing := newMockOnboardingService(onboardings, &mockFailingBusinessRepository{
+					mockBusinessRepository: businesses,
+					fail: func(business *entities.Business) error {
+						if business.AssistantID() != "" {
+							return domainerrors.NewDatabaseError(errors.New("connection reset"), "failed to update business")
+						}
+						return nil
+					},
+				}, provider)
+
+				response, err := failing.Start(ctx, "business-123", onboardingRequest())
+				if err != nil {
+					t.Fatalf("Start() error = %v", err)
+				}
+				if response.Steps[1].Status != string(entities.OnboardingStepFailed) || response.AssistantID != "assistant-new" {
+					t.Fatalf("expected the assistant step to fail after creating the assistant, got %+v", response)
+				}
+				if response.Steps[1].Error == "connection reset" {
+					t.Error("expected internal errors to be hidden")
+				}
+			},
+			validate: func(t *testing.T, response *dto.OnboardingResponse, onboardings *mockOnboardingRepository, assistants []providers.AssistantConfig, attached []providers.PhoneNumberRequest) {
+				if len(assistants) != 1 {
+					t.Errorf("expected the created assistant to be reused, got %d assistants", len(assistants))
+				}
+			},
+		},
+		{
+			// The first resume fails to save once the assistant is created
+			// and the second once the number is attached, so each request's
+			// transaction rolls back
+			name: "provider ids kept after rollbacks",
+			setupMocks: func(t *testing.T, service *OnboardingService, onboardings *mockOnboardingRepository, businesses *mockBusinessRepository, provider *mockPhoneProvider) {
+				create := provider.updateAssistantConfigFunc
+				provider.updateAssistantConfigFunc = func(ctx context.Context, config providers.AssistantConfig) (string, error) {
+					return "", domainerrors.NewProviderError(errors.New("timeout"), "failed to create assistant")
+				}
+				response, err := service.Start(ctx, "business-123", onboardingRequest())
+				if err != nil || response.Steps[1].Status != string(entities.OnboardingStepFailed) {
+					t.Fatalf("expected the assistant step to fail, got %+v, %v", response, err)
+				}
+				provider.updateAssistantConfigFunc = create
+
+				saveErr := domainerrors.NewDatabaseError(errors.New("connection reset"), "failed to save onboarding")
+				for _, created := range []func(onboarding *entities.Onboarding) bool{
+					func(onboarding *entities.Onboarding) bool { return onboarding.AssistantID != "" },
+					func(onboarding *entities.Onboarding) bool { return onboarding.PhoneNumberID != "" },
+				} {
+					committed := onboardings.snapshot()
+					onboardings.fail = func(onboarding *entities.Onboarding) error {
+						if created(onboarding) {
+							return saveErr
+						}
+						return nil
+					}
+					if _, err := service.Resume(ctx, "business-123"); err == nil {
+						t.Fatal("expected Resume() to fail")
+					}
+					onboardings.onboardings = committed
+					onboardings.fail = nil
+				}
+				onboardings.locks = 0
+			},
+			validate: func(t *testing.T, response *dto.OnboardingResponse, onboardings *mockOnboardingRepository, assistants []providers.AssistantConfig, attached []providers.PhoneNumberRequest) {
+				if response.AssistantID != "assistant-new" || response.PhoneNumberID != "phone-number-1" {
+					t.Errorf("expected the recorded provider ids, got %+v", response)
+				}
+				if len(assistants) != 1 || len(attached) != 1 {
+					t.Errorf("expected nothing to be created again, got %d assistants and %d numbers", len(assistants), len(attached))
+				}
+				if onboardings.locks != 1 {
+					t.Error("expected Resume to lock the onboarding")
+				}
+			},
+		},
+		{
+			name:     "not started",
+			wantCode: domainerrors.ErrCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assistants []providers.AssistantConfig
+			var attached []providers.PhoneNumberRequest
+			onboardings := newMockOnboardingRepository()
+			businesses := newMockOnboardingBusinessRepository()
+			provider := newMockOnboardingProvider(&assistants, &attached)
+			service := newMockOnboardingService(onboardings, businesses, provider)
+			if tt.setupMocks != nil {
+				tt.setupMocks(t, service, onboardings, businesses, provider)
+			}
+
+			response, err := service.Resume(ctx, "business-123")
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("Resume() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resume() error = %v", err)
+			}
+			if response.Status != string(entities.OnboardingStatusCompleted) {
+				t.Fatalf("expected the onboarding to complete, got %+v", response)
+			}
+			if tt.validate != nil {
+				tt.validate(t, response, onboardings, assistants, attached)
+			}
+		})
+	}
+}
+
+func TestOnboardingService_StartValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(req *dto.StartOnboardingRequest)
+		field  string
+	}{
+		{"missing business type", func(req *dto.StartOnboardingRequest) { req.BusinessType = "" }, "business_type"},
+		{"unknown time zone", func(req *dto.StartOnboardingRequest) { req.Timezone = "Mars/Olympus" }, "timezone"},
+		{"malformed hours", func(req *dto.StartOnboardingRequest) { req.Hours = json.RawMessage(`{"mon": []}`) }, "hours"},
+		{"hours closing before opening", func(req *dto.StartOnboardingRequest) {
+			req.Hours = json.RawMessage(`{"friday": [{"open": "17:00", "close": "09:00"}]}`)
+		}, "hours.friday[0].close"},
+		{"service without a name", func(req *dto.StartOnboardingRequest) {
+			req.Services = []dto.OfferedServiceRequest{{DurationMinutes: 30}}
+		}, "services[0].name"},
+		{"question without an answer", func(req *dto.StartOnboardingRequest) {
+			req.FAQs = []dto.FAQRequest{{Question: "Do you park?"}}
+		}, "faqs[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assistants []providers.AssistantConfig
+			var attached []providers.PhoneNumberRequest
+			onboardings := newMockOnboardingRepository()
+			service := newMockOnboardingService(onboardings, newMockOnboardingBusinessRepository(), newMockOnboardingProvider(&assistants, &attached))
+			req := onboardingRequest()
+			tt.change(&req)
+
+			_, err := service.Start(context.Background(), "business-123", req)
+			var domainErr *domainerrors.DomainError
+			if !errors.As(err, &domainErr) || domainErr.Field != tt.field {
+				t.Fatalf("expected a %s error, got %v", tt.field, err)
+			}
+			if onboardings.saves != 0 || len(assistants) != 0 {
+				t.Error("expected nothing to be started")
+			}
+		})
+	}
+}
+
+func TestOnboardingService_GetStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		started  bool
+		wantCode string
+	}{
+		{
+			name:    "started",
+			started: true,
+		},
+		{
+			name:     "not started",
+			wantCode: domainerrors.ErrCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assistants []providers.AssistantConfig
+			var attached []providers.PhoneNumberRequest
+			service := newMockOnboardingService(newMockOnboardingRepository(), newMockOnboardingBusinessRepository(), newMockOnboardingProvider(&assistants, &attached))
+			if tt.started {
+				if _, err := service.Start(context.Background(), "business-123", onboardingRequest()); err != nil {
+					t.Fatalf("Start() error = %v", err)
+				}
+			}
+
+			status, err := service.GetStatus(context.Background(), "business-123")
+			if errorCode(err) != tt.wantCode {
+				t.Fatalf("GetStatus() error = %v, want %q", err, tt.wantCode)
+			}
+			if tt.started && status.Status != string(entities.OnboardingStatusCompleted) {
+				t.Errorf("expected the completed onboarding, got %+v", status)
+			}
+		})
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	got := renderTemplate("Hi from {{business.name}}, {{ hours }}. {{caller_name}} is calling.", map[string]string{
+		"business.name": "Smith Dental",
+		"hours":         "open 9-5",
+	})
+	want := "Hi from Smith Dental, open 9-5. {{caller_name}} is calling."
+	if got != want {
+		t.Errorf("renderTemplate() = %q, want %q", got, want)
+	}
+}
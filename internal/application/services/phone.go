@@ -0,0 +1,19 @@
+package services
+
+import (
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/pkg/phone"
+)
+
+// normalizePhone converts user input to E.164, reporting failures against the
+// request field the number came from
+func normalizePhone(field, raw, region string) (string, error) {
+	number, err := phone.Normalize(raw, region)
+	if err == phone.ErrUnknownRegion {
+		return "", errors.NewFieldValidationError("default_region", "unsupported phone region "+region)
+	}
+	if err != nil {
+		return "", errors.NewFieldValidationError(field, field+" is not a valid phone number")
+	}
+	return number, nil
+}
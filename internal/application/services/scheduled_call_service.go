@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// ScheduledCallService books outbound calls for a later time and creates
+// callbacks for missed calls. Calls are placed by the ScheduledCallWorker.
+type ScheduledCallService struct {
+	scheduledRepo database.ScheduledCallRepository
+	contactRepo   database.CampaignContactRepository
+	businessRepo  database.BusinessRepository
+	logger        *logger.Logger
+
+	now func() time.Time
+}
+
+func NewScheduledCallService(
+	scheduledRepo database.ScheduledCallRepository,
+	contactRepo database.CampaignContactRepository,
+	businessRepo database.BusinessRepository,
+	log *logger.Logger,
+) *ScheduledCallService {
+	return &ScheduledCallService{
+		scheduledRepo: scheduledRepo,
+		contactRepo:   contactRepo,
+		businessRepo:  businessRepo,
+		logger:        log,
+		now:           time.Now,
+	}
+}
+
+func (s *ScheduledCallService) CreateScheduledCall(ctx context.Context, businessID, userID string, req dto.CreateScheduledCallRequest) (*dto.ScheduledCallResponse, error) {
+	if req.PhoneNumber == "" {
+		return nil, errors.NewFieldValidationError("phone_number", "phone_number is required")
+	}
+
+	business, err := s.businessRepo.GetByID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, errors.NewNotFoundError("business", businessID)
+	}
+
+	phoneNumber, err := normalizePhone("phone_number", req.PhoneNumber, business.DefaultRegion())
+	if err != nil {
+		return nil, err
+	}
+
+	scheduledFor, err := s.parseScheduledFor(req.ScheduledFor)
+	if err != nil {
+		return nil, err
+	}
+
+	assistantID := req.AssistantID
+	if assistantID == "" {
+		assistantID = business.AssistantID()
+	}
+
+	scheduled, err := entities.NewScheduledCall(businessID, phoneNumber, assistantID, req.Reason,
+		entities.ScheduledCallSourceManual, scheduledFor)
+	if err != nil {
+		return nil, err
+	}
+	if userID != "" {
+		scheduled.CreatedBy = &userID
+	}
+
+	if err := s.scheduledRepo.Create(ctx, scheduled); err != nil {
+		s.logger.Error("Failed to create scheduled call", err, map[string]interface{}{
+			"business_id": businessID,
+		})
+		return nil, err
+	}
+
+	return mapScheduledCallToResponse(scheduled), nil
+}
+
+func (s *ScheduledCallService) GetScheduledCall(ctx context.Context, businessID, id string) (*dto.ScheduledCallResponse, error) {
+	scheduled, err := s.getOwnedScheduledCall(ctx, businessID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return mapScheduledCallToResponse(scheduled), nil
+}
+
+// ListScheduledCalls lists a business's scheduled calls, optionally filtered by status
+func (s *ScheduledCallService) ListScheduledCalls(ctx context.Context, businessID, status string, limit, offset int) ([]*dto.ScheduledCallResponse, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	scheduledCalls, err := s.scheduledRepo.GetByBusinessID(ctx, businessID, entities.ScheduledCallStatus(status), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.ScheduledCallResponse, 0, len(scheduledCalls))
+	for _, scheduled := range scheduledCalls {
+		responses = append(responses, mapScheduledCallToResponse(scheduled))
+	}
+
+	return responses, nil
+}
+
+func (s *ScheduledCallService) CancelScheduledCall(ctx context.Context, businessID, id string) (*dto.ScheduledCallResponse, error) {
+	scheduled, err := s.getOwnedScheduledCall(ctx, businessID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := scheduled.Cancel(); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduledRepo.Update(ctx, scheduled); err != nil {
+		s.logger.Error("Failed to cancel scheduled call", err, map[string]interface{}{
+			"scheduled_call_id": scheduled.ID,
+		})
+		return nil, err
+	}
+
+	return mapScheduledCallToResponse(scheduled), nil
+}
+
+func (s *ScheduledCallService) RescheduleCall(ctx context.Context, businessID, id string, req dto.RescheduleCallRequest) (*dto.ScheduledCallResponse, error) {
+	scheduledFor, err := s.parseScheduledFor(req.ScheduledFor)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled, err := s.getOwnedScheduledCall(ctx, businessID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := scheduled.Reschedule(scheduledFor); err != nil {
+		return nil, err
+	}
+
+	if err := s.scheduledRepo.Update(ctx, scheduled); err != nil {
+		s.logger.Error("Failed to reschedule call", err, map[string]interface{}{
+			"scheduled_call_id": scheduled.ID,
+		})
+		return nil, err
+	}
+
+	return mapScheduledCallToResponse(scheduled), nil
+}
+
+// HandleCallEnded books a callback when the business's auto-callback rule
+// applies to the call. Calls placed by a campaign or by an earlier callback
+// are left alone, as is a number that already has a call waiting.
+func (s *ScheduledCallService) HandleCallEnded(ctx context.Context, call *entities.Call) error {
+	business, err := s.businessRepo.GetByID(ctx, call.BusinessID)
+	if err != nil {
+		return err
+	}
+	if business == nil {
+		return errors.NewNotFoundError("business", call.BusinessID)
+	}
+
+	rule := business.AutoCallback()
+	if !rule.Applies(call) {
+		return nil
+	}
+
+	if !call.IsInbound() {
+		origin, err := s.scheduledRepo.GetByCallID(ctx, call.ID)
+		if err != nil {
+			return err
+		}
+		if origin != nil && origin.Source == entities.ScheduledCallSourceCallback {
+			return nil
+		}
+
+		inCampaign, err := s.contactRepo.HasCall(ctx, call.ID)
+		if err != nil {
+			return err
+		}
+		if inCampaign {
+			return nil
+		}
+	}
+
+	pending, err := s.scheduledRepo.HasPending(ctx, call.BusinessID, call.CallerPhone)
+	if err != nil {
+		return err
+	}
+	if pending {
+		return nil
+	}
+
+	endedAt := s.now()
+	if call.EndedAt != nil {
+		endedAt = *call.EndedAt
+	}
+
+	callback, err := entities.NewScheduledCall(call.BusinessID, call.CallerPhone, business.AssistantID(),
+		callbackReason(call), entities.ScheduledCallSourceCallback, rule.CallbackTime(endedAt))
+	if err != nil {
+		return err
+	}
+	callback.OriginCallID = &call.ID
+
+	if err := s.scheduledRepo.Create(ctx, callback); err != nil {
+		return err
+	}
+
+	s.logger.Info("Callback scheduled", map[string]interface{}{
+		"scheduled_call_id": callback.ID,
+		"origin_call_id":    call.ID,
+		"scheduled_for":     callback.ScheduledFor.Format(time.RFC3339),
+	})
+	return nil
+}
+
+func callbackReason(call *entities.Call) string {
+	switch {
+	case call.IsInbound():
+		return "Returning a missed call"
+	case call.Status == entities.CallStatusNoAnswer:
+		return "Previous call was not answered"
+	default:
+		return "Previous call dropped"
+	}
+}
+
+// parseScheduledFor reads a required RFC3339 time that must not be in the past
+func (s *ScheduledCallService) parseScheduledFor(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.NewFieldValidationError("scheduled_for", "scheduled_for is required")
+	}
+	scheduledFor, err := parseOptionalTime("scheduled_for", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if scheduledFor.Before(s.now()) {
+		return time.Time{}, errors.NewFieldValidationError("scheduled_for", "scheduled_for must be in the future")
+	}
+	return *scheduledFor, nil
+}
+
+func (s *ScheduledCallService) getOwnedScheduledCall(ctx context.Context, businessID, id string) (*entities.ScheduledCall, error) {
+	scheduled, err := s.scheduledRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if scheduled.BusinessID != businessID {
+		return nil, errors.NewForbiddenError("access denied to this scheduled call")
+	}
+
+	return scheduled, nil
+}
+
+func mapScheduledCallToResponse(scheduled *entities.ScheduledCall) *dto.ScheduledCallResponse {
+	return &dto.ScheduledCallResponse{
+		ID:           scheduled.ID,
+		BusinessID:   scheduled.BusinessID,
+		PhoneNumber:  scheduled.Phone,
+		AssistantID:  scheduled.AssistantID,
+		Reason:       scheduled.Reason,
+		Source:       string(scheduled.Source),
+		Status:       string(scheduled.Status),
+		ScheduledFor: scheduled.ScheduledFor.Format(time.RFC3339),
+		Attempts:     scheduled.Attempts,
+		LastError:    scheduled.LastError,
+		CallID:       scheduled.CallID,
+		OriginCallID: scheduled.OriginCallID,
+		CreatedBy:    scheduled.CreatedBy,
+		CreatedAt:    scheduled.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    scheduled.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,461 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// Scheduled call repository backed by a slice, kept in insertion order
+type testScheduledCallRepository struct {
+	scheduled []*entities.ScheduledCall
+}
+
+func (m *testScheduledCallRepository) Create(ctx context.Context, scheduled *entities.ScheduledCall) error {
+	scheduled.ID = "scheduled-" + strconv.Itoa(len(m.scheduled)+1)
+	m.scheduled = append(m.scheduled, scheduled)
+	return nil
+}
+
+func (m *testScheduledCallRepository) GetByID(ctx context.Context, id string) (*entities.ScheduledCall, error) {
+	for _, scheduled := range m.scheduled {
+		if scheduled.ID == id {
+			return scheduled, nil
+		}
+	}
+	return nil, domainerrors.NewNotFoundError("scheduled call", id)
+}
+
+func (m *testScheduledCallRepository) GetByBusinessID(ctx context.Context, businessID string, status entities.ScheduledCallStatus, limit, offset int) ([]*entities.ScheduledCall, error) {
+	var result []*entities.ScheduledCall
+	for _, scheduled := range m.scheduled {
+		if scheduled.BusinessID == businessID && (status == "" || scheduled.Status == status) {
+			result = append(result, scheduled)
+		}
+	}
+	return result, nil
+}
+
+func (m *testScheduledCallRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]*entities.ScheduledCall, error) {
+	var result []*entities.ScheduledCall
+	for _, scheduled := range m.scheduled {
+		if scheduled.IsDue(now) && len(result) < limit {
+			result = append(result, scheduled)
+		}
+	}
+	return result, nil
+}
+
+func (m *testScheduledCallRepository) GetByCallID(ctx context.Context, callID string) (*entities.ScheduledCall, error) {
+	for _, scheduled := range m.scheduled {
+		if scheduled.CallID != nil && *scheduled.CallID == callID {
+			return scheduled, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *testScheduledCallRepository) HasPending(ctx context.Context, businessID, phone string) (bool, error) {
+	for _, scheduled := range m.scheduled {
+		if scheduled.BusinessID == businessID && scheduled.Phone == phone && scheduled.Status == entities.ScheduledCallStatusScheduled {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *testScheduledCallRepository) Update(ctx context.Context, scheduled *entities.ScheduledCall) error {
+	return nil
+}
+
+func newTestScheduledCallService(settings entities.BusinessSettings, now time.Time) (*ScheduledCallService, *testScheduledCallRepository, *mockCampaignContactRepository) {
+	scheduledRepo := &testScheduledCallRepository{}
+	contactRepo := &mockCampaignContactRepository{}
+	businessRepo := &mockBusinessRepository{businesses: map[string]*entities.Business{
+		"business-123": {
+			ID:       "business-123",
+			Name:     "Test Clinic",
+			Type:     "dentist",
+			Phone:    "+15551230000",
+			Settings: settings,
+		},
+	}}
+
+	service := NewScheduledCallService(scheduledRepo, contactRepo, businessRepo, logger.New("info", "console"))
+	service.now = func() time.Time { return now }
+
+	return service, scheduledRepo, contactRepo
+}
+
+func TestScheduledCallService_HandleCallEnded(t *testing.T) {
+	now := time.Date(2024, 3, 12, 15, 0, 0, 0, time.UTC)
+	enabled := testSettings(func(s *entities.BusinessSettings) {
+		s.AssistantID = "assistant-1"
+		s.AutoCallback.Enabled = true
+		s.AutoCallback.DelayMinutes = 20
+	})
+
+	endedCall := func(direction entities.CallDirection, status entities.CallStatus, connectedFor time.Duration) *entities.Call {
+		call, _ := entities.NewCall("business-123", "+15551234567")
+		call.ID = "call-1"
+		call.Direction = direction
+		call.Status = status
+		ended := now
+		call.EndedAt = &ended
+		if connectedFor > 0 {
+			started := now.Add(-connectedFor)
+			call.StartedAt = &started
+			call.Duration = int(connectedFor.Seconds())
+		}
+		return call
+	}
+
+	tests := []struct {
+		name         string
+		settings     entities.BusinessSettings
+		call         *entities.Call
+		setup        func(scheduled *testScheduledCallRepository, contacts *mockCampaignContactRepository)
+		wantCallback bool
+	}{
+		{
+			name:         "outbound call not answered",
+			settings:     enabled,
+			call:         endedCall(entities.CallDirectionOutbound, entities.CallStatusNoAnswer, 0),
+			wantCallback: true,
+		},
+		{
+			name:         "outbound call failed before connecting",
+			settings:     enabled,
+			call:         endedCall(entities.CallDirectionOutbound, entities.CallStatusFailed, 0),
+			wantCallback: true,
+		},
+		{
+			name:         "outbound call failed after a conversation",
+			settings:     enabled,
+			call:         endedCall(entities.CallDirectionOutbound, entities.CallStatusFailed, 3*time.Minute),
+			wantCallback: false,
+		},
+		{
+			name:         "outbound call completed",
+			settings:     enabled,
+			call:         endedCall(entities.CallDirectionOutbound, entities.CallStatusCompleted, 5*time.Second),
+			wantCallback: false,
+		},
+		{
+			name:         "inbound caller hung up early",
+			settings:     enabled,
+			call:         endedCall(entities.CallDirectionInbound, entities.CallStatusCompleted, 5*time.Second),
+			wantCallback: true,
+		},
+		{
+			name:         "inbound call failed",
+			settings:     enabled,
+			call:         endedCall(entities.CallDirectionInbound, entities.CallStatusFailed, 0),
+			wantCallback: true,
+		},
+		{
+			name:         "rule disabled",
+			settings:     entities.DefaultBusinessSettings(),
+			call:         endedCall(entities.CallDirectionOutbound, entities.CallStatusNoAnswer, 0),
+			wantCallback: false,
+		},
+		{
+			name:     "call was itself a callback",
+			settings: enabled,
+			call:     endedCall(entities.CallDirectionOutbound, entities.CallStatusNoAnswer, 0),
+			setup: func(scheduled *testScheduledCallRepository, contacts *mockCampaignContactRepository) {
+				callback, _ := entities.NewScheduledCall("business-123", "+15551234567", "", "", entities.ScheduledCallSourceCallback, now.Add(-time.Hour))
+				callback.MarkInitiated("call-1")
+				scheduled.Create(context.Background(), callback)
+			},
+			wantCallback: false,
+		},
+		{
+			name:     "campaign call",
+			settings: enabled,
+			call:     endedCall(entities.CallDirectionOutbound, entities.CallStatusNoAnswer, 0),
+			setup: func(scheduled *testScheduledCallRepository, contacts *mockCampaignContactRepository) {
+				contact, _ := entities.NewCampaignContact("campaign-1", "+15551234567", "", nil)
+				contact.RecordAttempt("call-1")
+				contacts.CreateBatch(context.Background(), []*entities.CampaignContact{contact})
+			},
+			wantCallback: false,
+		},
+		{
+			name:     "callback already pending",
+			settings: enabled,
+			call:     endedCall(entities.CallDirectionInbound, entities.CallStatusNoAnswer, 0),
+			setup: func(scheduled *testScheduledCallRepository, contacts *mockCampaignContactRepository) {
+				pending, _ := entities.NewScheduledCall("business-123", "+15551234567", "", "", entities.ScheduledCallSourceManual, now.Add(time.Hour))
+				scheduled.Create(context.Background(), pending)
+			},
+			wantCallback: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, scheduledRepo, contactRepo := newTestScheduledCallService(tt.settings, now)
+			if tt.setup != nil {
+				tt.setup(scheduledRepo, contactRepo)
+			}
+			before := len(scheduledRepo.scheduled)
+
+			if err := service.HandleCallEnded(context.Background(), tt.call); err != nil {
+				t.Fatalf("HandleCallEnded() error = %v", err)
+			}
+
+			created := scheduledRepo.scheduled[before:]
+			if got := len(created) == 1; got != tt.wantCallback {
+				t.Fatalf("expected callback = %v, got %d new scheduled calls", tt.wantCallback, len(created))
+			}
+			if !tt.wantCallback {
+				return
+			}
+
+			callback := created[0]
+			if callback.Source != entities.ScheduledCallSourceCallback {
+				t.Errorf("expected source %s, got %s", entities.ScheduledCallSourceCallback, callback.Source)
+			}
+			if want := now.Add(20 * time.Minute); !callback.ScheduledFor.Equal(want) {
+				t.Errorf("expected callback at %v, got %v", want, callback.ScheduledFor)
+			}
+			if callback.OriginCallID == nil || *callback.OriginCallID != "call-1" {
+				t.Errorf("expected origin call call-1, got %v", callback.OriginCallID)
+			}
+			if callback.AssistantID != "assistant-1" {
+				t.Errorf("expected assistant-1, got %s", callback.AssistantID)
+			}
+		})
+	}
+}
+
+func TestScheduledCallService_CreateAndReschedule(t *testing.T) {
+	now := time.Date(2024, 3, 12, 15, 0, 0, 0, time.UTC)
+	service, scheduledRepo, _ := newTestScheduledCallService(entities.DefaultBusinessSettings(), now)
+	ctx := context.Background()
+
+	if _, err := service.CreateScheduledCall(ctx, "business-123", "user-1", dto.CreateScheduledCallRequest{
+		PhoneNumber:  "(555) 123-4567",
+		ScheduledFor: now.Add(-time.Minute).Format(time.RFC3339),
+	}); err == nil {
+		t.Fatal("expected an error for a time in the past")
+	}
+
+	created, err := service.CreateScheduledCall(ctx, "business-123", "user-1", dto.CreateScheduledCallRequest{
+		PhoneNumber:  "(555) 123-4567",
+		ScheduledFor: now.Add(time.Hour).Format(time.RFC3339),
+		Reason:       "Follow up on quote",
+	})
+	if err != nil {
+		t.Fatalf("CreateScheduledCall() error = %v", err)
+	}
+	if created.PhoneNumber != "+15551234567" {
+		t.Errorf("expected normalised number, got %s", created.PhoneNumber)
+	}
+	if created.CreatedBy == nil || *created.CreatedBy != "user-1" {
+		t.Errorf("expected created_by user-1, got %v", created.CreatedBy)
+	}
+
+	later := now.Add(3 * time.Hour).Format(time.RFC3339)
+	rescheduled, err := service.RescheduleCall(ctx, "business-123", created.ID, dto.RescheduleCallRequest{ScheduledFor: later})
+	if err != nil {
+		t.Fatalf("RescheduleCall() error = %v", err)
+	}
+	if rescheduled.ScheduledFor != later {
+		t.Errorf("expected scheduled_for %s, got %s", later, rescheduled.ScheduledFor)
+	}
+
+	if _, err := service.GetScheduledCall(ctx, "business-456", created.ID); err == nil {
+		t.Error("expected another business to be denied access")
+	}
+
+	if _, err := service.CancelScheduledCall(ctx, "business-123", created.ID); err != nil {
+		t.Fatalf("CancelScheduledCall() error = %v", err)
+	}
+	if scheduledRepo.scheduled[0].Status != entities.ScheduledCallStatusCancelled {
+		t.Errorf("expected cancelled, got %s", scheduledRepo.scheduled[0].Status)
+	}
+	if _, err := service.RescheduleCall(ctx, "business-123", created.ID, dto.RescheduleCallRequest{ScheduledFor: later}); err == nil {
+		t.Error("expected a cancelled call not to be rescheduled")
+	}
+}
+
+func TestScheduledCallWorker_Tick(t *testing.T) {
+	now := time.Date(2024, 3, 12, 15, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	scheduledRepo := &testScheduledCallRepository{}
+	schedule := func(phone string, at time.Time) *entities.ScheduledCall {
+		scheduled, err := entities.NewScheduledCall("business-123", phone, "assistant-1", "Callback", entities.ScheduledCallSourceManual, at)
+		if err != nil {
+			t.Fatalf("NewScheduledCall() error = %v", err)
+		}
+		scheduledRepo.Create(ctx, scheduled)
+		return scheduled
+	}
+
+	due := schedule("+15551234567", now.Add(-time.Minute))
+	future := schedule("+12125551234", now.Add(time.Hour))
+	unreachable := schedule("+15559876543", now.Add(-time.Minute))
+
+	callRepo := newTestCallRepository()
+	callRepo.createFunc = func(ctx context.Context, call *entities.Call) error {
+		call.ID = "call-" + strconv.Itoa(len(callRepo.calls)+1)
+		callRepo.calls[call.ID] = call
+		return nil
+	}
+
+	var requests []providers.CallRequest
+	provider := &testVoiceProvider{
+		initiateCallFunc: func(ctx context.Context, req providers.CallRequest) (*providers.CallSession, error) {
+			if req.PhoneNumber == unreachable.Phone {
+				return nil, errors.New("provider unavailable")
+			}
+			requests = append(requests, req)
+			return &providers.CallSession{ID: "provider-1"}, nil
+		},
+	}
+
+	log := logger.New("info", "console")
+	callService := NewCallService(
+		callRepo,
+		newTestTranscriptRepository(),
+		newTestInteractionRepository(),
+		&mockBusinessRepository{businesses: make(map[string]*entities.Business)},
+		provider,
+		log,
+	)
+
+	worker := NewScheduledCallWorker(scheduledRepo, callService, log)
+	worker.now = func() time.Time { return now }
+
+	if err := worker.Tick(ctx); err != nil {
+		t.Fatalf("Tick() error = %v", err)
+	}
+
+	if len(requests) != 1 || requests[0].PhoneNumber != due.Phone {
+		t.Fatalf("expected only the due call to be placed, got %+v", requests)
+	}
+	if requests[0].Metadata["scheduled_call_id"] != due.ID {
+		t.Errorf("expected scheduled_call_id metadata, got %v", requests[0].Metadata)
+	}
+	if requests[0].AssistantConfig == nil || requests[0].AssistantConfig.VariableValues["call_reason"] != "Callback" {
+		t.Errorf("expected call_reason variable, got %+v", requests[0].AssistantConfig)
+	}
+
+	if due.Status != entities.ScheduledCallStatusInitiated || due.CallID == nil {
+		t.Errorf("expected due call to be initiated with a call ID, got %s", due.Status)
+	}
+	if future.Status != entities.ScheduledCallStatusScheduled {
+		t.Errorf("expected future call to stay scheduled, got %s", future.Status)
+	}
+
+	// A provider error is retried a few minutes later until attempts run out
+	if unreachable.Status != entities.ScheduledCallStatusScheduled || unreachable.Attempts != 1 {
+		t.Fatalf("expected a retry after the first failure, got status %s attempts %d", unreachable.Status, unreachable.Attempts)
+	}
+	for i := 0; i < 2; i++ {
+		now = now.Add(10 * time.Minute)
+		worker.Tick(ctx)
+	}
+	if unreachable.Status != entities.ScheduledCallStatusFailed {
+		t.Errorf("expected failed after repeated errors, got %s", unreachable.Status)
+	}
+}
+
+func TestScheduledCallWorker_SkipsDoNotCall(t *testing.T) {
+	now := time.Date(2024, 3, 12, 18, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	compliance, dncRepo, _ := newTestComplianceService(entities.DefaultBusinessSettings(), now)
+	entry, _ := entities.NewDoNotCallEntry(nil, "+15551234567", entities.DoNotCallSourceManual, "")
+	dncRepo.Create(ctx, entry)
+
+	scheduledRepo := &testScheduledCallRepository{}
+	scheduled, _ := entities.NewScheduledCall("business-123", "+15551234567", "", "", entities.ScheduledCallSourceCallback, now.Add(-time.Minute))
+	scheduledRepo.Create(ctx, scheduled)
+
+	provider := &testVoiceProvider{
+		initiateCallFunc: func(ctx context.Context, req providers.CallRequest) (*providers.CallSession, error) {
+			t.Fatal("expected no call to a number on the do-not-call list")
+			return nil, nil
+		},
+	}
+
+	log := logger.New("info", "console")
+	callService := NewCallService(
+		newTestCallRepository(),
+		newTestTranscriptRepository(),
+		newTestInteractionRepository(),
+		&mockBusinessRepository{businesses: make(map[string]*entities.Business)},
+		provider,
+		log,
+	)
+	callService.SetCompliance(compliance)
+
+	worker := NewScheduledCallWorker(scheduledRepo, callService, log)
+	worker.now = func() time.Time { return now }
+
+	if err := worker.Tick(ctx); err != nil {
+		t.Fatalf("Tick() error = %v", err)
+	}
+	if scheduled.Status != entities.ScheduledCallStatusFailed {
+		t.Errorf("expected failed, got %s", scheduled.Status)
+	}
+}
+
+func TestCallService_SchedulesCallbackWhenCallEnds(t *testing.T) {
+	now := time.Date(2024, 3, 12, 15, 0, 0, 0, time.UTC)
+	callbacks, scheduledRepo, _ := newTestScheduledCallService(testSettings(func(s *entities.BusinessSettings) {
+		s.AutoCallback.Enabled = true
+	}), now)
+
+	callRepo := newTestCallRepository()
+	call, _ := entities.NewCall("business-123", "+15551234567")
+	call.ID = "call-123"
+	call.ProviderCallID = "provider-123"
+	callRepo.calls[call.ID] = call
+
+	provider := &testVoiceProvider{
+		handleWebhookFunc: func(ctx context.Context, payload []byte, signature string) (*providers.CallEvent, error) {
+			return &providers.CallEvent{
+				Type:    providers.CallEventTypeEndOfCallReport,
+				CallID:  "provider-123",
+				Outcome: providers.CallOutcomeNoAnswer,
+			}, nil
+		},
+	}
+
+	service := NewCallService(
+		callRepo,
+		newTestTranscriptRepository(),
+		newTestInteractionRepository(),
+		&mockBusinessRepository{businesses: make(map[string]*entities.Business)},
+		provider,
+		logger.New("info", "console"),
+	)
+	service.SetCallbacks(callbacks)
+
+	// The provider may report the end of a call more than once
+	for i := 0; i < 2; i++ {
+		if err := service.HandleWebhook(context.Background(), []byte(`{}`), "valid-signature"); err != nil {
+			t.Fatalf("HandleWebhook() error = %v", err)
+		}
+	}
+
+	if len(scheduledRepo.scheduled) != 1 {
+		t.Fatalf("expected one callback, got %d", len(scheduledRepo.scheduled))
+	}
+	if scheduledRepo.scheduled[0].Phone != "+15551234567" {
+		t.Errorf("expected callback to the caller, got %s", scheduledRepo.scheduled[0].Phone)
+	}
+}
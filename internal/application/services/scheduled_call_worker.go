@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+const (
+	defaultScheduledCallInterval = 30 * time.Second
+	// scheduledCallBatchSize bounds how many due calls are placed per tick
+	scheduledCallBatchSize = 50
+)
+
+// ScheduledCallWorker places scheduled calls and callbacks once they are due
+type ScheduledCallWorker struct {
+	scheduledRepo database.ScheduledCallRepository
+	callService   *CallService
+	logger        *logger.Logger
+
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewScheduledCallWorker(
+	scheduledRepo database.ScheduledCallRepository,
+	callService *CallService,
+	log *logger.Logger,
+) *ScheduledCallWorker {
+	return &ScheduledCallWorker{
+		scheduledRepo: scheduledRepo,
+		callService:   callService,
+		logger:        log,
+		interval:      defaultScheduledCallInterval,
+		now:           time.Now,
+	}
+}
+
+// SetInterval changes how often the worker looks for due calls
+func (w *ScheduledCallWorker) SetInterval(interval time.Duration) {
+	if interval > 0 {
+		w.interval = interval
+	}
+}
+
+// Run places due calls until the context is cancelled
+func (w *ScheduledCallWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.logger.Info("Scheduled call worker started", map[string]interface{}{
+		"interval": w.interval.String(),
+	})
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("Scheduled call worker stopped", nil)
+			return
+		case <-ticker.C:
+			if err := w.Tick(ctx); err != nil {
+				w.logger.Error("Scheduled call worker tick failed", err, nil)
+			}
+		}
+	}
+}
+
+// Tick places every call that is due. It is not safe to call concurrently.
+func (w *ScheduledCallWorker) Tick(ctx context.Context) error {
+	// The worker places calls for every business
+	ctx = database.Unscoped(ctx)
+	now := w.now()
+
+	due, err := w.scheduledRepo.GetDue(ctx, now, scheduledCallBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, scheduled := range due {
+		w.place(ctx, scheduled, now)
+	}
+
+	return nil
+}
+
+func (w *ScheduledCallWorker) place(ctx context.Context, scheduled *entities.ScheduledCall, now time.Time) {
+	if compliance := w.callService.compliance; compliance != nil {
+		verdict, err := compliance.evaluate(ctx, scheduled.BusinessID, scheduled.Phone)
+		if err != nil {
+			w.logger.Error("Failed to check scheduled call compliance", err, map[string]interface{}{
+				"scheduled_call_id": scheduled.ID,
+			})
+			return
+		}
+		if !verdict.allowed() {
+			if verdict.outsideWindow {
+				scheduled.Defer(verdict.reason, now.Add(outsideWindowDelay))
+			} else {
+				scheduled.MarkFailed(verdict.reason)
+			}
+			w.update(ctx, scheduled)
+			return
+		}
+	}
+
+	variables := map[string]interface{}{}
+	if scheduled.Reason != "" {
+		variables["call_reason"] = scheduled.Reason
+	}
+
+	call, err := w.callService.InitiateCall(ctx, scheduled.BusinessID, dto.InitiateCallRequest{
+		PhoneNumber: scheduled.Phone,
+		AssistantID: scheduled.AssistantID,
+		Metadata: map[string]interface{}{
+			"scheduled_call_id": scheduled.ID,
+			"source":            string(scheduled.Source),
+		},
+		Variables: variables,
+	})
+
+	var domainErr *errors.DomainError
+	switch {
+	case err != nil && stderrors.As(err, &domainErr) && domainErr.Code == errors.ErrCodeComplianceBlock:
+		scheduled.MarkFailed(domainErr.Message)
+	case err != nil:
+		w.logger.Warn("Scheduled call failed to start", map[string]interface{}{
+			"scheduled_call_id": scheduled.ID,
+			"error":             err.Error(),
+		})
+		scheduled.RecordDialError(err.Error(), now)
+	default:
+		scheduled.MarkInitiated(call.ID)
+	}
+
+	w.update(ctx, scheduled)
+}
+
+func (w *ScheduledCallWorker) update(ctx context.Context, scheduled *entities.ScheduledCall) {
+	if err := w.scheduledRepo.Update(ctx, scheduled); err != nil {
+		w.logger.Error("Failed to update scheduled call", err, map[string]interface{}{
+			"scheduled_call_id": scheduled.ID,
+		})
+	}
+}
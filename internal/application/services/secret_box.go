@@ -0,0 +1,69 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/CallPilotReceptionist/pkg/config"
+)
+
+// secretBox seals secrets the backend has to read back, such as TOTP secrets
+// and identity provider client secrets, with AES-GCM
+type secretBox struct {
+	key []byte
+}
+
+// newSecretBox derives the key from MFA.EncryptionKey, which config
+// validation requires
+func newSecretBox(cfg *config.Config) *secretBox {
+	key := sha256.Sum256([]byte(cfg.MFA.EncryptionKey))
+	return &secretBox{key: key[:]}
+}
+
+func (b *secretBox) encrypt(plaintext string) (string, error) {
+	gcm, err := b.cipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (b *secretBox) decrypt(encoded string) (string, error) {
+	gcm, err := b.cipher()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("encrypted secret is too short")
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func (b *secretBox) cipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(b.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
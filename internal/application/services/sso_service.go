@@ -0,0 +1,482 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/config"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// ssoLoginTTL is how long a user has to sign in at their identity provider
+const ssoLoginTTL = 10 * time.Minute
+
+// SSOService signs staff in through their business's OpenID Connect identity
+// provider and exchanges the result for our own tokens. Users signing in for
+// the first time are created with the connection's default role; existing
+// accounts sign in once their owner has linked them.
+type SSOService struct {
+	ssoRepo     database.SSORepository
+	userRepo    database.UserRepository
+	auth        *AuthService
+	client      providers.OIDCClient
+	secrets     *secretBox
+	redirectURL string
+	logger      *logger.Logger
+
+	audit *AuditService
+
+	now func() time.Time
+}
+
+func NewSSOService(
+	ssoRepo database.SSORepository,
+	userRepo database.UserRepository,
+	auth *AuthService,
+	client providers.OIDCClient,
+	cfg *config.Config,
+	log *logger.Logger,
+) *SSOService {
+	return &SSOService{
+		ssoRepo:     ssoRepo,
+		userRepo:    userRepo,
+		auth:        auth,
+		client:      client,
+		secrets:     newSecretBox(cfg),
+		redirectURL: cfg.SSO.RedirectURL,
+		logger:      log,
+		now:         time.Now,
+	}
+}
+
+// SetAudit records changes to the business's identity provider in the audit
+// log
+func (s *SSOService) SetAudit(audit *AuditService) {
+	s.audit = audit
+}
+
+// GetConnection returns the business's identity provider settings. The
+// client secret is never returned.
+func (s *SSOService) GetConnection(ctx context.Context, businessID string) (*dto.SSOConnectionResponse, error) {
+	connection, err := s.ssoRepo.GetConnection(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if connection == nil {
+		return nil, errors.NewNotFoundError("SSO connection", businessID)
+	}
+
+	return s.mapConnectionToResponse(connection), nil
+}
+
+// SaveConnection creates or updates the business's identity provider
+func (s *SSOService) SaveConnection(ctx context.Context, businessID string, req dto.SSOConnectionRequest) (*dto.SSOConnectionResponse, error) {
+	connection, err := s.ssoRepo.GetConnection(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedSecret := ""
+	if req.ClientSecret != "" {
+		if encryptedSecret, err = s.secrets.encrypt(req.ClientSecret); err != nil {
+			return nil, errors.NewInternalError(err)
+		}
+	}
+
+	var before map[string]interface{}
+	if connection != nil {
+		before = ssoAuditFields(connection)
+	}
+
+	role := entities.UserRole(req.DefaultRole)
+	if connection == nil {
+		if connection, err = entities.NewSSOConnection(businessID, req.Issuer, req.ClientID, encryptedSecret, req.AllowedDomains, role); err != nil {
+			return nil, err
+		}
+		if req.Enabled != nil {
+			connection.Enabled = *req.Enabled
+		}
+	} else {
+		enabled := connection.Enabled
+		if req.Enabled != nil {
+			enabled = *req.Enabled
+		}
+		if err := connection.Update(req.Issuer, req.ClientID, encryptedSecret, req.AllowedDomains, role, enabled); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.ssoRepo.SaveConnection(ctx, connection); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("SSO connection saved", map[string]interface{}{
+		"business_id": businessID,
+		"issuer":      connection.Issuer,
+		"enabled":     connection.Enabled,
+	})
+
+	changes := entities.DiffFields(before, ssoAuditFields(connection))
+	if encryptedSecret != "" {
+		// Never log the secret itself
+		changes["client_secret"] = entities.AuditChange{After: "[changed]"}
+	}
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionSSOSaved,
+		TargetType: "sso_connection",
+		TargetID:   businessID,
+		Changes:    changes,
+	})
+
+	return s.mapConnectionToResponse(connection), nil
+}
+
+func (s *SSOService) DeleteConnection(ctx context.Context, businessID string) error {
+	if err := s.ssoRepo.DeleteConnection(ctx, businessID); err != nil {
+		return err
+	}
+
+	s.logger.Info("SSO connection deleted", map[string]interface{}{
+		"business_id": businessID,
+	})
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionSSODeleted,
+		TargetType: "sso_connection",
+		TargetID:   businessID,
+	})
+
+	return nil
+}
+
+// StartLogin returns the identity provider URL to send the user to. The PKCE
+// verifier and nonce stay on the server, keyed by the state the provider
+// hands back.
+func (s *SSOService) StartLogin(ctx context.Context, req dto.SSOStartRequest) (*dto.SSOStartResponse, error) {
+	if req.BusinessID == "" {
+		return nil, errors.NewValidationError("business_id is required")
+	}
+
+	return s.start(ctx, req.BusinessID, "")
+}
+
+// StartLink starts a sign-in at the business's identity provider that links
+// the account there to the signed-in user. This is how someone who already
+// has an account opts in to signing in with single sign-on.
+func (s *SSOService) StartLink(ctx context.Context, userID, businessID string) (*dto.SSOStartResponse, error) {
+	if userID == "" {
+		return nil, errors.NewForbiddenError("only users can link single sign-on")
+	}
+
+	member, err := s.userRepo.GetMember(ctx, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || !member.IsActive() {
+		return nil, errors.NewForbiddenError("you are not a member of this business")
+	}
+
+	return s.start(ctx, businessID, userID)
+}
+
+func (s *SSOService) start(ctx context.Context, businessID, userID string) (*dto.SSOStartResponse, error) {
+	connection, provider, err := s.getProvider(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	state, stateHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	verifier, _, err := newOpaqueToken()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	nonce, _, err := newOpaqueToken()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	authorizationURL, err := s.client.AuthorizationURL(ctx, provider, providers.OIDCAuthRequest{
+		RedirectURI:   s.redirectURL,
+		State:         state,
+		Nonce:         nonce,
+		CodeChallenge: codeChallenge(verifier),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	loginState, err := entities.NewSSOLoginState(connection.BusinessID, stateHash, verifier, nonce, s.now().Add(ssoLoginTTL))
+	if err != nil {
+		return nil, err
+	}
+	loginState.UserID = userID
+	if err := s.ssoRepo.CreateLoginState(ctx, loginState); err != nil {
+		return nil, err
+	}
+
+	return &dto.SSOStartResponse{AuthorizationURL: authorizationURL}, nil
+}
+
+// CompleteLogin redeems the code the identity provider redirected back with
+// and signs the user linked to the provider's account in, creating them if
+// this is their first sign-in. A sign-in started with StartLink links the
+// account first. The session cannot switch to the user's other businesses.
+func (s *SSOService) CompleteLogin(ctx context.Context, req dto.SSOCallbackRequest) (*dto.LoginResponse, error) {
+	if req.State == "" || req.Code == "" {
+		return nil, errors.NewValidationError("state and code are required")
+	}
+
+	loginState, err := s.ssoRepo.ConsumeLoginState(ctx, hashToken(req.State))
+	if err != nil {
+		return nil, err
+	}
+	if loginState == nil || loginState.IsExpired(s.now()) {
+		return nil, errors.NewUnauthorizedError("invalid or expired sign-in")
+	}
+
+	connection, provider, err := s.getProvider(ctx, loginState.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := s.client.Exchange(ctx, provider, providers.OIDCTokenRequest{
+		Code:         req.Code,
+		CodeVerifier: loginState.CodeVerifier,
+		RedirectURI:  s.redirectURL,
+		Nonce:        loginState.Nonce,
+	})
+	if err != nil {
+		s.logger.Warn("SSO code exchange failed", map[string]interface{}{
+			"business_id": connection.BusinessID,
+			"error":       err.Error(),
+		})
+		return nil, err
+	}
+
+	email := strings.TrimSpace(identity.Email)
+	if email == "" || !identity.EmailVerified {
+		return nil, errors.NewUnauthorizedError("the identity provider did not confirm your email address")
+	}
+	if !connection.AllowsEmail(email) {
+		s.logger.Warn("SSO sign-in from a domain that is not allowed", map[string]interface{}{
+			"business_id": connection.BusinessID,
+			"email":       email,
+		})
+		return nil, errors.NewForbiddenError("your email domain is not allowed to sign in to this business")
+	}
+
+	var user *entities.User
+	if loginState.UserID != "" {
+		user, err = s.linkIdentity(ctx, connection, identity, loginState.UserID)
+	} else {
+		user, err = s.findOrProvisionUser(ctx, connection, identity, email)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("User signed in with SSO", map[string]interface{}{
+		"user_id":     user.ID,
+		"business_id": user.BusinessID,
+		"issuer":      identity.Issuer,
+		"subject":     identity.Subject,
+	})
+
+	return s.auth.SignInWithSSO(ctx, user)
+}
+
+// findOrProvisionUser returns the member of the connection's business linked
+// to the provider's account, creating them with the connection's default
+// role if nobody has an account with the email address. The address alone
+// never signs in an existing account: its owner has to link it first.
+func (s *SSOService) findOrProvisionUser(ctx context.Context, connection *entities.SSOConnection, identity *providers.OIDCIdentity, email string) (*entities.User, error) {
+	linked, err := s.ssoRepo.GetIdentity(ctx, connection.BusinessID, identity.Issuer, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if linked != nil {
+		return s.getMember(ctx, connection.BusinessID, linked.UserID)
+	}
+
+	existing, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		s.logger.Warn("SSO sign-in for an account that is not linked", map[string]interface{}{
+			"business_id": connection.BusinessID,
+			"user_id":     existing.ID,
+			"issuer":      identity.Issuer,
+		})
+		return nil, errors.NewForbiddenError("an account with this email address already exists; sign in with your password and link single sign-on to it")
+	}
+
+	// The user signs in through their identity provider, so they get a
+	// random password nobody knows
+	password, _, err := newOpaqueToken()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+	hashedPassword, err := database.HashPassword(password)
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	user, err := entities.NewUser(connection.BusinessID, email, hashedPassword, connection.DefaultRole)
+	if err != nil {
+		return nil, err
+	}
+	user.VerifyEmail(s.now())
+
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		s.logger.Error("Failed to provision SSO user", err, map[string]interface{}{
+			"business_id": connection.BusinessID,
+			"email":       email,
+		})
+		return nil, err
+	}
+
+	link, err := entities.NewSSOIdentity(connection.BusinessID, identity.Issuer, identity.Subject, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ssoRepo.CreateIdentity(ctx, link); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("User provisioned by SSO", map[string]interface{}{
+		"user_id":     user.ID,
+		"business_id": user.BusinessID,
+		"role":        user.Role,
+	})
+
+	return user, nil
+}
+
+// linkIdentity links the provider's account to the user who started the
+// sign-in with StartLink
+func (s *SSOService) linkIdentity(ctx context.Context, connection *entities.SSOConnection, identity *providers.OIDCIdentity, userID string) (*entities.User, error) {
+	member, err := s.getMember(ctx, connection.BusinessID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	linked, err := s.ssoRepo.GetIdentity(ctx, connection.BusinessID, identity.Issuer, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if linked != nil {
+		if linked.UserID != member.ID {
+			return nil, errors.NewForbiddenError("this identity provider account is linked to another user")
+		}
+		return member, nil
+	}
+
+	link, err := entities.NewSSOIdentity(connection.BusinessID, identity.Issuer, identity.Subject, member.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ssoRepo.CreateIdentity(ctx, link); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("SSO identity linked", map[string]interface{}{
+		"user_id":     member.ID,
+		"business_id": connection.BusinessID,
+		"issuer":      identity.Issuer,
+	})
+
+	s.audit.Record(withAuditUser(ctx, member.ID), AuditEvent{
+		BusinessID: connection.BusinessID,
+		Action:     entities.AuditActionSSOLinked,
+		TargetType: "user",
+		TargetID:   member.ID,
+		Metadata: map[string]interface{}{
+			"issuer": identity.Issuer,
+		},
+	})
+
+	return member, nil
+}
+
+// getMember returns the user as an active member of the business
+func (s *SSOService) getMember(ctx context.Context, businessID, userID string) (*entities.User, error) {
+	member, err := s.userRepo.GetMember(ctx, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errors.NewForbiddenError("this account is not a member of the business")
+	}
+	if !member.IsActive() {
+		return nil, errors.NewUnauthorizedError("user is deactivated")
+	}
+	return member, nil
+}
+
+// getProvider loads the business's enabled connection with its client
+// secret decrypted
+func (s *SSOService) getProvider(ctx context.Context, businessID string) (*entities.SSOConnection, providers.OIDCProvider, error) {
+	connection, err := s.ssoRepo.GetConnection(ctx, businessID)
+	if err != nil {
+		return nil, providers.OIDCProvider{}, err
+	}
+	if connection == nil || !connection.Enabled {
+		return nil, providers.OIDCProvider{}, errors.NewValidationError("single sign-on is not enabled for this business")
+	}
+
+	secret, err := s.secrets.decrypt(connection.EncryptedClientSecret)
+	if err != nil {
+		return nil, providers.OIDCProvider{}, errors.NewInternalError(err)
+	}
+
+	return connection, providers.OIDCProvider{
+		Issuer:       connection.Issuer,
+		ClientID:     connection.ClientID,
+		ClientSecret: secret,
+	}, nil
+}
+
+func (s *SSOService) mapConnectionToResponse(connection *entities.SSOConnection) *dto.SSOConnectionResponse {
+	return &dto.SSOConnectionResponse{
+		Issuer:         connection.Issuer,
+		ClientID:       connection.ClientID,
+		AllowedDomains: connection.AllowedDomains,
+		DefaultRole:    string(connection.DefaultRole),
+		Enabled:        connection.Enabled,
+		RedirectURI:    s.redirectURL,
+		CreatedAt:      connection.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      connection.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// codeChallenge returns the S256 PKCE challenge for a verifier
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// ssoAuditFields returns the audited settings of a connection, leaving out
+// the client secret
+func ssoAuditFields(connection *entities.SSOConnection) map[string]interface{} {
+	return map[string]interface{}{
+		"issuer":          connection.Issuer,
+		"client_id":       connection.ClientID,
+		"allowed_domains": append([]string(nil), connection.AllowedDomains...),
+		"default_role":    string(connection.DefaultRole),
+		"enabled":         connection.Enabled,
+	}
+}
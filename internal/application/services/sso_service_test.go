@@ -0,0 +1,614 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/oidc"
+	"github.com/CallPilotReceptionist/internal/infrastructure/oidc/oidctest"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// newMockSSOService connects business-123 to idp for example.com addresses.
+// The stand-in provider listens on loopback, so private networks are
+// allowed.
+func newMockSSOService(t *testing.T, ssoRepo *mockSSORepository, users *mockUserRepository, auth *AuthService, idp *oidctest.Server) *SSOService {
+	t.Helper()
+
+	client := oidc.NewClient()
+	client.AllowPrivateNetworks()
+	service := NewSSOService(ssoRepo, users, auth, client, testAuthConfig(), logger.New("info", "console"))
+
+	_, err := service.SaveConnection(context.Background(), "business-123", dto.SSOConnectionRequest{
+		Issuer:         idp.Issuer(),
+		ClientID:       idp.ClientID,
+		ClientSecret:   idp.ClientSecret,
+		AllowedDomains: []string{"example.com"},
+	})
+	if err != nil {
+		t.Fatalf("SaveConnection() error = %v", err)
+	}
+	return service
+}
+
+// newMockSSOAuthService signs the team in, challenging users enrolled in
+// mfaRepo for a code
+func newMockSSOAuthService(users *mockUserRepository, mfaRepo *mockMFARepository) *AuthService {
+	businesses := newMockTeamBusinessRepository()
+	return newMockMFAAuthService(users, businesses, newMockMFAService(users, businesses, mfaRepo))
+}
+
+// ssoLogin signs user in at the identity provider and completes the sign-in.
+// With a linkingUserID the signed-in user links the provider account
+// instead.
+func ssoLogin(t *testing.T, service *SSOService, idp *oidctest.Server, linkingUserID string, user oidctest.User) (*dto.LoginResponse, error) {
+	t.Helper()
+	idp.SignInAs(user)
+
+	var start *dto.SSOStartResponse
+	var err error
+	if linkingUserID != "" {
+		start, err = service.StartLink(context.Background(), linkingUserID, "business-123")
+	} else {
+		start, err = service.StartLogin(context.Background(), dto.SSOStartRequest{BusinessID: "business-123"})
+	}
+	if err != nil {
+		t.Fatalf("starting the sign-in failed: %v", err)
+	}
+	code, state, err := idp.Authorize(start.AuthorizationURL)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+
+	return service.CompleteLogin(context.Background(), dto.SSOCallbackRequest{State: state, Code: code})
+}
+
+// linkIdentity links the provider account subject to userID
+func linkIdentity(ssoRepo *mockSSORepository, idp *oidctest.Server, subject, userID string) {
+	identity, _ := entities.NewSSOIdentity("business-123", idp.Issuer(), subject, userID)
+	ssoRepo.CreateIdentity(context.Background(), identity)
+}
+
+func TestSSOService_CompleteLogin(t *testing.T) {
+	jane := oidctest.User{Subject: "idp-jane", Email: "jane@example.com", EmailVerified: true, Name: "Jane"}
+	employee := oidctest.User{Subject: "idp-employee", Email: "employee@example.com", EmailVerified: true}
+
+	tests := []struct {
+		name          string
+		user          oidctest.User
+		linkingUserID string
+		setupMocks    func(users *mockUserRepository, ssoRepo *mockSSORepository, mfaRepo *mockMFARepository, idp *oidctest.Server)
+		wantCode      string
+		validate      func(t *testing.T, auth *AuthService, response *dto.LoginResponse, users *mockUserRepository, ssoRepo *mockSSORepository)
+	}{
+		{
+			name: "new user is provisioned",
+			user: jane,
+			validate: func(t *testing.T, auth *AuthService, response *dto.LoginResponse, users *mockUserRepository, ssoRepo *mockSSORepository) {
+				if response.AccessToken == "" || response.RefreshToken == "" {
+					t.Fatalf("expected tokens, got %+v", response)
+				}
+				claims, err := auth.ValidateToken(response.AccessToken)
+				if err != nil {
+					t.Fatalf("ValidateToken() error = %v", err)
+				}
+				if claims.BusinessID != "business-123" || claims.Role != string(entities.UserRoleEmployee) || claims.Unverified {
+					t.Errorf("unexpected claims %+v", claims)
+				}
+				if len(ssoRepo.identities) != 1 {
+					t.Errorf("expected the provider account to be linked, got %d identities", len(ssoRepo.identities))
+				}
+			},
+		},
+		{
+			name: "provisioned user signs in again",
+			user: jane,
+			setupMocks: func(users *mockUserRepository, ssoRepo *mockSSORepository, mfaRepo *mockMFARepository, idp *oidctest.Server) {
+				users.users["user-jane"] = &entities.User{ID: "user-jane", BusinessID: "business-123", Email: "jane@example.com", Role: entities.UserRoleEmployee}
+				linkIdentity(ssoRepo, idp, "idp-jane", "user-jane")
+			},
+			validate: func(t *testing.T, auth *AuthService, response *dto.LoginResponse, users *mockUserRepository, ssoRepo *mockSSORepository) {
+				if response.User.ID != "user-jane" || len(users.users) != 5 {
+					t.Error("expected the sign-in to reuse the provisioned user")
+				}
+			},
+		},
+		{
+			name: "linked user signs in",
+			user: employee,
+			setupMocks: func(users *mockUserRepository, ssoRepo *mockSSORepository, mfaRepo *mockMFARepository, idp *oidctest.Server) {
+				linkIdentity(ssoRepo, idp, "idp-employee", "user-employee")
+			},
+			validate: func(t *testing.T, auth *AuthService, response *dto.LoginResponse, users *mockUserRepository, ssoRepo *mockSSORepository) {
+				if response.User.ID != "user-employee" {
+					t.Errorf("expected the linked user, got %+v", response.User)
+				}
+			},
+		},
+		{
+			name:          "signed-in user links their account",
+			user:          employee,
+			linkingUserID: "user-employee",
+			validate: func(t *testing.T, auth *AuthService, response *dto.LoginResponse, users *mockUserRepository, ssoRepo *mockSSORepository) {
+				if response.User.ID != "user-employee" {
+					t.Errorf("expected the linking user to be signed in, got %+v", response.User)
+				}
+				if len(ssoRepo.identities) != 1 {
+					t.Errorf("expected the provider account to be linked, got %d identities", len(ssoRepo.identities))
+				}
+			},
+		},
+		{
+			name:          "users with two-factor authentication are challenged",
+			user:          oidctest.User{Subject: "idp-admin", Email: "admin@example.com", EmailVerified: true},
+			linkingUserID: "user-admin",
+			setupMocks: func(users *mockUserRepository, ssoRepo *mockSSORepository, mfaRepo *mockMFARepository, idp *oidctest.Server) {
+				enabledAt := time.Now()
+				mfaRepo.enrolments["user-admin"] = &entities.UserMFA{UserID: "user-admin", EnabledAt: &enabledAt}
+			},
+			validate: func(t *testing.T, auth *AuthService, response *dto.LoginResponse, users *mockUserRepository, ssoRepo *mockSSORepository) {
+				if !response.MFARequired || response.AccessToken != "" {
+					t.Errorf("expected a two-factor challenge, got %+v", response)
+				}
+			},
+		},
+		{
+			name:     "existing account is not signed in by its email address",
+			user:     employee,
+			wantCode: domainerrors.ErrCodeForbidden,
+			validate: func(t *testing.T, auth *AuthService, response *dto.LoginResponse, users *mockUserRepository, ssoRepo *mockSSORepository) {
+				if users.users["user-employee"].IsEmailVerified() {
+					t.Error("expected a refused sign-in not to verify the account's email address")
+				}
+			},
+		},
+		{
+			name: "another provider account with the same email address",
+			user: oidctest.User{Subject: "idp-impostor", Email: "employee@example.com", EmailVerified: true},
+			setupMocks: func(users *mockUserRepository, ssoRepo *mockSSORepository, mfaRepo *mockMFARepository, idp *oidctest.Server) {
+				linkIdentity(ssoRepo, idp, "idp-employee", "user-employee")
+			},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:          "account linked to another user",
+			user:          employee,
+			linkingUserID: "user-owner",
+			setupMocks: func(users *mockUserRepository, ssoRepo *mockSSORepository, mfaRepo *mockMFARepository, idp *oidctest.Server) {
+				linkIdentity(ssoRepo, idp, "idp-employee", "user-employee")
+			},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:     "unverified email",
+			user:     oidctest.User{Subject: "1", Email: "jane@example.com"},
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name:     "domain not allowed",
+			user:     oidctest.User{Subject: "1", Email: "jane@elsewhere.com", EmailVerified: true},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:     "user of another business",
+			user:     oidctest.User{Subject: "1", Email: "other@example.com", EmailVerified: true},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name: "deactivated user",
+			user: oidctest.User{Subject: "1", Email: "employee@example.com", EmailVerified: true},
+			setupMocks: func(users *mockUserRepository, ssoRepo *mockSSORepository, mfaRepo *mockMFARepository, idp *oidctest.Server) {
+				linkIdentity(ssoRepo, idp, "1", "user-employee")
+				users.users["user-employee"].Deactivate()
+			},
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name: "nonce mismatch",
+			user: oidctest.User{Subject: "1", Email: "jane@example.com", EmailVerified: true},
+			setupMocks: func(users *mockUserRepository, ssoRepo *mockSSORepository, mfaRepo *mockMFARepository, idp *oidctest.Server) {
+				idp.Tamper(func(claims jwt.MapClaims) { claims["nonce"] = "replayed" })
+			},
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name: "token for another client",
+			user: oidctest.User{Subject: "1", Email: "jane@example.com", EmailVerified: true},
+			setupMocks: func(users *mockUserRepository, ssoRepo *mockSSORepository, mfaRepo *mockMFARepository, idp *oidctest.Server) {
+				idp.Tamper(func(claims jwt.MapClaims) { claims["aud"] = "someone-else" })
+			},
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name: "expired token",
+			user: oidctest.User{Subject: "1", Email: "jane@example.com", EmailVerified: true},
+			setupMocks: func(users *mockUserRepository, ssoRepo *mockSSORepository, mfaRepo *mockMFARepository, idp *oidctest.Server) {
+				idp.Tamper(func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Minute).Unix() })
+			},
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := oidctest.NewServer("callpilot", "s3cret/+=")
+			defer idp.Close()
+			users := newMockTeamUserRepository()
+			ssoRepo := newMockSSORepository()
+			mfaRepo := newMockMFARepository()
+			if tt.setupMocks != nil {
+				tt.setupMocks(users, ssoRepo, mfaRepo, idp)
+			}
+			auth := newMockSSOAuthService(users, mfaRepo)
+			service := newMockSSOService(t, ssoRepo, users, auth, idp)
+			userCount := len(users.users)
+
+			response, err := ssoLogin(t, service, idp, tt.linkingUserID, tt.user)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Fatalf("CompleteLogin() error = %v, want %s", err, tt.wantCode)
+				}
+				if len(users.users) != userCount {
+					t.Error("expected no user to be provisioned")
+				}
+			} else if err != nil {
+				t.Fatalf("CompleteLogin() error = %v", err)
+			}
+			if tt.validate != nil {
+				tt.validate(t, auth, response, users, ssoRepo)
+			}
+		})
+	}
+}
+
+func TestSSOService_StartLink(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   string
+		wantCode string
+	}{
+		{
+			name:   "member of the business",
+			userID: "user-employee",
+		},
+		{
+			name:     "user of another business",
+			userID:   "user-other",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := oidctest.NewServer("callpilot", "s3cret/+=")
+			defer idp.Close()
+			users := newMockTeamUserRepository()
+			service := newMockSSOService(t, newMockSSORepository(), users, newMockSSOAuthService(users, newMockMFARepository()), idp)
+
+			start, err := service.StartLink(context.Background(), tt.userID, "business-123")
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("StartLink() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil || start.AuthorizationURL == "" {
+				t.Errorf("StartLink() = %+v, %v", start, err)
+			}
+		})
+	}
+}
+
+func TestSSOService_SessionStaysInBusiness(t *testing.T) {
+	tests := []struct {
+		name    string
+		refresh bool
+	}{
+		{
+			name: "signed-in session",
+		},
+		{
+			name:    "refreshed session",
+			refresh: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			idp := oidctest.NewServer("callpilot", "s3cret/+=")
+			defer idp.Close()
+
+			// user-other is an admin of business-123 as well as the owner of
+			// business-456
+			users := newMockTeamUserRepository()
+			users.SaveMembership(ctx, &entities.Membership{UserID: "user-other", BusinessID: "business-123", Role: entities.UserRoleAdmin})
+			ssoRepo := newMockSSORepository()
+			linkIdentity(ssoRepo, idp, "idp-other", "user-other")
+			auth := newMockSSOAuthService(users, newMockMFARepository())
+			service := newMockSSOService(t, ssoRepo, users, auth, idp)
+
+			response, err := ssoLogin(t, service, idp, "", oidctest.User{Subject: "idp-other", Email: "other@example.com", EmailVerified: true})
+			if err != nil {
+				t.Fatalf("CompleteLogin() error = %v", err)
+			}
+			accessToken := response.AccessToken
+			if tt.refresh {
+				refreshed, err := auth.RefreshToken(ctx, response.RefreshToken)
+				if err != nil {
+					t.Fatalf("RefreshToken() error = %v", err)
+				}
+				accessToken = refreshed.AccessToken
+			}
+
+			claims, err := auth.ValidateToken(accessToken)
+			if err != nil {
+				t.Fatalf("ValidateToken() error = %v", err)
+			}
+			if claims.BusinessID != "business-123" {
+				t.Fatalf("expected to sign in to the connection's business, got %s", claims.BusinessID)
+			}
+			if _, err := auth.SwitchBusiness(ctx, "user-other", claims.SessionID, dto.SwitchBusinessRequest{BusinessID: "business-456"}); errorCode(err) != domainerrors.ErrCodeForbidden {
+				t.Errorf("expected a single sign-on session to be unable to switch, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSSOService_LoginState(t *testing.T) {
+	tests := []struct {
+		name     string
+		forged   bool
+		usedOnce bool
+		late     bool
+		wantCode string
+	}{
+		{
+			name: "state from the sign-in",
+		},
+		{
+			name:     "unknown state",
+			forged:   true,
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name:     "state used twice",
+			usedOnce: true,
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name:     "late callback",
+			late:     true,
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			idp := oidctest.NewServer("callpilot", "s3cret/+=")
+			defer idp.Close()
+			users := newMockTeamUserRepository()
+			service := newMockSSOService(t, newMockSSORepository(), users, newMockSSOAuthService(users, newMockMFARepository()), idp)
+			idp.SignInAs(oidctest.User{Subject: "1", Email: "jane@example.com", EmailVerified: true})
+
+			start, err := service.StartLogin(ctx, dto.SSOStartRequest{BusinessID: "business-123"})
+			if err != nil {
+				t.Fatalf("StartLogin() error = %v", err)
+			}
+			code, state, err := idp.Authorize(start.AuthorizationURL)
+			if err != nil {
+				t.Fatalf("Authorize() error = %v", err)
+			}
+
+			if tt.usedOnce {
+				if _, err := service.CompleteLogin(ctx, dto.SSOCallbackRequest{State: state, Code: code}); err != nil {
+					t.Fatalf("CompleteLogin() error = %v", err)
+				}
+			}
+			if tt.late {
+				service.now = func() time.Time { return time.Now().Add(ssoLoginTTL + time.Minute) }
+			}
+			if tt.forged {
+				state = "forged"
+			}
+
+			_, err = service.CompleteLogin(ctx, dto.SSOCallbackRequest{State: state, Code: code})
+			if errorCode(err) != tt.wantCode {
+				t.Errorf("CompleteLogin() error = %v, want %q", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSSOService_KeyRotation(t *testing.T) {
+	tests := []struct {
+		name   string
+		rotate bool
+	}{
+		{
+			name: "current key",
+		},
+		{
+			name:   "new key",
+			rotate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := oidctest.NewServer("callpilot", "s3cret/+=")
+			defer idp.Close()
+			users := newMockTeamUserRepository()
+			service := newMockSSOService(t, newMockSSORepository(), users, newMockSSOAuthService(users, newMockMFARepository()), idp)
+			jane := oidctest.User{Subject: "1", Email: "jane@example.com", EmailVerified: true}
+
+			// Sign in once so the provider's keys are known
+			if _, err := ssoLogin(t, service, idp, "", jane); err != nil {
+				t.Fatalf("CompleteLogin() error = %v", err)
+			}
+			if tt.rotate {
+				idp.RotateKey()
+			}
+			if _, err := ssoLogin(t, service, idp, "", jane); err != nil {
+				t.Errorf("expected the token to be accepted, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSSOService_SaveConnection(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name       string
+		businessID string
+		request    func(idp *oidctest.Server) dto.SSOConnectionRequest
+		wantCode   string
+		validate   func(t *testing.T, service *SSOService, response *dto.SSOConnectionResponse, ssoRepo *mockSSORepository, stored entities.SSOConnection)
+	}{
+		{
+			name:       "new connection",
+			businessID: "business-456",
+			request: func(idp *oidctest.Server) dto.SSOConnectionRequest {
+				return dto.SSOConnectionRequest{Issuer: idp.Issuer(), ClientID: "client", ClientSecret: "secret", AllowedDomains: []string{"example.org"}}
+			},
+			validate: func(t *testing.T, service *SSOService, response *dto.SSOConnectionResponse, ssoRepo *mockSSORepository, stored entities.SSOConnection) {
+				if secret := ssoRepo.connections["business-456"].EncryptedClientSecret; secret == "" || secret == "secret" {
+					t.Error("expected the client secret to be stored encrypted")
+				}
+			},
+		},
+		{
+			name:       "new connection without a secret",
+			businessID: "business-456",
+			request: func(idp *oidctest.Server) dto.SSOConnectionRequest {
+				return dto.SSOConnectionRequest{Issuer: "https://login.example.org", ClientID: "client", AllowedDomains: []string{"example.org"}}
+			},
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:       "update keeps the secret",
+			businessID: "business-123",
+			request: func(idp *oidctest.Server) dto.SSOConnectionRequest {
+				return dto.SSOConnectionRequest{
+					Issuer:         idp.Issuer(),
+					ClientID:       idp.ClientID,
+					AllowedDomains: []string{"example.com"},
+					DefaultRole:    "admin",
+					Enabled:        &disabled,
+				}
+			},
+			validate: func(t *testing.T, service *SSOService, response *dto.SSOConnectionResponse, ssoRepo *mockSSORepository, stored entities.SSOConnection) {
+				if response.Enabled || response.DefaultRole != "admin" || ssoRepo.connections["business-123"].EncryptedClientSecret != stored.EncryptedClientSecret {
+					t.Errorf("expected the update to keep the secret, got %+v", response)
+				}
+				if _, err := service.StartLogin(context.Background(), dto.SSOStartRequest{BusinessID: "business-123"}); errorCode(err) != domainerrors.ErrCodeValidationError {
+					t.Errorf("expected a disabled connection to refuse sign-ins, got %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := oidctest.NewServer("callpilot", "s3cret/+=")
+			defer idp.Close()
+			users := newMockTeamUserRepository()
+			ssoRepo := newMockSSORepository()
+			service := newMockSSOService(t, ssoRepo, users, newMockSSOAuthService(users, newMockMFARepository()), idp)
+			stored := *ssoRepo.connections["business-123"]
+
+			response, err := service.SaveConnection(context.Background(), tt.businessID, tt.request(idp))
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("SaveConnection() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SaveConnection() error = %v", err)
+			}
+			if tt.validate != nil {
+				tt.validate(t, service, response, ssoRepo, stored)
+			}
+		})
+	}
+}
+
+func TestSSOService_DeleteConnection(t *testing.T) {
+	tests := []struct {
+		name       string
+		businessID string
+		wantCode   string
+	}{
+		{
+			name:       "connected business",
+			businessID: "business-123",
+		},
+		{
+			name:       "business without a connection",
+			businessID: "business-456",
+			wantCode:   domainerrors.ErrCodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			idp := oidctest.NewServer("callpilot", "s3cret/+=")
+			defer idp.Close()
+			users := newMockTeamUserRepository()
+			service := newMockSSOService(t, newMockSSORepository(), users, newMockSSOAuthService(users, newMockMFARepository()), idp)
+
+			err := service.DeleteConnection(ctx, tt.businessID)
+			if errorCode(err) != tt.wantCode {
+				t.Fatalf("DeleteConnection() error = %v, want %q", err, tt.wantCode)
+			}
+			if _, err := service.GetConnection(ctx, tt.businessID); errorCode(err) != domainerrors.ErrCodeNotFound {
+				t.Errorf("expected no connection to be left, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSSOService_PrivateNetworks(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  bool
+		wantCode string
+	}{
+		{
+			name:    "allowed for the stand-in provider",
+			allowed: true,
+		},
+		{
+			name:     "refused by default",
+			wantCode: domainerrors.ErrCodeProviderError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := oidctest.NewServer("callpilot", "s3cret/+=")
+			defer idp.Close()
+			users := newMockTeamUserRepository()
+			service := newMockSSOService(t, newMockSSORepository(), users, newMockSSOAuthService(users, newMockMFARepository()), idp)
+			if !tt.allowed {
+				service.client = oidc.NewClient()
+			}
+
+			// The stand-in provider listens on loopback, as an internal
+			// service would
+			_, err := service.StartLogin(context.Background(), dto.SSOStartRequest{BusinessID: "business-123"})
+			if errorCode(err) != tt.wantCode {
+				t.Errorf("StartLogin() error = %v, want %q", err, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,482 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+	"github.com/CallPilotReceptionist/pkg/phone"
+)
+
+// TransferToolName is the function the assistant calls to hand a caller over
+// to a person
+const TransferToolName = "transfer_call"
+
+// transferHoldMessage is said to the caller while the transfer is placed
+const transferHoldMessage = "Please hold while I connect you."
+
+// TransferService manages where calls can be transferred and who is on call,
+// and carries out transfers the assistant asks for. Every transfer attempt is
+// recorded as a transfer interaction on the call.
+type TransferService struct {
+	destinationRepo database.TransferDestinationRepository
+	shiftRepo       database.OnCallShiftRepository
+	callRepo        database.CallRepository
+	interactionRepo database.InteractionRepository
+	businessRepo    database.BusinessRepository
+	voiceProvider   providers.VoiceProvider
+	logger          *logger.Logger
+
+	now func() time.Time
+}
+
+func NewTransferService(
+	destinationRepo database.TransferDestinationRepository,
+	shiftRepo database.OnCallShiftRepository,
+	callRepo database.CallRepository,
+	interactionRepo database.InteractionRepository,
+	businessRepo database.BusinessRepository,
+	voiceProvider providers.VoiceProvider,
+	log *logger.Logger,
+) *TransferService {
+	return &TransferService{
+		destinationRepo: destinationRepo,
+		shiftRepo:       shiftRepo,
+		callRepo:        callRepo,
+		interactionRepo: interactionRepo,
+		businessRepo:    businessRepo,
+		voiceProvider:   voiceProvider,
+		logger:          log,
+		now:             time.Now,
+	}
+}
+
+func (s *TransferService) Name() string {
+	return TransferToolName
+}
+
+// Definition offers the business's active destinations to the assistant. The
+// tool is left out when the business has none.
+func (s *TransferService) Definition(ctx context.Context, businessID string) (*providers.Function, error) {
+	destinations, err := s.destinationRepo.GetByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	var names, descriptions []string
+	for _, destination := range destinations {
+		if !destination.Active {
+			continue
+		}
+		names = append(names, destination.Name)
+		if destination.Description != "" {
+			descriptions = append(descriptions, destination.Name+": "+destination.Description)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	destinationHelp := "Who to transfer the caller to"
+	if len(descriptions) > 0 {
+		destinationHelp += ". " + strings.Join(descriptions, "; ")
+	}
+
+	return &providers.Function{
+		Name: TransferToolName,
+		Description: "Transfer the caller to a person when they ask for one, when the request is urgent " +
+			"or when you cannot help them yourself",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"destination": map[string]interface{}{
+					"type":        "string",
+					"enum":        names,
+					"description": destinationHelp,
+				},
+				"reason": map[string]interface{}{
+					"type":        "string",
+					"description": "Why the caller needs a person",
+				},
+				"summary": map[string]interface{}{
+					"type":        "string",
+					"description": "A short summary of the call so far for the person taking over",
+				},
+			},
+			"required": []string{"destination", "reason"},
+		},
+	}, nil
+}
+
+// Invoke transfers the call to the requested destination, or to whoever is
+// on call for it. A transfer that cannot be placed is not an error: the
+// assistant is told so it can offer to take a message instead.
+func (s *TransferService) Invoke(ctx context.Context, call *entities.Call, args map[string]interface{}) (string, error) {
+	name := stringArg(args, "destination")
+	if name == "" {
+		return "", errors.NewFieldValidationError("destination", "destination is required")
+	}
+
+	destination, err := s.destinationRepo.GetByName(ctx, call.BusinessID, name)
+	if err != nil {
+		return "", err
+	}
+	if destination == nil || !destination.Active {
+		return "", errors.NewFieldValidationError("destination", "unknown transfer destination "+name)
+	}
+
+	reason := stringArg(args, "reason")
+	summary := stringArg(args, "summary")
+	if summary == "" {
+		summary = reason
+	}
+
+	target := destination.Phone
+	content := map[string]interface{}{
+		"destination":    destination.Name,
+		"destination_id": destination.ID,
+		"mode":           string(destination.Mode),
+		"reason":         reason,
+		"summary":        summary,
+	}
+
+	shift, err := s.shiftRepo.GetCurrent(ctx, destination.ID, s.now())
+	if err != nil {
+		s.logger.Warn("Failed to look up on-call shift, using destination number", map[string]interface{}{
+			"destination_id": destination.ID,
+			"error":          err.Error(),
+		})
+	} else if shift != nil {
+		target = shift.Phone
+		content["on_call_shift_id"] = shift.ID
+		content["on_call_name"] = shift.Name
+	}
+	content["phone"] = target
+
+	outcome := entities.TransferOutcomeInitiated
+	transferrer, ok := s.voiceProvider.(providers.CallTransferrer)
+	if !ok {
+		outcome = entities.TransferOutcomeUnsupported
+	} else {
+		err := transferrer.TransferCall(ctx, providers.TransferRequest{
+			CallID:      call.ProviderCallID,
+			PhoneNumber: target,
+			Mode:        providers.TransferMode(destination.Mode),
+			Message:     transferHoldMessage,
+			Summary:     summary,
+		})
+		if err != nil {
+			s.logger.Error("Failed to transfer call", err, map[string]interface{}{
+				"call_id":     call.ID,
+				"destination": destination.Name,
+			})
+			outcome = entities.TransferOutcomeFailed
+			content["error"] = err.Error()
+		}
+	}
+	content["outcome"] = outcome
+
+	s.recordTransfer(ctx, call, content)
+
+	if outcome != entities.TransferOutcomeInitiated {
+		return "The transfer could not be completed. Apologise to the caller and offer to take a message instead.", nil
+	}
+	return "The caller is being transferred to " + destination.Name + ".", nil
+}
+
+// recordTransfer stores the attempt as a call event. A failure is only logged
+// because the transfer itself has already happened.
+func (s *TransferService) recordTransfer(ctx context.Context, call *entities.Call, content map[string]interface{}) {
+	interaction, err := entities.NewInteraction(call.ID, entities.InteractionTypeTransfer, content)
+	if err == nil {
+		err = s.interactionRepo.Create(ctx, interaction)
+	}
+	if err != nil {
+		s.logger.Error("Failed to record transfer", err, map[string]interface{}{
+			"call_id": call.ID,
+		})
+	}
+}
+
+func (s *TransferService) ListDestinations(ctx context.Context, businessID string) ([]*dto.TransferDestinationResponse, error) {
+	destinations, err := s.destinationRepo.GetByBusinessID(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	responses := make([]*dto.TransferDestinationResponse, 0, len(destinations))
+	for _, destination := range destinations {
+		response := mapTransferDestinationToResponse(destination)
+		shift, err := s.shiftRepo.GetCurrent(ctx, destination.ID, now)
+		if err != nil {
+			return nil, err
+		}
+		if shift != nil {
+			response.OnCall = mapOnCallShiftToResponse(shift)
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+func (s *TransferService) CreateDestination(ctx context.Context, businessID string, req dto.TransferDestinationRequest) (*dto.TransferDestinationResponse, error) {
+	phoneNumber, err := s.normalizePhone(ctx, businessID, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	destination, err := entities.NewTransferDestination(businessID, req.Name, req.Description, phoneNumber, entities.TransferMode(req.Mode))
+	if err != nil {
+		return nil, err
+	}
+	if req.Active != nil {
+		destination.Active = *req.Active
+	}
+
+	if err := s.checkNameAvailable(ctx, businessID, destination.Name, ""); err != nil {
+		return nil, err
+	}
+
+	if err := s.destinationRepo.Create(ctx, destination); err != nil {
+		s.logger.Error("Failed to create transfer destination", err, map[string]interface{}{
+			"business_id": businessID,
+		})
+		return nil, err
+	}
+
+	return mapTransferDestinationToResponse(destination), nil
+}
+
+// UpdateDestination replaces a destination's details
+func (s *TransferService) UpdateDestination(ctx context.Context, businessID, id string, req dto.TransferDestinationRequest) (*dto.TransferDestinationResponse, error) {
+	destination, err := s.getOwnedDestination(ctx, businessID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	phoneNumber, err := s.normalizePhone(ctx, businessID, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	active := true
+	if req.Active != nil {
+		active = *req.Active
+	}
+	if err := destination.Update(req.Name, req.Description, phoneNumber, entities.TransferMode(req.Mode), active); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNameAvailable(ctx, businessID, destination.Name, destination.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.destinationRepo.Update(ctx, destination); err != nil {
+		s.logger.Error("Failed to update transfer destination", err, map[string]interface{}{
+			"destination_id": destination.ID,
+		})
+		return nil, err
+	}
+
+	return mapTransferDestinationToResponse(destination), nil
+}
+
+// DeleteDestination removes a destination along with its on-call shifts
+func (s *TransferService) DeleteDestination(ctx context.Context, businessID, id string) error {
+	if _, err := s.getOwnedDestination(ctx, businessID, id); err != nil {
+		return err
+	}
+
+	return s.destinationRepo.Delete(ctx, id)
+}
+
+// ListOnCallShifts lists a destination's current and upcoming shifts
+func (s *TransferService) ListOnCallShifts(ctx context.Context, businessID, destinationID string) ([]*dto.OnCallShiftResponse, error) {
+	if _, err := s.getOwnedDestination(ctx, businessID, destinationID); err != nil {
+		return nil, err
+	}
+
+	shifts, err := s.shiftRepo.GetByDestinationID(ctx, destinationID, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.OnCallShiftResponse, 0, len(shifts))
+	for _, shift := range shifts {
+		responses = append(responses, mapOnCallShiftToResponse(shift))
+	}
+
+	return responses, nil
+}
+
+func (s *TransferService) CreateOnCallShift(ctx context.Context, businessID, destinationID string, req dto.CreateOnCallShiftRequest) (*dto.OnCallShiftResponse, error) {
+	destination, err := s.getOwnedDestination(ctx, businessID, destinationID)
+	if err != nil {
+		return nil, err
+	}
+
+	phoneNumber, err := s.normalizePhone(ctx, businessID, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	startsAt, err := parseOptionalTime("starts_at", req.StartsAt)
+	if err != nil {
+		return nil, err
+	}
+	endsAt, err := parseOptionalTime("ends_at", req.EndsAt)
+	if err != nil {
+		return nil, err
+	}
+	if startsAt == nil || endsAt == nil {
+		return nil, errors.NewValidationError("starts_at and ends_at are required")
+	}
+
+	shift, err := entities.NewOnCallShift(destination, req.Name, phoneNumber, *startsAt, *endsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.shiftRepo.Create(ctx, shift); err != nil {
+		s.logger.Error("Failed to create on-call shift", err, map[string]interface{}{
+			"destination_id": destination.ID,
+		})
+		return nil, err
+	}
+
+	return mapOnCallShiftToResponse(shift), nil
+}
+
+func (s *TransferService) DeleteOnCallShift(ctx context.Context, businessID, id string) error {
+	shift, err := s.shiftRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if shift.BusinessID != businessID {
+		return errors.NewForbiddenError("access denied to this on-call shift")
+	}
+
+	return s.shiftRepo.Delete(ctx, id)
+}
+
+// GetTransferStats reports how often calls were escalated to a person over
+// the last days
+func (s *TransferService) GetTransferStats(ctx context.Context, businessID string, days int) (*dto.TransferStatsResponse, error) {
+	if days <= 0 {
+		days = 30
+	}
+
+	endDate := s.now()
+	startDate := endDate.AddDate(0, 0, -days)
+
+	callStats, err := s.callRepo.GetStats(ctx, businessID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers, err := s.interactionRepo.GetByType(ctx, businessID, entities.InteractionTypeTransfer, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &dto.TransferStatsResponse{
+		Days:          days,
+		TotalCalls:    callStats.TotalCalls,
+		Transfers:     len(transfers),
+		ByDestination: make(map[string]int),
+		ByOutcome:     make(map[string]int),
+	}
+
+	transferredCalls := make(map[string]bool)
+	for _, transfer := range transfers {
+		transferredCalls[transfer.CallID] = true
+		if destination, ok := transfer.Content["destination"].(string); ok {
+			stats.ByDestination[destination]++
+		}
+		if outcome, ok := transfer.Content["outcome"].(string); ok {
+			stats.ByOutcome[outcome]++
+		}
+	}
+
+	stats.TransferredCalls = len(transferredCalls)
+	if stats.TotalCalls > 0 {
+		stats.TransferRate = float64(stats.TransferredCalls) / float64(stats.TotalCalls)
+	}
+
+	return stats, nil
+}
+
+func (s *TransferService) checkNameAvailable(ctx context.Context, businessID, name, exceptID string) error {
+	existing, err := s.destinationRepo.GetByName(ctx, businessID, name)
+	if err != nil {
+		return err
+	}
+	if existing != nil && existing.ID != exceptID {
+		return errors.NewAlreadyExistsError("transfer destination", "name", name)
+	}
+	return nil
+}
+
+func (s *TransferService) normalizePhone(ctx context.Context, businessID, raw string) (string, error) {
+	if raw == "" {
+		return "", errors.NewFieldValidationError("phone_number", "phone_number is required")
+	}
+	region := phone.DefaultRegion
+	if business, err := s.businessRepo.GetByID(ctx, businessID); err == nil && business != nil {
+		region = business.DefaultRegion()
+	}
+	return normalizePhone("phone_number", raw, region)
+}
+
+func (s *TransferService) getOwnedDestination(ctx context.Context, businessID, id string) (*entities.TransferDestination, error) {
+	destination, err := s.destinationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if destination.BusinessID != businessID {
+		return nil, errors.NewForbiddenError("access denied to this transfer destination")
+	}
+
+	return destination, nil
+}
+
+// stringArg reads a trimmed string argument from an assistant function call
+func stringArg(args map[string]interface{}, key string) string {
+	value, _ := args[key].(string)
+	return strings.TrimSpace(value)
+}
+
+func mapTransferDestinationToResponse(destination *entities.TransferDestination) *dto.TransferDestinationResponse {
+	return &dto.TransferDestinationResponse{
+		ID:          destination.ID,
+		BusinessID:  destination.BusinessID,
+		Name:        destination.Name,
+		Description: destination.Description,
+		PhoneNumber: destination.Phone,
+		Mode:        string(destination.Mode),
+		Active:      destination.Active,
+		CreatedAt:   destination.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   destination.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func mapOnCallShiftToResponse(shift *entities.OnCallShift) *dto.OnCallShiftResponse {
+	return &dto.OnCallShiftResponse{
+		ID:            shift.ID,
+		DestinationID: shift.DestinationID,
+		Name:          shift.Name,
+		PhoneNumber:   shift.Phone,
+		StartsAt:      shift.StartsAt.Format(time.RFC3339),
+		EndsAt:        shift.EndsAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,528 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// newMockTransferDestinations gives business-123 a warm front_desk and a
+// cold emergency line, with Dr. Rivera on call for it at now
+func newMockTransferDestinations(now time.Time) (*mockTransferDestinationRepository, *mockOnCallShiftRepository) {
+	ctx := context.Background()
+	destinations := &mockTransferDestinationRepository{}
+	shifts := &mockOnCallShiftRepository{}
+
+	frontDesk, _ := entities.NewTransferDestination("business-123", "front_desk", "Bookings and general questions",
+		"+15551230001", entities.TransferModeWarm)
+	destinations.Create(ctx, frontDesk)
+	emergency, _ := entities.NewTransferDestination("business-123", "emergency", "Urgent dental pain or injury",
+		"+15551230002", entities.TransferModeCold)
+	destinations.Create(ctx, emergency)
+
+	shift, _ := entities.NewOnCallShift(emergency, "Dr. Rivera", "+12125551234", now.Add(-time.Hour), now.Add(time.Hour))
+	shifts.Create(ctx, shift)
+	past, _ := entities.NewOnCallShift(emergency, "Dr. Chen", "+12125559876", now.Add(-3*time.Hour), now.Add(-time.Hour))
+	shifts.Create(ctx, past)
+
+	return destinations, shifts
+}
+
+// newMockTransferCallRepository holds call-123, an inbound call to
+// business-123
+func newMockTransferCallRepository() *mockStatsCallRepository {
+	callRepo := newMockStatsCallRepository()
+	call, _ := entities.NewInboundCall("business-123", "+15551234567", "provider-123")
+	call.ID = "call-123"
+	callRepo.calls[call.ID] = call
+	return callRepo
+}
+
+func newMockTransferService(destinations *mockTransferDestinationRepository, shifts *mockOnCallShiftRepository, callRepo *mockStatsCallRepository, interactions *testInteractionRepository, provider providers.VoiceProvider, now time.Time) *TransferService {
+	businessRepo := &mockBusinessRepository{businesses: make(map[string]*entities.Business)}
+	service := NewTransferService(destinations, shifts, callRepo, interactions, businessRepo, provider, logger.New("info", "console"))
+	service.now = func() time.Time { return now }
+	return service
+}
+
+func TestTransferService_Invoke(t *testing.T) {
+	now := time.Date(2024, 3, 12, 22, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		provider    func() providers.VoiceProvider
+		args        map[string]interface{}
+		wantErr     bool
+		wantPhone   string
+		wantMode    providers.TransferMode
+		wantOutcome string
+	}{
+		{
+			name:        "on-call shift takes the transfer",
+			provider:    func() providers.VoiceProvider { return &mockTransferProvider{} },
+			args:        map[string]interface{}{"destination": "emergency", "reason": "Severe tooth pain"},
+			wantPhone:   "+12125551234",
+			wantMode:    providers.TransferModeCold,
+			wantOutcome: entities.TransferOutcomeInitiated,
+		},
+		{
+			name:        "destination number without a shift",
+			provider:    func() providers.VoiceProvider { return &mockTransferProvider{} },
+			args:        map[string]interface{}{"destination": "front_desk", "reason": "Billing question", "summary": "Asks about an invoice"},
+			wantPhone:   "+15551230001",
+			wantMode:    providers.TransferModeWarm,
+			wantOutcome: entities.TransferOutcomeInitiated,
+		},
+		{
+			name: "provider fails to transfer",
+			provider: func() providers.VoiceProvider {
+				return &mockTransferProvider{transferErr: errors.New("transfer rejected")}
+			},
+			args:        map[string]interface{}{"destination": "front_desk", "reason": "Wants a person"},
+			wantPhone:   "+15551230001",
+			wantOutcome: entities.TransferOutcomeFailed,
+		},
+		{
+			name:        "provider cannot transfer",
+			provider:    func() providers.VoiceProvider { return &testVoiceProvider{} },
+			args:        map[string]interface{}{"destination": "front_desk", "reason": "Wants a person"},
+			wantPhone:   "+15551230001",
+			wantOutcome: entities.TransferOutcomeUnsupported,
+		},
+		{
+			name:     "unknown destination",
+			provider: func() providers.VoiceProvider { return &mockTransferProvider{} },
+			args:     map[string]interface{}{"destination": "billing"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := tt.provider()
+			destinations, shifts := newMockTransferDestinations(now)
+			callRepo := newMockTransferCallRepository()
+			interactions := newTestInteractionRepository()
+			service := newMockTransferService(destinations, shifts, callRepo, interactions, provider, now)
+			call := callRepo.calls["call-123"]
+
+			result, err := service.Invoke(context.Background(), call, tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if len(interactions.interactions[call.ID]) != 0 {
+					t.Error("expected no transfer to be recorded")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Invoke() error = %v", err)
+			}
+			if result == "" {
+				t.Error("expected a message for the assistant")
+			}
+
+			if transferrer, ok := provider.(*mockTransferProvider); ok && tt.wantOutcome == entities.TransferOutcomeInitiated {
+				if len(transferrer.transfers) != 1 {
+					t.Fatalf("expected one transfer, got %d", len(transferrer.transfers))
+				}
+				req := transferrer.transfers[0]
+				if req.CallID != "provider-123" || req.PhoneNumber != tt.wantPhone || req.Mode != tt.wantMode {
+					t.Errorf("unexpected transfer request %+v", req)
+				}
+			}
+
+			recorded := interactions.interactions[call.ID]
+			if len(recorded) != 1 || recorded[0].Type != entities.InteractionTypeTransfer {
+				t.Fatalf("expected one transfer interaction, got %+v", recorded)
+			}
+			if recorded[0].Content["outcome"] != tt.wantOutcome {
+				t.Errorf("outcome = %v, want %s", recorded[0].Content["outcome"], tt.wantOutcome)
+			}
+			if recorded[0].Content["phone"] != tt.wantPhone {
+				t.Errorf("phone = %v, want %s", recorded[0].Content["phone"], tt.wantPhone)
+			}
+		})
+	}
+}
+
+func TestTransferService_Definition(t *testing.T) {
+	now := time.Date(2024, 3, 12, 22, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		businessID string
+		inactive   string
+		wantNames  []string
+	}{
+		{
+			name:       "every active destination",
+			businessID: "business-123",
+			wantNames:  []string{"front_desk", "emergency"},
+		},
+		{
+			name:       "inactive destination left out",
+			businessID: "business-123",
+			inactive:   "emergency",
+			wantNames:  []string{"front_desk"},
+		},
+		{
+			name:       "business without destinations",
+			businessID: "business-456",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destinations, shifts := newMockTransferDestinations(now)
+			for _, destination := range destinations.destinations {
+				if destination.Name == tt.inactive {
+					destination.Active = false
+				}
+			}
+			service := newMockTransferService(destinations, shifts, newMockTransferCallRepository(), newTestInteractionRepository(), &mockTransferProvider{}, now)
+
+			function, err := service.Definition(context.Background(), tt.businessID)
+			if err != nil {
+				t.Fatalf("Definition() error = %v", err)
+			}
+			if tt.wantNames == nil {
+				if function != nil {
+					t.Errorf("expected no function, got %+v", function)
+				}
+				return
+			}
+			if function == nil || function.Name != TransferToolName {
+				t.Fatalf("expected the transfer_call function, got %+v", function)
+			}
+			destination := function.Parameters["properties"].(map[string]interface{})["destination"].(map[string]interface{})
+			names := destination["enum"].([]string)
+			if len(names) != len(tt.wantNames) {
+				t.Fatalf("expected destinations %v, got %v", tt.wantNames, names)
+			}
+			for i, name := range tt.wantNames {
+				if names[i] != name {
+					t.Errorf("expected destinations %v, got %v", tt.wantNames, names)
+				}
+			}
+		})
+	}
+}
+
+func TestTransferService_CreateDestination(t *testing.T) {
+	now := time.Date(2024, 3, 12, 22, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		req      dto.TransferDestinationRequest
+		wantCode string
+		validate func(t *testing.T, created *dto.TransferDestinationResponse)
+	}{
+		{
+			name: "new destination",
+			req:  dto.TransferDestinationRequest{Name: "manager", PhoneNumber: "(212) 555-0000"},
+			validate: func(t *testing.T, created *dto.TransferDestinationResponse) {
+				if created.PhoneNumber != "+12125550000" || created.Mode != "warm" || !created.Active {
+					t.Errorf("unexpected destination %+v", created)
+				}
+			},
+		},
+		{
+			name:     "name taken",
+			req:      dto.TransferDestinationRequest{Name: "front_desk", PhoneNumber: "+12125550001"},
+			wantCode: domainerrors.ErrCodeAlreadyExists,
+		},
+		{
+			name:     "invalid name",
+			req:      dto.TransferDestinationRequest{Name: "Front Desk", PhoneNumber: "+12125550001"},
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:     "missing phone number",
+			req:      dto.TransferDestinationRequest{Name: "manager"},
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destinations, shifts := newMockTransferDestinations(now)
+			service := newMockTransferService(destinations, shifts, newMockTransferCallRepository(), newTestInteractionRepository(), &mockTransferProvider{}, now)
+
+			created, err := service.CreateDestination(context.Background(), "business-123", tt.req)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("CreateDestination() error = %v, want %s", err, tt.wantCode)
+				}
+				if len(destinations.destinations) != 2 {
+					t.Error("expected no destination to be created")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateDestination() error = %v", err)
+			}
+			tt.validate(t, created)
+		})
+	}
+}
+
+func TestTransferService_CreateOnCallShift(t *testing.T) {
+	now := time.Date(2024, 3, 12, 22, 0, 0, 0, time.UTC)
+	shift := dto.CreateOnCallShiftRequest{
+		Name:        "Sam",
+		PhoneNumber: "+15551234567",
+		StartsAt:    now.Format(time.RFC3339),
+		EndsAt:      now.Add(8 * time.Hour).Format(time.RFC3339),
+	}
+
+	tests := []struct {
+		name       string
+		businessID string
+		req        dto.CreateOnCallShiftRequest
+		wantCode   string
+	}{
+		{
+			name:       "shift on call now",
+			businessID: "business-123",
+			req:        shift,
+		},
+		{
+			name:       "another business's destination",
+			businessID: "business-456",
+			req:        shift,
+			wantCode:   domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:       "missing times",
+			businessID: "business-123",
+			req:        dto.CreateOnCallShiftRequest{Name: "Sam", PhoneNumber: "+15551234567"},
+			wantCode:   domainerrors.ErrCodeValidationError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			destinations, shifts := newMockTransferDestinations(now)
+			service := newMockTransferService(destinations, shifts, newMockTransferCallRepository(), newTestInteractionRepository(), &mockTransferProvider{}, now)
+			frontDesk := destinations.destinations[0]
+
+			created, err := service.CreateOnCallShift(ctx, tt.businessID, frontDesk.ID, tt.req)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Errorf("CreateOnCallShift() error = %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateOnCallShift() error = %v", err)
+			}
+
+			listed, err := service.ListDestinations(ctx, "business-123")
+			if err != nil {
+				t.Fatalf("ListDestinations() error = %v", err)
+			}
+			for _, destination := range listed {
+				if destination.ID == frontDesk.ID && (destination.OnCall == nil || destination.OnCall.ID != created.ID) {
+					t.Errorf("expected the new shift to be on call, got %+v", destination.OnCall)
+				}
+			}
+		})
+	}
+}
+
+func TestTransferService_GetTransferStats(t *testing.T) {
+	now := time.Date(2024, 3, 12, 22, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		transfers            []string
+		wantTransferredCalls int
+		wantRate             float64
+		wantEmergency        int
+	}{
+		{
+			name:                 "one of two calls transferred twice",
+			transfers:            []string{"emergency", "front_desk"},
+			wantTransferredCalls: 1,
+			wantRate:             0.5,
+			wantEmergency:        1,
+		},
+		{
+			name: "no transfers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			destinations, shifts := newMockTransferDestinations(now)
+			callRepo := newMockTransferCallRepository()
+			other, _ := entities.NewCall("business-123", "+12125551234")
+			other.ID = "call-456"
+			callRepo.calls[other.ID] = other
+			service := newMockTransferService(destinations, shifts, callRepo, newTestInteractionRepository(), &mockTransferProvider{}, now)
+
+			call := callRepo.calls["call-123"]
+			for _, destination := range tt.transfers {
+				if _, err := service.Invoke(ctx, call, map[string]interface{}{"destination": destination, "reason": "Pain"}); err != nil {
+					t.Fatalf("Invoke() error = %v", err)
+				}
+			}
+
+			// Interactions are timestamped with the wall clock
+			service.now = time.Now
+
+			stats, err := service.GetTransferStats(ctx, "business-123", 7)
+			if err != nil {
+				t.Fatalf("GetTransferStats() error = %v", err)
+			}
+			if stats.TotalCalls != 2 || stats.TransferredCalls != tt.wantTransferredCalls || stats.Transfers != len(tt.transfers) {
+				t.Errorf("unexpected counts %+v", stats)
+			}
+			if stats.TransferRate != tt.wantRate {
+				t.Errorf("transfer rate = %v, want %v", stats.TransferRate, tt.wantRate)
+			}
+			if stats.ByDestination["emergency"] != tt.wantEmergency || stats.ByOutcome[entities.TransferOutcomeInitiated] != len(tt.transfers) {
+				t.Errorf("unexpected breakdown %+v %+v", stats.ByDestination, stats.ByOutcome)
+			}
+		})
+	}
+}
+
+func TestCallService_HandlesTransferToolCall(t *testing.T) {
+	now := time.Date(2024, 3, 12, 22, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		toolCall      providers.ToolCall
+		wantErr       bool
+		wantTransfers int
+	}{
+		{
+			name:          "transfer",
+			toolCall:      providers.ToolCall{ID: "tool-1", Name: TransferToolName, Arguments: map[string]interface{}{"destination": "emergency", "reason": "Pain"}},
+			wantTransfers: 1,
+		},
+		{
+			name:     "unknown destination",
+			toolCall: providers.ToolCall{ID: "tool-1", Name: TransferToolName, Arguments: map[string]interface{}{"destination": "billing"}},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown function",
+			toolCall: providers.ToolCall{ID: "tool-1", Name: "book_table", Arguments: map[string]interface{}{}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &mockTransferProvider{}
+			provider.handleWebhookFunc = func(ctx context.Context, payload []byte, signature string) (*providers.CallEvent, error) {
+				return &providers.CallEvent{
+					Type:      providers.CallEventTypeToolCalls,
+					CallID:    "provider-123",
+					ToolCalls: []providers.ToolCall{tt.toolCall},
+				}, nil
+			}
+			destinations, shifts := newMockTransferDestinations(now)
+			callRepo := newMockTransferCallRepository()
+			interactions := newTestInteractionRepository()
+			service := NewCallService(
+				callRepo.testCallRepository,
+				newTestTranscriptRepository(),
+				interactions,
+				&mockBusinessRepository{businesses: make(map[string]*entities.Business)},
+				provider,
+				logger.New("info", "console"),
+			)
+			service.AddTool(newMockTransferService(destinations, shifts, callRepo, interactions, provider, now))
+
+			response, err := service.ProcessWebhook(context.Background(), []byte(`{}`), "valid-signature")
+			if err != nil {
+				t.Fatalf("ProcessWebhook() error = %v", err)
+			}
+
+			results, ok := response.([]providers.ToolCallResult)
+			if !ok || len(results) != 1 {
+				t.Fatalf("expected one tool call result, got %#v", response)
+			}
+			if results[0].ToolCallID != "tool-1" || (results[0].Error != "") != tt.wantErr {
+				t.Errorf("unexpected result %+v", results[0])
+			}
+			if !tt.wantErr && results[0].Result == "" {
+				t.Error("expected a message for the assistant")
+			}
+			if len(provider.transfers) != tt.wantTransfers {
+				t.Errorf("expected %d transfers, got %d", tt.wantTransfers, len(provider.transfers))
+			}
+		})
+	}
+}
+
+// Outbound calls carry the transfer function too
+func TestCallService_OffersTransferToolOnOutboundCalls(t *testing.T) {
+	now := time.Date(2024, 3, 12, 22, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		businessID    string
+		wantFunctions int
+	}{
+		{
+			name:          "business with destinations",
+			businessID:    "business-123",
+			wantFunctions: 1,
+		},
+		{
+			name:       "business without destinations",
+			businessID: "business-456",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []providers.CallRequest
+			provider := &mockTransferProvider{}
+			provider.initiateCallFunc = func(ctx context.Context, req providers.CallRequest) (*providers.CallSession, error) {
+				requests = append(requests, req)
+				return &providers.CallSession{ID: "provider-456"}, nil
+			}
+			destinations, shifts := newMockTransferDestinations(now)
+			callRepo := newMockTransferCallRepository()
+			interactions := newTestInteractionRepository()
+			service := NewCallService(
+				callRepo.testCallRepository,
+				newTestTranscriptRepository(),
+				interactions,
+				&mockBusinessRepository{businesses: make(map[string]*entities.Business)},
+				provider,
+				logger.New("info", "console"),
+			)
+			service.AddTool(newMockTransferService(destinations, shifts, callRepo, interactions, provider, now))
+
+			if _, err := service.InitiateCall(context.Background(), tt.businessID, dto.InitiateCallRequest{PhoneNumber: "+12125551234", AssistantID: "assistant-1"}); err != nil {
+				t.Fatalf("InitiateCall() error = %v", err)
+			}
+
+			config := requests[0].AssistantConfig
+			var functions []providers.Function
+			if config != nil {
+				functions = config.Functions
+			}
+			if len(functions) != tt.wantFunctions || (tt.wantFunctions > 0 && functions[0].Name != TransferToolName) {
+				t.Errorf("expected %d transfer_call functions on the outbound call, got %+v", tt.wantFunctions, config)
+			}
+		})
+	}
+}
@@ -0,0 +1,480 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/infrastructure/database"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// invitationTTL is how long an invitation can be accepted for
+const invitationTTL = 7 * 24 * time.Hour
+
+// UserService manages the team of a business: inviting users, changing their
+// roles and deactivating them. The team includes members whose home business
+// is another one. Every business keeps at least one active owner.
+type UserService struct {
+	userRepo       database.UserRepository
+	invitationRepo database.UserInvitationRepository
+	logger         *logger.Logger
+
+	// throttle lets managers clear a user's login lockout when set
+	throttle *LoginThrottleService
+
+	audit *AuditService
+
+	now func() time.Time
+}
+
+func NewUserService(
+	userRepo database.UserRepository,
+	invitationRepo database.UserInvitationRepository,
+	log *logger.Logger,
+) *UserService {
+	return &UserService{
+		userRepo:       userRepo,
+		invitationRepo: invitationRepo,
+		logger:         log,
+		now:            time.Now,
+	}
+}
+
+// SetLoginThrottle lets managers unlock users locked out by failed logins
+func (s *UserService) SetLoginThrottle(throttle *LoginThrottleService) {
+	s.throttle = throttle
+}
+
+// SetAudit records changes to the team in the audit log
+func (s *UserService) SetAudit(audit *AuditService) {
+	s.audit = audit
+}
+
+func (s *UserService) ListUsers(ctx context.Context, businessID string) ([]dto.UserResponse, error) {
+	users, err := s.userRepo.GetMembers(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.UserResponse, 0, len(users))
+	for _, user := range users {
+		response = append(response, mapUserToResponse(user))
+	}
+
+	return response, nil
+}
+
+// InviteUser issues a one-time invitation token for someone to join the
+// business. Someone who already has an account in another business is added
+// to this one when they accept. The token is only returned here.
+func (s *UserService) InviteUser(ctx context.Context, businessID, actorID string, req dto.InviteUserRequest) (*dto.InvitationResponse, error) {
+	actor, err := s.getManager(ctx, businessID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	role := entities.UserRole(req.Role)
+	if role == "" {
+		role = entities.UserRoleEmployee
+	}
+	if role == entities.UserRoleOwner && !actor.IsOwner() {
+		return nil, errors.NewForbiddenError("only owners can invite owners")
+	}
+
+	email := strings.TrimSpace(req.Email)
+	existing, err := s.userRepo.GetByEmail(ctx, email)
+	if err == nil && existing != nil {
+		member, err := s.userRepo.GetMember(ctx, businessID, existing.ID)
+		if err != nil {
+			return nil, err
+		}
+		if member != nil {
+			return nil, errors.NewAlreadyExistsError("user", "email", email)
+		}
+	}
+
+	pending, err := s.invitationRepo.GetPending(ctx, businessID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	for _, invitation := range pending {
+		if strings.EqualFold(invitation.Email, email) {
+			return nil, errors.NewAlreadyExistsError("invitation", "email", email)
+		}
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, errors.NewInternalError(err)
+	}
+
+	invitation, err := entities.NewUserInvitation(businessID, email, role, actor.ID, tokenHash, s.now().Add(invitationTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+		s.logger.Error("Failed to create invitation", err, map[string]interface{}{
+			"business_id": businessID,
+		})
+		return nil, err
+	}
+
+	s.logger.Info("User invited", map[string]interface{}{
+		"business_id":   businessID,
+		"invitation_id": invitation.ID,
+		"invited_by":    actor.ID,
+		"role":          string(role),
+	})
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionUserInvited,
+		TargetType: "invitation",
+		TargetID:   invitation.ID,
+		Metadata:   map[string]interface{}{"email": email, "role": string(role)},
+	})
+
+	response := mapInvitationToResponse(invitation)
+	response.Token = token
+	return &response, nil
+}
+
+func (s *UserService) ListInvitations(ctx context.Context, businessID string) ([]dto.InvitationResponse, error) {
+	invitations, err := s.invitationRepo.GetPending(ctx, businessID, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]dto.InvitationResponse, 0, len(invitations))
+	for _, invitation := range invitations {
+		response = append(response, mapInvitationToResponse(invitation))
+	}
+
+	return response, nil
+}
+
+func (s *UserService) RevokeInvitation(ctx context.Context, businessID, actorID, id string) error {
+	if _, err := s.getManager(ctx, businessID, actorID); err != nil {
+		return err
+	}
+
+	invitation, err := s.invitationRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if invitation.BusinessID != businessID {
+		return errors.NewForbiddenError("access denied to this invitation")
+	}
+
+	if err := invitation.Revoke(s.now()); err != nil {
+		return err
+	}
+
+	if err := s.invitationRepo.Update(ctx, invitation); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     entities.AuditActionInvitationRevoked,
+		TargetType: "invitation",
+		TargetID:   invitation.ID,
+		Metadata:   map[string]interface{}{"email": invitation.Email},
+	})
+	return nil
+}
+
+// ChangeRole changes a user's role. Only owners can grant ownership or change
+// another owner's role, and the last active owner cannot be demoted.
+func (s *UserService) ChangeRole(ctx context.Context, businessID, actorID, userID string, req dto.UpdateUserRoleRequest) (*dto.UserResponse, error) {
+	actor, err := s.getManager(ctx, businessID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.getOwnedUser(ctx, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	role := entities.UserRole(req.Role)
+	if !role.IsValid() {
+		return nil, errors.NewFieldValidationError("role", "role must be owner, admin or employee")
+	}
+	if !actor.IsOwner() && (target.IsOwner() || role == entities.UserRoleOwner) {
+		return nil, errors.NewForbiddenError("only owners can grant ownership or change an owner's role")
+	}
+	if target.IsOwner() && role != entities.UserRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, businessID, target.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	previousRole := target.Role
+	if err := target.ChangeRole(role); err != nil {
+		return nil, err
+	}
+
+	response, err := s.saveUser(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordUserChange(ctx, businessID, entities.AuditActionUserRoleChanged, target.ID, map[string]entities.AuditChange{
+		"role": {Before: string(previousRole), After: string(role)},
+	})
+	return response, nil
+}
+
+// DeactivateUser stops a user from signing in. Users cannot deactivate
+// themselves, and the last active owner cannot be deactivated.
+func (s *UserService) DeactivateUser(ctx context.Context, businessID, actorID, userID string) (*dto.UserResponse, error) {
+	actor, err := s.getManager(ctx, businessID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.getHomeUser(ctx, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.ID == actor.ID {
+		return nil, errors.NewValidationError("you cannot deactivate yourself")
+	}
+	if target.IsOwner() {
+		if !actor.IsOwner() {
+			return nil, errors.NewForbiddenError("only owners can deactivate an owner")
+		}
+		if err := s.ensureAnotherOwner(ctx, businessID, target.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := target.Deactivate(); err != nil {
+		return nil, err
+	}
+
+	response, err := s.saveUser(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordUserChange(ctx, businessID, entities.AuditActionUserDeactivated, target.ID, nil)
+	return response, nil
+}
+
+func (s *UserService) ReactivateUser(ctx context.Context, businessID, actorID, userID string) (*dto.UserResponse, error) {
+	actor, err := s.getManager(ctx, businessID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.getHomeUser(ctx, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.IsOwner() && !actor.IsOwner() {
+		return nil, errors.NewForbiddenError("only owners can reactivate an owner")
+	}
+
+	target.Reactivate()
+	response, err := s.saveUser(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordUserChange(ctx, businessID, entities.AuditActionUserReactivated, target.ID, nil)
+	return response, nil
+}
+
+// RemoveMember takes a user whose home business is another one out of the
+// team. Users of this business are deactivated instead.
+func (s *UserService) RemoveMember(ctx context.Context, businessID, actorID, userID string) error {
+	actor, err := s.getManager(ctx, businessID, actorID)
+	if err != nil {
+		return err
+	}
+
+	target, err := s.getOwnedUser(ctx, businessID, userID)
+	if err != nil {
+		return err
+	}
+
+	home, err := s.userRepo.GetByID(ctx, target.ID)
+	if err != nil {
+		return err
+	}
+	if home.BusinessID == businessID {
+		return errors.NewValidationError("this business is the user's home business; deactivate them instead")
+	}
+	if target.IsOwner() {
+		if !actor.IsOwner() {
+			return errors.NewForbiddenError("only owners can remove an owner")
+		}
+		if err := s.ensureAnotherOwner(ctx, businessID, target.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.userRepo.DeleteMembership(ctx, target.ID, businessID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Member removed", map[string]interface{}{
+		"business_id": businessID,
+		"user_id":     target.ID,
+		"removed_by":  actor.ID,
+	})
+
+	s.recordUserChange(ctx, businessID, entities.AuditActionMemberRemoved, target.ID, nil)
+	return nil
+}
+
+// UnlockUser clears a user's failed logins, lifting any lockout
+func (s *UserService) UnlockUser(ctx context.Context, businessID, actorID, userID string) error {
+	actor, err := s.getManager(ctx, businessID, actorID)
+	if err != nil {
+		return err
+	}
+
+	target, err := s.getOwnedUser(ctx, businessID, userID)
+	if err != nil {
+		return err
+	}
+
+	if s.throttle == nil {
+		return errors.NewValidationError("login throttling is not enabled")
+	}
+	if err := s.throttle.Reset(ctx, target.Email); err != nil {
+		return err
+	}
+
+	s.logger.Info("User unlocked", map[string]interface{}{
+		"business_id": businessID,
+		"user_id":     target.ID,
+		"unlocked_by": actor.ID,
+	})
+
+	s.recordUserChange(ctx, businessID, entities.AuditActionUserUnlocked, target.ID, nil)
+	return nil
+}
+
+func (s *UserService) recordUserChange(ctx context.Context, businessID, action, userID string, changes map[string]entities.AuditChange) {
+	s.audit.Record(ctx, AuditEvent{
+		BusinessID: businessID,
+		Action:     action,
+		TargetType: "user",
+		TargetID:   userID,
+		Changes:    changes,
+	})
+}
+
+func (s *UserService) saveUser(ctx context.Context, user *entities.User) (*dto.UserResponse, error) {
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		s.logger.Error("Failed to update user", err, map[string]interface{}{
+			"user_id": user.ID,
+		})
+		return nil, err
+	}
+
+	response := mapUserToResponse(user)
+	return &response, nil
+}
+
+// ensureAnotherOwner rejects a change that would leave the business without
+// an active owner other than userID
+func (s *UserService) ensureAnotherOwner(ctx context.Context, businessID, userID string) error {
+	users, err := s.userRepo.GetMembers(ctx, businessID)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if user.ID != userID && user.IsOwner() && user.IsActive() {
+			return nil
+		}
+	}
+
+	return errors.NewValidationError("a business must keep at least one active owner")
+}
+
+// getManager loads the acting user and checks they may manage the team
+func (s *UserService) getManager(ctx context.Context, businessID, actorID string) (*entities.User, error) {
+	actor, err := s.userRepo.GetMember(ctx, businessID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if actor == nil || !actor.IsActive() || !actor.Role.Can(entities.PermissionUsersManage) {
+		return nil, errors.NewForbiddenError("only owners and admins can manage the team")
+	}
+	return actor, nil
+}
+
+// getOwnedUser loads a member of the business with their role there
+func (s *UserService) getOwnedUser(ctx context.Context, businessID, userID string) (*entities.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.NewNotFoundError("user", userID)
+	}
+
+	member, err := s.userRepo.GetMember(ctx, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, errors.NewForbiddenError("access denied to this user")
+	}
+	return member, nil
+}
+
+// getHomeUser loads a user whose home business is this one. Deactivating
+// an account locks it out of every business, so members from elsewhere can
+// only be removed.
+func (s *UserService) getHomeUser(ctx context.Context, businessID, userID string) (*entities.User, error) {
+	user, err := s.getOwnedUser(ctx, businessID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	home, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if home.BusinessID != businessID {
+		return nil, errors.NewValidationError("this user belongs to another business; remove them from the team instead")
+	}
+	return user, nil
+}
+
+func mapUserToResponse(user *entities.User) dto.UserResponse {
+	return dto.UserResponse{
+		ID:            user.ID,
+		BusinessID:    user.BusinessID,
+		Email:         user.Email,
+		Role:          string(user.Role),
+		EmailVerified: user.IsEmailVerified(),
+		DeactivatedAt: formatOptionalTime(user.DeactivatedAt),
+		CreatedAt:     user.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func mapInvitationToResponse(invitation *entities.UserInvitation) dto.InvitationResponse {
+	return dto.InvitationResponse{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		Role:      string(invitation.Role),
+		InvitedBy: invitation.InvitedBy,
+		ExpiresAt: invitation.ExpiresAt.Format(time.RFC3339),
+		CreatedAt: invitation.CreatedAt.Format(time.RFC3339),
+	}
+}
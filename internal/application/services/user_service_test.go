@@ -0,0 +1,741 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/application/dto"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	domainerrors "github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// newMockUserService returns the team's user service and the auth service
+// that accepts its invitations
+func newMockUserService(users *mockUserRepository, invitations *mockInvitationRepository) (*UserService, *AuthService) {
+	log := logger.New("info", "console")
+	service := NewUserService(users, invitations, log)
+	auth := NewAuthService(users, newMockTeamBusinessRepository(), invitations, newMockSessionRepository(), testAuthConfig(), log)
+	return service, auth
+}
+
+// addMember makes user-other, an owner of business-456, an admin of
+// business-123
+func addMember(t *testing.T, users *mockUserRepository) {
+	t.Helper()
+	membership, err := entities.NewMembership("user-other", "business-123", entities.UserRoleAdmin)
+	if err != nil {
+		t.Fatalf("NewMembership() error = %v", err)
+	}
+	users.SaveMembership(context.Background(), membership)
+}
+
+func errorCode(err error) string {
+	if domainErr, ok := err.(*domainerrors.DomainError); ok {
+		return domainErr.Code
+	}
+	return ""
+}
+
+func TestUserService_InviteUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		actorID  string
+		req      dto.InviteUserRequest
+		wantCode string
+	}{
+		{
+			name:    "admin invites an employee",
+			actorID: "user-admin",
+			req:     dto.InviteUserRequest{Email: "new@example.com"},
+		},
+		{
+			name:    "owner invites an owner",
+			actorID: "user-owner",
+			req:     dto.InviteUserRequest{Email: "partner@example.com", Role: "owner"},
+		},
+		{
+			name:     "employee cannot invite",
+			actorID:  "user-employee",
+			req:      dto.InviteUserRequest{Email: "new@example.com"},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:     "admin cannot invite an owner",
+			actorID:  "user-admin",
+			req:      dto.InviteUserRequest{Email: "new@example.com", Role: "owner"},
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:    "user of another business",
+			actorID: "user-owner",
+			req:     dto.InviteUserRequest{Email: "other@example.com"},
+		},
+		{
+			name:     "existing member",
+			actorID:  "user-owner",
+			req:      dto.InviteUserRequest{Email: "employee@example.com"},
+			wantCode: domainerrors.ErrCodeAlreadyExists,
+		},
+		{
+			name:     "invalid role",
+			actorID:  "user-owner",
+			req:      dto.InviteUserRequest{Email: "new@example.com", Role: "manager"},
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invitations := newMockInvitationRepository()
+			service, _ := newMockUserService(newMockTeamUserRepository(), invitations)
+
+			response, err := service.InviteUser(context.Background(), "business-123", tt.actorID, tt.req)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Fatalf("expected %s error, got %v", tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("InviteUser() error = %v", err)
+			}
+			if response.Token == "" {
+				t.Error("expected the token to be returned once")
+			}
+			if stored := invitations.invitations[response.ID]; stored.TokenHash == response.Token || stored.TokenHash != hashToken(response.Token) {
+				t.Error("expected only the token hash to be stored")
+			}
+
+			if _, err := service.InviteUser(context.Background(), "business-123", tt.actorID, tt.req); errorCode(err) != domainerrors.ErrCodeAlreadyExists {
+				t.Errorf("expected a duplicate pending invitation to be rejected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthService_AcceptInvitation(t *testing.T) {
+	tests := []struct {
+		name        string
+		email       string
+		token       string
+		password    string
+		acceptFirst bool
+		wantCode    string
+		wantUserID  string
+	}{
+		{
+			name:     "new user",
+			email:    "new@example.com",
+			password: "secret123",
+		},
+		{
+			name:     "unknown token",
+			email:    "new@example.com",
+			token:    "wrong-token",
+			password: "secret123",
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name:        "token already used",
+			email:       "new@example.com",
+			password:    "secret123",
+			acceptFirst: true,
+			wantCode:    domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name:     "existing user with the wrong password",
+			email:    "other@example.com",
+			password: "wrong-password",
+			wantCode: domainerrors.ErrCodeUnauthorized,
+		},
+		{
+			name:       "existing user joins with their password",
+			email:      "other@example.com",
+			password:   "password123",
+			wantUserID: "user-other",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := newMockTeamUserRepository()
+			service, auth := newMockUserService(users, newMockInvitationRepository())
+
+			invitation, err := service.InviteUser(ctx, "business-123", "user-owner", dto.InviteUserRequest{Email: tt.email, Role: "admin"})
+			if err != nil {
+				t.Fatalf("InviteUser() error = %v", err)
+			}
+			req := dto.AcceptInvitationRequest{Token: invitation.Token, Password: tt.password}
+			if tt.token != "" {
+				req.Token = tt.token
+			}
+			if tt.acceptFirst {
+				if _, err := auth.AcceptInvitation(ctx, req); err != nil {
+					t.Fatalf("AcceptInvitation() error = %v", err)
+				}
+			}
+
+			login, err := auth.AcceptInvitation(ctx, req)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Fatalf("expected %s error, got %v", tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AcceptInvitation() error = %v", err)
+			}
+			if login.User.BusinessID != "business-123" || login.User.Role != "admin" || login.AccessToken == "" {
+				t.Errorf("expected to be signed in to business-123 as an admin, got %+v", login)
+			}
+
+			if tt.wantUserID == "" {
+				if _, err := auth.Login(ctx, dto.LoginRequest{Email: tt.email, Password: tt.password}); err != nil {
+					t.Errorf("expected the invited user to log in, got %v", err)
+				}
+				return
+			}
+			if login.User.ID != tt.wantUserID {
+				t.Errorf("expected %s to be signed in, got %s", tt.wantUserID, login.User.ID)
+			}
+			if home := users.users[tt.wantUserID]; home.BusinessID != "business-456" || home.Role != entities.UserRoleOwner {
+				t.Errorf("expected the home business to be unchanged, got %s as %s", home.BusinessID, home.Role)
+			}
+			if _, err := service.InviteUser(ctx, "business-123", "user-owner", dto.InviteUserRequest{Email: tt.email}); errorCode(err) != domainerrors.ErrCodeAlreadyExists {
+				t.Errorf("expected a member to be impossible to invite again, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthService_AcceptInvitationConcurrently(t *testing.T) {
+	tests := []struct {
+		name       string
+		concurrent func(t *testing.T, service *UserService, invitations *mockInvitationRepository, invitationID string)
+	}{
+		{
+			name: "accepted by another request",
+			concurrent: func(t *testing.T, service *UserService, invitations *mockInvitationRepository, invitationID string) {
+				now := time.Now()
+				invitations.invitations[invitationID].AcceptedAt = &now
+			},
+		},
+		{
+			name: "revoked by an admin",
+			concurrent: func(t *testing.T, service *UserService, invitations *mockInvitationRepository, invitationID string) {
+				if err := service.RevokeInvitation(context.Background(), "business-123", "user-admin", invitationID); err != nil {
+					t.Fatalf("RevokeInvitation() error = %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := newMockTeamUserRepository()
+			invitations := newMockInvitationRepository()
+			service, auth := newMockUserService(users, invitations)
+
+			invitation, err := service.InviteUser(ctx, "business-123", "user-admin", dto.InviteUserRequest{Email: "new@example.com", Role: "admin"})
+			if err != nil {
+				t.Fatalf("InviteUser() error = %v", err)
+			}
+
+			// The other request writes after this one has read the
+			// invitation as pending
+			invitations.beforeUpdate = func() {
+				invitations.beforeUpdate = nil
+				tt.concurrent(t, service, invitations, invitation.ID)
+			}
+
+			_, err = auth.AcceptInvitation(ctx, dto.AcceptInvitationRequest{Token: invitation.Token, Password: "secret123"})
+			if errorCode(err) != domainerrors.ErrCodeValidationError {
+				t.Errorf("expected the invitation to be redeemed only once, got %v", err)
+			}
+			if user, _ := users.GetByEmail(ctx, "new@example.com"); user != nil {
+				t.Error("expected no user to be created")
+			}
+		})
+	}
+}
+
+func TestUserService_ListUsers(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, users *mockUserRepository)
+		wantUsers int
+	}{
+		{
+			name:      "users of the business",
+			wantUsers: 3,
+		},
+		{
+			name:      "member from another business",
+			setup:     addMember,
+			wantUsers: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockTeamUserRepository()
+			if tt.setup != nil {
+				tt.setup(t, users)
+			}
+			service, _ := newMockUserService(users, newMockInvitationRepository())
+
+			listed, err := service.ListUsers(context.Background(), "business-123")
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			if len(listed) != tt.wantUsers {
+				t.Errorf("expected %d users, got %d", tt.wantUsers, len(listed))
+			}
+		})
+	}
+}
+
+func TestUserService_ChangeRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, users *mockUserRepository)
+		actorID  string
+		userID   string
+		role     string
+		wantCode string
+		wantRole entities.UserRole
+	}{
+		{
+			name:     "admin promotes an employee",
+			actorID:  "user-admin",
+			userID:   "user-employee",
+			role:     "admin",
+			wantRole: entities.UserRoleAdmin,
+		},
+		{
+			name:     "member from another business acts with their role here",
+			setup:    addMember,
+			actorID:  "user-other",
+			userID:   "user-employee",
+			role:     "admin",
+			wantRole: entities.UserRoleAdmin,
+		},
+		{
+			name:     "last owner cannot be demoted",
+			actorID:  "user-owner",
+			userID:   "user-owner",
+			role:     "admin",
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name: "owner can step down when another owner remains",
+			setup: func(t *testing.T, users *mockUserRepository) {
+				users.users["user-admin"].Role = entities.UserRoleOwner
+			},
+			actorID:  "user-owner",
+			userID:   "user-owner",
+			role:     "employee",
+			wantRole: entities.UserRoleEmployee,
+		},
+		{
+			name: "deactivated owners do not count",
+			setup: func(t *testing.T, users *mockUserRepository) {
+				users.users["user-admin"].Role = entities.UserRoleOwner
+				users.users["user-admin"].Deactivate()
+			},
+			actorID:  "user-owner",
+			userID:   "user-owner",
+			role:     "admin",
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:     "admin cannot change an owner",
+			actorID:  "user-admin",
+			userID:   "user-owner",
+			role:     "employee",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:     "admin cannot grant ownership",
+			actorID:  "user-admin",
+			userID:   "user-employee",
+			role:     "owner",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:     "employee cannot change roles",
+			actorID:  "user-employee",
+			userID:   "user-admin",
+			role:     "employee",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:     "user of another business",
+			actorID:  "user-owner",
+			userID:   "user-other",
+			role:     "employee",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockTeamUserRepository()
+			if tt.setup != nil {
+				tt.setup(t, users)
+			}
+			service, _ := newMockUserService(users, newMockInvitationRepository())
+
+			_, err := service.ChangeRole(context.Background(), "business-123", tt.actorID, tt.userID, dto.UpdateUserRoleRequest{Role: tt.role})
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Fatalf("expected %s error, got %v", tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ChangeRole() error = %v", err)
+			}
+			if got := users.users[tt.userID].Role; got != tt.wantRole {
+				t.Errorf("role = %s, want %s", got, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestUserService_DeactivateUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, users *mockUserRepository)
+		actorID  string
+		userID   string
+		wantCode string
+	}{
+		{
+			name:    "admin deactivates an employee",
+			actorID: "user-admin",
+			userID:  "user-employee",
+		},
+		{
+			name:     "yourself",
+			actorID:  "user-owner",
+			userID:   "user-owner",
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:     "admin cannot deactivate an owner",
+			actorID:  "user-admin",
+			userID:   "user-owner",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:     "member from another business is removed instead",
+			setup:    addMember,
+			actorID:  "user-owner",
+			userID:   "user-other",
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := newMockTeamUserRepository()
+			if tt.setup != nil {
+				tt.setup(t, users)
+			}
+			service, auth := newMockUserService(users, newMockInvitationRepository())
+
+			deactivated, err := service.DeactivateUser(ctx, "business-123", tt.actorID, tt.userID)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Fatalf("expected %s error, got %v", tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DeactivateUser() error = %v", err)
+			}
+			if deactivated.DeactivatedAt == nil {
+				t.Error("expected deactivated_at to be set")
+			}
+
+			if _, err := auth.Login(ctx, dto.LoginRequest{Email: deactivated.Email, Password: "password123"}); errorCode(err) != domainerrors.ErrCodeUnauthorized {
+				t.Errorf("expected a deactivated user to be unable to log in, got %v", err)
+			}
+			if _, err := service.InviteUser(ctx, "business-123", tt.userID, dto.InviteUserRequest{Email: "new@example.com"}); err == nil {
+				t.Error("expected a deactivated user to be unable to manage the team")
+			}
+		})
+	}
+}
+
+func TestUserService_ReactivateUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		actorID  string
+		userID   string
+		wantCode string
+	}{
+		{
+			name:    "admin reactivates an employee",
+			actorID: "user-admin",
+			userID:  "user-employee",
+		},
+		{
+			name:     "admin cannot reactivate an owner",
+			actorID:  "user-admin",
+			userID:   "user-owner",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:     "employee cannot reactivate users",
+			actorID:  "user-employee",
+			userID:   "user-admin",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := newMockTeamUserRepository()
+			users.users[tt.userID].Deactivate()
+			service, auth := newMockUserService(users, newMockInvitationRepository())
+
+			reactivated, err := service.ReactivateUser(ctx, "business-123", tt.actorID, tt.userID)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Fatalf("expected %s error, got %v", tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReactivateUser() error = %v", err)
+			}
+			if _, err := auth.Login(ctx, dto.LoginRequest{Email: reactivated.Email, Password: "password123"}); err != nil {
+				t.Errorf("expected a reactivated user to log in, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAuthService_ListBusinesses(t *testing.T) {
+	tests := []struct {
+		name             string
+		setup            func(t *testing.T, users *mockUserRepository)
+		userID           string
+		activeBusinessID string
+		want             []dto.BusinessMembershipResponse
+	}{
+		{
+			name:             "home business only",
+			userID:           "user-employee",
+			activeBusinessID: "business-123",
+			want: []dto.BusinessMembershipResponse{
+				{BusinessID: "business-123", Name: "Test Business", Role: "employee", Home: true, Active: true},
+			},
+		},
+		{
+			name:             "member of another business",
+			setup:            addMember,
+			userID:           "user-other",
+			activeBusinessID: "business-456",
+			want: []dto.BusinessMembershipResponse{
+				{BusinessID: "business-123", Name: "Test Business", Role: "admin"},
+				{BusinessID: "business-456", Name: "Other Business", Role: "owner", Home: true, Active: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newMockTeamUserRepository()
+			if tt.setup != nil {
+				tt.setup(t, users)
+			}
+			_, auth := newMockUserService(users, newMockInvitationRepository())
+
+			businesses, err := auth.ListBusinesses(context.Background(), tt.userID, tt.activeBusinessID)
+			if err != nil {
+				t.Fatalf("ListBusinesses() error = %v", err)
+			}
+			sort.Slice(businesses, func(i, j int) bool { return businesses[i].BusinessID < businesses[j].BusinessID })
+			if !reflect.DeepEqual(businesses, tt.want) {
+				t.Errorf("ListBusinesses() = %+v, want %+v", businesses, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthService_SwitchBusiness(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		businessID  string
+		fromSession bool
+		remove      bool
+		wantCode    string
+	}{
+		{
+			name:        "member switches their session",
+			userID:      "user-other",
+			businessID:  "business-123",
+			fromSession: true,
+		},
+		{
+			name:       "member without a session",
+			userID:     "user-other",
+			businessID: "business-123",
+		},
+		{
+			name:       "non-member",
+			userID:     "user-employee",
+			businessID: "business-456",
+			wantCode:   domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:       "API key",
+			businessID: "business-123",
+			wantCode:   domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:       "removed member",
+			userID:     "user-other",
+			businessID: "business-123",
+			remove:     true,
+			wantCode:   domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := newMockTeamUserRepository()
+			addMember(t, users)
+			service, auth := newMockUserService(users, newMockInvitationRepository())
+
+			login := teamLogin(t, auth, "other@example.com")
+			if claims := validClaims(t, auth, login.AccessToken); claims.BusinessID != "business-456" {
+				t.Fatalf("expected to sign in to the home business, got %s", claims.BusinessID)
+			}
+			sessionID := ""
+			if tt.fromSession {
+				sessionID = validClaims(t, auth, login.AccessToken).SessionID
+			}
+			if tt.remove {
+				if err := service.RemoveMember(ctx, tt.businessID, "user-owner", tt.userID); err != nil {
+					t.Fatalf("RemoveMember() error = %v", err)
+				}
+			}
+
+			switched, err := auth.SwitchBusiness(ctx, tt.userID, sessionID, dto.SwitchBusinessRequest{BusinessID: tt.businessID})
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Fatalf("expected %s error, got %v", tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SwitchBusiness() error = %v", err)
+			}
+			if claims := validClaims(t, auth, switched.AccessToken); claims.BusinessID != "business-123" || claims.Role != "admin" {
+				t.Errorf("expected an admin token for business-123, got %s as %s", claims.BusinessID, claims.Role)
+			}
+
+			refreshed, err := auth.RefreshToken(ctx, switched.RefreshToken)
+			if err != nil {
+				t.Fatalf("RefreshToken() error = %v", err)
+			}
+			if claims := validClaims(t, auth, refreshed.AccessToken); claims.BusinessID != "business-123" || claims.Role != "admin" {
+				t.Errorf("expected a refresh to stay in business-123, got %s as %s", claims.BusinessID, claims.Role)
+			}
+
+			_, err = auth.RefreshToken(ctx, login.RefreshToken)
+			if tt.fromSession && err == nil {
+				t.Error("expected the session replaced by the switch to stop working")
+			}
+			if !tt.fromSession && err != nil {
+				t.Errorf("expected the home session to keep working, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUserService_RemoveMember(t *testing.T) {
+	tests := []struct {
+		name        string
+		actorID     string
+		userID      string
+		removeFirst bool
+		wantCode    string
+	}{
+		{
+			name:    "owner removes a member",
+			actorID: "user-owner",
+			userID:  "user-other",
+		},
+		{
+			name:     "user of the business is deactivated instead",
+			actorID:  "user-owner",
+			userID:   "user-employee",
+			wantCode: domainerrors.ErrCodeValidationError,
+		},
+		{
+			name:     "employee cannot remove members",
+			actorID:  "user-employee",
+			userID:   "user-other",
+			wantCode: domainerrors.ErrCodeForbidden,
+		},
+		{
+			name:        "removed twice",
+			actorID:     "user-owner",
+			userID:      "user-other",
+			removeFirst: true,
+			wantCode:    domainerrors.ErrCodeForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := newMockTeamUserRepository()
+			addMember(t, users)
+			service, auth := newMockUserService(users, newMockInvitationRepository())
+
+			// The member is signed in to business-123 when removed
+			switched, err := auth.SwitchBusiness(ctx, "user-other", "", dto.SwitchBusinessRequest{BusinessID: "business-123"})
+			if err != nil {
+				t.Fatalf("SwitchBusiness() error = %v", err)
+			}
+			if tt.removeFirst {
+				if err := service.RemoveMember(ctx, "business-123", tt.actorID, tt.userID); err != nil {
+					t.Fatalf("RemoveMember() error = %v", err)
+				}
+			}
+
+			err = service.RemoveMember(ctx, "business-123", tt.actorID, tt.userID)
+			if tt.wantCode != "" {
+				if errorCode(err) != tt.wantCode {
+					t.Fatalf("expected %s error, got %v", tt.wantCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RemoveMember() error = %v", err)
+			}
+			if _, err := auth.RefreshToken(ctx, switched.RefreshToken); errorCode(err) != domainerrors.ErrCodeUnauthorized {
+				t.Errorf("expected a removed member to be unable to refresh, got %v", err)
+			}
+			if _, err := auth.Login(ctx, dto.LoginRequest{Email: "other@example.com", Password: "password123"}); err != nil {
+				t.Errorf("expected the home business to be unaffected, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package entities
+
+import (
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+// APIKeyPrefix starts every API key so they can be told apart from JWTs
+const APIKeyPrefix = "cpk_"
+
+// apiKeyScopes are the permissions an API key can be granted. Actions that
+// are attributed to a person, like acknowledging messages or working the
+// inbox, and managing the business or its team are left to users.
+var apiKeyScopes = permissionSet([]Permission{
+	PermissionBusinessRead,
+	PermissionCallsRead,
+	PermissionCallsCreate,
+	PermissionAppointmentsRead,
+	PermissionAppointmentsUpdate,
+	PermissionCampaignsRead,
+	PermissionCampaignsWrite,
+	PermissionScheduledCallsRead,
+	PermissionScheduledCallsWrite,
+	PermissionTransfersRead,
+	PermissionMessagesRead,
+	PermissionInboxRead,
+	PermissionComplianceRead,
+	PermissionComplianceWrite,
+	PermissionAnalyticsRead,
+})
+
+// IsAPIKeyScope reports whether an API key can be granted the permission
+func IsAPIKeyScope(permission Permission) bool {
+	return apiKeyScopes[permission]
+}
+
+// APIKey lets a back-office system call the API on behalf of a business
+// without a user. The key is only shown once; its hash is stored, along with
+// a short prefix to recognise it by.
+type APIKey struct {
+	ID         string       `json:"id"`
+	BusinessID string       `json:"business_id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	KeyHash    string       `json:"-"`
+	Scopes     []Permission `json:"scopes"`
+	CreatedBy  string       `json:"created_by"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time   `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+func NewAPIKey(businessID, name string, scopes []Permission, createdBy, prefix, keyHash string, expiresAt *time.Time) (*APIKey, error) {
+	if businessID == "" {
+		return nil, errors.NewValidationError("business_id is required")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.NewFieldValidationError("name", "name is required")
+	}
+	if len(name) > 100 {
+		return nil, errors.NewFieldValidationError("name", "name must be at most 100 characters")
+	}
+	if len(scopes) == 0 {
+		return nil, errors.NewFieldValidationError("scopes", "at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !IsAPIKeyScope(scope) {
+			return nil, errors.NewFieldValidationError("scopes", "API keys cannot be granted "+string(scope))
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, errors.NewFieldValidationError("expires_at", "expires_at must be in the future")
+	}
+	if keyHash == "" {
+		return nil, errors.NewValidationError("key is required")
+	}
+
+	return &APIKey{
+		BusinessID: businessID,
+		Name:       name,
+		Prefix:     prefix,
+		KeyHash:    keyHash,
+		Scopes:     scopes,
+		CreatedBy:  createdBy,
+		ExpiresAt:  expiresAt,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// IsActive reports whether the key can still be used
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// HasScope reports whether the key was granted the permission
+func (k *APIKey) HasScope(permission Permission) bool {
+	for _, scope := range k.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *APIKey) Revoke(now time.Time) error {
+	if k.RevokedAt != nil {
+		return errors.NewValidationError("API key is already revoked")
+	}
+	k.RevokedAt = &now
+	return nil
+}
@@ -44,6 +44,9 @@ func NewAppointmentRequest(
 	if customerPhone == "" {
 		return nil, errors.NewValidationError("customer_phone is required")
 	}
+	if err := validatePhone("customer_phone", customerPhone); err != nil {
+		return nil, err
+	}
 
 	now := time.Now()
 	return &AppointmentRequest{
@@ -95,6 +98,19 @@ func (a *AppointmentRequest) IsConfirmed() bool {
 	return a.Status == AppointmentStatusConfirmed
 }
 
+// IsUpcoming reports whether the appointment is still active and falls on or
+// after the day of now
+func (a *AppointmentRequest) IsUpcoming(now time.Time) bool {
+	if a.Status != AppointmentStatusPending && a.Status != AppointmentStatusConfirmed {
+		return false
+	}
+	if a.RequestedDate == nil {
+		return false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return !a.RequestedDate.Before(today)
+}
+
 func (a *AppointmentRequest) Validate() error {
 	if a.CallID == "" {
 		return errors.NewValidationError("call_id is required")
@@ -105,5 +121,5 @@ func (a *AppointmentRequest) Validate() error {
 	if a.CustomerPhone == "" {
 		return errors.NewValidationError("customer_phone is required")
 	}
-	return nil
+	return validatePhone("customer_phone", a.CustomerPhone)
 }
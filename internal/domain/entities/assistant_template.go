@@ -0,0 +1,138 @@
+package entities
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+// Limits on what a business can override in its assistant template
+const (
+	maxTemplatePromptLength       = 20000
+	maxTemplateFirstMessageLength = 1000
+	maxInteractionFields          = 30
+)
+
+var interactionFieldName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// InteractionField is something the assistant collects from callers. The
+// fields are listed in the assistant's prompt and extracted from each
+// finished call as an interaction.
+type InteractionField struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// AssistantTemplate is the starting point for the assistant of one kind of
+// business. The prompt and first message use {{variables}}, such as
+// {{business.name}} and {{hours}}, that are filled in for each business.
+type AssistantTemplate struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	// BusinessTypes are the business types the template is chosen for
+	BusinessTypes []string `json:"business_types,omitempty"`
+	Prompt        string   `json:"prompt"`
+	FirstMessage  string   `json:"first_message"`
+	// Functions names the call tools the assistant is given
+	Functions         []string           `json:"functions"`
+	InteractionFields []InteractionField `json:"interaction_fields"`
+	// Services are offered to callers when the business has not listed its
+	// own
+	Services []OfferedService `json:"services"`
+}
+
+// Matches reports whether the template is meant for the business type
+func (t AssistantTemplate) Matches(businessType string) bool {
+	businessType = NormalizeBusinessType(businessType)
+	for _, candidate := range t.BusinessTypes {
+		if NormalizeBusinessType(candidate) == businessType {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeBusinessType lowercases the type and joins its words with
+// underscores, so "Dental Clinic" and "dental-clinic" are the same type
+func NormalizeBusinessType(businessType string) string {
+	fields := strings.FieldsFunc(strings.ToLower(businessType), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	})
+	return strings.Join(fields, "_")
+}
+
+// AssistantTemplateOverride is a business's changes to its assistant
+// template. Template picks a template other than the one for the business's
+// type. Empty text and nil lists keep the template's; an empty list
+// replaces the template's with nothing.
+type AssistantTemplateOverride struct {
+	BusinessID        string             `json:"business_id"`
+	Template          string             `json:"template,omitempty"`
+	Prompt            string             `json:"prompt,omitempty"`
+	FirstMessage      string             `json:"first_message,omitempty"`
+	Functions         []string           `json:"functions"`
+	InteractionFields []InteractionField `json:"interaction_fields"`
+	Services          []OfferedService   `json:"services"`
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
+}
+
+// Validate checks the override. Whether the template and functions exist is
+// checked against the template library.
+func (o *AssistantTemplateOverride) Validate() error {
+	if o.BusinessID == "" {
+		return errors.NewValidationError("business_id is required")
+	}
+	if len(o.Prompt) > maxTemplatePromptLength {
+		return errors.NewFieldValidationError("prompt", fmt.Sprintf("prompt must be at most %d characters", maxTemplatePromptLength))
+	}
+	if len(o.FirstMessage) > maxTemplateFirstMessageLength {
+		return errors.NewFieldValidationError("first_message", fmt.Sprintf("first message must be at most %d characters", maxTemplateFirstMessageLength))
+	}
+
+	if len(o.InteractionFields) > maxInteractionFields {
+		return errors.NewFieldValidationError("interaction_fields", fmt.Sprintf("at most %d interaction fields can be listed", maxInteractionFields))
+	}
+	seen := make(map[string]bool, len(o.InteractionFields))
+	for i, field := range o.InteractionFields {
+		if !interactionFieldName.MatchString(field.Name) {
+			return errors.NewFieldValidationError(fmt.Sprintf("interaction_fields[%d].name", i), "name must be lowercase letters, digits and underscores, starting with a letter")
+		}
+		if strings.TrimSpace(field.Description) == "" {
+			return errors.NewFieldValidationError(fmt.Sprintf("interaction_fields[%d].description", i), "description is required")
+		}
+		if field.Name == "type" {
+			return errors.NewFieldValidationError(fmt.Sprintf("interaction_fields[%d].name", i), "type is reserved for the interaction type")
+		}
+		if seen[field.Name] {
+			return errors.NewFieldValidationError(fmt.Sprintf("interaction_fields[%d].name", i), "name is listed more than once")
+		}
+		seen[field.Name] = true
+	}
+
+	return validateOfferedServices(o.Services)
+}
+
+// Apply returns the template with the override's changes
+func (o *AssistantTemplateOverride) Apply(template AssistantTemplate) AssistantTemplate {
+	if o.Prompt != "" {
+		template.Prompt = o.Prompt
+	}
+	if o.FirstMessage != "" {
+		template.FirstMessage = o.FirstMessage
+	}
+	if o.Functions != nil {
+		template.Functions = o.Functions
+	}
+	if o.InteractionFields != nil {
+		template.InteractionFields = o.InteractionFields
+	}
+	if o.Services != nil {
+		template.Services = o.Services
+	}
+	return template
+}
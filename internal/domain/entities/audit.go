@@ -0,0 +1,151 @@
+package entities
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+// AuditActorType is who took an audited action
+type AuditActorType string
+
+const (
+	AuditActorUser   AuditActorType = "user"
+	AuditActorAPIKey AuditActorType = "api_key"
+	AuditActorSystem AuditActorType = "system"
+)
+
+// Audited actions, named after the target and what happened to it
+const (
+	AuditActionLogin               = "auth.login"
+	AuditActionLoginFailed         = "auth.login_failed"
+	AuditActionLogout              = "auth.logout"
+	AuditActionLogoutAll           = "auth.logout_all"
+	AuditActionBusinessSwitched    = "auth.business_switched"
+	AuditActionPasswordReset       = "auth.password_reset"
+	AuditActionMFAEnabled          = "mfa.enabled"
+	AuditActionMFADisabled         = "mfa.disabled"
+	AuditActionBusinessUpdated     = "business.updated"
+	AuditActionSSOSaved            = "sso.connection_saved"
+	AuditActionSSODeleted          = "sso.connection_deleted"
+	AuditActionSSOLinked           = "sso.identity_linked"
+	AuditActionUserInvited         = "user.invited"
+	AuditActionInvitationRevoked   = "user.invitation_revoked"
+	AuditActionInvitationAccepted  = "user.invitation_accepted"
+	AuditActionUserRoleChanged     = "user.role_changed"
+	AuditActionUserDeactivated     = "user.deactivated"
+	AuditActionUserReactivated     = "user.reactivated"
+	AuditActionUserUnlocked        = "user.unlocked"
+	AuditActionMemberRemoved       = "user.member_removed"
+	AuditActionAPIKeyCreated       = "api_key.created"
+	AuditActionAPIKeyRevoked       = "api_key.revoked"
+	AuditActionCallInitiated       = "call.initiated"
+	AuditActionAppointmentUpdated  = "appointment.updated"
+	AuditActionChildCreated        = "agency.child_created"
+	AuditActionChildUpdated        = "agency.child_updated"
+	AuditActionImpersonated        = "agency.impersonated"
+	AuditActionAssistantPushed     = "agency.assistant_pushed"
+	AuditActionBusinessSuspended   = "business.suspended"
+	AuditActionBusinessReactivated = "business.reactivated"
+	AuditActionCallReconciled      = "call.reconciled"
+	AuditActionOnboardingStarted   = "business.onboarding_started"
+	AuditActionOnboardingCompleted = "business.onboarding_completed"
+	AuditActionTemplateSaved       = "business.assistant_template_saved"
+	AuditActionTemplateReset       = "business.assistant_template_reset"
+	AuditActionAssistantGenerated  = "business.assistant_generated"
+	AuditActionBusinessesSearched  = "admin.businesses_searched"
+	AuditActionBusinessViewed      = "admin.business_viewed"
+	AuditActionCallViewed          = "admin.call_viewed"
+	AuditActionWebhooksSearched    = "admin.webhooks_searched"
+	AuditActionWebhookViewed       = "admin.webhook_viewed"
+	AuditActionUsageViewed         = "admin.usage_viewed"
+	AuditActionAuditSearched       = "admin.audit_searched"
+	AuditActionDoNotCallViewed     = "admin.do_not_call_viewed"
+	AuditActionDoNotCallAdded      = "admin.do_not_call_added"
+	AuditActionDoNotCallRemoved    = "admin.do_not_call_removed"
+)
+
+// AuditActor is who took an action and where their request came from.
+// AgencyID is set when a user of an agency acts in one of its child
+// businesses.
+type AuditActor struct {
+	Type      AuditActorType
+	ID        string
+	AgencyID  string
+	IP        string
+	UserAgent string
+}
+
+// SystemActor is the actor for actions the backend takes on its own, such as
+// scheduled calls
+var SystemActor = AuditActor{Type: AuditActorSystem}
+
+// AuditChange is the value of a field before and after an action
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditEntry records an action taken in a business. Entries are never
+// changed once written; they are only deleted when they pass the retention
+// period.
+type AuditEntry struct {
+	ID         string                 `json:"id"`
+	BusinessID string                 `json:"business_id,omitempty"`
+	ActorType  AuditActorType         `json:"actor_type"`
+	ActorID    string                 `json:"actor_id,omitempty"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"target_type,omitempty"`
+	TargetID   string                 `json:"target_id,omitempty"`
+	Changes    map[string]AuditChange `json:"changes,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	IP         string                 `json:"ip,omitempty"`
+	UserAgent  string                 `json:"user_agent,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+func NewAuditEntry(businessID string, actor AuditActor, action, targetType, targetID string, changes map[string]AuditChange) (*AuditEntry, error) {
+	if action == "" {
+		return nil, errors.NewValidationError("audit action is required")
+	}
+	switch actor.Type {
+	case AuditActorUser, AuditActorAPIKey, AuditActorSystem:
+	default:
+		return nil, errors.NewValidationError("invalid audit actor type")
+	}
+
+	entry := &AuditEntry{
+		BusinessID: businessID,
+		ActorType:  actor.Type,
+		ActorID:    actor.ID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Changes:    changes,
+		IP:         actor.IP,
+		UserAgent:  actor.UserAgent,
+		CreatedAt:  time.Now(),
+	}
+	if actor.AgencyID != "" {
+		entry.Metadata = map[string]interface{}{"agency_id": actor.AgencyID}
+	}
+	return entry, nil
+}
+
+// DiffFields returns the fields whose values differ between before and
+// after, including fields only one of them has
+func DiffFields(before, after map[string]interface{}) map[string]AuditChange {
+	changes := make(map[string]AuditChange)
+	for key, old := range before {
+		if value, ok := after[key]; !ok || !reflect.DeepEqual(old, value) {
+			changes[key] = AuditChange{Before: old, After: after[key]}
+		}
+	}
+	for key, value := range after {
+		if _, ok := before[key]; !ok {
+			changes[key] = AuditChange{After: value}
+		}
+	}
+	return changes
+}
@@ -4,25 +4,40 @@ import (
 	"time"
 
 	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/pkg/phone"
 )
 
 type Business struct {
-	ID        string                 `json:"id"`
-	Name      string                 `json:"name"`
-	Type      string                 `json:"type"`
-	Phone     string                 `json:"phone"`
-	Settings  map[string]interface{} `json:"settings"`
-	CreatedAt time.Time              `json:"created_at"`
-	UpdatedAt time.Time              `json:"updated_at"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// ParentID is the agency that manages the business, if any. Agencies
+	// resell the receptionist to their child businesses; the hierarchy is one
+	// level deep.
+	ParentID *string          `json:"parent_id,omitempty"`
+	Phone    string           `json:"phone"`
+	Settings BusinessSettings `json:"settings"`
+	// SuspendedAt is set while a platform operator has suspended the
+	// business. Its users cannot sign in and it cannot make or take calls.
+	SuspendedAt      *time.Time `json:"suspended_at,omitempty"`
+	SuspensionReason string     `json:"suspension_reason,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
 }
 
-func NewBusiness(name, businessType, phone string, settings map[string]interface{}) (*Business, error) {
+func NewBusiness(name, businessType, phone string, settings BusinessSettings) (*Business, error) {
 	if name == "" {
 		return nil, errors.NewValidationError("business name is required")
 	}
 	if phone == "" {
 		return nil, errors.NewValidationError("business phone is required")
 	}
+	if err := validatePhone("phone", phone); err != nil {
+		return nil, err
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
 
 	now := time.Now()
 	return &Business{
@@ -35,7 +50,69 @@ func NewBusiness(name, businessType, phone string, settings map[string]interface
 	}, nil
 }
 
-func (b *Business) Update(name, businessType, phone string, settings map[string]interface{}) error {
+// NewChildBusiness creates a business managed by the agency
+func NewChildBusiness(agency *Business, name, businessType, phone string, settings BusinessSettings) (*Business, error) {
+	if !agency.CanHaveChildren() {
+		return nil, errors.NewValidationError("a child business cannot have children of its own")
+	}
+
+	business, err := NewBusiness(name, businessType, phone, settings)
+	if err != nil {
+		return nil, err
+	}
+	parentID := agency.ID
+	business.ParentID = &parentID
+	return business, nil
+}
+
+// CanHaveChildren reports whether the business can act as an agency. Child
+// businesses cannot.
+func (b *Business) CanHaveChildren() bool {
+	return b.ParentID == nil
+}
+
+// IsChildOf reports whether the agency manages the business
+func (b *Business) IsChildOf(agencyID string) bool {
+	return b.ParentID != nil && *b.ParentID == agencyID
+}
+
+// IsSuspended reports whether a platform operator has suspended the business
+func (b *Business) IsSuspended() bool {
+	return b.SuspendedAt != nil
+}
+
+// Suspend blocks the business's sign-ins and calls until it is reactivated
+func (b *Business) Suspend(reason string, now time.Time) error {
+	if b.IsSuspended() {
+		return errors.NewValidationError("business is already suspended")
+	}
+	if reason == "" {
+		return errors.NewFieldValidationError("reason", "a reason is required to suspend a business")
+	}
+	b.SuspendedAt = &now
+	b.SuspensionReason = reason
+	b.UpdatedAt = now
+	return nil
+}
+
+// Reactivate lifts a suspension
+func (b *Business) Reactivate(now time.Time) error {
+	if !b.IsSuspended() {
+		return errors.NewValidationError("business is not suspended")
+	}
+	b.SuspendedAt = nil
+	b.SuspensionReason = ""
+	b.UpdatedAt = now
+	return nil
+}
+
+func (b *Business) Update(name, businessType, phone string) error {
+	if phone != "" {
+		if err := validatePhone("phone", phone); err != nil {
+			return err
+		}
+	}
+
 	if name != "" {
 		b.Name = name
 	}
@@ -45,9 +122,18 @@ func (b *Business) Update(name, businessType, phone string, settings map[string]
 	if phone != "" {
 		b.Phone = phone
 	}
-	if settings != nil {
-		b.Settings = settings
+	b.UpdatedAt = time.Now()
+	return nil
+}
+
+// UpdateSettings replaces the settings once they validate. Use
+// BusinessSettings.Merge to change some of them.
+func (b *Business) UpdateSettings(settings BusinessSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
 	}
+	settings.Version = CurrentSettingsVersion
+	b.Settings = settings
 	b.UpdatedAt = time.Now()
 	return nil
 }
@@ -59,5 +145,76 @@ func (b *Business) Validate() error {
 	if b.Phone == "" {
 		return errors.NewValidationError("business phone is required")
 	}
-	return nil
+	return validatePhone("phone", b.Phone)
+}
+
+// EffectiveSettings returns the business's settings, or the defaults when
+// they were never set
+func (b *Business) EffectiveSettings() BusinessSettings {
+	if b.Settings.Version == 0 {
+		return DefaultBusinessSettings()
+	}
+	return b.Settings
+}
+
+// AssistantID returns the assistant that answers inbound calls, if configured
+func (b *Business) AssistantID() string {
+	return b.EffectiveSettings().AssistantID
+}
+
+// CallerRecognitionEnabled reports whether the business opted in to
+// personalising calls with the caller's history
+func (b *Business) CallerRecognitionEnabled() bool {
+	return b.EffectiveSettings().CallerRecognition
+}
+
+// DefaultRegion returns the region used to read phone numbers written in
+// national format, such as "(555) 123-4567"
+func (b *Business) DefaultRegion() string {
+	if region := b.EffectiveSettings().DefaultRegion; region != "" {
+		return region
+	}
+	return phone.DefaultRegion
+}
+
+// Timezone returns the IANA time zone the business operates in
+func (b *Business) Timezone() string {
+	if tz := b.EffectiveSettings().Timezone; tz != "" {
+		return tz
+	}
+	return "UTC"
+}
+
+// CallingWindow returns the hours during which outbound calls may be placed
+func (b *Business) CallingWindow() (CallingWindow, error) {
+	window := b.EffectiveSettings().CallingWindow
+	return ParseCallingWindow(window.Start, window.End)
+}
+
+// RequiresConsent reports whether outbound calls need a consent record
+func (b *Business) RequiresConsent() bool {
+	return b.EffectiveSettings().RequireConsent
+}
+
+// RequiresAdminMFA reports whether owners and admins must use two-factor
+// authentication
+func (b *Business) RequiresAdminMFA() bool {
+	return b.EffectiveSettings().RequireAdminMFA
+}
+
+// AutoCallback returns the rule for calling back missed and dropped calls
+func (b *Business) AutoCallback() AutoCallbackRule {
+	return b.EffectiveSettings().AutoCallback
+}
+
+// NotifiesMessage reports whether a message of the urgency notifies its
+// recipient
+func (b *Business) NotifiesMessage(urgency MessageUrgency) bool {
+	notifications := b.EffectiveSettings().Notifications
+	return notifications.Messages && (!notifications.UrgentOnly || urgency == MessageUrgencyUrgent)
+}
+
+// Hours returns the business's opening hours in its time zone
+func (b *Business) Hours() WeeklyHours {
+	return b.EffectiveSettings().Hours
 }
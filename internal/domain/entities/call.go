@@ -18,17 +18,25 @@ const (
 	CallStatusBusy       CallStatus = "busy"
 )
 
+type CallDirection string
+
+const (
+	CallDirectionInbound  CallDirection = "inbound"
+	CallDirectionOutbound CallDirection = "outbound"
+)
+
 type Call struct {
-	ID             string     `json:"id"`
-	BusinessID     string     `json:"business_id"`
-	ProviderCallID string     `json:"provider_call_id"`
-	CallerPhone    string     `json:"caller_phone"`
-	Duration       int        `json:"duration"` // in seconds
-	Status         CallStatus `json:"status"`
-	Cost           float64    `json:"cost"`
-	StartedAt      *time.Time `json:"started_at,omitempty"`
-	EndedAt        *time.Time `json:"ended_at,omitempty"`
-	CreatedAt      time.Time  `json:"created_at"`
+	ID             string        `json:"id"`
+	BusinessID     string        `json:"business_id"`
+	ProviderCallID string        `json:"provider_call_id"`
+	CallerPhone    string        `json:"caller_phone"`
+	Direction      CallDirection `json:"direction"`
+	Duration       int           `json:"duration"` // in seconds
+	Status         CallStatus    `json:"status"`
+	Cost           float64       `json:"cost"`
+	StartedAt      *time.Time    `json:"started_at,omitempty"`
+	EndedAt        *time.Time    `json:"ended_at,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
 }
 
 func NewCall(businessID, callerPhone string) (*Call, error) {
@@ -38,15 +46,31 @@ func NewCall(businessID, callerPhone string) (*Call, error) {
 	if callerPhone == "" {
 		return nil, errors.NewValidationError("caller_phone is required")
 	}
+	if err := validatePhone("caller_phone", callerPhone); err != nil {
+		return nil, err
+	}
 
 	return &Call{
 		BusinessID:  businessID,
 		CallerPhone: callerPhone,
+		Direction:   CallDirectionOutbound,
 		Status:      CallStatusInitiated,
 		CreatedAt:   time.Now(),
 	}, nil
 }
 
+// NewInboundCall records a call placed to the business by a customer
+func NewInboundCall(businessID, callerPhone, providerCallID string) (*Call, error) {
+	call, err := NewCall(businessID, callerPhone)
+	if err != nil {
+		return nil, err
+	}
+	call.Direction = CallDirectionInbound
+	call.Status = CallStatusRinging
+	call.ProviderCallID = providerCallID
+	return call, nil
+}
+
 func (c *Call) UpdateStatus(status CallStatus) error {
 	validStatuses := map[CallStatus]bool{
 		CallStatusInitiated:  true,
@@ -95,6 +119,12 @@ func (c *Call) IsCompleted() bool {
 		c.Status == CallStatusNoAnswer || c.Status == CallStatusBusy
 }
 
+func (c *Call) IsInbound() bool {
+	return c.Direction == CallDirectionInbound
+}
+
+// Validate checks a stored call. The number's format is only checked by
+// NewCall, as calls from before numbers were normalised may hold any.
 func (c *Call) Validate() error {
 	if c.BusinessID == "" {
 		return errors.NewValidationError("business_id is required")
@@ -0,0 +1,76 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CallerProfile summarises what the business already knows about a phone
+// number, built from previous appointments and interactions
+type CallerProfile struct {
+	Phone                string                `json:"phone"`
+	Name                 string                `json:"name,omitempty"`
+	UpcomingAppointments []*AppointmentRequest `json:"upcoming_appointments,omitempty"`
+	RecentIssues         []*Interaction        `json:"recent_issues,omitempty"`
+}
+
+func NewCallerProfile(phone string) *CallerProfile {
+	return &CallerProfile{Phone: phone}
+}
+
+// IsKnown reports whether the caller has any history with the business
+func (p *CallerProfile) IsKnown() bool {
+	return p.Name != "" || len(p.UpcomingAppointments) > 0 || len(p.RecentIssues) > 0
+}
+
+// Reference identifies the caller in call metadata. The rest of the profile
+// stays with the business; the assistant only sees it through Variables.
+func (p *CallerProfile) Reference() map[string]interface{} {
+	return map[string]interface{}{
+		"phone": p.Phone,
+		"name":  p.Name,
+	}
+}
+
+// Variables returns the values substituted into assistant prompt placeholders
+func (p *CallerProfile) Variables() map[string]interface{} {
+	appointments := make([]string, 0, len(p.UpcomingAppointments))
+	for _, apt := range p.UpcomingAppointments {
+		appointments = append(appointments, describeAppointment(apt))
+	}
+
+	issues := make([]string, 0, len(p.RecentIssues))
+	for _, issue := range p.RecentIssues {
+		issues = append(issues, describeInteraction(issue))
+	}
+
+	return map[string]interface{}{
+		"customer_name":         p.Name,
+		"known_customer":        p.IsKnown(),
+		"upcoming_appointments": strings.Join(appointments, "; "),
+		"recent_issues":         strings.Join(issues, "; "),
+	}
+}
+
+func describeAppointment(apt *AppointmentRequest) string {
+	parts := []string{}
+	if apt.ServiceType != "" {
+		parts = append(parts, apt.ServiceType)
+	} else {
+		parts = append(parts, "appointment")
+	}
+	if apt.RequestedDate != nil {
+		parts = append(parts, "on "+apt.RequestedDate.Format("Monday, January 2"))
+	}
+	if apt.RequestedTime != "" {
+		parts = append(parts, "at "+apt.RequestedTime)
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(parts, " "), apt.Status)
+}
+
+func describeInteraction(i *Interaction) string {
+	if summary := i.Summary(); summary != "" {
+		return summary
+	}
+	return fmt.Sprintf("%s on %s", i.Type, i.Timestamp.Format("January 2"))
+}
@@ -0,0 +1,311 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type CampaignStatus string
+
+const (
+	CampaignStatusDraft     CampaignStatus = "draft"
+	CampaignStatusRunning   CampaignStatus = "running"
+	CampaignStatusPaused    CampaignStatus = "paused"
+	CampaignStatusCompleted CampaignStatus = "completed"
+	CampaignStatusCancelled CampaignStatus = "cancelled"
+)
+
+const (
+	maxCampaignConcurrency = 50
+	maxCampaignCallRate    = 120 // calls per minute
+	maxCampaignAttempts    = 10
+)
+
+// RetryPolicy decides which call outcomes are tried again and how often
+type RetryPolicy struct {
+	MaxAttempts       int          `json:"max_attempts"`
+	RetryDelayMinutes int          `json:"retry_delay_minutes"`
+	RetryOn           []CallStatus `json:"retry_on"`
+}
+
+// DefaultRetryPolicy tries unanswered and busy numbers twice more, an hour apart
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:       3,
+	RetryDelayMinutes: 60,
+	RetryOn:           []CallStatus{CallStatusNoAnswer, CallStatusBusy},
+}
+
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 || p.MaxAttempts > maxCampaignAttempts {
+		return errors.NewFieldValidationError("retry_policy.max_attempts", "max_attempts must be between 1 and 10")
+	}
+	if p.RetryDelayMinutes < 0 {
+		return errors.NewFieldValidationError("retry_policy.retry_delay_minutes", "retry_delay_minutes cannot be negative")
+	}
+	for _, status := range p.RetryOn {
+		if status != CallStatusNoAnswer && status != CallStatusBusy && status != CallStatusFailed {
+			return errors.NewFieldValidationError("retry_policy.retry_on", "only no_answer, busy and failed calls can be retried")
+		}
+	}
+	return nil
+}
+
+// ShouldRetry reports whether a call that ended with status on the given
+// attempt should be tried again
+func (p RetryPolicy) ShouldRetry(status CallStatus, attempts int) bool {
+	if attempts >= p.MaxAttempts {
+		return false
+	}
+	for _, retryable := range p.RetryOn {
+		if retryable == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Campaign is a batch of outbound calls placed by the dialer at a controlled
+// pace between StartsAt and EndsAt
+type Campaign struct {
+	ID             string         `json:"id"`
+	BusinessID     string         `json:"business_id"`
+	Name           string         `json:"name"`
+	AssistantID    string         `json:"assistant_id,omitempty"`
+	Status         CampaignStatus `json:"status"`
+	MaxConcurrent  int            `json:"max_concurrent"`
+	CallsPerMinute int            `json:"calls_per_minute"`
+	RetryPolicy    RetryPolicy    `json:"retry_policy"`
+	StartsAt       time.Time      `json:"starts_at"`
+	EndsAt         *time.Time     `json:"ends_at,omitempty"`
+	StartedAt      *time.Time     `json:"started_at,omitempty"`
+	CompletedAt    *time.Time     `json:"completed_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+func NewCampaign(businessID, name, assistantID string, maxConcurrent, callsPerMinute int, retryPolicy RetryPolicy, startsAt time.Time, endsAt *time.Time) (*Campaign, error) {
+	if businessID == "" {
+		return nil, errors.NewValidationError("business_id is required")
+	}
+	if name == "" {
+		return nil, errors.NewFieldValidationError("name", "campaign name is required")
+	}
+
+	now := time.Now()
+	if startsAt.IsZero() {
+		startsAt = now
+	}
+
+	campaign := &Campaign{
+		BusinessID:     businessID,
+		Name:           name,
+		AssistantID:    assistantID,
+		Status:         CampaignStatusDraft,
+		MaxConcurrent:  maxConcurrent,
+		CallsPerMinute: callsPerMinute,
+		RetryPolicy:    retryPolicy,
+		StartsAt:       startsAt,
+		EndsAt:         endsAt,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	if err := campaign.Validate(); err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+func (c *Campaign) Validate() error {
+	if c.MaxConcurrent < 1 || c.MaxConcurrent > maxCampaignConcurrency {
+		return errors.NewFieldValidationError("max_concurrent", "max_concurrent must be between 1 and 50")
+	}
+	if c.CallsPerMinute < 1 || c.CallsPerMinute > maxCampaignCallRate {
+		return errors.NewFieldValidationError("calls_per_minute", "calls_per_minute must be between 1 and 120")
+	}
+	if c.EndsAt != nil && !c.EndsAt.After(c.StartsAt) {
+		return errors.NewFieldValidationError("ends_at", "ends_at must be after starts_at")
+	}
+	return c.RetryPolicy.Validate()
+}
+
+func (c *Campaign) Start() error {
+	if c.Status != CampaignStatusDraft && c.Status != CampaignStatusPaused {
+		return errors.NewValidationError("only draft or paused campaigns can be started")
+	}
+	now := time.Now()
+	c.Status = CampaignStatusRunning
+	if c.StartedAt == nil {
+		c.StartedAt = &now
+	}
+	c.UpdatedAt = now
+	return nil
+}
+
+func (c *Campaign) Pause() error {
+	if c.Status != CampaignStatusRunning {
+		return errors.NewValidationError("only running campaigns can be paused")
+	}
+	c.Status = CampaignStatusPaused
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+func (c *Campaign) Cancel() error {
+	if c.IsFinished() {
+		return errors.NewValidationError("campaign has already finished")
+	}
+	now := time.Now()
+	c.Status = CampaignStatusCancelled
+	c.CompletedAt = &now
+	c.UpdatedAt = now
+	return nil
+}
+
+func (c *Campaign) Complete() {
+	now := time.Now()
+	c.Status = CampaignStatusCompleted
+	c.CompletedAt = &now
+	c.UpdatedAt = now
+}
+
+func (c *Campaign) IsFinished() bool {
+	return c.Status == CampaignStatusCompleted || c.Status == CampaignStatusCancelled
+}
+
+// InSchedule reports whether the campaign may place calls at t
+func (c *Campaign) InSchedule(t time.Time) bool {
+	if t.Before(c.StartsAt) {
+		return false
+	}
+	return c.EndsAt == nil || t.Before(*c.EndsAt)
+}
+
+// HasEnded reports whether the campaign's schedule is over at t
+func (c *Campaign) HasEnded(t time.Time) bool {
+	return c.EndsAt != nil && !t.Before(*c.EndsAt)
+}
+
+type CampaignContactStatus string
+
+const (
+	CampaignContactStatusPending   CampaignContactStatus = "pending"
+	CampaignContactStatusCalling   CampaignContactStatus = "calling"
+	CampaignContactStatusCompleted CampaignContactStatus = "completed"
+	CampaignContactStatusFailed    CampaignContactStatus = "failed"
+	CampaignContactStatusSkipped   CampaignContactStatus = "skipped"
+)
+
+// CampaignContact is one number to call in a campaign, along with the calls
+// placed to it so far
+type CampaignContact struct {
+	ID            string                 `json:"id"`
+	CampaignID    string                 `json:"campaign_id"`
+	Phone         string                 `json:"phone"`
+	Name          string                 `json:"name,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	Status        CampaignContactStatus  `json:"status"`
+	Attempts      int                    `json:"attempts"`
+	CallIDs       []string               `json:"call_ids"`
+	LastOutcome   CallStatus             `json:"last_outcome,omitempty"`
+	LastError     string                 `json:"last_error,omitempty"`
+	NextAttemptAt *time.Time             `json:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+}
+
+func NewCampaignContact(campaignID, phone, name string, variables map[string]interface{}) (*CampaignContact, error) {
+	if campaignID == "" {
+		return nil, errors.NewValidationError("campaign_id is required")
+	}
+	if err := validatePhone("phone", phone); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &CampaignContact{
+		CampaignID: campaignID,
+		Phone:      phone,
+		Name:       name,
+		Variables:  variables,
+		Status:     CampaignContactStatusPending,
+		CallIDs:    []string{},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// IsDue reports whether the contact is waiting to be dialled at t
+func (c *CampaignContact) IsDue(t time.Time) bool {
+	if c.Status != CampaignContactStatusPending {
+		return false
+	}
+	return c.NextAttemptAt == nil || !t.Before(*c.NextAttemptAt)
+}
+
+// CurrentCallID returns the call placed on the latest attempt
+func (c *CampaignContact) CurrentCallID() string {
+	if len(c.CallIDs) == 0 {
+		return ""
+	}
+	return c.CallIDs[len(c.CallIDs)-1]
+}
+
+// RecordAttempt links a newly placed call to the contact
+func (c *CampaignContact) RecordAttempt(callID string) {
+	c.Attempts++
+	c.CallIDs = append(c.CallIDs, callID)
+	c.Status = CampaignContactStatusCalling
+	c.NextAttemptAt = nil
+	c.LastError = ""
+	c.UpdatedAt = time.Now()
+}
+
+// RecordOutcome stores how the latest call ended and schedules a retry when
+// the policy allows one
+func (c *CampaignContact) RecordOutcome(status CallStatus, policy RetryPolicy, now time.Time) {
+	c.LastOutcome = status
+	c.UpdatedAt = now
+
+	switch {
+	case status == CallStatusCompleted:
+		c.Status = CampaignContactStatusCompleted
+	case policy.ShouldRetry(status, c.Attempts):
+		next := now.Add(time.Duration(policy.RetryDelayMinutes) * time.Minute)
+		c.Status = CampaignContactStatusPending
+		c.NextAttemptAt = &next
+	default:
+		c.Status = CampaignContactStatusFailed
+	}
+}
+
+// RecordDialError handles a call that could not be placed at all. It counts
+// as a failed attempt.
+func (c *CampaignContact) RecordDialError(reason string, policy RetryPolicy, now time.Time) {
+	c.Attempts++
+	c.LastError = reason
+	c.RecordOutcome(CallStatusFailed, policy, now)
+}
+
+// Defer postpones the contact without using up an attempt
+func (c *CampaignContact) Defer(reason string, until time.Time) {
+	c.LastError = reason
+	c.NextAttemptAt = &until
+	c.UpdatedAt = time.Now()
+}
+
+// Skip removes the contact from dialling for good
+func (c *CampaignContact) Skip(reason string) {
+	c.Status = CampaignContactStatusSkipped
+	c.LastError = reason
+	c.NextAttemptAt = nil
+	c.UpdatedAt = time.Now()
+}
+
+func (c *CampaignContact) IsFinished() bool {
+	return c.Status == CampaignContactStatusCompleted ||
+		c.Status == CampaignContactStatusFailed ||
+		c.Status == CampaignContactStatusSkipped
+}
@@ -0,0 +1,157 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type DoNotCallSource string
+
+const (
+	DoNotCallSourceManual DoNotCallSource = "manual"
+	DoNotCallSourceOptOut DoNotCallSource = "opt_out"
+	DoNotCallSourceImport DoNotCallSource = "import"
+)
+
+// DoNotCallEntry blocks outbound calls to a number. Entries without a
+// BusinessID apply to every business on the platform.
+type DoNotCallEntry struct {
+	ID         string          `json:"id"`
+	BusinessID *string         `json:"business_id,omitempty"`
+	Phone      string          `json:"phone"`
+	Source     DoNotCallSource `json:"source"`
+	Reason     string          `json:"reason,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+func NewDoNotCallEntry(businessID *string, phone string, source DoNotCallSource, reason string) (*DoNotCallEntry, error) {
+	if phone == "" {
+		return nil, errors.NewValidationError("phone is required")
+	}
+	if err := validatePhone("phone", phone); err != nil {
+		return nil, err
+	}
+
+	validSources := map[DoNotCallSource]bool{
+		DoNotCallSourceManual: true,
+		DoNotCallSourceOptOut: true,
+		DoNotCallSourceImport: true,
+	}
+
+	if !validSources[source] {
+		return nil, errors.NewValidationError("invalid do-not-call source")
+	}
+
+	return &DoNotCallEntry{
+		BusinessID: businessID,
+		Phone:      phone,
+		Source:     source,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// IsGlobal reports whether the entry applies to every business
+func (e *DoNotCallEntry) IsGlobal() bool {
+	return e.BusinessID == nil
+}
+
+// ConsentRecord is evidence that a person agreed to receive outbound calls
+type ConsentRecord struct {
+	ID         string     `json:"id"`
+	BusinessID string     `json:"business_id"`
+	Phone      string     `json:"phone"`
+	Source     string     `json:"source"` // where consent was captured, e.g. web_form, verbal, sms
+	Notes      string     `json:"notes,omitempty"`
+	GrantedAt  time.Time  `json:"granted_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewConsentRecord(businessID, phone, source, notes string, grantedAt time.Time) (*ConsentRecord, error) {
+	if businessID == "" {
+		return nil, errors.NewValidationError("business_id is required")
+	}
+	if phone == "" {
+		return nil, errors.NewValidationError("phone is required")
+	}
+	if err := validatePhone("phone", phone); err != nil {
+		return nil, err
+	}
+	if source == "" {
+		return nil, errors.NewFieldValidationError("source", "consent source is required")
+	}
+	if grantedAt.IsZero() {
+		grantedAt = time.Now()
+	}
+
+	return &ConsentRecord{
+		BusinessID: businessID,
+		Phone:      phone,
+		Source:     source,
+		Notes:      notes,
+		GrantedAt:  grantedAt,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+func (c *ConsentRecord) Revoke() error {
+	if c.RevokedAt != nil {
+		return errors.NewValidationError("consent already revoked")
+	}
+	now := time.Now()
+	c.RevokedAt = &now
+	return nil
+}
+
+func (c *ConsentRecord) IsActive() bool {
+	return c.RevokedAt == nil
+}
+
+// CallingWindow is the local time of day during which outbound calls may be
+// placed, expressed as minutes after midnight. End is exclusive.
+type CallingWindow struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// DefaultCallingWindow follows the common 8am to 9pm telemarketing rule
+var DefaultCallingWindow = CallingWindow{Start: 8 * 60, End: 21 * 60}
+
+// ParseCallingWindow reads a window written as "HH:MM" start and end times
+func ParseCallingWindow(start, end string) (CallingWindow, error) {
+	startMinutes, err := parseClock(start)
+	if err != nil {
+		return CallingWindow{}, errors.NewFieldValidationError("calling_window.start", err.Error())
+	}
+	endMinutes, err := parseClock(end)
+	if err != nil {
+		return CallingWindow{}, errors.NewFieldValidationError("calling_window.end", err.Error())
+	}
+	if endMinutes <= startMinutes {
+		return CallingWindow{}, errors.NewFieldValidationError("calling_window.end", "calling window must end after it starts")
+	}
+	return CallingWindow{Start: startMinutes, End: endMinutes}, nil
+}
+
+// Allows reports whether t, already converted to the callee's zone, falls
+// inside the window
+func (w CallingWindow) Allows(t time.Time) bool {
+	minutes := t.Hour()*60 + t.Minute()
+	return minutes >= w.Start && minutes < w.End
+}
+
+func (w CallingWindow) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", w.Start/60, w.Start%60, w.End/60, w.End%60)
+}
+
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("time must be written as HH:MM")
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -1,8 +1,12 @@
 package entities
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
 )
 
 func TestNewBusiness(t *testing.T) {
@@ -11,23 +15,23 @@ func TestNewBusiness(t *testing.T) {
 		businessName string
 		businessType string
 		phone        string
-		settings     map[string]interface{}
+		settings     BusinessSettings
 		wantErr      bool
 	}{
 		{
 			name:         "valid business",
 			businessName: "Test Business",
 			businessType: "dentist",
-			phone:        "+1234567890",
-			settings:     nil,
+			phone:        "+15551234567",
+			settings:     DefaultBusinessSettings(),
 			wantErr:      false,
 		},
 		{
 			name:         "missing name",
 			businessName: "",
 			businessType: "dentist",
-			phone:        "+1234567890",
-			settings:     nil,
+			phone:        "+15551234567",
+			settings:     DefaultBusinessSettings(),
 			wantErr:      true,
 		},
 		{
@@ -35,17 +39,25 @@ func TestNewBusiness(t *testing.T) {
 			businessName: "Test Business",
 			businessType: "dentist",
 			phone:        "",
-			settings:     nil,
+			settings:     DefaultBusinessSettings(),
 			wantErr:      true,
 		},
 		{
 			name:         "with settings",
 			businessName: "Test Business",
 			businessType: "dentist",
-			phone:        "+1234567890",
-			settings:     map[string]interface{}{"key": "value"},
+			phone:        "+15551234567",
+			settings:     BusinessSettings{Version: 1, Timezone: "America/New_York", Locale: "en-US", DefaultRegion: "US", CallingWindow: CallingHours{Start: "09:00", End: "20:00"}},
 			wantErr:      false,
 		},
+		{
+			name:         "invalid settings",
+			businessName: "Test Business",
+			businessType: "dentist",
+			phone:        "+15551234567",
+			settings:     BusinessSettings{Version: 1, Timezone: "Mars/Olympus", Locale: "en-US", DefaultRegion: "US"},
+			wantErr:      true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -70,12 +82,12 @@ func TestNewBusiness(t *testing.T) {
 }
 
 func TestBusiness_Update(t *testing.T) {
-	business, _ := NewBusiness("Original", "dentist", "+1234567890", nil)
+	business, _ := NewBusiness("Original", "dentist", "+15551234567", DefaultBusinessSettings())
 	originalUpdatedAt := business.UpdatedAt
 
 	time.Sleep(10 * time.Millisecond)
 
-	err := business.Update("Updated", "clinic", "+0987654321", map[string]interface{}{"new": "setting"})
+	err := business.Update("Updated", "clinic", "+15559876543")
 
 	if err != nil {
 		t.Errorf("Update() unexpected error: %v", err)
@@ -90,6 +102,29 @@ func TestBusiness_Update(t *testing.T) {
 	}
 }
 
+func TestNewChildBusiness(t *testing.T) {
+	agency, _ := NewBusiness("Agency", "agency", "+15551234567", DefaultBusinessSettings())
+	agency.ID = "agency-1"
+
+	child, err := NewChildBusiness(agency, "Clinic", "dentist", "+15559876543", DefaultBusinessSettings())
+	if err != nil {
+		t.Fatalf("NewChildBusiness() error = %v", err)
+	}
+	if !child.IsChildOf("agency-1") || child.IsChildOf("agency-2") {
+		t.Errorf("expected the child to belong to agency-1, got parent %v", child.ParentID)
+	}
+	if child.CanHaveChildren() {
+		t.Error("expected a child to be unable to have children")
+	}
+
+	if _, err := NewChildBusiness(child, "Grandchild", "dentist", "+15559876543", DefaultBusinessSettings()); err == nil {
+		t.Error("expected a grandchild to be rejected")
+	}
+	if _, err := NewChildBusiness(agency, "", "dentist", "+15559876543", DefaultBusinessSettings()); err == nil {
+		t.Error("expected the child to be validated")
+	}
+}
+
 func TestNewCall(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -100,13 +135,13 @@ func TestNewCall(t *testing.T) {
 		{
 			name:        "valid call",
 			businessID:  "business-123",
-			callerPhone: "+1234567890",
+			callerPhone: "+15551234567",
 			wantErr:     false,
 		},
 		{
 			name:        "missing business ID",
 			businessID:  "",
-			callerPhone: "+1234567890",
+			callerPhone: "+15551234567",
 			wantErr:     true,
 		},
 		{
@@ -115,6 +150,12 @@ func TestNewCall(t *testing.T) {
 			callerPhone: "",
 			wantErr:     true,
 		},
+		{
+			name:        "caller phone not in E.164",
+			businessID:  "business-123",
+			callerPhone: "(555) 123-4567",
+			wantErr:     true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -139,7 +180,7 @@ func TestNewCall(t *testing.T) {
 }
 
 func TestCall_UpdateStatus(t *testing.T) {
-	call, _ := NewCall("business-123", "+1234567890")
+	call, _ := NewCall("business-123", "+15551234567")
 
 	tests := []struct {
 		name       string
@@ -216,7 +257,7 @@ func TestCall_IsCompleted(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			call, _ := NewCall("business-123", "+1234567890")
+			call, _ := NewCall("business-123", "+15551234567")
 			call.UpdateStatus(tt.status)
 
 			if got := call.IsCompleted(); got != tt.want {
@@ -312,6 +353,51 @@ func TestUser_Permissions(t *testing.T) {
 	}
 }
 
+func TestUserRole_Can(t *testing.T) {
+	tests := []struct {
+		permission Permission
+		owner      bool
+		admin      bool
+		employee   bool
+	}{
+		{PermissionBusinessRead, true, true, true},
+		{PermissionBusinessWrite, true, true, false},
+		{PermissionCallsCreate, true, true, true},
+		{PermissionAppointmentsUpdate, true, true, true},
+		{PermissionCampaignsRead, true, true, true},
+		{PermissionCampaignsWrite, true, true, false},
+		{PermissionScheduledCallsWrite, true, true, true},
+		{PermissionTransfersWrite, true, true, false},
+		{PermissionMessagesUpdate, true, true, true},
+		{PermissionRecipientsWrite, true, true, false},
+		{PermissionInboxUpdate, true, true, true},
+		{PermissionComplianceWrite, true, true, true},
+		{PermissionComplianceManage, true, true, false},
+		{PermissionUsersRead, true, true, true},
+		{PermissionUsersManage, true, true, false},
+		{PermissionAPIKeysManage, true, true, false},
+		{PermissionSSOManage, true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.permission), func(t *testing.T) {
+			if got := UserRoleOwner.Can(tt.permission); got != tt.owner {
+				t.Errorf("owner Can() = %v, want %v", got, tt.owner)
+			}
+			if got := UserRoleAdmin.Can(tt.permission); got != tt.admin {
+				t.Errorf("admin Can() = %v, want %v", got, tt.admin)
+			}
+			if got := UserRoleEmployee.Can(tt.permission); got != tt.employee {
+				t.Errorf("employee Can() = %v, want %v", got, tt.employee)
+			}
+		})
+	}
+
+	if UserRole("").Can(PermissionBusinessRead) || UserRole("manager").Can(PermissionBusinessRead) {
+		t.Error("expected unknown roles to be granted nothing")
+	}
+}
+
 func TestNewAppointmentRequest(t *testing.T) {
 	requestedDate := time.Now().Add(24 * time.Hour)
 
@@ -326,14 +412,14 @@ func TestNewAppointmentRequest(t *testing.T) {
 			name:          "valid appointment",
 			callID:        "call-123",
 			businessID:    "business-123",
-			customerPhone: "+1234567890",
+			customerPhone: "+15551234567",
 			wantErr:       false,
 		},
 		{
 			name:          "missing call ID",
 			callID:        "",
 			businessID:    "business-123",
-			customerPhone: "+1234567890",
+			customerPhone: "+15551234567",
 			wantErr:       true,
 		},
 		{
@@ -377,7 +463,7 @@ func TestAppointmentRequest_StatusTransitions(t *testing.T) {
 		"call-123",
 		"business-123",
 		"John Doe",
-		"+1234567890",
+		"+15551234567",
 		nil,
 		"10:00 AM",
 		"cleaning",
@@ -408,3 +494,896 @@ func TestAppointmentRequest_StatusTransitions(t *testing.T) {
 		t.Error("Cancel() should fail on completed appointment")
 	}
 }
+
+func TestAppointmentRequest_IsUpcoming(t *testing.T) {
+	now := time.Now()
+	tomorrow := now.Add(24 * time.Hour)
+	yesterday := now.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		date   *time.Time
+		status AppointmentStatus
+		want   bool
+	}{
+		{"pending tomorrow", &tomorrow, AppointmentStatusPending, true},
+		{"confirmed today", &now, AppointmentStatusConfirmed, true},
+		{"confirmed yesterday", &yesterday, AppointmentStatusConfirmed, false},
+		{"cancelled tomorrow", &tomorrow, AppointmentStatusCancelled, false},
+		{"no date", nil, AppointmentStatusPending, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apt, _ := NewAppointmentRequest("call-123", "business-123", "John Doe", "+15551234567", tt.date, "", "", "")
+			apt.Status = tt.status
+
+			if got := apt.IsUpcoming(now); got != tt.want {
+				t.Errorf("IsUpcoming() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallerProfile_IsKnown(t *testing.T) {
+	profile := NewCallerProfile("+15551234567")
+	if profile.IsKnown() {
+		t.Error("IsKnown() should be false for an empty profile")
+	}
+
+	profile.Name = "John Doe"
+	if !profile.IsKnown() {
+		t.Error("IsKnown() should be true once the caller has a name")
+	}
+
+	if profile.Variables()["customer_name"] != "John Doe" {
+		t.Errorf("Variables() customer_name = %v, want John Doe", profile.Variables()["customer_name"])
+	}
+}
+
+func TestParseCallingWindow(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   string
+		end     string
+		wantErr bool
+	}{
+		{name: "valid window", start: "08:00", end: "21:00", wantErr: false},
+		{name: "end before start", start: "21:00", end: "08:00", wantErr: true},
+		{name: "malformed time", start: "8am", end: "21:00", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCallingWindow(tt.start, tt.end)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCallingWindow() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCallingWindow_Allows(t *testing.T) {
+	window := DefaultCallingWindow
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want bool
+	}{
+		{name: "before start", at: time.Date(2024, 1, 1, 7, 59, 0, 0, time.UTC), want: false},
+		{name: "at start", at: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC), want: true},
+		{name: "last minute", at: time.Date(2024, 1, 1, 20, 59, 0, 0, time.UTC), want: true},
+		{name: "at end", at: time.Date(2024, 1, 1, 21, 0, 0, 0, time.UTC), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := window.Allows(tt.at); got != tt.want {
+				t.Errorf("Allows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCampaign_StatusTransitions(t *testing.T) {
+	campaign, err := NewCampaign("business-123", "Reminders", "", 2, 10, DefaultRetryPolicy, time.Time{}, nil)
+	if err != nil {
+		t.Fatalf("NewCampaign() error = %v", err)
+	}
+
+	if err := campaign.Pause(); err == nil {
+		t.Error("expected error pausing a draft campaign")
+	}
+	if err := campaign.Start(); err != nil {
+		t.Errorf("Start() error = %v", err)
+	}
+	if err := campaign.Pause(); err != nil {
+		t.Errorf("Pause() error = %v", err)
+	}
+	if err := campaign.Start(); err != nil {
+		t.Errorf("resuming with Start() error = %v", err)
+	}
+	if err := campaign.Cancel(); err != nil {
+		t.Errorf("Cancel() error = %v", err)
+	}
+	if err := campaign.Start(); err == nil {
+		t.Error("expected error starting a cancelled campaign")
+	}
+}
+
+func TestCampaignContact_RecordOutcome(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 2, RetryDelayMinutes: 30, RetryOn: []CallStatus{CallStatusNoAnswer}}
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		attempts int
+		outcome  CallStatus
+		want     CampaignContactStatus
+	}{
+		{name: "answered", attempts: 1, outcome: CallStatusCompleted, want: CampaignContactStatusCompleted},
+		{name: "no answer with attempts left", attempts: 1, outcome: CallStatusNoAnswer, want: CampaignContactStatusPending},
+		{name: "no answer on last attempt", attempts: 2, outcome: CallStatusNoAnswer, want: CampaignContactStatusFailed},
+		{name: "outcome not in policy", attempts: 1, outcome: CallStatusBusy, want: CampaignContactStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contact, _ := NewCampaignContact("campaign-1", "+15551234567", "", nil)
+			contact.Attempts = tt.attempts
+			contact.Status = CampaignContactStatusCalling
+
+			contact.RecordOutcome(tt.outcome, policy, now)
+
+			if contact.Status != tt.want {
+				t.Errorf("status = %s, want %s", contact.Status, tt.want)
+			}
+			if tt.want == CampaignContactStatusPending && !contact.NextAttemptAt.Equal(now.Add(30*time.Minute)) {
+				t.Errorf("next attempt = %v, want 30 minutes later", contact.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestScheduledCall_Reschedule(t *testing.T) {
+	at := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	scheduled, err := NewScheduledCall("business-1", "+15551234567", "", "", ScheduledCallSourceManual, at)
+	if err != nil {
+		t.Fatalf("NewScheduledCall() error = %v", err)
+	}
+
+	for i := 0; i < maxScheduledCallAttempts; i++ {
+		scheduled.RecordDialError("provider unavailable", at)
+	}
+	if scheduled.Status != ScheduledCallStatusFailed {
+		t.Fatalf("status = %s, want failed after %d errors", scheduled.Status, maxScheduledCallAttempts)
+	}
+
+	if err := scheduled.Reschedule(at.Add(time.Hour)); err != nil {
+		t.Fatalf("Reschedule() error = %v", err)
+	}
+	if scheduled.Status != ScheduledCallStatusScheduled || scheduled.Attempts != 0 {
+		t.Errorf("expected a fresh scheduled call, got status %s attempts %d", scheduled.Status, scheduled.Attempts)
+	}
+
+	scheduled.MarkInitiated("call-1")
+	if err := scheduled.Cancel(); err == nil {
+		t.Error("expected error cancelling a call that was already placed")
+	}
+	if err := scheduled.Reschedule(at); err == nil {
+		t.Error("expected error rescheduling a call that was already placed")
+	}
+}
+
+func TestTransferDestination_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		dest    string
+		phone   string
+		mode    TransferMode
+		wantErr bool
+	}{
+		{name: "valid", dest: "front_desk", phone: "+15551234567", mode: TransferModeCold},
+		{name: "default mode", dest: "manager", phone: "+15551234567"},
+		{name: "name with spaces", dest: "Front Desk", phone: "+15551234567", wantErr: true},
+		{name: "phone not E.164", dest: "manager", phone: "555-1234", wantErr: true},
+		{name: "unknown mode", dest: "manager", phone: "+15551234567", mode: "conference", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destination, err := NewTransferDestination("business-1", tt.dest, "", tt.phone, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewTransferDestination() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && tt.mode == "" && destination.Mode != TransferModeWarm {
+				t.Errorf("mode = %s, want warm by default", destination.Mode)
+			}
+		})
+	}
+}
+
+func TestOnCallShift_Covers(t *testing.T) {
+	start := time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+	destination, _ := NewTransferDestination("business-1", "emergency", "", "+15551234567", TransferModeCold)
+
+	if _, err := NewOnCallShift(destination, "Sam", "+15551234567", start, start); err == nil {
+		t.Error("expected error for a shift that ends when it starts")
+	}
+
+	shift, err := NewOnCallShift(destination, "Sam", "+15551234567", start, start.Add(12*time.Hour))
+	if err != nil {
+		t.Fatalf("NewOnCallShift() error = %v", err)
+	}
+	if !shift.Covers(start) || !shift.Covers(start.Add(6*time.Hour)) {
+		t.Error("expected shift to cover its start and middle")
+	}
+	if shift.Covers(start.Add(12*time.Hour)) || shift.Covers(start.Add(-time.Minute)) {
+		t.Error("expected shift not to cover its end or before it starts")
+	}
+}
+
+func TestMessageRecipient_Matches(t *testing.T) {
+	recipient, err := NewMessageRecipient("business-1", "Dr. Lee", []string{"Susan Lee", "the dentist"}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewMessageRecipient() error = %v", err)
+	}
+
+	tests := []struct {
+		requested string
+		want      bool
+	}{
+		{"Dr. Lee", true},
+		{"doctor lee", true},
+		{"LEE", true},
+		{"Susan Lee", true},
+		{"the dentist", true},
+		{"Dr. Leeds", false},
+		{"Dr.", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := recipient.Matches(tt.requested); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestMessage_Workflow(t *testing.T) {
+	message, err := NewMessage("business-1", "+15551234567", "", "Call me back", "", MessageSourceTool)
+	if err != nil {
+		t.Fatalf("NewMessage() error = %v", err)
+	}
+	if message.CallbackPhone != "+15551234567" || message.Urgency != MessageUrgencyNormal {
+		t.Errorf("expected defaults, got callback %s urgency %s", message.CallbackPhone, message.Urgency)
+	}
+
+	if err := message.Reopen(); err == nil {
+		t.Error("expected error reopening an open message")
+	}
+	if err := message.Resolve("user-1"); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if message.AcknowledgedAt == nil {
+		t.Error("expected resolving to acknowledge the message too")
+	}
+	if err := message.Resolve("user-1"); err == nil {
+		t.Error("expected error resolving twice")
+	}
+	if err := message.Reopen(); err != nil || message.Status != MessageStatusAcknowledged {
+		t.Errorf("Reopen() = %v, status %s", err, message.Status)
+	}
+}
+
+func TestInboxItemState_Workflow(t *testing.T) {
+	now := time.Now()
+	state := NewInboxItemState("business-1", InboxItemTypeMessage, "message-1")
+
+	if !state.IsActive(now) {
+		t.Fatal("expected a new item to be active")
+	}
+	if err := state.Snooze(now.Add(-time.Minute), now); err == nil {
+		t.Error("expected error snoozing into the past")
+	}
+	if err := state.Snooze(now.Add(time.Hour), now); err != nil {
+		t.Fatalf("Snooze() error = %v", err)
+	}
+	if !state.IsSnoozed(now) || state.IsSnoozed(now.Add(2*time.Hour)) {
+		t.Error("expected the item to be snoozed for an hour")
+	}
+	if err := state.MarkDone("user-1"); err != nil {
+		t.Fatalf("MarkDone() error = %v", err)
+	}
+	if state.IsSnoozed(now) || state.IsActive(now) {
+		t.Error("expected a done item to be neither snoozed nor active")
+	}
+	if err := state.MarkDone("user-1"); err == nil {
+		t.Error("expected error marking done twice")
+	}
+	state.Reopen()
+	if !state.IsActive(now) {
+		t.Error("expected a reopened item to be active")
+	}
+}
+
+func TestParseInboxItemKey(t *testing.T) {
+	itemType, sourceID, err := ParseInboxItemKey(InboxItemKey(InboxItemTypeFailedCall, "call-1"))
+	if err != nil || itemType != InboxItemTypeFailedCall || sourceID != "call-1" {
+		t.Errorf("ParseInboxItemKey() = %s, %s, %v", itemType, sourceID, err)
+	}
+
+	for _, key := range []string{"", "call-1", "voicemail:call-1", "message:"} {
+		if _, _, err := ParseInboxItemKey(key); err == nil {
+			t.Errorf("expected error for %q", key)
+		}
+	}
+}
+
+func TestAPIKey_IsActive(t *testing.T) {
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+
+	key, err := NewAPIKey("business-123", "CRM", []Permission{PermissionCallsRead}, "user-1", "cpk_abcdefgh", "hash", &expiresAt)
+	if err != nil {
+		t.Fatalf("NewAPIKey() error = %v", err)
+	}
+
+	if !key.IsActive(now) {
+		t.Error("expected the key to be active")
+	}
+	if key.IsActive(expiresAt.Add(time.Second)) {
+		t.Error("expected the key to expire")
+	}
+	if err := key.Revoke(now); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	if key.IsActive(now) {
+		t.Error("expected a revoked key to be inactive")
+	}
+	if err := key.Revoke(now); err == nil {
+		t.Error("expected error revoking twice")
+	}
+
+	if _, err := NewAPIKey("business-123", "CRM", []Permission{PermissionBusinessWrite}, "user-1", "cpk_abcdefgh", "hash", nil); err == nil {
+		t.Error("expected business:write to be refused as an API key scope")
+	}
+}
+
+func TestAllowedWhileUnverified(t *testing.T) {
+	if !AllowedWhileUnverified(PermissionCallsRead) {
+		t.Error("expected unverified users to read calls")
+	}
+	for _, permission := range []Permission{PermissionCallsCreate, PermissionBusinessWrite, PermissionUsersManage} {
+		if AllowedWhileUnverified(permission) {
+			t.Errorf("expected unverified users to be refused %s", permission)
+		}
+	}
+}
+
+func TestUserToken_Use(t *testing.T) {
+	now := time.Now()
+	token, err := NewUserToken("user-1", UserTokenPasswordReset, "hash", now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("NewUserToken() error = %v", err)
+	}
+
+	if token.Use(now.Add(2*time.Hour)) == nil {
+		t.Error("expected an expired token to be refused")
+	}
+	if err := token.Use(now); err != nil {
+		t.Fatalf("Use() error = %v", err)
+	}
+	if token.Use(now) == nil {
+		t.Error("expected the token to be single use")
+	}
+}
+
+func TestUserMFA_UseStep(t *testing.T) {
+	mfa, err := NewUserMFA("user-1", "encrypted")
+	if err != nil {
+		t.Fatalf("NewUserMFA() error = %v", err)
+	}
+	if mfa.IsEnabled() {
+		t.Error("expected a new enrolment to be pending")
+	}
+
+	if err := mfa.UseStep(100); err != nil {
+		t.Fatalf("UseStep() error = %v", err)
+	}
+	if mfa.UseStep(100) == nil {
+		t.Error("expected a code not to be usable twice")
+	}
+	if mfa.UseStep(99) == nil {
+		t.Error("expected an earlier code to be refused")
+	}
+	if err := mfa.UseStep(101); err != nil {
+		t.Errorf("UseStep() error = %v", err)
+	}
+}
+
+func TestLoginThrottle_RetryAt(t *testing.T) {
+	last := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{6, 30 * time.Second},
+		{40, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		throttle := &LoginThrottle{Failures: tt.failures, LastFailureAt: last}
+		if got := throttle.RetryAt(time.Second, 30*time.Second); !got.Equal(last.Add(tt.want)) {
+			t.Errorf("RetryAt() with %d failures = %v, want %v", tt.failures, got.Sub(last), tt.want)
+		}
+	}
+}
+
+func TestNewSSOConnection(t *testing.T) {
+	tests := []struct {
+		name    string
+		issuer  string
+		domains []string
+		role    UserRole
+		wantErr bool
+	}{
+		{"valid", "https://login.example.com/", []string{"Example.com", "example.com"}, "", false},
+		{"loopback over http", "http://127.0.0.1:8080", []string{"example.com"}, UserRoleAdmin, false},
+		{"plain http", "http://login.example.com", []string{"example.com"}, "", true},
+		{"no domains", "https://login.example.com", nil, "", true},
+		{"email as domain", "https://login.example.com", []string{"jane@example.com"}, "", true},
+		{"owner role", "https://login.example.com", []string{"example.com"}, UserRoleOwner, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := NewSSOConnection("business-1", tt.issuer, "client", "encrypted", tt.domains, tt.role)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSSOConnection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if conn.Issuer != strings.TrimSuffix(tt.issuer, "/") {
+				t.Errorf("Issuer = %s", conn.Issuer)
+			}
+			if len(conn.AllowedDomains) != 1 || conn.DefaultRole == "" {
+				t.Errorf("unexpected connection %+v", conn)
+			}
+		})
+	}
+}
+
+func TestSSOConnection_AllowsEmail(t *testing.T) {
+	conn := &SSOConnection{AllowedDomains: []string{"example.com"}}
+
+	for email, want := range map[string]bool{
+		"jane@example.com":      true,
+		"Jane@EXAMPLE.com":      true,
+		"jane@sub.example.com":  false,
+		"jane@example.com.evil": false,
+		"example.com":           false,
+	} {
+		if got := conn.AllowsEmail(email); got != want {
+			t.Errorf("AllowsEmail(%q) = %v, want %v", email, got, want)
+		}
+	}
+}
+
+func TestUser_AsMember(t *testing.T) {
+	user := &User{ID: "user-1", BusinessID: "business-1", Role: UserRoleOwner}
+	membership, err := NewMembership(user.ID, "business-2", UserRoleEmployee)
+	if err != nil {
+		t.Fatalf("NewMembership() error = %v", err)
+	}
+
+	member := user.AsMember(membership)
+	if member.BusinessID != "business-2" || member.Role != UserRoleEmployee || member.ID != user.ID {
+		t.Errorf("unexpected member %+v", member)
+	}
+	if user.BusinessID != "business-1" || user.Role != UserRoleOwner {
+		t.Error("expected the user to be left unchanged")
+	}
+
+	if _, err := NewMembership(user.ID, "business-2", "superuser"); err == nil {
+		t.Error("expected an invalid role to be rejected")
+	}
+}
+
+func TestNewAuditEntry(t *testing.T) {
+	actor := AuditActor{Type: AuditActorUser, ID: "user-1", AgencyID: "agency-1", IP: "203.0.113.7", UserAgent: "curl/8.0"}
+	entry, err := NewAuditEntry("business-1", actor, AuditActionBusinessUpdated, "business", "business-1", nil)
+	if err != nil {
+		t.Fatalf("NewAuditEntry() error = %v", err)
+	}
+	if entry.ActorType != AuditActorUser || entry.ActorID != "user-1" || entry.IP != "203.0.113.7" || entry.UserAgent != "curl/8.0" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if entry.Metadata["agency_id"] != "agency-1" {
+		t.Error("expected the agency to be recorded")
+	}
+
+	if _, err := NewAuditEntry("business-1", actor, "", "business", "business-1", nil); err == nil {
+		t.Error("expected the action to be required")
+	}
+	if _, err := NewAuditEntry("business-1", AuditActor{Type: "robot"}, AuditActionLogin, "", "", nil); err == nil {
+		t.Error("expected an invalid actor type to be rejected")
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	before := map[string]interface{}{"name": "Old", "phone": "+15551234567", "settings.timezone": "UTC"}
+	after := map[string]interface{}{"name": "New", "phone": "+15551234567", "settings.language": "es"}
+
+	changes := DiffFields(before, after)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %+v", changes)
+	}
+	if changes["name"].Before != "Old" || changes["name"].After != "New" {
+		t.Errorf("unexpected name change %+v", changes["name"])
+	}
+	if changes["settings.timezone"].Before != "UTC" || changes["settings.timezone"].After != nil {
+		t.Errorf("unexpected removed setting %+v", changes["settings.timezone"])
+	}
+	if changes["settings.language"].Before != nil || changes["settings.language"].After != "es" {
+		t.Errorf("unexpected added setting %+v", changes["settings.language"])
+	}
+	if _, ok := changes["phone"]; ok {
+		t.Error("expected unchanged fields to be left out")
+	}
+}
+
+func TestBusiness_Suspend(t *testing.T) {
+	business := &Business{ID: "business-1", Name: "Test Business"}
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := business.Reactivate(now); err == nil {
+		t.Error("expected an active business to be unable to be reactivated")
+	}
+	if err := business.Suspend("", now); err == nil {
+		t.Error("expected a reason to be required")
+	}
+
+	if err := business.Suspend("unpaid invoices", now); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	if !business.IsSuspended() || business.SuspensionReason != "unpaid invoices" || !business.SuspendedAt.Equal(now) {
+		t.Errorf("unexpected business %+v", business)
+	}
+	if err := business.Suspend("again", now); err == nil {
+		t.Error("expected a suspended business to be unable to be suspended again")
+	}
+
+	if err := business.Reactivate(now.Add(time.Hour)); err != nil {
+		t.Fatalf("Reactivate() error = %v", err)
+	}
+	if business.IsSuspended() || business.SuspensionReason != "" {
+		t.Errorf("expected the suspension to be lifted, got %+v", business)
+	}
+}
+
+func TestNewWebhookEvent(t *testing.T) {
+	now := time.Now()
+
+	event, err := NewWebhookEvent("end-of-call-report", "vapi-1", []byte(`{"message":{}}`), nil, now)
+	if err != nil {
+		t.Fatalf("NewWebhookEvent() error = %v", err)
+	}
+	if event.Status != WebhookEventProcessed || event.Error != "" || string(event.Payload) != `{"message":{}}` {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	event, err = NewWebhookEvent("status-update", "vapi-1", []byte("not json"), errors.NewNotFoundError("call", "vapi-1"), now)
+	if err != nil {
+		t.Fatalf("NewWebhookEvent() error = %v", err)
+	}
+	if event.Status != WebhookEventFailed || event.Error == "" || event.Payload != nil {
+		t.Errorf("expected a failed event without the invalid payload, got %+v", event)
+	}
+
+	if _, err := NewWebhookEvent("", "vapi-1", nil, nil, now); err == nil {
+		t.Error("expected the event type to be required")
+	}
+}
+
+func TestParseBusinessSettings(t *testing.T) {
+	// Settings stored before they had a schema are upgraded, keeping the
+	// values that fit and defaulting the rest
+	settings, err := ParseBusinessSettings([]byte(`{
+		"timezone": "America/Chicago",
+		"caller_recognition": "yes",
+		"auto_callback": {"enabled": true},
+		"workingHours": "9-5"
+	}`))
+	if err != nil {
+		t.Fatalf("ParseBusinessSettings() error = %v", err)
+	}
+	if settings.Version != CurrentSettingsVersion || settings.Timezone != "America/Chicago" || settings.Locale != "en-US" {
+		t.Errorf("unexpected settings %+v", settings)
+	}
+	if settings.CallerRecognition {
+		t.Error("expected a value of the wrong type to take its default")
+	}
+	if !settings.AutoCallback.Enabled || settings.AutoCallback.DelayMinutes != DefaultAutoCallbackRule.DelayMinutes {
+		t.Errorf("expected missing nested members to take their defaults, got %+v", settings.AutoCallback)
+	}
+	if len(settings.Hours.Monday) != 1 || len(settings.Hours.Sunday) != 0 {
+		t.Errorf("expected the default hours, got %+v", settings.Hours)
+	}
+
+	if settings, err := ParseBusinessSettings(nil); err != nil || settings.Timezone != "UTC" {
+		t.Errorf("expected empty settings to be the defaults, got %+v, %v", settings, err)
+	}
+	if _, err := ParseBusinessSettings([]byte(`{"version": 99}`)); err == nil {
+		t.Error("expected a newer version to be rejected")
+	}
+}
+
+func TestBusinessSettings_Merge(t *testing.T) {
+	settings := DefaultBusinessSettings()
+	settings.Timezone = "America/New_York"
+	settings.CallerRecognition = true
+
+	tests := []struct {
+		name    string
+		patch   string
+		check   func(t *testing.T, merged BusinessSettings)
+		wantErr bool
+	}{
+		{
+			name:  "changes only the patched member",
+			patch: `{"locale": "es-MX"}`,
+			check: func(t *testing.T, merged BusinessSettings) {
+				if merged.Locale != "es-MX" || merged.Timezone != "America/New_York" || !merged.CallerRecognition {
+					t.Errorf("expected the other settings to be kept, got %+v", merged)
+				}
+			},
+		},
+		{
+			name:  "merges nested objects",
+			patch: `{"hours": {"saturday": [{"open": "10:00", "close": "14:00"}]}, "auto_callback": {"enabled": true}}`,
+			check: func(t *testing.T, merged BusinessSettings) {
+				if len(merged.Hours.Saturday) != 1 || len(merged.Hours.Monday) != 1 {
+					t.Errorf("expected saturday to be added to the hours, got %+v", merged.Hours)
+				}
+				if !merged.AutoCallback.Enabled || merged.AutoCallback.DelayMinutes != 10 {
+					t.Errorf("expected the rule to be merged, got %+v", merged.AutoCallback)
+				}
+			},
+		},
+		{
+			name:  "null resets to the default",
+			patch: `{"timezone": null, "version": 7}`,
+			check: func(t *testing.T, merged BusinessSettings) {
+				if merged.Timezone != "UTC" || merged.Version != CurrentSettingsVersion {
+					t.Errorf("expected the default time zone and current version, got %+v", merged)
+				}
+			},
+		},
+		{name: "unknown member", patch: `{"workingHours": "9-5"}`, wantErr: true},
+		{name: "wrong type", patch: `{"caller_recognition": "yes"}`, wantErr: true},
+		{name: "not an object", patch: `["timezone"]`, wantErr: true},
+		{name: "invalid JSON", patch: `{"timezone":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, err := settings.Merge([]byte(tt.patch))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Merge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.check != nil {
+				tt.check(t, merged)
+			}
+		})
+	}
+}
+
+func TestBusinessSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(s *BusinessSettings)
+		field  string
+	}{
+		{"defaults", func(s *BusinessSettings) {}, ""},
+		{"unknown time zone", func(s *BusinessSettings) { s.Timezone = "Mars/Olympus" }, "timezone"},
+		{"invalid locale", func(s *BusinessSettings) { s.Locale = "english" }, "locale"},
+		{"unsupported region", func(s *BusinessSettings) { s.DefaultRegion = "XX" }, "default_region"},
+		{"hours closing before opening", func(s *BusinessSettings) {
+			s.Hours.Monday = []OpeningHours{{Open: "17:00", Close: "09:00"}}
+		}, "hours.monday[0].close"},
+		{"overlapping hours", func(s *BusinessSettings) {
+			s.Hours.Tuesday = []OpeningHours{{Open: "09:00", Close: "13:00"}, {Open: "12:00", Close: "17:00"}}
+		}, "hours.tuesday"},
+		{"transfer number not in E.164", func(s *BusinessSettings) {
+			s.TransferNumbers = []TransferNumber{{Label: "Front desk", Phone: "555-1234"}}
+		}, "transfer_numbers[0].phone"},
+		{"announcing without a disclosure", func(s *BusinessSettings) {
+			s.RecordingConsent = RecordingConsent{Announce: true}
+		}, "recording_consent.disclosure"},
+		{"calling window ending before it starts", func(s *BusinessSettings) {
+			s.CallingWindow = CallingHours{Start: "20:00", End: "08:00"}
+		}, "calling_window.end"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := DefaultBusinessSettings()
+			tt.change(&settings)
+
+			err := settings.Validate()
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			domainErr, ok := err.(*errors.DomainError)
+			if !ok || domainErr.Field != tt.field {
+				t.Errorf("expected a %s error, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestWeeklyHours_IsOpen(t *testing.T) {
+	hours := DefaultBusinessSettings().Hours
+	hours.Saturday = []OpeningHours{{Open: "10:00", Close: "12:00"}, {Open: "13:00", Close: "15:00"}}
+
+	tests := []struct {
+		at   time.Time
+		want bool
+	}{
+		{time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC), true},    // Monday
+		{time.Date(2025, 6, 2, 17, 0, 0, 0, time.UTC), false},  // Monday, closing time
+		{time.Date(2025, 6, 7, 12, 30, 0, 0, time.UTC), false}, // Saturday lunch
+		{time.Date(2025, 6, 7, 13, 30, 0, 0, time.UTC), true},
+		{time.Date(2025, 6, 8, 11, 0, 0, 0, time.UTC), false}, // Sunday
+	}
+
+	for _, tt := range tests {
+		if got := hours.IsOpen(tt.at); got != tt.want {
+			t.Errorf("IsOpen(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
+
+// The published schema must describe every setting
+func TestBusinessSettingsSchema(t *testing.T) {
+	var schema struct {
+		Properties map[string]struct {
+			Const      interface{}                `json:"const"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"properties"`
+		AdditionalProperties bool `json:"additionalProperties"`
+	}
+	if err := json.Unmarshal(BusinessSettingsSchema, &schema); err != nil {
+		t.Fatalf("the schema is not valid JSON: %v", err)
+	}
+
+	var defaults map[string]interface{}
+	data, _ := json.Marshal(DefaultBusinessSettings())
+	json.Unmarshal(data, &defaults)
+
+	for key, value := range defaults {
+		property, ok := schema.Properties[key]
+		if !ok {
+			t.Errorf("the schema is missing %s", key)
+			continue
+		}
+		if object, ok := value.(map[string]interface{}); ok {
+			for nested := range object {
+				if _, ok := property.Properties[nested]; !ok {
+					t.Errorf("the schema is missing %s.%s", key, nested)
+				}
+			}
+		}
+	}
+	for key := range schema.Properties {
+		if _, ok := defaults[key]; !ok {
+			t.Errorf("the schema describes %s, which is not a setting", key)
+		}
+	}
+	if version, _ := schema.Properties["version"].Const.(float64); int(version) != CurrentSettingsVersion {
+		t.Errorf("expected the schema to be version %d, got %v", CurrentSettingsVersion, schema.Properties["version"].Const)
+	}
+}
+
+func TestOnboarding_Steps(t *testing.T) {
+	onboarding, err := NewOnboarding("business-123", OnboardingProfile{BusinessType: "salon"})
+	if err != nil {
+		t.Fatalf("NewOnboarding() error = %v", err)
+	}
+	if onboarding.Status != OnboardingStatusInProgress || onboarding.NextStep().Name != OnboardingStepProfile {
+		t.Fatalf("expected to start with the profile, got %+v", onboarding)
+	}
+
+	onboarding.CompleteStep(OnboardingStepProfile)
+	onboarding.FailStep(OnboardingStepAssistant, "provider unavailable")
+	if onboarding.Status != OnboardingStatusFailed || onboarding.NextStep().Name != OnboardingStepAssistant {
+		t.Errorf("expected to resume from the failed step, got %+v", onboarding)
+	}
+
+	onboarding.CompleteStep(OnboardingStepAssistant)
+	onboarding.CompleteStep(OnboardingStepPhoneNumber)
+	if !onboarding.IsCompleted() || onboarding.CompletedAt == nil || onboarding.NextStep() != nil {
+		t.Errorf("expected the onboarding to complete, got %+v", onboarding)
+	}
+	if step := onboarding.Step(OnboardingStepAssistant); step.Attempts != 2 || step.Error != "" {
+		t.Errorf("expected the retried step to be cleared, got %+v", step)
+	}
+
+	if _, err := NewOnboarding("business-123", OnboardingProfile{}); err == nil {
+		t.Error("expected a profile without a business type to be rejected")
+	}
+}
+
+func TestAssistantTemplate_Matches(t *testing.T) {
+	template := AssistantTemplate{Key: "dental_clinic", BusinessTypes: []string{"dentist", "dental clinic"}}
+
+	for _, businessType := range []string{"dentist", "Dental Clinic", "dental-clinic", " dental_clinic "} {
+		if !template.Matches(businessType) {
+			t.Errorf("expected %q to match", businessType)
+		}
+	}
+	for _, businessType := range []string{"dental", "clinic", ""} {
+		if template.Matches(businessType) {
+			t.Errorf("expected %q not to match", businessType)
+		}
+	}
+}
+
+func TestAssistantTemplateOverride_Apply(t *testing.T) {
+	template := AssistantTemplate{
+		Key:               "salon",
+		Prompt:            "template prompt",
+		FirstMessage:      "template greeting",
+		Functions:         []string{"take_message"},
+		InteractionFields: []InteractionField{{Name: "client_name", Description: "Name"}},
+		Services:          []OfferedService{{Name: "Haircut"}},
+	}
+
+	override := &AssistantTemplateOverride{BusinessID: "business-123", FirstMessage: "custom greeting", Functions: []string{}}
+	got := override.Apply(template)
+	if got.Prompt != "template prompt" || got.FirstMessage != "custom greeting" {
+		t.Errorf("expected only the first message to change, got %+v", got)
+	}
+	if got.Functions == nil || len(got.Functions) != 0 {
+		t.Errorf("expected an empty list to remove the functions, got %v", got.Functions)
+	}
+	if len(got.InteractionFields) != 1 || len(got.Services) != 1 {
+		t.Errorf("expected nil lists to keep the template's, got %+v", got)
+	}
+}
+
+func TestAssistantTemplateOverride_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		override AssistantTemplateOverride
+		wantErr  bool
+	}{
+		{"empty override", AssistantTemplateOverride{BusinessID: "business-123"}, false},
+		{"no business", AssistantTemplateOverride{}, true},
+		{"long prompt", AssistantTemplateOverride{BusinessID: "business-123", Prompt: strings.Repeat("a", maxTemplatePromptLength+1)}, true},
+		{"field without description", AssistantTemplateOverride{BusinessID: "business-123", InteractionFields: []InteractionField{{Name: "vehicle"}}}, true},
+		{"duplicate field", AssistantTemplateOverride{BusinessID: "business-123", InteractionFields: []InteractionField{
+			{Name: "vehicle", Description: "Make and model"},
+			{Name: "vehicle", Description: "Year"},
+		}}, true},
+		{"negative duration", AssistantTemplateOverride{BusinessID: "business-123", Services: []OfferedService{{Name: "Oil change", DurationMinutes: -5}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.override.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package entities
+
+import (
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type InboxItemType string
+
+const (
+	InboxItemTypeAppointmentRequest InboxItemType = "appointment_request"
+	InboxItemTypeComplaint          InboxItemType = "complaint"
+	InboxItemTypeMessage            InboxItemType = "message"
+	InboxItemTypeFailedCall         InboxItemType = "failed_call"
+)
+
+func (t InboxItemType) IsValid() bool {
+	switch t {
+	case InboxItemTypeAppointmentRequest, InboxItemTypeComplaint, InboxItemTypeMessage, InboxItemTypeFailedCall:
+		return true
+	}
+	return false
+}
+
+type InboxPriority string
+
+const (
+	InboxPriorityHigh   InboxPriority = "high"
+	InboxPriorityNormal InboxPriority = "normal"
+	InboxPriorityLow    InboxPriority = "low"
+)
+
+// Rank orders priorities, highest first
+func (p InboxPriority) Rank() int {
+	switch p {
+	case InboxPriorityHigh:
+		return 3
+	case InboxPriorityNormal:
+		return 2
+	case InboxPriorityLow:
+		return 1
+	}
+	return 0
+}
+
+func (p InboxPriority) IsValid() bool {
+	return p.Rank() > 0
+}
+
+// InboxItemKey builds the ID of an inbox item from the record it points at,
+// such as "message:<message id>"
+func InboxItemKey(itemType InboxItemType, sourceID string) string {
+	return string(itemType) + ":" + sourceID
+}
+
+// ParseInboxItemKey splits an inbox item ID into its type and source record
+func ParseInboxItemKey(key string) (InboxItemType, string, error) {
+	parts := strings.SplitN(key, ":", 2)
+	if len(parts) != 2 || parts[1] == "" || !InboxItemType(parts[0]).IsValid() {
+		return "", "", errors.NewInvalidInputError("invalid inbox item id")
+	}
+	return InboxItemType(parts[0]), parts[1], nil
+}
+
+// InboxItem is something front-desk staff need to act on. Items are not
+// stored: they are derived from the appointment, interaction, message or call
+// they point at, with the follow-up state kept alongside in InboxItemState.
+type InboxItem struct {
+	ID           string          `json:"id"`
+	BusinessID   string          `json:"business_id"`
+	Type         InboxItemType   `json:"type"`
+	SourceID     string          `json:"source_id"`
+	CallID       *string         `json:"call_id,omitempty"`
+	Title        string          `json:"title"`
+	Summary      string          `json:"summary,omitempty"`
+	Phone        string          `json:"phone,omitempty"`
+	Priority     InboxPriority   `json:"priority"`
+	OccurredAt   time.Time       `json:"occurred_at"`
+	State        *InboxItemState `json:"state"`
+	CommentCount int             `json:"comment_count"`
+}
+
+func NewInboxItem(businessID string, itemType InboxItemType, sourceID string, priority InboxPriority, occurredAt time.Time) *InboxItem {
+	return &InboxItem{
+		ID:         InboxItemKey(itemType, sourceID),
+		BusinessID: businessID,
+		Type:       itemType,
+		SourceID:   sourceID,
+		Priority:   priority,
+		OccurredAt: occurredAt,
+		State:      NewInboxItemState(businessID, itemType, sourceID),
+	}
+}
+
+// InboxItemState is the follow-up state of an inbox item: who is handling
+// it, whether it is snoozed and whether it is done
+type InboxItemState struct {
+	BusinessID   string        `json:"business_id"`
+	ItemType     InboxItemType `json:"item_type"`
+	SourceID     string        `json:"source_id"`
+	AssigneeID   *string       `json:"assignee_id,omitempty"`
+	SnoozedUntil *time.Time    `json:"snoozed_until,omitempty"`
+	DoneBy       *string       `json:"done_by,omitempty"`
+	DoneAt       *time.Time    `json:"done_at,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+func NewInboxItemState(businessID string, itemType InboxItemType, sourceID string) *InboxItemState {
+	now := time.Now()
+	return &InboxItemState{
+		BusinessID: businessID,
+		ItemType:   itemType,
+		SourceID:   sourceID,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// Assign hands the item to a user. A nil user unassigns it.
+func (s *InboxItemState) Assign(userID *string) {
+	s.AssigneeID = userID
+	s.UpdatedAt = time.Now()
+}
+
+// Snooze hides the item from the active feed until the given time
+func (s *InboxItemState) Snooze(until, now time.Time) error {
+	if s.IsDone() {
+		return errors.NewValidationError("done items cannot be snoozed")
+	}
+	if !until.After(now) {
+		return errors.NewFieldValidationError("until", "snooze time must be in the future")
+	}
+	s.SnoozedUntil = &until
+	s.UpdatedAt = time.Now()
+	return nil
+}
+
+// MarkDone records that the item has been dealt with
+func (s *InboxItemState) MarkDone(userID string) error {
+	if s.IsDone() {
+		return errors.NewValidationError("item is already done")
+	}
+	now := time.Now()
+	s.DoneBy = &userID
+	s.DoneAt = &now
+	s.SnoozedUntil = nil
+	s.UpdatedAt = now
+	return nil
+}
+
+// Reopen returns a done or snoozed item to the active feed
+func (s *InboxItemState) Reopen() {
+	s.DoneBy = nil
+	s.DoneAt = nil
+	s.SnoozedUntil = nil
+	s.UpdatedAt = time.Now()
+}
+
+func (s *InboxItemState) IsDone() bool {
+	return s.DoneAt != nil
+}
+
+func (s *InboxItemState) IsSnoozed(now time.Time) bool {
+	return !s.IsDone() && s.SnoozedUntil != nil && s.SnoozedUntil.After(now)
+}
+
+// IsActive reports whether the item belongs in the active feed
+func (s *InboxItemState) IsActive(now time.Time) bool {
+	return !s.IsDone() && !s.IsSnoozed(now)
+}
+
+// InboxComment is a note staff leave on an inbox item
+type InboxComment struct {
+	ID         string        `json:"id"`
+	BusinessID string        `json:"business_id"`
+	ItemType   InboxItemType `json:"item_type"`
+	SourceID   string        `json:"source_id"`
+	UserID     string        `json:"user_id"`
+	Body       string        `json:"body"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+func NewInboxComment(businessID string, itemType InboxItemType, sourceID, userID, body string) (*InboxComment, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user_id is required")
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, errors.NewFieldValidationError("body", "comment is required")
+	}
+	if len(body) > 2000 {
+		return nil, errors.NewFieldValidationError("body", "comment must be at most 2000 characters")
+	}
+
+	return &InboxComment{
+		BusinessID: businessID,
+		ItemType:   itemType,
+		SourceID:   sourceID,
+		UserID:     userID,
+		Body:       body,
+		CreatedAt:  time.Now(),
+	}, nil
+}
@@ -15,6 +15,7 @@ const (
 	InteractionTypeInformation        InteractionType = "information"
 	InteractionTypeGreeting           InteractionType = "greeting"
 	InteractionTypeFarewell           InteractionType = "farewell"
+	InteractionTypeTransfer           InteractionType = "transfer"
 	InteractionTypeOther              InteractionType = "other"
 )
 
@@ -39,6 +40,7 @@ func NewInteraction(callID string, interactionType InteractionType, content map[
 		InteractionTypeInformation:        true,
 		InteractionTypeGreeting:           true,
 		InteractionTypeFarewell:           true,
+		InteractionTypeTransfer:           true,
 		InteractionTypeOther:              true,
 	}
 
@@ -60,6 +62,17 @@ func (i *Interaction) IsAppointmentRequest() bool {
 	return i.Type == InteractionTypeAppointmentRequest
 }
 
+// Summary returns the free-text description extracted for the interaction,
+// or "" if there is none
+func (i *Interaction) Summary() string {
+	for _, key := range []string{"summary", "description", "text"} {
+		if value, ok := i.Content[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
 func (i *Interaction) Validate() error {
 	if i.CallID == "" {
 		return errors.NewValidationError("call_id is required")
@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+// UserInvitation lets someone join an existing business. The invitee accepts
+// it with the one-time token, which is only stored hashed.
+type UserInvitation struct {
+	ID         string     `json:"id"`
+	BusinessID string     `json:"business_id"`
+	Email      string     `json:"email"`
+	Role       UserRole   `json:"role"`
+	TokenHash  string     `json:"-"`
+	InvitedBy  string     `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func NewUserInvitation(businessID, email string, role UserRole, invitedBy, tokenHash string, expiresAt time.Time) (*UserInvitation, error) {
+	if businessID == "" {
+		return nil, errors.NewValidationError("business_id is required")
+	}
+	email = strings.TrimSpace(email)
+	if !strings.Contains(email, "@") {
+		return nil, errors.NewFieldValidationError("email", "a valid email address is required")
+	}
+	if !role.IsValid() {
+		return nil, errors.NewFieldValidationError("role", "role must be owner, admin or employee")
+	}
+	if tokenHash == "" {
+		return nil, errors.NewValidationError("token is required")
+	}
+
+	return &UserInvitation{
+		BusinessID: businessID,
+		Email:      email,
+		Role:       role,
+		TokenHash:  tokenHash,
+		InvitedBy:  invitedBy,
+		ExpiresAt:  expiresAt,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// IsPending reports whether the invitation can still be accepted
+func (i *UserInvitation) IsPending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
+
+func (i *UserInvitation) Accept(now time.Time) error {
+	if !i.IsPending(now) {
+		return errors.NewValidationError("invitation is no longer valid")
+	}
+	i.AcceptedAt = &now
+	return nil
+}
+
+func (i *UserInvitation) Revoke(now time.Time) error {
+	if !i.IsPending(now) {
+		return errors.NewValidationError("only pending invitations can be revoked")
+	}
+	i.RevokedAt = &now
+	return nil
+}
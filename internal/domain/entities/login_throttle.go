@@ -0,0 +1,35 @@
+package entities
+
+import "time"
+
+// LoginThrottle counts recent failed logins for one key, an account or a
+// client IP address
+type LoginThrottle struct {
+	Key           string     `json:"key"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
+
+// IsLocked reports whether logins for the key are locked out at now
+func (t *LoginThrottle) IsLocked(now time.Time) bool {
+	return t != nil && t.LockedUntil != nil && now.Before(*t.LockedUntil)
+}
+
+// RetryAt returns when the next attempt is allowed after a delay that doubles
+// with every failure, from base up to max
+func (t *LoginThrottle) RetryAt(base, max time.Duration) time.Time {
+	if t == nil || t.Failures == 0 {
+		return time.Time{}
+	}
+
+	delay := base
+	for i := 1; i < t.Failures && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+
+	return t.LastFailureAt.Add(delay)
+}
@@ -0,0 +1,46 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+// Membership gives a user a role in a business. Every user is a member of the
+// business they signed up with, their home business, and can be invited to
+// others, such as an agency operator or an owner of several clinics.
+type Membership struct {
+	UserID     string    `json:"user_id"`
+	BusinessID string    `json:"business_id"`
+	Role       UserRole  `json:"role"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func NewMembership(userID, businessID string, role UserRole) (*Membership, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user_id is required")
+	}
+	if businessID == "" {
+		return nil, errors.NewValidationError("business_id is required")
+	}
+	if !role.IsValid() {
+		return nil, errors.NewValidationError("invalid user role")
+	}
+
+	return &Membership{
+		UserID:     userID,
+		BusinessID: businessID,
+		Role:       role,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// AsMember returns a copy of the user acting in the membership's business,
+// with their role there. Saving the copy only changes the role of that
+// membership, never the user's home business.
+func (u *User) AsMember(m *Membership) *User {
+	member := *u
+	member.BusinessID = m.BusinessID
+	member.Role = m.Role
+	return &member
+}
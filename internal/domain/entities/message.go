@@ -0,0 +1,287 @@
+package entities
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type MessageStatus string
+
+const (
+	MessageStatusOpen         MessageStatus = "open"
+	MessageStatusAcknowledged MessageStatus = "acknowledged"
+	MessageStatusResolved     MessageStatus = "resolved"
+)
+
+type MessageUrgency string
+
+const (
+	MessageUrgencyLow    MessageUrgency = "low"
+	MessageUrgencyNormal MessageUrgency = "normal"
+	MessageUrgencyUrgent MessageUrgency = "urgent"
+)
+
+type MessageSource string
+
+const (
+	// MessageSourceTool is a message the assistant took with the take_message function
+	MessageSourceTool MessageSource = "tool"
+	// MessageSourceExtraction is a message found in the provider's analysis of the call
+	MessageSourceExtraction MessageSource = "extraction"
+)
+
+// Message is a message a caller left for someone at the business. It is
+// routed to a recipient from the business's directory and followed up until
+// resolved.
+type Message struct {
+	ID             string         `json:"id"`
+	BusinessID     string         `json:"business_id"`
+	CallID         *string        `json:"call_id,omitempty"`
+	CallerName     string         `json:"caller_name,omitempty"`
+	CallerPhone    string         `json:"caller_phone"`
+	CallbackPhone  string         `json:"callback_phone"`
+	RecipientName  string         `json:"recipient_name,omitempty"` // who the caller asked for
+	RecipientID    *string        `json:"recipient_id,omitempty"`   // directory entry the message was routed to
+	AssigneeID     *string        `json:"assignee_id,omitempty"`    // user following the message up
+	Urgency        MessageUrgency `json:"urgency"`
+	Body           string         `json:"body"`
+	Source         MessageSource  `json:"source"`
+	Status         MessageStatus  `json:"status"`
+	AcknowledgedBy *string        `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time     `json:"acknowledged_at,omitempty"`
+	ResolvedBy     *string        `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time     `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+func NewMessage(businessID, callerPhone, callbackPhone, body string, urgency MessageUrgency, source MessageSource) (*Message, error) {
+	if businessID == "" {
+		return nil, errors.NewValidationError("business_id is required")
+	}
+	if err := validatePhone("caller_phone", callerPhone); err != nil {
+		return nil, err
+	}
+	if callbackPhone == "" {
+		callbackPhone = callerPhone
+	}
+	if err := validatePhone("callback_number", callbackPhone); err != nil {
+		return nil, err
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, errors.NewFieldValidationError("message", "message is required")
+	}
+	if urgency == "" {
+		urgency = MessageUrgencyNormal
+	}
+	if !urgency.IsValid() {
+		return nil, errors.NewFieldValidationError("urgency", "urgency must be low, normal or urgent")
+	}
+	if source != MessageSourceTool && source != MessageSourceExtraction {
+		return nil, errors.NewFieldValidationError("source", "invalid message source")
+	}
+
+	now := time.Now()
+	return &Message{
+		BusinessID:    businessID,
+		CallerPhone:   callerPhone,
+		CallbackPhone: callbackPhone,
+		Urgency:       urgency,
+		Body:          body,
+		Source:        source,
+		Status:        MessageStatusOpen,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+func (u MessageUrgency) IsValid() bool {
+	return u == MessageUrgencyLow || u == MessageUrgencyNormal || u == MessageUrgencyUrgent
+}
+
+func (s MessageStatus) IsValid() bool {
+	return s == MessageStatusOpen || s == MessageStatusAcknowledged || s == MessageStatusResolved
+}
+
+// RouteTo delivers the message to a directory entry and hands follow-up to
+// the user linked to it, if any
+func (m *Message) RouteTo(recipient *MessageRecipient) {
+	m.RecipientID = &recipient.ID
+	if recipient.UserID != nil && m.AssigneeID == nil {
+		userID := *recipient.UserID
+		m.AssigneeID = &userID
+	}
+}
+
+// Assign hands follow-up to a user. A nil user unassigns the message.
+func (m *Message) Assign(userID *string) {
+	m.AssigneeID = userID
+	m.UpdatedAt = time.Now()
+}
+
+// Acknowledge records that someone has seen the message
+func (m *Message) Acknowledge(userID string) error {
+	if m.Status != MessageStatusOpen {
+		return errors.NewValidationError("only open messages can be acknowledged")
+	}
+	now := time.Now()
+	m.Status = MessageStatusAcknowledged
+	m.AcknowledgedBy = &userID
+	m.AcknowledgedAt = &now
+	m.UpdatedAt = now
+	return nil
+}
+
+// Resolve closes the message once the caller has been dealt with
+func (m *Message) Resolve(userID string) error {
+	if m.Status == MessageStatusResolved {
+		return errors.NewValidationError("message is already resolved")
+	}
+	now := time.Now()
+	if m.AcknowledgedAt == nil {
+		m.AcknowledgedBy = &userID
+		m.AcknowledgedAt = &now
+	}
+	m.Status = MessageStatusResolved
+	m.ResolvedBy = &userID
+	m.ResolvedAt = &now
+	m.UpdatedAt = now
+	return nil
+}
+
+// Reopen puts a resolved message back in the acknowledged state
+func (m *Message) Reopen() error {
+	if m.Status != MessageStatusResolved {
+		return errors.NewValidationError("only resolved messages can be reopened")
+	}
+	m.Status = MessageStatusAcknowledged
+	m.ResolvedBy = nil
+	m.ResolvedAt = nil
+	m.UpdatedAt = time.Now()
+	return nil
+}
+
+type NotificationChannelType string
+
+const (
+	NotificationChannelEmail   NotificationChannelType = "email"
+	NotificationChannelSMS     NotificationChannelType = "sms"
+	NotificationChannelWebhook NotificationChannelType = "webhook"
+)
+
+// NotificationChannel is one way of reaching a message recipient
+type NotificationChannel struct {
+	Type   NotificationChannelType `json:"type"`
+	Target string                  `json:"target"` // email address, E.164 number or URL
+}
+
+func (c NotificationChannel) Validate() error {
+	switch c.Type {
+	case NotificationChannelEmail:
+		if !strings.Contains(c.Target, "@") {
+			return errors.NewFieldValidationError("channels", "email channel needs an email address")
+		}
+	case NotificationChannelSMS:
+		if err := validatePhone("channels", c.Target); err != nil {
+			return err
+		}
+	case NotificationChannelWebhook:
+		if !strings.HasPrefix(c.Target, "https://") && !strings.HasPrefix(c.Target, "http://") {
+			return errors.NewFieldValidationError("channels", "webhook channel needs an http(s) URL")
+		}
+	default:
+		return errors.NewFieldValidationError("channels", "channel type must be email, sms or webhook")
+	}
+	return nil
+}
+
+// MessageRecipient is an entry in the business's message directory: a person
+// or team callers can leave messages for, such as "Dr. Lee" or "billing".
+// Recipients need not have a login; UserID links one who does.
+type MessageRecipient struct {
+	ID         string                `json:"id"`
+	BusinessID string                `json:"business_id"`
+	UserID     *string               `json:"user_id,omitempty"`
+	Name       string                `json:"name"`
+	Aliases    []string              `json:"aliases,omitempty"`
+	Channels   []NotificationChannel `json:"channels"`
+	Active     bool                  `json:"active"`
+	CreatedAt  time.Time             `json:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at"`
+}
+
+func NewMessageRecipient(businessID, name string, aliases []string, channels []NotificationChannel, userID *string) (*MessageRecipient, error) {
+	if businessID == "" {
+		return nil, errors.NewValidationError("business_id is required")
+	}
+
+	recipient := &MessageRecipient{BusinessID: businessID}
+	if err := recipient.Update(name, aliases, channels, userID, true); err != nil {
+		return nil, err
+	}
+	recipient.CreatedAt = recipient.UpdatedAt
+	return recipient, nil
+}
+
+func (r *MessageRecipient) Update(name string, aliases []string, channels []NotificationChannel, userID *string, active bool) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.NewFieldValidationError("name", "name is required")
+	}
+	for _, channel := range channels {
+		if err := channel.Validate(); err != nil {
+			return err
+		}
+	}
+
+	r.Name = name
+	r.Aliases = nil
+	for _, alias := range aliases {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			r.Aliases = append(r.Aliases, alias)
+		}
+	}
+	r.Channels = channels
+	r.UserID = userID
+	r.Active = active
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// honorifics are ignored when matching the name a caller asked for
+var honorifics = map[string]bool{"dr": true, "doctor": true, "mr": true, "mrs": true, "ms": true, "miss": true}
+
+var nonWordPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// matchKey reduces a name to lowercase words without punctuation or titles,
+// so "Dr. Lee" and "lee" match
+func matchKey(name string) string {
+	var words []string
+	for _, word := range strings.Fields(nonWordPattern.ReplaceAllString(strings.ToLower(name), " ")) {
+		if !honorifics[word] {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Matches reports whether the name a caller asked for refers to this recipient
+func (r *MessageRecipient) Matches(requested string) bool {
+	key := matchKey(requested)
+	if key == "" {
+		return false
+	}
+	if matchKey(r.Name) == key {
+		return true
+	}
+	for _, alias := range r.Aliases {
+		if matchKey(alias) == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+// UserMFA is a user's TOTP enrolment. It is pending until the user confirms
+// it with a code from their authenticator app. The secret is stored
+// encrypted, as it is needed to check codes.
+type UserMFA struct {
+	UserID          string     `json:"user_id"`
+	EncryptedSecret string     `json:"-"`
+	EnabledAt       *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep    int64      `json:"-"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+func NewUserMFA(userID, encryptedSecret string) (*UserMFA, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user_id is required")
+	}
+	if encryptedSecret == "" {
+		return nil, errors.NewValidationError("secret is required")
+	}
+
+	return &UserMFA{
+		UserID:          userID,
+		EncryptedSecret: encryptedSecret,
+		CreatedAt:       time.Now(),
+	}, nil
+}
+
+// IsEnabled reports whether sign-in requires a second factor
+func (m *UserMFA) IsEnabled() bool {
+	return m != nil && m.EnabledAt != nil
+}
+
+func (m *UserMFA) Enable(now time.Time) error {
+	if m.EnabledAt != nil {
+		return errors.NewValidationError("two-factor authentication is already enabled")
+	}
+	m.EnabledAt = &now
+	return nil
+}
+
+// UseStep records the time step of an accepted code. A code cannot be used
+// twice, so steps at or before the last one are refused.
+func (m *UserMFA) UseStep(step int64) error {
+	if step <= m.LastUsedStep {
+		return errors.NewUnauthorizedError("code has already been used")
+	}
+	m.LastUsedStep = step
+	return nil
+}
+
+// RecoveryCode is a one-time code that stands in for the authenticator app.
+// Only its hash is stored.
+type RecoveryCode struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func NewRecoveryCode(userID, codeHash string) *RecoveryCode {
+	return &RecoveryCode{
+		UserID:    userID,
+		CodeHash:  codeHash,
+		CreatedAt: time.Now(),
+	}
+}
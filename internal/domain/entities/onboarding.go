@@ -0,0 +1,231 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+// OnboardingStepName is one step of setting a business up to take calls
+type OnboardingStepName string
+
+const (
+	// OnboardingStepProfile saves the business type and opening hours
+	OnboardingStepProfile OnboardingStepName = "profile"
+	// OnboardingStepAssistant creates the business's assistant
+	OnboardingStepAssistant OnboardingStepName = "assistant"
+	// OnboardingStepPhoneNumber attaches the business's number to the
+	// voice provider so the assistant answers it
+	OnboardingStepPhoneNumber OnboardingStepName = "phone_number"
+)
+
+// OnboardingSteps are the steps in the order they run
+var OnboardingSteps = []OnboardingStepName{
+	OnboardingStepProfile,
+	OnboardingStepAssistant,
+	OnboardingStepPhoneNumber,
+}
+
+type OnboardingStepStatus string
+
+const (
+	OnboardingStepPending   OnboardingStepStatus = "pending"
+	OnboardingStepCompleted OnboardingStepStatus = "completed"
+	OnboardingStepFailed    OnboardingStepStatus = "failed"
+)
+
+type OnboardingStatus string
+
+const (
+	OnboardingStatusInProgress OnboardingStatus = "in_progress"
+	OnboardingStatusFailed     OnboardingStatus = "failed"
+	OnboardingStatusCompleted  OnboardingStatus = "completed"
+)
+
+// Limits on what a business describes at onboarding, which all ends up in
+// the assistant's prompt
+const (
+	maxOfferedServices = 50
+	maxFAQs            = 50
+)
+
+// OfferedService is something the business offers that callers can book
+type OfferedService struct {
+	Name            string `json:"name"`
+	Description     string `json:"description,omitempty"`
+	DurationMinutes int    `json:"duration_minutes,omitempty"`
+}
+
+// FAQ is a question callers often ask and the answer the assistant gives
+type FAQ struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// OnboardingProfile is what a business tells us about itself so its
+// assistant can be generated
+type OnboardingProfile struct {
+	BusinessType string           `json:"business_type"`
+	Timezone     string           `json:"timezone,omitempty"`
+	Hours        WeeklyHours      `json:"hours"`
+	Services     []OfferedService `json:"services"`
+	FAQs         []FAQ            `json:"faqs"`
+}
+
+// Validate checks the profile. The time zone is checked with the rest of
+// the settings when the profile is saved.
+func (p OnboardingProfile) Validate() error {
+	if strings.TrimSpace(p.BusinessType) == "" {
+		return errors.NewFieldValidationError("business_type", "business type is required")
+	}
+
+	for _, day := range p.Hours.days() {
+		if err := validateOpeningHours("hours."+day.name, day.hours); err != nil {
+			return err
+		}
+	}
+
+	if err := validateOfferedServices(p.Services); err != nil {
+		return err
+	}
+
+	if len(p.FAQs) > maxFAQs {
+		return errors.NewFieldValidationError("faqs", fmt.Sprintf("at most %d questions can be listed", maxFAQs))
+	}
+	for i, faq := range p.FAQs {
+		if strings.TrimSpace(faq.Question) == "" || strings.TrimSpace(faq.Answer) == "" {
+			return errors.NewFieldValidationError(fmt.Sprintf("faqs[%d]", i), "question and answer are required")
+		}
+	}
+	return nil
+}
+
+func validateOfferedServices(services []OfferedService) error {
+	if len(services) > maxOfferedServices {
+		return errors.NewFieldValidationError("services", fmt.Sprintf("at most %d services can be listed", maxOfferedServices))
+	}
+	for i, service := range services {
+		if strings.TrimSpace(service.Name) == "" {
+			return errors.NewFieldValidationError(fmt.Sprintf("services[%d].name", i), "service name is required")
+		}
+		if service.DurationMinutes < 0 {
+			return errors.NewFieldValidationError(fmt.Sprintf("services[%d].duration_minutes", i), "duration cannot be negative")
+		}
+	}
+	return nil
+}
+
+// OnboardingStep records how far one step got. Error is set while the step
+// has failed.
+type OnboardingStep struct {
+	Name        OnboardingStepName   `json:"name"`
+	Status      OnboardingStepStatus `json:"status"`
+	Error       string               `json:"error,omitempty"`
+	Attempts    int                  `json:"attempts"`
+	CompletedAt *time.Time           `json:"completed_at,omitempty"`
+}
+
+// Onboarding sets a new business up to take calls: it saves the business's
+// profile, creates its assistant and attaches its number. Each step is
+// recorded as it finishes, so an onboarding that failed resumes from the
+// step that failed. AssistantID and PhoneNumberID keep what the voice
+// provider created, so a resumed onboarding does not create them twice.
+type Onboarding struct {
+	BusinessID    string            `json:"business_id"`
+	Profile       OnboardingProfile `json:"profile"`
+	Status        OnboardingStatus  `json:"status"`
+	Steps         []OnboardingStep  `json:"steps"`
+	AssistantID   string            `json:"assistant_id,omitempty"`
+	PhoneNumberID string            `json:"phone_number_id,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	CompletedAt   *time.Time        `json:"completed_at,omitempty"`
+}
+
+func NewOnboarding(businessID string, profile OnboardingProfile) (*Onboarding, error) {
+	if businessID == "" {
+		return nil, errors.NewValidationError("business_id is required")
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	o := &Onboarding{
+		BusinessID: businessID,
+		Profile:    profile,
+		Status:     OnboardingStatusInProgress,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	for _, name := range OnboardingSteps {
+		o.Steps = append(o.Steps, OnboardingStep{Name: name, Status: OnboardingStepPending})
+	}
+	return o, nil
+}
+
+// IsCompleted reports whether every step has finished
+func (o *Onboarding) IsCompleted() bool {
+	return o.Status == OnboardingStatusCompleted
+}
+
+// NextStep returns the first step that has not completed, or nil once they
+// all have
+func (o *Onboarding) NextStep() *OnboardingStep {
+	for i := range o.Steps {
+		if o.Steps[i].Status != OnboardingStepCompleted {
+			return &o.Steps[i]
+		}
+	}
+	return nil
+}
+
+// Step returns the named step
+func (o *Onboarding) Step(name OnboardingStepName) *OnboardingStep {
+	for i := range o.Steps {
+		if o.Steps[i].Name == name {
+			return &o.Steps[i]
+		}
+	}
+	return nil
+}
+
+// CompleteStep records that the step finished, and the onboarding once it
+// was the last
+func (o *Onboarding) CompleteStep(name OnboardingStepName) {
+	step := o.Step(name)
+	if step == nil {
+		return
+	}
+
+	now := time.Now()
+	step.Status = OnboardingStepCompleted
+	step.Error = ""
+	step.Attempts++
+	step.CompletedAt = &now
+	o.UpdatedAt = now
+
+	if o.NextStep() == nil {
+		o.Status = OnboardingStatusCompleted
+		o.CompletedAt = &now
+	} else {
+		o.Status = OnboardingStatusInProgress
+	}
+}
+
+// FailStep records that the step failed. The steps after it wait until the
+// onboarding is resumed.
+func (o *Onboarding) FailStep(name OnboardingStepName, reason string) {
+	step := o.Step(name)
+	if step == nil {
+		return
+	}
+
+	step.Status = OnboardingStepFailed
+	step.Error = reason
+	step.Attempts++
+	o.Status = OnboardingStatusFailed
+	o.UpdatedAt = time.Now()
+}
@@ -0,0 +1,142 @@
+package entities
+
+// Permission is an action a user may be allowed to take within their business
+type Permission string
+
+const (
+	PermissionBusinessRead  Permission = "business:read"
+	PermissionBusinessWrite Permission = "business:write"
+
+	PermissionCallsRead   Permission = "calls:read"
+	PermissionCallsCreate Permission = "calls:create"
+
+	PermissionAppointmentsRead   Permission = "appointments:read"
+	PermissionAppointmentsUpdate Permission = "appointments:update"
+
+	PermissionCampaignsRead  Permission = "campaigns:read"
+	PermissionCampaignsWrite Permission = "campaigns:write"
+
+	PermissionScheduledCallsRead  Permission = "scheduled_calls:read"
+	PermissionScheduledCallsWrite Permission = "scheduled_calls:write"
+
+	PermissionTransfersRead  Permission = "transfers:read"
+	PermissionTransfersWrite Permission = "transfers:write"
+
+	PermissionMessagesRead    Permission = "messages:read"
+	PermissionMessagesUpdate  Permission = "messages:update"
+	PermissionRecipientsWrite Permission = "recipients:write"
+
+	PermissionInboxRead   Permission = "inbox:read"
+	PermissionInboxUpdate Permission = "inbox:update"
+
+	// PermissionComplianceWrite adds do-not-call entries and records or revokes
+	// consent; PermissionComplianceManage removes do-not-call entries
+	PermissionComplianceRead   Permission = "compliance:read"
+	PermissionComplianceWrite  Permission = "compliance:write"
+	PermissionComplianceManage Permission = "compliance:manage"
+
+	PermissionAnalyticsRead Permission = "analytics:read"
+
+	PermissionUsersRead   Permission = "users:read"
+	PermissionUsersManage Permission = "users:manage"
+
+	PermissionAPIKeysManage Permission = "api_keys:manage"
+
+	// PermissionAgencyManage creates and manages the child businesses of an
+	// agency, and lets the user act in them
+	PermissionAgencyManage Permission = "agency:manage"
+
+	PermissionAuditRead Permission = "audit:read"
+
+	// PermissionSSOManage configures the business's identity provider, which
+	// vouches for who its staff are
+	PermissionSSOManage Permission = "sso:manage"
+)
+
+// employeePermissions cover the day-to-day work of the front desk: reading
+// everything, placing calls and following up appointments, messages and
+// inbox items
+var employeePermissions = []Permission{
+	PermissionBusinessRead,
+	PermissionCallsRead,
+	PermissionCallsCreate,
+	PermissionAppointmentsRead,
+	PermissionAppointmentsUpdate,
+	PermissionCampaignsRead,
+	PermissionScheduledCallsRead,
+	PermissionScheduledCallsWrite,
+	PermissionTransfersRead,
+	PermissionMessagesRead,
+	PermissionMessagesUpdate,
+	PermissionInboxRead,
+	PermissionInboxUpdate,
+	PermissionComplianceRead,
+	PermissionComplianceWrite,
+	PermissionAnalyticsRead,
+	PermissionUsersRead,
+}
+
+// adminPermissions are added to the employee's to configure the business
+// and manage its team
+var adminPermissions = []Permission{
+	PermissionBusinessWrite,
+	PermissionCampaignsWrite,
+	PermissionTransfersWrite,
+	PermissionRecipientsWrite,
+	PermissionComplianceManage,
+	PermissionUsersManage,
+	PermissionAPIKeysManage,
+	PermissionAgencyManage,
+	PermissionAuditRead,
+}
+
+// ownerPermissions are only the owner's: an identity provider signs in
+// anyone it vouches for, so choosing it is as good as holding every account
+var ownerPermissions = []Permission{
+	PermissionSSOManage,
+}
+
+// RolePermissions is the policy table: the permissions each role grants.
+// Owners hold every admin permission and their own.
+var RolePermissions = map[UserRole]map[Permission]bool{
+	UserRoleEmployee: permissionSet(employeePermissions),
+	UserRoleAdmin:    permissionSet(employeePermissions, adminPermissions),
+	UserRoleOwner:    permissionSet(employeePermissions, adminPermissions, ownerPermissions),
+}
+
+func permissionSet(groups ...[]Permission) map[Permission]bool {
+	set := make(map[Permission]bool)
+	for _, group := range groups {
+		for _, permission := range group {
+			set[permission] = true
+		}
+	}
+	return set
+}
+
+// unverifiedPermissions are all a user can do until they verify their email
+// address: look, but not change anything
+var unverifiedPermissions = permissionSet([]Permission{
+	PermissionBusinessRead,
+	PermissionCallsRead,
+	PermissionAppointmentsRead,
+	PermissionCampaignsRead,
+	PermissionScheduledCallsRead,
+	PermissionTransfersRead,
+	PermissionMessagesRead,
+	PermissionInboxRead,
+	PermissionComplianceRead,
+	PermissionAnalyticsRead,
+	PermissionUsersRead,
+})
+
+// AllowedWhileUnverified reports whether a user who has not verified their
+// email address may use the permission their role grants
+func AllowedWhileUnverified(permission Permission) bool {
+	return unverifiedPermissions[permission]
+}
+
+// Can reports whether the role grants the permission
+func (r UserRole) Can(permission Permission) bool {
+	return RolePermissions[r][permission]
+}
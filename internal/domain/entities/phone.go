@@ -0,0 +1,15 @@
+package entities
+
+import (
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/pkg/phone"
+)
+
+// validatePhone checks that a stored phone number is already in E.164.
+// Callers normalise user input with phone.Normalize before building entities.
+func validatePhone(field, number string) error {
+	if !phone.IsE164(number) {
+		return errors.NewFieldValidationError(field, field+" must be a valid phone number in E.164 format")
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type ScheduledCallStatus string
+
+const (
+	ScheduledCallStatusScheduled ScheduledCallStatus = "scheduled"
+	ScheduledCallStatusInitiated ScheduledCallStatus = "initiated"
+	ScheduledCallStatusFailed    ScheduledCallStatus = "failed"
+	ScheduledCallStatusCancelled ScheduledCallStatus = "cancelled"
+)
+
+type ScheduledCallSource string
+
+const (
+	ScheduledCallSourceManual   ScheduledCallSource = "manual"
+	ScheduledCallSourceCallback ScheduledCallSource = "auto_callback"
+)
+
+const (
+	// maxScheduledCallAttempts is how many times the worker tries to place a
+	// scheduled call before marking it failed
+	maxScheduledCallAttempts = 3
+	scheduledCallRetryDelay  = 5 * time.Minute
+)
+
+// ScheduledCall is an outbound call to be placed at a given time, either
+// booked by a user or created automatically to call back a missed caller
+type ScheduledCall struct {
+	ID           string              `json:"id"`
+	BusinessID   string              `json:"business_id"`
+	Phone        string              `json:"phone"`
+	AssistantID  string              `json:"assistant_id,omitempty"`
+	Reason       string              `json:"reason,omitempty"`
+	Source       ScheduledCallSource `json:"source"`
+	Status       ScheduledCallStatus `json:"status"`
+	ScheduledFor time.Time           `json:"scheduled_for"`
+	Attempts     int                 `json:"attempts"`
+	LastError    string              `json:"last_error,omitempty"`
+	CallID       *string             `json:"call_id,omitempty"`        // call placed by the worker
+	OriginCallID *string             `json:"origin_call_id,omitempty"` // missed call that triggered a callback
+	CreatedBy    *string             `json:"created_by,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+}
+
+func NewScheduledCall(businessID, phone, assistantID, reason string, source ScheduledCallSource, scheduledFor time.Time) (*ScheduledCall, error) {
+	if businessID == "" {
+		return nil, errors.NewValidationError("business_id is required")
+	}
+	if err := validatePhone("phone_number", phone); err != nil {
+		return nil, err
+	}
+	if scheduledFor.IsZero() {
+		return nil, errors.NewFieldValidationError("scheduled_for", "scheduled_for is required")
+	}
+	if source != ScheduledCallSourceManual && source != ScheduledCallSourceCallback {
+		return nil, errors.NewFieldValidationError("source", "invalid scheduled call source")
+	}
+
+	now := time.Now()
+	return &ScheduledCall{
+		BusinessID:   businessID,
+		Phone:        phone,
+		AssistantID:  assistantID,
+		Reason:       reason,
+		Source:       source,
+		Status:       ScheduledCallStatusScheduled,
+		ScheduledFor: scheduledFor,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+// IsDue reports whether the call should be placed at t
+func (c *ScheduledCall) IsDue(t time.Time) bool {
+	return c.Status == ScheduledCallStatusScheduled && !t.Before(c.ScheduledFor)
+}
+
+// Reschedule moves the call to a new time. A call that failed to be placed
+// is scheduled again with a fresh set of attempts.
+func (c *ScheduledCall) Reschedule(at time.Time) error {
+	if c.Status != ScheduledCallStatusScheduled && c.Status != ScheduledCallStatusFailed {
+		return errors.NewValidationError("only scheduled or failed calls can be rescheduled")
+	}
+	if c.Status == ScheduledCallStatusFailed {
+		c.Attempts = 0
+		c.LastError = ""
+	}
+	c.Status = ScheduledCallStatusScheduled
+	c.ScheduledFor = at
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+func (c *ScheduledCall) Cancel() error {
+	if c.Status != ScheduledCallStatusScheduled {
+		return errors.NewValidationError("only scheduled calls can be cancelled")
+	}
+	c.Status = ScheduledCallStatusCancelled
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// MarkInitiated links the call placed by the worker
+func (c *ScheduledCall) MarkInitiated(callID string) {
+	c.Attempts++
+	c.CallID = &callID
+	c.Status = ScheduledCallStatusInitiated
+	c.LastError = ""
+	c.UpdatedAt = time.Now()
+}
+
+// MarkFailed gives up on the call
+func (c *ScheduledCall) MarkFailed(reason string) {
+	c.Status = ScheduledCallStatusFailed
+	c.LastError = reason
+	c.UpdatedAt = time.Now()
+}
+
+// RecordDialError counts a failed attempt to place the call and retries it
+// shortly, until the attempts run out
+func (c *ScheduledCall) RecordDialError(reason string, now time.Time) {
+	c.Attempts++
+	if c.Attempts >= maxScheduledCallAttempts {
+		c.MarkFailed(reason)
+		return
+	}
+	c.Defer(reason, now.Add(scheduledCallRetryDelay))
+}
+
+// Defer postpones the call without using up an attempt
+func (c *ScheduledCall) Defer(reason string, until time.Time) {
+	c.LastError = reason
+	c.ScheduledFor = until
+	c.UpdatedAt = time.Now()
+}
+
+// AutoCallbackRule decides which ended calls are automatically called back
+type AutoCallbackRule struct {
+	Enabled bool `json:"enabled"`
+	// DelayMinutes is how long after the missed call the callback is placed
+	DelayMinutes int `json:"delay_minutes"`
+	// EarlyFailureSeconds is the duration under which a call that ended is
+	// considered to have dropped before the caller was helped
+	EarlyFailureSeconds int `json:"early_failure_seconds"`
+}
+
+// DefaultAutoCallbackRule is used for any value missing from the business's
+// auto_callback setting
+var DefaultAutoCallbackRule = AutoCallbackRule{
+	Enabled:             false,
+	DelayMinutes:        10,
+	EarlyFailureSeconds: 30,
+}
+
+// Applies reports whether the ended call warrants a callback: an unanswered
+// call, a call that failed before or shortly after connecting, or an inbound
+// call the caller left within the first few seconds.
+func (r AutoCallbackRule) Applies(call *Call) bool {
+	if !r.Enabled || !call.IsCompleted() {
+		return false
+	}
+	connected := call.StartedAt != nil
+	early := connected && call.Duration < r.EarlyFailureSeconds
+	switch call.Status {
+	case CallStatusNoAnswer:
+		return true
+	case CallStatusFailed:
+		return !connected || early
+	case CallStatusCompleted:
+		return call.IsInbound() && early
+	default:
+		return false
+	}
+}
+
+// CallbackTime returns when the callback for a call that ended at endedAt is due
+func (r AutoCallbackRule) CallbackTime(endedAt time.Time) time.Time {
+	return endedAt.Add(time.Duration(r.DelayMinutes) * time.Minute)
+}
@@ -0,0 +1,57 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+// Session is a signed-in device, identified by its refresh token which is
+// only stored hashed. Every refresh rotates the token into a new session of
+// the same family and revokes the old one, so a revoked token being presented
+// again means it was stolen and the whole family is revoked.
+type Session struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	BusinessID   string     `json:"business_id"`
+	FamilyID     string     `json:"family_id"`
+	TokenHash    string     `json:"-"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	ReplacedByID *string    `json:"replaced_by_id,omitempty"`
+	// SSO is set for sessions opened through the business's identity
+	// provider, which vouches for that business only: they cannot switch
+	// to another
+	SSO       bool      `json:"sso"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewSession starts a session. familyID is empty for a fresh sign-in, in
+// which case the session starts its own family.
+func NewSession(userID, businessID, familyID, tokenHash string, expiresAt time.Time) (*Session, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user_id is required")
+	}
+	if tokenHash == "" {
+		return nil, errors.NewValidationError("token is required")
+	}
+
+	return &Session{
+		UserID:     userID,
+		BusinessID: businessID,
+		FamilyID:   familyID,
+		TokenHash:  tokenHash,
+		ExpiresAt:  expiresAt,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// IsActive reports whether the session's refresh token can still be used
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// IsRevoked reports whether the session was revoked, as opposed to expired
+func (s *Session) IsRevoked() bool {
+	return s.RevokedAt != nil
+}
@@ -0,0 +1,395 @@
+package entities
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata" // time zones are validated even on minimal images
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/pkg/mergepatch"
+	"github.com/CallPilotReceptionist/pkg/phone"
+)
+
+// CurrentSettingsVersion is the settings schema written by this build.
+// Stored settings of an older version are upgraded when they are read.
+const CurrentSettingsVersion = 1
+
+// BusinessSettingsSchema is the JSON Schema of BusinessSettings, published
+// for UIs that edit the settings
+//
+//go:embed business_settings.schema.json
+var BusinessSettingsSchema []byte
+
+// BusinessSettings configure how the receptionist works for a business.
+// Members missing from the stored document take their defaults (see
+// DefaultBusinessSettings).
+type BusinessSettings struct {
+	Version int `json:"version"`
+	// Timezone is the IANA time zone the business operates in
+	Timezone string `json:"timezone"`
+	// Locale is the BCP 47 language tag the assistant speaks, such as "en-US"
+	Locale string `json:"locale"`
+	// DefaultRegion is the ISO 3166 region used to read phone numbers
+	// written in national format, such as "(555) 123-4567"
+	DefaultRegion string `json:"default_region"`
+	// Hours are the business's opening hours in its time zone
+	Hours WeeklyHours `json:"hours"`
+	// Notifications choose which messages notify their recipients
+	Notifications NotificationSettings `json:"notifications"`
+	// TransferNumbers are the numbers the assistant offers when a caller
+	// asks for a person and no transfer destination is on call
+	TransferNumbers []TransferNumber `json:"transfer_numbers"`
+	// RecordingConsent is the disclosure played before a call is recorded
+	RecordingConsent RecordingConsent `json:"recording_consent"`
+	// CallingWindow is the local time of day when outbound calls may be
+	// placed, as "HH:MM" start and end times
+	CallingWindow CallingHours `json:"calling_window"`
+	// RequireConsent makes outbound calls need a consent record
+	RequireConsent bool `json:"require_consent"`
+	// AutoCallback is the rule for calling back missed and dropped calls
+	AutoCallback AutoCallbackRule `json:"auto_callback"`
+	// CallerRecognition personalises calls with the caller's history
+	CallerRecognition bool `json:"caller_recognition"`
+	// RequireAdminMFA makes owners and admins use two-factor authentication
+	RequireAdminMFA bool `json:"require_admin_mfa"`
+	// AssistantID is the provider's assistant that answers inbound calls
+	AssistantID string `json:"assistant_id"`
+}
+
+// WeeklyHours lists each day's opening hours. A day with no hours is closed.
+type WeeklyHours struct {
+	Monday    []OpeningHours `json:"monday"`
+	Tuesday   []OpeningHours `json:"tuesday"`
+	Wednesday []OpeningHours `json:"wednesday"`
+	Thursday  []OpeningHours `json:"thursday"`
+	Friday    []OpeningHours `json:"friday"`
+	Saturday  []OpeningHours `json:"saturday"`
+	Sunday    []OpeningHours `json:"sunday"`
+}
+
+// OpeningHours is one "HH:MM" opening and closing time. Close is exclusive.
+type OpeningHours struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+type NotificationSettings struct {
+	// Messages notifies a message's recipient when a caller leaves one
+	Messages bool `json:"messages"`
+	// UrgentOnly limits those notifications to urgent messages
+	UrgentOnly bool `json:"urgent_only"`
+}
+
+type TransferNumber struct {
+	Label string `json:"label"`
+	Phone string `json:"phone"`
+}
+
+type RecordingConsent struct {
+	// Announce plays the disclosure at the start of every call
+	Announce   bool   `json:"announce"`
+	Disclosure string `json:"disclosure"`
+}
+
+// CallingHours is the calling window as it is written in the settings
+type CallingHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// DefaultBusinessSettings are the settings of a new business
+func DefaultBusinessSettings() BusinessSettings {
+	weekday := []OpeningHours{{Open: "09:00", Close: "17:00"}}
+	return BusinessSettings{
+		Version:       CurrentSettingsVersion,
+		Timezone:      "UTC",
+		Locale:        "en-US",
+		DefaultRegion: phone.DefaultRegion,
+		Hours: WeeklyHours{
+			Monday:    weekday,
+			Tuesday:   weekday,
+			Wednesday: weekday,
+			Thursday:  weekday,
+			Friday:    weekday,
+			Saturday:  []OpeningHours{},
+			Sunday:    []OpeningHours{},
+		},
+		Notifications:   NotificationSettings{Messages: true},
+		TransferNumbers: []TransferNumber{},
+		RecordingConsent: RecordingConsent{
+			Disclosure: "This call may be recorded for quality and training purposes.",
+		},
+		CallingWindow: CallingHours{Start: "08:00", End: "21:00"},
+		AutoCallback:  DefaultAutoCallbackRule,
+	}
+}
+
+// settingsUpgrades rewrite stored settings from one version to the next,
+// indexed by the version they upgrade from
+var settingsUpgrades = map[int]func(raw map[string]interface{}){
+	// Version 0 is the free-form map stored before settings had a schema.
+	// Its keys match version 1, but values of the wrong type are dropped so
+	// they take their defaults.
+	0: func(raw map[string]interface{}) {
+		for key, value := range raw {
+			member, _ := json.Marshal(map[string]interface{}{key: value})
+			if err := json.Unmarshal(member, &BusinessSettings{}); err != nil {
+				delete(raw, key)
+			}
+		}
+	},
+}
+
+// ParseBusinessSettings reads stored settings, upgrading them to the
+// current version. Unknown members are ignored.
+func ParseBusinessSettings(data []byte) (BusinessSettings, error) {
+	raw := make(map[string]interface{})
+	if len(data) > 0 && string(data) != "null" {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return BusinessSettings{}, err
+		}
+	}
+
+	version := 0
+	if v, ok := raw["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > CurrentSettingsVersion {
+		return BusinessSettings{}, fmt.Errorf("settings version %d is newer than this build supports", version)
+	}
+	for ; version < CurrentSettingsVersion; version++ {
+		settingsUpgrades[version](raw)
+	}
+	raw["version"] = CurrentSettingsVersion
+
+	return decodeSettings(raw, false)
+}
+
+// Merge applies a JSON merge patch (RFC 7396), rejecting unknown members
+// and values of the wrong type. Members set to null go back to their
+// defaults, and the version cannot be patched. Settings that were never set
+// merge onto the defaults. Business.UpdateSettings validates the result.
+func (s BusinessSettings) Merge(patch []byte) (BusinessSettings, error) {
+	if s.Version == 0 {
+		s = DefaultBusinessSettings()
+	}
+
+	current, err := json.Marshal(s)
+	if err != nil {
+		return BusinessSettings{}, err
+	}
+
+	merged, err := mergepatch.Apply(current, patch)
+	if err != nil {
+		return BusinessSettings{}, errors.NewValidationError("settings must be valid JSON")
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(merged, &raw); err != nil || raw == nil {
+		return BusinessSettings{}, errors.NewValidationError("settings must be a JSON object")
+	}
+	raw["version"] = CurrentSettingsVersion
+
+	return decodeSettings(raw, true)
+}
+
+// decodeSettings fills the members missing from raw with their defaults and
+// decodes it. Strict decoding rejects unknown members.
+func decodeSettings(raw map[string]interface{}, strict bool) (BusinessSettings, error) {
+	var defaults map[string]interface{}
+	data, _ := json.Marshal(DefaultBusinessSettings())
+	json.Unmarshal(data, &defaults)
+	fillDefaults(raw, defaults)
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return BusinessSettings{}, err
+	}
+
+	var settings BusinessSettings
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(&settings); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if stderrors.As(err, &typeErr) {
+			return BusinessSettings{}, errors.NewFieldValidationError(typeErr.Field, typeErr.Field+" must be "+jsonType(typeErr.Type))
+		}
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return BusinessSettings{}, errors.NewValidationError("unknown setting " + field)
+		}
+		return BusinessSettings{}, errors.NewValidationError("invalid settings")
+	}
+	return settings, nil
+}
+
+// jsonType names the JSON type a Go type decodes from
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+// fillDefaults copies the defaults raw is missing, recursing into objects.
+// Arrays are replaced whole.
+func fillDefaults(raw, defaults map[string]interface{}) {
+	for key, value := range defaults {
+		current, ok := raw[key]
+		if !ok || current == nil {
+			raw[key] = value
+			continue
+		}
+		currentObject, currentIsObject := current.(map[string]interface{})
+		defaultObject, defaultIsObject := value.(map[string]interface{})
+		if currentIsObject && defaultIsObject {
+			fillDefaults(currentObject, defaultObject)
+		}
+	}
+}
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z][a-z]{3})?(-([A-Z]{2}|[0-9]{3}))?$`)
+
+// Validate checks the settings against the rules the schema cannot express
+// on its own, such as real time zones and opening hours that do not overlap
+func (s BusinessSettings) Validate() error {
+	if _, err := time.LoadLocation(s.Timezone); err != nil || s.Timezone == "" || s.Timezone == "Local" {
+		return errors.NewFieldValidationError("timezone", "timezone must be an IANA time zone such as America/New_York")
+	}
+	if !localePattern.MatchString(s.Locale) {
+		return errors.NewFieldValidationError("locale", "locale must be a language tag such as en-US")
+	}
+	if !phone.IsSupportedRegion(s.DefaultRegion) {
+		return errors.NewFieldValidationError("default_region", "unsupported phone region "+s.DefaultRegion)
+	}
+
+	for _, day := range s.Hours.days() {
+		if err := validateOpeningHours("hours."+day.name, day.hours); err != nil {
+			return err
+		}
+	}
+
+	if len(s.TransferNumbers) > 10 {
+		return errors.NewFieldValidationError("transfer_numbers", "at most 10 transfer numbers can be set")
+	}
+	for i, number := range s.TransferNumbers {
+		field := fmt.Sprintf("transfer_numbers[%d]", i)
+		if number.Label == "" {
+			return errors.NewFieldValidationError(field+".label", "label is required")
+		}
+		if err := validatePhone(field+".phone", number.Phone); err != nil {
+			return err
+		}
+	}
+
+	if s.RecordingConsent.Announce && s.RecordingConsent.Disclosure == "" {
+		return errors.NewFieldValidationError("recording_consent.disclosure", "a disclosure is required to announce recording")
+	}
+	if len(s.RecordingConsent.Disclosure) > 500 {
+		return errors.NewFieldValidationError("recording_consent.disclosure", "disclosure must be at most 500 characters")
+	}
+
+	if _, err := ParseCallingWindow(s.CallingWindow.Start, s.CallingWindow.End); err != nil {
+		return err
+	}
+
+	if s.AutoCallback.DelayMinutes < 0 || s.AutoCallback.DelayMinutes > 24*60 {
+		return errors.NewFieldValidationError("auto_callback.delay_minutes", "delay must be between 0 and 1440 minutes")
+	}
+	if s.AutoCallback.EarlyFailureSeconds < 0 {
+		return errors.NewFieldValidationError("auto_callback.early_failure_seconds", "early failure seconds cannot be negative")
+	}
+	return nil
+}
+
+func validateOpeningHours(field string, hours []OpeningHours) error {
+	type span struct{ open, close int }
+	spans := make([]span, 0, len(hours))
+	for i, h := range hours {
+		open, err := parseClock(h.Open)
+		if err != nil {
+			return errors.NewFieldValidationError(fmt.Sprintf("%s[%d].open", field, i), err.Error())
+		}
+		closing, err := parseClock(h.Close)
+		if err != nil {
+			return errors.NewFieldValidationError(fmt.Sprintf("%s[%d].close", field, i), err.Error())
+		}
+		if closing <= open {
+			return errors.NewFieldValidationError(fmt.Sprintf("%s[%d].close", field, i), "hours must close after they open")
+		}
+		spans = append(spans, span{open, closing})
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].open < spans[j].open })
+	for i := 1; i < len(spans); i++ {
+		if spans[i].open < spans[i-1].close {
+			return errors.NewFieldValidationError(field, "opening hours cannot overlap")
+		}
+	}
+	return nil
+}
+
+type dayHours struct {
+	name    string
+	weekday time.Weekday
+	hours   []OpeningHours
+}
+
+func (h WeeklyHours) days() []dayHours {
+	return []dayHours{
+		{"monday", time.Monday, h.Monday},
+		{"tuesday", time.Tuesday, h.Tuesday},
+		{"wednesday", time.Wednesday, h.Wednesday},
+		{"thursday", time.Thursday, h.Thursday},
+		{"friday", time.Friday, h.Friday},
+		{"saturday", time.Saturday, h.Saturday},
+		{"sunday", time.Sunday, h.Sunday},
+	}
+}
+
+// On returns the opening hours of the weekday
+func (h WeeklyHours) On(weekday time.Weekday) []OpeningHours {
+	for _, day := range h.days() {
+		if day.weekday == weekday {
+			return day.hours
+		}
+	}
+	return nil
+}
+
+// IsOpen reports whether t, already converted to the business's zone, falls
+// inside its opening hours
+func (h WeeklyHours) IsOpen(t time.Time) bool {
+	minutes := t.Hour()*60 + t.Minute()
+	for _, hours := range h.On(t.Weekday()) {
+		open, err := parseClock(hours.Open)
+		if err != nil {
+			continue
+		}
+		closing, err := parseClock(hours.Close)
+		if err != nil {
+			continue
+		}
+		if minutes >= open && minutes < closing {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package entities
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+// SSOConnection is a business's OpenID Connect identity provider. Staff with
+// an email address at one of AllowedDomains sign in through it, and those
+// without an account are created with DefaultRole on their first sign-in.
+// Existing accounts only sign in through it once their owner has linked it.
+// The client secret is stored encrypted, as it is sent to the provider.
+type SSOConnection struct {
+	BusinessID            string    `json:"business_id"`
+	Issuer                string    `json:"issuer"`
+	ClientID              string    `json:"client_id"`
+	EncryptedClientSecret string    `json:"-"`
+	AllowedDomains        []string  `json:"allowed_domains"`
+	DefaultRole           UserRole  `json:"default_role"`
+	Enabled               bool      `json:"enabled"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+func NewSSOConnection(businessID, issuer, clientID, encryptedClientSecret string, allowedDomains []string, defaultRole UserRole) (*SSOConnection, error) {
+	if businessID == "" {
+		return nil, errors.NewValidationError("business_id is required")
+	}
+	if encryptedClientSecret == "" {
+		return nil, errors.NewFieldValidationError("client_secret", "client secret is required")
+	}
+
+	now := time.Now()
+	c := &SSOConnection{
+		BusinessID:            businessID,
+		EncryptedClientSecret: encryptedClientSecret,
+		Enabled:               true,
+		CreatedAt:             now,
+		UpdatedAt:             now,
+	}
+	if err := c.Update(issuer, clientID, "", allowedDomains, defaultRole, true); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Update replaces the connection's settings. An empty encryptedClientSecret
+// keeps the current secret.
+func (c *SSOConnection) Update(issuer, clientID, encryptedClientSecret string, allowedDomains []string, defaultRole UserRole, enabled bool) error {
+	issuer = strings.TrimSuffix(strings.TrimSpace(issuer), "/")
+	if err := validateIssuer(issuer); err != nil {
+		return err
+	}
+	if strings.TrimSpace(clientID) == "" {
+		return errors.NewFieldValidationError("client_id", "client ID is required")
+	}
+
+	domains, err := normalizeDomains(allowedDomains)
+	if err != nil {
+		return err
+	}
+
+	if defaultRole == "" {
+		defaultRole = UserRoleEmployee
+	}
+	if !defaultRole.IsValid() {
+		return errors.NewFieldValidationError("default_role", "role must be admin or employee")
+	}
+	if defaultRole == UserRoleOwner {
+		return errors.NewFieldValidationError("default_role", "new users cannot be made owners")
+	}
+
+	c.Issuer = issuer
+	c.ClientID = strings.TrimSpace(clientID)
+	if encryptedClientSecret != "" {
+		c.EncryptedClientSecret = encryptedClientSecret
+	}
+	c.AllowedDomains = domains
+	c.DefaultRole = defaultRole
+	c.Enabled = enabled
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// AllowsEmail reports whether the email address is at one of the allowed
+// domains
+func (c *SSOConnection) AllowsEmail(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range c.AllowedDomains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// validateIssuer requires an https URL, except for loopback addresses used in
+// development
+func validateIssuer(issuer string) error {
+	u, err := url.Parse(issuer)
+	if err != nil || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+		return errors.NewFieldValidationError("issuer", "issuer must be a URL")
+	}
+	if u.Scheme == "https" {
+		return nil
+	}
+	if u.Scheme == "http" {
+		host := u.Hostname()
+		if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return nil
+		}
+	}
+	return errors.NewFieldValidationError("issuer", "issuer must use https")
+}
+
+func normalizeDomains(domains []string) ([]string, error) {
+	seen := make(map[string]bool)
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" || seen[domain] {
+			continue
+		}
+		if strings.ContainsAny(domain, "@/ ") || !strings.Contains(domain, ".") {
+			return nil, errors.NewFieldValidationError("allowed_domains", "invalid domain "+domain)
+		}
+		seen[domain] = true
+		normalized = append(normalized, domain)
+	}
+	if len(normalized) == 0 {
+		return nil, errors.NewFieldValidationError("allowed_domains", "at least one domain is required")
+	}
+	return normalized, nil
+}
+
+// SSOLoginState ties the identity provider's redirect back to the sign-in
+// that started it. Only the hash of the state parameter is stored, and the
+// PKCE verifier and nonce never leave the backend.
+type SSOLoginState struct {
+	StateHash    string `json:"-"`
+	BusinessID   string `json:"business_id"`
+	CodeVerifier string `json:"-"`
+	Nonce        string `json:"-"`
+	// UserID is the signed-in user linking their account, empty for a
+	// sign-in
+	UserID    string    `json:"user_id,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func NewSSOLoginState(businessID, stateHash, codeVerifier, nonce string, expiresAt time.Time) (*SSOLoginState, error) {
+	if businessID == "" {
+		return nil, errors.NewValidationError("business_id is required")
+	}
+	if stateHash == "" || codeVerifier == "" || nonce == "" {
+		return nil, errors.NewValidationError("state, code verifier and nonce are required")
+	}
+
+	return &SSOLoginState{
+		StateHash:    stateHash,
+		BusinessID:   businessID,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    expiresAt,
+		CreatedAt:    time.Now(),
+	}, nil
+}
+
+// IsExpired reports whether the sign-in took too long to come back
+func (s *SSOLoginState) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// SSOIdentity links an account at a business's identity provider, named by
+// the provider's issuer and subject, to a user
+type SSOIdentity struct {
+	BusinessID string    `json:"business_id"`
+	Issuer     string    `json:"issuer"`
+	Subject    string    `json:"subject"`
+	UserID     string    `json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func NewSSOIdentity(businessID, issuer, subject, userID string) (*SSOIdentity, error) {
+	if businessID == "" || userID == "" {
+		return nil, errors.NewValidationError("business_id and user_id are required")
+	}
+	if issuer == "" || subject == "" {
+		return nil, errors.NewValidationError("issuer and subject are required")
+	}
+
+	return &SSOIdentity{
+		BusinessID: businessID,
+		Issuer:     strings.TrimSuffix(issuer, "/"),
+		Subject:    subject,
+		UserID:     userID,
+		CreatedAt:  time.Now(),
+	}, nil
+}
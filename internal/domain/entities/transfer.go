@@ -0,0 +1,125 @@
+package entities
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type TransferMode string
+
+const (
+	// TransferModeWarm briefs the person answering before the caller is connected
+	TransferModeWarm TransferMode = "warm"
+	// TransferModeCold connects the caller straight away
+	TransferModeCold TransferMode = "cold"
+)
+
+// Outcomes recorded for each transfer the assistant attempts
+const (
+	TransferOutcomeInitiated   = "initiated"
+	TransferOutcomeFailed      = "failed"
+	TransferOutcomeUnsupported = "unsupported"
+)
+
+// destinationNamePattern keeps names usable as values the assistant can pass
+// back in a transfer_call function call
+var destinationNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// TransferDestination is somewhere the assistant can hand a caller over to,
+// such as the front desk, a manager or an emergency line. Phone is used when
+// no on-call shift covers the current time.
+type TransferDestination struct {
+	ID          string       `json:"id"`
+	BusinessID  string       `json:"business_id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Phone       string       `json:"phone"`
+	Mode        TransferMode `json:"mode"`
+	Active      bool         `json:"active"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+func NewTransferDestination(businessID, name, description, phone string, mode TransferMode) (*TransferDestination, error) {
+	if businessID == "" {
+		return nil, errors.NewValidationError("business_id is required")
+	}
+
+	destination := &TransferDestination{
+		BusinessID: businessID,
+		Active:     true,
+	}
+	if err := destination.Update(name, description, phone, mode, true); err != nil {
+		return nil, err
+	}
+	destination.CreatedAt = destination.UpdatedAt
+	return destination, nil
+}
+
+func (d *TransferDestination) Update(name, description, phone string, mode TransferMode, active bool) error {
+	if !destinationNamePattern.MatchString(name) {
+		return errors.NewFieldValidationError("name", "name must start with a letter and contain only lowercase letters, digits and underscores")
+	}
+	if err := validatePhone("phone_number", phone); err != nil {
+		return err
+	}
+	if mode == "" {
+		mode = TransferModeWarm
+	}
+	if mode != TransferModeWarm && mode != TransferModeCold {
+		return errors.NewFieldValidationError("mode", "mode must be warm or cold")
+	}
+
+	d.Name = name
+	d.Description = description
+	d.Phone = phone
+	d.Mode = mode
+	d.Active = active
+	d.UpdatedAt = time.Now()
+	return nil
+}
+
+// OnCallShift routes a destination's transfers to a person for a period of
+// time, so a rotation is a sequence of shifts
+type OnCallShift struct {
+	ID            string    `json:"id"`
+	BusinessID    string    `json:"business_id"`
+	DestinationID string    `json:"destination_id"`
+	Name          string    `json:"name"`
+	Phone         string    `json:"phone"`
+	StartsAt      time.Time `json:"starts_at"`
+	EndsAt        time.Time `json:"ends_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func NewOnCallShift(destination *TransferDestination, name, phone string, startsAt, endsAt time.Time) (*OnCallShift, error) {
+	if name == "" {
+		return nil, errors.NewFieldValidationError("name", "name is required")
+	}
+	if err := validatePhone("phone_number", phone); err != nil {
+		return nil, err
+	}
+	if startsAt.IsZero() || endsAt.IsZero() {
+		return nil, errors.NewValidationError("starts_at and ends_at are required")
+	}
+	if !endsAt.After(startsAt) {
+		return nil, errors.NewFieldValidationError("ends_at", "ends_at must be after starts_at")
+	}
+
+	return &OnCallShift{
+		BusinessID:    destination.BusinessID,
+		DestinationID: destination.ID,
+		Name:          name,
+		Phone:         phone,
+		StartsAt:      startsAt,
+		EndsAt:        endsAt,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// Covers reports whether the shift is on call at t
+func (s *OnCallShift) Covers(t time.Time) bool {
+	return !t.Before(s.StartsAt) && t.Before(s.EndsAt)
+}
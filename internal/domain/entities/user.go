@@ -15,12 +15,18 @@ const (
 )
 
 type User struct {
-	ID           string    `json:"id"`
-	BusinessID   string    `json:"business_id"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	Role         UserRole  `json:"role"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID              string     `json:"id"`
+	BusinessID      string     `json:"business_id"`
+	Email           string     `json:"email"`
+	PasswordHash    string     `json:"-"`
+	Role            UserRole   `json:"role"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	DeactivatedAt   *time.Time `json:"deactivated_at,omitempty"`
+	// PlatformAdmin lets the user operate the platform across every
+	// business. It is independent of their role in any business and is
+	// only granted in the database.
+	PlatformAdmin bool      `json:"platform_admin,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 func NewUser(businessID, email, passwordHash string, role UserRole) (*User, error) {
@@ -34,13 +40,7 @@ func NewUser(businessID, email, passwordHash string, role UserRole) (*User, erro
 		return nil, errors.NewValidationError("password is required")
 	}
 
-	validRoles := map[UserRole]bool{
-		UserRoleOwner:    true,
-		UserRoleAdmin:    true,
-		UserRoleEmployee: true,
-	}
-
-	if !validRoles[role] {
+	if !role.IsValid() {
 		return nil, errors.NewValidationError("invalid user role")
 	}
 
@@ -53,6 +53,10 @@ func NewUser(businessID, email, passwordHash string, role UserRole) (*User, erro
 	}, nil
 }
 
+func (r UserRole) IsValid() bool {
+	return r == UserRoleOwner || r == UserRoleAdmin || r == UserRoleEmployee
+}
+
 func (u *User) IsOwner() bool {
 	return u.Role == UserRoleOwner
 }
@@ -62,7 +66,54 @@ func (u *User) IsAdmin() bool {
 }
 
 func (u *User) CanManageBusiness() bool {
-	return u.IsAdmin()
+	return u.Role.Can(PermissionBusinessWrite)
+}
+
+// IsActive reports whether the user may still sign in
+func (u *User) IsActive() bool {
+	return u.DeactivatedAt == nil
+}
+
+func (u *User) ChangeRole(role UserRole) error {
+	if !role.IsValid() {
+		return errors.NewFieldValidationError("role", "role must be owner, admin or employee")
+	}
+	u.Role = role
+	return nil
+}
+
+// Deactivate blocks the user from signing in while keeping their history
+func (u *User) Deactivate() error {
+	if !u.IsActive() {
+		return errors.NewValidationError("user is already deactivated")
+	}
+	now := time.Now()
+	u.DeactivatedAt = &now
+	return nil
+}
+
+func (u *User) Reactivate() {
+	u.DeactivatedAt = nil
+}
+
+// IsEmailVerified reports whether the user proved they own their email
+// address. Unverified users have restricted access.
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+func (u *User) VerifyEmail(now time.Time) {
+	if u.EmailVerifiedAt == nil {
+		u.EmailVerifiedAt = &now
+	}
+}
+
+func (u *User) ChangePassword(passwordHash string) error {
+	if passwordHash == "" {
+		return errors.NewValidationError("password is required")
+	}
+	u.PasswordHash = passwordHash
+	return nil
 }
 
 func (u *User) Validate() error {
@@ -0,0 +1,64 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+// UserTokenPurpose is what a single-use user token can be exchanged for
+type UserTokenPurpose string
+
+const (
+	UserTokenPasswordReset     UserTokenPurpose = "password_reset"
+	UserTokenEmailVerification UserTokenPurpose = "email_verification"
+)
+
+func (p UserTokenPurpose) IsValid() bool {
+	return p == UserTokenPasswordReset || p == UserTokenEmailVerification
+}
+
+// UserToken is a single-use, time-limited token emailed to a user to reset
+// their password or verify their email address. Only its hash is stored.
+type UserToken struct {
+	ID        string           `json:"id"`
+	UserID    string           `json:"user_id"`
+	Purpose   UserTokenPurpose `json:"purpose"`
+	TokenHash string           `json:"-"`
+	ExpiresAt time.Time        `json:"expires_at"`
+	UsedAt    *time.Time       `json:"used_at,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+func NewUserToken(userID string, purpose UserTokenPurpose, tokenHash string, expiresAt time.Time) (*UserToken, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user_id is required")
+	}
+	if !purpose.IsValid() {
+		return nil, errors.NewValidationError("invalid token purpose")
+	}
+	if tokenHash == "" {
+		return nil, errors.NewValidationError("token is required")
+	}
+
+	return &UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// IsUsable reports whether the token can still be redeemed
+func (t *UserToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
+
+func (t *UserToken) Use(now time.Time) error {
+	if !t.IsUsable(now) {
+		return errors.NewUnauthorizedError("invalid or expired token")
+	}
+	t.UsedAt = &now
+	return nil
+}
@@ -0,0 +1,48 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type WebhookEventStatus string
+
+const (
+	WebhookEventProcessed WebhookEventStatus = "processed"
+	WebhookEventFailed    WebhookEventStatus = "failed"
+)
+
+// WebhookEvent is a webhook received from the voice provider and what came
+// of processing it, kept so operators can see what the provider sent
+type WebhookEvent struct {
+	ID             string             `json:"id"`
+	EventType      string             `json:"event_type"`
+	ProviderCallID string             `json:"provider_call_id,omitempty"`
+	Status         WebhookEventStatus `json:"status"`
+	Error          string             `json:"error,omitempty"`
+	Payload        json.RawMessage    `json:"payload,omitempty"`
+	ReceivedAt     time.Time          `json:"received_at"`
+}
+
+func NewWebhookEvent(eventType, providerCallID string, payload []byte, processErr error, now time.Time) (*WebhookEvent, error) {
+	if eventType == "" {
+		return nil, errors.NewValidationError("event_type is required")
+	}
+
+	event := &WebhookEvent{
+		EventType:      eventType,
+		ProviderCallID: providerCallID,
+		Status:         WebhookEventProcessed,
+		ReceivedAt:     now,
+	}
+	if json.Valid(payload) {
+		event.Payload = json.RawMessage(payload)
+	}
+	if processErr != nil {
+		event.Status = WebhookEventFailed
+		event.Error = processErr.Error()
+	}
+	return event, nil
+}
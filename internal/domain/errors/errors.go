@@ -5,6 +5,7 @@ import "fmt"
 type DomainError struct {
 	Code    string
 	Message string
+	Field   string
 	Err     error
 }
 
@@ -30,6 +31,7 @@ const (
 	ErrCodeProviderError   = "PROVIDER_ERROR"
 	ErrCodeDatabaseError   = "DATABASE_ERROR"
 	ErrCodeValidationError = "VALIDATION_ERROR"
+	ErrCodeComplianceBlock = "COMPLIANCE_BLOCKED"
 )
 
 // Common domain errors
@@ -61,6 +63,15 @@ func NewValidationError(message string) *DomainError {
 	}
 }
 
+// NewFieldValidationError reports a validation failure tied to a request field
+func NewFieldValidationError(field string, message string) *DomainError {
+	return &DomainError{
+		Code:    ErrCodeValidationError,
+		Message: message,
+		Field:   field,
+	}
+}
+
 func NewUnauthorizedError(message string) *DomainError {
 	return &DomainError{
 		Code:    ErrCodeUnauthorized,
@@ -75,6 +86,14 @@ func NewForbiddenError(message string) *DomainError {
 	}
 }
 
+// NewComplianceError reports an outbound call blocked by calling regulations
+func NewComplianceError(message string) *DomainError {
+	return &DomainError{
+		Code:    ErrCodeComplianceBlock,
+		Message: message,
+	}
+}
+
 func NewInternalError(err error) *DomainError {
 	return &DomainError{
 		Code:    ErrCodeInternal,
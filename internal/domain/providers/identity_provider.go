@@ -0,0 +1,46 @@
+package providers
+
+import "context"
+
+// OIDCProvider is a business's OpenID Connect identity provider and our
+// client registration with it
+type OIDCProvider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+}
+
+// OIDCAuthRequest starts a sign-in at the identity provider. CodeChallenge is
+// the S256 PKCE challenge of a verifier only the backend knows.
+type OIDCAuthRequest struct {
+	RedirectURI   string
+	State         string
+	Nonce         string
+	CodeChallenge string
+}
+
+// OIDCTokenRequest redeems the code the identity provider redirected back
+// with
+type OIDCTokenRequest struct {
+	Code         string
+	CodeVerifier string
+	RedirectURI  string
+	Nonce        string
+}
+
+// OIDCIdentity is the user a verified ID token vouches for
+type OIDCIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// OIDCClient runs the OpenID Connect authorization code flow with PKCE
+type OIDCClient interface {
+	// AuthorizationURL returns where to send the user's browser to sign in
+	AuthorizationURL(ctx context.Context, provider OIDCProvider, req OIDCAuthRequest) (string, error)
+	// Exchange redeems an authorization code and verifies the ID token
+	Exchange(ctx context.Context, provider OIDCProvider, req OIDCTokenRequest) (*OIDCIdentity, error)
+}
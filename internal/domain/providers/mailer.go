@@ -0,0 +1,15 @@
+package providers
+
+import "context"
+
+// Email is a plain-text email to one recipient
+type Email struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Mailer delivers transactional emails such as password resets
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
+}
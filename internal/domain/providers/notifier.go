@@ -0,0 +1,17 @@
+package providers
+
+import "context"
+
+// Notification is a message delivered to one person through one channel
+type Notification struct {
+	Channel  string                 `json:"channel"` // email, sms or webhook
+	Target   string                 `json:"target"`  // address, number or URL for the channel
+	Subject  string                 `json:"subject"`
+	Body     string                 `json:"body"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Notifier delivers notifications to staff
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
@@ -5,7 +5,8 @@ import (
 	"time"
 )
 
-// CallRequest represents a request to initiate a call
+// CallRequest represents a request to initiate a call. AssistantConfig
+// overrides the saved assistant for this call.
 type CallRequest struct {
 	PhoneNumber      string                 `json:"phone_number"`
 	AssistantID      string                 `json:"assistant_id,omitempty"`
@@ -22,13 +23,80 @@ type CallSession struct {
 	Metadata    map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// Call event types shared across providers
+const (
+	// CallEventTypeAssistantRequest is sent before an inbound call is answered,
+	// asking the backend which assistant should take the call
+	CallEventTypeAssistantRequest = "assistant-request"
+
+	// CallEventTypeEndOfCallReport is sent once a call has finished, with the
+	// reason it ended
+	CallEventTypeEndOfCallReport = "end-of-call-report"
+
+	// CallEventTypeToolCalls is sent when the assistant invokes one of its
+	// functions during a call
+	CallEventTypeToolCalls = "tool-calls"
+)
+
+// Outcomes of a finished call, normalised from provider-specific end reasons
+const (
+	CallOutcomeCompleted = "completed"
+	CallOutcomeNoAnswer  = "no_answer"
+	CallOutcomeBusy      = "busy"
+	CallOutcomeFailed    = "failed"
+)
+
 // CallEvent represents a webhook event from the provider
 type CallEvent struct {
-	Type        string                 `json:"type"`
-	CallID      string                 `json:"call_id"`
-	Status      string                 `json:"status"`
-	Timestamp   time.Time              `json:"timestamp"`
-	Data        map[string]interface{} `json:"data,omitempty"`
+	Type          string                 `json:"type"`
+	CallID        string                 `json:"call_id"`
+	Status        string                 `json:"status"`
+	CustomerPhone string                 `json:"customer_phone,omitempty"`
+	BusinessPhone string                 `json:"business_phone,omitempty"`
+	Outcome       string                 `json:"outcome,omitempty"` // set when the call has ended
+	ToolCalls     []ToolCall             `json:"tool_calls,omitempty"`
+	// Analysis holds structured data the provider extracted from a finished
+	// call, when the assistant is configured to produce it
+	Analysis      map[string]interface{} `json:"analysis,omitempty"`
+	Timestamp     time.Time              `json:"timestamp"`
+	Data          map[string]interface{} `json:"data,omitempty"`
+}
+
+// ToolCall is a function invocation requested by the assistant mid-call
+type ToolCall struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// ToolCallResult is the backend's answer to a ToolCall. Result is read back
+// to the assistant; Error is set instead when the function failed.
+type ToolCallResult struct {
+	ToolCallID string `json:"tool_call_id"`
+	Name       string `json:"name"`
+	Result     string `json:"result,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// TransferMode controls whether the human is briefed before the caller is
+// connected
+type TransferMode string
+
+const (
+	// TransferModeWarm plays a summary to the person answering before
+	// connecting the caller
+	TransferModeWarm TransferMode = "warm"
+	// TransferModeCold connects the caller straight away
+	TransferModeCold TransferMode = "cold"
+)
+
+// TransferRequest asks the provider to hand a live call over to a phone number
+type TransferRequest struct {
+	CallID      string       `json:"call_id"` // provider call ID
+	PhoneNumber string       `json:"phone_number"`
+	Mode        TransferMode `json:"mode"`
+	Message     string       `json:"message,omitempty"` // said to the caller before the transfer
+	Summary     string       `json:"summary,omitempty"` // said to the person answering a warm transfer
 }
 
 // CallDetails contains detailed information about a call
@@ -42,6 +110,9 @@ type CallDetails struct {
 	EndedAt      *time.Time             `json:"ended_at,omitempty"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
 	ErrorMessage string                 `json:"error_message,omitempty"`
+	// Outcome is one of the CallOutcome values once the call has ended, and
+	// empty while it is still going
+	Outcome string `json:"outcome,omitempty"`
 }
 
 // Transcript contains the conversation transcript
@@ -67,6 +138,12 @@ type AssistantConfig struct {
 	Model            string                 `json:"model,omitempty"`
 	Functions        []Function             `json:"functions,omitempty"`
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	// VariableValues are substituted into {{placeholders}} in the prompt and
+	// first message when the call starts
+	VariableValues   map[string]interface{} `json:"variable_values,omitempty"`
+	// AnalysisSchema is the JSON schema of the structured data extracted
+	// from each finished call and reported as the call event's Analysis
+	AnalysisSchema   map[string]interface{} `json:"analysis_schema,omitempty"`
 }
 
 // Function represents a callable function for the assistant
@@ -76,6 +153,13 @@ type Function struct {
 	Parameters  map[string]interface{} `json:"parameters"`
 }
 
+// AssistantSelection describes which assistant should answer an inbound call.
+// When AssistantID is set, AssistantConfig is applied as per-call overrides.
+type AssistantSelection struct {
+	AssistantID     string           `json:"assistant_id,omitempty"`
+	AssistantConfig *AssistantConfig `json:"assistant_config,omitempty"`
+}
+
 // VoiceProvider defines the interface for voice AI providers
 // This abstraction allows easy switching between providers (Vapi, Twilio, etc.)
 type VoiceProvider interface {
@@ -103,3 +187,36 @@ type VoiceProvider interface {
 	// ValidateWebhookSignature verifies the authenticity of webhook requests
 	ValidateWebhookSignature(payload []byte, signature string) bool
 }
+
+// AssistantRequestResponder is implemented by providers that let the backend
+// choose the assistant for inbound calls. The returned value is written back
+// as the webhook response body.
+type AssistantRequestResponder interface {
+	BuildAssistantResponse(selection AssistantSelection) interface{}
+}
+
+// ToolCallResponder is implemented by providers that take function results
+// in the webhook reply. The returned value is written back as the response body.
+type ToolCallResponder interface {
+	BuildToolCallResponse(results []ToolCallResult) interface{}
+}
+
+// CallTransferrer is implemented by providers that can transfer a live call
+// to a phone number
+type CallTransferrer interface {
+	TransferCall(ctx context.Context, req TransferRequest) error
+}
+
+// PhoneNumberRequest puts a business's own number on the provider so its
+// inbound calls are answered by an assistant
+type PhoneNumberRequest struct {
+	PhoneNumber string `json:"phone_number"` // E.164
+	Name        string `json:"name,omitempty"`
+	AssistantID string `json:"assistant_id"`
+}
+
+// PhoneNumberAttacher is implemented by providers that can answer a phone
+// number. AttachPhoneNumber returns the provider's ID for the number.
+type PhoneNumberAttacher interface {
+	AttachPhoneNumber(ctx context.Context, req PhoneNumberRequest) (string, error)
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type APIKeyRepositoryImpl struct {
+	db *DB
+}
+
+func NewAPIKeyRepository(db *DB) APIKeyRepository {
+	return &APIKeyRepositoryImpl{db: db}
+}
+
+const apiKeyColumns = `id, business_id, name, prefix, key_hash, scopes, created_by, expires_at, last_used_at, revoked_at, created_at`
+
+func (r *APIKeyRepositoryImpl) Create(ctx context.Context, key *entities.APIKey) error {
+	key.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO api_keys (` + apiKeyColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		key.ID,
+		key.BusinessID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		pq.Array(scopeStrings(key.Scopes)),
+		key.CreatedBy,
+		key.ExpiresAt,
+		key.LastUsedAt,
+		key.RevokedAt,
+		key.CreatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create API key")
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE id = $1`
+
+	key, err := r.scanAPIKey(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("API key", id)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get API key")
+	}
+
+	return key, nil
+}
+
+// GetByHash returns the API key with the given hash, or nil if there is none
+func (r *APIKeyRepositoryImpl) GetByHash(ctx context.Context, keyHash string) (*entities.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = $1`
+
+	key, err := r.scanAPIKey(r.db.QueryRowContext(ctx, query, keyHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get API key by hash")
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepositoryImpl) GetByBusinessID(ctx context.Context, businessID string) ([]*entities.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE business_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get API keys")
+	}
+	defer rows.Close()
+
+	var keys []*entities.APIKey
+	for rows.Next() {
+		key, err := r.scanAPIKey(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan API key")
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate API keys")
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyRepositoryImpl) Update(ctx context.Context, key *entities.APIKey) error {
+	query := `
+		UPDATE api_keys
+		SET name = $2, revoked_at = $3
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, key.ID, key.Name, key.RevokedAt)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to update API key")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("API key", key.ID)
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepositoryImpl) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id, usedAt); err != nil {
+		return errors.NewDatabaseError(err, "failed to record API key use")
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepositoryImpl) scanAPIKey(row rowScanner) (*entities.APIKey, error) {
+	key := &entities.APIKey{}
+	var scopes []string
+	var createdBy sql.NullString
+
+	err := row.Scan(
+		&key.ID,
+		&key.BusinessID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		pq.Array(&scopes),
+		&createdBy,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	key.CreatedBy = createdBy.String
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, entities.Permission(scope))
+	}
+
+	return key, nil
+}
+
+func scopeStrings(scopes []entities.Permission) []string {
+	result := make([]string, len(scopes))
+	for i, scope := range scopes {
+		result[i] = string(scope)
+	}
+	return result
+}
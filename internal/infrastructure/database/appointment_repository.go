@@ -156,6 +156,25 @@ func (r *AppointmentRepositoryImpl) GetPendingAppointments(ctx context.Context,
 	return r.scanAppointments(rows)
 }
 
+func (r *AppointmentRepositoryImpl) GetByCustomerPhone(ctx context.Context, businessID, customerPhone string, limit int) ([]*entities.AppointmentRequest, error) {
+	query := `
+		SELECT id, call_id, business_id, customer_name, customer_phone, 
+			requested_date, requested_time, service_type, notes, status, extracted_at, confirmed_at, created_at
+		FROM appointments
+		WHERE business_id = $1 AND customer_phone = $2
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, customerPhone, limit)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get appointments by customer phone")
+	}
+	defer rows.Close()
+
+	return r.scanAppointments(rows)
+}
+
 func (r *AppointmentRepositoryImpl) Update(ctx context.Context, appointment *entities.AppointmentRequest) error {
 	query := `
 		UPDATE appointments
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type AssistantTemplateRepositoryImpl struct {
+	db *DB
+}
+
+func NewAssistantTemplateRepository(db *DB) AssistantTemplateRepository {
+	return &AssistantTemplateRepositoryImpl{db: db}
+}
+
+// GetOverride returns the business's changes to its template, or nil if it
+// has none
+func (r *AssistantTemplateRepositoryImpl) GetOverride(ctx context.Context, businessID string) (*entities.AssistantTemplateOverride, error) {
+	query := `
+		SELECT business_id, template, prompt, first_message, functions, interaction_fields, services,
+			created_at, updated_at
+		FROM assistant_template_overrides
+		WHERE business_id = $1
+	`
+
+	override := &entities.AssistantTemplateOverride{}
+	var functionsJSON, fieldsJSON, servicesJSON []byte
+	err := r.db.QueryRowContext(ctx, query, businessID).Scan(
+		&override.BusinessID,
+		&override.Template,
+		&override.Prompt,
+		&override.FirstMessage,
+		&functionsJSON,
+		&fieldsJSON,
+		&servicesJSON,
+		&override.CreatedAt,
+		&override.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get assistant template override")
+	}
+
+	// NULL columns leave the lists nil, keeping the template's
+	if functionsJSON != nil {
+		if err := json.Unmarshal(functionsJSON, &override.Functions); err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to unmarshal template functions")
+		}
+	}
+	if fieldsJSON != nil {
+		if err := json.Unmarshal(fieldsJSON, &override.InteractionFields); err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to unmarshal template interaction fields")
+		}
+	}
+	if servicesJSON != nil {
+		if err := json.Unmarshal(servicesJSON, &override.Services); err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to unmarshal template services")
+		}
+	}
+
+	return override, nil
+}
+
+// SaveOverride creates or replaces the business's changes to its template
+func (r *AssistantTemplateRepositoryImpl) SaveOverride(ctx context.Context, override *entities.AssistantTemplateOverride) error {
+	// Nil lists are stored as NULL
+	functionsJSON, err := json.Marshal(override.Functions)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to marshal template functions")
+	}
+	fieldsJSON, err := json.Marshal(override.InteractionFields)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to marshal template interaction fields")
+	}
+	servicesJSON, err := json.Marshal(override.Services)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to marshal template services")
+	}
+
+	query := `
+		INSERT INTO assistant_template_overrides (business_id, template, prompt, first_message,
+			functions, interaction_fields, services, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (business_id) DO UPDATE SET
+			template = EXCLUDED.template,
+			prompt = EXCLUDED.prompt,
+			first_message = EXCLUDED.first_message,
+			functions = EXCLUDED.functions,
+			interaction_fields = EXCLUDED.interaction_fields,
+			services = EXCLUDED.services,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		override.BusinessID,
+		override.Template,
+		override.Prompt,
+		override.FirstMessage,
+		nullableJSON(functionsJSON),
+		nullableJSON(fieldsJSON),
+		nullableJSON(servicesJSON),
+		override.CreatedAt,
+		override.UpdatedAt,
+	)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to save assistant template override")
+	}
+
+	return nil
+}
+
+// DeleteOverride removes the business's changes, returning it to the
+// template for its type
+func (r *AssistantTemplateRepositoryImpl) DeleteOverride(ctx context.Context, businessID string) error {
+	query := `DELETE FROM assistant_template_overrides WHERE business_id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, businessID); err != nil {
+		return errors.NewDatabaseError(err, "failed to delete assistant template override")
+	}
+
+	return nil
+}
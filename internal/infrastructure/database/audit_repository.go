@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+const auditColumns = `id, business_id, actor_type, actor_id, action, target_type, target_id, changes, metadata, ip, user_agent, created_at`
+
+type AuditRepositoryImpl struct {
+	db *DB
+}
+
+func NewAuditRepository(db *DB) AuditRepository {
+	return &AuditRepositoryImpl{db: db}
+}
+
+// Create appends the entry. Inside a tenant transaction it commits on its
+// own, like OnboardingRepository.RecordProviderID, so what a request did is
+// still recorded when the request then fails and its transaction rolls
+// back.
+func (r *AuditRepositoryImpl) Create(ctx context.Context, entry *entities.AuditEntry) error {
+	if tenantTx(ctx) != nil && entry.BusinessID != "" {
+		return r.db.Detached(ctx, entry.BusinessID, func(ctx context.Context) error {
+			return r.create(ctx, entry)
+		})
+	}
+	return r.create(ctx, entry)
+}
+
+func (r *AuditRepositoryImpl) create(ctx context.Context, entry *entities.AuditEntry) error {
+	entry.ID = uuid.New().String()
+
+	changesJSON, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to marshal audit changes")
+	}
+	metadataJSON, err := json.Marshal(entry.Metadata)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to marshal audit metadata")
+	}
+
+	query := `
+		INSERT INTO audit_log (` + auditColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8::jsonb, '{}'::jsonb), COALESCE($9::jsonb, '{}'::jsonb), $10, $11, $12)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		entry.ID,
+		nullableString(entry.BusinessID),
+		entry.ActorType,
+		nullableString(entry.ActorID),
+		entry.Action,
+		nullableString(entry.TargetType),
+		nullableString(entry.TargetID),
+		nullableJSON(changesJSON),
+		nullableJSON(metadataJSON),
+		nullableString(entry.IP),
+		nullableString(entry.UserAgent),
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create audit entry")
+	}
+
+	return nil
+}
+
+// List returns a page of the business's audit entries, or of every entry
+// when businessID is empty, newest first
+func (r *AuditRepositoryImpl) List(ctx context.Context, businessID string, filter AuditFilter, limit, offset int) ([]*entities.AuditEntry, error) {
+	query := `
+		SELECT ` + auditColumns + `
+		FROM audit_log
+		WHERE ($1 = '' OR business_id::text = $1)
+			AND ($2 = '' OR actor_id = $2)
+			AND ($3 = '' OR action = $3)
+			AND ($4 = '' OR target_type = $4)
+			AND ($5 = '' OR target_id = $5)
+			AND ($6::timestamp IS NULL OR created_at >= $6)
+			AND ($7::timestamp IS NULL OR created_at < $7)
+		ORDER BY created_at DESC
+		LIMIT $8 OFFSET $9
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, filter.ActorID, filter.Action, filter.TargetType, filter.TargetID,
+		filter.From, filter.To, limit, offset)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to list audit entries")
+	}
+	defer rows.Close()
+
+	var entries []*entities.AuditEntry
+	for rows.Next() {
+		entry, err := r.scanAuditEntry(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan audit entry")
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate audit entries")
+	}
+
+	return entries, nil
+}
+
+// DeleteBefore removes the entries older than before and returns how many
+// were removed
+func (r *AuditRepositoryImpl) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM audit_log WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, errors.NewDatabaseError(err, "failed to delete audit entries")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	return int(deleted), nil
+}
+
+func (r *AuditRepositoryImpl) scanAuditEntry(row rowScanner) (*entities.AuditEntry, error) {
+	entry := &entities.AuditEntry{}
+	var businessID, actorID, targetType, targetID, ip, userAgent sql.NullString
+	var changesJSON, metadataJSON []byte
+
+	err := row.Scan(
+		&entry.ID,
+		&businessID,
+		&entry.ActorType,
+		&actorID,
+		&entry.Action,
+		&targetType,
+		&targetID,
+		&changesJSON,
+		&metadataJSON,
+		&ip,
+		&userAgent,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.BusinessID = businessID.String
+	entry.ActorID = actorID.String
+	entry.TargetType = targetType.String
+	entry.TargetID = targetID.String
+	entry.IP = ip.String
+	entry.UserAgent = userAgent.String
+
+	if err := json.Unmarshal(changesJSON, &entry.Changes); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(metadataJSON, &entry.Metadata); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// nullableString stores an empty string as NULL
+func nullableString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// nullableJSON stores a marshalled nil map or slice as NULL
+func nullableJSON(data []byte) interface{} {
+	if string(data) == "null" {
+		return nil
+	}
+	return string(data)
+}
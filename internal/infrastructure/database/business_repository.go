@@ -10,6 +10,8 @@ import (
 	"github.com/CallPilotReceptionist/internal/domain/errors"
 )
 
+const businessColumns = `id, name, type, parent_id, phone, settings, suspended_at, suspension_reason, created_at, updated_at`
+
 type BusinessRepositoryImpl struct {
 	db *DB
 }
@@ -27,14 +29,15 @@ func (r *BusinessRepositoryImpl) Create(ctx context.Context, business *entities.
 	}
 
 	query := `
-		INSERT INTO businesses (id, name, type, phone, settings, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO businesses (id, name, type, parent_id, phone, settings, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	_, err = r.db.ExecContext(ctx, query,
 		business.ID,
 		business.Name,
 		business.Type,
+		business.ParentID,
 		business.Phone,
 		settingsJSON,
 		business.CreatedAt,
@@ -49,25 +52,9 @@ func (r *BusinessRepositoryImpl) Create(ctx context.Context, business *entities.
 }
 
 func (r *BusinessRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Business, error) {
-	query := `
-		SELECT id, name, type, phone, settings, created_at, updated_at
-		FROM businesses
-		WHERE id = $1
-	`
-
-	business := &entities.Business{}
-	var settingsJSON []byte
-
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&business.ID,
-		&business.Name,
-		&business.Type,
-		&business.Phone,
-		&settingsJSON,
-		&business.CreatedAt,
-		&business.UpdatedAt,
-	)
+	query := `SELECT ` + businessColumns + ` FROM businesses WHERE id = $1`
 
+	business, err := r.scanBusiness(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, errors.NewNotFoundError("business", id)
 	}
@@ -75,33 +62,13 @@ func (r *BusinessRepositoryImpl) GetByID(ctx context.Context, id string) (*entit
 		return nil, errors.NewDatabaseError(err, "failed to get business")
 	}
 
-	if err := json.Unmarshal(settingsJSON, &business.Settings); err != nil {
-		return nil, errors.NewDatabaseError(err, "failed to unmarshal settings")
-	}
-
 	return business, nil
 }
 
 func (r *BusinessRepositoryImpl) GetByPhone(ctx context.Context, phone string) (*entities.Business, error) {
-	query := `
-		SELECT id, name, type, phone, settings, created_at, updated_at
-		FROM businesses
-		WHERE phone = $1
-	`
-
-	business := &entities.Business{}
-	var settingsJSON []byte
-
-	err := r.db.QueryRowContext(ctx, query, phone).Scan(
-		&business.ID,
-		&business.Name,
-		&business.Type,
-		&business.Phone,
-		&settingsJSON,
-		&business.CreatedAt,
-		&business.UpdatedAt,
-	)
+	query := `SELECT ` + businessColumns + ` FROM businesses WHERE phone = $1`
 
+	business, err := r.scanBusiness(r.db.QueryRowContext(ctx, query, phone))
 	if err == sql.ErrNoRows {
 		return nil, errors.NewNotFoundError("business", phone)
 	}
@@ -109,10 +76,6 @@ func (r *BusinessRepositoryImpl) GetByPhone(ctx context.Context, phone string) (
 		return nil, errors.NewDatabaseError(err, "failed to get business")
 	}
 
-	if err := json.Unmarshal(settingsJSON, &business.Settings); err != nil {
-		return nil, errors.NewDatabaseError(err, "failed to unmarshal settings")
-	}
-
 	return business, nil
 }
 
@@ -124,7 +87,7 @@ func (r *BusinessRepositoryImpl) Update(ctx context.Context, business *entities.
 
 	query := `
 		UPDATE businesses
-		SET name = $2, type = $3, phone = $4, settings = $5, updated_at = $6
+		SET name = $2, type = $3, phone = $4, settings = $5, suspended_at = $6, suspension_reason = $7, updated_at = $8
 		WHERE id = $1
 	`
 
@@ -134,6 +97,8 @@ func (r *BusinessRepositoryImpl) Update(ctx context.Context, business *entities.
 		business.Type,
 		business.Phone,
 		settingsJSON,
+		business.SuspendedAt,
+		nullableString(business.SuspensionReason),
 		business.UpdatedAt,
 	)
 
@@ -173,15 +138,18 @@ func (r *BusinessRepositoryImpl) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (r *BusinessRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*entities.Business, error) {
+func (r *BusinessRepositoryImpl) List(ctx context.Context, filter BusinessFilter, limit, offset int) ([]*entities.Business, error) {
 	query := `
-		SELECT id, name, type, phone, settings, created_at, updated_at
+		SELECT ` + businessColumns + `
 		FROM businesses
+		WHERE ($1 = '' OR parent_id::text = $1)
+			AND ($2 = '' OR name ILIKE '%' || $2 || '%' OR phone LIKE '%' || $2 || '%' OR id::text = $2)
+			AND ($3::boolean IS NULL OR (suspended_at IS NOT NULL) = $3)
 		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
+		LIMIT $4 OFFSET $5
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, filter.ParentID, filter.Search, filter.Suspended, limit, offset)
 	if err != nil {
 		return nil, errors.NewDatabaseError(err, "failed to list businesses")
 	}
@@ -190,27 +158,11 @@ func (r *BusinessRepositoryImpl) List(ctx context.Context, limit, offset int) ([
 	var businesses []*entities.Business
 
 	for rows.Next() {
-		business := &entities.Business{}
-		var settingsJSON []byte
-
-		err := rows.Scan(
-			&business.ID,
-			&business.Name,
-			&business.Type,
-			&business.Phone,
-			&settingsJSON,
-			&business.CreatedAt,
-			&business.UpdatedAt,
-		)
-
+		business, err := r.scanBusiness(rows)
 		if err != nil {
 			return nil, errors.NewDatabaseError(err, "failed to scan business")
 		}
 
-		if err := json.Unmarshal(settingsJSON, &business.Settings); err != nil {
-			return nil, errors.NewDatabaseError(err, "failed to unmarshal settings")
-		}
-
 		businesses = append(businesses, business)
 	}
 
@@ -220,3 +172,38 @@ func (r *BusinessRepositoryImpl) List(ctx context.Context, limit, offset int) ([
 
 	return businesses, nil
 }
+
+func (r *BusinessRepositoryImpl) scanBusiness(row rowScanner) (*entities.Business, error) {
+	business := &entities.Business{}
+	var parentID, suspensionReason sql.NullString
+	var settingsJSON []byte
+
+	err := row.Scan(
+		&business.ID,
+		&business.Name,
+		&business.Type,
+		&parentID,
+		&business.Phone,
+		&settingsJSON,
+		&business.SuspendedAt,
+		&suspensionReason,
+		&business.CreatedAt,
+		&business.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		business.ParentID = &parentID.String
+	}
+	business.SuspensionReason = suspensionReason.String
+
+	// Settings stored by an older version are upgraded as they are read
+	settings, err := entities.ParseBusinessSettings(settingsJSON)
+	if err != nil {
+		return nil, err
+	}
+	business.Settings = settings
+
+	return business, nil
+}
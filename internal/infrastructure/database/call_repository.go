@@ -22,8 +22,8 @@ func (r *CallRepositoryImpl) Create(ctx context.Context, call *entities.Call) er
 	call.ID = uuid.New().String()
 
 	query := `
-		INSERT INTO calls (id, business_id, provider_call_id, caller_phone, duration, status, cost, started_at, ended_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO calls (id, business_id, provider_call_id, caller_phone, direction, duration, status, cost, started_at, ended_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -31,6 +31,7 @@ func (r *CallRepositoryImpl) Create(ctx context.Context, call *entities.Call) er
 		call.BusinessID,
 		call.ProviderCallID,
 		call.CallerPhone,
+		call.Direction,
 		call.Duration,
 		call.Status,
 		call.Cost,
@@ -48,7 +49,7 @@ func (r *CallRepositoryImpl) Create(ctx context.Context, call *entities.Call) er
 
 func (r *CallRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Call, error) {
 	query := `
-		SELECT id, business_id, provider_call_id, caller_phone, duration, status, cost, started_at, ended_at, created_at
+		SELECT id, business_id, provider_call_id, caller_phone, direction, duration, status, cost, started_at, ended_at, created_at
 		FROM calls
 		WHERE id = $1
 	`
@@ -59,6 +60,7 @@ func (r *CallRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.
 		&call.BusinessID,
 		&call.ProviderCallID,
 		&call.CallerPhone,
+		&call.Direction,
 		&call.Duration,
 		&call.Status,
 		&call.Cost,
@@ -79,7 +81,7 @@ func (r *CallRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.
 
 func (r *CallRepositoryImpl) GetByProviderCallID(ctx context.Context, providerCallID string) (*entities.Call, error) {
 	query := `
-		SELECT id, business_id, provider_call_id, caller_phone, duration, status, cost, started_at, ended_at, created_at
+		SELECT id, business_id, provider_call_id, caller_phone, direction, duration, status, cost, started_at, ended_at, created_at
 		FROM calls
 		WHERE provider_call_id = $1
 	`
@@ -90,6 +92,7 @@ func (r *CallRepositoryImpl) GetByProviderCallID(ctx context.Context, providerCa
 		&call.BusinessID,
 		&call.ProviderCallID,
 		&call.CallerPhone,
+		&call.Direction,
 		&call.Duration,
 		&call.Status,
 		&call.Cost,
@@ -110,7 +113,7 @@ func (r *CallRepositoryImpl) GetByProviderCallID(ctx context.Context, providerCa
 
 func (r *CallRepositoryImpl) GetByBusinessID(ctx context.Context, businessID string, limit, offset int) ([]*entities.Call, error) {
 	query := `
-		SELECT id, business_id, provider_call_id, caller_phone, duration, status, cost, started_at, ended_at, created_at
+		SELECT id, business_id, provider_call_id, caller_phone, direction, duration, status, cost, started_at, ended_at, created_at
 		FROM calls
 		WHERE business_id = $1
 		ORDER BY created_at DESC
@@ -182,7 +185,7 @@ func (r *CallRepositoryImpl) Delete(ctx context.Context, id string) error {
 
 func (r *CallRepositoryImpl) GetByDateRange(ctx context.Context, businessID string, startDate, endDate time.Time) ([]*entities.Call, error) {
 	query := `
-		SELECT id, business_id, provider_call_id, caller_phone, duration, status, cost, started_at, ended_at, created_at
+		SELECT id, business_id, provider_call_id, caller_phone, direction, duration, status, cost, started_at, ended_at, created_at
 		FROM calls
 		WHERE business_id = $1 AND created_at BETWEEN $2 AND $3
 		ORDER BY created_at DESC
@@ -227,6 +230,78 @@ func (r *CallRepositoryImpl) GetStats(ctx context.Context, businessID string, st
 	return stats, nil
 }
 
+// GetUsageByBusiness returns the call stats of every business that had calls
+// in the period, busiest first
+func (r *CallRepositoryImpl) GetUsageByBusiness(ctx context.Context, startDate, endDate time.Time) ([]*BusinessUsage, error) {
+	query := `
+		SELECT
+			c.business_id,
+			b.name,
+			COUNT(*) as total_calls,
+			COUNT(CASE WHEN c.status = 'completed' THEN 1 END) as completed_calls,
+			COUNT(CASE WHEN c.status = 'failed' THEN 1 END) as failed_calls,
+			COALESCE(SUM(c.duration), 0) as total_duration,
+			COALESCE(AVG(c.duration), 0) as average_duration,
+			COALESCE(SUM(c.cost), 0) as total_cost
+		FROM calls c
+		JOIN businesses b ON b.id = c.business_id
+		WHERE c.created_at BETWEEN $1 AND $2
+		GROUP BY c.business_id, b.name
+		ORDER BY total_calls DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get usage")
+	}
+	defer rows.Close()
+
+	var usage []*BusinessUsage
+	for rows.Next() {
+		u := &BusinessUsage{}
+		err := rows.Scan(
+			&u.BusinessID,
+			&u.BusinessName,
+			&u.TotalCalls,
+			&u.CompletedCalls,
+			&u.FailedCalls,
+			&u.TotalDuration,
+			&u.AverageDuration,
+			&u.TotalCost,
+		)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan usage")
+		}
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate usage")
+	}
+
+	return usage, nil
+}
+
+// GetUnfinished returns calls of any business created before the cutoff
+// that have not ended, oldest first
+func (r *CallRepositoryImpl) GetUnfinished(ctx context.Context, createdBefore time.Time, limit int) ([]*entities.Call, error) {
+	query := `
+		SELECT id, business_id, provider_call_id, caller_phone, direction, duration, status, cost, started_at, ended_at, created_at
+		FROM calls
+		WHERE status IN ('initiated', 'ringing', 'in_progress') AND created_at < $1
+		ORDER BY created_at ASC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, createdBefore, limit)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get unfinished calls")
+	}
+	defer rows.Close()
+
+	return r.scanCalls(rows)
+}
+
 func (r *CallRepositoryImpl) scanCalls(rows *sql.Rows) ([]*entities.Call, error) {
 	var calls []*entities.Call
 
@@ -237,6 +312,7 @@ func (r *CallRepositoryImpl) scanCalls(rows *sql.Rows) ([]*entities.Call, error)
 			&call.BusinessID,
 			&call.ProviderCallID,
 			&call.CallerPhone,
+			&call.Direction,
 			&call.Duration,
 			&call.Status,
 			&call.Cost,
@@ -0,0 +1,481 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+type CampaignRepositoryImpl struct {
+	db *DB
+}
+
+func NewCampaignRepository(db *DB) CampaignRepository {
+	return &CampaignRepositoryImpl{db: db}
+}
+
+const campaignColumns = `id, business_id, name, assistant_id, status, max_concurrent, calls_per_minute,
+			retry_policy, starts_at, ends_at, started_at, completed_at, created_at, updated_at`
+
+func (r *CampaignRepositoryImpl) Create(ctx context.Context, campaign *entities.Campaign) error {
+	campaign.ID = uuid.New().String()
+
+	policyJSON, err := json.Marshal(campaign.RetryPolicy)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to marshal retry policy")
+	}
+
+	query := `
+		INSERT INTO campaigns (` + campaignColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		campaign.ID,
+		campaign.BusinessID,
+		campaign.Name,
+		campaign.AssistantID,
+		campaign.Status,
+		campaign.MaxConcurrent,
+		campaign.CallsPerMinute,
+		policyJSON,
+		campaign.StartsAt,
+		campaign.EndsAt,
+		campaign.StartedAt,
+		campaign.CompletedAt,
+		campaign.CreatedAt,
+		campaign.UpdatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create campaign")
+	}
+
+	return nil
+}
+
+func (r *CampaignRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Campaign, error) {
+	query := `SELECT ` + campaignColumns + ` FROM campaigns WHERE id = $1`
+
+	campaign, err := r.scanCampaign(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("campaign", id)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get campaign")
+	}
+
+	return campaign, nil
+}
+
+func (r *CampaignRepositoryImpl) GetByBusinessID(ctx context.Context, businessID string, limit, offset int) ([]*entities.Campaign, error) {
+	query := `
+		SELECT ` + campaignColumns + `
+		FROM campaigns
+		WHERE business_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, limit, offset)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get campaigns by business")
+	}
+	defer rows.Close()
+
+	return r.scanCampaigns(rows)
+}
+
+func (r *CampaignRepositoryImpl) GetByStatus(ctx context.Context, status entities.CampaignStatus) ([]*entities.Campaign, error) {
+	query := `
+		SELECT ` + campaignColumns + `
+		FROM campaigns
+		WHERE status = $1
+		ORDER BY starts_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get campaigns by status")
+	}
+	defer rows.Close()
+
+	return r.scanCampaigns(rows)
+}
+
+func (r *CampaignRepositoryImpl) Update(ctx context.Context, campaign *entities.Campaign) error {
+	policyJSON, err := json.Marshal(campaign.RetryPolicy)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to marshal retry policy")
+	}
+
+	query := `
+		UPDATE campaigns
+		SET name = $2, assistant_id = $3, status = $4, max_concurrent = $5, calls_per_minute = $6,
+			retry_policy = $7, starts_at = $8, ends_at = $9, started_at = $10, completed_at = $11, updated_at = $12
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		campaign.ID,
+		campaign.Name,
+		campaign.AssistantID,
+		campaign.Status,
+		campaign.MaxConcurrent,
+		campaign.CallsPerMinute,
+		policyJSON,
+		campaign.StartsAt,
+		campaign.EndsAt,
+		campaign.StartedAt,
+		campaign.CompletedAt,
+		campaign.UpdatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to update campaign")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("campaign", campaign.ID)
+	}
+
+	return nil
+}
+
+func (r *CampaignRepositoryImpl) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM campaigns WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to delete campaign")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("campaign", id)
+	}
+
+	return nil
+}
+
+func (r *CampaignRepositoryImpl) scanCampaign(row rowScanner) (*entities.Campaign, error) {
+	campaign := &entities.Campaign{}
+	var policyJSON []byte
+
+	err := row.Scan(
+		&campaign.ID,
+		&campaign.BusinessID,
+		&campaign.Name,
+		&campaign.AssistantID,
+		&campaign.Status,
+		&campaign.MaxConcurrent,
+		&campaign.CallsPerMinute,
+		&policyJSON,
+		&campaign.StartsAt,
+		&campaign.EndsAt,
+		&campaign.StartedAt,
+		&campaign.CompletedAt,
+		&campaign.CreatedAt,
+		&campaign.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(policyJSON, &campaign.RetryPolicy); err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+func (r *CampaignRepositoryImpl) scanCampaigns(rows *sql.Rows) ([]*entities.Campaign, error) {
+	var campaigns []*entities.Campaign
+
+	for rows.Next() {
+		campaign, err := r.scanCampaign(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan campaign")
+		}
+		campaigns = append(campaigns, campaign)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate campaigns")
+	}
+
+	return campaigns, nil
+}
+
+type CampaignContactRepositoryImpl struct {
+	db *DB
+}
+
+func NewCampaignContactRepository(db *DB) CampaignContactRepository {
+	return &CampaignContactRepositoryImpl{db: db}
+}
+
+const campaignContactColumns = `id, campaign_id, phone, name, variables, status, attempts, call_ids,
+			last_outcome, last_error, next_attempt_at, created_at, updated_at`
+
+// CreateBatch inserts the contacts, skipping numbers already in the campaign,
+// and returns how many were added
+func (r *CampaignContactRepositoryImpl) CreateBatch(ctx context.Context, contacts []*entities.CampaignContact) (int, error) {
+	if len(contacts) == 0 {
+		return 0, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.NewDatabaseError(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO campaign_contacts (`+campaignContactColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (campaign_id, phone) DO NOTHING
+	`)
+	if err != nil {
+		return 0, errors.NewDatabaseError(err, "failed to prepare statement")
+	}
+	defer stmt.Close()
+
+	added := 0
+	for _, contact := range contacts {
+		contact.ID = uuid.New().String()
+
+		variablesJSON, err := json.Marshal(contact.Variables)
+		if err != nil {
+			return 0, errors.NewDatabaseError(err, "failed to marshal contact variables")
+		}
+
+		result, err := stmt.ExecContext(ctx,
+			contact.ID,
+			contact.CampaignID,
+			contact.Phone,
+			contact.Name,
+			variablesJSON,
+			contact.Status,
+			contact.Attempts,
+			pq.Array(contact.CallIDs),
+			contact.LastOutcome,
+			contact.LastError,
+			contact.NextAttemptAt,
+			contact.CreatedAt,
+			contact.UpdatedAt,
+		)
+		if err != nil {
+			return 0, errors.NewDatabaseError(err, "failed to insert campaign contact in batch")
+		}
+
+		if rowsAffected, err := result.RowsAffected(); err == nil {
+			added += int(rowsAffected)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.NewDatabaseError(err, "failed to commit transaction")
+	}
+
+	return added, nil
+}
+
+func (r *CampaignContactRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.CampaignContact, error) {
+	query := `SELECT ` + campaignContactColumns + ` FROM campaign_contacts WHERE id = $1`
+
+	contact, err := r.scanContact(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("campaign contact", id)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get campaign contact")
+	}
+
+	return contact, nil
+}
+
+// GetByCampaignID lists a campaign's contacts. An empty status returns all of them.
+func (r *CampaignContactRepositoryImpl) GetByCampaignID(ctx context.Context, campaignID string, status entities.CampaignContactStatus, limit, offset int) ([]*entities.CampaignContact, error) {
+	query := `
+		SELECT ` + campaignContactColumns + `
+		FROM campaign_contacts
+		WHERE campaign_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at, id
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, campaignID, string(status), limit, offset)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get campaign contacts")
+	}
+	defer rows.Close()
+
+	return r.scanContacts(rows)
+}
+
+// GetDue returns pending contacts whose next attempt is due, oldest first
+func (r *CampaignContactRepositoryImpl) GetDue(ctx context.Context, campaignID string, now time.Time, limit int) ([]*entities.CampaignContact, error) {
+	query := `
+		SELECT ` + campaignContactColumns + `
+		FROM campaign_contacts
+		WHERE campaign_id = $1 AND status = 'pending'
+			AND (next_attempt_at IS NULL OR next_attempt_at <= $2)
+		ORDER BY next_attempt_at NULLS FIRST, created_at
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, campaignID, now, limit)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get due campaign contacts")
+	}
+	defer rows.Close()
+
+	return r.scanContacts(rows)
+}
+
+func (r *CampaignContactRepositoryImpl) CountByStatus(ctx context.Context, campaignID string) (map[entities.CampaignContactStatus]int, error) {
+	query := `
+		SELECT status, COUNT(*)
+		FROM campaign_contacts
+		WHERE campaign_id = $1
+		GROUP BY status
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, campaignID)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to count campaign contacts")
+	}
+	defer rows.Close()
+
+	counts := make(map[entities.CampaignContactStatus]int)
+	for rows.Next() {
+		var status entities.CampaignContactStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan campaign contact count")
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate campaign contact counts")
+	}
+
+	return counts, nil
+}
+
+func (r *CampaignContactRepositoryImpl) Update(ctx context.Context, contact *entities.CampaignContact) error {
+	query := `
+		UPDATE campaign_contacts
+		SET status = $2, attempts = $3, call_ids = $4, last_outcome = $5, last_error = $6,
+			next_attempt_at = $7, updated_at = $8
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		contact.ID,
+		contact.Status,
+		contact.Attempts,
+		pq.Array(contact.CallIDs),
+		contact.LastOutcome,
+		contact.LastError,
+		contact.NextAttemptAt,
+		contact.UpdatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to update campaign contact")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("campaign contact", contact.ID)
+	}
+
+	return nil
+}
+
+func (r *CampaignContactRepositoryImpl) scanContact(row rowScanner) (*entities.CampaignContact, error) {
+	contact := &entities.CampaignContact{}
+	var variablesJSON []byte
+
+	err := row.Scan(
+		&contact.ID,
+		&contact.CampaignID,
+		&contact.Phone,
+		&contact.Name,
+		&variablesJSON,
+		&contact.Status,
+		&contact.Attempts,
+		pq.Array(&contact.CallIDs),
+		&contact.LastOutcome,
+		&contact.LastError,
+		&contact.NextAttemptAt,
+		&contact.CreatedAt,
+		&contact.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(variablesJSON, &contact.Variables); err != nil {
+		return nil, err
+	}
+
+	return contact, nil
+}
+
+func (r *CampaignContactRepositoryImpl) scanContacts(rows *sql.Rows) ([]*entities.CampaignContact, error) {
+	var contacts []*entities.CampaignContact
+
+	for rows.Next() {
+		contact, err := r.scanContact(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan campaign contact")
+		}
+		contacts = append(contacts, contact)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate campaign contacts")
+	}
+
+	return contacts, nil
+}
+
+// HasCall reports whether the call was placed by a campaign
+func (r *CampaignContactRepositoryImpl) HasCall(ctx context.Context, callID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM campaign_contacts WHERE $1 = ANY(call_ids))`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, callID).Scan(&exists); err != nil {
+		return false, errors.NewDatabaseError(err, "failed to look up campaign call")
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,335 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type DoNotCallRepositoryImpl struct {
+	db *DB
+}
+
+func NewDoNotCallRepository(db *DB) DoNotCallRepository {
+	return &DoNotCallRepositoryImpl{db: db}
+}
+
+// Create adds a number to the list. Adding a number that is already listed
+// for the same scope is not an error.
+func (r *DoNotCallRepositoryImpl) Create(ctx context.Context, entry *entities.DoNotCallEntry) error {
+	entry.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO do_not_call (id, business_id, phone, source, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		entry.ID,
+		entry.BusinessID,
+		entry.Phone,
+		entry.Source,
+		entry.Reason,
+		entry.CreatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create do-not-call entry")
+	}
+
+	return nil
+}
+
+func (r *DoNotCallRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.DoNotCallEntry, error) {
+	query := `
+		SELECT id, business_id, phone, source, reason, created_at
+		FROM do_not_call
+		WHERE id = $1
+	`
+
+	entry := &entities.DoNotCallEntry{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&entry.ID,
+		&entry.BusinessID,
+		&entry.Phone,
+		&entry.Source,
+		&entry.Reason,
+		&entry.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("do-not-call entry", id)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get do-not-call entry")
+	}
+
+	return entry, nil
+}
+
+// IsListed checks both the business's own list and the global list
+func (r *DoNotCallRepositoryImpl) IsListed(ctx context.Context, businessID, phone string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM do_not_call
+			WHERE phone = $2 AND (business_id = $1 OR business_id IS NULL)
+		)
+	`
+
+	var listed bool
+	if err := r.db.QueryRowContext(ctx, query, businessID, phone).Scan(&listed); err != nil {
+		return false, errors.NewDatabaseError(err, "failed to check do-not-call list")
+	}
+
+	return listed, nil
+}
+
+func (r *DoNotCallRepositoryImpl) GetByBusinessID(ctx context.Context, businessID string, limit, offset int) ([]*entities.DoNotCallEntry, error) {
+	query := `
+		SELECT id, business_id, phone, source, reason, created_at
+		FROM do_not_call
+		WHERE business_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, limit, offset)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get do-not-call entries")
+	}
+	return scanDoNotCallEntries(rows)
+}
+
+func (r *DoNotCallRepositoryImpl) GetGlobal(ctx context.Context, limit, offset int) ([]*entities.DoNotCallEntry, error) {
+	query := `
+		SELECT id, business_id, phone, source, reason, created_at
+		FROM do_not_call
+		WHERE business_id IS NULL
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get global do-not-call entries")
+	}
+	return scanDoNotCallEntries(rows)
+}
+
+func scanDoNotCallEntries(rows *sql.Rows) ([]*entities.DoNotCallEntry, error) {
+	defer rows.Close()
+
+	var entries []*entities.DoNotCallEntry
+	for rows.Next() {
+		entry := &entities.DoNotCallEntry{}
+		err := rows.Scan(
+			&entry.ID,
+			&entry.BusinessID,
+			&entry.Phone,
+			&entry.Source,
+			&entry.Reason,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan do-not-call entry")
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate do-not-call entries")
+	}
+
+	return entries, nil
+}
+
+func (r *DoNotCallRepositoryImpl) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM do_not_call WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to delete do-not-call entry")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("do-not-call entry", id)
+	}
+
+	return nil
+}
+
+type ConsentRepositoryImpl struct {
+	db *DB
+}
+
+func NewConsentRepository(db *DB) ConsentRepository {
+	return &ConsentRepositoryImpl{db: db}
+}
+
+func (r *ConsentRepositoryImpl) Create(ctx context.Context, consent *entities.ConsentRecord) error {
+	consent.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO consent_records (id, business_id, phone, source, notes, granted_at, revoked_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		consent.ID,
+		consent.BusinessID,
+		consent.Phone,
+		consent.Source,
+		consent.Notes,
+		consent.GrantedAt,
+		consent.RevokedAt,
+		consent.CreatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create consent record")
+	}
+
+	return nil
+}
+
+func (r *ConsentRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.ConsentRecord, error) {
+	query := `
+		SELECT id, business_id, phone, source, notes, granted_at, revoked_at, created_at
+		FROM consent_records
+		WHERE id = $1
+	`
+
+	consent := &entities.ConsentRecord{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&consent.ID,
+		&consent.BusinessID,
+		&consent.Phone,
+		&consent.Source,
+		&consent.Notes,
+		&consent.GrantedAt,
+		&consent.RevokedAt,
+		&consent.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("consent record", id)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get consent record")
+	}
+
+	return consent, nil
+}
+
+// GetActive returns the most recent unrevoked consent for the number, or nil
+// when there is none
+func (r *ConsentRepositoryImpl) GetActive(ctx context.Context, businessID, phone string) (*entities.ConsentRecord, error) {
+	query := `
+		SELECT id, business_id, phone, source, notes, granted_at, revoked_at, created_at
+		FROM consent_records
+		WHERE business_id = $1 AND phone = $2 AND revoked_at IS NULL
+		ORDER BY granted_at DESC
+		LIMIT 1
+	`
+
+	consent := &entities.ConsentRecord{}
+	err := r.db.QueryRowContext(ctx, query, businessID, phone).Scan(
+		&consent.ID,
+		&consent.BusinessID,
+		&consent.Phone,
+		&consent.Source,
+		&consent.Notes,
+		&consent.GrantedAt,
+		&consent.RevokedAt,
+		&consent.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get active consent")
+	}
+
+	return consent, nil
+}
+
+func (r *ConsentRepositoryImpl) GetByBusinessID(ctx context.Context, businessID string, limit, offset int) ([]*entities.ConsentRecord, error) {
+	query := `
+		SELECT id, business_id, phone, source, notes, granted_at, revoked_at, created_at
+		FROM consent_records
+		WHERE business_id = $1
+		ORDER BY granted_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, limit, offset)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get consent records")
+	}
+	defer rows.Close()
+
+	var consents []*entities.ConsentRecord
+	for rows.Next() {
+		consent := &entities.ConsentRecord{}
+		err := rows.Scan(
+			&consent.ID,
+			&consent.BusinessID,
+			&consent.Phone,
+			&consent.Source,
+			&consent.Notes,
+			&consent.GrantedAt,
+			&consent.RevokedAt,
+			&consent.CreatedAt,
+		)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan consent record")
+		}
+		consents = append(consents, consent)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate consent records")
+	}
+
+	return consents, nil
+}
+
+func (r *ConsentRepositoryImpl) Update(ctx context.Context, consent *entities.ConsentRecord) error {
+	query := `
+		UPDATE consent_records
+		SET source = $2, notes = $3, revoked_at = $4
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		consent.ID,
+		consent.Source,
+		consent.Notes,
+		consent.RevokedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to update consent record")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("consent record", consent.ID)
+	}
+
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -11,13 +13,51 @@ import (
 	"github.com/CallPilotReceptionist/pkg/logger"
 )
 
+// DB is the application's connection pool, on which row-level security
+// shows only the rows of the business a transaction is scoped to, and a
+// second pool for Unscoped system work whose connections set
+// app.bypass_rls
 type DB struct {
 	*sql.DB
+	system *sql.DB
 	logger *logger.Logger
 }
 
 func NewDB(cfg *config.DatabaseConfig, log *logger.Logger) (*DB, error) {
-	db, err := sql.Open("postgres", cfg.URL)
+	db, err := openPool(cfg, cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	systemURL, err := withBypassRLS(cfg.URL)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("invalid database URL: %w", err)
+	}
+	system, err := openPool(cfg, systemURL)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	log.Info("Database connection established", nil)
+
+	// Row-level security does not apply to superusers or roles that bypass it
+	var bypassesRLS bool
+	err = db.QueryRow(`SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`).Scan(&bypassesRLS)
+	if err == nil && bypassesRLS {
+		log.Warn("Database role bypasses row-level security; tenant isolation relies on the application alone", nil)
+	}
+
+	return &DB{
+		DB:     db,
+		system: system,
+		logger: log,
+	}, nil
+}
+
+func openPool(cfg *config.DatabaseConfig, dsn string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -27,19 +67,36 @@ func NewDB(cfg *config.DatabaseConfig, log *logger.Logger) (*DB, error) {
 	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
+	return db, nil
+}
 
-	log.Info("Database connection established", nil)
+// withBypassRLS adds app.bypass_rls=on to the options every connection
+// starts with. It accepts both URLs and key=value connection strings.
+func withBypassRLS(dsn string) (string, error) {
+	const option = "-c app.bypass_rls=on"
 
-	return &DB{
-		DB:     db,
-		logger: log,
-	}, nil
+	if !strings.HasPrefix(dsn, "postgres://") && !strings.HasPrefix(dsn, "postgresql://") {
+		return dsn + " options='" + option + "'", nil
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("options", strings.TrimSpace(query.Get("options")+" "+option))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
 }
 
 func (db *DB) Close() error {
 	db.logger.Info("Closing database connection", nil)
+	if db.system != nil {
+		db.system.Close()
+	}
 	return db.DB.Close()
 }
 
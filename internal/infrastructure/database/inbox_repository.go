@@ -0,0 +1,226 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type InboxRepositoryImpl struct {
+	db *DB
+}
+
+func NewInboxRepository(db *DB) InboxRepository {
+	return &InboxRepositoryImpl{db: db}
+}
+
+const inboxStateColumns = `business_id, item_type, source_id, assignee_id, snoozed_until, done_by, done_at, created_at, updated_at`
+
+// GetState returns the follow-up state of an item, or nil if nobody has
+// acted on it yet
+func (r *InboxRepositoryImpl) GetState(ctx context.Context, businessID string, itemType entities.InboxItemType, sourceID string) (*entities.InboxItemState, error) {
+	query := `
+		SELECT ` + inboxStateColumns + `
+		FROM inbox_item_states
+		WHERE business_id = $1 AND item_type = $2 AND source_id = $3
+	`
+
+	state, err := r.scanState(r.db.QueryRowContext(ctx, query, businessID, itemType, sourceID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get inbox item state")
+	}
+
+	return state, nil
+}
+
+func (r *InboxRepositoryImpl) GetStates(ctx context.Context, businessID string, sourceIDs []string) ([]*entities.InboxItemState, error) {
+	if len(sourceIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT ` + inboxStateColumns + `
+		FROM inbox_item_states
+		WHERE business_id = $1 AND source_id = ANY($2::uuid[])
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, pq.Array(sourceIDs))
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get inbox item states")
+	}
+	defer rows.Close()
+
+	var states []*entities.InboxItemState
+	for rows.Next() {
+		state, err := r.scanState(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan inbox item state")
+		}
+		states = append(states, state)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate inbox item states")
+	}
+
+	return states, nil
+}
+
+// SaveState creates or replaces the follow-up state of an item
+func (r *InboxRepositoryImpl) SaveState(ctx context.Context, state *entities.InboxItemState) error {
+	query := `
+		INSERT INTO inbox_item_states (` + inboxStateColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (business_id, item_type, source_id) DO UPDATE
+		SET assignee_id = EXCLUDED.assignee_id, snoozed_until = EXCLUDED.snoozed_until,
+			done_by = EXCLUDED.done_by, done_at = EXCLUDED.done_at, updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		state.BusinessID,
+		state.ItemType,
+		state.SourceID,
+		state.AssigneeID,
+		state.SnoozedUntil,
+		state.DoneBy,
+		state.DoneAt,
+		state.CreatedAt,
+		state.UpdatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to save inbox item state")
+	}
+
+	return nil
+}
+
+func (r *InboxRepositoryImpl) CreateComment(ctx context.Context, comment *entities.InboxComment) error {
+	comment.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO inbox_comments (id, business_id, item_type, source_id, user_id, body, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		comment.ID,
+		comment.BusinessID,
+		comment.ItemType,
+		comment.SourceID,
+		comment.UserID,
+		comment.Body,
+		comment.CreatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create inbox comment")
+	}
+
+	return nil
+}
+
+func (r *InboxRepositoryImpl) GetComments(ctx context.Context, businessID string, itemType entities.InboxItemType, sourceID string) ([]*entities.InboxComment, error) {
+	query := `
+		SELECT id, business_id, item_type, source_id, user_id, body, created_at
+		FROM inbox_comments
+		WHERE business_id = $1 AND item_type = $2 AND source_id = $3
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, itemType, sourceID)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get inbox comments")
+	}
+	defer rows.Close()
+
+	var comments []*entities.InboxComment
+	for rows.Next() {
+		comment := &entities.InboxComment{}
+		var userID sql.NullString
+		err := rows.Scan(
+			&comment.ID,
+			&comment.BusinessID,
+			&comment.ItemType,
+			&comment.SourceID,
+			&userID,
+			&comment.Body,
+			&comment.CreatedAt,
+		)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan inbox comment")
+		}
+		comment.UserID = userID.String
+		comments = append(comments, comment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate inbox comments")
+	}
+
+	return comments, nil
+}
+
+// CountComments returns the number of comments per source record
+func (r *InboxRepositoryImpl) CountComments(ctx context.Context, businessID string, sourceIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(sourceIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT source_id, COUNT(*)
+		FROM inbox_comments
+		WHERE business_id = $1 AND source_id = ANY($2::uuid[])
+		GROUP BY source_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, pq.Array(sourceIDs))
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to count inbox comments")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sourceID string
+		var count int
+		if err := rows.Scan(&sourceID, &count); err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan inbox comment count")
+		}
+		counts[sourceID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate inbox comment counts")
+	}
+
+	return counts, nil
+}
+
+func (r *InboxRepositoryImpl) scanState(row rowScanner) (*entities.InboxItemState, error) {
+	state := &entities.InboxItemState{}
+
+	err := row.Scan(
+		&state.BusinessID,
+		&state.ItemType,
+		&state.SourceID,
+		&state.AssigneeID,
+		&state.SnoozedUntil,
+		&state.DoneBy,
+		&state.DoneAt,
+		&state.CreatedAt,
+		&state.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/CallPilotReceptionist/internal/domain/entities"
 	"github.com/CallPilotReceptionist/internal/domain/errors"
 )
@@ -116,6 +118,51 @@ func (r *InteractionRepositoryImpl) List(ctx context.Context, businessID string,
 	return r.scanInteractions(rows)
 }
 
+func (r *InteractionRepositoryImpl) GetByCallerPhone(ctx context.Context, businessID, callerPhone string, types []entities.InteractionType, limit int) ([]*entities.Interaction, error) {
+	typeNames := make([]string, 0, len(types))
+	for _, t := range types {
+		typeNames = append(typeNames, string(t))
+	}
+
+	query := `
+		SELECT i.id, i.call_id, i.type, i.content, i.timestamp, i.created_at
+		FROM interactions i
+		JOIN calls c ON i.call_id = c.id
+		WHERE c.business_id = $1 AND c.caller_phone = $2
+			AND (cardinality($3::text[]) = 0 OR i.type = ANY($3))
+		ORDER BY i.timestamp DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, callerPhone, pq.Array(typeNames), limit)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get interactions by caller phone")
+	}
+	defer rows.Close()
+
+	return r.scanInteractions(rows)
+}
+
+// GetByType returns a business's interactions of one type recorded in the
+// date range, oldest first
+func (r *InteractionRepositoryImpl) GetByType(ctx context.Context, businessID string, interactionType entities.InteractionType, startDate, endDate time.Time) ([]*entities.Interaction, error) {
+	query := `
+		SELECT i.id, i.call_id, i.type, i.content, i.timestamp, i.created_at
+		FROM interactions i
+		JOIN calls c ON i.call_id = c.id
+		WHERE c.business_id = $1 AND i.type = $2 AND i.timestamp >= $3 AND i.timestamp <= $4
+		ORDER BY i.timestamp ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, string(interactionType), startDate, endDate)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get interactions by type")
+	}
+	defer rows.Close()
+
+	return r.scanInteractions(rows)
+}
+
 func (r *InteractionRepositoryImpl) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM interactions WHERE id = $1`
 
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type UserInvitationRepositoryImpl struct {
+	db *DB
+}
+
+func NewUserInvitationRepository(db *DB) UserInvitationRepository {
+	return &UserInvitationRepositoryImpl{db: db}
+}
+
+const invitationColumns = `id, business_id, email, role, token_hash, invited_by, expires_at, accepted_at, revoked_at, created_at`
+
+func (r *UserInvitationRepositoryImpl) Create(ctx context.Context, invitation *entities.UserInvitation) error {
+	invitation.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO user_invitations (` + invitationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		invitation.ID,
+		invitation.BusinessID,
+		invitation.Email,
+		invitation.Role,
+		invitation.TokenHash,
+		invitation.InvitedBy,
+		invitation.ExpiresAt,
+		invitation.AcceptedAt,
+		invitation.RevokedAt,
+		invitation.CreatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create invitation")
+	}
+
+	return nil
+}
+
+func (r *UserInvitationRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.UserInvitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM user_invitations WHERE id = $1`
+
+	invitation, err := r.scanInvitation(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("invitation", id)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get invitation")
+	}
+
+	return invitation, nil
+}
+
+// GetByTokenHash returns the invitation issued with a token, or nil if there
+// is none
+func (r *UserInvitationRepositoryImpl) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserInvitation, error) {
+	query := `SELECT ` + invitationColumns + ` FROM user_invitations WHERE token_hash = $1`
+
+	invitation, err := r.scanInvitation(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get invitation by token")
+	}
+
+	return invitation, nil
+}
+
+// GetPending returns the invitations of a business that can still be accepted
+func (r *UserInvitationRepositoryImpl) GetPending(ctx context.Context, businessID string, now time.Time) ([]*entities.UserInvitation, error) {
+	query := `
+		SELECT ` + invitationColumns + `
+		FROM user_invitations
+		WHERE business_id = $1 AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, now)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get pending invitations")
+	}
+	defer rows.Close()
+
+	var invitations []*entities.UserInvitation
+	for rows.Next() {
+		invitation, err := r.scanInvitation(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan invitation")
+		}
+		invitations = append(invitations, invitation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate invitations")
+	}
+
+	return invitations, nil
+}
+
+// Update accepts or revokes an invitation. The update only applies while
+// the invitation is pending, so it cannot be redeemed twice concurrently or
+// accepted and revoked at once.
+func (r *UserInvitationRepositoryImpl) Update(ctx context.Context, invitation *entities.UserInvitation) error {
+	query := `
+		UPDATE user_invitations
+		SET accepted_at = $2, revoked_at = $3
+		WHERE id = $1 AND accepted_at IS NULL AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, invitation.ID, invitation.AcceptedAt, invitation.RevokedAt)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to update invitation")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewValidationError("invitation is no longer valid")
+	}
+
+	return nil
+}
+
+func (r *UserInvitationRepositoryImpl) scanInvitation(row rowScanner) (*entities.UserInvitation, error) {
+	invitation := &entities.UserInvitation{}
+	var invitedBy sql.NullString
+
+	err := row.Scan(
+		&invitation.ID,
+		&invitation.BusinessID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.TokenHash,
+		&invitedBy,
+		&invitation.ExpiresAt,
+		&invitation.AcceptedAt,
+		&invitation.RevokedAt,
+		&invitation.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	invitation.InvitedBy = invitedBy.String
+
+	return invitation, nil
+}
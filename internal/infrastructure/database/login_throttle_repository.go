@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/pkg/config"
+)
+
+// NewLoginThrottleStore returns the failed-login store configured by
+// cfg.Store: "postgres" shares counters between instances, "memory" keeps
+// them in the process for single-node deployments
+func NewLoginThrottleStore(db *DB, cfg config.LoginThrottleConfig) (LoginThrottleRepository, error) {
+	switch cfg.Store {
+	case "postgres", "":
+		return NewLoginThrottleRepository(db), nil
+	case "memory":
+		return NewMemoryLoginThrottleRepository(), nil
+	default:
+		return nil, fmt.Errorf("unknown login throttle store %q", cfg.Store)
+	}
+}
+
+type LoginThrottleRepositoryImpl struct {
+	db *DB
+}
+
+func NewLoginThrottleRepository(db *DB) LoginThrottleRepository {
+	return &LoginThrottleRepositoryImpl{db: db}
+}
+
+// Get returns the counters for key, or nil if it has none
+func (r *LoginThrottleRepositoryImpl) Get(ctx context.Context, key string) (*entities.LoginThrottle, error) {
+	query := `SELECT key, failures, last_failure_at, locked_until FROM login_throttles WHERE key = $1`
+
+	throttle := &entities.LoginThrottle{}
+	err := r.db.QueryRowContext(ctx, query, key).Scan(
+		&throttle.Key,
+		&throttle.Failures,
+		&throttle.LastFailureAt,
+		&throttle.LockedUntil,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get login throttle")
+	}
+
+	return throttle, nil
+}
+
+// RecordFailure counts a failed login for key in a single statement, so
+// concurrent attempts cannot lose a failure. Failures before windowStart no
+// longer count.
+func (r *LoginThrottleRepositoryImpl) RecordFailure(ctx context.Context, key string, now, windowStart time.Time) (*entities.LoginThrottle, error) {
+	query := `
+		INSERT INTO login_throttles (key, failures, last_failure_at)
+		VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_throttles.last_failure_at < $3 THEN 1 ELSE login_throttles.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING key, failures, last_failure_at, locked_until
+	`
+
+	throttle := &entities.LoginThrottle{}
+	err := r.db.QueryRowContext(ctx, query, key, now, windowStart).Scan(
+		&throttle.Key,
+		&throttle.Failures,
+		&throttle.LastFailureAt,
+		&throttle.LockedUntil,
+	)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to record login failure")
+	}
+
+	return throttle, nil
+}
+
+func (r *LoginThrottleRepositoryImpl) Lock(ctx context.Context, key string, until time.Time) error {
+	query := `UPDATE login_throttles SET locked_until = $2 WHERE key = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, key, until); err != nil {
+		return errors.NewDatabaseError(err, "failed to lock login")
+	}
+
+	return nil
+}
+
+func (r *LoginThrottleRepositoryImpl) Delete(ctx context.Context, key string) error {
+	query := `DELETE FROM login_throttles WHERE key = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, key); err != nil {
+		return errors.NewDatabaseError(err, "failed to delete login throttle")
+	}
+
+	return nil
+}
+
+// MemoryLoginThrottleRepository keeps failed-login counters in memory. They
+// are lost on restart and not shared between instances.
+type MemoryLoginThrottleRepository struct {
+	mu        sync.Mutex
+	throttles map[string]*entities.LoginThrottle
+}
+
+func NewMemoryLoginThrottleRepository() *MemoryLoginThrottleRepository {
+	return &MemoryLoginThrottleRepository{throttles: make(map[string]*entities.LoginThrottle)}
+}
+
+func (r *MemoryLoginThrottleRepository) Get(ctx context.Context, key string) (*entities.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	throttle, ok := r.throttles[key]
+	if !ok {
+		return nil, nil
+	}
+	copied := *throttle
+	return &copied, nil
+}
+
+// RecordFailure counts a failed login for key. Counters that have gone
+// quiet since windowStart are dropped so the map does not grow without
+// bound.
+func (r *MemoryLoginThrottleRepository) RecordFailure(ctx context.Context, key string, now, windowStart time.Time) (*entities.LoginThrottle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, throttle := range r.throttles {
+		if throttle.LastFailureAt.Before(windowStart) && !throttle.IsLocked(now) {
+			delete(r.throttles, k)
+		}
+	}
+
+	throttle, ok := r.throttles[key]
+	if !ok {
+		throttle = &entities.LoginThrottle{Key: key}
+		r.throttles[key] = throttle
+	}
+	throttle.Failures++
+	throttle.LastFailureAt = now
+
+	copied := *throttle
+	return &copied, nil
+}
+
+func (r *MemoryLoginThrottleRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if throttle, ok := r.throttles[key]; ok {
+		throttle.LockedUntil = &until
+	}
+	return nil
+}
+
+func (r *MemoryLoginThrottleRepository) Delete(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.throttles, key)
+	return nil
+}
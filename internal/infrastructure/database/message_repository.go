@@ -0,0 +1,360 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type MessageRepositoryImpl struct {
+	db *DB
+}
+
+func NewMessageRepository(db *DB) MessageRepository {
+	return &MessageRepositoryImpl{db: db}
+}
+
+const messageColumns = `id, business_id, call_id, caller_name, caller_phone, callback_phone, recipient_name,
+			recipient_id, assignee_id, urgency, body, source, status, acknowledged_by, acknowledged_at,
+			resolved_by, resolved_at, created_at, updated_at`
+
+func (r *MessageRepositoryImpl) Create(ctx context.Context, message *entities.Message) error {
+	message.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO messages (` + messageColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		message.ID,
+		message.BusinessID,
+		message.CallID,
+		message.CallerName,
+		message.CallerPhone,
+		message.CallbackPhone,
+		message.RecipientName,
+		message.RecipientID,
+		message.AssigneeID,
+		message.Urgency,
+		message.Body,
+		message.Source,
+		message.Status,
+		message.AcknowledgedBy,
+		message.AcknowledgedAt,
+		message.ResolvedBy,
+		message.ResolvedAt,
+		message.CreatedAt,
+		message.UpdatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create message")
+	}
+
+	return nil
+}
+
+func (r *MessageRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Message, error) {
+	query := `SELECT ` + messageColumns + ` FROM messages WHERE id = $1`
+
+	message, err := r.scanMessage(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("message", id)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get message")
+	}
+
+	return message, nil
+}
+
+// List returns a business's messages matching the filter, urgent ones first
+// and then newest first
+func (r *MessageRepositoryImpl) List(ctx context.Context, businessID string, filter MessageFilter, limit, offset int) ([]*entities.Message, error) {
+	query := `
+		SELECT ` + messageColumns + `
+		FROM messages
+		WHERE business_id = $1
+			AND ($2 = '' OR status = $2)
+			AND ($3 = '' OR recipient_id::text = $3)
+			AND ($4 = '' OR assignee_id::text = $4)
+		ORDER BY (urgency = 'urgent') DESC, created_at DESC
+		LIMIT $5 OFFSET $6
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, string(filter.Status), filter.RecipientID, filter.AssigneeID, limit, offset)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to list messages")
+	}
+	defer rows.Close()
+
+	var messages []*entities.Message
+	for rows.Next() {
+		message, err := r.scanMessage(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan message")
+		}
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate messages")
+	}
+
+	return messages, nil
+}
+
+// HasCallMessage reports whether a message was already taken during the call
+func (r *MessageRepositoryImpl) HasCallMessage(ctx context.Context, callID string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM messages WHERE call_id = $1)`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, callID).Scan(&exists); err != nil {
+		return false, errors.NewDatabaseError(err, "failed to check call messages")
+	}
+
+	return exists, nil
+}
+
+func (r *MessageRepositoryImpl) Update(ctx context.Context, message *entities.Message) error {
+	query := `
+		UPDATE messages
+		SET recipient_id = $2, assignee_id = $3, status = $4, acknowledged_by = $5, acknowledged_at = $6,
+			resolved_by = $7, resolved_at = $8, updated_at = $9
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		message.ID,
+		message.RecipientID,
+		message.AssigneeID,
+		message.Status,
+		message.AcknowledgedBy,
+		message.AcknowledgedAt,
+		message.ResolvedBy,
+		message.ResolvedAt,
+		message.UpdatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to update message")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("message", message.ID)
+	}
+
+	return nil
+}
+
+func (r *MessageRepositoryImpl) scanMessage(row rowScanner) (*entities.Message, error) {
+	message := &entities.Message{}
+
+	err := row.Scan(
+		&message.ID,
+		&message.BusinessID,
+		&message.CallID,
+		&message.CallerName,
+		&message.CallerPhone,
+		&message.CallbackPhone,
+		&message.RecipientName,
+		&message.RecipientID,
+		&message.AssigneeID,
+		&message.Urgency,
+		&message.Body,
+		&message.Source,
+		&message.Status,
+		&message.AcknowledgedBy,
+		&message.AcknowledgedAt,
+		&message.ResolvedBy,
+		&message.ResolvedAt,
+		&message.CreatedAt,
+		&message.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+type MessageRecipientRepositoryImpl struct {
+	db *DB
+}
+
+func NewMessageRecipientRepository(db *DB) MessageRecipientRepository {
+	return &MessageRecipientRepositoryImpl{db: db}
+}
+
+const messageRecipientColumns = `id, business_id, user_id, name, aliases, channels, active, created_at, updated_at`
+
+func (r *MessageRecipientRepositoryImpl) Create(ctx context.Context, recipient *entities.MessageRecipient) error {
+	recipient.ID = uuid.New().String()
+
+	channelsJSON, err := json.Marshal(recipient.Channels)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to marshal channels")
+	}
+
+	query := `
+		INSERT INTO message_recipients (` + messageRecipientColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		recipient.ID,
+		recipient.BusinessID,
+		recipient.UserID,
+		recipient.Name,
+		pq.Array(recipient.Aliases),
+		channelsJSON,
+		recipient.Active,
+		recipient.CreatedAt,
+		recipient.UpdatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create message recipient")
+	}
+
+	return nil
+}
+
+func (r *MessageRecipientRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.MessageRecipient, error) {
+	query := `SELECT ` + messageRecipientColumns + ` FROM message_recipients WHERE id = $1`
+
+	recipient, err := r.scanRecipient(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("message recipient", id)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get message recipient")
+	}
+
+	return recipient, nil
+}
+
+func (r *MessageRecipientRepositoryImpl) GetByBusinessID(ctx context.Context, businessID string) ([]*entities.MessageRecipient, error) {
+	query := `
+		SELECT ` + messageRecipientColumns + `
+		FROM message_recipients
+		WHERE business_id = $1
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get message recipients by business")
+	}
+	defer rows.Close()
+
+	var recipients []*entities.MessageRecipient
+	for rows.Next() {
+		recipient, err := r.scanRecipient(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan message recipient")
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate message recipients")
+	}
+
+	return recipients, nil
+}
+
+func (r *MessageRecipientRepositoryImpl) Update(ctx context.Context, recipient *entities.MessageRecipient) error {
+	channelsJSON, err := json.Marshal(recipient.Channels)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to marshal channels")
+	}
+
+	query := `
+		UPDATE message_recipients
+		SET user_id = $2, name = $3, aliases = $4, channels = $5, active = $6, updated_at = $7
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		recipient.ID,
+		recipient.UserID,
+		recipient.Name,
+		pq.Array(recipient.Aliases),
+		channelsJSON,
+		recipient.Active,
+		recipient.UpdatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to update message recipient")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("message recipient", recipient.ID)
+	}
+
+	return nil
+}
+
+func (r *MessageRecipientRepositoryImpl) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM message_recipients WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to delete message recipient")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("message recipient", id)
+	}
+
+	return nil
+}
+
+func (r *MessageRecipientRepositoryImpl) scanRecipient(row rowScanner) (*entities.MessageRecipient, error) {
+	recipient := &entities.MessageRecipient{}
+	var channelsJSON []byte
+
+	err := row.Scan(
+		&recipient.ID,
+		&recipient.BusinessID,
+		&recipient.UserID,
+		&recipient.Name,
+		pq.Array(&recipient.Aliases),
+		&channelsJSON,
+		&recipient.Active,
+		&recipient.CreatedAt,
+		&recipient.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(channelsJSON, &recipient.Channels); err != nil {
+		return nil, err
+	}
+
+	return recipient, nil
+}
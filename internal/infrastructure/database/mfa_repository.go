@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type MFARepositoryImpl struct {
+	db *DB
+}
+
+func NewMFARepository(db *DB) MFARepository {
+	return &MFARepositoryImpl{db: db}
+}
+
+// GetByUserID returns the user's enrolment, or nil if they have none
+func (r *MFARepositoryImpl) GetByUserID(ctx context.Context, userID string) (*entities.UserMFA, error) {
+	query := `
+		SELECT user_id, encrypted_secret, enabled_at, last_used_step, created_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+
+	mfa := &entities.UserMFA{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&mfa.UserID,
+		&mfa.EncryptedSecret,
+		&mfa.EnabledAt,
+		&mfa.LastUsedStep,
+		&mfa.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get two-factor enrolment")
+	}
+
+	return mfa, nil
+}
+
+// Save creates or replaces the user's enrolment. A saved enrolment is only
+// replaced with a later code step, so two requests cannot both use a code,
+// or by a new setup while it is not enabled.
+func (r *MFARepositoryImpl) Save(ctx context.Context, mfa *entities.UserMFA) error {
+	query := `
+		INSERT INTO user_mfa (user_id, encrypted_secret, enabled_at, last_used_step, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET
+			encrypted_secret = EXCLUDED.encrypted_secret,
+			enabled_at = EXCLUDED.enabled_at,
+			last_used_step = EXCLUDED.last_used_step,
+			created_at = EXCLUDED.created_at
+		WHERE user_mfa.last_used_step < EXCLUDED.last_used_step
+			OR (EXCLUDED.last_used_step = 0 AND user_mfa.enabled_at IS NULL)
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		mfa.UserID,
+		mfa.EncryptedSecret,
+		mfa.EnabledAt,
+		mfa.LastUsedStep,
+		mfa.CreatedAt,
+	)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to save two-factor enrolment")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+	if rowsAffected == 0 {
+		if mfa.LastUsedStep == 0 {
+			return errors.NewValidationError("two-factor authentication is already enabled")
+		}
+		return errors.NewUnauthorizedError("code has already been used")
+	}
+
+	return nil
+}
+
+// Delete removes the user's enrolment and recovery codes
+func (r *MFARepositoryImpl) Delete(ctx context.Context, userID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return errors.NewDatabaseError(err, "failed to delete recovery codes")
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID); err != nil {
+		return errors.NewDatabaseError(err, "failed to delete two-factor enrolment")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewDatabaseError(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes discards the user's recovery codes and stores new ones
+func (r *MFARepositoryImpl) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []*entities.RecoveryCode) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return errors.NewDatabaseError(err, "failed to delete recovery codes")
+	}
+
+	for _, code := range codes {
+		code.ID = uuid.New().String()
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO mfa_recovery_codes (id, user_id, code_hash, used_at, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, code.ID, code.UserID, code.CodeHash, code.UsedAt, code.CreatedAt)
+		if err != nil {
+			return errors.NewDatabaseError(err, "failed to create recovery code")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewDatabaseError(err, "failed to commit transaction")
+	}
+
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code used and reports whether
+// there was one
+func (r *MFARepositoryImpl) UseRecoveryCode(ctx context.Context, userID, codeHash string, now time.Time) (bool, error) {
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = $3
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, codeHash, now)
+	if err != nil {
+		return false, errors.NewDatabaseError(err, "failed to use recovery code")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// CountRecoveryCodes returns how many unused recovery codes the user has left
+func (r *MFARepositoryImpl) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, errors.NewDatabaseError(err, "failed to count recovery codes")
+	}
+
+	return count, nil
+}
@@ -0,0 +1,206 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type OnboardingRepositoryImpl struct {
+	db *DB
+}
+
+func NewOnboardingRepository(db *DB) OnboardingRepository {
+	return &OnboardingRepositoryImpl{db: db}
+}
+
+// onboardingColumns prefers the provider ids recorded by RecordProviderID,
+// which outlive a request that rolled back after the provider created them
+const onboardingColumns = `
+	o.business_id, o.profile, o.status, o.steps,
+	COALESCE((SELECT provider_id FROM onboarding_provider_ids
+		WHERE business_id = o.business_id AND step = 'assistant'), o.assistant_id),
+	COALESCE((SELECT provider_id FROM onboarding_provider_ids
+		WHERE business_id = o.business_id AND step = 'phone_number'), o.phone_number_id),
+	o.created_at, o.updated_at, o.completed_at
+`
+
+// Get returns the business's onboarding, or nil if it has not started one
+func (r *OnboardingRepositoryImpl) Get(ctx context.Context, businessID string) (*entities.Onboarding, error) {
+	query := `SELECT ` + onboardingColumns + ` FROM onboardings o WHERE o.business_id = $1`
+	return r.get(ctx, query, businessID)
+}
+
+// GetForUpdate returns the business's onboarding as Get does and locks it
+// until the context's transaction ends, so onboardings of the same business
+// run one at a time. Without a transaction the lock is released at once.
+func (r *OnboardingRepositoryImpl) GetForUpdate(ctx context.Context, businessID string) (*entities.Onboarding, error) {
+	query := `SELECT ` + onboardingColumns + ` FROM onboardings o WHERE o.business_id = $1 FOR UPDATE OF o`
+	return r.get(ctx, query, businessID)
+}
+
+func (r *OnboardingRepositoryImpl) get(ctx context.Context, query string, businessID string) (*entities.Onboarding, error) {
+	onboarding := &entities.Onboarding{}
+	var profileJSON, stepsJSON []byte
+	var completedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, businessID).Scan(
+		&onboarding.BusinessID,
+		&profileJSON,
+		&onboarding.Status,
+		&stepsJSON,
+		&onboarding.AssistantID,
+		&onboarding.PhoneNumberID,
+		&onboarding.CreatedAt,
+		&onboarding.UpdatedAt,
+		&completedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get onboarding")
+	}
+
+	if err := json.Unmarshal(profileJSON, &onboarding.Profile); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to unmarshal onboarding profile")
+	}
+	if err := json.Unmarshal(stepsJSON, &onboarding.Steps); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to unmarshal onboarding steps")
+	}
+	if completedAt.Valid {
+		onboarding.CompletedAt = &completedAt.Time
+	}
+
+	return onboarding, nil
+}
+
+// Create starts the business's onboarding. It fails with AlreadyExists if
+// the business has one, once any transaction creating it has committed.
+func (r *OnboardingRepositoryImpl) Create(ctx context.Context, onboarding *entities.Onboarding) error {
+	profileJSON, err := json.Marshal(onboarding.Profile)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to marshal onboarding profile")
+	}
+	stepsJSON, err := json.Marshal(onboarding.Steps)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to marshal onboarding steps")
+	}
+
+	query := `
+		INSERT INTO onboardings (business_id, profile, status, steps, assistant_id, phone_number_id,
+			created_at, updated_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (business_id) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		onboarding.BusinessID,
+		profileJSON,
+		onboarding.Status,
+		stepsJSON,
+		onboarding.AssistantID,
+		onboarding.PhoneNumberID,
+		onboarding.CreatedAt,
+		onboarding.UpdatedAt,
+		onboarding.CompletedAt,
+	)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create onboarding")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+	if rowsAffected == 0 {
+		return errors.NewAlreadyExistsError("onboarding", "business_id", onboarding.BusinessID)
+	}
+
+	return nil
+}
+
+// Save creates or replaces the business's onboarding
+func (r *OnboardingRepositoryImpl) Save(ctx context.Context, onboarding *entities.Onboarding) error {
+	profileJSON, err := json.Marshal(onboarding.Profile)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to marshal onboarding profile")
+	}
+	stepsJSON, err := json.Marshal(onboarding.Steps)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to marshal onboarding steps")
+	}
+
+	query := `
+		INSERT INTO onboardings (business_id, profile, status, steps, assistant_id, phone_number_id,
+			created_at, updated_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (business_id) DO UPDATE SET
+			profile = EXCLUDED.profile,
+			status = EXCLUDED.status,
+			steps = EXCLUDED.steps,
+			assistant_id = EXCLUDED.assistant_id,
+			phone_number_id = EXCLUDED.phone_number_id,
+			created_at = EXCLUDED.created_at,
+			updated_at = EXCLUDED.updated_at,
+			completed_at = EXCLUDED.completed_at
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		onboarding.BusinessID,
+		profileJSON,
+		onboarding.Status,
+		stepsJSON,
+		onboarding.AssistantID,
+		onboarding.PhoneNumberID,
+		onboarding.CreatedAt,
+		onboarding.UpdatedAt,
+		onboarding.CompletedAt,
+	)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to save onboarding")
+	}
+
+	return nil
+}
+
+// RecordProviderID records what the provider created for a step. It commits
+// on its own rather than in the context's transaction, so the id is kept
+// even if the request that created it fails.
+func (r *OnboardingRepositoryImpl) RecordProviderID(ctx context.Context, businessID string, step entities.OnboardingStepName, providerID string) error {
+	query := `
+		INSERT INTO onboarding_provider_ids (business_id, step, provider_id, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (business_id, step) DO UPDATE SET
+			provider_id = EXCLUDED.provider_id,
+			created_at = EXCLUDED.created_at
+	`
+
+	err := r.db.Detached(ctx, businessID, func(ctx context.Context) error {
+		_, err := r.db.ExecContext(ctx, query, businessID, string(step), providerID)
+		return err
+	})
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to record onboarding provider id")
+	}
+
+	return nil
+}
+
+// ClearProviderID forgets what the provider created for a step, so it is
+// created again. Like RecordProviderID it commits on its own.
+func (r *OnboardingRepositoryImpl) ClearProviderID(ctx context.Context, businessID string, step entities.OnboardingStepName) error {
+	query := `DELETE FROM onboarding_provider_ids WHERE business_id = $1 AND step = $2`
+
+	err := r.db.Detached(ctx, businessID, func(ctx context.Context) error {
+		_, err := r.db.ExecContext(ctx, query, businessID, string(step))
+		return err
+	})
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to clear onboarding provider id")
+	}
+
+	return nil
+}
@@ -14,7 +14,17 @@ type BusinessRepository interface {
 	GetByPhone(ctx context.Context, phone string) (*entities.Business, error)
 	Update(ctx context.Context, business *entities.Business) error
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, limit, offset int) ([]*entities.Business, error)
+	List(ctx context.Context, filter BusinessFilter, limit, offset int) ([]*entities.Business, error)
+}
+
+// BusinessFilter narrows a business listing. Empty fields match every
+// business.
+type BusinessFilter struct {
+	ParentID string
+	// Search matches part of the name or phone number, or the exact ID
+	Search string
+	// Suspended, when set, matches only suspended or only active businesses
+	Suspended *bool
 }
 
 // UserRepository defines the interface for user data operations
@@ -25,6 +35,82 @@ type UserRepository interface {
 	GetByBusinessID(ctx context.Context, businessID string) ([]*entities.User, error)
 	Update(ctx context.Context, user *entities.User) error
 	Delete(ctx context.Context, id string) error
+
+	// Memberships of users in businesses other than their home business
+	GetMember(ctx context.Context, businessID, userID string) (*entities.User, error)
+	GetMembers(ctx context.Context, businessID string) ([]*entities.User, error)
+	GetMemberships(ctx context.Context, userID string) ([]*entities.Membership, error)
+	SaveMembership(ctx context.Context, membership *entities.Membership) error
+	DeleteMembership(ctx context.Context, userID, businessID string) error
+}
+
+// UserInvitationRepository defines the interface for team invitation operations
+type UserInvitationRepository interface {
+	Create(ctx context.Context, invitation *entities.UserInvitation) error
+	GetByID(ctx context.Context, id string) (*entities.UserInvitation, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserInvitation, error)
+	GetPending(ctx context.Context, businessID string, now time.Time) ([]*entities.UserInvitation, error)
+	Update(ctx context.Context, invitation *entities.UserInvitation) error
+}
+
+// UserTokenRepository defines the interface for password reset and email
+// verification token operations
+type UserTokenRepository interface {
+	Create(ctx context.Context, token *entities.UserToken) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserToken, error)
+	Update(ctx context.Context, token *entities.UserToken) error
+	InvalidateForUser(ctx context.Context, userID string, purpose entities.UserTokenPurpose, now time.Time) error
+}
+
+// MFARepository defines the interface for two-factor authentication operations
+type MFARepository interface {
+	GetByUserID(ctx context.Context, userID string) (*entities.UserMFA, error)
+	Save(ctx context.Context, mfa *entities.UserMFA) error
+	Delete(ctx context.Context, userID string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codes []*entities.RecoveryCode) error
+	UseRecoveryCode(ctx context.Context, userID, codeHash string, now time.Time) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+}
+
+// SSORepository defines the interface for single sign-on connections and
+// pending sign-ins
+type SSORepository interface {
+	GetConnection(ctx context.Context, businessID string) (*entities.SSOConnection, error)
+	SaveConnection(ctx context.Context, connection *entities.SSOConnection) error
+	DeleteConnection(ctx context.Context, businessID string) error
+	CreateLoginState(ctx context.Context, state *entities.SSOLoginState) error
+	ConsumeLoginState(ctx context.Context, stateHash string) (*entities.SSOLoginState, error)
+	GetIdentity(ctx context.Context, businessID, issuer, subject string) (*entities.SSOIdentity, error)
+	CreateIdentity(ctx context.Context, identity *entities.SSOIdentity) error
+}
+
+// LoginThrottleRepository defines the interface for failed-login counters
+type LoginThrottleRepository interface {
+	Get(ctx context.Context, key string) (*entities.LoginThrottle, error)
+	RecordFailure(ctx context.Context, key string, now, windowStart time.Time) (*entities.LoginThrottle, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Delete(ctx context.Context, key string) error
+}
+
+// SessionRepository defines the interface for refresh-token session operations
+type SessionRepository interface {
+	Create(ctx context.Context, session *entities.Session) error
+	GetByID(ctx context.Context, id string) (*entities.Session, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entities.Session, error)
+	Revoke(ctx context.Context, id string, replacedByID *string, now time.Time) (bool, error)
+	RevokeFamily(ctx context.Context, familyID string, now time.Time) error
+	RevokeAllForUser(ctx context.Context, userID string, now time.Time) (int, error)
+	RevokeAllForBusiness(ctx context.Context, businessID string, now time.Time) (int, error)
+}
+
+// APIKeyRepository defines the interface for API key operations
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entities.APIKey) error
+	GetByID(ctx context.Context, id string) (*entities.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*entities.APIKey, error)
+	GetByBusinessID(ctx context.Context, businessID string) ([]*entities.APIKey, error)
+	Update(ctx context.Context, key *entities.APIKey) error
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error
 }
 
 // CallRepository defines the interface for call data operations
@@ -37,6 +123,8 @@ type CallRepository interface {
 	Delete(ctx context.Context, id string) error
 	GetByDateRange(ctx context.Context, businessID string, startDate, endDate time.Time) ([]*entities.Call, error)
 	GetStats(ctx context.Context, businessID string, startDate, endDate time.Time) (*CallStats, error)
+	GetUsageByBusiness(ctx context.Context, startDate, endDate time.Time) ([]*BusinessUsage, error)
+	GetUnfinished(ctx context.Context, createdBefore time.Time, limit int) ([]*entities.Call, error)
 }
 
 // InteractionRepository defines the interface for interaction data operations
@@ -45,6 +133,8 @@ type InteractionRepository interface {
 	GetByID(ctx context.Context, id string) (*entities.Interaction, error)
 	GetByCallID(ctx context.Context, callID string) ([]*entities.Interaction, error)
 	List(ctx context.Context, businessID string, limit, offset int) ([]*entities.Interaction, error)
+	GetByCallerPhone(ctx context.Context, businessID, callerPhone string, types []entities.InteractionType, limit int) ([]*entities.Interaction, error)
+	GetByType(ctx context.Context, businessID string, interactionType entities.InteractionType, startDate, endDate time.Time) ([]*entities.Interaction, error)
 	Delete(ctx context.Context, id string) error
 }
 
@@ -64,10 +154,179 @@ type AppointmentRepository interface {
 	GetByCallID(ctx context.Context, callID string) (*entities.AppointmentRequest, error)
 	GetByBusinessID(ctx context.Context, businessID string, limit, offset int) ([]*entities.AppointmentRequest, error)
 	GetPendingAppointments(ctx context.Context, businessID string) ([]*entities.AppointmentRequest, error)
+	GetByCustomerPhone(ctx context.Context, businessID, customerPhone string, limit int) ([]*entities.AppointmentRequest, error)
 	Update(ctx context.Context, appointment *entities.AppointmentRequest) error
 	Delete(ctx context.Context, id string) error
 }
 
+// DoNotCallRepository defines the interface for do-not-call list operations
+type DoNotCallRepository interface {
+	Create(ctx context.Context, entry *entities.DoNotCallEntry) error
+	GetByID(ctx context.Context, id string) (*entities.DoNotCallEntry, error)
+	IsListed(ctx context.Context, businessID, phone string) (bool, error)
+	GetByBusinessID(ctx context.Context, businessID string, limit, offset int) ([]*entities.DoNotCallEntry, error)
+	// GetGlobal returns the entries that apply to every business
+	GetGlobal(ctx context.Context, limit, offset int) ([]*entities.DoNotCallEntry, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// ConsentRepository defines the interface for consent record operations
+type ConsentRepository interface {
+	Create(ctx context.Context, consent *entities.ConsentRecord) error
+	GetByID(ctx context.Context, id string) (*entities.ConsentRecord, error)
+	GetActive(ctx context.Context, businessID, phone string) (*entities.ConsentRecord, error)
+	GetByBusinessID(ctx context.Context, businessID string, limit, offset int) ([]*entities.ConsentRecord, error)
+	Update(ctx context.Context, consent *entities.ConsentRecord) error
+}
+
+// CampaignRepository defines the interface for outbound campaign operations
+type CampaignRepository interface {
+	Create(ctx context.Context, campaign *entities.Campaign) error
+	GetByID(ctx context.Context, id string) (*entities.Campaign, error)
+	GetByBusinessID(ctx context.Context, businessID string, limit, offset int) ([]*entities.Campaign, error)
+	GetByStatus(ctx context.Context, status entities.CampaignStatus) ([]*entities.Campaign, error)
+	Update(ctx context.Context, campaign *entities.Campaign) error
+	Delete(ctx context.Context, id string) error
+}
+
+// CampaignContactRepository defines the interface for campaign contact operations
+type CampaignContactRepository interface {
+	CreateBatch(ctx context.Context, contacts []*entities.CampaignContact) (int, error)
+	GetByID(ctx context.Context, id string) (*entities.CampaignContact, error)
+	GetByCampaignID(ctx context.Context, campaignID string, status entities.CampaignContactStatus, limit, offset int) ([]*entities.CampaignContact, error)
+	GetDue(ctx context.Context, campaignID string, now time.Time, limit int) ([]*entities.CampaignContact, error)
+	CountByStatus(ctx context.Context, campaignID string) (map[entities.CampaignContactStatus]int, error)
+	Update(ctx context.Context, contact *entities.CampaignContact) error
+	HasCall(ctx context.Context, callID string) (bool, error)
+}
+
+// ScheduledCallRepository defines the interface for scheduled call operations
+type ScheduledCallRepository interface {
+	Create(ctx context.Context, scheduled *entities.ScheduledCall) error
+	GetByID(ctx context.Context, id string) (*entities.ScheduledCall, error)
+	GetByBusinessID(ctx context.Context, businessID string, status entities.ScheduledCallStatus, limit, offset int) ([]*entities.ScheduledCall, error)
+	GetDue(ctx context.Context, now time.Time, limit int) ([]*entities.ScheduledCall, error)
+	GetByCallID(ctx context.Context, callID string) (*entities.ScheduledCall, error)
+	HasPending(ctx context.Context, businessID, phone string) (bool, error)
+	Update(ctx context.Context, scheduled *entities.ScheduledCall) error
+}
+
+// TransferDestinationRepository defines the interface for transfer destination operations
+type TransferDestinationRepository interface {
+	Create(ctx context.Context, destination *entities.TransferDestination) error
+	GetByID(ctx context.Context, id string) (*entities.TransferDestination, error)
+	GetByBusinessID(ctx context.Context, businessID string) ([]*entities.TransferDestination, error)
+	GetByName(ctx context.Context, businessID, name string) (*entities.TransferDestination, error)
+	Update(ctx context.Context, destination *entities.TransferDestination) error
+	Delete(ctx context.Context, id string) error
+}
+
+// OnCallShiftRepository defines the interface for on-call rotation operations
+type OnCallShiftRepository interface {
+	Create(ctx context.Context, shift *entities.OnCallShift) error
+	GetByID(ctx context.Context, id string) (*entities.OnCallShift, error)
+	GetByDestinationID(ctx context.Context, destinationID string, from time.Time) ([]*entities.OnCallShift, error)
+	GetCurrent(ctx context.Context, destinationID string, at time.Time) (*entities.OnCallShift, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// MessageFilter narrows a message listing. Empty fields match every message.
+type MessageFilter struct {
+	Status      entities.MessageStatus
+	RecipientID string
+	AssigneeID  string
+}
+
+// MessageRepository defines the interface for caller message operations
+type MessageRepository interface {
+	Create(ctx context.Context, message *entities.Message) error
+	GetByID(ctx context.Context, id string) (*entities.Message, error)
+	List(ctx context.Context, businessID string, filter MessageFilter, limit, offset int) ([]*entities.Message, error)
+	HasCallMessage(ctx context.Context, callID string) (bool, error)
+	Update(ctx context.Context, message *entities.Message) error
+}
+
+// MessageRecipientRepository defines the interface for message directory operations
+type MessageRecipientRepository interface {
+	Create(ctx context.Context, recipient *entities.MessageRecipient) error
+	GetByID(ctx context.Context, id string) (*entities.MessageRecipient, error)
+	GetByBusinessID(ctx context.Context, businessID string) ([]*entities.MessageRecipient, error)
+	Update(ctx context.Context, recipient *entities.MessageRecipient) error
+	Delete(ctx context.Context, id string) error
+}
+
+// InboxRepository defines the interface for inbox follow-up state and comments
+type InboxRepository interface {
+	GetState(ctx context.Context, businessID string, itemType entities.InboxItemType, sourceID string) (*entities.InboxItemState, error)
+	GetStates(ctx context.Context, businessID string, sourceIDs []string) ([]*entities.InboxItemState, error)
+	SaveState(ctx context.Context, state *entities.InboxItemState) error
+	CreateComment(ctx context.Context, comment *entities.InboxComment) error
+	GetComments(ctx context.Context, businessID string, itemType entities.InboxItemType, sourceID string) ([]*entities.InboxComment, error)
+	CountComments(ctx context.Context, businessID string, sourceIDs []string) (map[string]int, error)
+}
+
+// AuditFilter narrows an audit log listing. Empty fields match every entry.
+type AuditFilter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+// AuditRepository defines the interface for the append-only audit log
+type AuditRepository interface {
+	Create(ctx context.Context, entry *entities.AuditEntry) error
+	// List returns the entries of a business, or of every business and the
+	// platform itself when businessID is empty
+	List(ctx context.Context, businessID string, filter AuditFilter, limit, offset int) ([]*entities.AuditEntry, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+}
+
+// WebhookEventFilter narrows a webhook event listing. Empty fields match
+// every event.
+type WebhookEventFilter struct {
+	ProviderCallID string
+	EventType      string
+	Status         string
+}
+
+// WebhookEventRepository defines the interface for the log of received
+// provider webhooks
+type WebhookEventRepository interface {
+	Create(ctx context.Context, event *entities.WebhookEvent) error
+	GetByID(ctx context.Context, id string) (*entities.WebhookEvent, error)
+	List(ctx context.Context, filter WebhookEventFilter, limit, offset int) ([]*entities.WebhookEvent, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int, error)
+}
+
+// OnboardingRepository defines the interface for businesses' onboarding
+// progress
+type OnboardingRepository interface {
+	Get(ctx context.Context, businessID string) (*entities.Onboarding, error)
+	GetForUpdate(ctx context.Context, businessID string) (*entities.Onboarding, error)
+	Create(ctx context.Context, onboarding *entities.Onboarding) error
+	Save(ctx context.Context, onboarding *entities.Onboarding) error
+	RecordProviderID(ctx context.Context, businessID string, step entities.OnboardingStepName, providerID string) error
+	ClearProviderID(ctx context.Context, businessID string, step entities.OnboardingStepName) error
+}
+
+// AssistantTemplateRepository defines the interface for businesses' changes
+// to their assistant templates
+type AssistantTemplateRepository interface {
+	GetOverride(ctx context.Context, businessID string) (*entities.AssistantTemplateOverride, error)
+	SaveOverride(ctx context.Context, override *entities.AssistantTemplateOverride) error
+	DeleteOverride(ctx context.Context, businessID string) error
+}
+
+// BusinessUsage is the call statistics of one business
+type BusinessUsage struct {
+	BusinessID   string `json:"business_id"`
+	BusinessName string `json:"business_name"`
+	CallStats
+}
+
 // CallStats represents aggregated call statistics
 type CallStats struct {
 	TotalCalls       int     `json:"total_calls"`
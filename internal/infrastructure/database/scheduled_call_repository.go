@@ -0,0 +1,223 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type ScheduledCallRepositoryImpl struct {
+	db *DB
+}
+
+func NewScheduledCallRepository(db *DB) ScheduledCallRepository {
+	return &ScheduledCallRepositoryImpl{db: db}
+}
+
+const scheduledCallColumns = `id, business_id, phone, assistant_id, reason, source, status, scheduled_for,
+			attempts, last_error, call_id, origin_call_id, created_by, created_at, updated_at`
+
+func (r *ScheduledCallRepositoryImpl) Create(ctx context.Context, scheduled *entities.ScheduledCall) error {
+	scheduled.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO scheduled_calls (` + scheduledCallColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		scheduled.ID,
+		scheduled.BusinessID,
+		scheduled.Phone,
+		scheduled.AssistantID,
+		scheduled.Reason,
+		scheduled.Source,
+		scheduled.Status,
+		scheduled.ScheduledFor,
+		scheduled.Attempts,
+		scheduled.LastError,
+		scheduled.CallID,
+		scheduled.OriginCallID,
+		scheduled.CreatedBy,
+		scheduled.CreatedAt,
+		scheduled.UpdatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create scheduled call")
+	}
+
+	return nil
+}
+
+func (r *ScheduledCallRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.ScheduledCall, error) {
+	query := `SELECT ` + scheduledCallColumns + ` FROM scheduled_calls WHERE id = $1`
+
+	scheduled, err := r.scanScheduledCall(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("scheduled call", id)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get scheduled call")
+	}
+
+	return scheduled, nil
+}
+
+// GetByBusinessID lists a business's scheduled calls, soonest first. An empty
+// status returns all of them.
+func (r *ScheduledCallRepositoryImpl) GetByBusinessID(ctx context.Context, businessID string, status entities.ScheduledCallStatus, limit, offset int) ([]*entities.ScheduledCall, error) {
+	query := `
+		SELECT ` + scheduledCallColumns + `
+		FROM scheduled_calls
+		WHERE business_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY scheduled_for, id
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID, string(status), limit, offset)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get scheduled calls by business")
+	}
+	defer rows.Close()
+
+	return r.scanScheduledCalls(rows)
+}
+
+// GetDue returns scheduled calls across all businesses whose time has come,
+// oldest first
+func (r *ScheduledCallRepositoryImpl) GetDue(ctx context.Context, now time.Time, limit int) ([]*entities.ScheduledCall, error) {
+	query := `
+		SELECT ` + scheduledCallColumns + `
+		FROM scheduled_calls
+		WHERE status = 'scheduled' AND scheduled_for <= $1
+		ORDER BY scheduled_for
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get due scheduled calls")
+	}
+	defer rows.Close()
+
+	return r.scanScheduledCalls(rows)
+}
+
+// GetByCallID returns the scheduled call that placed the given call, or nil
+// if the call was not scheduled
+func (r *ScheduledCallRepositoryImpl) GetByCallID(ctx context.Context, callID string) (*entities.ScheduledCall, error) {
+	query := `SELECT ` + scheduledCallColumns + ` FROM scheduled_calls WHERE call_id = $1`
+
+	scheduled, err := r.scanScheduledCall(r.db.QueryRowContext(ctx, query, callID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get scheduled call by call")
+	}
+
+	return scheduled, nil
+}
+
+// HasPending reports whether a call to the number is already waiting to be placed
+func (r *ScheduledCallRepositoryImpl) HasPending(ctx context.Context, businessID, phone string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM scheduled_calls
+			WHERE business_id = $1 AND phone = $2 AND status = 'scheduled'
+		)
+	`
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, query, businessID, phone).Scan(&exists); err != nil {
+		return false, errors.NewDatabaseError(err, "failed to check pending scheduled calls")
+	}
+
+	return exists, nil
+}
+
+func (r *ScheduledCallRepositoryImpl) Update(ctx context.Context, scheduled *entities.ScheduledCall) error {
+	query := `
+		UPDATE scheduled_calls
+		SET assistant_id = $2, reason = $3, status = $4, scheduled_for = $5, attempts = $6,
+			last_error = $7, call_id = $8, updated_at = $9
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		scheduled.ID,
+		scheduled.AssistantID,
+		scheduled.Reason,
+		scheduled.Status,
+		scheduled.ScheduledFor,
+		scheduled.Attempts,
+		scheduled.LastError,
+		scheduled.CallID,
+		scheduled.UpdatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to update scheduled call")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("scheduled call", scheduled.ID)
+	}
+
+	return nil
+}
+
+func (r *ScheduledCallRepositoryImpl) scanScheduledCall(row rowScanner) (*entities.ScheduledCall, error) {
+	scheduled := &entities.ScheduledCall{}
+
+	err := row.Scan(
+		&scheduled.ID,
+		&scheduled.BusinessID,
+		&scheduled.Phone,
+		&scheduled.AssistantID,
+		&scheduled.Reason,
+		&scheduled.Source,
+		&scheduled.Status,
+		&scheduled.ScheduledFor,
+		&scheduled.Attempts,
+		&scheduled.LastError,
+		&scheduled.CallID,
+		&scheduled.OriginCallID,
+		&scheduled.CreatedBy,
+		&scheduled.CreatedAt,
+		&scheduled.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scheduled, nil
+}
+
+func (r *ScheduledCallRepositoryImpl) scanScheduledCalls(rows *sql.Rows) ([]*entities.ScheduledCall, error) {
+	var scheduledCalls []*entities.ScheduledCall
+
+	for rows.Next() {
+		scheduled, err := r.scanScheduledCall(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan scheduled call")
+		}
+		scheduledCalls = append(scheduledCalls, scheduled)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate scheduled calls")
+	}
+
+	return scheduledCalls, nil
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type SessionRepositoryImpl struct {
+	db *DB
+}
+
+func NewSessionRepository(db *DB) SessionRepository {
+	return &SessionRepositoryImpl{db: db}
+}
+
+const sessionColumns = `id, user_id, business_id, family_id, token_hash, expires_at, revoked_at, replaced_by_id, sso, created_at`
+
+// Create stores a session. A session without a family starts its own.
+func (r *SessionRepositoryImpl) Create(ctx context.Context, session *entities.Session) error {
+	session.ID = uuid.New().String()
+	if session.FamilyID == "" {
+		session.FamilyID = session.ID
+	}
+
+	query := `
+		INSERT INTO sessions (` + sessionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		session.ID,
+		session.UserID,
+		session.BusinessID,
+		session.FamilyID,
+		session.TokenHash,
+		session.ExpiresAt,
+		session.RevokedAt,
+		session.ReplacedByID,
+		session.SSO,
+		session.CreatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create session")
+	}
+
+	return nil
+}
+
+func (r *SessionRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1`
+
+	session, err := r.scanSession(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("session", id)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get session")
+	}
+
+	return session, nil
+}
+
+// GetByTokenHash returns the session issued with a refresh token, or nil if
+// there is none
+func (r *SessionRepositoryImpl) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE token_hash = $1`
+
+	session, err := r.scanSession(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get session by token")
+	}
+
+	return session, nil
+}
+
+// Revoke revokes a session unless it already was, and reports whether this
+// call revoked it. Two refreshes racing on the same token therefore cannot
+// both succeed.
+func (r *SessionRepositoryImpl) Revoke(ctx context.Context, id string, replacedByID *string, now time.Time) (bool, error) {
+	query := `
+		UPDATE sessions
+		SET revoked_at = $2, replaced_by_id = $3
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, now, replacedByID)
+	if err != nil {
+		return false, errors.NewDatabaseError(err, "failed to revoke session")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *SessionRepositoryImpl) RevokeFamily(ctx context.Context, familyID string, now time.Time) error {
+	query := `UPDATE sessions SET revoked_at = $2 WHERE family_id = $1 AND revoked_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, familyID, now); err != nil {
+		return errors.NewDatabaseError(err, "failed to revoke session family")
+	}
+
+	return nil
+}
+
+// RevokeAllForUser revokes every active session of a user and returns how
+// many were revoked
+func (r *SessionRepositoryImpl) RevokeAllForUser(ctx context.Context, userID string, now time.Time) (int, error) {
+	query := `UPDATE sessions SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, userID, now)
+	if err != nil {
+		return 0, errors.NewDatabaseError(err, "failed to revoke sessions")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	return int(rowsAffected), nil
+}
+
+// RevokeAllForBusiness revokes every active session in a business and
+// returns how many were revoked
+func (r *SessionRepositoryImpl) RevokeAllForBusiness(ctx context.Context, businessID string, now time.Time) (int, error) {
+	query := `UPDATE sessions SET revoked_at = $2 WHERE business_id = $1 AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, businessID, now)
+	if err != nil {
+		return 0, errors.NewDatabaseError(err, "failed to revoke sessions")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	return int(rowsAffected), nil
+}
+
+func (r *SessionRepositoryImpl) scanSession(row rowScanner) (*entities.Session, error) {
+	session := &entities.Session{}
+	var replacedByID sql.NullString
+
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.BusinessID,
+		&session.FamilyID,
+		&session.TokenHash,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+		&replacedByID,
+		&session.SSO,
+		&session.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if replacedByID.Valid {
+		session.ReplacedByID = &replacedByID.String
+	}
+
+	return session, nil
+}
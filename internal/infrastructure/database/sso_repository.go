@@ -0,0 +1,219 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type SSORepositoryImpl struct {
+	db *DB
+}
+
+func NewSSORepository(db *DB) SSORepository {
+	return &SSORepositoryImpl{db: db}
+}
+
+// GetConnection returns the business's identity provider, or nil if it has
+// none
+func (r *SSORepositoryImpl) GetConnection(ctx context.Context, businessID string) (*entities.SSOConnection, error) {
+	query := `
+		SELECT business_id, issuer, client_id, encrypted_client_secret, allowed_domains,
+			default_role, enabled, created_at, updated_at
+		FROM sso_connections
+		WHERE business_id = $1
+	`
+
+	connection := &entities.SSOConnection{}
+	err := r.db.QueryRowContext(ctx, query, businessID).Scan(
+		&connection.BusinessID,
+		&connection.Issuer,
+		&connection.ClientID,
+		&connection.EncryptedClientSecret,
+		pq.Array(&connection.AllowedDomains),
+		&connection.DefaultRole,
+		&connection.Enabled,
+		&connection.CreatedAt,
+		&connection.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get SSO connection")
+	}
+
+	return connection, nil
+}
+
+// SaveConnection creates or replaces the business's identity provider
+func (r *SSORepositoryImpl) SaveConnection(ctx context.Context, connection *entities.SSOConnection) error {
+	query := `
+		INSERT INTO sso_connections (business_id, issuer, client_id, encrypted_client_secret,
+			allowed_domains, default_role, enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (business_id) DO UPDATE SET
+			issuer = EXCLUDED.issuer,
+			client_id = EXCLUDED.client_id,
+			encrypted_client_secret = EXCLUDED.encrypted_client_secret,
+			allowed_domains = EXCLUDED.allowed_domains,
+			default_role = EXCLUDED.default_role,
+			enabled = EXCLUDED.enabled,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		connection.BusinessID,
+		connection.Issuer,
+		connection.ClientID,
+		connection.EncryptedClientSecret,
+		pq.Array(connection.AllowedDomains),
+		connection.DefaultRole,
+		connection.Enabled,
+		connection.CreatedAt,
+		connection.UpdatedAt,
+	)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to save SSO connection")
+	}
+
+	return nil
+}
+
+func (r *SSORepositoryImpl) DeleteConnection(ctx context.Context, businessID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM sso_connections WHERE business_id = $1`, businessID)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to delete SSO connection")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("SSO connection", businessID)
+	}
+
+	return nil
+}
+
+// CreateLoginState stores a pending sign-in and clears out expired ones
+func (r *SSORepositoryImpl) CreateLoginState(ctx context.Context, state *entities.SSOLoginState) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM sso_login_states WHERE expires_at < $1`, state.CreatedAt); err != nil {
+		return errors.NewDatabaseError(err, "failed to delete expired SSO sign-ins")
+	}
+
+	query := `
+		INSERT INTO sso_login_states (state_hash, business_id, code_verifier, nonce, user_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		state.StateHash,
+		state.BusinessID,
+		state.CodeVerifier,
+		state.Nonce,
+		sql.NullString{String: state.UserID, Valid: state.UserID != ""},
+		state.ExpiresAt,
+		state.CreatedAt,
+	)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create SSO sign-in")
+	}
+
+	return nil
+}
+
+// ConsumeLoginState deletes and returns the pending sign-in with the given
+// state hash, or nil if there is none. Deleting it makes the state single
+// use even under concurrent callbacks.
+func (r *SSORepositoryImpl) ConsumeLoginState(ctx context.Context, stateHash string) (*entities.SSOLoginState, error) {
+	query := `
+		DELETE FROM sso_login_states
+		WHERE state_hash = $1
+		RETURNING state_hash, business_id, code_verifier, nonce, user_id, expires_at, created_at
+	`
+
+	state := &entities.SSOLoginState{}
+	var userID sql.NullString
+	err := r.db.QueryRowContext(ctx, query, stateHash).Scan(
+		&state.StateHash,
+		&state.BusinessID,
+		&state.CodeVerifier,
+		&state.Nonce,
+		&userID,
+		&state.ExpiresAt,
+		&state.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to consume SSO sign-in")
+	}
+	state.UserID = userID.String
+
+	return state, nil
+}
+
+// GetIdentity returns the user linked to the identity provider account, or
+// nil if it is not linked
+func (r *SSORepositoryImpl) GetIdentity(ctx context.Context, businessID, issuer, subject string) (*entities.SSOIdentity, error) {
+	query := `
+		SELECT business_id, issuer, subject, user_id, created_at
+		FROM sso_identities
+		WHERE business_id = $1 AND issuer = $2 AND subject = $3
+	`
+
+	identity := &entities.SSOIdentity{}
+	err := r.db.QueryRowContext(ctx, query, businessID, issuer, subject).Scan(
+		&identity.BusinessID,
+		&identity.Issuer,
+		&identity.Subject,
+		&identity.UserID,
+		&identity.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get SSO identity")
+	}
+
+	return identity, nil
+}
+
+// CreateIdentity links an identity provider account to a user. An account
+// can only be linked to one user.
+func (r *SSORepositoryImpl) CreateIdentity(ctx context.Context, identity *entities.SSOIdentity) error {
+	query := `
+		INSERT INTO sso_identities (business_id, issuer, subject, user_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		identity.BusinessID,
+		identity.Issuer,
+		identity.Subject,
+		identity.UserID,
+		identity.CreatedAt,
+	)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create SSO identity")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+	if rowsAffected == 0 {
+		return errors.NewAlreadyExistsError("SSO identity", "subject", identity.Subject)
+	}
+
+	return nil
+}
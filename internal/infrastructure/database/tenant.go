@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// tenantTxKey carries the transaction opened by InTenant
+type tenantTxKey struct{}
+
+// unscopedKey marks work that is not scoped to one business
+type unscopedKey struct{}
+
+// Unscoped marks ctx as system work that is not scoped to one business:
+// sign-in, the provider webhook, background workers and the /me and /admin
+// routes. Repositories called with it use connections that set
+// app.bypass_rls, which the row-level security policies let see every
+// business. Work that is neither scoped nor marked sees no tenant rows at all.
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey{}, true)
+}
+
+func isUnscoped(ctx context.Context) bool {
+	unscoped, _ := ctx.Value(unscopedKey{}).(bool)
+	return unscoped
+}
+
+// Unscoped is database.Unscoped, for callers that only hold a DB
+func (db *DB) Unscoped(ctx context.Context) context.Context {
+	return Unscoped(ctx)
+}
+
+// InTenant runs fn in a transaction scoped to the business. The
+// row-level security policies (see migrations/021_row_level_security)
+// limit the transaction to the business's rows and, for an agency, its
+// children's. Repositories called with the context passed to fn run inside
+// the transaction, which commits when fn returns nil and rolls back
+// otherwise. An Unscoped mark on ctx does not carry into fn.
+func (db *DB) InTenant(ctx context.Context, businessID string, fn func(ctx context.Context) error) (err error) {
+	if businessID == "" {
+		return fmt.Errorf("tenant scope requires a business")
+	}
+	if tenantTx(ctx) != nil {
+		return fmt.Errorf("already in a tenant scope")
+	}
+
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin tenant transaction: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// The setting is local to the transaction, so the pooled connection is
+	// unscoped again once it ends
+	if _, err = tx.ExecContext(ctx, `SELECT set_config('app.current_business_id', $1, true)`, businessID); err != nil {
+		return fmt.Errorf("failed to scope transaction: %w", err)
+	}
+
+	ctx = context.WithValue(ctx, unscopedKey{}, false)
+	if err = fn(context.WithValue(ctx, tenantTxKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tenant transaction: %w", err)
+	}
+	return nil
+}
+
+// Detached runs fn in a tenant transaction of its own, on another
+// connection, even when ctx is already in one. It commits when fn returns,
+// whatever then happens to the request's transaction, so it suits recording
+// what cannot be undone, such as resources created with a provider. fn must
+// not write rows the request's transaction has locked, or it waits forever.
+func (db *DB) Detached(ctx context.Context, businessID string, fn func(ctx context.Context) error) error {
+	return db.InTenant(context.WithValue(ctx, tenantTxKey{}, (*sql.Tx)(nil)), businessID, fn)
+}
+
+func tenantTx(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(tenantTxKey{}).(*sql.Tx)
+	return tx
+}
+
+// pool returns the connections for work outside a tenant transaction: the
+// system pool for Unscoped work, otherwise the application's, on which the
+// row-level security policies show no tenant rows
+func (db *DB) pool(ctx context.Context) *sql.DB {
+	if isUnscoped(ctx) && db.system != nil {
+		return db.system
+	}
+	return db.DB
+}
+
+// ExecContext runs in the context's tenant transaction, if any
+func (db *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if tx := tenantTx(ctx); tx != nil {
+		return tx.ExecContext(ctx, query, args...)
+	}
+	return db.pool(ctx).ExecContext(ctx, query, args...)
+}
+
+// QueryContext runs in the context's tenant transaction, if any
+func (db *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if tx := tenantTx(ctx); tx != nil {
+		return tx.QueryContext(ctx, query, args...)
+	}
+	return db.pool(ctx).QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext runs in the context's tenant transaction, if any
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if tx := tenantTx(ctx); tx != nil {
+		return tx.QueryRowContext(ctx, query, args...)
+	}
+	return db.pool(ctx).QueryRowContext(ctx, query, args...)
+}
+
+// Tx is a repository transaction. Inside a tenant scope it is a savepoint
+// of the tenant transaction, so it stays scoped, and committing it only
+// releases the savepoint.
+type Tx struct {
+	*sql.Tx
+	savepoint bool
+	done      bool
+}
+
+// BeginTx starts a transaction, or a savepoint inside the context's tenant
+// transaction
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	if tx := tenantTx(ctx); tx != nil {
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT repository_tx`); err != nil {
+			return nil, err
+		}
+		return &Tx{Tx: tx, savepoint: true}, nil
+	}
+
+	tx, err := db.pool(ctx).BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx}, nil
+}
+
+func (tx *Tx) Commit() error {
+	if !tx.savepoint {
+		return tx.Tx.Commit()
+	}
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+
+	_, err := tx.Tx.Exec(`RELEASE SAVEPOINT repository_tx`)
+	return err
+}
+
+func (tx *Tx) Rollback() error {
+	if !tx.savepoint {
+		return tx.Tx.Rollback()
+	}
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+
+	if _, err := tx.Tx.Exec(`ROLLBACK TO SAVEPOINT repository_tx`); err != nil {
+		return err
+	}
+	_, err := tx.Tx.Exec(`RELEASE SAVEPOINT repository_tx`)
+	return err
+}
@@ -0,0 +1,326 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type TransferDestinationRepositoryImpl struct {
+	db *DB
+}
+
+func NewTransferDestinationRepository(db *DB) TransferDestinationRepository {
+	return &TransferDestinationRepositoryImpl{db: db}
+}
+
+const transferDestinationColumns = `id, business_id, name, description, phone, mode, active, created_at, updated_at`
+
+func (r *TransferDestinationRepositoryImpl) Create(ctx context.Context, destination *entities.TransferDestination) error {
+	destination.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO transfer_destinations (` + transferDestinationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		destination.ID,
+		destination.BusinessID,
+		destination.Name,
+		destination.Description,
+		destination.Phone,
+		destination.Mode,
+		destination.Active,
+		destination.CreatedAt,
+		destination.UpdatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create transfer destination")
+	}
+
+	return nil
+}
+
+func (r *TransferDestinationRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.TransferDestination, error) {
+	query := `SELECT ` + transferDestinationColumns + ` FROM transfer_destinations WHERE id = $1`
+
+	destination, err := r.scanDestination(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("transfer destination", id)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get transfer destination")
+	}
+
+	return destination, nil
+}
+
+func (r *TransferDestinationRepositoryImpl) GetByBusinessID(ctx context.Context, businessID string) ([]*entities.TransferDestination, error) {
+	query := `
+		SELECT ` + transferDestinationColumns + `
+		FROM transfer_destinations
+		WHERE business_id = $1
+		ORDER BY name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get transfer destinations by business")
+	}
+	defer rows.Close()
+
+	var destinations []*entities.TransferDestination
+	for rows.Next() {
+		destination, err := r.scanDestination(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan transfer destination")
+		}
+		destinations = append(destinations, destination)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate transfer destinations")
+	}
+
+	return destinations, nil
+}
+
+// GetByName returns the business's destination with the given name, or nil
+// if there is none
+func (r *TransferDestinationRepositoryImpl) GetByName(ctx context.Context, businessID, name string) (*entities.TransferDestination, error) {
+	query := `SELECT ` + transferDestinationColumns + ` FROM transfer_destinations WHERE business_id = $1 AND name = $2`
+
+	destination, err := r.scanDestination(r.db.QueryRowContext(ctx, query, businessID, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get transfer destination by name")
+	}
+
+	return destination, nil
+}
+
+func (r *TransferDestinationRepositoryImpl) Update(ctx context.Context, destination *entities.TransferDestination) error {
+	query := `
+		UPDATE transfer_destinations
+		SET name = $2, description = $3, phone = $4, mode = $5, active = $6, updated_at = $7
+		WHERE id = $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		destination.ID,
+		destination.Name,
+		destination.Description,
+		destination.Phone,
+		destination.Mode,
+		destination.Active,
+		destination.UpdatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to update transfer destination")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("transfer destination", destination.ID)
+	}
+
+	return nil
+}
+
+func (r *TransferDestinationRepositoryImpl) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM transfer_destinations WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to delete transfer destination")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("transfer destination", id)
+	}
+
+	return nil
+}
+
+func (r *TransferDestinationRepositoryImpl) scanDestination(row rowScanner) (*entities.TransferDestination, error) {
+	destination := &entities.TransferDestination{}
+
+	err := row.Scan(
+		&destination.ID,
+		&destination.BusinessID,
+		&destination.Name,
+		&destination.Description,
+		&destination.Phone,
+		&destination.Mode,
+		&destination.Active,
+		&destination.CreatedAt,
+		&destination.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return destination, nil
+}
+
+type OnCallShiftRepositoryImpl struct {
+	db *DB
+}
+
+func NewOnCallShiftRepository(db *DB) OnCallShiftRepository {
+	return &OnCallShiftRepositoryImpl{db: db}
+}
+
+const onCallShiftColumns = `id, business_id, destination_id, name, phone, starts_at, ends_at, created_at`
+
+func (r *OnCallShiftRepositoryImpl) Create(ctx context.Context, shift *entities.OnCallShift) error {
+	shift.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO on_call_shifts (` + onCallShiftColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		shift.ID,
+		shift.BusinessID,
+		shift.DestinationID,
+		shift.Name,
+		shift.Phone,
+		shift.StartsAt,
+		shift.EndsAt,
+		shift.CreatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create on-call shift")
+	}
+
+	return nil
+}
+
+func (r *OnCallShiftRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.OnCallShift, error) {
+	query := `SELECT ` + onCallShiftColumns + ` FROM on_call_shifts WHERE id = $1`
+
+	shift, err := r.scanShift(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("on-call shift", id)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get on-call shift")
+	}
+
+	return shift, nil
+}
+
+// GetByDestinationID lists a destination's shifts that have not ended by
+// from, in rotation order
+func (r *OnCallShiftRepositoryImpl) GetByDestinationID(ctx context.Context, destinationID string, from time.Time) ([]*entities.OnCallShift, error) {
+	query := `
+		SELECT ` + onCallShiftColumns + `
+		FROM on_call_shifts
+		WHERE destination_id = $1 AND ends_at > $2
+		ORDER BY starts_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, destinationID, from)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get on-call shifts")
+	}
+	defer rows.Close()
+
+	var shifts []*entities.OnCallShift
+	for rows.Next() {
+		shift, err := r.scanShift(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan on-call shift")
+		}
+		shifts = append(shifts, shift)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate on-call shifts")
+	}
+
+	return shifts, nil
+}
+
+// GetCurrent returns the shift on call at the given time, or nil if nobody
+// is. When shifts overlap the one that started last wins.
+func (r *OnCallShiftRepositoryImpl) GetCurrent(ctx context.Context, destinationID string, at time.Time) (*entities.OnCallShift, error) {
+	query := `
+		SELECT ` + onCallShiftColumns + `
+		FROM on_call_shifts
+		WHERE destination_id = $1 AND starts_at <= $2 AND ends_at > $2
+		ORDER BY starts_at DESC
+		LIMIT 1
+	`
+
+	shift, err := r.scanShift(r.db.QueryRowContext(ctx, query, destinationID, at))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get current on-call shift")
+	}
+
+	return shift, nil
+}
+
+func (r *OnCallShiftRepositoryImpl) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM on_call_shifts WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to delete on-call shift")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("on-call shift", id)
+	}
+
+	return nil
+}
+
+func (r *OnCallShiftRepositoryImpl) scanShift(row rowScanner) (*entities.OnCallShift, error) {
+	shift := &entities.OnCallShift{}
+
+	err := row.Scan(
+		&shift.ID,
+		&shift.BusinessID,
+		&shift.DestinationID,
+		&shift.Name,
+		&shift.Phone,
+		&shift.StartsAt,
+		&shift.EndsAt,
+		&shift.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return shift, nil
+}
@@ -18,20 +18,32 @@ func NewUserRepository(db *DB) UserRepository {
 	return &UserRepositoryImpl{db: db}
 }
 
+const userColumns = `id, business_id, email, password_hash, role, email_verified_at, deactivated_at, platform_admin, created_at`
+
+// Create stores the user and their membership of their home business
 func (r *UserRepositoryImpl) Create(ctx context.Context, user *entities.User) error {
 	user.ID = uuid.New().String()
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO users (id, business_id, email, password_hash, role, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO users (` + userColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 	`
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		user.ID,
 		user.BusinessID,
 		user.Email,
 		user.PasswordHash,
 		user.Role,
+		user.EmailVerifiedAt,
+		user.DeactivatedAt,
+		user.PlatformAdmin,
 		user.CreatedAt,
 	)
 
@@ -39,25 +51,29 @@ func (r *UserRepositoryImpl) Create(ctx context.Context, user *entities.User) er
 		return errors.NewDatabaseError(err, "failed to create user")
 	}
 
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO business_memberships (user_id, business_id, role, created_at)
+		VALUES ($1, $2, $3, $4)
+	`, user.ID, user.BusinessID, user.Role, user.CreatedAt)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create membership")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewDatabaseError(err, "failed to commit transaction")
+	}
+
 	return nil
 }
 
 func (r *UserRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.User, error) {
 	query := `
-		SELECT id, business_id, email, password_hash, role, created_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1
 	`
 
-	user := &entities.User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID,
-		&user.BusinessID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.Role,
-		&user.CreatedAt,
-	)
+	user, err := r.scanUser(r.db.QueryRowContext(ctx, query, id))
 
 	if err == sql.ErrNoRows {
 		return nil, errors.NewNotFoundError("user", id)
@@ -71,20 +87,12 @@ func (r *UserRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.
 
 func (r *UserRepositoryImpl) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
 	query := `
-		SELECT id, business_id, email, password_hash, role, created_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE email = $1
 	`
 
-	user := &entities.User{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
-		&user.ID,
-		&user.BusinessID,
-		&user.Email,
-		&user.PasswordHash,
-		&user.Role,
-		&user.CreatedAt,
-	)
+	user, err := r.scanUser(r.db.QueryRowContext(ctx, query, email))
 
 	if err == sql.ErrNoRows {
 		return nil, errors.NewNotFoundError("user", email)
@@ -98,7 +106,7 @@ func (r *UserRepositoryImpl) GetByEmail(ctx context.Context, email string) (*ent
 
 func (r *UserRepositoryImpl) GetByBusinessID(ctx context.Context, businessID string) ([]*entities.User, error) {
 	query := `
-		SELECT id, business_id, email, password_hash, role, created_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE business_id = $1
 		ORDER BY created_at DESC
@@ -113,15 +121,7 @@ func (r *UserRepositoryImpl) GetByBusinessID(ctx context.Context, businessID str
 	var users []*entities.User
 
 	for rows.Next() {
-		user := &entities.User{}
-		err := rows.Scan(
-			&user.ID,
-			&user.BusinessID,
-			&user.Email,
-			&user.PasswordHash,
-			&user.Role,
-			&user.CreatedAt,
-		)
+		user, err := r.scanUser(rows)
 		if err != nil {
 			return nil, errors.NewDatabaseError(err, "failed to scan user")
 		}
@@ -135,18 +135,31 @@ func (r *UserRepositoryImpl) GetByBusinessID(ctx context.Context, businessID str
 	return users, nil
 }
 
+// Update saves the user's account and their role in user.BusinessID. For a
+// user acting in another business (see User.AsMember) only that
+// membership's role changes; the home business is never changed.
 func (r *UserRepositoryImpl) Update(ctx context.Context, user *entities.User) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE users
-		SET email = $2, password_hash = $3, role = $4
+		SET email = $2, password_hash = $3, email_verified_at = $5, deactivated_at = $6,
+			role = CASE WHEN business_id = $7 THEN $4 ELSE role END
 		WHERE id = $1
 	`
 
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := tx.ExecContext(ctx, query,
 		user.ID,
 		user.Email,
 		user.PasswordHash,
 		user.Role,
+		user.EmailVerifiedAt,
+		user.DeactivatedAt,
+		user.BusinessID,
 	)
 
 	if err != nil {
@@ -162,6 +175,18 @@ func (r *UserRepositoryImpl) Update(ctx context.Context, user *entities.User) er
 		return errors.NewNotFoundError("user", user.ID)
 	}
 
+	_, err = tx.ExecContext(ctx, `
+		UPDATE business_memberships SET role = $3
+		WHERE user_id = $1 AND business_id = $2
+	`, user.ID, user.BusinessID, user.Role)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to update membership")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.NewDatabaseError(err, "failed to commit transaction")
+	}
+
 	return nil
 }
 
@@ -185,6 +210,166 @@ func (r *UserRepositoryImpl) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// memberColumns selects a user as a member of business_memberships m: with
+// the membership's business and role in place of their home business
+const memberColumns = `u.id, m.business_id, u.email, u.password_hash, m.role, u.email_verified_at, u.deactivated_at, u.platform_admin, u.created_at`
+
+// GetMember returns the user acting in the business, with their role there,
+// or nil if they are not a member
+func (r *UserRepositoryImpl) GetMember(ctx context.Context, businessID, userID string) (*entities.User, error) {
+	query := `
+		SELECT ` + memberColumns + `
+		FROM users u
+		JOIN business_memberships m ON m.user_id = u.id
+		WHERE u.id = $1 AND m.business_id = $2
+	`
+
+	user, err := r.scanUser(r.db.QueryRowContext(ctx, query, userID, businessID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get member")
+	}
+
+	return user, nil
+}
+
+// GetMembers returns every member of the business, including users whose
+// home business is another one, with their role in this business
+func (r *UserRepositoryImpl) GetMembers(ctx context.Context, businessID string) ([]*entities.User, error) {
+	query := `
+		SELECT ` + memberColumns + `
+		FROM users u
+		JOIN business_memberships m ON m.user_id = u.id
+		WHERE m.business_id = $1
+		ORDER BY u.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, businessID)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get members")
+	}
+	defer rows.Close()
+
+	var users []*entities.User
+
+	for rows.Next() {
+		user, err := r.scanUser(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan member")
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate members")
+	}
+
+	return users, nil
+}
+
+// GetMemberships returns the businesses the user is a member of, oldest
+// first
+func (r *UserRepositoryImpl) GetMemberships(ctx context.Context, userID string) ([]*entities.Membership, error) {
+	query := `
+		SELECT user_id, business_id, role, created_at
+		FROM business_memberships
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get memberships")
+	}
+	defer rows.Close()
+
+	var memberships []*entities.Membership
+
+	for rows.Next() {
+		membership := &entities.Membership{}
+		if err := rows.Scan(&membership.UserID, &membership.BusinessID, &membership.Role, &membership.CreatedAt); err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan membership")
+		}
+		memberships = append(memberships, membership)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate memberships")
+	}
+
+	return memberships, nil
+}
+
+// SaveMembership adds the user to the business or changes their role there
+func (r *UserRepositoryImpl) SaveMembership(ctx context.Context, membership *entities.Membership) error {
+	query := `
+		INSERT INTO business_memberships (user_id, business_id, role, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, business_id) DO UPDATE SET role = EXCLUDED.role
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		membership.UserID,
+		membership.BusinessID,
+		membership.Role,
+		membership.CreatedAt,
+	)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to save membership")
+	}
+
+	return nil
+}
+
+// DeleteMembership removes the user from a business other than their home
+// business
+func (r *UserRepositoryImpl) DeleteMembership(ctx context.Context, userID, businessID string) error {
+	query := `
+		DELETE FROM business_memberships m
+		USING users u
+		WHERE m.user_id = u.id AND m.user_id = $1 AND m.business_id = $2 AND u.business_id <> $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, businessID)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to delete membership")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewNotFoundError("membership", userID)
+	}
+
+	return nil
+}
+
+func (r *UserRepositoryImpl) scanUser(row rowScanner) (*entities.User, error) {
+	user := &entities.User{}
+
+	err := row.Scan(
+		&user.ID,
+		&user.BusinessID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.Role,
+		&user.EmailVerifiedAt,
+		&user.DeactivatedAt,
+		&user.PlatformAdmin,
+		&user.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
 // HashPassword hashes a plain text password
 func HashPassword(password string) (string, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+type UserTokenRepositoryImpl struct {
+	db *DB
+}
+
+func NewUserTokenRepository(db *DB) UserTokenRepository {
+	return &UserTokenRepositoryImpl{db: db}
+}
+
+const userTokenColumns = `id, user_id, purpose, token_hash, expires_at, used_at, created_at`
+
+func (r *UserTokenRepositoryImpl) Create(ctx context.Context, token *entities.UserToken) error {
+	token.ID = uuid.New().String()
+
+	query := `
+		INSERT INTO user_tokens (` + userTokenColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		token.ID,
+		token.UserID,
+		token.Purpose,
+		token.TokenHash,
+		token.ExpiresAt,
+		token.UsedAt,
+		token.CreatedAt,
+	)
+
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create user token")
+	}
+
+	return nil
+}
+
+// GetByTokenHash returns the token with the given hash, or nil if there is
+// none
+func (r *UserTokenRepositoryImpl) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.UserToken, error) {
+	query := `SELECT ` + userTokenColumns + ` FROM user_tokens WHERE token_hash = $1`
+
+	token := &entities.UserToken{}
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Purpose,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get user token")
+	}
+
+	return token, nil
+}
+
+// Update marks a token used. The update only applies while the token is
+// unused, so a token cannot be redeemed twice concurrently.
+func (r *UserTokenRepositoryImpl) Update(ctx context.Context, token *entities.UserToken) error {
+	query := `
+		UPDATE user_tokens
+		SET used_at = $2
+		WHERE id = $1 AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, token.ID, token.UsedAt)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to update user token")
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	if rowsAffected == 0 {
+		return errors.NewUnauthorizedError("invalid or expired token")
+	}
+
+	return nil
+}
+
+// InvalidateForUser uses up a user's outstanding tokens for a purpose, so
+// only the most recently issued one works
+func (r *UserTokenRepositoryImpl) InvalidateForUser(ctx context.Context, userID string, purpose entities.UserTokenPurpose, now time.Time) error {
+	query := `UPDATE user_tokens SET used_at = $3 WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, purpose, now); err != nil {
+		return errors.NewDatabaseError(err, "failed to invalidate user tokens")
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+)
+
+const webhookEventColumns = `id, event_type, provider_call_id, status, error, payload, received_at`
+
+type WebhookEventRepositoryImpl struct {
+	db *DB
+}
+
+func NewWebhookEventRepository(db *DB) WebhookEventRepository {
+	return &WebhookEventRepositoryImpl{db: db}
+}
+
+func (r *WebhookEventRepositoryImpl) Create(ctx context.Context, event *entities.WebhookEvent) error {
+	event.ID = uuid.New().String()
+
+	var payload interface{}
+	if len(event.Payload) > 0 {
+		payload = string(event.Payload)
+	}
+
+	query := `
+		INSERT INTO webhook_events (` + webhookEventColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6::jsonb, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		event.ID,
+		event.EventType,
+		nullableString(event.ProviderCallID),
+		event.Status,
+		nullableString(event.Error),
+		payload,
+		event.ReceivedAt,
+	)
+	if err != nil {
+		return errors.NewDatabaseError(err, "failed to create webhook event")
+	}
+
+	return nil
+}
+
+func (r *WebhookEventRepositoryImpl) GetByID(ctx context.Context, id string) (*entities.WebhookEvent, error) {
+	query := `SELECT ` + webhookEventColumns + ` FROM webhook_events WHERE id = $1`
+
+	event, err := r.scanWebhookEvent(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, errors.NewNotFoundError("webhook event", id)
+	}
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to get webhook event")
+	}
+
+	return event, nil
+}
+
+// List returns a page of the received webhooks, newest first
+func (r *WebhookEventRepositoryImpl) List(ctx context.Context, filter WebhookEventFilter, limit, offset int) ([]*entities.WebhookEvent, error) {
+	query := `
+		SELECT ` + webhookEventColumns + `
+		FROM webhook_events
+		WHERE ($1 = '' OR provider_call_id = $1)
+			AND ($2 = '' OR event_type = $2)
+			AND ($3 = '' OR status = $3)
+		ORDER BY received_at DESC
+		LIMIT $4 OFFSET $5
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, filter.ProviderCallID, filter.EventType, filter.Status, limit, offset)
+	if err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to list webhook events")
+	}
+	defer rows.Close()
+
+	var events []*entities.WebhookEvent
+	for rows.Next() {
+		event, err := r.scanWebhookEvent(rows)
+		if err != nil {
+			return nil, errors.NewDatabaseError(err, "failed to scan webhook event")
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.NewDatabaseError(err, "failed to iterate webhook events")
+	}
+
+	return events, nil
+}
+
+// DeleteBefore removes the events received before before and returns how
+// many were removed
+func (r *WebhookEventRepositoryImpl) DeleteBefore(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_events WHERE received_at < $1`, before)
+	if err != nil {
+		return 0, errors.NewDatabaseError(err, "failed to delete webhook events")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.NewDatabaseError(err, "failed to get rows affected")
+	}
+
+	return int(deleted), nil
+}
+
+func (r *WebhookEventRepositoryImpl) scanWebhookEvent(row rowScanner) (*entities.WebhookEvent, error) {
+	event := &entities.WebhookEvent{}
+	var providerCallID, errorMessage sql.NullString
+	var payload []byte
+
+	err := row.Scan(
+		&event.ID,
+		&event.EventType,
+		&providerCallID,
+		&event.Status,
+		&errorMessage,
+		&payload,
+		&event.ReceivedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	event.ProviderCallID = providerCallID.String
+	event.Error = errorMessage.String
+	event.Payload = payload
+
+	return event, nil
+}
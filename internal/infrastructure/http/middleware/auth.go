@@ -8,32 +8,65 @@ import (
 
 	"github.com/CallPilotReceptionist/internal/application/dto"
 	"github.com/CallPilotReceptionist/internal/application/services"
+	"github.com/CallPilotReceptionist/internal/domain/entities"
+	"github.com/CallPilotReceptionist/internal/domain/errors"
 	"github.com/CallPilotReceptionist/pkg/logger"
 )
 
 type contextKey string
 
 const (
-	UserIDKey     contextKey = "user_id"
-	BusinessIDKey contextKey = "business_id"
-	EmailKey      contextKey = "email"
-	RoleKey       contextKey = "role"
+	UserIDKey           contextKey = "user_id"
+	BusinessIDKey       contextKey = "business_id"
+	EmailKey            contextKey = "email"
+	RoleKey             contextKey = "role"
+	SessionIDKey        contextKey = "session_id"
+	APIKeyIDKey         contextKey = "api_key_id"
+	ScopesKey           contextKey = "scopes"
+	UnverifiedKey       contextKey = "unverified"
+	MFASetupRequiredKey contextKey = "mfa_setup_required"
+	AgencyIDKey         contextKey = "agency_id"
+	PlatformAdminKey    contextKey = "platform_admin"
 )
 
 type AuthMiddleware struct {
-	authService *services.AuthService
-	logger      *logger.Logger
+	authService   *services.AuthService
+	apiKeyService *services.APIKeyService
+	logger        *logger.Logger
 }
 
-func NewAuthMiddleware(authService *services.AuthService, log *logger.Logger) *AuthMiddleware {
+func NewAuthMiddleware(authService *services.AuthService, apiKeyService *services.APIKeyService, log *logger.Logger) *AuthMiddleware {
 	return &AuthMiddleware{
-		authService: authService,
-		logger:      log,
+		authService:   authService,
+		apiKeyService: apiKeyService,
+		logger:        log,
 	}
 }
 
+// RecordClient notes where each request came from, so that the actions it
+// leads to are audited with the caller's address and user agent
+func (m *AuthMiddleware) RecordClient(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := m.authService.ClientIP(dto.ClientInfo{
+			RemoteAddr:   r.RemoteAddr,
+			ForwardedFor: r.Header.Get("X-Forwarded-For"),
+		})
+		ctx := services.WithAuditActor(r.Context(), entities.AuditActor{
+			IP:        ip,
+			UserAgent: r.UserAgent(),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// API keys can be sent on their own header
+		if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+			m.authenticateAPIKey(w, r, next, apiKey)
+			return
+		}
+
 		// Get token from Authorization header
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
@@ -49,6 +82,10 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 		}
 
 		token := parts[1]
+		if strings.HasPrefix(token, entities.APIKeyPrefix) {
+			m.authenticateAPIKey(w, r, next, token)
+			return
+		}
 
 		// Validate token
 		claims, err := m.authService.ValidateToken(token)
@@ -60,17 +97,93 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 			return
 		}
 
+		// Otherwise a token would outlive its session, such as after logging
+		// out
+		if err := m.authService.CheckSession(r.Context(), claims); err != nil {
+			if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeUnauthorized {
+				m.respondError(w, http.StatusUnauthorized, "session has been revoked")
+				return
+			}
+			RespondError(w, err, m.logger)
+			return
+		}
+
 		// Add claims to context
 		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
 		ctx = context.WithValue(ctx, BusinessIDKey, claims.BusinessID)
 		ctx = context.WithValue(ctx, EmailKey, claims.Email)
 		ctx = context.WithValue(ctx, RoleKey, claims.Role)
+		ctx = context.WithValue(ctx, SessionIDKey, claims.SessionID)
+		ctx = context.WithValue(ctx, UnverifiedKey, claims.Unverified)
+		ctx = context.WithValue(ctx, MFASetupRequiredKey, claims.MFASetupRequired)
+		ctx = context.WithValue(ctx, AgencyIDKey, claims.AgencyID)
+		ctx = context.WithValue(ctx, PlatformAdminKey, claims.PlatformAdmin)
+		ctx = withAuditActor(ctx, r, entities.AuditActorUser, claims.UserID, claims.AgencyID)
+
+		if claims.AgencyID != "" {
+			m.logger.Info("Agency user acting in child business", map[string]interface{}{
+				"user_id":     claims.UserID,
+				"agency_id":   claims.AgencyID,
+				"business_id": claims.BusinessID,
+				"method":      r.Method,
+				"path":        r.URL.Path,
+			})
+		}
 
 		// Call next handler
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// authenticateAPIKey lets the request through on behalf of the key's
+// business. There is no user, so only the key's scopes are granted.
+func (m *AuthMiddleware) authenticateAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, rawKey string) {
+	key, err := m.apiKeyService.Authenticate(r.Context(), rawKey)
+	if err != nil {
+		m.logger.Warn("Invalid API key", map[string]interface{}{
+			"error": err.Error(),
+		})
+		if domainErr, ok := err.(*errors.DomainError); ok && domainErr.Code == errors.ErrCodeUnauthorized {
+			m.respondError(w, http.StatusUnauthorized, "invalid or expired API key")
+			return
+		}
+		RespondError(w, err, m.logger)
+		return
+	}
+
+	m.logger.Info("API key used", map[string]interface{}{
+		"api_key_id":  key.ID,
+		"business_id": key.BusinessID,
+		"method":      r.Method,
+		"path":        r.URL.Path,
+	})
+
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	ctx := context.WithValue(r.Context(), BusinessIDKey, key.BusinessID)
+	ctx = context.WithValue(ctx, APIKeyIDKey, key.ID)
+	ctx = context.WithValue(ctx, ScopesKey, scopes)
+	ctx = withAuditActor(ctx, r, entities.AuditActorAPIKey, key.ID, "")
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// withAuditActor attributes the request's audited actions to the
+// authenticated user or API key
+func withAuditActor(ctx context.Context, r *http.Request, actorType entities.AuditActorType, actorID, agencyID string) context.Context {
+	actor := entities.AuditActor{IP: r.RemoteAddr, UserAgent: r.UserAgent()}
+	if client, ok := services.AuditActorFrom(r.Context()); ok {
+		actor = client
+	}
+	actor.Type = actorType
+	actor.ID = actorID
+	actor.AgencyID = agencyID
+	return services.WithAuditActor(ctx, actor)
+}
+
 func (m *AuthMiddleware) respondError(w http.ResponseWriter, status int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -109,3 +222,59 @@ func GetRole(ctx context.Context) string {
 	}
 	return ""
 }
+
+func GetSessionID(ctx context.Context) string {
+	if val := ctx.Value(SessionIDKey); val != nil {
+		return val.(string)
+	}
+	return ""
+}
+
+func GetAPIKeyID(ctx context.Context) string {
+	if val := ctx.Value(APIKeyIDKey); val != nil {
+		return val.(string)
+	}
+	return ""
+}
+
+func GetScopes(ctx context.Context) []string {
+	if val := ctx.Value(ScopesKey); val != nil {
+		return val.([]string)
+	}
+	return nil
+}
+
+// IsUnverified reports whether the user has yet to verify their email address
+func IsUnverified(ctx context.Context) bool {
+	if val := ctx.Value(UnverifiedKey); val != nil {
+		return val.(bool)
+	}
+	return false
+}
+
+// IsMFASetupRequired reports whether the user must set up two-factor
+// authentication before doing anything else
+func IsMFASetupRequired(ctx context.Context) bool {
+	if val := ctx.Value(MFASetupRequiredKey); val != nil {
+		return val.(bool)
+	}
+	return false
+}
+
+// GetAgencyID returns the agency whose user is acting in this business, if
+// the request was made with an impersonation token
+func GetAgencyID(ctx context.Context) string {
+	if val := ctx.Value(AgencyIDKey); val != nil {
+		return val.(string)
+	}
+	return ""
+}
+
+// IsPlatformAdmin reports whether the user operates the platform across
+// every business. API keys never do.
+func IsPlatformAdmin(ctx context.Context) bool {
+	if val := ctx.Value(PlatformAdminKey); val != nil {
+		return val.(bool)
+	}
+	return false
+}
@@ -15,7 +15,7 @@ func NewCORSMiddleware(origins, methods, headers []string) *CORSMiddleware {
 		origins = []string{"*"}
 	}
 	if len(methods) == 0 {
-		methods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+		methods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
 	}
 	if len(headers) == 0 {
 		headers = []string{"Content-Type", "Authorization"}
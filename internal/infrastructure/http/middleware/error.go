@@ -50,6 +50,7 @@ func RespondError(w http.ResponseWriter, err error, log *logger.Logger) {
 		response = dto.ErrorResponse{
 			Code:    domainErr.Code,
 			Message: domainErr.Message,
+			Field:   domainErr.Field,
 		}
 
 		switch domainErr.Code {
@@ -63,6 +64,8 @@ func RespondError(w http.ResponseWriter, err error, log *logger.Logger) {
 			statusCode = http.StatusUnauthorized
 		case errors.ErrCodeForbidden:
 			statusCode = http.StatusForbidden
+		case errors.ErrCodeComplianceBlock:
+			statusCode = http.StatusUnprocessableEntity
 		case errors.ErrCodeProviderError:
 			statusCode = http.StatusBadGateway
 		default:
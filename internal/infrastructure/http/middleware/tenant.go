@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// TenantScope runs database work in a transaction limited to one business's
+// rows, or marks it as system work that may see every business. Detached
+// serves routes under RequireTenant, whose services scope their own work.
+// database.DB implements it with row-level security.
+type TenantScope interface {
+	InTenant(ctx context.Context, businessID string, fn func(ctx context.Context) error) error
+	Detached(ctx context.Context, businessID string, fn func(ctx context.Context) error) error
+	Unscoped(ctx context.Context) context.Context
+}
+
+// errRequestFailed rolls back the transaction of a request that failed
+var errRequestFailed = stderrors.New("request failed")
+
+// ScopeTenant runs the request in a transaction scoped to the authenticated
+// business, so a query that forgets to filter by business still cannot read
+// or change another business's rows. The transaction commits when the
+// handler succeeds and rolls back when it responds with an error or panics.
+// The response is held until the commit, so a client never sees success
+// for changes that were not saved. It must run after
+// AuthMiddleware.Authenticate.
+func ScopeTenant(scope TenantScope, log *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			businessID := GetBusinessID(r.Context())
+			if businessID == "" {
+				RespondError(w, errors.NewUnauthorizedError("no business in token"), log)
+				return
+			}
+
+			response := newBufferedResponse()
+			err := scope.InTenant(r.Context(), businessID, func(ctx context.Context) error {
+				next.ServeHTTP(response, r.WithContext(ctx))
+				if response.status >= http.StatusBadRequest {
+					return errRequestFailed
+				}
+				return nil
+			})
+			if err != nil && err != errRequestFailed {
+				log.Error("Tenant transaction failed", err, map[string]interface{}{
+					"business_id": businessID,
+					"path":        r.URL.Path,
+				})
+				RespondError(w, errors.NewDatabaseError(err, "failed to save changes"), log)
+				return
+			}
+
+			response.flush(w)
+		})
+	}
+}
+
+// RequireTenant admits requests authenticated for a business without
+// opening a transaction for them, for handlers whose services scope their
+// own database work. Placing a call waits on the provider, which must not
+// happen inside a transaction. It must run after
+// AuthMiddleware.Authenticate.
+func RequireTenant(log *logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if GetBusinessID(r.Context()) == "" {
+				RespondError(w, errors.NewUnauthorizedError("no business in token"), log)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Unscoped marks requests that are not about one business, such as sign-in,
+// the provider webhook and the /me and /admin routes, as system work that
+// row-level security lets see every business. Routes under ScopeTenant are
+// scoped again to the authenticated business. Without either mark a request
+// sees no business's rows.
+func Unscoped(scope TenantScope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(scope.Unscoped(r.Context())))
+		})
+	}
+}
+
+// bufferedResponse holds a handler's response until its transaction ends
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferedResponse() *bufferedResponse {
+	return &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	b.status = code
+}
+
+func (b *bufferedResponse) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+func (b *bufferedResponse) flush(w http.ResponseWriter) {
+	for key, values := range b.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(b.status)
+	w.Write(b.body.Bytes())
+}
@@ -0,0 +1,138 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/pkg/config"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// NewMailer returns the mailer selected by the mail configuration
+func NewMailer(cfg config.MailConfig, log *logger.Logger) (providers.Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg), nil
+	case "file":
+		return NewFileMailer(cfg.FileDir, cfg.From)
+	case "log", "":
+		return NewLogMailer(log), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q", cfg.Driver)
+	}
+}
+
+// SMTPMailer sends emails through an SMTP server
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(cfg config.MailConfig) *SMTPMailer {
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return &SMTPMailer{
+		addr: net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		from: cfg.From,
+		auth: auth,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, email providers.Email) error {
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{email.To}, formatEmail(m.from, email)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// FileMailer writes each email to its own file, for local development
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+func (m *FileMailer) Send(ctx context.Context, email providers.Email) error {
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(email.To, "_"))
+
+	if err := os.WriteFile(filepath.Join(m.dir, name), formatEmail(m.from, email), 0o600); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	return nil
+}
+
+// LogMailer writes emails to the log instead of delivering them
+type LogMailer struct {
+	logger *logger.Logger
+}
+
+func NewLogMailer(log *logger.Logger) *LogMailer {
+	return &LogMailer{logger: log}
+}
+
+func (m *LogMailer) Send(ctx context.Context, email providers.Email) error {
+	m.logger.Info("Email", map[string]interface{}{
+		"to":      email.To,
+		"subject": email.Subject,
+		"body":    email.Body,
+	})
+	return nil
+}
+
+// MailerNotifier delivers email notifications through a mailer, so it can be
+// registered on a Dispatcher for the email channel
+type MailerNotifier struct {
+	mailer providers.Mailer
+}
+
+func NewMailerNotifier(mailer providers.Mailer) *MailerNotifier {
+	return &MailerNotifier{mailer: mailer}
+}
+
+func (n *MailerNotifier) Notify(ctx context.Context, notification providers.Notification) error {
+	return n.mailer.Send(ctx, providers.Email{
+		To:      notification.Target,
+		Subject: notification.Subject,
+		Body:    notification.Body,
+	})
+}
+
+// formatEmail renders a plain-text RFC 5322 message
+func formatEmail(from string, email providers.Email) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + headerValue(from) + "\r\n")
+	b.WriteString("To: " + headerValue(email.To) + "\r\n")
+	b.WriteString("Subject: " + headerValue(email.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(email.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// headerValue drops line breaks so a value cannot add headers
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -0,0 +1,99 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/pkg/logger"
+)
+
+// Dispatcher sends each notification through the notifier registered for its
+// channel. Channels without a notifier are logged so nothing is lost while a
+// transport is not configured.
+type Dispatcher struct {
+	notifiers map[string]providers.Notifier
+	fallback  providers.Notifier
+}
+
+func NewDispatcher(log *logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		notifiers: make(map[string]providers.Notifier),
+		fallback:  NewLogNotifier(log),
+	}
+}
+
+// Register sets the notifier used for a channel
+func (d *Dispatcher) Register(channel string, notifier providers.Notifier) {
+	d.notifiers[channel] = notifier
+}
+
+func (d *Dispatcher) Notify(ctx context.Context, notification providers.Notification) error {
+	if notifier, ok := d.notifiers[notification.Channel]; ok {
+		return notifier.Notify(ctx, notification)
+	}
+	return d.fallback.Notify(ctx, notification)
+}
+
+// LogNotifier writes notifications to the log instead of delivering them
+type LogNotifier struct {
+	logger *logger.Logger
+}
+
+func NewLogNotifier(log *logger.Logger) *LogNotifier {
+	return &LogNotifier{logger: log}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification providers.Notification) error {
+	n.logger.Info("Notification", map[string]interface{}{
+		"channel": notification.Channel,
+		"target":  notification.Target,
+		"subject": notification.Subject,
+		"body":    notification.Body,
+	})
+	return nil
+}
+
+// WebhookNotifier posts notifications as JSON to the channel's URL
+type WebhookNotifier struct {
+	httpClient *http.Client
+}
+
+func NewWebhookNotifier() *WebhookNotifier {
+	return &WebhookNotifier{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification providers.Notification) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"subject":  notification.Subject,
+		"body":     notification.Body,
+		"metadata": notification.Metadata,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", notification.Target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
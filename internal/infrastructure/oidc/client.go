@@ -0,0 +1,372 @@
+// Package oidc signs users in with OpenID Connect identity providers using
+// the authorization code flow with PKCE (RFC 7636). Provider endpoints are
+// found through discovery and ID tokens are verified against the provider's
+// published keys.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/CallPilotReceptionist/internal/domain/errors"
+	"github.com/CallPilotReceptionist/internal/domain/providers"
+	"github.com/CallPilotReceptionist/pkg/jwtkeys"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// scopes are the claims asked for: the subject, email address and name
+	scopes = "openid email profile"
+	// maxResponseSize caps how much of a provider response is read
+	maxResponseSize = 1 << 20
+)
+
+// signingMethods are the ID token algorithms accepted. HS256 is left out on
+// purpose: it would make the client secret a signing key.
+var signingMethods = []string{"RS256", "ES256", "EdDSA"}
+
+// discovery is the part of the provider's configuration document that is
+// used
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type idTokenClaims struct {
+	Nonce           string `json:"nonce"`
+	Email           string `json:"email"`
+	EmailVerified   bool   `json:"email_verified"`
+	Name            string `json:"name"`
+	AuthorizedParty string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+// internalPrefixes are the non-public ranges net/netip has no predicate for
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // "this network"
+	netip.MustParsePrefix("100.64.0.0/10"),  // carrier-grade NAT
+	netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+	netip.MustParsePrefix("198.18.0.0/15"),  // benchmarking
+	netip.MustParsePrefix("240.0.0.0/4"),    // reserved, and broadcast
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, which can reach any IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local-use NAT64
+	netip.MustParsePrefix("2001:db8::/32"),  // documentation
+}
+
+// Client is an OpenID Connect relying party. Discovery documents and keys are
+// cached per issuer; keys are fetched again when a token names one that is
+// not known yet, which is how providers roll their keys.
+//
+// The issuer is configured by a business, so the client only connects to
+// public addresses. The check is made on the address actually dialled, after
+// DNS resolution and on every redirect, so a name cannot be pointed at an
+// internal service after it was checked.
+type Client struct {
+	httpClient *http.Client
+	// allowPrivateNetworks lifts the public address check
+	allowPrivateNetworks bool
+
+	mu        sync.Mutex
+	discovery map[string]*discovery
+	keys      map[string]map[string]interface{} // jwks_uri -> kid -> key
+}
+
+func NewClient() *Client {
+	c := &Client{
+		discovery: make(map[string]*discovery),
+		keys:      make(map[string]map[string]interface{}),
+	}
+
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: c.checkAddress,
+	}
+	c.httpClient = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			// No proxy, so the address checked is the provider's
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+	return c
+}
+
+// AllowPrivateNetworks lets the client connect to loopback and private
+// addresses, for tests and identity providers run locally in development.
+// Call it before the client is used.
+func (c *Client) AllowPrivateNetworks() {
+	c.allowPrivateNetworks = true
+}
+
+// checkAddress refuses connections to addresses that are not public
+func (c *Client) checkAddress(network, address string, _ syscall.RawConn) error {
+	if c.allowPrivateNetworks {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublic(ip.Unmap()) {
+		return fmt.Errorf("refusing to connect to %s: not a public address", host)
+	}
+	return nil
+}
+
+func isPublic(ip netip.Addr) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, prefix := range internalPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// AuthorizationURL returns the provider's authorization endpoint with the
+// request's parameters
+func (c *Client) AuthorizationURL(ctx context.Context, provider providers.OIDCProvider, req providers.OIDCAuthRequest) (string, error) {
+	doc, err := c.discover(ctx, provider.Issuer)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint, err := url.Parse(doc.AuthorizationEndpoint)
+	if err != nil {
+		return "", errors.NewProviderError(err, "invalid authorization endpoint")
+	}
+
+	query := endpoint.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", provider.ClientID)
+	query.Set("redirect_uri", req.RedirectURI)
+	query.Set("scope", scopes)
+	query.Set("state", req.State)
+	query.Set("nonce", req.Nonce)
+	query.Set("code_challenge", req.CodeChallenge)
+	query.Set("code_challenge_method", "S256")
+	endpoint.RawQuery = query.Encode()
+
+	return endpoint.String(), nil
+}
+
+// Exchange redeems the authorization code at the token endpoint and returns
+// the identity in the verified ID token
+func (c *Client) Exchange(ctx context.Context, provider providers.OIDCProvider, req providers.OIDCTokenRequest) (*providers.OIDCIdentity, error) {
+	doc, err := c.discover(ctx, provider.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {req.Code},
+		"redirect_uri":  {req.RedirectURI},
+		"code_verifier": {req.CodeVerifier},
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.NewProviderError(err, "failed to create token request")
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+	// client_secret_basic: both parts are form-encoded first (RFC 6749 2.3.1)
+	httpReq.SetBasicAuth(url.QueryEscape(provider.ClientID), url.QueryEscape(provider.ClientSecret))
+
+	var tokens struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := c.do(httpReq, &tokens)
+	if err != nil {
+		return nil, errors.NewProviderError(err, "failed to redeem authorization code")
+	}
+	if status != http.StatusOK {
+		if tokens.Error == "invalid_grant" {
+			return nil, errors.NewUnauthorizedError("the sign-in code is invalid or expired")
+		}
+		return nil, errors.NewProviderError(fmt.Errorf("token endpoint returned %d: %s %s", status, tokens.Error, tokens.ErrorDescription), "failed to redeem authorization code")
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.NewProviderError(fmt.Errorf("no id_token in response"), "failed to redeem authorization code")
+	}
+
+	return c.verify(ctx, doc, provider, tokens.IDToken, req.Nonce)
+}
+
+// verify checks the ID token's signature, issuer, audience, expiry and nonce
+// (OpenID Connect Core 3.1.3.7)
+func (c *Client) verify(ctx context.Context, doc *discovery, provider providers.OIDCProvider, raw, nonce string) (*providers.OIDCIdentity, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return c.key(ctx, doc.JWKSURI, kid)
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(provider.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return nil, errors.NewUnauthorizedError("invalid identity token")
+	}
+
+	if claims.Nonce == "" || claims.Nonce != nonce {
+		return nil, errors.NewUnauthorizedError("invalid identity token")
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != provider.ClientID {
+		return nil, errors.NewUnauthorizedError("invalid identity token")
+	}
+	if claims.Subject == "" {
+		return nil, errors.NewUnauthorizedError("invalid identity token")
+	}
+
+	return &providers.OIDCIdentity{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+	}, nil
+}
+
+// discover fetches the provider's configuration. The document must name the
+// issuer it was fetched for, so one provider cannot stand in for another.
+func (c *Client) discover(ctx context.Context, issuer string) (*discovery, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+
+	c.mu.Lock()
+	doc, ok := c.discovery[issuer]
+	c.mu.Unlock()
+	if ok {
+		return doc, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, errors.NewProviderError(err, "failed to create discovery request")
+	}
+
+	doc = &discovery{}
+	status, err := c.do(req, doc)
+	if err != nil {
+		return nil, errors.NewProviderError(err, "failed to fetch identity provider configuration")
+	}
+	if status != http.StatusOK {
+		return nil, errors.NewProviderError(fmt.Errorf("discovery returned %d", status), "failed to fetch identity provider configuration")
+	}
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, errors.NewProviderError(fmt.Errorf("discovery issuer %q does not match %q", doc.Issuer, issuer), "identity provider configuration is invalid")
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.NewProviderError(fmt.Errorf("discovery document is missing endpoints"), "identity provider configuration is invalid")
+	}
+
+	c.mu.Lock()
+	c.discovery[issuer] = doc
+	c.mu.Unlock()
+
+	return doc, nil
+}
+
+// key returns the provider's key with the given ID, fetching the key set
+// again if it is not known. A token without a kid is accepted when the
+// provider publishes a single key.
+func (c *Client) key(ctx context.Context, jwksURI, kid string) (interface{}, error) {
+	if key, ok := c.cachedKey(jwksURI, kid); ok {
+		return key, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var set jwtkeys.JWKS
+	status, err := c.do(req, &set)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("jwks returned %d", status)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Keys of other types are skipped, not fatal
+		if public, err := jwk.PublicKey(); err == nil {
+			keys[jwk.KeyID] = public
+		}
+	}
+
+	c.mu.Lock()
+	c.keys[jwksURI] = keys
+	c.mu.Unlock()
+
+	if key, ok := c.cachedKey(jwksURI, kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+func (c *Client) cachedKey(jwksURI, kid string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys := c.keys[jwksURI]
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, true
+		}
+	}
+	key, ok := keys[kid]
+	return key, ok
+}
+
+// do sends the request and decodes the JSON response into v, returning the
+// status code
+func (c *Client) do(req *http.Request, v interface{}) (int, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(body, v); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
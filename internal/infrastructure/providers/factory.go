@@ -33,11 +33,13 @@ func NewProviderFactory(cfg *config.Config) *ProviderFactory {
 func (f *ProviderFactory) CreateProvider(providerType ProviderType) (providers.VoiceProvider, error) {
 	switch providerType {
 	case ProviderTypeVapi:
-		return vapi.NewVapiProvider(
+		provider := vapi.NewVapiProvider(
 			f.config.Vapi.APIKey,
 			f.config.Vapi.APIBaseURL,
 			"", // No webhook secret - Vapi doesn't require signature validation
-		), nil
+		)
+		provider.SetPhoneNumbers(f.config.Vapi.PhoneCredentialID, f.config.Vapi.WebhookURL)
+		return provider, nil
 	// Future provider implementations:
 	// case ProviderTypeTwilio:
 	//     return twilio.NewTwilioProvider(...), nil
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/CallPilotReceptionist/internal/domain/errors"
@@ -21,6 +22,12 @@ type VapiProvider struct {
 	baseURL    string
 	httpClient *http.Client
 	webhookSecret string
+
+	// phoneCredentialID is the SIP trunk credential businesses' own numbers
+	// are imported over, and serverURL where their inbound calls ask for an
+	// assistant
+	phoneCredentialID string
+	serverURL         string
 }
 
 func NewVapiProvider(apiKey, baseURL, webhookSecret string) *VapiProvider {
@@ -39,12 +46,13 @@ func (v *VapiProvider) InitiateCall(ctx context.Context, req providers.CallReque
 		"phoneNumber": req.PhoneNumber,
 	}
 
+	// The config only overrides the saved assistant; it is never sent as a
+	// transient assistant, which would have no model, prompt or voice
 	if req.AssistantID != "" {
 		payload["assistantId"] = req.AssistantID
 	}
-
 	if req.AssistantConfig != nil {
-		payload["assistant"] = v.convertAssistantConfig(req.AssistantConfig)
+		payload["assistantOverrides"] = v.convertAssistantOverrides(req.AssistantConfig)
 	}
 
 	if req.Metadata != nil {
@@ -81,10 +89,16 @@ func (v *VapiProvider) HandleWebhook(ctx context.Context, payload []byte, signat
 		return nil, errors.NewInvalidInputError("invalid webhook payload")
 	}
 
+	// Server messages wrap the event in a "message" envelope
+	if message, ok := webhookData["message"].(map[string]interface{}); ok {
+		return v.parseServerMessage(message, webhookData), nil
+	}
+
 	event := &providers.CallEvent{
 		Type:      getString(webhookData, "type"),
 		CallID:    getString(webhookData, "callId"),
 		Status:    getString(webhookData, "status"),
+		Outcome:   callOutcome(getString(webhookData, "endedReason")),
 		Timestamp: time.Now(),
 		Data:      webhookData,
 	}
@@ -98,6 +112,178 @@ func (v *VapiProvider) HandleWebhook(ctx context.Context, payload []byte, signat
 	return event, nil
 }
 
+func (v *VapiProvider) parseServerMessage(message, raw map[string]interface{}) *providers.CallEvent {
+	event := &providers.CallEvent{
+		Type:          getString(message, "type"),
+		CallID:        getString(message, "call", "id"),
+		Status:        getString(message, "status"),
+		CustomerPhone: getString(message, "customer", "number"),
+		BusinessPhone: getString(message, "phoneNumber", "number"),
+		Timestamp:     time.Now(),
+		Data:          raw,
+	}
+
+	if event.CustomerPhone == "" {
+		event.CustomerPhone = getString(message, "call", "customer", "number")
+	}
+	if event.BusinessPhone == "" {
+		event.BusinessPhone = getString(message, "call", "phoneNumber", "number")
+	}
+
+	endedReason := getString(message, "endedReason")
+	if endedReason == "" {
+		endedReason = getString(message, "call", "endedReason")
+	}
+	event.Outcome = callOutcome(endedReason)
+
+	// Older assistants send a single "function-call" rather than a tool-call list
+	if event.Type == "function-call" {
+		event.Type = providers.CallEventTypeToolCalls
+	}
+	if event.Type == providers.CallEventTypeToolCalls {
+		event.ToolCalls = parseToolCalls(message)
+	}
+
+	if analysis, ok := message["analysis"].(map[string]interface{}); ok {
+		if structured, ok := analysis["structuredData"].(map[string]interface{}); ok {
+			event.Analysis = structured
+		}
+	}
+
+	if timestamp := getString(message, "timestamp"); timestamp != "" {
+		if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
+			event.Timestamp = t
+		}
+	}
+
+	return event
+}
+
+// SetPhoneNumbers configures how businesses' numbers are attached. Without
+// a credential, AttachPhoneNumber fails.
+func (v *VapiProvider) SetPhoneNumbers(credentialID, serverURL string) {
+	v.phoneCredentialID = credentialID
+	v.serverURL = serverURL
+}
+
+// AttachPhoneNumber imports the business's own number over the configured
+// SIP trunk credential. When a server URL is set, inbound calls to the
+// number send an assistant-request message so the backend still chooses the
+// assistant for each call; otherwise the assistant answers directly.
+func (v *VapiProvider) AttachPhoneNumber(ctx context.Context, req providers.PhoneNumberRequest) (string, error) {
+	if v.phoneCredentialID == "" {
+		return "", errors.NewProviderError(fmt.Errorf("no phone number credential configured"), "phone numbers cannot be attached")
+	}
+
+	payload := map[string]interface{}{
+		"provider":               "byo-phone-number",
+		"number":                 req.PhoneNumber,
+		"numberE164CheckEnabled": true,
+		"credentialId":           v.phoneCredentialID,
+	}
+	if req.Name != "" {
+		payload["name"] = req.Name
+	}
+	if v.serverURL != "" {
+		payload["server"] = map[string]interface{}{"url": v.serverURL}
+	} else {
+		payload["assistantId"] = req.AssistantID
+	}
+
+	respData, err := v.makeRequest(ctx, "POST", "/phone-number", payload)
+	if err != nil {
+		return "", errors.NewProviderError(err, "failed to attach phone number")
+	}
+
+	return getString(respData, "id"), nil
+}
+
+// BuildAssistantResponse formats the reply to an assistant-request server message
+func (v *VapiProvider) BuildAssistantResponse(selection providers.AssistantSelection) interface{} {
+	response := map[string]interface{}{}
+
+	if selection.AssistantID != "" {
+		response["assistantId"] = selection.AssistantID
+		if selection.AssistantConfig != nil {
+			response["assistantOverrides"] = v.convertAssistantOverrides(selection.AssistantConfig)
+		}
+		return response
+	}
+
+	if selection.AssistantConfig != nil {
+		response["assistant"] = v.convertAssistantConfig(selection.AssistantConfig)
+		if selection.AssistantConfig.VariableValues != nil {
+			response["assistantOverrides"] = map[string]interface{}{
+				"variableValues": selection.AssistantConfig.VariableValues,
+			}
+		}
+	}
+
+	return response
+}
+
+// BuildToolCallResponse formats the reply to a tool-calls server message
+func (v *VapiProvider) BuildToolCallResponse(results []providers.ToolCallResult) interface{} {
+	items := make([]map[string]interface{}, 0, len(results))
+	for _, result := range results {
+		item := map[string]interface{}{
+			"toolCallId": result.ToolCallID,
+			"name":       result.Name,
+		}
+		if result.Error != "" {
+			item["error"] = result.Error
+		} else {
+			item["result"] = result.Result
+		}
+		items = append(items, item)
+	}
+	return map[string]interface{}{"results": items}
+}
+
+// TransferCall hands a live call over to a phone number through the call's
+// control URL
+func (v *VapiProvider) TransferCall(ctx context.Context, req providers.TransferRequest) error {
+	callData, err := v.makeRequest(ctx, "GET", fmt.Sprintf("/call/%s", req.CallID), nil)
+	if err != nil {
+		return errors.NewProviderError(err, "failed to look up call for transfer")
+	}
+
+	controlURL := getString(callData, "monitor", "controlUrl")
+	if controlURL == "" {
+		return errors.NewProviderError(fmt.Errorf("call %s has no control URL", req.CallID), "call cannot be transferred")
+	}
+
+	destination := map[string]interface{}{
+		"type":   "number",
+		"number": req.PhoneNumber,
+	}
+	if req.Mode == providers.TransferModeWarm {
+		transferPlan := map[string]interface{}{"mode": "warm-transfer-say-summary"}
+		if req.Summary != "" {
+			transferPlan = map[string]interface{}{
+				"mode":    "warm-transfer-say-message",
+				"message": req.Summary,
+			}
+		}
+		destination["transferPlan"] = transferPlan
+	} else {
+		destination["transferPlan"] = map[string]interface{}{"mode": "blind-transfer"}
+	}
+
+	payload := map[string]interface{}{
+		"type":        "transfer",
+		"destination": destination,
+	}
+	if req.Message != "" {
+		payload["content"] = req.Message
+	}
+
+	if _, err := v.doRequest(ctx, "POST", controlURL, payload); err != nil {
+		return errors.NewProviderError(err, "failed to transfer call")
+	}
+	return nil
+}
+
 func (v *VapiProvider) GetCallDetails(ctx context.Context, callID string) (*providers.CallDetails, error) {
 	respData, err := v.makeRequest(ctx, "GET", fmt.Sprintf("/call/%s", callID), nil)
 	if err != nil {
@@ -128,6 +314,13 @@ func (v *VapiProvider) GetCallDetails(ctx context.Context, callID string) (*prov
 		details.Metadata = metadata
 	}
 
+	if details.Status == "ended" {
+		details.Outcome = callOutcome(getString(respData, "endedReason"))
+		if details.Outcome == "" {
+			details.Outcome = providers.CallOutcomeCompleted
+		}
+	}
+
 	return details, nil
 }
 
@@ -218,8 +411,21 @@ func (v *VapiProvider) ValidateWebhookSignature(payload []byte, signature string
 }
 
 func (v *VapiProvider) makeRequest(ctx context.Context, method, path string, payload interface{}) (map[string]interface{}, error) {
-	url := v.baseURL + path
+	respBody, err := v.doRequest(ctx, method, v.baseURL+path, payload)
+	if err != nil {
+		return nil, err
+	}
 
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return result, nil
+}
+
+// doRequest sends an authenticated request and returns the raw response body
+func (v *VapiProvider) doRequest(ctx context.Context, method, url string, payload interface{}) ([]byte, error) {
 	var body io.Reader
 	if payload != nil {
 		jsonData, err := json.Marshal(payload)
@@ -252,12 +458,7 @@ func (v *VapiProvider) makeRequest(ctx context.Context, method, path string, pay
 		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(respBody, &result); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return result, nil
+	return respBody, nil
 }
 
 func (v *VapiProvider) convertAssistantConfig(config *providers.AssistantConfig) map[string]interface{} {
@@ -283,10 +484,128 @@ func (v *VapiProvider) convertAssistantConfig(config *providers.AssistantConfig)
 	if config.Metadata != nil {
 		result["metadata"] = config.Metadata
 	}
+	if len(config.Functions) > 0 {
+		model := map[string]interface{}{"tools": convertFunctions(config.Functions)}
+		if config.Model != "" {
+			model["model"] = config.Model
+		}
+		result["model"] = model
+	}
+	if config.AnalysisSchema != nil {
+		result["analysisPlan"] = map[string]interface{}{"structuredDataSchema": config.AnalysisSchema}
+	}
+
+	return result
+}
+
+// convertAssistantOverrides maps a partial config onto Vapi's per-call overrides
+func (v *VapiProvider) convertAssistantOverrides(config *providers.AssistantConfig) map[string]interface{} {
+	result := map[string]interface{}{}
+
+	if config.FirstMessage != "" {
+		result["firstMessage"] = config.FirstMessage
+	}
+	if config.VariableValues != nil {
+		result["variableValues"] = config.VariableValues
+	}
+	if config.Metadata != nil {
+		result["metadata"] = config.Metadata
+	}
+	if len(config.Functions) > 0 {
+		result["model"] = map[string]interface{}{"tools": convertFunctions(config.Functions)}
+	}
 
 	return result
 }
 
+// convertFunctions maps assistant functions to Vapi function tools. Calls to
+// them are sent to the server URL as tool-calls messages.
+func convertFunctions(functions []providers.Function) []map[string]interface{} {
+	tools := make([]map[string]interface{}, 0, len(functions))
+	for _, fn := range functions {
+		tools = append(tools, map[string]interface{}{
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":        fn.Name,
+				"description": fn.Description,
+				"parameters":  fn.Parameters,
+			},
+		})
+	}
+	return tools
+}
+
+// parseToolCalls reads the functions invoked in a tool-calls or
+// function-call server message
+func parseToolCalls(message map[string]interface{}) []providers.ToolCall {
+	var toolCalls []providers.ToolCall
+
+	if list, ok := message["toolCallList"].([]interface{}); ok {
+		for _, item := range list {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			toolCalls = append(toolCalls, providers.ToolCall{
+				ID:        getString(entry, "id"),
+				Name:      getString(entry, "function", "name"),
+				Arguments: toolArguments(entry, "function", "arguments"),
+			})
+		}
+		return toolCalls
+	}
+
+	if fn, ok := message["functionCall"].(map[string]interface{}); ok {
+		toolCalls = append(toolCalls, providers.ToolCall{
+			ID:        getString(fn, "id"),
+			Name:      getString(fn, "name"),
+			Arguments: toolArguments(fn, "parameters"),
+		})
+	}
+
+	return toolCalls
+}
+
+// toolArguments reads function arguments sent either as an object or as a
+// JSON-encoded string
+func toolArguments(data map[string]interface{}, keys ...string) map[string]interface{} {
+	current := data
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]interface{})
+		if !ok {
+			return map[string]interface{}{}
+		}
+		current = next
+	}
+
+	switch args := current[keys[len(keys)-1]].(type) {
+	case map[string]interface{}:
+		return args
+	case string:
+		parsed := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(args), &parsed); err == nil {
+			return parsed
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// callOutcome maps a Vapi endedReason to a provider-neutral call outcome
+func callOutcome(endedReason string) string {
+	switch {
+	case endedReason == "":
+		return ""
+	case endedReason == "customer-did-not-answer", endedReason == "voicemail":
+		return providers.CallOutcomeNoAnswer
+	case endedReason == "customer-busy":
+		return providers.CallOutcomeBusy
+	case strings.Contains(endedReason, "error"), strings.Contains(endedReason, "failed"):
+		return providers.CallOutcomeFailed
+	default:
+		return providers.CallOutcomeCompleted
+	}
+}
+
 // Helper functions
 func getString(data map[string]interface{}, keys ...string) string {
 	current := data
@@ -0,0 +1,5 @@
+-- migrations/002_caller_lookup_indexes.down.sql
+
+DROP INDEX IF EXISTS idx_businesses_phone;
+DROP INDEX IF EXISTS idx_appointments_business_customer_phone;
+DROP INDEX IF EXISTS idx_calls_business_caller_phone;
@@ -0,0 +1,6 @@
+-- migrations/002_caller_lookup_indexes.up.sql
+
+-- Support looking up a caller's history when a call starts
+CREATE INDEX IF NOT EXISTS idx_calls_business_caller_phone ON calls(business_id, caller_phone);
+CREATE INDEX IF NOT EXISTS idx_appointments_business_customer_phone ON appointments(business_id, customer_phone);
+CREATE INDEX IF NOT EXISTS idx_businesses_phone ON businesses(phone);
@@ -0,0 +1,9 @@
+-- migrations/003_normalize_phone_numbers.down.sql
+
+-- Normalised numbers are kept; only the triggers and helpers are removed
+DROP TRIGGER IF EXISTS trg_appointments_customer_phone_e164 ON appointments;
+DROP TRIGGER IF EXISTS trg_calls_caller_phone_e164 ON calls;
+DROP TRIGGER IF EXISTS trg_businesses_phone_e164 ON businesses;
+
+DROP FUNCTION IF EXISTS require_e164_phone();
+DROP FUNCTION IF EXISTS normalize_phone_e164(TEXT, TEXT, TEXT);
@@ -0,0 +1,110 @@
+-- migrations/003_normalize_phone_numbers.up.sql
+
+-- Converts a stored phone number to E.164 using the numbering rules of the
+-- owning business's region. Returns NULL when the number cannot be parsed.
+-- Mirrors pkg/phone.Normalize without the per-region length checks.
+CREATE OR REPLACE FUNCTION normalize_phone_e164(raw TEXT, calling_code TEXT, trunk_prefix TEXT)
+RETURNS TEXT AS $$
+DECLARE
+    digits TEXT;
+BEGIN
+    IF raw IS NULL OR btrim(raw) ~ '[^0-9+ ().\-/]' THEN
+        RETURN NULL;
+    END IF;
+
+    digits := regexp_replace(raw, '[^0-9]', '', 'g');
+    IF digits = '' THEN
+        RETURN NULL;
+    END IF;
+
+    IF left(btrim(raw), 1) = '+' THEN
+        NULL; -- already international
+    ELSIF left(digits, 2) = '00' THEN
+        digits := substr(digits, 3);
+    ELSIF calling_code = '1' AND left(digits, 3) = '011' THEN
+        digits := substr(digits, 4);
+    ELSIF trunk_prefix <> '' AND left(digits, length(trunk_prefix)) = trunk_prefix THEN
+        digits := calling_code || substr(digits, length(trunk_prefix) + 1);
+    ELSIF calling_code = '1' AND length(digits) = 11 AND left(digits, 1) = '1' THEN
+        NULL; -- North American number written with the leading 1
+    ELSE
+        digits := calling_code || digits;
+    END IF;
+
+    IF digits !~ '^[1-9][0-9]{7,14}$' THEN
+        RETURN NULL;
+    END IF;
+
+    RETURN '+' || digits;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Record the region existing businesses have implicitly been using
+UPDATE businesses
+SET settings = COALESCE(settings, '{}'::jsonb) || '{"default_region": "US"}'::jsonb
+WHERE settings IS NULL OR NOT settings ? 'default_region';
+
+-- Regions supported by pkg/phone
+CREATE TEMPORARY TABLE phone_regions (
+    code VARCHAR(2) PRIMARY KEY,
+    calling_code VARCHAR(3) NOT NULL,
+    trunk_prefix VARCHAR(1) NOT NULL
+);
+
+INSERT INTO phone_regions (code, calling_code, trunk_prefix) VALUES
+    ('US', '1', ''), ('CA', '1', ''), ('GB', '44', '0'), ('IE', '353', '0'),
+    ('AU', '61', '0'), ('NZ', '64', '0'), ('DE', '49', '0'), ('FR', '33', '0'),
+    ('ES', '34', ''), ('IT', '39', ''), ('NL', '31', '0'), ('PL', '48', ''),
+    ('MX', '52', ''), ('BR', '55', '0'), ('IN', '91', '0'), ('ZA', '27', '0');
+
+-- Normalise existing rows; numbers that cannot be parsed are left untouched
+UPDATE businesses b
+SET phone = COALESCE(normalize_phone_e164(b.phone, r.calling_code, r.trunk_prefix), b.phone)
+FROM phone_regions r
+WHERE r.code = upper(b.settings->>'default_region');
+
+UPDATE calls c
+SET caller_phone = COALESCE(normalize_phone_e164(c.caller_phone, r.calling_code, r.trunk_prefix), c.caller_phone)
+FROM businesses b
+JOIN phone_regions r ON r.code = upper(b.settings->>'default_region')
+WHERE c.business_id = b.id;
+
+UPDATE appointments a
+SET customer_phone = COALESCE(normalize_phone_e164(a.customer_phone, r.calling_code, r.trunk_prefix), a.customer_phone)
+FROM businesses b
+JOIN phone_regions r ON r.code = upper(b.settings->>'default_region')
+WHERE a.business_id = b.id;
+
+DROP TABLE phone_regions;
+
+-- New numbers must be E.164. A CHECK constraint would also be checked on
+-- every update of a legacy row that could not be parsed, failing status
+-- changes that leave the number alone, so the format is only enforced when
+-- a number is inserted or changed. Legacy rows can be found with the same
+-- pattern and fixed by hand.
+CREATE OR REPLACE FUNCTION require_e164_phone()
+RETURNS TRIGGER AS $$
+DECLARE
+    phone_column TEXT := TG_ARGV[0];
+    phone_number TEXT := to_jsonb(NEW) ->> phone_column;
+BEGIN
+    IF TG_OP = 'UPDATE' AND phone_number IS NOT DISTINCT FROM to_jsonb(OLD) ->> phone_column THEN
+        RETURN NEW;
+    END IF;
+    IF phone_number IS NULL OR phone_number !~ '^\+[1-9][0-9]{7,14}$' THEN
+        RAISE EXCEPTION '%.% must be a phone number in E.164 format', TG_TABLE_NAME, phone_column
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_businesses_phone_e164
+    BEFORE INSERT OR UPDATE OF phone ON businesses
+    FOR EACH ROW EXECUTE FUNCTION require_e164_phone('phone');
+CREATE TRIGGER trg_calls_caller_phone_e164
+    BEFORE INSERT OR UPDATE OF caller_phone ON calls
+    FOR EACH ROW EXECUTE FUNCTION require_e164_phone('caller_phone');
+CREATE TRIGGER trg_appointments_customer_phone_e164
+    BEFORE INSERT OR UPDATE OF customer_phone ON appointments
+    FOR EACH ROW EXECUTE FUNCTION require_e164_phone('customer_phone');
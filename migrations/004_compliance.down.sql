@@ -0,0 +1,4 @@
+-- migrations/004_compliance.down.sql
+
+DROP TABLE IF EXISTS consent_records;
+DROP TABLE IF EXISTS do_not_call;
@@ -0,0 +1,29 @@
+-- migrations/004_compliance.up.sql
+
+-- Do-not-call list. Rows without a business_id apply to every business.
+CREATE TABLE IF NOT EXISTS do_not_call (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id UUID REFERENCES businesses(id) ON DELETE CASCADE,
+    phone VARCHAR(50) NOT NULL CHECK (phone ~ '^\+[1-9][0-9]{7,14}$'),
+    source VARCHAR(50) NOT NULL CHECK (source IN ('manual', 'opt_out', 'import')),
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_do_not_call_scope_phone
+    ON do_not_call(COALESCE(business_id, '00000000-0000-0000-0000-000000000000'::uuid), phone);
+CREATE INDEX IF NOT EXISTS idx_do_not_call_phone ON do_not_call(phone);
+
+-- Consent to receive outbound calls
+CREATE TABLE IF NOT EXISTS consent_records (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    phone VARCHAR(50) NOT NULL CHECK (phone ~ '^\+[1-9][0-9]{7,14}$'),
+    source VARCHAR(100) NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    granted_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_consent_records_business_phone ON consent_records(business_id, phone);
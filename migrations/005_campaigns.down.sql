@@ -0,0 +1,4 @@
+-- migrations/005_campaigns.down.sql
+
+DROP TABLE IF EXISTS campaign_contacts;
+DROP TABLE IF EXISTS campaigns;
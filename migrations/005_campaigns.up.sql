@@ -0,0 +1,42 @@
+-- migrations/005_campaigns.up.sql
+
+-- Outbound calling campaigns
+CREATE TABLE IF NOT EXISTS campaigns (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    assistant_id VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(50) NOT NULL CHECK (status IN ('draft', 'running', 'paused', 'completed', 'cancelled')),
+    max_concurrent INTEGER NOT NULL CHECK (max_concurrent > 0),
+    calls_per_minute INTEGER NOT NULL CHECK (calls_per_minute > 0),
+    retry_policy JSONB NOT NULL DEFAULT '{}',
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_business_id ON campaigns(business_id);
+CREATE INDEX IF NOT EXISTS idx_campaigns_status ON campaigns(status);
+
+-- Numbers to call in a campaign and the calls placed to each
+CREATE TABLE IF NOT EXISTS campaign_contacts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    campaign_id UUID NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    phone VARCHAR(50) NOT NULL CHECK (phone ~ '^\+[1-9][0-9]{7,14}$'),
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    variables JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(50) NOT NULL CHECK (status IN ('pending', 'calling', 'completed', 'failed', 'skipped')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    call_ids UUID[] NOT NULL DEFAULT '{}',
+    last_outcome VARCHAR(50) NOT NULL DEFAULT '',
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (campaign_id, phone)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_contacts_due ON campaign_contacts(campaign_id, status, next_attempt_at);
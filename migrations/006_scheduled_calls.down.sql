@@ -0,0 +1,5 @@
+-- migrations/006_scheduled_calls.down.sql
+
+DROP TABLE IF EXISTS scheduled_calls;
+DROP INDEX IF EXISTS idx_calls_direction;
+ALTER TABLE calls DROP COLUMN IF EXISTS direction;
@@ -0,0 +1,29 @@
+-- migrations/006_scheduled_calls.up.sql
+
+-- Inbound calls are now recorded alongside outbound ones
+ALTER TABLE calls ADD COLUMN IF NOT EXISTS direction VARCHAR(20) NOT NULL DEFAULT 'outbound'
+    CHECK (direction IN ('inbound', 'outbound'));
+
+-- Outbound calls to be placed at a set time, including automatic callbacks
+CREATE TABLE IF NOT EXISTS scheduled_calls (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    phone VARCHAR(50) NOT NULL CHECK (phone ~ '^\+[1-9][0-9]{7,14}$'),
+    assistant_id VARCHAR(255) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    source VARCHAR(50) NOT NULL CHECK (source IN ('manual', 'auto_callback')),
+    status VARCHAR(50) NOT NULL CHECK (status IN ('scheduled', 'initiated', 'failed', 'cancelled')),
+    scheduled_for TIMESTAMP NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    call_id UUID REFERENCES calls(id) ON DELETE SET NULL,
+    origin_call_id UUID REFERENCES calls(id) ON DELETE SET NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_calls_due ON scheduled_calls(status, scheduled_for);
+CREATE INDEX IF NOT EXISTS idx_scheduled_calls_business_id ON scheduled_calls(business_id, scheduled_for);
+CREATE INDEX IF NOT EXISTS idx_scheduled_calls_call_id ON scheduled_calls(call_id);
+CREATE INDEX IF NOT EXISTS idx_calls_direction ON calls(business_id, direction);
@@ -0,0 +1,5 @@
+-- migrations/007_call_transfers.down.sql
+
+DROP INDEX IF EXISTS idx_interactions_type;
+DROP TABLE IF EXISTS on_call_shifts;
+DROP TABLE IF EXISTS transfer_destinations;
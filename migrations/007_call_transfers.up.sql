@@ -0,0 +1,30 @@
+-- migrations/007_call_transfers.up.sql
+
+-- People and lines the assistant can hand a caller over to
+CREATE TABLE IF NOT EXISTS transfer_destinations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL CHECK (name ~ '^[a-z][a-z0-9_]{0,49}$'),
+    description TEXT NOT NULL DEFAULT '',
+    phone VARCHAR(50) NOT NULL CHECK (phone ~ '^\+[1-9][0-9]{7,14}$'),
+    mode VARCHAR(20) NOT NULL CHECK (mode IN ('warm', 'cold')),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (business_id, name)
+);
+
+-- On-call rotation: who answers a destination's transfers and when
+CREATE TABLE IF NOT EXISTS on_call_shifts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    destination_id UUID NOT NULL REFERENCES transfer_destinations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(50) NOT NULL CHECK (phone ~ '^\+[1-9][0-9]{7,14}$'),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL CHECK (ends_at > starts_at),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_on_call_shifts_destination ON on_call_shifts(destination_id, starts_at, ends_at);
+CREATE INDEX IF NOT EXISTS idx_interactions_type ON interactions(type, timestamp);
@@ -0,0 +1,4 @@
+-- migrations/008_messages.down.sql
+
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS message_recipients;
@@ -0,0 +1,43 @@
+-- migrations/008_messages.up.sql
+
+-- People and teams callers can leave messages for
+CREATE TABLE IF NOT EXISTS message_recipients (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    aliases TEXT[] NOT NULL DEFAULT '{}',
+    channels JSONB NOT NULL DEFAULT '[]',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Messages left by callers, followed up until resolved
+CREATE TABLE IF NOT EXISTS messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    call_id UUID REFERENCES calls(id) ON DELETE SET NULL,
+    caller_name VARCHAR(255) NOT NULL DEFAULT '',
+    caller_phone VARCHAR(50) NOT NULL CHECK (caller_phone ~ '^\+[1-9][0-9]{7,14}$'),
+    callback_phone VARCHAR(50) NOT NULL CHECK (callback_phone ~ '^\+[1-9][0-9]{7,14}$'),
+    recipient_name VARCHAR(255) NOT NULL DEFAULT '',
+    recipient_id UUID REFERENCES message_recipients(id) ON DELETE SET NULL,
+    assignee_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    urgency VARCHAR(20) NOT NULL CHECK (urgency IN ('low', 'normal', 'urgent')),
+    body TEXT NOT NULL,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('tool', 'extraction')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('open', 'acknowledged', 'resolved')),
+    acknowledged_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    acknowledged_at TIMESTAMP,
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_recipients_business_id ON message_recipients(business_id);
+CREATE INDEX IF NOT EXISTS idx_messages_business_status ON messages(business_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_messages_recipient_id ON messages(recipient_id);
+CREATE INDEX IF NOT EXISTS idx_messages_assignee_id ON messages(assignee_id);
+CREATE INDEX IF NOT EXISTS idx_messages_call_id ON messages(call_id);
@@ -0,0 +1,4 @@
+-- migrations/009_inbox.down.sql
+
+DROP TABLE IF EXISTS inbox_comments;
+DROP TABLE IF EXISTS inbox_item_states;
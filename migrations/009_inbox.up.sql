@@ -0,0 +1,30 @@
+-- migrations/009_inbox.up.sql
+
+-- Follow-up state of inbox items. Items themselves are derived from the
+-- appointment, interaction, message or call they point at.
+CREATE TABLE IF NOT EXISTS inbox_item_states (
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    item_type VARCHAR(50) NOT NULL CHECK (item_type IN ('appointment_request', 'complaint', 'message', 'failed_call')),
+    source_id UUID NOT NULL,
+    assignee_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    snoozed_until TIMESTAMP,
+    done_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    done_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (business_id, item_type, source_id)
+);
+
+-- Notes staff leave on inbox items
+CREATE TABLE IF NOT EXISTS inbox_comments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    item_type VARCHAR(50) NOT NULL CHECK (item_type IN ('appointment_request', 'complaint', 'message', 'failed_call')),
+    source_id UUID NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_inbox_item_states_source_id ON inbox_item_states(business_id, source_id);
+CREATE INDEX IF NOT EXISTS idx_inbox_comments_source_id ON inbox_comments(business_id, source_id, created_at);
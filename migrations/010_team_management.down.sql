@@ -0,0 +1,5 @@
+-- migrations/010_team_management.down.sql
+
+DROP TABLE IF EXISTS user_invitations;
+
+ALTER TABLE users DROP COLUMN IF EXISTS deactivated_at;
@@ -0,0 +1,3 @@
+-- migrations/011_sessions.down.sql
+
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,3 @@
+-- migrations/012_api_keys.down.sql
+
+DROP TABLE IF EXISTS api_keys;
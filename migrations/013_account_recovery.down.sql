@@ -0,0 +1,5 @@
+-- migrations/013_account_recovery.down.sql
+
+DROP TABLE IF EXISTS user_tokens;
+
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
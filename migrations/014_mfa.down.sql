@@ -0,0 +1,4 @@
+-- migrations/014_mfa.down.sql
+
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
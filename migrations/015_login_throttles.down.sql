@@ -0,0 +1,3 @@
+-- migrations/015_login_throttles.down.sql
+
+DROP TABLE IF EXISTS login_throttles;
@@ -0,0 +1,12 @@
+-- migrations/015_login_throttles.up.sql
+
+-- Failed login counters per account ("account:<email>") and per client IP
+-- ("ip:<address>"), used when LOGIN_THROTTLE_STORE is postgres
+CREATE TABLE IF NOT EXISTS login_throttles (
+    key VARCHAR(320) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_throttles_last_failure_at ON login_throttles(last_failure_at);
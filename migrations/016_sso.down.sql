@@ -0,0 +1,4 @@
+-- migrations/016_sso.down.sql
+
+DROP TABLE IF EXISTS sso_login_states;
+DROP TABLE IF EXISTS sso_connections;
@@ -0,0 +1,26 @@
+-- migrations/016_sso.up.sql
+
+-- OpenID Connect identity provider per business
+CREATE TABLE IF NOT EXISTS sso_connections (
+    business_id UUID PRIMARY KEY REFERENCES businesses(id) ON DELETE CASCADE,
+    issuer VARCHAR(500) NOT NULL,
+    client_id VARCHAR(255) NOT NULL,
+    encrypted_client_secret TEXT NOT NULL,
+    allowed_domains TEXT[] NOT NULL,
+    default_role VARCHAR(50) NOT NULL DEFAULT 'employee',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Sign-ins waiting for the identity provider's redirect; single use
+CREATE TABLE IF NOT EXISTS sso_login_states (
+    state_hash VARCHAR(64) PRIMARY KEY,
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sso_login_states_expires_at ON sso_login_states(expires_at);
@@ -0,0 +1,3 @@
+-- migrations/017_business_memberships.down.sql
+
+DROP TABLE IF EXISTS business_memberships;
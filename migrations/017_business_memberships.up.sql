@@ -0,0 +1,17 @@
+-- migrations/017_business_memberships.up.sql
+
+-- The businesses each user can work in and their role there. users.business_id
+-- stays the home business users sign in to; its membership mirrors users.role.
+CREATE TABLE IF NOT EXISTS business_memberships (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, business_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_business_memberships_business_id ON business_memberships(business_id);
+
+INSERT INTO business_memberships (user_id, business_id, role, created_at)
+SELECT id, business_id, role, created_at FROM users
+ON CONFLICT (user_id, business_id) DO NOTHING;
@@ -0,0 +1,4 @@
+-- migrations/018_agencies.down.sql
+
+DROP INDEX IF EXISTS idx_businesses_parent_id;
+ALTER TABLE businesses DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,7 @@
+-- migrations/018_agencies.up.sql
+
+-- Agencies manage child businesses. The hierarchy is one level deep, and an
+-- agency with children cannot be deleted.
+ALTER TABLE businesses ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES businesses(id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_businesses_parent_id ON businesses(parent_id, created_at DESC);
@@ -0,0 +1,4 @@
+-- migrations/019_audit_log.down.sql
+
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_prevent_update();
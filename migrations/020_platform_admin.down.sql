@@ -0,0 +1,6 @@
+-- migrations/020_platform_admin.down.sql
+
+DROP TABLE IF EXISTS webhook_events;
+ALTER TABLE businesses DROP COLUMN IF EXISTS suspension_reason;
+ALTER TABLE businesses DROP COLUMN IF EXISTS suspended_at;
+ALTER TABLE users DROP COLUMN IF EXISTS platform_admin;
@@ -0,0 +1,23 @@
+-- migrations/020_platform_admin.up.sql
+
+-- Platform operators run the service across every business. The flag is only
+-- set in the database; no endpoint grants it.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS platform_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- A suspended business cannot sign in or make or take calls
+ALTER TABLE businesses ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMP;
+ALTER TABLE businesses ADD COLUMN IF NOT EXISTS suspension_reason TEXT;
+
+-- Webhooks received from the voice provider, kept for operators to inspect
+CREATE TABLE IF NOT EXISTS webhook_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    event_type VARCHAR(100) NOT NULL,
+    provider_call_id VARCHAR(255),
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    payload JSONB,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_events_provider_call_id ON webhook_events(provider_call_id, received_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_events_received_at ON webhook_events(received_at);
@@ -0,0 +1,25 @@
+-- migrations/021_row_level_security.down.sql
+
+DO $$
+DECLARE
+    tenant_table text;
+BEGIN
+    FOREACH tenant_table IN ARRAY ARRAY[
+        'businesses', 'calls', 'interactions', 'transcripts', 'appointments',
+        'do_not_call', 'consent_records', 'campaigns', 'campaign_contacts',
+        'scheduled_calls', 'transfer_destinations', 'on_call_shifts',
+        'message_recipients', 'messages', 'inbox_item_states', 'inbox_comments',
+        'user_invitations', 'sessions', 'api_keys', 'sso_connections',
+        'sso_login_states', 'business_memberships', 'audit_log'
+    ] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', tenant_table);
+        EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', tenant_table);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', tenant_table);
+    END LOOP;
+END;
+$$;
+
+DROP POLICY IF EXISTS global_entries ON do_not_call;
+
+DROP FUNCTION IF EXISTS app_business_visible(uuid);
+DROP FUNCTION IF EXISTS app_current_business_id();
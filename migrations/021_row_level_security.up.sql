@@ -0,0 +1,79 @@
+-- migrations/021_row_level_security.up.sql
+
+-- Row-level security on every tenant table. Requests scope their transaction
+-- with set_config('app.current_business_id', <id>, true) and then only see
+-- and write the rows of that business and, for an agency, of its children.
+-- Work that is not scoped to a business (webhooks, workers, sign-in and the
+-- platform admin routes) leaves the setting unset and sees every row.
+--
+-- The application must connect as a role that is neither a superuser nor
+-- has BYPASSRLS, or these policies are ignored. FORCE applies them to the
+-- table owner too.
+
+CREATE OR REPLACE FUNCTION app_current_business_id() RETURNS uuid AS $$
+    SELECT NULLIF(current_setting('app.current_business_id', true), '')::uuid;
+$$ LANGUAGE sql STABLE;
+
+-- True when the transaction is unscoped, or the business is the current one
+-- or one of its children
+CREATE OR REPLACE FUNCTION app_business_visible(target uuid) RETURNS boolean AS $$
+    SELECT app_current_business_id() IS NULL
+        OR target = app_current_business_id()
+        OR EXISTS (
+            SELECT 1 FROM businesses
+            WHERE businesses.id = target AND businesses.parent_id = app_current_business_id()
+        );
+$$ LANGUAGE sql STABLE;
+
+-- Businesses are matched on their own columns; going through
+-- app_business_visible would make the policy query itself
+ALTER TABLE businesses ENABLE ROW LEVEL SECURITY;
+ALTER TABLE businesses FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON businesses;
+CREATE POLICY tenant_isolation ON businesses
+    USING (app_current_business_id() IS NULL OR id = app_current_business_id() OR parent_id = app_current_business_id());
+
+-- Tables that carry their business
+DO $$
+DECLARE
+    tenant_table text;
+BEGIN
+    FOREACH tenant_table IN ARRAY ARRAY[
+        'calls', 'appointments', 'do_not_call', 'consent_records', 'campaigns',
+        'scheduled_calls', 'transfer_destinations', 'on_call_shifts',
+        'message_recipients', 'messages', 'inbox_item_states', 'inbox_comments',
+        'user_invitations', 'sessions', 'api_keys', 'sso_connections',
+        'sso_login_states', 'business_memberships', 'audit_log'
+    ] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', tenant_table);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', tenant_table);
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', tenant_table);
+        EXECUTE format('CREATE POLICY tenant_isolation ON %I USING (app_business_visible(business_id))', tenant_table);
+    END LOOP;
+END;
+$$;
+
+-- Global do-not-call entries are readable by every business but only
+-- written unscoped
+DROP POLICY IF EXISTS global_entries ON do_not_call;
+CREATE POLICY global_entries ON do_not_call FOR SELECT USING (business_id IS NULL);
+
+-- Tables that belong to a business through their call or campaign. The
+-- subqueries are themselves filtered by the calls and campaigns policies.
+ALTER TABLE interactions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE interactions FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON interactions;
+CREATE POLICY tenant_isolation ON interactions
+    USING (EXISTS (SELECT 1 FROM calls WHERE calls.id = interactions.call_id));
+
+ALTER TABLE transcripts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE transcripts FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON transcripts;
+CREATE POLICY tenant_isolation ON transcripts
+    USING (EXISTS (SELECT 1 FROM calls WHERE calls.id = transcripts.call_id));
+
+ALTER TABLE campaign_contacts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE campaign_contacts FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON campaign_contacts;
+CREATE POLICY tenant_isolation ON campaign_contacts
+    USING (EXISTS (SELECT 1 FROM campaigns WHERE campaigns.id = campaign_contacts.campaign_id));
@@ -0,0 +1,3 @@
+-- migrations/022_onboarding.down.sql
+
+DROP TABLE IF EXISTS onboardings;
@@ -0,0 +1,21 @@
+-- migrations/022_onboarding.up.sql
+
+-- Progress of setting a business up to take calls. Steps are recorded as
+-- they finish so a failed onboarding resumes where it stopped.
+CREATE TABLE IF NOT EXISTS onboardings (
+    business_id UUID PRIMARY KEY REFERENCES businesses(id) ON DELETE CASCADE,
+    profile JSONB NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    steps JSONB NOT NULL,
+    assistant_id VARCHAR(255) NOT NULL DEFAULT '',
+    phone_number_id VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP
+);
+
+-- Scoped to the business like the tables in 021_row_level_security
+ALTER TABLE onboardings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE onboardings FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON onboardings;
+CREATE POLICY tenant_isolation ON onboardings USING (app_business_visible(business_id));
@@ -0,0 +1,3 @@
+-- migrations/023_assistant_templates.down.sql
+
+DROP TABLE IF EXISTS assistant_template_overrides;
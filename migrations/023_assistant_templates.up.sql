@@ -0,0 +1,21 @@
+-- migrations/023_assistant_templates.up.sql
+
+-- A business's changes to the assistant template for its type. NULL lists
+-- keep the template's; an empty list replaces them with nothing.
+CREATE TABLE IF NOT EXISTS assistant_template_overrides (
+    business_id UUID PRIMARY KEY REFERENCES businesses(id) ON DELETE CASCADE,
+    template VARCHAR(100) NOT NULL DEFAULT '',
+    prompt TEXT NOT NULL DEFAULT '',
+    first_message TEXT NOT NULL DEFAULT '',
+    functions JSONB,
+    interaction_fields JSONB,
+    services JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Scoped to the business like the tables in 021_row_level_security
+ALTER TABLE assistant_template_overrides ENABLE ROW LEVEL SECURITY;
+ALTER TABLE assistant_template_overrides FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON assistant_template_overrides;
+CREATE POLICY tenant_isolation ON assistant_template_overrides USING (app_business_visible(business_id));
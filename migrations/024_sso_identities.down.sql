@@ -0,0 +1,5 @@
+-- migrations/024_sso_identities.down.sql
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS sso;
+ALTER TABLE sso_login_states DROP COLUMN IF EXISTS user_id;
+DROP TABLE IF EXISTS sso_identities;
@@ -0,0 +1,26 @@
+-- migrations/024_sso_identities.up.sql
+
+-- Identity provider accounts linked to users, matched on the issuer and
+-- subject the provider vouches for rather than on the email address it sends
+CREATE TABLE IF NOT EXISTS sso_identities (
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    issuer VARCHAR(500) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (business_id, issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sso_identities_user_id ON sso_identities(user_id);
+
+ALTER TABLE sso_identities ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sso_identities FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON sso_identities;
+CREATE POLICY tenant_isolation ON sso_identities USING (app_business_visible(business_id));
+
+-- A sign-in started by a signed-in user links the identity to them
+ALTER TABLE sso_login_states ADD COLUMN IF NOT EXISTS user_id UUID REFERENCES users(id) ON DELETE CASCADE;
+
+-- Sessions opened through a business's identity provider stay in that
+-- business
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS sso BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,34 @@
+-- migrations/025_rls_fail_closed.down.sql
+
+DO $$
+DECLARE
+    rls_table text;
+BEGIN
+    FOREACH rls_table IN ARRAY ARRAY['users', 'user_tokens', 'user_mfa', 'mfa_recovery_codes'] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', rls_table);
+        EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', rls_table);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', rls_table);
+    END LOOP;
+    FOREACH rls_table IN ARRAY ARRAY['login_throttles', 'webhook_events'] LOOP
+        EXECUTE format('DROP POLICY IF EXISTS system_only ON %I', rls_table);
+        EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', rls_table);
+        EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', rls_table);
+    END LOOP;
+END;
+$$;
+
+-- Back to 021's policies, which show every row to unscoped work
+CREATE OR REPLACE FUNCTION app_business_visible(target uuid) RETURNS boolean AS $$
+    SELECT app_current_business_id() IS NULL
+        OR target = app_current_business_id()
+        OR EXISTS (
+            SELECT 1 FROM businesses
+            WHERE businesses.id = target AND businesses.parent_id = app_current_business_id()
+        );
+$$ LANGUAGE sql STABLE;
+
+DROP POLICY IF EXISTS tenant_isolation ON businesses;
+CREATE POLICY tenant_isolation ON businesses
+    USING (app_current_business_id() IS NULL OR id = app_current_business_id() OR parent_id = app_current_business_id());
+
+DROP FUNCTION IF EXISTS app_rls_bypassed();
@@ -0,0 +1,70 @@
+-- migrations/025_rls_fail_closed.up.sql
+
+-- Row-level security fails closed: a transaction that has not set
+-- app.current_business_id sees no tenant rows instead of every row. Work
+-- that is not scoped to one business (webhooks, workers, sign-in and the
+-- /me and /admin routes) runs on connections that set app.bypass_rls, which
+-- only the application's system connection pool does.
+
+CREATE OR REPLACE FUNCTION app_rls_bypassed() RETURNS boolean AS $$
+    SELECT COALESCE(current_setting('app.bypass_rls', true), '') = 'on';
+$$ LANGUAGE sql STABLE;
+
+-- True when row-level security is bypassed, or the business is the current
+-- one or one of its children. Without a current business only the bypass
+-- grants anything.
+CREATE OR REPLACE FUNCTION app_business_visible(target uuid) RETURNS boolean AS $$
+    SELECT app_rls_bypassed()
+        OR COALESCE(
+            target = app_current_business_id()
+            OR EXISTS (
+                SELECT 1 FROM businesses
+                WHERE businesses.id = target AND businesses.parent_id = app_current_business_id()
+            ),
+            false
+        );
+$$ LANGUAGE sql STABLE;
+
+DROP POLICY IF EXISTS tenant_isolation ON businesses;
+CREATE POLICY tenant_isolation ON businesses
+    USING (app_rls_bypassed() OR COALESCE(id = app_current_business_id() OR parent_id = app_current_business_id(), false));
+
+-- Users are visible in the business they signed up with and in every
+-- business they are a member of. The subquery is itself filtered by the
+-- business_memberships policy.
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON users;
+CREATE POLICY tenant_isolation ON users
+    USING (
+        app_business_visible(business_id)
+        OR EXISTS (SELECT 1 FROM business_memberships WHERE business_memberships.user_id = users.id)
+    );
+
+-- Tables that belong to a user follow the users policy
+DO $$
+DECLARE
+    user_table text;
+BEGIN
+    FOREACH user_table IN ARRAY ARRAY['user_tokens', 'user_mfa', 'mfa_recovery_codes'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', user_table);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', user_table);
+        EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', user_table);
+        EXECUTE format('CREATE POLICY tenant_isolation ON %I USING (EXISTS (SELECT 1 FROM users WHERE users.id = %I.user_id))', user_table, user_table);
+    END LOOP;
+END;
+$$;
+
+-- Tables no business owns are for system work only
+DO $$
+DECLARE
+    system_table text;
+BEGIN
+    FOREACH system_table IN ARRAY ARRAY['login_throttles', 'webhook_events'] LOOP
+        EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', system_table);
+        EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', system_table);
+        EXECUTE format('DROP POLICY IF EXISTS system_only ON %I', system_table);
+        EXECUTE format('CREATE POLICY system_only ON %I USING (app_rls_bypassed())', system_table);
+    END LOOP;
+END;
+$$;
@@ -0,0 +1,3 @@
+-- migrations/026_onboarding_provider_ids.down.sql
+
+DROP TABLE IF EXISTS onboarding_provider_ids;
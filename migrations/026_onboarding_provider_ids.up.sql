@@ -0,0 +1,20 @@
+-- migrations/026_onboarding_provider_ids.up.sql
+
+-- The assistant and phone number the voice provider created for an
+-- onboarding. They are written in a transaction of their own as soon as the
+-- provider returns them, so a request that rolls back afterwards does not
+-- forget them and a retry does not create them again. They are kept apart
+-- from onboardings because the request holds that row locked while it runs.
+CREATE TABLE IF NOT EXISTS onboarding_provider_ids (
+    business_id UUID NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+    step VARCHAR(50) NOT NULL,
+    provider_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (business_id, step)
+);
+
+-- Scoped to the business like the tables in 021_row_level_security
+ALTER TABLE onboarding_provider_ids ENABLE ROW LEVEL SECURITY;
+ALTER TABLE onboarding_provider_ids FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON onboarding_provider_ids;
+CREATE POLICY tenant_isolation ON onboarding_provider_ids USING (app_business_visible(business_id));
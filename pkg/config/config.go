@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,6 +13,12 @@ type Config struct {
 	Database DatabaseConfig
 	JWT      JWTConfig
 	Vapi     VapiConfig
+	Mail     MailConfig
+	MFA      MFAConfig
+	SSO      SSOConfig
+	Login    LoginThrottleConfig
+	Audit    AuditConfig
+	Webhooks WebhookLogConfig
 	Logger   LoggerConfig
 }
 
@@ -30,8 +37,20 @@ type DatabaseConfig struct {
 	ConnMaxLifetime time.Duration
 }
 
+// JWTConfig configures access tokens. They are signed with the private key in
+// SigningKeyFile (RSA or Ed25519) when set, and with SecretKey (HS256)
+// otherwise. VerificationKeyFiles holds keys rotated out of signing, which
+// are still accepted until the tokens they signed expire. When both are set,
+// tokens signed with SecretKey are accepted until SharedSecretUntil, to
+// switch from the secret to a key without signing anyone out. The cut-off is
+// a fixed time, so restarts do not extend it; without it they are refused.
 type JWTConfig struct {
 	SecretKey            string
+	SigningKeyFile       string
+	VerificationKeyFiles []string
+	SharedSecretUntil    time.Time
+	Issuer               string
+	Audience             string
 	AccessTokenDuration  time.Duration
 	RefreshTokenDuration time.Duration
 }
@@ -40,6 +59,70 @@ type VapiConfig struct {
 	APIKey     string
 	WebhookURL string
 	APIBaseURL string
+	// PhoneCredentialID is the SIP trunk credential businesses' own numbers
+	// are imported over during onboarding
+	PhoneCredentialID string
+}
+
+// MailConfig selects how transactional emails are delivered: over SMTP, as
+// files in FileDir, or to the log
+type MailConfig struct {
+	Driver       string // smtp, file or log
+	From         string
+	AppURL       string // base URL of the web app, used in emailed links
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	FileDir      string
+}
+
+// MFAConfig configures TOTP two-factor authentication. EncryptionKey protects
+// the stored TOTP secrets and SSO client secrets. It is required and must
+// not be the JWT secret key, so that a leaked signing key does not expose
+// the secrets too.
+type MFAConfig struct {
+	Issuer        string
+	EncryptionKey string
+}
+
+// SSOConfig configures single sign-on. RedirectURL is the web app page the
+// identity provider sends users back to; it must be registered with each
+// provider and defaults to APP_URL + "/sso/callback".
+type SSOConfig struct {
+	RedirectURL string
+}
+
+// LoginThrottleConfig configures brute-force protection for logins. Each
+// failure delays the next attempt for the same account or IP, doubling from
+// BaseDelay up to MaxDelay; MaxAccountFailures or MaxIPFailures failures
+// within Window lock the key out for LockoutDuration. Client IPs are read
+// from X-Forwarded-For only when the request comes from one of
+// TrustedProxies.
+type LoginThrottleConfig struct {
+	Store              string // postgres or memory
+	MaxAccountFailures int
+	MaxIPFailures      int
+	Window             time.Duration
+	LockoutDuration    time.Duration
+	BaseDelay          time.Duration
+	MaxDelay           time.Duration
+	TrustedProxies     []string
+}
+
+// AuditConfig configures the audit log. Entries older than Retention are
+// deleted every PurgeInterval; a zero Retention keeps them forever.
+type AuditConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// WebhookLogConfig configures the log of provider webhooks kept for
+// operators. Events older than Retention are deleted every PurgeInterval; a
+// zero Retention keeps them forever.
+type WebhookLogConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
 }
 
 type LoggerConfig struct {
@@ -48,6 +131,11 @@ type LoggerConfig struct {
 }
 
 func Load() (*Config, error) {
+	sharedSecretUntil, err := getTimeEnv("JWT_SHARED_SECRET_UNTIL")
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
 		Server: ServerConfig{
 			Port:            getEnv("SERVER_PORT", "8080"),
@@ -64,13 +152,54 @@ func Load() (*Config, error) {
 		},
 		JWT: JWTConfig{
 			SecretKey:            getEnv("JWT_SECRET_KEY", ""),
+			SigningKeyFile:       getEnv("JWT_SIGNING_KEY_FILE", ""),
+			VerificationKeyFiles: getListEnv("JWT_VERIFICATION_KEY_FILES"),
+			SharedSecretUntil:    sharedSecretUntil,
+			Issuer:               getEnv("JWT_ISSUER", "vapi-integration"),
+			Audience:             getEnv("JWT_AUDIENCE", "callpilot-api"),
 			AccessTokenDuration:  getDurationEnv("JWT_ACCESS_TOKEN_DURATION", 15*time.Minute),
 			RefreshTokenDuration: getDurationEnv("JWT_REFRESH_TOKEN_DURATION", 7*24*time.Hour),
 		},
 		Vapi: VapiConfig{
-			APIKey:     getEnv("VAPI_API_KEY", ""),
-			WebhookURL: getEnv("VAPI_WEBHOOK_URL", ""),
-			APIBaseURL: getEnv("VAPI_API_BASE_URL", "https://api.vapi.ai"),
+			APIKey:            getEnv("VAPI_API_KEY", ""),
+			WebhookURL:        getEnv("VAPI_WEBHOOK_URL", ""),
+			APIBaseURL:        getEnv("VAPI_API_BASE_URL", "https://api.vapi.ai"),
+			PhoneCredentialID: getEnv("VAPI_PHONE_CREDENTIAL_ID", ""),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "no-reply@callpilot.local"),
+			AppURL:       getEnv("APP_URL", "http://localhost:3000"),
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getIntEnv("SMTP_PORT", 587),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			FileDir:      getEnv("MAIL_FILE_DIR", "tmp/mail"),
+		},
+		MFA: MFAConfig{
+			Issuer:        getEnv("MFA_ISSUER", "CallPilot"),
+			EncryptionKey: getEnv("MFA_ENCRYPTION_KEY", ""),
+		},
+		SSO: SSOConfig{
+			RedirectURL: getEnv("SSO_REDIRECT_URL", ""),
+		},
+		Login: LoginThrottleConfig{
+			Store:              getEnv("LOGIN_THROTTLE_STORE", "postgres"),
+			MaxAccountFailures: getIntEnv("LOGIN_MAX_ACCOUNT_FAILURES", 5),
+			MaxIPFailures:      getIntEnv("LOGIN_MAX_IP_FAILURES", 50),
+			Window:             getDurationEnv("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			LockoutDuration:    getDurationEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			BaseDelay:          getDurationEnv("LOGIN_BASE_DELAY", time.Second),
+			MaxDelay:           getDurationEnv("LOGIN_MAX_DELAY", 30*time.Second),
+			TrustedProxies:     getListEnv("LOGIN_TRUSTED_PROXIES"),
+		},
+		Audit: AuditConfig{
+			Retention:     time.Duration(getIntEnv("AUDIT_RETENTION_DAYS", 365)) * 24 * time.Hour,
+			PurgeInterval: getDurationEnv("AUDIT_PURGE_INTERVAL", time.Hour),
+		},
+		Webhooks: WebhookLogConfig{
+			Retention:     time.Duration(getIntEnv("WEBHOOK_LOG_RETENTION_DAYS", 30)) * 24 * time.Hour,
+			PurgeInterval: getDurationEnv("WEBHOOK_LOG_PURGE_INTERVAL", time.Hour),
 		},
 		Logger: LoggerConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
@@ -78,6 +207,10 @@ func Load() (*Config, error) {
 		},
 	}
 
+	if cfg.SSO.RedirectURL == "" {
+		cfg.SSO.RedirectURL = strings.TrimSuffix(cfg.Mail.AppURL, "/") + "/sso/callback"
+	}
+
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
@@ -89,12 +222,24 @@ func (c *Config) Validate() error {
 	if c.Database.URL == "" {
 		return fmt.Errorf("DATABASE_URL is required")
 	}
-	if c.JWT.SecretKey == "" {
-		return fmt.Errorf("JWT_SECRET_KEY is required")
+	if c.JWT.SecretKey == "" && c.JWT.SigningKeyFile == "" {
+		return fmt.Errorf("JWT_SECRET_KEY or JWT_SIGNING_KEY_FILE is required")
+	}
+	if c.MFA.EncryptionKey == "" {
+		return fmt.Errorf("MFA_ENCRYPTION_KEY is required")
+	}
+	if c.MFA.EncryptionKey == c.JWT.SecretKey {
+		return fmt.Errorf("MFA_ENCRYPTION_KEY must differ from JWT_SECRET_KEY")
 	}
 	if c.Vapi.APIKey == "" {
 		return fmt.Errorf("VAPI_API_KEY is required")
 	}
+	if c.Mail.Driver == "smtp" && c.Mail.SMTPHost == "" {
+		return fmt.Errorf("SMTP_HOST is required when MAIL_DRIVER is smtp")
+	}
+	if c.Login.Store != "postgres" && c.Login.Store != "memory" {
+		return fmt.Errorf("LOGIN_THROTTLE_STORE must be postgres or memory")
+	}
 	return nil
 }
 
@@ -122,3 +267,29 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// getTimeEnv reads an RFC 3339 time, which is zero when unset. Unlike the
+// other settings a malformed value is an error rather than the default, as
+// it is a security cut-off.
+func getTimeEnv(key string) (time.Time, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 time: %w", key, err)
+	}
+	return parsed, nil
+}
+
+// getListEnv reads a comma-separated list, dropping empty entries
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
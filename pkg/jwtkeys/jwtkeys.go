@@ -0,0 +1,309 @@
+// Package jwtkeys loads the asymmetric keys access tokens are signed with and
+// publishes their public halves as a JSON Web Key Set (RFC 7517). Keys are
+// identified by their RFC 7638 thumbprint, used as the token's "kid" header.
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+const (
+	// AlgorithmRS256 is RSA PKCS #1 v1.5 with SHA-256
+	AlgorithmRS256 = "RS256"
+	// AlgorithmEdDSA is Ed25519
+	AlgorithmEdDSA = "EdDSA"
+
+	// minRSABits is the smallest RSA modulus accepted
+	minRSABits = 2048
+)
+
+var ErrUnsupportedKey = errors.New("unsupported key type: use RSA or Ed25519")
+
+// Key is a signing or verification key. Private is nil for keys that are only
+// kept to verify tokens signed before a rotation.
+type Key struct {
+	ID        string
+	Algorithm string
+	Private   crypto.Signer
+	Public    crypto.PublicKey
+}
+
+// NewKey wraps a private or public RSA or Ed25519 key
+func NewKey(key interface{}) (*Key, error) {
+	k := &Key{}
+	switch typed := key.(type) {
+	case *rsa.PrivateKey:
+		k.Private, k.Public = typed, &typed.PublicKey
+	case ed25519.PrivateKey:
+		k.Private, k.Public = typed, typed.Public()
+	case *rsa.PublicKey, ed25519.PublicKey:
+		k.Public = typed
+	default:
+		return nil, ErrUnsupportedKey
+	}
+
+	switch public := k.Public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSABits)
+		}
+		k.Algorithm = AlgorithmRS256
+	case ed25519.PublicKey:
+		k.Algorithm = AlgorithmEdDSA
+	}
+
+	k.ID = thumbprint(k.jwk())
+	return k, nil
+}
+
+// ParsePEM reads a PEM encoded PKCS #8, PKCS #1 or PKIX key
+func ParsePEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKey(key)
+}
+
+// LoadFile reads a PEM encoded key from path
+func LoadFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ParsePEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// KeySet is the key new tokens are signed with plus the keys tokens are still
+// accepted from. Keeping a rotated-out key here until the tokens it signed
+// expire means rotation signs nobody out.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+	order   []string
+}
+
+// NewKeySet builds a key set. The signing key is also a verification key.
+func NewKeySet(signing *Key, verification ...*Key) (*KeySet, error) {
+	if signing == nil || signing.Private == nil {
+		return nil, errors.New("the signing key must be a private key")
+	}
+
+	ks := &KeySet{signing: signing, keys: make(map[string]*Key)}
+	for _, key := range append([]*Key{signing}, verification...) {
+		if _, ok := ks.keys[key.ID]; ok {
+			continue
+		}
+		ks.keys[key.ID] = key
+		ks.order = append(ks.order, key.ID)
+	}
+	return ks, nil
+}
+
+// Load reads the signing key and the extra verification keys from PEM files
+func Load(signingKeyFile string, verificationKeyFiles []string) (*KeySet, error) {
+	signing, err := LoadFile(signingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := make([]*Key, 0, len(verificationKeyFiles))
+	for _, path := range verificationKeyFiles {
+		key, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		verification = append(verification, key)
+	}
+
+	return NewKeySet(signing, verification...)
+}
+
+// SigningKey returns the key new tokens are signed with
+func (ks *KeySet) SigningKey() *Key {
+	return ks.signing
+}
+
+// Lookup returns the verification key with the given ID
+func (ks *KeySet) Lookup(id string) (*Key, bool) {
+	key, ok := ks.keys[id]
+	return key, ok
+}
+
+// Algorithms returns the algorithms of the verification keys
+func (ks *KeySet) Algorithms() []string {
+	seen := make(map[string]bool)
+	var algorithms []string
+	for _, id := range ks.order {
+		if alg := ks.keys[id].Algorithm; !seen[alg] {
+			seen[alg] = true
+			algorithms = append(algorithms, alg)
+		}
+	}
+	return algorithms
+}
+
+// JWK is the public half of a key in JSON Web Key form
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid,omitempty"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public verification keys
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: make([]JWK, 0, len(ks.order))}
+	for _, id := range ks.order {
+		key := ks.keys[id]
+		jwk := key.jwk()
+		jwk.KeyID = key.ID
+		jwk.Use = "sig"
+		jwk.Algorithm = key.Algorithm
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// PublicKey decodes an RSA, P-256 or Ed25519 public key, as published by
+// identity providers in their JWKS
+func (j JWK) PublicKey() (crypto.PublicKey, error) {
+	switch {
+	case j.KeyType == "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+
+	case j.KeyType == "EC" && j.Curve == "P-256":
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("EC point is not on the curve")
+		}
+		return key, nil
+
+	case j.KeyType == "OKP" && j.Curve == "Ed25519":
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key length")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, ErrUnsupportedKey
+}
+
+// jwk returns the required members of the public key, the input to its
+// thumbprint
+func (k *Key) jwk() JWK {
+	switch public := k.Public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			KeyType: "RSA",
+			N:       encode(public.N.Bytes()),
+			E:       encode(big.NewInt(int64(public.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return JWK{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       encode(public),
+		}
+	}
+	return JWK{}
+}
+
+// thumbprint computes the RFC 7638 thumbprint. encoding/json writes struct
+// fields in declaration order, so the members are listed in the
+// lexicographic order the RFC requires.
+func thumbprint(jwk JWK) string {
+	var members interface{}
+	if jwk.KeyType == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.KeyType, jwk.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Curve, jwk.KeyType, jwk.X}
+	}
+
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return encode(sum[:])
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
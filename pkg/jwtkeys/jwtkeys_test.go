@@ -0,0 +1,154 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+)
+
+func decode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatalf("failed to decode %q: %v", s, err)
+	}
+	return b
+}
+
+func TestNewKey_Thumbprint(t *testing.T) {
+	// The examples of RFC 7638 section 3.1 and RFC 8037 appendix A.3
+	rsaKey := &rsa.PublicKey{
+		N: new(big.Int).SetBytes(decode(t, "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")),
+		E: 65537,
+	}
+	edKey := ed25519.PublicKey(decode(t, "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"))
+
+	tests := []struct {
+		name    string
+		key     interface{}
+		wantID  string
+		wantAlg string
+	}{
+		{"RSA", rsaKey, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", AlgorithmRS256},
+		{"Ed25519", edKey, "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k", AlgorithmEdDSA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := NewKey(tt.key)
+			if err != nil {
+				t.Fatalf("NewKey() error = %v", err)
+			}
+			if key.ID != tt.wantID || key.Algorithm != tt.wantAlg {
+				t.Errorf("NewKey() = %s %s, want %s %s", key.ID, key.Algorithm, tt.wantID, tt.wantAlg)
+			}
+		})
+	}
+}
+
+func TestNewKey_RejectsWeakRSA(t *testing.T) {
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	if _, err := NewKey(weak); err == nil {
+		t.Error("expected a 1024-bit RSA key to be rejected")
+	}
+}
+
+func TestParsePEM(t *testing.T) {
+	_, private, _ := ed25519.GenerateKey(rand.Reader)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	key, err := ParsePEM(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("ParsePEM() error = %v", err)
+	}
+	if key.Private == nil {
+		t.Error("expected a private key")
+	}
+
+	der, _ = x509.MarshalPKIXPublicKey(private.Public())
+	public, err := ParsePEM(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("ParsePEM() error = %v", err)
+	}
+	if public.Private != nil || public.ID != key.ID {
+		t.Errorf("expected the public key to share the private key's ID")
+	}
+
+	if _, err := ParsePEM([]byte("not a key")); err == nil {
+		t.Error("expected garbage to be rejected")
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	_, current, _ := ed25519.GenerateKey(rand.Reader)
+	previous, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	signing, _ := NewKey(current)
+	old, _ := NewKey(&previous.PublicKey)
+
+	if _, err := NewKeySet(old); err == nil {
+		t.Error("expected a public key to be refused as the signing key")
+	}
+
+	ks, err := NewKeySet(signing, old, signing)
+	if err != nil {
+		t.Fatalf("NewKeySet() error = %v", err)
+	}
+	if _, ok := ks.Lookup(old.ID); !ok {
+		t.Error("expected the rotated-out key to still verify")
+	}
+
+	set := ks.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(set.Keys))
+	}
+	if set.Keys[0].KeyID != signing.ID || set.Keys[0].KeyType != "OKP" || set.Keys[0].Use != "sig" {
+		t.Errorf("unexpected signing JWK %+v", set.Keys[0])
+	}
+	if set.Keys[1].Algorithm != AlgorithmRS256 || set.Keys[1].E != "AQAB" {
+		t.Errorf("unexpected RSA JWK %+v", set.Keys[1])
+	}
+
+	if algs := ks.Algorithms(); len(algs) != 2 {
+		t.Errorf("Algorithms() = %v", algs)
+	}
+}
+
+func TestJWK_PublicKey(t *testing.T) {
+	previous, _ := rsa.GenerateKey(rand.Reader, 2048)
+	_, current, _ := ed25519.GenerateKey(rand.Reader)
+	rsaKey, _ := NewKey(previous)
+	edKey, _ := NewKey(current)
+	ks, _ := NewKeySet(edKey, rsaKey)
+
+	for _, jwk := range ks.JWKS().Keys {
+		public, err := jwk.PublicKey()
+		if err != nil {
+			t.Fatalf("PublicKey() error = %v", err)
+		}
+		key, err := NewKey(public)
+		if err != nil {
+			t.Fatalf("NewKey() error = %v", err)
+		}
+		if key.ID != jwk.KeyID {
+			t.Errorf("round trip changed the key: %s, want %s", key.ID, jwk.KeyID)
+		}
+	}
+
+	if _, err := (JWK{KeyType: "EC", Curve: "P-256", X: "AQ", Y: "AQ"}).PublicKey(); err == nil {
+		t.Error("expected a point off the curve to be rejected")
+	}
+	if _, err := (JWK{KeyType: "oct"}).PublicKey(); err == nil {
+		t.Error("expected symmetric keys to be rejected")
+	}
+}
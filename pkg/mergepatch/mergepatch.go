@@ -0,0 +1,51 @@
+// Package mergepatch applies JSON merge patches (RFC 7396): members of the
+// patch replace the target's, null removes them, and objects merge
+// recursively.
+package mergepatch
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidJSON = errors.New("invalid JSON")
+
+// Apply returns the target document with the patch applied. An empty target
+// is treated as null.
+func Apply(target, patch []byte) ([]byte, error) {
+	var targetValue interface{}
+	if len(target) > 0 {
+		if err := json.Unmarshal(target, &targetValue); err != nil {
+			return nil, ErrInvalidJSON
+		}
+	}
+
+	var patchValue interface{}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, ErrInvalidJSON
+	}
+
+	return json.Marshal(merge(targetValue, patchValue))
+}
+
+// merge is the MergePatch function of RFC 7396, section 2
+func merge(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{})
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = merge(targetObject[key], value)
+	}
+	return targetObject
+}
@@ -0,0 +1,204 @@
+// Package phone parses user-entered phone numbers and normalises them to
+// E.164 so the same number always compares equal across the system.
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultRegion is used when a business has not chosen a region
+const DefaultRegion = "US"
+
+var (
+	ErrInvalidNumber = errors.New("invalid phone number")
+	ErrUnknownRegion = errors.New("unknown phone region")
+)
+
+// Region describes how national numbers are written in a country
+type Region struct {
+	Code              string
+	CallingCode       string
+	TrunkPrefix       string
+	MinNationalLength int
+	MaxNationalLength int
+}
+
+var regions = map[string]Region{
+	"US": {Code: "US", CallingCode: "1", MinNationalLength: 10, MaxNationalLength: 10},
+	"CA": {Code: "CA", CallingCode: "1", MinNationalLength: 10, MaxNationalLength: 10},
+	"GB": {Code: "GB", CallingCode: "44", TrunkPrefix: "0", MinNationalLength: 9, MaxNationalLength: 10},
+	"IE": {Code: "IE", CallingCode: "353", TrunkPrefix: "0", MinNationalLength: 7, MaxNationalLength: 9},
+	"AU": {Code: "AU", CallingCode: "61", TrunkPrefix: "0", MinNationalLength: 9, MaxNationalLength: 9},
+	"NZ": {Code: "NZ", CallingCode: "64", TrunkPrefix: "0", MinNationalLength: 8, MaxNationalLength: 10},
+	"DE": {Code: "DE", CallingCode: "49", TrunkPrefix: "0", MinNationalLength: 6, MaxNationalLength: 13},
+	"FR": {Code: "FR", CallingCode: "33", TrunkPrefix: "0", MinNationalLength: 9, MaxNationalLength: 9},
+	"ES": {Code: "ES", CallingCode: "34", MinNationalLength: 9, MaxNationalLength: 9},
+	"IT": {Code: "IT", CallingCode: "39", MinNationalLength: 6, MaxNationalLength: 11},
+	"NL": {Code: "NL", CallingCode: "31", TrunkPrefix: "0", MinNationalLength: 9, MaxNationalLength: 9},
+	"PL": {Code: "PL", CallingCode: "48", MinNationalLength: 9, MaxNationalLength: 9},
+	"MX": {Code: "MX", CallingCode: "52", MinNationalLength: 10, MaxNationalLength: 10},
+	"BR": {Code: "BR", CallingCode: "55", TrunkPrefix: "0", MinNationalLength: 10, MaxNationalLength: 11},
+	"IN": {Code: "IN", CallingCode: "91", TrunkPrefix: "0", MinNationalLength: 10, MaxNationalLength: 10},
+	"ZA": {Code: "ZA", CallingCode: "27", TrunkPrefix: "0", MinNationalLength: 9, MaxNationalLength: 9},
+}
+
+// primaryRegions resolves a calling code shared by several regions to the one
+// whose numbering rules are used for validation
+var primaryRegions = map[string]string{
+	"1": "US",
+}
+
+// LookupRegion returns the numbering rules for an ISO 3166 region code
+func LookupRegion(code string) (Region, bool) {
+	region, ok := regions[strings.ToUpper(code)]
+	return region, ok
+}
+
+// IsSupportedRegion reports whether numbers can be parsed for the region
+func IsSupportedRegion(code string) bool {
+	_, ok := LookupRegion(code)
+	return ok
+}
+
+// Normalize parses a number written in national or international format and
+// returns it in E.164. National numbers are read using defaultRegion.
+func Normalize(raw, defaultRegion string) (string, error) {
+	if defaultRegion == "" {
+		defaultRegion = DefaultRegion
+	}
+	region, ok := LookupRegion(defaultRegion)
+	if !ok {
+		return "", ErrUnknownRegion
+	}
+
+	digits, international, err := extractDigits(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if !international {
+		switch {
+		case strings.HasPrefix(digits, "00"):
+			digits, international = digits[2:], true
+		case region.CallingCode == "1" && strings.HasPrefix(digits, "011"):
+			digits, international = digits[3:], true
+		}
+	}
+
+	if !international {
+		digits = nationalToInternational(digits, region)
+	}
+
+	number := "+" + digits
+	if !IsE164(number) {
+		return "", ErrInvalidNumber
+	}
+	return number, nil
+}
+
+// IsE164 reports whether number is a well-formed E.164 number. Numbers in
+// supported regions are also checked against that region's length rules.
+func IsE164(number string) bool {
+	if !strings.HasPrefix(number, "+") {
+		return false
+	}
+	digits := number[1:]
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	region, ok := regionForDigits(digits)
+	if !ok {
+		return true
+	}
+
+	national := digits[len(region.CallingCode):]
+	if len(national) < region.MinNationalLength || len(national) > region.MaxNationalLength {
+		return false
+	}
+	if region.TrunkPrefix != "" && strings.HasPrefix(national, region.TrunkPrefix) {
+		return false
+	}
+	// North American area codes never start with 0 or 1
+	if region.CallingCode == "1" && (national[0] == '0' || national[0] == '1') {
+		return false
+	}
+	return true
+}
+
+// RegionOf returns the region whose calling code prefixes an E.164 number, or
+// an empty string when the calling code is not supported
+func RegionOf(number string) string {
+	if !strings.HasPrefix(number, "+") {
+		return ""
+	}
+	region, ok := regionForDigits(number[1:])
+	if !ok {
+		return ""
+	}
+	return region.Code
+}
+
+func extractDigits(raw string) (string, bool, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false, ErrInvalidNumber
+	}
+
+	international := strings.HasPrefix(raw, "+")
+	if international {
+		raw = raw[1:]
+	}
+
+	var b strings.Builder
+	for _, c := range raw {
+		switch {
+		case c >= '0' && c <= '9':
+			b.WriteRune(c)
+		case c == ' ' || c == '-' || c == '.' || c == '(' || c == ')' || c == '/':
+			// Formatting characters are ignored
+		default:
+			return "", false, ErrInvalidNumber
+		}
+	}
+
+	if b.Len() == 0 {
+		return "", false, ErrInvalidNumber
+	}
+	return b.String(), international, nil
+}
+
+func nationalToInternational(digits string, region Region) string {
+	if region.TrunkPrefix != "" && strings.HasPrefix(digits, region.TrunkPrefix) {
+		return region.CallingCode + strings.TrimPrefix(digits, region.TrunkPrefix)
+	}
+	// North American numbers are often written with the leading 1
+	if region.CallingCode == "1" && len(digits) == 11 && digits[0] == '1' {
+		return digits
+	}
+	return region.CallingCode + digits
+}
+
+func regionForDigits(digits string) (Region, bool) {
+	for length := 3; length >= 1; length-- {
+		if len(digits) <= length {
+			continue
+		}
+		code := digits[:length]
+		if primary, ok := primaryRegions[code]; ok {
+			return regions[primary], true
+		}
+		for _, region := range regions {
+			if region.CallingCode == code {
+				return region, true
+			}
+		}
+	}
+	return Region{}, false
+}
@@ -0,0 +1,105 @@
+package phone
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		region  string
+		want    string
+		wantErr error
+	}{
+		{"us national", "(555) 123-4567", "US", "+15551234567", nil},
+		{"us with leading one", "1-555-123-4567", "US", "+15551234567", nil},
+		{"us dotted", "555.123.4567", "", "+15551234567", nil},
+		{"already e164", "+15551234567", "US", "+15551234567", nil},
+		{"international from us", "011 44 20 7946 0958", "US", "+442079460958", nil},
+		{"uk national trunk prefix", "020 7946 0958", "GB", "+442079460958", nil},
+		{"uk double zero prefix", "0044 20 7946 0958", "GB", "+442079460958", nil},
+		{"international in other region", "+1 (555) 123-4567", "GB", "+15551234567", nil},
+		{"unknown calling code", "+888 1234 5678", "US", "+88812345678", nil},
+		{"too short", "555-1234", "US", "", ErrInvalidNumber},
+		{"invalid area code", "(155) 123-4567", "US", "", ErrInvalidNumber},
+		{"letters", "555-CALL-NOW", "US", "", ErrInvalidNumber},
+		{"empty", "", "US", "", ErrInvalidNumber},
+		{"unknown region", "5551234567", "XX", "", ErrUnknownRegion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.raw, tt.region)
+
+			if err != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsE164(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"+15551234567", true},
+		{"+442079460958", true},
+		{"15551234567", false},
+		{"+1234567890", false},
+		{"+4402079460958", false},
+		{"+0123456789", false},
+		{"+1555123456789012", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			if got := IsE164(tt.number); got != tt.want {
+				t.Errorf("IsE164(%q) = %v, want %v", tt.number, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegionOf(t *testing.T) {
+	if got := RegionOf("+442079460958"); got != "GB" {
+		t.Errorf("RegionOf() = %v, want GB", got)
+	}
+	if got := RegionOf("+15551234567"); got != "US" {
+		t.Errorf("RegionOf() = %v, want US", got)
+	}
+	if got := RegionOf("+88812345678"); got != "" {
+		t.Errorf("RegionOf() = %v, want empty", got)
+	}
+}
+
+func TestTimeZones(t *testing.T) {
+	tests := []struct {
+		number string
+		want   []string
+	}{
+		{"+14155550100", []string{"America/Los_Angeles"}},
+		{"+12125550100", []string{"America/New_York"}},
+		{"+18085550100", []string{"Pacific/Honolulu"}},
+		{"+15555550100", continentalTimeZones},
+		{"+442079460958", []string{"Europe/London"}},
+		{"+61298765432", []string{"Australia/Sydney"}},
+		{"+88812345678", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			got := TimeZones(tt.number)
+			if len(got) != len(tt.want) {
+				t.Fatalf("TimeZones() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("TimeZones() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
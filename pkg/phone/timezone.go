@@ -0,0 +1,128 @@
+package phone
+
+import "strings"
+
+// North American area codes grouped by the time zone that covers them.
+// Area codes that span several zones, or that are missing here, resolve to
+// every continental zone so callers can apply the strictest interpretation.
+var nanpTimeZones = map[string][]string{
+	"America/Los_Angeles": {
+		"209", "213", "279", "310", "323", "341", "350", "408", "415", "424", "442", "510", "530",
+		"559", "562", "619", "626", "628", "650", "657", "661", "669", "707", "714", "747", "760",
+		"805", "818", "820", "831", "840", "858", "909", "916", "925", "949", "951",
+		"206", "253", "360", "425", "509", "564", "458", "503", "541", "971", "702", "725", "775",
+		"236", "250", "604", "672", "778",
+	},
+	"America/Denver": {
+		"303", "719", "720", "970", "983", "385", "435", "801", "505", "575", "406", "307", "208",
+		"986", "915", "368", "403", "587", "780", "825",
+	},
+	"America/Phoenix": {"480", "520", "602", "623", "928"},
+	"America/Chicago": {
+		"210", "214", "254", "281", "325", "346", "361", "409", "430", "432", "469", "512", "682",
+		"713", "726", "737", "806", "817", "830", "832", "903", "936", "940", "945", "956", "972",
+		"979", "217", "224", "309", "312", "331", "447", "464", "618", "630", "708", "773", "779",
+		"815", "847", "872", "218", "320", "507", "612", "651", "763", "952", "262", "414", "534",
+		"608", "715", "920", "319", "515", "563", "641", "712", "314", "417", "557", "573", "636",
+		"660", "816", "316", "620", "785", "913", "308", "402", "531", "405", "539", "580", "918",
+		"479", "501", "870", "225", "318", "337", "504", "985", "228", "601", "662", "769", "205",
+		"251", "256", "334", "659", "938", "615", "629", "731", "901", "931", "270", "701", "605",
+		"204", "431", "306", "639",
+	},
+	"America/New_York": {
+		"212", "315", "332", "347", "516", "518", "585", "607", "631", "646", "680", "716", "718",
+		"838", "845", "914", "917", "929", "934", "201", "551", "609", "640", "732", "848", "856",
+		"862", "908", "973", "215", "223", "267", "272", "412", "445", "484", "570", "582", "610",
+		"717", "724", "814", "835", "878", "239", "305", "321", "352", "386", "407", "448", "561",
+		"645", "656", "689", "727", "754", "772", "786", "813", "863", "904", "941", "954", "229",
+		"404", "470", "478", "678", "706", "762", "770", "912", "943", "252", "336", "704", "743",
+		"828", "910", "919", "980", "984", "803", "839", "843", "854", "864", "276", "434", "540",
+		"571", "703", "757", "804", "826", "948", "240", "301", "410", "443", "667", "202", "771",
+		"339", "351", "413", "508", "617", "774", "781", "857", "978", "203", "475", "860", "959",
+		"401", "603", "802", "207", "302", "216", "220", "234", "283", "326", "330", "380", "419",
+		"436", "440", "513", "567", "614", "740", "937", "231", "248", "269", "313", "517", "586",
+		"616", "679", "734", "810", "906", "947", "989", "219", "260", "317", "463", "574", "765",
+		"812", "930", "364", "502", "606", "859", "304", "681", "423", "865", "226", "249", "289",
+		"343", "365", "416", "437", "519", "548", "613", "647", "705", "807", "905", "367", "418",
+		"438", "450", "514", "579", "581", "819", "873",
+	},
+	"America/Anchorage": {"907"},
+	"Pacific/Honolulu":  {"808"},
+	"America/Halifax":   {"782", "902"},
+	"America/Moncton":   {"506"},
+	"America/St_Johns":  {"709"},
+}
+
+var continentalTimeZones = []string{
+	"America/New_York", "America/Chicago", "America/Denver", "America/Phoenix", "America/Los_Angeles",
+}
+
+// Zones for regions outside North America, keyed by region and then by the
+// leading digit of the national number where the region spans several zones
+var regionTimeZones = map[string]map[string][]string{
+	"GB": {"": {"Europe/London"}},
+	"IE": {"": {"Europe/Dublin"}},
+	"AU": {
+		"":  {"Australia/Perth", "Australia/Darwin", "Australia/Adelaide", "Australia/Brisbane", "Australia/Sydney"},
+		"2": {"Australia/Sydney"},
+		"3": {"Australia/Melbourne"},
+		"7": {"Australia/Brisbane"},
+		"8": {"Australia/Perth", "Australia/Darwin", "Australia/Adelaide"},
+	},
+	"NZ": {"": {"Pacific/Auckland"}},
+	"DE": {"": {"Europe/Berlin"}},
+	"FR": {"": {"Europe/Paris"}},
+	"ES": {"": {"Europe/Madrid"}},
+	"IT": {"": {"Europe/Rome"}},
+	"NL": {"": {"Europe/Amsterdam"}},
+	"PL": {"": {"Europe/Warsaw"}},
+	"MX": {"": {"America/Mexico_City"}},
+	"BR": {"": {"America/Sao_Paulo"}},
+	"IN": {"": {"Asia/Kolkata"}},
+	"ZA": {"": {"Africa/Johannesburg"}},
+}
+
+var nanpAreaCodes = buildAreaCodeIndex()
+
+func buildAreaCodeIndex() map[string]string {
+	index := make(map[string]string)
+	for zone, codes := range nanpTimeZones {
+		for _, code := range codes {
+			index[code] = zone
+		}
+	}
+	return index
+}
+
+// TimeZones returns the IANA time zones the owner of an E.164 number may be
+// in. It returns nil when the number's region is not supported.
+func TimeZones(number string) []string {
+	if !IsE164(number) {
+		return nil
+	}
+
+	digits := number[1:]
+	region, ok := regionForDigits(digits)
+	if !ok {
+		return nil
+	}
+	national := digits[len(region.CallingCode):]
+
+	if region.CallingCode == "1" {
+		if zone, ok := nanpAreaCodes[national[:3]]; ok {
+			return []string{zone}
+		}
+		return append([]string(nil), continentalTimeZones...)
+	}
+
+	zones, ok := regionTimeZones[region.Code]
+	if !ok {
+		return nil
+	}
+	for prefix, prefixZones := range zones {
+		if prefix != "" && strings.HasPrefix(national, prefix) {
+			return append([]string(nil), prefixZones...)
+		}
+	}
+	return append([]string(nil), zones[""]...)
+}